@@ -19,6 +19,7 @@ package admin
 
 import (
 	"context"
+	"io"
 
 	apisecurity "github.com/polarismesh/specification/source/go/api/v1/security"
 	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
@@ -26,6 +27,7 @@ import (
 	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/common/model/admin"
 	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	"github.com/polarismesh/polaris/store/backup"
 )
 
 // AdminOperateServer Maintain related operation
@@ -58,4 +60,27 @@ type AdminOperateServer interface {
 	InitMainUser(ctx context.Context, user apisecurity.User) error
 	// GetServerFunctions Get server functions
 	GetServerFunctions(ctx context.Context) []authcommon.ServerFunctionGroup
+	// BackupData Write a snapshot of the store into w as a backup archive
+	BackupData(ctx context.Context, w io.Writer, opt *backup.BackupOption) error
+	// RestoreData Restore a backup archive into the store
+	RestoreData(ctx context.Context, r io.Reader, opt *backup.RestoreOption) (*backup.RestoreReport, error)
+	// GetCacheStatus Get whether the caches are synchronized with the store
+	GetCacheStatus(ctx context.Context) (*admin.CacheStatus, error)
+	// ListServiceAccessPolicies List service access policies of the destination service
+	ListServiceAccessPolicies(ctx context.Context, namespace, service string) ([]*authcommon.ServiceAccessPolicy, error)
+	// CreateServiceAccessPolicy Create a service access policy
+	CreateServiceAccessPolicy(ctx context.Context,
+		policy *authcommon.ServiceAccessPolicy) (*authcommon.ServiceAccessPolicy, error)
+	// UpdateServiceAccessPolicy Update a service access policy
+	UpdateServiceAccessPolicy(ctx context.Context,
+		policy *authcommon.ServiceAccessPolicy) (*authcommon.ServiceAccessPolicy, error)
+	// DeleteServiceAccessPolicy Delete a service access policy
+	DeleteServiceAccessPolicy(ctx context.Context, id string) error
+	// ListHealthCheckSuspensions List the unexpired scoped health check suspensions
+	ListHealthCheckSuspensions(ctx context.Context) ([]*model.HealthCheckSuspension, error)
+	// CreateHealthCheckSuspension Stop marking the selected instances unhealthy until the ttl expires
+	CreateHealthCheckSuspension(ctx context.Context,
+		suspension *model.HealthCheckSuspension) (*model.HealthCheckSuspension, error)
+	// CancelHealthCheckSuspension Cancel a scoped health check suspension
+	CancelHealthCheckSuspension(ctx context.Context, id string) error
 }
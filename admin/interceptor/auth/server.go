@@ -19,6 +19,7 @@ package auth
 
 import (
 	"context"
+	"io"
 
 	apisecurity "github.com/polarismesh/specification/source/go/api/v1/security"
 	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
@@ -30,6 +31,7 @@ import (
 	admincommon "github.com/polarismesh/polaris/common/model/admin"
 	authcommon "github.com/polarismesh/polaris/common/model/auth"
 	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store/backup"
 )
 
 var _ admin.AdminOperateServer = (*Server)(nil)
@@ -208,6 +210,131 @@ func (svr *Server) GetCMDBInfo(ctx context.Context) ([]model.LocationView, error
 	return svr.nextSvr.GetCMDBInfo(ctx)
 }
 
+func (svr *Server) BackupData(ctx context.Context, w io.Writer, opt *backup.BackupOption) error {
+	authCtx := svr.collectMaintainAuthContext(ctx, authcommon.Read, authcommon.BackupData)
+	if _, err := svr.policySvr.GetAuthChecker().CheckConsolePermission(authCtx); err != nil {
+		return err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.nextSvr.BackupData(ctx, w, opt)
+}
+
+func (svr *Server) RestoreData(ctx context.Context, r io.Reader,
+	opt *backup.RestoreOption) (*backup.RestoreReport, error) {
+	authCtx := svr.collectMaintainAuthContext(ctx, authcommon.Modify, authcommon.RestoreData)
+	if _, err := svr.policySvr.GetAuthChecker().CheckConsolePermission(authCtx); err != nil {
+		return nil, err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.nextSvr.RestoreData(ctx, r, opt)
+}
+
+func (svr *Server) GetCacheStatus(ctx context.Context) (*admincommon.CacheStatus, error) {
+	authCtx := svr.collectMaintainAuthContext(ctx, authcommon.Read, authcommon.DescribeCacheStatus)
+	if _, err := svr.policySvr.GetAuthChecker().CheckConsolePermission(authCtx); err != nil {
+		return nil, err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.nextSvr.GetCacheStatus(ctx)
+}
+
+func (svr *Server) ListServiceAccessPolicies(ctx context.Context,
+	namespace, service string) ([]*authcommon.ServiceAccessPolicy, error) {
+	authCtx := svr.collectMaintainAuthContext(ctx, authcommon.Read, authcommon.DescribeServiceAccessPolicies)
+	if _, err := svr.policySvr.GetAuthChecker().CheckConsolePermission(authCtx); err != nil {
+		return nil, err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.nextSvr.ListServiceAccessPolicies(ctx, namespace, service)
+}
+
+func (svr *Server) CreateServiceAccessPolicy(ctx context.Context,
+	policy *authcommon.ServiceAccessPolicy) (*authcommon.ServiceAccessPolicy, error) {
+	authCtx := svr.collectMaintainAuthContext(ctx, authcommon.Create, authcommon.CreateServiceAccessPolicy)
+	if _, err := svr.policySvr.GetAuthChecker().CheckConsolePermission(authCtx); err != nil {
+		return nil, err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.nextSvr.CreateServiceAccessPolicy(ctx, policy)
+}
+
+func (svr *Server) UpdateServiceAccessPolicy(ctx context.Context,
+	policy *authcommon.ServiceAccessPolicy) (*authcommon.ServiceAccessPolicy, error) {
+	authCtx := svr.collectMaintainAuthContext(ctx, authcommon.Modify, authcommon.UpdateServiceAccessPolicy)
+	if _, err := svr.policySvr.GetAuthChecker().CheckConsolePermission(authCtx); err != nil {
+		return nil, err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.nextSvr.UpdateServiceAccessPolicy(ctx, policy)
+}
+
+func (svr *Server) DeleteServiceAccessPolicy(ctx context.Context, id string) error {
+	authCtx := svr.collectMaintainAuthContext(ctx, authcommon.Delete, authcommon.DeleteServiceAccessPolicy)
+	if _, err := svr.policySvr.GetAuthChecker().CheckConsolePermission(authCtx); err != nil {
+		return err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.nextSvr.DeleteServiceAccessPolicy(ctx, id)
+}
+
+func (svr *Server) ListHealthCheckSuspensions(ctx context.Context) ([]*model.HealthCheckSuspension, error) {
+	authCtx := svr.collectMaintainAuthContext(ctx, authcommon.Read, authcommon.DescribeHealthCheckSuspensions)
+	if _, err := svr.policySvr.GetAuthChecker().CheckConsolePermission(authCtx); err != nil {
+		return nil, err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.nextSvr.ListHealthCheckSuspensions(ctx)
+}
+
+func (svr *Server) CreateHealthCheckSuspension(ctx context.Context,
+	suspension *model.HealthCheckSuspension) (*model.HealthCheckSuspension, error) {
+	authCtx := svr.collectMaintainAuthContext(ctx, authcommon.Create, authcommon.CreateHealthCheckSuspension)
+	if _, err := svr.policySvr.GetAuthChecker().CheckConsolePermission(authCtx); err != nil {
+		return nil, err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.nextSvr.CreateHealthCheckSuspension(ctx, suspension)
+}
+
+func (svr *Server) CancelHealthCheckSuspension(ctx context.Context, id string) error {
+	authCtx := svr.collectMaintainAuthContext(ctx, authcommon.Delete, authcommon.CancelHealthCheckSuspension)
+	if _, err := svr.policySvr.GetAuthChecker().CheckConsolePermission(authCtx); err != nil {
+		return err
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return svr.nextSvr.CancelHealthCheckSuspension(ctx, id)
+}
+
 // GetServerFunctions .
 func (svr *Server) GetServerFunctions(ctx context.Context) []authcommon.ServerFunctionGroup {
 	return svr.nextSvr.GetServerFunctions(ctx)
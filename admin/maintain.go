@@ -20,6 +20,7 @@ package admin
 import (
 	"context"
 	"errors"
+	"io"
 	"runtime/debug"
 	"time"
 
@@ -37,6 +38,7 @@ import (
 	commonstore "github.com/polarismesh/polaris/common/store"
 	"github.com/polarismesh/polaris/common/utils"
 	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store/backup"
 )
 
 func (s *Server) HasMainUser(ctx context.Context, user apisecurity.User) (bool, error) {
@@ -183,6 +185,31 @@ func (s *Server) GetLastHeartbeat(_ context.Context, req *apiservice.Instance) *
 	return s.healthCheckServer.GetLastHeartbeat(req)
 }
 
+func (s *Server) ListHealthCheckSuspensions(_ context.Context) ([]*model.HealthCheckSuspension, error) {
+	return s.healthCheckServer.ListSuspensions()
+}
+
+func (s *Server) CreateHealthCheckSuspension(ctx context.Context,
+	suspension *model.HealthCheckSuspension) (*model.HealthCheckSuspension, error) {
+	ret, err := s.healthCheckServer.CreateSuspension(suspension)
+	if err != nil {
+		log.Error("[MAINTAIN] create health check suspension", utils.RequestID(ctx), zap.Error(err))
+		return nil, err
+	}
+	log.Info("[MAINTAIN] create health check suspension", utils.RequestID(ctx), zap.String("id", ret.ID))
+	return ret, nil
+}
+
+func (s *Server) CancelHealthCheckSuspension(ctx context.Context, id string) error {
+	if err := s.healthCheckServer.CancelSuspension(id); err != nil {
+		log.Error("[MAINTAIN] cancel health check suspension", utils.RequestID(ctx), zap.String("id", id),
+			zap.Error(err))
+		return err
+	}
+	log.Info("[MAINTAIN] cancel health check suspension", utils.RequestID(ctx), zap.String("id", id))
+	return nil
+}
+
 func (s *Server) GetLogOutputLevel(_ context.Context) ([]admin.ScopeLevel, error) {
 	scopes := commonlog.Scopes()
 	out := make([]admin.ScopeLevel, 0, len(scopes))
@@ -235,3 +262,40 @@ func (svr *Server) GetCMDBInfo(ctx context.Context) ([]model.LocationView, error
 func (svr *Server) GetServerFunctions(ctx context.Context) []authcommon.ServerFunctionGroup {
 	return authcommon.ServerFunctions
 }
+
+// BackupData 在快照读视图中导出存储中的数据
+func (s *Server) BackupData(_ context.Context, w io.Writer, opt *backup.BackupOption) error {
+	manifest, err := backup.Backup(s.storage, w, opt)
+	if err != nil {
+		log.Error("[MAINTAIN] backup data", zap.Error(err))
+		return err
+	}
+	log.Info("[MAINTAIN] backup data finished", zap.Strings("namespaces", manifest.Namespaces),
+		zap.Int("resources", len(manifest.Resources)))
+	return nil
+}
+
+// RestoreData 将备份数据写入存储，各个缓存按照修改时间增量拉取数据，因此在线恢复时不回写备份中的时间
+func (s *Server) RestoreData(_ context.Context, r io.Reader,
+	opt *backup.RestoreOption) (*backup.RestoreReport, error) {
+	if opt == nil {
+		opt = &backup.RestoreOption{}
+	}
+	opt.RestoreTimestamps = false
+	report, err := backup.Restore(s.storage, r, opt)
+	if err != nil {
+		log.Error("[MAINTAIN] restore data", zap.Error(err))
+		return report, err
+	}
+	log.Info("[MAINTAIN] restore data finished", zap.Strings("namespaces", opt.Namespaces),
+		zap.String("conflict", string(opt.Conflict)))
+	return report, nil
+}
+
+// GetCacheStatus 获取缓存和存储的同步状态
+func (s *Server) GetCacheStatus(_ context.Context) (*admin.CacheStatus, error) {
+	if s.cacheMgn == nil {
+		return nil, errors.New("cache manager not initialized")
+	}
+	return s.cacheMgn.GetCacheStatus(), nil
+}
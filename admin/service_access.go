@@ -0,0 +1,207 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	apisecurity "github.com/polarismesh/specification/source/go/api/v1/security"
+	"go.uber.org/zap"
+
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+var (
+	// ErrServiceAccessNotSupport 存储插件没有实现 store.ServiceAccessPolicyStore
+	ErrServiceAccessNotSupport = errors.New("store not support service access policy")
+	// ErrServiceAccessNotFound 服务访问策略不存在
+	ErrServiceAccessNotFound = errors.New("service access policy not found")
+)
+
+// ListServiceAccessPolicies 查询服务访问策略，namespace 以及 service 为空时不做过滤
+func (s *Server) ListServiceAccessPolicies(_ context.Context,
+	namespace, service string) ([]*authcommon.ServiceAccessPolicy, error) {
+	accessStore, err := s.serviceAccessStore()
+	if err != nil {
+		return nil, err
+	}
+	policies, err := accessStore.GetMoreServiceAccessPolicies(time.Time{}, true)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*authcommon.ServiceAccessPolicy, 0, len(policies))
+	for _, policy := range policies {
+		if namespace != "" && policy.Namespace != namespace {
+			continue
+		}
+		if service != "" && policy.Service != service {
+			continue
+		}
+		ret = append(ret, policy)
+	}
+	return ret, nil
+}
+
+// CreateServiceAccessPolicy 创建服务访问策略
+func (s *Server) CreateServiceAccessPolicy(ctx context.Context,
+	policy *authcommon.ServiceAccessPolicy) (*authcommon.ServiceAccessPolicy, error) {
+	accessStore, err := s.serviceAccessStore()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkServiceAccessPolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := s.checkServiceAccessPolicyName(ctx, policy); err != nil {
+		return nil, err
+	}
+	policy.ID = utils.NewUUID()
+	policy.Revision = utils.NewUUID()
+	if err := accessStore.AddServiceAccessPolicy(policy); err != nil {
+		log.Error("[MAINTAIN] create service access policy", zap.String("name", policy.Name), zap.Error(err))
+		return nil, err
+	}
+	log.Info("[MAINTAIN] create service access policy", zap.String("id", policy.ID),
+		zap.String("namespace", policy.Namespace), zap.String("service", policy.Service))
+	return accessStore.GetServiceAccessPolicy(policy.ID)
+}
+
+// UpdateServiceAccessPolicy 更新服务访问策略，策略所属的目标服务不允许修改
+func (s *Server) UpdateServiceAccessPolicy(ctx context.Context,
+	policy *authcommon.ServiceAccessPolicy) (*authcommon.ServiceAccessPolicy, error) {
+	accessStore, err := s.serviceAccessStore()
+	if err != nil {
+		return nil, err
+	}
+	saved, err := accessStore.GetServiceAccessPolicy(policy.ID)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		return nil, ErrServiceAccessNotFound
+	}
+	policy.Namespace = saved.Namespace
+	policy.Service = saved.Service
+	if err := checkServiceAccessPolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := s.checkServiceAccessPolicyName(ctx, policy); err != nil {
+		return nil, err
+	}
+	policy.Revision = utils.NewUUID()
+	if err := accessStore.UpdateServiceAccessPolicy(policy); err != nil {
+		log.Error("[MAINTAIN] update service access policy", zap.String("id", policy.ID), zap.Error(err))
+		return nil, err
+	}
+	log.Info("[MAINTAIN] update service access policy", zap.String("id", policy.ID))
+	return accessStore.GetServiceAccessPolicy(policy.ID)
+}
+
+// DeleteServiceAccessPolicy 删除服务访问策略
+func (s *Server) DeleteServiceAccessPolicy(_ context.Context, id string) error {
+	accessStore, err := s.serviceAccessStore()
+	if err != nil {
+		return err
+	}
+	saved, err := accessStore.GetServiceAccessPolicy(id)
+	if err != nil {
+		return err
+	}
+	if saved == nil {
+		return ErrServiceAccessNotFound
+	}
+	if err := accessStore.DeleteServiceAccessPolicy(id); err != nil {
+		log.Error("[MAINTAIN] delete service access policy", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	log.Info("[MAINTAIN] delete service access policy", zap.String("id", id))
+	return nil
+}
+
+func (s *Server) serviceAccessStore() (store.ServiceAccessPolicyStore, error) {
+	accessStore, ok := s.storage.(store.ServiceAccessPolicyStore)
+	if !ok {
+		return nil, ErrServiceAccessNotSupport
+	}
+	return accessStore, nil
+}
+
+// checkServiceAccessPolicyName 同一个目标服务下策略名称不能重复
+func (s *Server) checkServiceAccessPolicyName(ctx context.Context, policy *authcommon.ServiceAccessPolicy) error {
+	policies, err := s.ListServiceAccessPolicies(ctx, policy.Namespace, policy.Service)
+	if err != nil {
+		return err
+	}
+	for _, item := range policies {
+		if item.Name == policy.Name && item.ID != policy.ID {
+			return fmt.Errorf("service access policy %s already exists", policy.Name)
+		}
+	}
+	return nil
+}
+
+// checkServiceAccessPolicy 检查策略参数，并将 action、method 以及 pathType 统一转为大写
+func checkServiceAccessPolicy(policy *authcommon.ServiceAccessPolicy) error {
+	if policy.Name == "" {
+		return errors.New("policy name is empty")
+	}
+	if policy.Namespace == "" || policy.Service == "" {
+		return errors.New("destination namespace or service is empty")
+	}
+	policy.Action = strings.ToUpper(policy.Action)
+	switch policy.Action {
+	case apisecurity.AuthAction_ALLOW.String(), apisecurity.AuthAction_DENY.String():
+	default:
+		return fmt.Errorf("invalid policy action %q, only ALLOW or DENY", policy.Action)
+	}
+	for _, source := range policy.Sources {
+		if source.Namespace == "" || source.Service == "" {
+			return errors.New("source namespace or service is empty, use * to match any")
+		}
+	}
+	for i := range policy.Conditions {
+		condition := &policy.Conditions[i]
+		for j := range condition.Methods {
+			condition.Methods[j] = strings.ToUpper(condition.Methods[j])
+		}
+		if condition.Path == "" {
+			condition.PathType = ""
+			continue
+		}
+		condition.PathType = strings.ToUpper(condition.PathType)
+		switch condition.PathType {
+		case "":
+			condition.PathType = authcommon.PathMatchExact
+		case authcommon.PathMatchExact, authcommon.PathMatchPrefix:
+		case authcommon.PathMatchRegex:
+			if _, err := regexp.Compile(condition.Path); err != nil {
+				return fmt.Errorf("invalid path regex %q: %w", condition.Path, err)
+			}
+		default:
+			return fmt.Errorf("invalid path type %q, only EXACT, PREFIX or REGEX", condition.PathType)
+		}
+	}
+	return nil
+}
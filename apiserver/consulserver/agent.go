@@ -0,0 +1,92 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/emicklei/go-restful/v3"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+func (h *ConsulServer) addAgentAccess(ws *restful.WebService) {
+	ws.Route(ws.PUT("/agent/service/register").To(h.registerService))
+	ws.Route(ws.PUT("/agent/service/deregister/{service_id}").To(h.deregisterService))
+	ws.Route(ws.PUT("/agent/check/pass/{check_id}").To(h.passCheck))
+}
+
+// registerService 注册服务实例，相同服务 ID 的重复注册会覆盖之前的实例信息
+func (h *ConsulServer) registerService(req *restful.Request, rsp *restful.Response) {
+	reg := &AgentServiceRegistration{}
+	if err := json.NewDecoder(req.Request.Body).Decode(reg); err != nil {
+		writeError(rsp, http.StatusBadRequest, "Request decode failed: "+err.Error())
+		return
+	}
+	ins, err := reg.toSpecInstance(h.namespace(req), clientIP(req))
+	if err != nil {
+		writeError(rsp, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := h.discoverSvr.RegisterInstance(h.parseContext(req, rsp), ins)
+	code := apimodel.Code(resp.GetCode().GetValue())
+	if code != apimodel.Code_ExecuteSuccess && code != apimodel.Code_ExistedResource {
+		log.Errorf("[Consul] register service %s fail, code %d, info %s", reg.ID, code, resp.GetInfo().GetValue())
+		writeResponseError(rsp, resp)
+		return
+	}
+	rsp.WriteHeader(http.StatusOK)
+}
+
+func (h *ConsulServer) deregisterService(req *restful.Request, rsp *restful.Response) {
+	serviceID := req.PathParameter("service_id")
+	resp := h.discoverSvr.DeregisterInstance(h.parseContext(req, rsp), &apiservice.Instance{
+		Id: utils.NewStringValue(instanceID(h.namespace(req), serviceID)),
+	})
+	if !api.IsSuccess(resp) {
+		log.Errorf("[Consul] deregister service %s fail, code %d, info %s", serviceID,
+			resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+		writeResponseError(rsp, resp)
+		return
+	}
+	rsp.WriteHeader(http.StatusOK)
+}
+
+// passCheck TTL 健康检查的心跳，只支持服务注册时携带的 service:<service id> 检查
+func (h *ConsulServer) passCheck(req *restful.Request, rsp *restful.Response) {
+	checkID := req.PathParameter("check_id")
+	serviceID, ok := serviceIDOfCheck(checkID)
+	if !ok {
+		writeError(rsp, http.StatusNotFound, "Unknown check ID \""+checkID+"\"")
+		return
+	}
+	resp := h.healthSvr.Report(h.parseContext(req, rsp), &apiservice.Instance{
+		Id: utils.NewStringValue(instanceID(h.namespace(req), serviceID)),
+	})
+	if !api.IsSuccess(resp) {
+		log.Errorf("[Consul] pass check %s fail, code %d, info %s", checkID,
+			resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+		writeResponseError(rsp, resp)
+		return
+	}
+	rsp.WriteHeader(http.StatusOK)
+}
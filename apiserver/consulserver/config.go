@@ -0,0 +1,82 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	connlimit "github.com/polarismesh/polaris/common/conn/limit"
+	"github.com/polarismesh/polaris/common/secure"
+)
+
+const (
+	ProtocolName = "service-consul"
+
+	DefaultListenPort = 8500
+	DefaultNamespace  = "default"
+	DefaultDatacenter = "dc1"
+	DefaultKVGroup    = "consul-kv"
+	// DefaultBlockWait 阻塞查询没有指定 wait 参数时的等待时间
+	DefaultBlockWait = 5 * time.Minute
+	// MaxBlockWait 阻塞查询的最大等待时间
+	MaxBlockWait = 10 * time.Minute
+)
+
+// ConsulConfig Consul 兼容 API 服务器的配置
+type ConsulConfig struct {
+	ListenIP   string            `mapstructure:"listenIP"`
+	ListenPort uint32            `mapstructure:"listenPort"`
+	ConnLimit  *connlimit.Config `mapstructure:"connLimit"`
+	TLS        *secure.TLSConfig `mapstructure:"tls"`
+	// DefaultNamespace 请求中没有携带 ns 参数时使用的北极星命名空间
+	DefaultNamespace string `mapstructure:"defaultNamespace"`
+	// Datacenter 返回给客户端的数据中心名称
+	Datacenter string `mapstructure:"datacenter"`
+	// KVGroup KV 数据保存在该配置分组下，key 即配置文件名
+	KVGroup string `mapstructure:"kvGroup"`
+	// MaxBlockWait 阻塞查询的最大等待时间
+	MaxBlockWait time.Duration `mapstructure:"maxBlockWait"`
+}
+
+func loadConsulConfig(raw map[string]interface{}) (*ConsulConfig, error) {
+	cfg := &ConsulConfig{
+		ListenIP:         "0.0.0.0",
+		ListenPort:       DefaultListenPort,
+		DefaultNamespace: DefaultNamespace,
+		Datacenter:       DefaultDatacenter,
+		KVGroup:          DefaultKVGroup,
+		MaxBlockWait:     MaxBlockWait,
+	}
+	decodeConfig := &mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     cfg,
+	}
+	decoder, err := mapstructure.NewDecoder(decodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(raw); err != nil {
+		return nil, err
+	}
+	if cfg.MaxBlockWait <= 0 {
+		cfg.MaxBlockWait = MaxBlockWait
+	}
+	return cfg, nil
+}
@@ -0,0 +1,109 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful/v3"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+func (h *ConsulServer) addHealthAccess(ws *restful.WebService) {
+	ws.Route(ws.GET("/catalog/services").To(h.catalogServices))
+	ws.Route(ws.GET("/health/service/{service}").To(h.healthService))
+}
+
+// catalogServices 查询命名空间下有实例的服务以及服务的 tags
+func (h *ConsulServer) catalogServices(req *restful.Request, rsp *restful.Response) {
+	minIndex, wait, err := h.blockingParams(req)
+	if err != nil {
+		writeError(rsp, http.StatusBadRequest, err.Error())
+		return
+	}
+	namespace := h.namespace(req)
+	index := h.indexes.wait(req.Request.Context(), catalogIndexKey(namespace), minIndex, wait)
+
+	ctx := h.parseContext(req, rsp)
+	_, services := h.discoverSvr.Cache().Service().ListServices(ctx, namespace)
+	ret := make(map[string][]string, len(services))
+	for _, svc := range services {
+		instances := h.discoverSvr.Cache().Instance().DiscoverServiceInstances(svc.ID, false)
+		if len(instances) == 0 {
+			continue
+		}
+		tags := map[string]struct{}{}
+		for _, ins := range instances {
+			for _, tag := range parseTags(ins.Metadata()) {
+				tags[tag] = struct{}{}
+			}
+		}
+		ret[svc.Name] = sortedKeys(tags)
+	}
+	writeJSON(rsp, index, ret)
+}
+
+// healthService 查询服务的实例，支持 passing 以及 tag 过滤，支持通过 index/wait 进行阻塞查询
+func (h *ConsulServer) healthService(req *restful.Request, rsp *restful.Response) {
+	minIndex, wait, err := h.blockingParams(req)
+	if err != nil {
+		writeError(rsp, http.StatusBadRequest, err.Error())
+		return
+	}
+	namespace := h.namespace(req)
+	serviceName := req.PathParameter("service")
+	query := req.Request.URL.Query()
+	_, passing := query["passing"]
+	if val := query.Get("passing"); val == "false" || val == "0" {
+		passing = false
+	}
+	tags := query["tag"]
+	index := h.indexes.wait(req.Request.Context(), serviceIndexKey(namespace, serviceName), minIndex, wait)
+
+	resp := h.discoverSvr.ServiceInstancesCache(h.parseContext(req, rsp),
+		&apiservice.DiscoverFilter{OnlyHealthyInstance: passing}, &apiservice.Service{
+			Name:      utils.NewStringValue(serviceName),
+			Namespace: utils.NewStringValue(namespace),
+		})
+	entries := make([]*ServiceEntry, 0, len(resp.GetInstances()))
+	switch {
+	case api.IsSuccess(resp):
+	case resp.GetCode().GetValue() == uint32(apimodel.Code_NotFoundResource):
+		// Consul 查询不存在的服务返回空列表
+		writeJSON(rsp, index, entries)
+		return
+	default:
+		writeResponseError(rsp, resp)
+		return
+	}
+	for _, ins := range resp.GetInstances() {
+		if passing && (!ins.GetHealthy().GetValue() || ins.GetIsolate().GetValue()) {
+			continue
+		}
+		entry := toServiceEntry(h.cfg.Datacenter, ins)
+		if !hasTags(entry, tags) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	writeJSON(rsp, index, entries)
+}
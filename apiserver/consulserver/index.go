@@ -0,0 +1,156 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/polarismesh/polaris/common/eventhub"
+)
+
+// indexEntry 某个资源的当前版本，资源变化时关闭 changed 通知阻塞的查询
+type indexEntry struct {
+	index   uint64
+	changed chan struct{}
+}
+
+// indexCenter 为阻塞查询维护资源的 X-Consul-Index，北极星的 revision 是字符串，
+// 这里在实例缓存以及 KV 配置分组变化时为资源分配单调递增的 index
+type indexCenter struct {
+	lock    sync.Mutex
+	index   uint64
+	entries map[string]*indexEntry
+	kvGroup string
+	subs    []*eventhub.SubscribtionContext
+}
+
+func newIndexCenter(kvGroup string) *indexCenter {
+	return &indexCenter{
+		index:   1,
+		entries: map[string]*indexEntry{},
+		kvGroup: kvGroup,
+	}
+}
+
+func serviceIndexKey(namespace, service string) string {
+	return "service/" + namespace + "/" + service
+}
+
+func catalogIndexKey(namespace string) string {
+	return "catalog/" + namespace
+}
+
+func kvIndexKey(namespace string) string {
+	return "kv/" + namespace
+}
+
+// subscribe 订阅实例缓存以及配置发布的变化
+func (c *indexCenter) subscribe() error {
+	sub, err := eventhub.SubscribeWithFunc(eventhub.CacheInstanceEventTopic, c.onInstanceEvent)
+	if err != nil {
+		return err
+	}
+	c.subs = append(c.subs, sub)
+	sub, err = eventhub.SubscribeWithFunc(eventhub.ConfigFilePublishTopic, c.onConfigFileEvent)
+	if err != nil {
+		c.stop()
+		return err
+	}
+	c.subs = append(c.subs, sub)
+	return nil
+}
+
+func (c *indexCenter) stop() {
+	for _, sub := range c.subs {
+		sub.Cancel()
+	}
+	c.subs = nil
+}
+
+func (c *indexCenter) onInstanceEvent(_ context.Context, args any) error {
+	event, ok := args.(*eventhub.CacheInstanceEvent)
+	if !ok || event.Instance == nil {
+		return nil
+	}
+	c.touch(serviceIndexKey(event.Instance.Namespace(), event.Instance.Service()),
+		catalogIndexKey(event.Instance.Namespace()))
+	return nil
+}
+
+func (c *indexCenter) onConfigFileEvent(_ context.Context, args any) error {
+	event, ok := args.(*eventhub.PublishConfigFileEvent)
+	if !ok || event.Message == nil || event.Message.ConfigFileReleaseKey == nil {
+		return nil
+	}
+	if event.Message.Group != c.kvGroup {
+		return nil
+	}
+	c.touch(kvIndexKey(event.Message.Namespace))
+	return nil
+}
+
+// touch 资源发生变化，分配新的 index 并唤醒阻塞中的查询
+func (c *indexCenter) touch(keys ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.index++
+	for _, key := range keys {
+		entry, ok := c.entries[key]
+		if !ok {
+			c.entries[key] = &indexEntry{index: c.index, changed: make(chan struct{})}
+			continue
+		}
+		entry.index = c.index
+		close(entry.changed)
+		entry.changed = make(chan struct{})
+	}
+}
+
+func (c *indexCenter) get(key string) (uint64, chan struct{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &indexEntry{index: c.index, changed: make(chan struct{})}
+		c.entries[key] = entry
+	}
+	return entry.index, entry.changed
+}
+
+// wait 阻塞直到资源的 index 大于客户端携带的 index 或者超时，返回资源当前的 index；
+// 客户端的 index 大于当前 index 时（例如服务端重启）立即返回，由客户端重置 index
+func (c *indexCenter) wait(ctx context.Context, key string, minIndex uint64, wait time.Duration) uint64 {
+	index, changed := c.get(key)
+	if minIndex == 0 || index != minIndex {
+		return index
+	}
+	// 和 Consul 一样增加随机的等待时间，避免大量客户端同时发起查询
+	wait += time.Duration(rand.Int63n(int64(wait/16) + 1))
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-changed:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	index, _ = c.get(key)
+	return index
+}
@@ -0,0 +1,253 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/emicklei/go-restful/v3"
+	apiconfig "github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+func (h *ConsulServer) addKVAccess(ws *restful.WebService) {
+	// 通配的路径参数不能匹配空字符串，/v1/kv/?recurse 这类查询所有 KV 的请求需要单独的路由
+	ws.Route(ws.GET("/kv/").To(h.getKV))
+	ws.Route(ws.DELETE("/kv/").To(h.deleteKV))
+	ws.Route(ws.GET("/kv/{key:*}").To(h.getKV))
+	ws.Route(ws.PUT("/kv/{key:*}").To(h.putKV))
+	ws.Route(ws.DELETE("/kv/{key:*}").To(h.deleteKV))
+}
+
+// getKV 查询 KV，支持 recurse/keys/raw 参数以及阻塞查询，KV 保存在 kvGroup 配置分组下，key 即配置文件名
+func (h *ConsulServer) getKV(req *restful.Request, rsp *restful.Response) {
+	minIndex, wait, err := h.blockingParams(req)
+	if err != nil {
+		writeError(rsp, http.StatusBadRequest, err.Error())
+		return
+	}
+	namespace := h.namespace(req)
+	key := req.PathParameter("key")
+	query := req.Request.URL.Query()
+	_, recurse := query["recurse"]
+	_, keysOnly := query["keys"]
+	_, raw := query["raw"]
+	if key == "" && !recurse && !keysOnly {
+		writeError(rsp, http.StatusBadRequest, "Missing key name")
+		return
+	}
+	index := h.indexes.wait(req.Request.Context(), kvIndexKey(namespace), minIndex, wait)
+	ctx := h.parseContext(req, rsp)
+
+	if keysOnly {
+		keys := h.listKeys(namespace, key, query.Get("separator"))
+		if len(keys) == 0 {
+			writeNotFound(rsp, index)
+			return
+		}
+		writeJSON(rsp, index, keys)
+		return
+	}
+
+	names := []string{key}
+	if recurse {
+		names = h.listKeys(namespace, key, "")
+	}
+	pairs := make([]*KVPair, 0, len(names))
+	for _, name := range names {
+		pair, resp := h.queryKV(ctx, namespace, name)
+		if pair != nil {
+			pairs = append(pairs, pair)
+			continue
+		}
+		if resp.GetCode().GetValue() != uint32(apimodel.Code_NotFoundResource) {
+			writeResponseError(rsp, resp)
+			return
+		}
+	}
+	if len(pairs) == 0 {
+		writeNotFound(rsp, index)
+		return
+	}
+	if raw && !recurse {
+		rsp.AddHeader(headerConsulIndex, strconv.FormatUint(index, 10))
+		rsp.WriteHeader(http.StatusOK)
+		_, _ = rsp.Write(pairs[0].Value)
+		return
+	}
+	writeJSON(rsp, index, pairs)
+}
+
+// putKV 写入 KV，写入后立即发布；cas=0 表示只在 key 不存在时写入，否则只在 ModifyIndex 一致时写入
+func (h *ConsulServer) putKV(req *restful.Request, rsp *restful.Response) {
+	namespace := h.namespace(req)
+	key := req.PathParameter("key")
+	if key == "" {
+		writeError(rsp, http.StatusBadRequest, "Missing key name")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(rsp, req.Request.Body, utils.MaxRequestBodySize))
+	if err != nil {
+		writeError(rsp, http.StatusBadRequest, err.Error())
+		return
+	}
+	ctx := h.parseContext(req, rsp)
+	publishReq := &apiconfig.ConfigFilePublishInfo{
+		Namespace: utils.NewStringValue(namespace),
+		Group:     utils.NewStringValue(h.cfg.KVGroup),
+		FileName:  utils.NewStringValue(key),
+		Content:   utils.NewStringValue(string(body)),
+		Format:    utils.NewStringValue(utils.FileFormatText),
+	}
+
+	var resp *apiconfig.ConfigResponse
+	if val := req.QueryParameter("cas"); val != "" {
+		casIndex, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			writeError(rsp, http.StatusBadRequest, "Invalid cas index "+val)
+			return
+		}
+		// 版本比对在存储事务内完成，本节点上同一个 key 的写入再串行化，避免并发创建时都走到插入
+		unlock := h.kvLocks.lock(namespace + "/" + key)
+		resp = h.configSvr.VersionCasUpsertAndReleaseConfigFileFromClient(ctx, publishReq, casIndex)
+		unlock()
+		if resp.GetCode().GetValue() == uint32(apimodel.Code_DataConflict) {
+			writeJSON(rsp, 0, false)
+			return
+		}
+	} else {
+		resp = h.configSvr.UpsertAndReleaseConfigFileFromClient(ctx, publishReq)
+	}
+	if !api.IsSuccess(resp) {
+		log.Errorf("[Consul] put kv %s fail, code %d, info %s", key, resp.GetCode().GetValue(),
+			resp.GetInfo().GetValue())
+		writeResponseError(rsp, resp)
+		return
+	}
+	writeJSON(rsp, 0, true)
+}
+
+// deleteKV 删除 KV，recurse 时删除所有以 key 为前缀的 KV
+func (h *ConsulServer) deleteKV(req *restful.Request, rsp *restful.Response) {
+	namespace := h.namespace(req)
+	key := req.PathParameter("key")
+	names := []string{key}
+	if _, recurse := req.Request.URL.Query()["recurse"]; recurse {
+		names = h.listKeys(namespace, key, "")
+	} else if key == "" {
+		writeError(rsp, http.StatusBadRequest, "Missing key name")
+		return
+	}
+	ctx := h.parseContext(req, rsp)
+	for _, name := range names {
+		resp := h.configSvr.DeleteConfigFileFromClient(ctx, &apiconfig.ConfigFile{
+			Namespace: utils.NewStringValue(namespace),
+			Group:     utils.NewStringValue(h.cfg.KVGroup),
+			Name:      utils.NewStringValue(name),
+		})
+		if !api.IsSuccess(resp) && resp.GetCode().GetValue() != uint32(apimodel.Code_NotFoundResource) {
+			log.Errorf("[Consul] delete kv %s fail, code %d, info %s", name, resp.GetCode().GetValue(),
+				resp.GetInfo().GetValue())
+			writeResponseError(rsp, resp)
+			return
+		}
+	}
+	writeJSON(rsp, 0, true)
+}
+
+func (h *ConsulServer) queryKV(ctx context.Context, namespace, key string) (*KVPair,
+	*apiconfig.ConfigClientResponse) {
+	resp := h.configSvr.GetConfigFileWithCache(ctx, &apiconfig.ClientConfigFileInfo{
+		Namespace: utils.NewStringValue(namespace),
+		Group:     utils.NewStringValue(h.cfg.KVGroup),
+		FileName:  utils.NewStringValue(key),
+	})
+	if !api.IsSuccess(resp) {
+		return nil, resp
+	}
+	file := resp.GetConfigFile()
+	return &KVPair{
+		Key:         key,
+		CreateIndex: file.GetVersion().GetValue(),
+		ModifyIndex: file.GetVersion().GetValue(),
+		Value:       []byte(file.GetContent().GetValue()),
+	}, resp
+}
+
+// listKeys 列出以 prefix 为前缀的 key，指定 separator 时只返回到第一个分隔符为止的部分
+func (h *ConsulServer) listKeys(namespace, prefix, separator string) []string {
+	releases, _ := h.discoverSvr.Cache().ConfigFile().GetGroupActiveReleases(namespace, h.cfg.KVGroup)
+	names := make([]string, 0, len(releases))
+	for _, release := range releases {
+		if release.ReleaseType != model.ReleaseTypeFull {
+			continue
+		}
+		names = append(names, release.FileName)
+	}
+	return filterKeys(names, prefix, separator)
+}
+
+func filterKeys(names []string, prefix, separator string) []string {
+	keys := map[string]struct{}{}
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if separator != "" {
+			if idx := strings.Index(name[len(prefix):], separator); idx >= 0 {
+				name = name[:len(prefix)+idx+len(separator)]
+			}
+		}
+		keys[name] = struct{}{}
+	}
+	ret := sortedKeys(keys)
+	sort.Strings(ret)
+	return ret
+}
+
+func writeNotFound(rsp *restful.Response, index uint64) {
+	rsp.AddHeader(headerConsulIndex, strconv.FormatUint(index, 10))
+	rsp.WriteHeader(http.StatusNotFound)
+}
+
+// kvLockSlots keyLocks 的分段数
+const kvLockSlots = 64
+
+// keyLocks 按 key 哈希分段的互斥锁，用于串行化同一个 key 的 cas 写入
+type keyLocks struct {
+	slots [kvLockSlots]sync.Mutex
+}
+
+func (l *keyLocks) lock(key string) func() {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	mu := &l.slots[h.Sum32()%kvLockSlots]
+	mu.Lock()
+	return mu.Unlock
+}
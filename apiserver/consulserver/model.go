@@ -0,0 +1,309 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+const (
+	// MetaKeyConsulServiceID 实例在 Consul 中的服务 ID
+	MetaKeyConsulServiceID = "internal-consul-service-id"
+	// MetaKeyConsulTags 实例在 Consul 中的 tags，使用 json 数组保存
+	MetaKeyConsulTags = "internal-consul-tags"
+
+	// checkIDPrefix 服务注册时携带的健康检查 ID 统一为 service:<service id>
+	checkIDPrefix = "service:"
+	// defaultWeight 没有指定权重时使用北极星实例的默认权重，和其他协议注册的实例保持一致
+	defaultWeight   = 100
+	statusPassing   = "passing"
+	statusCritical  = "critical"
+	internalMetaPre = "internal-"
+)
+
+// AgentWeights 实例的权重，Passing 对应北极星实例的权重
+type AgentWeights struct {
+	Passing int
+	Warning int
+}
+
+// AgentServiceCheck 服务注册时携带的健康检查，只支持 TTL 类型，映射为北极星的心跳健康检查
+type AgentServiceCheck struct {
+	CheckID string `json:",omitempty"`
+	Name    string `json:",omitempty"`
+	TTL     string `json:",omitempty"`
+	Status  string `json:",omitempty"`
+}
+
+// AgentServiceRegistration /v1/agent/service/register 的请求体
+type AgentServiceRegistration struct {
+	ID      string
+	Name    string
+	Tags    []string
+	Port    int
+	Address string
+	Meta    map[string]string
+	Weights *AgentWeights
+	Check   *AgentServiceCheck
+	Checks  []*AgentServiceCheck
+}
+
+// Node 实例所在的节点，北极星中没有 agent 节点的概念，使用实例地址作为节点
+type Node struct {
+	ID              string
+	Node            string
+	Address         string
+	Datacenter      string
+	TaggedAddresses map[string]string
+	Meta            map[string]string
+}
+
+// AgentService 服务实例
+type AgentService struct {
+	ID                string
+	Service           string
+	Tags              []string
+	Address           string
+	Meta              map[string]string
+	Port              int
+	Weights           AgentWeights
+	EnableTagOverride bool
+}
+
+// HealthCheck 实例的健康检查状态
+type HealthCheck struct {
+	Node        string
+	CheckID     string
+	Name        string
+	Status      string
+	Notes       string
+	Output      string
+	ServiceID   string
+	ServiceName string
+	ServiceTags []string
+}
+
+// ServiceEntry /v1/health/service/<name> 返回的实例信息
+type ServiceEntry struct {
+	Node    *Node
+	Service *AgentService
+	Checks  []*HealthCheck
+}
+
+// KVPair /v1/kv/<key> 返回的 KV 数据，Value 在 json 中为 base64 编码
+type KVPair struct {
+	Key         string
+	CreateIndex uint64
+	ModifyIndex uint64
+	LockIndex   uint64
+	Flags       uint64
+	Value       []byte
+}
+
+// instanceID Consul 中服务 ID 只需要在 agent 内唯一，北极星的实例 ID 由命名空间和服务 ID 计算得到，
+// 这样注销以及 TTL 心跳只需要携带服务 ID 即可定位到实例
+func instanceID(namespace, serviceID string) string {
+	h := sha1.New()
+	_, _ = h.Write([]byte("consul@" + namespace + "@" + serviceID))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// serviceIDOfCheck 从 check ID 中解析服务 ID
+func serviceIDOfCheck(checkID string) (string, bool) {
+	serviceID := strings.TrimPrefix(checkID, checkIDPrefix)
+	if serviceID == checkID || serviceID == "" {
+		return "", false
+	}
+	return serviceID, true
+}
+
+// ttlCheck 找到第一个 TTL 类型的健康检查
+func (r *AgentServiceRegistration) ttlCheck() *AgentServiceCheck {
+	checks := make([]*AgentServiceCheck, 0, len(r.Checks)+1)
+	if r.Check != nil {
+		checks = append(checks, r.Check)
+	}
+	checks = append(checks, r.Checks...)
+	for _, check := range checks {
+		if check != nil && check.TTL != "" {
+			return check
+		}
+	}
+	return nil
+}
+
+// toSpecInstance 转换为北极星的实例，没有指定地址时使用客户端的地址
+func (r *AgentServiceRegistration) toSpecInstance(namespace, clientIP string) (*apiservice.Instance, error) {
+	if r.Name == "" {
+		return nil, fmt.Errorf("missing service name")
+	}
+	if r.ID == "" {
+		r.ID = r.Name
+	}
+	if r.Address == "" {
+		r.Address = clientIP
+	}
+	weight := defaultWeight
+	if r.Weights != nil && r.Weights.Passing > 0 {
+		weight = r.Weights.Passing
+	}
+	metadata := make(map[string]string, len(r.Meta)+2)
+	for k, v := range r.Meta {
+		metadata[k] = v
+	}
+	metadata[MetaKeyConsulServiceID] = r.ID
+	if len(r.Tags) != 0 {
+		tags, err := json.Marshal(r.Tags)
+		if err != nil {
+			return nil, err
+		}
+		metadata[MetaKeyConsulTags] = string(tags)
+	}
+
+	ins := &apiservice.Instance{
+		Id:                utils.NewStringValue(instanceID(namespace, r.ID)),
+		Service:           utils.NewStringValue(r.Name),
+		Namespace:         utils.NewStringValue(namespace),
+		Host:              utils.NewStringValue(r.Address),
+		Port:              utils.NewUInt32Value(uint32(r.Port)),
+		Weight:            utils.NewUInt32Value(uint32(weight)),
+		Metadata:          metadata,
+		EnableHealthCheck: utils.NewBoolValue(false),
+		Healthy:           utils.NewBoolValue(true),
+	}
+	if check := r.ttlCheck(); check != nil {
+		ttl, err := time.ParseDuration(check.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid check ttl %s: %w", check.TTL, err)
+		}
+		ttlSec := uint32(ttl / time.Second)
+		if ttlSec == 0 {
+			ttlSec = 1
+		}
+		ins.EnableHealthCheck = utils.NewBoolValue(true)
+		ins.HealthCheck = &apiservice.HealthCheck{
+			Type: apiservice.HealthCheck_HEARTBEAT,
+			Heartbeat: &apiservice.HeartbeatHealthCheck{
+				Ttl: utils.NewUInt32Value(ttlSec),
+			},
+		}
+		// Consul 中新注册的 TTL 检查默认为 critical，这里和北极星保持一致默认健康，由心跳超时后置为不健康
+		ins.Healthy = utils.NewBoolValue(check.Status != statusCritical)
+	}
+	return ins, nil
+}
+
+// parseTags 解析实例的 tags
+func parseTags(metadata map[string]string) []string {
+	tags := []string{}
+	if val := metadata[MetaKeyConsulTags]; val != "" {
+		_ = json.Unmarshal([]byte(val), &tags)
+	}
+	return tags
+}
+
+// toServiceEntry 北极星的实例转换为 Consul 的服务实例
+func toServiceEntry(datacenter string, ins *apiservice.Instance) *ServiceEntry {
+	metadata := ins.GetMetadata()
+	serviceID := metadata[MetaKeyConsulServiceID]
+	if serviceID == "" {
+		serviceID = ins.GetId().GetValue()
+	}
+	meta := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if strings.HasPrefix(k, internalMetaPre) {
+			continue
+		}
+		meta[k] = v
+	}
+	tags := parseTags(metadata)
+	host := ins.GetHost().GetValue()
+	status := statusPassing
+	if !ins.GetHealthy().GetValue() || ins.GetIsolate().GetValue() {
+		status = statusCritical
+	}
+	return &ServiceEntry{
+		Node: &Node{
+			Node:            host,
+			Address:         host,
+			Datacenter:      datacenter,
+			TaggedAddresses: map[string]string{},
+			Meta:            map[string]string{},
+		},
+		Service: &AgentService{
+			ID:      serviceID,
+			Service: ins.GetService().GetValue(),
+			Tags:    tags,
+			Address: host,
+			Meta:    meta,
+			Port:    int(ins.GetPort().GetValue()),
+			Weights: AgentWeights{
+				Passing: int(ins.GetWeight().GetValue()),
+				Warning: 1,
+			},
+		},
+		Checks: []*HealthCheck{
+			{
+				Node:        host,
+				CheckID:     checkIDPrefix + serviceID,
+				Name:        fmt.Sprintf("Service '%s' check", ins.GetService().GetValue()),
+				Status:      status,
+				ServiceID:   serviceID,
+				ServiceName: ins.GetService().GetValue(),
+				ServiceTags: tags,
+			},
+		},
+	}
+}
+
+// hasTags 实例是否包含所有指定的 tag
+func hasTags(entry *ServiceEntry, tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, item := range entry.Service.Tags {
+			if item == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedKeys 返回排序后的 key 列表
+func sortedKeys(m map[string]struct{}) []string {
+	ret := make([]string, 0, len(m))
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
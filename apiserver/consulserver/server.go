@@ -0,0 +1,306 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/apiserver"
+	httpcommon "github.com/polarismesh/polaris/apiserver/httpserver/utils"
+	api "github.com/polarismesh/polaris/common/api/v1"
+	keepalive "github.com/polarismesh/polaris/common/conn/keepalive"
+	connlimit "github.com/polarismesh/polaris/common/conn/limit"
+	"github.com/polarismesh/polaris/common/metrics"
+	"github.com/polarismesh/polaris/common/secure"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/config"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/service"
+	"github.com/polarismesh/polaris/service/healthcheck"
+)
+
+const (
+	headerConsulIndex = "X-Consul-Index"
+	headerConsulToken = "X-Consul-Token"
+	headerKnownLeader = "X-Consul-Knownleader"
+)
+
+// ConsulServer 兼容 Consul agent/catalog/health/kv HTTP API 的服务器，方便 Consul 客户端直接迁移到北极星
+type ConsulServer struct {
+	cfg     *ConsulConfig
+	tlsInfo *secure.TLSInfo
+	server  *http.Server
+
+	discoverSvr service.DiscoverServer
+	healthSvr   *healthcheck.Server
+	configSvr   config.ConfigCenterServer
+	indexes     *indexCenter
+	kvLocks     keyLocks
+}
+
+// GetProtocol API协议名
+func (h *ConsulServer) GetProtocol() string {
+	return ProtocolName
+}
+
+// GetPort API的监听端口
+func (h *ConsulServer) GetPort() uint32 {
+	return h.cfg.ListenPort
+}
+
+// Initialize API初始化逻辑
+func (h *ConsulServer) Initialize(_ context.Context, option map[string]interface{},
+	_ map[string]apiserver.APIConfig) error {
+	cfg, err := loadConsulConfig(option)
+	if err != nil {
+		return err
+	}
+	h.cfg = cfg
+	if cfg.TLS != nil {
+		h.tlsInfo = &secure.TLSInfo{
+			CertFile:      cfg.TLS.CertFile,
+			KeyFile:       cfg.TLS.KeyFile,
+			TrustedCAFile: cfg.TLS.TrustedCAFile,
+		}
+	}
+	return nil
+}
+
+func (h *ConsulServer) prepareRun() error {
+	var err error
+	if h.discoverSvr, err = service.GetServer(); err != nil {
+		return err
+	}
+	if h.healthSvr, err = healthcheck.GetServer(); err != nil {
+		return err
+	}
+	if h.configSvr, err = config.GetServer(); err != nil {
+		return err
+	}
+	h.indexes = newIndexCenter(h.cfg.KVGroup)
+	return h.indexes.subscribe()
+}
+
+// Run API服务的主逻辑循环
+func (h *ConsulServer) Run(errCh chan error) {
+	log.Infof("start consul http server")
+	if err := h.prepareRun(); err != nil {
+		errCh <- err
+		return
+	}
+
+	address := fmt.Sprintf("%v:%v", h.cfg.ListenIP, h.cfg.ListenPort)
+	// 阻塞查询的应答需要在等待结束后才写入，写超时需要大于最大等待时间
+	server := &http.Server{Addr: address, Handler: h.createRestfulContainer(),
+		WriteTimeout: h.cfg.MaxBlockWait + time.Minute}
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Errorf("consul http server net listen(%s) err: %s", address, err.Error())
+		errCh <- err
+		return
+	}
+	ln = keepalive.NewTcpKeepAliveListener(keepalive.DefaultAlivePeriodTime, ln.(*net.TCPListener))
+	if h.cfg.ConnLimit != nil && h.cfg.ConnLimit.OpenConnLimit {
+		log.Infof("consul http server use max connection limit per ip: %d, http max limit: %d",
+			h.cfg.ConnLimit.MaxConnPerHost, h.cfg.ConnLimit.MaxConnLimit)
+		ln, err = connlimit.NewListener(ln, h.GetProtocol(), h.cfg.ConnLimit)
+		if err != nil {
+			log.Errorf("conn limit init err: %s", err.Error())
+			errCh <- err
+			return
+		}
+	}
+	h.server = server
+
+	if h.tlsInfo.IsEmpty() {
+		err = server.Serve(ln)
+	} else {
+		err = server.ServeTLS(ln, h.tlsInfo.CertFile, h.tlsInfo.KeyFile)
+	}
+	if err != nil && err != http.ErrServerClosed {
+		log.Errorf("%+v", err)
+		errCh <- err
+		return
+	}
+	log.Infof("consul http server stop")
+}
+
+// Stop 停止API端口监听
+func (h *ConsulServer) Stop() {
+	connlimit.RemoveLimitListener(h.GetProtocol())
+	if h.indexes != nil {
+		h.indexes.stop()
+	}
+	if h.server != nil {
+		_ = h.server.Close()
+	}
+}
+
+// Restart 重启API
+func (h *ConsulServer) Restart(option map[string]interface{}, api map[string]apiserver.APIConfig,
+	errCh chan error) error {
+	log.Infof("restart consul http server with new config: %+v", option)
+	h.Stop()
+	if err := h.Initialize(context.Background(), option, api); err != nil {
+		return err
+	}
+	go h.Run(errCh)
+	return nil
+}
+
+func (h *ConsulServer) createRestfulContainer() *restful.Container {
+	wsContainer := restful.NewContainer()
+	wsContainer.Filter(h.process)
+
+	ws := new(restful.WebService)
+	ws.Path("/v1").Consumes(restful.MIME_JSON, restful.MIME_OCTET, "text/plain").Produces(restful.MIME_JSON)
+	h.addAgentAccess(ws)
+	h.addHealthAccess(ws)
+	h.addKVAccess(ws)
+	ws.Route(ws.GET("/status/leader").To(h.statusLeader))
+	wsContainer.Add(ws)
+	return wsContainer
+}
+
+// process 在接收和回复时统一处理请求
+func (h *ConsulServer) process(req *restful.Request, rsp *restful.Response, chain *restful.FilterChain) {
+	start := time.Now()
+	log.Debug("[Consul] receive request",
+		zap.String("client-address", req.Request.RemoteAddr),
+		zap.String("method", req.Request.Method),
+		zap.String("url", req.Request.URL.String()),
+	)
+	chain.ProcessFilter(req, rsp)
+
+	path := strings.TrimSuffix(req.Request.URL.Path, "/")
+	// 带参数的路径只统计到接口级别
+	if selected := req.SelectedRoutePath(); selected != "" {
+		path = selected
+	}
+	plugin.GetStatis().ReportCallMetrics(metrics.CallMetric{
+		Type:     metrics.ServerCallMetric,
+		API:      req.Request.Method + ":" + path,
+		Protocol: "HTTP",
+		Code:     rsp.StatusCode(),
+		Duration: time.Since(start),
+	})
+}
+
+// parseContext 解析请求的上下文，Consul 的 token 作为北极星的鉴权 token
+func (h *ConsulServer) parseContext(req *restful.Request, rsp *restful.Response) context.Context {
+	token := req.HeaderParameter(headerConsulToken)
+	if token == "" {
+		token = req.QueryParameter("token")
+	}
+	if token != "" {
+		req.Request.Header.Set(utils.HeaderAuthTokenKey, token)
+	}
+	handler := httpcommon.Handler{Request: req, Response: rsp}
+	return handler.ParseHeaderContext()
+}
+
+// namespace 请求的命名空间，兼容 Consul 企业版的 ns 参数
+func (h *ConsulServer) namespace(req *restful.Request) string {
+	if ns := strings.TrimSpace(req.QueryParameter("ns")); ns != "" {
+		return ns
+	}
+	return h.cfg.DefaultNamespace
+}
+
+// blockingParams 解析阻塞查询的 index 以及 wait 参数
+func (h *ConsulServer) blockingParams(req *restful.Request) (uint64, time.Duration, error) {
+	var (
+		index uint64
+		wait  = DefaultBlockWait
+		err   error
+	)
+	if val := req.QueryParameter("index"); val != "" {
+		if index, err = strconv.ParseUint(val, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid index %s", val)
+		}
+	}
+	if val := req.QueryParameter("wait"); val != "" {
+		if wait, err = time.ParseDuration(val); err != nil {
+			return 0, 0, fmt.Errorf("invalid wait %s", val)
+		}
+	}
+	if wait > h.cfg.MaxBlockWait {
+		wait = h.cfg.MaxBlockWait
+	}
+	return index, wait, nil
+}
+
+// statusLeader 北极星集群没有 leader 的概念，返回当前节点的地址，供客户端检查 agent 是否可用
+func (h *ConsulServer) statusLeader(req *restful.Request, rsp *restful.Response) {
+	writeJSON(rsp, 0, req.Request.Host)
+}
+
+func clientIP(req *restful.Request) string {
+	host, _, err := net.SplitHostPort(req.Request.RemoteAddr)
+	if err != nil {
+		return req.Request.RemoteAddr
+	}
+	return host
+}
+
+func writeJSON(rsp *restful.Response, index uint64, data interface{}) {
+	if index > 0 {
+		rsp.AddHeader(headerConsulIndex, strconv.FormatUint(index, 10))
+	}
+	rsp.AddHeader(headerKnownLeader, "true")
+	rsp.AddHeader(restful.HEADER_ContentType, restful.MIME_JSON)
+	rsp.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(rsp).Encode(data)
+}
+
+// writeError Consul 的错误信息为纯文本
+func writeError(rsp *restful.Response, code int, msg string) {
+	rsp.AddHeader(restful.HEADER_ContentType, "text/plain")
+	rsp.WriteHeader(code)
+	_, _ = rsp.Write([]byte(msg))
+}
+
+// writeResponseError 根据北极星的返回码输出错误信息
+func writeResponseError(rsp *restful.Response, resp api.ResponseMessage) {
+	code := http.StatusInternalServerError
+	switch api.CalcCode(resp) {
+	case http.StatusBadRequest:
+		code = http.StatusBadRequest
+	case http.StatusUnauthorized, http.StatusForbidden:
+		code = http.StatusForbidden
+	case http.StatusNotFound:
+		code = http.StatusNotFound
+	case http.StatusTooManyRequests:
+		code = http.StatusTooManyRequests
+	}
+	if resp.GetCode().GetValue() == uint32(apimodel.Code_NotAllowedAccess) {
+		code = http.StatusForbidden
+	}
+	writeError(rsp, code, resp.GetInfo().GetValue())
+}
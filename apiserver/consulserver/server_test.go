@@ -0,0 +1,150 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package consulserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/eventhub"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+func TestAgentServiceRegistration_toSpecInstance(t *testing.T) {
+	reg := &AgentServiceRegistration{
+		Name: "web",
+		Tags: []string{"v1", "primary"},
+		Port: 8080,
+		Meta: map[string]string{"env": "prod"},
+		Check: &AgentServiceCheck{
+			TTL: "15s",
+		},
+	}
+	ins, err := reg.toSpecInstance("default", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, instanceID("default", "web"), ins.GetId().GetValue())
+	assert.Equal(t, "10.0.0.1", ins.GetHost().GetValue())
+	assert.Equal(t, uint32(8080), ins.GetPort().GetValue())
+	assert.Equal(t, uint32(defaultWeight), ins.GetWeight().GetValue())
+	assert.True(t, ins.GetEnableHealthCheck().GetValue())
+	assert.Equal(t, service_manage.HealthCheck_HEARTBEAT, ins.GetHealthCheck().GetType())
+	assert.Equal(t, uint32(15), ins.GetHealthCheck().GetHeartbeat().GetTtl().GetValue())
+	assert.Equal(t, "web", ins.GetMetadata()[MetaKeyConsulServiceID])
+
+	entry := toServiceEntry("dc1", ins)
+	assert.Equal(t, "web", entry.Service.ID)
+	assert.Equal(t, []string{"v1", "primary"}, entry.Service.Tags)
+	assert.Equal(t, map[string]string{"env": "prod"}, entry.Service.Meta)
+	assert.Equal(t, "service:web", entry.Checks[0].CheckID)
+	assert.Equal(t, statusPassing, entry.Checks[0].Status)
+	assert.True(t, hasTags(entry, []string{"primary"}))
+	assert.False(t, hasTags(entry, []string{"v2"}))
+
+	ins.Healthy = utils.NewBoolValue(false)
+	assert.Equal(t, statusCritical, toServiceEntry("dc1", ins).Checks[0].Status)
+
+	serviceID, ok := serviceIDOfCheck(entry.Checks[0].CheckID)
+	assert.True(t, ok)
+	assert.Equal(t, "web", serviceID)
+	_, ok = serviceIDOfCheck("web")
+	assert.False(t, ok)
+
+	_, err = (&AgentServiceRegistration{Name: "web", Check: &AgentServiceCheck{TTL: "abc"}}).
+		toSpecInstance("default", "10.0.0.1")
+	assert.Error(t, err)
+	_, err = (&AgentServiceRegistration{}).toSpecInstance("default", "10.0.0.1")
+	assert.Error(t, err)
+}
+
+func TestIndexCenter_Wait(t *testing.T) {
+	center := newIndexCenter(DefaultKVGroup)
+	key := serviceIndexKey("default", "web")
+	index := center.wait(context.Background(), key, 0, time.Second)
+
+	t.Run("index 不一致时立即返回", func(t *testing.T) {
+		start := time.Now()
+		assert.Equal(t, index, center.wait(context.Background(), key, index+10, time.Second))
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("资源变化时唤醒阻塞的查询", func(t *testing.T) {
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = center.onInstanceEvent(context.Background(), &eventhub.CacheInstanceEvent{
+				Instance: &model.Instance{Proto: &service_manage.Instance{
+					Service:   utils.NewStringValue("web"),
+					Namespace: utils.NewStringValue("default"),
+				}},
+			})
+		}()
+		newIndex := center.wait(context.Background(), key, index, 5*time.Second)
+		assert.Greater(t, newIndex, index)
+		catalogIndex, _ := center.get(catalogIndexKey("default"))
+		assert.Equal(t, newIndex, catalogIndex)
+		index = newIndex
+	})
+
+	t.Run("超时后返回当前 index", func(t *testing.T) {
+		assert.Equal(t, index, center.wait(context.Background(), key, index, 50*time.Millisecond))
+	})
+
+	t.Run("只有 KV 分组的发布会触发 KV 变化", func(t *testing.T) {
+		kvIndex, _ := center.get(kvIndexKey("default"))
+		_ = center.onConfigFileEvent(context.Background(), &eventhub.PublishConfigFileEvent{
+			Message: &model.SimpleConfigFileRelease{ConfigFileReleaseKey: &model.ConfigFileReleaseKey{
+				Namespace: "default", Group: "other", FileName: "a",
+			}},
+		})
+		newIndex, _ := center.get(kvIndexKey("default"))
+		assert.Equal(t, kvIndex, newIndex)
+		_ = center.onConfigFileEvent(context.Background(), &eventhub.PublishConfigFileEvent{
+			Message: &model.SimpleConfigFileRelease{ConfigFileReleaseKey: &model.ConfigFileReleaseKey{
+				Namespace: "default", Group: DefaultKVGroup, FileName: "a",
+			}},
+		})
+		newIndex, _ = center.get(kvIndexKey("default"))
+		assert.Greater(t, newIndex, kvIndex)
+	})
+}
+
+func TestFilterKeys(t *testing.T) {
+	names := []string{"app/db/host", "app/db/port", "app/name", "other"}
+	assert.Equal(t, []string{"app/db/host", "app/db/port", "app/name"}, filterKeys(names, "app/", ""))
+	assert.Equal(t, []string{"app/db/", "app/name"}, filterKeys(names, "app/", "/"))
+	assert.Equal(t, []string{"app/", "other"}, filterKeys(names, "", "/"))
+	assert.Empty(t, filterKeys(names, "none", ""))
+}
+
+func TestLoadConsulConfig(t *testing.T) {
+	cfg, err := loadConsulConfig(map[string]interface{}{
+		"listenPort":   8501,
+		"kvGroup":      "kv",
+		"maxBlockWait": "1m",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(8501), cfg.ListenPort)
+	assert.Equal(t, "kv", cfg.KVGroup)
+	assert.Equal(t, time.Minute, cfg.MaxBlockWait)
+	assert.Equal(t, DefaultNamespace, cfg.DefaultNamespace)
+	assert.Equal(t, DefaultDatacenter, cfg.Datacenter)
+}
@@ -0,0 +1,78 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dnsserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	DefaultListenPort  = 8053
+	DefaultZone        = "polaris."
+	DefaultTTL         = 5
+	DefaultNegativeTTL = 30
+	// DefaultUDPSize 客户端没有携带 EDNS0 时 UDP 应答的最大长度
+	DefaultUDPSize = 512
+	// MaxUDPSize 允许客户端通过 EDNS0 声明的最大 UDP 应答长度
+	MaxUDPSize = 4096
+)
+
+// Config DNS 服务器配置
+type Config struct {
+	ListenIP   string `mapstructure:"listenIP"`
+	ListenPort uint32 `mapstructure:"listenPort"`
+	// Zone 域名后缀，服务的域名格式为 <service>.<namespace>.<zone>
+	Zone string `mapstructure:"zone"`
+	// TTL A/AAAA/SRV 记录的缓存时间，单位秒
+	TTL uint32 `mapstructure:"ttl"`
+	// NegativeTTL 域名不存在或者没有对应记录时，SOA 记录中的否定缓存时间，单位秒
+	NegativeTTL uint32 `mapstructure:"negativeTtl"`
+}
+
+func parseConfig(option map[string]interface{}) (*Config, error) {
+	cfg := &Config{
+		ListenIP:    "0.0.0.0",
+		ListenPort:  DefaultListenPort,
+		Zone:        DefaultZone,
+		TTL:         DefaultTTL,
+		NegativeTTL: DefaultNegativeTTL,
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		WeaklyTypedInput: true,
+		Result:           cfg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(option); err != nil {
+		return nil, err
+	}
+	cfg.Zone = strings.ToLower(strings.Trim(cfg.Zone, "."))
+	if cfg.Zone == "" {
+		return nil, fmt.Errorf("dns zone must not be empty")
+	}
+	cfg.Zone += "."
+	if _, err := dnsmessage.NewName("hostmaster." + cfg.Zone); err != nil {
+		return nil, fmt.Errorf("invalid dns zone %s: %w", cfg.Zone, err)
+	}
+	return cfg, nil
+}
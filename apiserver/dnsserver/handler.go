@@ -0,0 +1,393 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dnsserver
+
+import (
+	"context"
+	"encoding/hex"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"golang.org/x/net/dns/dnsmessage"
+
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/metrics"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+const (
+	// addrLabel SRV 记录的目标域名格式为 <十六进制的IP>.addr.<zone>，用于解析出具体实例的地址
+	addrLabel = "addr"
+	// maxTCPSize TCP 报文的最大长度
+	maxTCPSize = math.MaxUint16
+)
+
+// result 一次查询的结果
+type result struct {
+	rcode       dnsmessage.RCode
+	answers     []dnsmessage.Resource
+	additionals []dnsmessage.Resource
+	// authoritative 查询的域名属于当前 zone
+	authoritative bool
+	// withSOA 否定应答需要在 authority 中携带 SOA 记录，客户端根据 SOA 的 MinTTL 缓存否定结果
+	withSOA bool
+}
+
+// handle 处理一个 DNS 请求报文，返回 nil 表示丢弃该请求
+func (d *DNSServer) handle(ctx context.Context, req []byte, udp bool) []byte {
+	start := time.Now()
+	var parser dnsmessage.Parser
+	header, err := parser.Start(req)
+	if err != nil || header.Response {
+		return nil
+	}
+	msg := &dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:               header.ID,
+			Response:         true,
+			OpCode:           header.OpCode,
+			RecursionDesired: header.RecursionDesired,
+		},
+	}
+	question, err := parser.Question()
+	if err != nil {
+		msg.Header.RCode = dnsmessage.RCodeFormatError
+		return d.pack(msg, DefaultUDPSize)
+	}
+	msg.Questions = []dnsmessage.Question{question}
+
+	maxSize := maxTCPSize
+	opt, ok := parseEDNS(&parser)
+	if udp {
+		maxSize = DefaultUDPSize
+		if ok && int(opt.Class) > maxSize {
+			maxSize = int(opt.Class)
+		}
+		if maxSize > MaxUDPSize {
+			maxSize = MaxUDPSize
+		}
+	}
+
+	var ret *result
+	if header.OpCode != 0 {
+		ret = &result{rcode: dnsmessage.RCodeNotImplemented}
+	} else {
+		ret = d.resolve(ctx, question)
+	}
+	msg.Header.RCode = ret.rcode
+	msg.Header.Authoritative = ret.authoritative
+	msg.Answers = ret.answers
+	msg.Additionals = ret.additionals
+	if ret.withSOA {
+		msg.Authorities = []dnsmessage.Resource{d.soa()}
+	}
+	if ok {
+		var optHeader dnsmessage.ResourceHeader
+		_ = optHeader.SetEDNS0(MaxUDPSize, ret.rcode, false)
+		msg.Additionals = append(msg.Additionals, dnsmessage.Resource{
+			Header: optHeader,
+			Body:   &dnsmessage.OPTResource{},
+		})
+	}
+	out := d.pack(msg, maxSize)
+	d.reportMetrics(question, ret.rcode, start)
+	return out
+}
+
+// parseEDNS 解析请求中的 OPT 记录
+func parseEDNS(parser *dnsmessage.Parser) (dnsmessage.ResourceHeader, bool) {
+	if err := parser.SkipAllQuestions(); err != nil {
+		return dnsmessage.ResourceHeader{}, false
+	}
+	if err := parser.SkipAllAnswers(); err != nil {
+		return dnsmessage.ResourceHeader{}, false
+	}
+	if err := parser.SkipAllAuthorities(); err != nil {
+		return dnsmessage.ResourceHeader{}, false
+	}
+	for {
+		header, err := parser.AdditionalHeader()
+		if err != nil {
+			return dnsmessage.ResourceHeader{}, false
+		}
+		if header.Type == dnsmessage.TypeOPT {
+			return header, true
+		}
+		if err := parser.SkipAdditional(); err != nil {
+			return dnsmessage.ResourceHeader{}, false
+		}
+	}
+}
+
+// pack 序列化应答，超过长度限制时只返回问题并设置 TC 标志，由客户端改用 TCP 重新查询
+func (d *DNSServer) pack(msg *dnsmessage.Message, maxSize int) []byte {
+	out, err := msg.Pack()
+	if err == nil && len(out) <= maxSize {
+		return out
+	}
+	if err != nil {
+		log.Errorf("[DNS] pack response of %v error: %v", msg.Questions, err)
+		msg.Header.RCode = dnsmessage.RCodeServerFailure
+	} else {
+		msg.Header.Truncated = true
+	}
+	msg.Answers = nil
+	msg.Authorities = nil
+	additionals := msg.Additionals
+	msg.Additionals = nil
+	for i := range additionals {
+		if additionals[i].Header.Type == dnsmessage.TypeOPT {
+			msg.Additionals = append(msg.Additionals, additionals[i])
+		}
+	}
+	out, err = msg.Pack()
+	if err != nil {
+		log.Errorf("[DNS] pack truncated response of %v error: %v", msg.Questions, err)
+		return nil
+	}
+	return out
+}
+
+func (d *DNSServer) resolve(ctx context.Context, question dnsmessage.Question) *result {
+	if question.Class != dnsmessage.ClassINET && question.Class != dnsmessage.ClassANY {
+		return &result{rcode: dnsmessage.RCodeNotImplemented}
+	}
+	name := question.Name.String()
+	lowerName := strings.ToLower(name)
+	zone := d.cfg.Zone
+	if lowerName == zone {
+		ret := &result{rcode: dnsmessage.RCodeSuccess, authoritative: true}
+		if question.Type == dnsmessage.TypeSOA {
+			ret.answers = []dnsmessage.Resource{d.soa()}
+		} else {
+			ret.withSOA = true
+		}
+		return ret
+	}
+	if !strings.HasSuffix(lowerName, "."+zone) {
+		// 不提供递归查询
+		return &result{rcode: dnsmessage.RCodeRefused}
+	}
+	prefix := name[:len(name)-len(zone)-1]
+	if ip, ok := parseAddrName(prefix); ok {
+		return d.resolveAddr(question, ip)
+	}
+	svcName, namespace, ok := parseServiceName(prefix)
+	if !ok {
+		return &result{rcode: dnsmessage.RCodeNameError, authoritative: true, withSOA: true}
+	}
+	return d.resolveService(ctx, question, svcName, namespace)
+}
+
+// parseServiceName 解析 <service>.<namespace> 或者 RFC 2782 格式的 _<service>._tcp.<namespace>，服务名中可以包含 '.'
+func parseServiceName(prefix string) (string, string, bool) {
+	idx := strings.LastIndex(prefix, ".")
+	if idx <= 0 || idx == len(prefix)-1 {
+		return "", "", false
+	}
+	svcName, namespace := prefix[:idx], prefix[idx+1:]
+	labels := strings.Split(svcName, ".")
+	if len(labels) >= 2 && strings.HasPrefix(labels[0], "_") {
+		proto := labels[len(labels)-1]
+		if proto == "_tcp" || proto == "_udp" {
+			svcName = strings.TrimPrefix(strings.Join(labels[:len(labels)-1], "."), "_")
+		}
+	}
+	if svcName == "" {
+		return "", "", false
+	}
+	return svcName, namespace, true
+}
+
+// parseAddrName 解析 SRV 记录的目标域名 <十六进制的IP>.addr
+func parseAddrName(prefix string) (net.IP, bool) {
+	hexIP, ok := strings.CutSuffix(prefix, "."+addrLabel)
+	if !ok || (len(hexIP) != 2*net.IPv4len && len(hexIP) != 2*net.IPv6len) {
+		return nil, false
+	}
+	ip, err := hex.DecodeString(hexIP)
+	if err != nil {
+		return nil, false
+	}
+	return net.IP(ip), true
+}
+
+func (d *DNSServer) addrName(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return hex.EncodeToString(ip) + "." + addrLabel + "." + d.cfg.Zone
+}
+
+func (d *DNSServer) resolveAddr(question dnsmessage.Question, ip net.IP) *result {
+	ret := &result{rcode: dnsmessage.RCodeSuccess, authoritative: true}
+	if rr, ok := d.addressRecord(question.Name, question.Type, ip); ok {
+		ret.answers = append(ret.answers, rr)
+	}
+	ret.withSOA = len(ret.answers) == 0
+	return ret
+}
+
+// addressRecord 根据查询类型生成 A 或者 AAAA 记录，IP 的类型和查询类型不匹配时返回 false
+func (d *DNSServer) addressRecord(name dnsmessage.Name, qtype dnsmessage.Type,
+	ip net.IP) (dnsmessage.Resource, bool) {
+	header := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: d.cfg.TTL}
+	ip4 := ip.To4()
+	switch {
+	case ip4 != nil && (qtype == dnsmessage.TypeA || qtype == dnsmessage.TypeALL):
+		header.Type = dnsmessage.TypeA
+		body := &dnsmessage.AResource{}
+		copy(body.A[:], ip4)
+		return dnsmessage.Resource{Header: header, Body: body}, true
+	case ip4 == nil && len(ip) == net.IPv6len && (qtype == dnsmessage.TypeAAAA || qtype == dnsmessage.TypeALL):
+		header.Type = dnsmessage.TypeAAAA
+		body := &dnsmessage.AAAAResource{}
+		copy(body.AAAA[:], ip)
+		return dnsmessage.Resource{Header: header, Body: body}, true
+	}
+	return dnsmessage.Resource{}, false
+}
+
+// resolveService 查询服务的实例，只返回健康且没有被隔离的实例；
+// 服务发现逻辑与客户端一致，其他命名空间下通过 ServiceExportTo 暴露给该命名空间的同名服务也会返回
+func (d *DNSServer) resolveService(ctx context.Context, question dnsmessage.Question,
+	svcName, namespace string) *result {
+	resp := d.namingServer.ServiceInstancesCache(ctx, &apiservice.DiscoverFilter{OnlyHealthyInstance: true},
+		&apiservice.Service{
+			Name:      utils.NewStringValue(svcName),
+			Namespace: utils.NewStringValue(namespace),
+		})
+	switch {
+	case api.IsSuccess(resp):
+	case resp.GetCode().GetValue() == uint32(apimodel.Code_NotFoundResource) ||
+		resp.GetCode().GetValue() == uint32(apimodel.Code_NotFoundService) || api.CalcCode(resp) == 400:
+		return &result{rcode: dnsmessage.RCodeNameError, authoritative: true, withSOA: true}
+	default:
+		log.Errorf("[DNS] discover instances of %s.%s fail, code %d, info %s", svcName, namespace,
+			resp.GetCode().GetValue(), resp.GetInfo().GetValue())
+		return &result{rcode: dnsmessage.RCodeServerFailure}
+	}
+
+	ret := &result{rcode: dnsmessage.RCodeSuccess, authoritative: true}
+	visited := make(map[string]struct{}, len(resp.GetInstances()))
+	for _, ins := range resp.GetInstances() {
+		if !ins.GetHealthy().GetValue() || ins.GetIsolate().GetValue() {
+			continue
+		}
+		host := ins.GetHost().GetValue()
+		ip := net.ParseIP(host)
+		if question.Type != dnsmessage.TypeSRV {
+			if _, ok := visited[host]; ok || ip == nil {
+				continue
+			}
+			visited[host] = struct{}{}
+			if rr, ok := d.addressRecord(question.Name, question.Type, ip); ok {
+				ret.answers = append(ret.answers, rr)
+			}
+			continue
+		}
+		target := strings.TrimSuffix(host, ".") + "."
+		if ip != nil {
+			target = d.addrName(ip)
+		}
+		targetName, err := dnsmessage.NewName(target)
+		if err != nil {
+			log.Warnf("[DNS] invalid srv target %s of instance %s", target, ins.GetId().GetValue())
+			continue
+		}
+		ret.answers = append(ret.answers, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{
+				Name:  question.Name,
+				Type:  dnsmessage.TypeSRV,
+				Class: dnsmessage.ClassINET,
+				TTL:   d.cfg.TTL,
+			},
+			Body: &dnsmessage.SRVResource{
+				Priority: clampUint16(ins.GetPriority().GetValue()),
+				Weight:   clampUint16(ins.GetWeight().GetValue()),
+				Port:     clampUint16(ins.GetPort().GetValue()),
+				Target:   targetName,
+			},
+		})
+		if _, ok := visited[host]; ok || ip == nil {
+			continue
+		}
+		visited[host] = struct{}{}
+		if rr, ok := d.addressRecord(targetName, dnsmessage.TypeALL, ip); ok {
+			ret.additionals = append(ret.additionals, rr)
+		}
+	}
+	ret.withSOA = len(ret.answers) == 0
+	return ret
+}
+
+func clampUint16(v uint32) uint16 {
+	if v > math.MaxUint16 {
+		return math.MaxUint16
+	}
+	return uint16(v)
+}
+
+func (d *DNSServer) soa() dnsmessage.Resource {
+	zone := dnsmessage.MustNewName(d.cfg.Zone)
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{
+			Name:  zone,
+			Type:  dnsmessage.TypeSOA,
+			Class: dnsmessage.ClassINET,
+			TTL:   d.cfg.NegativeTTL,
+		},
+		Body: &dnsmessage.SOAResource{
+			NS:      dnsmessage.MustNewName("ns." + d.cfg.Zone),
+			MBox:    dnsmessage.MustNewName("hostmaster." + d.cfg.Zone),
+			Serial:  uint32(time.Now().Unix()),
+			Refresh: 3600,
+			Retry:   600,
+			Expire:  86400,
+			MinTTL:  d.cfg.NegativeTTL,
+		},
+	}
+}
+
+func (d *DNSServer) reportMetrics(question dnsmessage.Question, rcode dnsmessage.RCode, start time.Time) {
+	if d.statis == nil {
+		return
+	}
+	code := 500
+	switch rcode {
+	case dnsmessage.RCodeSuccess:
+		code = 200
+	case dnsmessage.RCodeNameError:
+		code = 404
+	case dnsmessage.RCodeFormatError:
+		code = 400
+	case dnsmessage.RCodeRefused:
+		code = 403
+	}
+	d.statis.ReportCallMetrics(metrics.CallMetric{
+		Type:     metrics.ServerCallMetric,
+		API:      "DNS:" + strings.TrimPrefix(question.Type.String(), "Type"),
+		Protocol: "DNS",
+		Code:     code,
+		Duration: time.Since(start),
+	})
+}
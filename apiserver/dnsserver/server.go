@@ -0,0 +1,200 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dnsserver
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/polarismesh/polaris/apiserver"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/service"
+)
+
+const (
+	// tcpIdleTimeout TCP 连接上两次请求之间的最大空闲时间
+	tcpIdleTimeout = 10 * time.Second
+	// tcpWriteTimeout TCP 应答的写超时
+	tcpWriteTimeout = 5 * time.Second
+)
+
+// DNSServer 基于 DNS 协议的服务发现 API 服务器，将服务的实例暴露为 A/AAAA/SRV 记录
+type DNSServer struct {
+	cfg *Config
+
+	lock        sync.Mutex
+	udpConn     net.PacketConn
+	tcpListener net.Listener
+
+	namingServer service.DiscoverServer
+	statis       plugin.Statis
+}
+
+// GetPort 获取端口
+func (d *DNSServer) GetPort() uint32 {
+	return d.cfg.ListenPort
+}
+
+// GetProtocol 获取Server的协议
+func (d *DNSServer) GetProtocol() string {
+	return "dns"
+}
+
+// Initialize 初始化 DNS API 服务器
+func (d *DNSServer) Initialize(_ context.Context, option map[string]interface{},
+	_ map[string]apiserver.APIConfig) error {
+	cfg, err := parseConfig(option)
+	if err != nil {
+		return err
+	}
+	d.cfg = cfg
+	return nil
+}
+
+// Run 启动 DNS API 服务器，同时监听 UDP 以及 TCP 端口
+func (d *DNSServer) Run(errCh chan error) {
+	log.Infof("start dnsserver")
+
+	var err error
+	d.namingServer, err = service.GetServer()
+	if err != nil {
+		log.Errorf("%v", err)
+		errCh <- err
+		return
+	}
+	d.statis = plugin.GetStatis()
+
+	address := fmt.Sprintf("%v:%v", d.cfg.ListenIP, d.cfg.ListenPort)
+	udpConn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		log.Errorf("listen udp error: %v", err)
+		errCh <- err
+		return
+	}
+	tcpListener, err := net.Listen("tcp", address)
+	if err != nil {
+		_ = udpConn.Close()
+		log.Errorf("listen tcp error: %v", err)
+		errCh <- err
+		return
+	}
+	d.lock.Lock()
+	d.udpConn = udpConn
+	d.tcpListener = tcpListener
+	d.lock.Unlock()
+
+	go d.serveUDP(udpConn)
+	for {
+		conn, err := tcpListener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Errorf("accept error: %v", err)
+			errCh <- err
+			return
+		}
+		go d.serveTCP(conn)
+	}
+}
+
+// Stop server
+func (d *DNSServer) Stop() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.udpConn != nil {
+		_ = d.udpConn.Close()
+	}
+	if d.tcpListener != nil {
+		_ = d.tcpListener.Close()
+	}
+}
+
+// Restart restart server
+func (d *DNSServer) Restart(option map[string]interface{}, api map[string]apiserver.APIConfig,
+	errCh chan error) error {
+	log.Infof("restart dnsserver with new config: %+v", option)
+
+	d.Stop()
+	if err := d.Initialize(context.Background(), option, api); err != nil {
+		return err
+	}
+	go d.Run(errCh)
+	return nil
+}
+
+func (d *DNSServer) serveUDP(conn net.PacketConn) {
+	buf := make([]byte, MaxUDPSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Errorf("[DNS] read udp packet error: %v", err)
+			continue
+		}
+		req := make([]byte, n)
+		copy(req, buf[:n])
+		go func() {
+			resp := d.handle(context.Background(), req, true)
+			if resp == nil {
+				return
+			}
+			if _, err := conn.WriteTo(resp, addr); err != nil {
+				log.Errorf("[DNS] write udp response to %s error: %v", addr, err)
+			}
+		}()
+	}
+}
+
+// serveTCP TCP 报文前两个字节为报文长度，同一个连接上可以顺序发送多个请求
+func (d *DNSServer) serveTCP(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	lenBuf := make([]byte, 2)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(tcpIdleTimeout))
+		if _, err := io.ReadFull(conn, lenBuf); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint16(lenBuf))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+		resp := d.handle(context.Background(), req, false)
+		if resp == nil {
+			return
+		}
+		out := make([]byte, 2, 2+len(resp))
+		binary.BigEndian.PutUint16(out, uint16(len(resp)))
+		out = append(out, resp...)
+		_ = conn.SetWriteDeadline(time.Now().Add(tcpWriteTimeout))
+		if _, err := conn.Write(out); err != nil {
+			log.Errorf("[DNS] write tcp response to %s error: %v", conn.RemoteAddr(), err)
+			return
+		}
+	}
+}
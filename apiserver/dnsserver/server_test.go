@@ -0,0 +1,218 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dnsserver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/service"
+)
+
+// fakeDiscoverServer 只实现 DNS 服务器依赖的实例查询接口
+type fakeDiscoverServer struct {
+	service.DiscoverServer
+	instances map[string][]*apiservice.Instance
+}
+
+func (f *fakeDiscoverServer) ServiceInstancesCache(_ context.Context, filter *apiservice.DiscoverFilter,
+	req *apiservice.Service) *apiservice.DiscoverResponse {
+	key := req.GetName().GetValue() + "." + req.GetNamespace().GetValue()
+	instances, ok := f.instances[key]
+	if !ok {
+		return api.NewDiscoverInstanceResponse(apimodel.Code_NotFoundResource, req)
+	}
+	resp := api.NewDiscoverInstanceResponse(apimodel.Code_ExecuteSuccess, req)
+	for _, ins := range instances {
+		if filter.GetOnlyHealthyInstance() && !ins.GetHealthy().GetValue() {
+			continue
+		}
+		resp.Instances = append(resp.Instances, ins)
+	}
+	return resp
+}
+
+func newTestInstance(host string, port, weight uint32, healthy, isolate bool) *apiservice.Instance {
+	return &apiservice.Instance{
+		Id:       utils.NewStringValue(host),
+		Host:     utils.NewStringValue(host),
+		Port:     utils.NewUInt32Value(port),
+		Weight:   utils.NewUInt32Value(weight),
+		Priority: utils.NewUInt32Value(0),
+		Healthy:  utils.NewBoolValue(healthy),
+		Isolate:  utils.NewBoolValue(isolate),
+	}
+}
+
+func newTestServer(t *testing.T) *DNSServer {
+	cfg, err := parseConfig(map[string]interface{}{
+		"zone": "Polaris",
+		"ttl":  10,
+	})
+	assert.NoError(t, err)
+	return &DNSServer{
+		cfg: cfg,
+		namingServer: &fakeDiscoverServer{
+			instances: map[string][]*apiservice.Instance{
+				"echo.default": {
+					newTestInstance("10.0.0.1", 8080, 100, true, false),
+					newTestInstance("10.0.0.2", 8080, 50, true, false),
+					newTestInstance("10.0.0.3", 8080, 100, false, false),
+					newTestInstance("10.0.0.4", 8080, 100, true, true),
+					newTestInstance("fe80::1", 9090, 100, true, false),
+				},
+				"a.b.prod": {
+					newTestInstance("db.example.com", 3306, 100, true, false),
+				},
+			},
+		},
+	}
+}
+
+func query(t *testing.T, svr *DNSServer, name string, qtype dnsmessage.Type, udp bool) *dnsmessage.Message {
+	req := &dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 1, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(name),
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	buf, err := req.Pack()
+	assert.NoError(t, err)
+	out := svr.handle(context.Background(), buf, udp)
+	assert.NotNil(t, out)
+	resp := &dnsmessage.Message{}
+	assert.NoError(t, resp.Unpack(out))
+	assert.Equal(t, uint16(1), resp.Header.ID)
+	assert.True(t, resp.Header.Response)
+	return resp
+}
+
+func TestDNSServer_Handle(t *testing.T) {
+	svr := newTestServer(t)
+
+	t.Run("A 记录只返回健康且没有隔离的实例", func(t *testing.T) {
+		resp := query(t, svr, "echo.default.polaris.", dnsmessage.TypeA, true)
+		assert.Equal(t, dnsmessage.RCodeSuccess, resp.Header.RCode)
+		assert.True(t, resp.Header.Authoritative)
+		ips := make([]string, 0, len(resp.Answers))
+		for _, rr := range resp.Answers {
+			assert.Equal(t, uint32(10), rr.Header.TTL)
+			a := rr.Body.(*dnsmessage.AResource)
+			ips = append(ips, net.IP(a.A[:]).String())
+		}
+		assert.ElementsMatch(t, []string{"10.0.0.1", "10.0.0.2"}, ips)
+	})
+
+	t.Run("AAAA 记录", func(t *testing.T) {
+		resp := query(t, svr, "echo.default.polaris.", dnsmessage.TypeAAAA, true)
+		assert.Equal(t, dnsmessage.RCodeSuccess, resp.Header.RCode)
+		assert.Len(t, resp.Answers, 1)
+		aaaa := resp.Answers[0].Body.(*dnsmessage.AAAAResource)
+		assert.Equal(t, "fe80::1", net.IP(aaaa.AAAA[:]).String())
+	})
+
+	t.Run("SRV 记录携带端口和权重", func(t *testing.T) {
+		resp := query(t, svr, "_echo._tcp.default.polaris.", dnsmessage.TypeSRV, true)
+		assert.Equal(t, dnsmessage.RCodeSuccess, resp.Header.RCode)
+		assert.Len(t, resp.Answers, 3)
+		weights := map[string]uint16{}
+		for _, rr := range resp.Answers {
+			srv := rr.Body.(*dnsmessage.SRVResource)
+			weights[srv.Target.String()] = srv.Weight
+		}
+		assert.Equal(t, map[string]uint16{
+			"0a000001.addr.polaris.":                         100,
+			"0a000002.addr.polaris.":                         50,
+			"fe800000000000000000000000000001.addr.polaris.": 100,
+		}, weights)
+		assert.Len(t, resp.Additionals, 3)
+
+		// SRV 的目标域名可以解析到具体实例的地址
+		resp = query(t, svr, "0a000002.addr.polaris.", dnsmessage.TypeA, true)
+		assert.Len(t, resp.Answers, 1)
+		assert.Equal(t, [4]byte{10, 0, 0, 2}, resp.Answers[0].Body.(*dnsmessage.AResource).A)
+
+		// 实例地址为域名时直接作为目标域名，服务名可以包含 '.'
+		resp = query(t, svr, "a.b.prod.polaris.", dnsmessage.TypeSRV, true)
+		assert.Len(t, resp.Answers, 1)
+		srv := resp.Answers[0].Body.(*dnsmessage.SRVResource)
+		assert.Equal(t, "db.example.com.", srv.Target.String())
+		assert.Equal(t, uint16(3306), srv.Port)
+	})
+
+	t.Run("服务不存在返回 NXDOMAIN", func(t *testing.T) {
+		resp := query(t, svr, "unknown.default.polaris.", dnsmessage.TypeA, true)
+		assert.Equal(t, dnsmessage.RCodeNameError, resp.Header.RCode)
+		assert.Len(t, resp.Authorities, 1)
+		soa := resp.Authorities[0].Body.(*dnsmessage.SOAResource)
+		assert.Equal(t, uint32(DefaultNegativeTTL), soa.MinTTL)
+
+		resp = query(t, svr, "polaris.", dnsmessage.TypeA, true)
+		assert.Equal(t, dnsmessage.RCodeSuccess, resp.Header.RCode)
+		assert.Empty(t, resp.Answers)
+	})
+
+	t.Run("不属于当前 zone 的域名拒绝查询", func(t *testing.T) {
+		resp := query(t, svr, "www.example.com.", dnsmessage.TypeA, true)
+		assert.Equal(t, dnsmessage.RCodeRefused, resp.Header.RCode)
+		assert.False(t, resp.Header.Authoritative)
+	})
+}
+
+func TestDNSServer_Truncate(t *testing.T) {
+	svr := newTestServer(t)
+	instances := make([]*apiservice.Instance, 0, 64)
+	for i := 0; i < 64; i++ {
+		instances = append(instances, newTestInstance(net.IPv4(10, 1, 0, byte(i)).String(), 8080, 100, true, false))
+	}
+	svr.namingServer.(*fakeDiscoverServer).instances["big.default"] = instances
+
+	resp := query(t, svr, "big.default.polaris.", dnsmessage.TypeA, true)
+	assert.True(t, resp.Header.Truncated)
+	assert.Empty(t, resp.Answers)
+
+	resp = query(t, svr, "big.default.polaris.", dnsmessage.TypeA, false)
+	assert.False(t, resp.Header.Truncated)
+	assert.Len(t, resp.Answers, 64)
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := parseConfig(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultZone, cfg.Zone)
+	assert.Equal(t, uint32(DefaultListenPort), cfg.ListenPort)
+
+	cfg, err = parseConfig(map[string]interface{}{"zone": ".svc.local.", "listenPort": 53, "negativeTtl": 5})
+	assert.NoError(t, err)
+	assert.Equal(t, "svc.local.", cfg.Zone)
+	assert.Equal(t, uint32(53), cfg.ListenPort)
+	assert.Equal(t, uint32(5), cfg.NegativeTTL)
+
+	_, err = parseConfig(map[string]interface{}{"zone": "."})
+	assert.Error(t, err)
+}
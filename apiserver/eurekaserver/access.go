@@ -31,12 +31,15 @@ import (
 )
 
 const (
-	ParamAppId      string = "appId"
-	ParamInstId     string = "instId"
-	ParamValue      string = "value"
-	ParamVip        string = "vipAddress"
-	ParamSVip       string = "svipAddress"
-	HeaderNamespace string = "x-namespace"
+	ParamAppId            string = "appId"
+	ParamInstId           string = "instId"
+	ParamValue            string = "value"
+	ParamVip              string = "vipAddress"
+	ParamSVip             string = "svipAddress"
+	ParamRegions          string = "regions"
+	ParamStatus           string = "status"
+	ParamOverriddenStatus string = "overriddenstatus"
+	HeaderNamespace       string = "x-namespace"
 )
 
 // GetEurekaServer eureka web server
@@ -133,7 +136,12 @@ func parseAcceptValue(acceptValue string) map[string]bool {
 // GetAllApplications 全量拉取服务实例信息
 func (h *EurekaServer) GetAllApplications(req *restful.Request, rsp *restful.Response) {
 	namespace := readNamespaceFromRequest(req, h.namespace)
-	appsRespCache := h.workers.Get(namespace).GetCachedAppsWithLoad()
+	var appsRespCache *ApplicationsRespCache
+	if regions := parseRegions(req.QueryParameter(ParamRegions), h.region); h.isRegionAware(regions) {
+		appsRespCache = h.getRegionApps(namespace, regions, false)
+	} else {
+		appsRespCache = h.workers.Get(namespace).GetCachedAppsWithLoad()
+	}
 	remoteAddr := req.Request.RemoteAddr
 	acceptValue := getParamFromEurekaRequestHeader(req, restful.HEADER_Accept)
 	if err := writeResponse(parseAcceptValue(acceptValue), appsRespCache, req, rsp); nil != err {
@@ -276,14 +284,11 @@ func writeResponse(acceptValues map[string]bool, appsRespCache *ApplicationsResp
 // GetDeltaApplications 增量拉取服务实例信息
 func (h *EurekaServer) GetDeltaApplications(req *restful.Request, rsp *restful.Response) {
 	namespace := readNamespaceFromRequest(req, h.namespace)
-	work := h.workers.Get(namespace)
-	appsRespCache := work.GetDeltaApps()
-	if nil == appsRespCache {
-		ctx := work.StartWorker()
-		if nil != ctx {
-			<-ctx.Done()
-		}
-		appsRespCache = work.GetDeltaApps()
+	var appsRespCache *ApplicationsRespCache
+	if regions := parseRegions(req.QueryParameter(ParamRegions), h.region); h.isRegionAware(regions) {
+		appsRespCache = h.getRegionApps(namespace, regions, true)
+	} else {
+		appsRespCache = h.loadApps(namespace, true)
 	}
 	remoteAddr := req.Request.RemoteAddr
 	acceptValue := getParamFromEurekaRequestHeader(req, restful.HEADER_Accept)
@@ -436,8 +441,15 @@ func (h *EurekaServer) UpdateStatus(req *restful.Request, rsp *restful.Response)
 		writeHeader(http.StatusOK, rsp)
 		return
 	}
+	if !isValidStatus(status) {
+		eurekalog.Errorf("[EUREKA-SERVER] fail to parse request uri, uri: %s, client: %s, err: invalid status %s",
+			req.Request.RequestURI, remoteAddr, status)
+		writePolarisStatusCode(req, api.InvalidParameter)
+		writeHeader(http.StatusBadRequest, rsp)
+		return
+	}
 	ctx := context.WithValue(context.Background(), sourceFromEureka{}, true)
-	code := h.updateStatus(ctx, namespace, appId, instId, status, false)
+	code := h.updateStatus(ctx, namespace, appId, instId, status, isReplicationRequest(req))
 	writePolarisStatusCode(req, code)
 	if code == api.ExecuteSuccess || code == api.NoNeedUpdate {
 		eurekalog.Infof("[EUREKA-SERVER] instance (namespace=%s, instId=%s, appId=%s) has been updated successfully",
@@ -448,14 +460,14 @@ func (h *EurekaServer) UpdateStatus(req *restful.Request, rsp *restful.Response)
 	eurekalog.Errorf("[EUREKA-SERVER] instance (namespace=%s, instId=%s, appId=%s) has been updated failed, "+
 		"code is %d",
 		namespace, instId, appId, code)
-	if code == api.NotFoundResource {
+	if code == api.NotFoundResource || code == api.NotFoundInstance {
 		writeHeader(http.StatusNotFound, rsp)
 		return
 	}
 	writeHeader(int(code/1000), rsp)
 }
 
-// DeleteStatus 关闭强制隔离
+// DeleteStatus 删除实例的覆盖状态，value 参数为删除后实例的状态，默认为 UNKNOWN
 func (h *EurekaServer) DeleteStatus(req *restful.Request, rsp *restful.Response) {
 	remoteAddr := req.Request.RemoteAddr
 	appId := readAppIdFromRequest(req)
@@ -476,15 +488,19 @@ func (h *EurekaServer) DeleteStatus(req *restful.Request, rsp *restful.Response)
 	}
 
 	namespace := readNamespaceFromRequest(req, h.namespace)
+	status := req.QueryParameter(ParamValue)
+	if len(status) == 0 {
+		status = StatusUnknown
+	}
 
 	eurekalog.Infof("[EUREKA-SERVER]received instance status delete request, "+
-		"client: %s,namespace=%s, instId=%s, appId=%s",
-		remoteAddr, namespace, instId, appId)
+		"client: %s,namespace=%s, instId=%s, appId=%s, status=%s",
+		remoteAddr, namespace, instId, appId, status)
 
 	ctx := context.WithValue(context.Background(), sourceFromEureka{}, true)
-	code := h.updateStatus(ctx, namespace, appId, instId, StatusUp, false)
+	code := h.deleteStatusOverride(ctx, namespace, appId, instId, status, isReplicationRequest(req))
 	writePolarisStatusCode(req, code)
-	if code == api.ExecuteSuccess {
+	if code == api.ExecuteSuccess || code == api.NoNeedUpdate {
 		eurekalog.Infof("[EUREKA-SERVER]instance status (namespace=%s, instId=%s, appId=%s) "+
 			"has been deleted successfully",
 			namespace, instId, appId)
@@ -494,7 +510,7 @@ func (h *EurekaServer) DeleteStatus(req *restful.Request, rsp *restful.Response)
 	eurekalog.Errorf("[EUREKA-SERVER]instance status (namespace=%s, instId=%s, appId=%s) "+
 		"has been deleted failed, code is %d",
 		namespace, instId, appId, code)
-	if code == api.NotFoundResource {
+	if code == api.NotFoundResource || code == api.NotFoundInstance {
 		writeHeader(http.StatusNotFound, rsp)
 		return
 	}
@@ -521,7 +537,10 @@ func (h *EurekaServer) RenewInstance(req *restful.Request, rsp *restful.Response
 		return
 	}
 	namespace := readNamespaceFromRequest(req, h.namespace)
-	code := h.renew(context.Background(), namespace, appId, instId, false)
+	status := req.QueryParameter(ParamStatus)
+	overriddenStatus := req.QueryParameter(ParamOverriddenStatus)
+	code := h.renew(context.Background(), namespace, appId, instId, status, overriddenStatus,
+		isReplicationRequest(req))
 	writePolarisStatusCode(req, code)
 	if code == api.ExecuteSuccess || code == api.HeartbeatExceedLimit {
 		writeHeader(http.StatusOK, rsp)
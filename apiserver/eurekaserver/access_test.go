@@ -367,6 +367,62 @@ func Test_EurekaWrite(t *testing.T) {
 			assert.Equal(t, StatusOutOfService, saveInss[0].Proto.Metadata[InternalMetadataStatus])
 		})
 	})
+
+	t.Run("OverriddenStatus", func(t *testing.T) {
+		t.Run("01_UpdateStatus", func(t *testing.T) {
+			mockReq := httptest.NewRequest("", fmt.Sprintf("http://127.0.0.1:8761/eureka/v2/apps/%s/%s/status",
+				mockIns.AppName, mockIns.InstanceId), nil)
+			mockReq.PostForm = url.Values{}
+			mockReq.PostForm.Add(ParamValue, StatusOutOfService)
+			mockRsp := newMockResponseWriter()
+
+			restfulReq := restful.NewRequest(mockReq)
+			injectRestfulReqPathParameters(t, restfulReq, map[string]string{
+				ParamAppId:  mockIns.AppName,
+				ParamInstId: mockIns.InstanceId,
+			})
+			eurekaSrv.UpdateStatus(restfulReq, restful.NewResponse(mockRsp))
+			assert.Equal(t, http.StatusOK, mockRsp.statusCode)
+
+			saveIns, err := discoverSuit.Storage.GetInstance(mockIns.InstanceId)
+			assert.NoError(t, err)
+			assert.True(t, saveIns.Isolate())
+			assert.Equal(t, StatusOutOfService, saveIns.Proto.Metadata[InternalMetadataOverriddenStatus])
+		})
+
+		t.Run("02_ReRegisterKeepOverride", func(t *testing.T) {
+			code := eurekaSrv.registerInstances(context.Background(), "default", mockIns.AppName, mockIns, false)
+			assert.Equal(t, api.ExecuteSuccess, code)
+
+			saveIns, err := discoverSuit.Storage.GetInstance(mockIns.InstanceId)
+			assert.NoError(t, err)
+			assert.True(t, saveIns.Isolate())
+			assert.Equal(t, StatusOutOfService, saveIns.Proto.Metadata[InternalMetadataStatus])
+			assert.Equal(t, StatusOutOfService, saveIns.Proto.Metadata[InternalMetadataOverriddenStatus])
+		})
+
+		t.Run("03_DeleteStatus", func(t *testing.T) {
+			mockReq := httptest.NewRequest("", fmt.Sprintf("http://127.0.0.1:8761/eureka/v2/apps/%s/%s/status",
+				mockIns.AppName, mockIns.InstanceId), nil)
+			mockReq.PostForm = url.Values{}
+			mockReq.PostForm.Add(ParamValue, StatusUp)
+			mockRsp := newMockResponseWriter()
+
+			restfulReq := restful.NewRequest(mockReq)
+			injectRestfulReqPathParameters(t, restfulReq, map[string]string{
+				ParamAppId:  mockIns.AppName,
+				ParamInstId: mockIns.InstanceId,
+			})
+			eurekaSrv.DeleteStatus(restfulReq, restful.NewResponse(mockRsp))
+			assert.Equal(t, http.StatusOK, mockRsp.statusCode)
+
+			saveIns, err := discoverSuit.Storage.GetInstance(mockIns.InstanceId)
+			assert.NoError(t, err)
+			assert.False(t, saveIns.Isolate())
+			assert.Equal(t, StatusUp, saveIns.Proto.Metadata[InternalMetadataStatus])
+			assert.Equal(t, StatusUnknown, saveIns.Proto.Metadata[InternalMetadataOverriddenStatus])
+		})
+	})
 }
 
 func injectRestfulReqPathParameters(t *testing.T, req *restful.Request, params map[string]string) {
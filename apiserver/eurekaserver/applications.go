@@ -190,8 +190,8 @@ func parseStatus(instance *apiservice.Instance) string {
 	}
 	status := instance.Metadata[InternalMetadataStatus]
 	switch status {
-	case StatusDown:
-		return StatusDown
+	case StatusDown, StatusStarting, StatusUnknown:
+		return status
 	default:
 		return StatusOutOfService
 	}
@@ -323,7 +323,7 @@ func buildInstance(appName string, instance *apiservice.Instance, lastModifyTime
 	}
 	instanceInfo.IpAddr = instance.GetHost().GetValue()
 	instanceInfo.Status = parseStatus(instance)
-	instanceInfo.OverriddenStatus = StatusUnknown
+	instanceInfo.OverriddenStatus = normalizeOverriddenStatus(metadata[InternalMetadataOverriddenStatus])
 	parsePortWrapper(instanceInfo, instance)
 	if countryIdStr, ok := metadata[MetadataCountryId]; ok {
 		cId, err := strconv.Atoi(countryIdStr)
@@ -415,6 +415,12 @@ func buildLocationInfo(instanceInfo *InstanceInfo, instance *apiservice.Instance
 	if _, ok := instanceInfo.Metadata.Meta[keyCampus]; !ok && len(campus) > 0 {
 		instanceInfo.Metadata.Meta[keyCampus] = campus
 	}
+	if len(zone) > 0 {
+		// 默认的 DataCenterInfo 是共享的，需要拷贝后再设置可用区
+		dataCenterInfo := *instanceInfo.DataCenterInfo
+		dataCenterInfo.Metadata = &DataCenterMetadata{AvailabilityZone: zone}
+		instanceInfo.DataCenterInfo = &dataCenterInfo
+	}
 }
 
 func newApplications() *Applications {
@@ -31,6 +31,8 @@ const (
 	optionPeerNodesToReplicate   = "peersToReplicate"
 	optionCustomValues           = "customValues"
 	optionGenerateUniqueInstId   = "generateUniqueInstId"
+	optionRegion                 = "region"
+	optionRemoteRegions          = "remoteRegions"
 )
 
 const (
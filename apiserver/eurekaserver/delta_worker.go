@@ -127,6 +127,10 @@ type ApplicationsWorker struct {
 	vipCacheMutex *sync.RWMutex
 	// vip数据缓存，数据格式为VipCacheKey:ApplicationsRespCache
 	vipCache map[VipCacheKey]*ApplicationsRespCache
+	// region缓存同步
+	regionCacheMutex *sync.RWMutex
+	// 多region合并后的数据缓存，数据格式为RegionCacheKey:ApplicationsRespCache
+	regionCache map[RegionCacheKey]*ApplicationsRespCache
 
 	appBuilder *ApplicationsBuilder
 
@@ -153,6 +157,8 @@ func NewApplicationsWorker(interval time.Duration,
 		deltaCache:          &atomic.Value{},
 		vipCacheMutex:       &sync.RWMutex{},
 		vipCache:            make(map[VipCacheKey]*ApplicationsRespCache),
+		regionCacheMutex:    &sync.RWMutex{},
+		regionCache:         make(map[RegionCacheKey]*ApplicationsRespCache),
 		healthCheckServer:   healthCheckServer,
 		appBuilder:          appBuilder,
 		leases:              make([]*Lease, 0),
@@ -234,6 +240,26 @@ func (a *ApplicationsWorker) GetVipApps(key VipCacheKey) *ApplicationsRespCache
 	return res
 }
 
+// GetRegionApps 从缓存中读取多region合并后的数据，不存在时进行构建，
+// 构建时可能需要读取其他命名空间的数据，因此不能持有锁
+func (a *ApplicationsWorker) GetRegionApps(key RegionCacheKey,
+	builder func() *ApplicationsRespCache) *ApplicationsRespCache {
+	a.regionCacheMutex.RLock()
+	res, ok := a.regionCache[key]
+	a.regionCacheMutex.RUnlock()
+	if ok {
+		return res
+	}
+	res = builder()
+	a.regionCacheMutex.Lock()
+	defer a.regionCacheMutex.Unlock()
+	if exist, ok := a.regionCache[key]; ok {
+		return exist
+	}
+	a.regionCache[key] = res
+	return res
+}
+
 func (a *ApplicationsWorker) timingReloadAppsCache(workerCtx context.Context) {
 	ticker := time.NewTicker(a.interval)
 	defer ticker.Stop()
@@ -248,6 +274,7 @@ func (a *ApplicationsWorker) timingReloadAppsCache(workerCtx context.Context) {
 			a.appsCache.Store(newApps)
 			a.deltaCache.Store(newDeltaApps)
 			a.clearExpiredVipResources()
+			a.clearExpiredRegionResources()
 		}
 	}
 }
@@ -264,6 +291,18 @@ func (a *ApplicationsWorker) clearExpiredVipResources() {
 	}
 }
 
+func (a *ApplicationsWorker) clearExpiredRegionResources() {
+	expireIntervalSec := int64(a.interval / time.Second)
+	a.regionCacheMutex.Lock()
+	defer a.regionCacheMutex.Unlock()
+	for key, respCache := range a.regionCache {
+		curTimeSec := time.Now().Unix()
+		if curTimeSec-respCache.createTimeSec >= expireIntervalSec {
+			delete(a.regionCache, key)
+		}
+	}
+}
+
 func diffApplicationInstances(curTimeSec int64, oldApplication *Application, newApplication *Application) []*Lease {
 	var out []*Lease
 	oldRevision := oldApplication.Revision
@@ -107,6 +107,20 @@ type DataCenterInfo struct {
 	Clazz string `json:"@class" xml:"class,attr"`
 
 	Name string `json:"name" xml:"name"`
+
+	Metadata *DataCenterMetadata `json:"metadata,omitempty" xml:"metadata,omitempty"`
+}
+
+func (d *DataCenterInfo) availabilityZone() string {
+	if d == nil || d.Metadata == nil {
+		return ""
+	}
+	return d.Metadata.AvailabilityZone
+}
+
+// DataCenterMetadata 数据中心元数据，客户端通过 availability-zone 识别实例所在的可用区
+type DataCenterMetadata struct {
+	AvailabilityZone string `json:"availability-zone,omitempty" xml:"availability-zone,omitempty"`
 }
 
 // LeaseInfo 租约信息
@@ -327,6 +341,7 @@ func (i *InstanceInfo) Clone(actionType string) *InstanceInfo {
 		LastUpdatedTimestamp:          i.LastUpdatedTimestamp,
 		LastDirtyTimestamp:            i.LastDirtyTimestamp,
 		ActionType:                    actionType,
+		RealInstance:                  i.RealInstance,
 	}
 }
 
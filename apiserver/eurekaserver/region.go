@@ -0,0 +1,180 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package eurekaserver
+
+import (
+	"sort"
+	"strings"
+)
+
+// RegionCacheKey key for reference the region cache
+type RegionCacheKey struct {
+	delta   bool
+	regions string
+}
+
+// regionSource 某个 region 的服务数据来源
+type regionSource struct {
+	apps   *Applications
+	filter func(instance *InstanceInfo) bool
+}
+
+// parseRegions 解析 ?regions= 参数，去重并排序，保证相同的 regions 命中同一份缓存
+func parseRegions(value string, localRegion string) []string {
+	if len(value) == 0 {
+		return nil
+	}
+	exists := make(map[string]struct{})
+	regions := make([]string, 0, 4)
+	for _, region := range strings.Split(value, ",") {
+		region = strings.ToLower(strings.TrimSpace(region))
+		if len(region) == 0 || region == localRegion {
+			continue
+		}
+		if _, ok := exists[region]; ok {
+			continue
+		}
+		exists[region] = struct{}{}
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// instanceRegion 实例所属的 eureka region，使用 CMDB 中实例的地域信息，没有地域信息的实例属于本地 region
+func instanceRegion(instance *InstanceInfo, localRegion string) string {
+	if region := instance.RealInstance.GetLocation().GetRegion().GetValue(); len(region) > 0 {
+		return strings.ToLower(region)
+	}
+	return localRegion
+}
+
+// regionAppsLoader 获取某个命名空间下的全量或者增量服务数据
+type regionAppsLoader func(namespace string, delta bool) *ApplicationsRespCache
+
+// buildRegionSources 计算本地 region 以及 remote regions 对应的服务数据来源：
+// 1. 配置了 remoteRegions 映射的 region，使用映射的北极星命名空间下的全部实例
+// 2. 其余的 region，使用当前命名空间下 CMDB 地域信息为该 region 的实例
+func (h *EurekaServer) buildRegionSources(namespace string, regions []string, delta bool,
+	loader regionAppsLoader) []*regionSource {
+	localApps := loader(namespace, delta).AppsResp.Applications
+	sources := []*regionSource{
+		{
+			apps: localApps,
+			filter: func(instance *InstanceInfo) bool {
+				return len(h.region) == 0 || instanceRegion(instance, h.region) == h.region
+			},
+		},
+	}
+	for i := range regions {
+		region := regions[i]
+		if remoteNamespace, ok := h.remoteRegions[region]; ok && remoteNamespace != namespace {
+			sources = append(sources, &regionSource{
+				apps: loader(remoteNamespace, delta).AppsResp.Applications,
+			})
+			continue
+		}
+		sources = append(sources, &regionSource{
+			apps: localApps,
+			filter: func(instance *InstanceInfo) bool {
+				return instanceRegion(instance, h.region) == region
+			},
+		})
+	}
+	return sources
+}
+
+// BuildApplicationsForRegions 合并多个 region 的服务数据，同一个实例只会返回一次
+func BuildApplicationsForRegions(versionsDelta string, sources []*regionSource,
+	delta bool) (*Applications, int) {
+	toReturn := newApplications()
+	toReturn.VersionsDelta = versionsDelta
+	hashBuilder := make(map[string]int)
+	var instCount int
+	for _, source := range sources {
+		for _, application := range source.apps.Application {
+			for _, instance := range application.Instance {
+				if source.filter != nil && !source.filter(instance) {
+					continue
+				}
+				appToAdd, ok := toReturn.ApplicationMap[application.Name]
+				if !ok {
+					appToAdd = &Application{
+						Name:         application.Name,
+						InstanceMap:  make(map[string]*InstanceInfo),
+						StatusCounts: make(map[string]int),
+					}
+					toReturn.Application = append(toReturn.Application, appToAdd)
+					toReturn.ApplicationMap[application.Name] = appToAdd
+				}
+				if _, exist := appToAdd.InstanceMap[instance.InstanceId]; exist {
+					continue
+				}
+				appToAdd.Instance = append(appToAdd.Instance, instance)
+				appToAdd.InstanceMap[instance.InstanceId] = instance
+				instCount++
+				if !delta {
+					appToAdd.StatusCounts[instance.Status] = appToAdd.StatusCounts[instance.Status] + 1
+					hashBuilder[instance.Status] = hashBuilder[instance.Status] + 1
+				}
+			}
+		}
+	}
+	if !delta {
+		toReturn.AppsHashCode = buildHashStr(hashBuilder)
+	}
+	return toReturn, instCount
+}
+
+// isRegionAware 配置了本地 region 或者客户端拉取 remote regions 时，需要按照 region 构建服务数据
+func (h *EurekaServer) isRegionAware(regions []string) bool {
+	return len(h.region) > 0 || len(regions) > 0
+}
+
+// getRegionApps 获取本地 region 以及 remote regions 合并后的全量或者增量服务数据
+func (h *EurekaServer) getRegionApps(namespace string, regions []string, delta bool) *ApplicationsRespCache {
+	key := RegionCacheKey{delta: delta, regions: strings.Join(regions, ",")}
+	return h.workers.Get(namespace).GetRegionApps(key, func() *ApplicationsRespCache {
+		sources := h.buildRegionSources(namespace, regions, delta, h.loadApps)
+		localApps := sources[0].apps
+		apps, instCount := BuildApplicationsForRegions(localApps.VersionsDelta, sources, delta)
+		if delta {
+			// 客户端合并增量数据后，会使用合并后的全量数据计算 hashcode 进行校验
+			fullApps, _ := BuildApplicationsForRegions(localApps.VersionsDelta,
+				h.buildRegionSources(namespace, regions, false, h.loadApps), false)
+			apps.AppsHashCode = fullApps.AppsHashCode
+		}
+		return constructResponseCache(apps, instCount, delta)
+	})
+}
+
+func (h *EurekaServer) loadApps(namespace string, delta bool) *ApplicationsRespCache {
+	work := h.workers.Get(namespace)
+	if !delta {
+		return work.GetCachedAppsWithLoad()
+	}
+	appsRespCache := work.GetDeltaApps()
+	if nil == appsRespCache {
+		ctx := work.StartWorker()
+		if nil != ctx {
+			<-ctx.Done()
+		}
+		appsRespCache = work.GetDeltaApps()
+	}
+	return appsRespCache
+}
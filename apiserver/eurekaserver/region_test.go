@@ -0,0 +1,175 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package eurekaserver
+
+import (
+	"testing"
+
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestParseRegions(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		local  string
+		expect []string
+	}{
+		{name: "empty", value: "", expect: nil},
+		{name: "sort-and-dedupe", value: "us-west, ap-east,US-WEST", expect: []string{"ap-east", "us-west"}},
+		{name: "skip-local", value: "us-west,ap-east", local: "ap-east", expect: []string{"us-west"}},
+		{name: "only-local", value: "ap-east", local: "ap-east", expect: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, parseRegions(tt.value, tt.local))
+		})
+	}
+}
+
+func TestParseRemoteRegions(t *testing.T) {
+	ret := parseRemoteRegions(map[interface{}]interface{}{
+		"US-West": "us-west-ns",
+		"empty":   "",
+	})
+	assert.Equal(t, map[string]string{"us-west": "us-west-ns"}, ret)
+	assert.Empty(t, parseRemoteRegions(nil))
+}
+
+func buildRegionInstance(appName string, id string, region string, status string) *InstanceInfo {
+	return &InstanceInfo{
+		InstanceId: id,
+		AppName:    appName,
+		Status:     status,
+		RealInstance: &apiservice.Instance{
+			Location: &apimodel.Location{Region: wrapperspb.String(region)},
+		},
+	}
+}
+
+func buildRegionApplications(instances ...*InstanceInfo) *ApplicationsRespCache {
+	apps := newApplications()
+	for _, instance := range instances {
+		app, ok := apps.ApplicationMap[instance.AppName]
+		if !ok {
+			app = &Application{Name: instance.AppName, InstanceMap: map[string]*InstanceInfo{}}
+			apps.ApplicationMap[instance.AppName] = app
+			apps.Application = append(apps.Application, app)
+		}
+		app.Instance = append(app.Instance, instance)
+		app.InstanceMap[instance.InstanceId] = instance
+	}
+	apps.VersionsDelta = "1"
+	return &ApplicationsRespCache{AppsResp: &ApplicationsResponse{Applications: apps}}
+}
+
+func TestBuildApplicationsForRegions(t *testing.T) {
+	namespaceApps := map[string]*ApplicationsRespCache{
+		"default": buildRegionApplications(
+			buildRegionInstance("APP", "local-1", "", StatusUp),
+			buildRegionInstance("APP", "local-2", "ap-east", StatusUp),
+			buildRegionInstance("APP", "cmdb-1", "eu-west", StatusDown),
+		),
+		"us-west-ns": buildRegionApplications(
+			buildRegionInstance("APP", "remote-1", "", StatusUp),
+			buildRegionInstance("OTHER", "remote-2", "", StatusUp),
+		),
+	}
+	loader := func(namespace string, delta bool) *ApplicationsRespCache {
+		return namespaceApps[namespace]
+	}
+
+	tests := []struct {
+		name      string
+		region    string
+		regions   []string
+		expectIds map[string][]string
+		hashCode  string
+	}{
+		{
+			name:      "no-local-region",
+			expectIds: map[string][]string{"APP": {"local-1", "local-2", "cmdb-1"}},
+			hashCode:  "DOWN_1_UP_2_",
+		},
+		{
+			name:      "local-region-only",
+			region:    "ap-east",
+			expectIds: map[string][]string{"APP": {"local-1", "local-2"}},
+			hashCode:  "UP_2_",
+		},
+		{
+			name:    "remote-namespace-and-cmdb-region",
+			region:  "ap-east",
+			regions: []string{"eu-west", "us-west"},
+			expectIds: map[string][]string{
+				"APP":   {"local-1", "local-2", "cmdb-1", "remote-1"},
+				"OTHER": {"remote-2"},
+			},
+			hashCode: "DOWN_1_UP_4_",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &EurekaServer{
+				region:        tt.region,
+				remoteRegions: map[string]string{"us-west": "us-west-ns"},
+			}
+			sources := h.buildRegionSources("default", tt.regions, false, loader)
+			apps, count := BuildApplicationsForRegions("1", sources, false)
+			ids := map[string][]string{}
+			total := 0
+			for _, app := range apps.Application {
+				for _, instance := range app.Instance {
+					ids[app.Name] = append(ids[app.Name], instance.InstanceId)
+					total++
+				}
+			}
+			assert.Equal(t, tt.expectIds, ids)
+			assert.Equal(t, total, count)
+			assert.Equal(t, tt.hashCode, apps.AppsHashCode)
+			assert.Equal(t, "1", apps.VersionsDelta)
+		})
+	}
+}
+
+func TestBuildLocationInfo_Zone(t *testing.T) {
+	instance := &apiservice.Instance{
+		Id:       wrapperspb.String("ins-1"),
+		Host:     wrapperspb.String("127.0.0.1"),
+		Port:     wrapperspb.UInt32(8080),
+		Location: &apimodel.Location{Region: wrapperspb.String("ap-east"), Zone: wrapperspb.String("ap-east-1")},
+	}
+	info := buildInstance("APP", instance, 0)
+	assert.Equal(t, "ap-east-1", info.DataCenterInfo.availabilityZone())
+	assert.Equal(t, "ap-east-1", info.Metadata.Meta[keyZone])
+	assert.Nil(t, DefaultDataCenterInfo.Metadata)
+
+	registered := buildBaseInstance(&InstanceInfo{
+		InstanceId: "ins-1",
+		IpAddr:     "127.0.0.1",
+		DataCenterInfo: &DataCenterInfo{
+			Clazz:    "com.netflix.appinfo.AmazonInfo",
+			Name:     "Amazon",
+			Metadata: &DataCenterMetadata{AvailabilityZone: "us-east-1a"},
+		},
+	}, "default", "default", "APP", false)
+	assert.Equal(t, "us-east-1a", registered.GetLocation().GetZone().GetValue())
+}
@@ -48,8 +48,15 @@ const (
 	headerIdentityVersion = "DiscoveryIdentity-Version"
 	headerIdentityId      = "DiscoveryIdentity-Id"
 	valueIdentityName     = "PolarisServer"
+	headerReplication     = "x-netflix-discovery-replication"
 )
 
+// isReplicationRequest 判断请求是否为 eureka 对端节点同步过来的请求
+func isReplicationRequest(req *restful.Request) bool {
+	isReplication, _ := strconv.ParseBool(req.HeaderParameter(headerReplication))
+	return isReplication
+}
+
 // BatchReplication do the server request replication
 func (h *EurekaServer) BatchReplication(req *restful.Request, rsp *restful.Response) {
 	eurekalog.Infof("[EUREKA-SERVER] received replicate request %+v", req)
@@ -138,7 +145,8 @@ func (h *EurekaServer) dispatch(
 		}
 	case actionHeartbeat:
 		instanceId := replicationInstance.Id
-		retCode = h.renew(ctx, namespace, appName, instanceId, true)
+		retCode = h.renew(ctx, namespace, appName, instanceId,
+			replicationInstance.Status, replicationInstance.OverriddenStatus, true)
 		if retCode == api.ExecuteSuccess || retCode == api.HeartbeatExceedLimit {
 			retCode = api.ExecuteSuccess
 		}
@@ -154,7 +162,7 @@ func (h *EurekaServer) dispatch(
 		retCode = h.updateStatus(ctx, namespace, appName, instanceId, status, true)
 	case actionDeleteStatusOverride:
 		instanceId := replicationInstance.Id
-		retCode = h.updateStatus(ctx, namespace, appName, instanceId, StatusUp, true)
+		retCode = h.deleteStatusOverride(ctx, namespace, appName, instanceId, replicationInstance.Status, true)
 	}
 
 	statusCode := http.StatusOK
@@ -244,6 +252,20 @@ func (h *EurekaServer) handleInstanceEvent(ctx context.Context, i interface{}) e
 		eurekaInstanceId = e.MetaData[MetadataInstanceId]
 	}
 	replicateWorker, _ := h.replicateWorkers.Get(namespace)
+	if action, ok := e.MetaData[MetadataStatusAction]; ok {
+		// 覆盖状态变更同时会产生实例更新以及隔离状态变更事件，只需要根据实例更新事件同步一次
+		if e.EType == model.EventInstanceUpdate {
+			replicateWorker.AddReplicateTask(&ReplicationInstance{
+				AppName:            appName,
+				Id:                 eurekaInstanceId,
+				LastDirtyTimestamp: curTimeMilli,
+				Status:             e.Instance.GetMetadata()[InternalMetadataStatus],
+				OverriddenStatus:   e.Instance.GetMetadata()[InternalMetadataOverriddenStatus],
+				Action:             action,
+			})
+		}
+		return nil
+	}
 	switch e.EType {
 	case model.EventInstanceOnline, model.EventInstanceUpdate, model.EventInstanceTurnHealth:
 		instanceInfo := eventToInstance(&e, appName, curTimeMilli)
@@ -264,11 +286,12 @@ func (h *EurekaServer) handleInstanceEvent(ctx context.Context, i interface{}) e
 	case model.EventInstanceSendHeartbeat:
 		instanceInfo := eventToInstance(&e, appName, curTimeMilli)
 		rInstance := &ReplicationInstance{
-			AppName:      appName,
-			Id:           eurekaInstanceId,
-			Status:       instanceInfo.Status,
-			InstanceInfo: instanceInfo,
-			Action:       actionHeartbeat,
+			AppName:          appName,
+			Id:               eurekaInstanceId,
+			Status:           instanceInfo.Status,
+			OverriddenStatus: instanceInfo.OverriddenStatus,
+			InstanceInfo:     instanceInfo,
+			Action:           actionHeartbeat,
 		}
 		replicateWorker.AddReplicateTask(rInstance)
 	case model.EventInstanceOpenIsolate, model.EventInstanceCloseIsolate:
@@ -277,6 +300,7 @@ func (h *EurekaServer) handleInstanceEvent(ctx context.Context, i interface{}) e
 			Id:                 eurekaInstanceId,
 			LastDirtyTimestamp: curTimeMilli,
 			Status:             parseStatus(e.Instance),
+			OverriddenStatus:   normalizeOverriddenStatus(e.Instance.GetMetadata()[InternalMetadataOverriddenStatus]),
 			Action:             actionStatusUpdate,
 		})
 
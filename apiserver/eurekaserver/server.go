@@ -65,6 +65,7 @@ const (
 	MetadataSecurePortEnabled   = "internal-eureka-secure-port-enabled"
 	MetadataReplicate           = "internal-eureka-replicate"
 	MetadataInstanceId          = "internal-eureka-instance-id"
+	MetadataStatusAction        = "internal-eureka-status-action"
 
 	InternalMetadataStatus           = "internal-eureka-status"
 	InternalMetadataOverriddenStatus = "internal-eureka-overriddenStatus"
@@ -149,7 +150,11 @@ type EurekaServer struct {
 	replicateWorkers       *ReplicateWorkers
 	eventHandlerHandler    *EurekaInstanceEventHandler
 
-	replicatePeers       map[string][]string
+	replicatePeers map[string][]string
+	// region 本地的 eureka region，为空时不区分 region
+	region string
+	// remoteRegions remote region 与北极星命名空间的映射
+	remoteRegions        map[string]string
 	generateUniqueInstId bool
 	subCtxs              []*eventhub.SubscribtionContext
 
@@ -253,6 +258,11 @@ func (h *EurekaServer) Initialize(ctx context.Context, option map[string]interfa
 		h.generateUniqueInstId = false
 	}
 
+	if value, ok := option[optionRegion].(string); ok {
+		h.region = strings.ToLower(strings.TrimSpace(value))
+	}
+	h.remoteRegions = parseRemoteRegions(option[optionRemoteRegions])
+
 	if raw, _ := option[optionCustomValues].(map[interface{}]interface{}); raw != nil {
 		for k, v := range raw {
 			CustomEurekaParameters[k.(string)] = fmt.Sprintf("%v", v)
@@ -263,6 +273,24 @@ func (h *EurekaServer) Initialize(ctx context.Context, option map[string]interfa
 	return nil
 }
 
+// parseRemoteRegions 解析 remote region 与北极星命名空间的映射
+func parseRemoteRegions(value interface{}) map[string]string {
+	ret := make(map[string]string)
+	raw, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return ret
+	}
+	for k, v := range raw {
+		region := strings.ToLower(strings.TrimSpace(fmt.Sprintf("%v", k)))
+		namespace := strings.TrimSpace(fmt.Sprintf("%v", v))
+		if len(region) == 0 || len(namespace) == 0 {
+			continue
+		}
+		ret[region] = namespace
+	}
+	return ret
+}
+
 func parsePeersToReplicate(defaultNamespace string, replicatePeerObjs []interface{}) map[string][]string {
 	ret := make(map[string][]string)
 	if len(replicatePeerObjs) == 0 {
@@ -0,0 +1,111 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package eurekaserver
+
+const (
+	// StatusStarting 实例启动中
+	StatusStarting = "STARTING"
+)
+
+// statusContext 计算实例最终状态所需的上下文
+type statusContext struct {
+	// status 客户端注册或者心跳时携带的实例状态
+	status string
+	// overriddenStatus 当前生效的覆盖状态，为空或者 UNKNOWN 表示不存在覆盖状态
+	overriddenStatus string
+	// existStatus 已存在租约的实例当前状态，为空表示租约不存在
+	existStatus string
+	// replicated 是否为对端节点同步过来的请求
+	replicated bool
+}
+
+// statusOverrideRule 对应 eureka 的 InstanceStatusOverrideRule，命中时返回实例状态
+type statusOverrideRule func(sc *statusContext) (string, bool)
+
+// statusOverrideRules 按照 eureka FirstMatchWinsCompositeRule 的顺序依次匹配
+var statusOverrideRules = []statusOverrideRule{
+	downOrStartingRule,
+	overrideExistsRule,
+	leaseExistsRule,
+	alwaysMatchInstanceStatusRule,
+}
+
+// downOrStartingRule 实例自身处于 DOWN、STARTING 等非可服务状态时，以实例自身的状态为准
+func downOrStartingRule(sc *statusContext) (string, bool) {
+	if sc.status != StatusUp && sc.status != StatusOutOfService {
+		return sc.status, true
+	}
+	return "", false
+}
+
+// overrideExistsRule 存在覆盖状态时，以覆盖状态为准
+func overrideExistsRule(sc *statusContext) (string, bool) {
+	if hasOverriddenStatus(sc.overriddenStatus) {
+		return sc.overriddenStatus, true
+	}
+	return "", false
+}
+
+// leaseExistsRule 非同步请求并且租约已存在时，保留已有的 UP 或者 OUT_OF_SERVICE 状态
+func leaseExistsRule(sc *statusContext) (string, bool) {
+	if sc.replicated || len(sc.existStatus) == 0 {
+		return "", false
+	}
+	if sc.existStatus == StatusUp || sc.existStatus == StatusOutOfService {
+		return sc.existStatus, true
+	}
+	return "", false
+}
+
+// alwaysMatchInstanceStatusRule 兜底规则，直接使用实例自身的状态
+func alwaysMatchInstanceStatusRule(sc *statusContext) (string, bool) {
+	return sc.status, true
+}
+
+// resolveInstanceStatus 根据 eureka 的覆盖状态规则计算实例最终生效的状态
+func resolveInstanceStatus(sc *statusContext) string {
+	for _, rule := range statusOverrideRules {
+		if status, ok := rule(sc); ok {
+			return status
+		}
+	}
+	return sc.status
+}
+
+// hasOverriddenStatus 判断覆盖状态是否有效
+func hasOverriddenStatus(status string) bool {
+	return isValidStatus(status) && status != StatusUnknown
+}
+
+// isValidStatus 判断是否为 eureka 支持的实例状态
+func isValidStatus(status string) bool {
+	switch status {
+	case StatusUp, StatusDown, StatusStarting, StatusOutOfService, StatusUnknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// normalizeOverriddenStatus 将无效的覆盖状态统一转换为 UNKNOWN
+func normalizeOverriddenStatus(status string) string {
+	if hasOverriddenStatus(status) {
+		return status
+	}
+	return StatusUnknown
+}
@@ -0,0 +1,58 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package eurekaserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveInstanceStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		sc     statusContext
+		expect string
+	}{
+		{name: "down-wins", sc: statusContext{status: StatusDown, overriddenStatus: StatusOutOfService}, expect: StatusDown},
+		{name: "starting-wins", sc: statusContext{status: StatusStarting, existStatus: StatusUp}, expect: StatusStarting},
+		{name: "override-exists", sc: statusContext{status: StatusUp, overriddenStatus: StatusOutOfService},
+			expect: StatusOutOfService},
+		{name: "override-unknown", sc: statusContext{status: StatusUp, overriddenStatus: StatusUnknown}, expect: StatusUp},
+		{name: "lease-keeps-out-of-service", sc: statusContext{status: StatusUp, existStatus: StatusOutOfService},
+			expect: StatusOutOfService},
+		{name: "replicated-ignores-lease",
+			sc:     statusContext{status: StatusUp, existStatus: StatusOutOfService, replicated: true},
+			expect: StatusUp},
+		{name: "lease-unknown", sc: statusContext{status: StatusUp, existStatus: StatusUnknown}, expect: StatusUp},
+		{name: "deleted-override", sc: statusContext{status: StatusUnknown, existStatus: StatusUnknown},
+			expect: StatusUnknown},
+		{name: "new-instance", sc: statusContext{status: StatusOutOfService}, expect: StatusOutOfService},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, resolveInstanceStatus(&tt.sc))
+		})
+	}
+}
+
+func TestNormalizeOverriddenStatus(t *testing.T) {
+	assert.Equal(t, StatusOutOfService, normalizeOverriddenStatus(StatusOutOfService))
+	assert.Equal(t, StatusUnknown, normalizeOverriddenStatus(""))
+	assert.Equal(t, StatusUnknown, normalizeOverriddenStatus("invalid"))
+}
@@ -124,6 +124,13 @@ func buildBaseInstance(
 			targetInstance.Metadata[k] = strValue
 		}
 	}
+	if zone := instance.DataCenterInfo.availabilityZone(); len(zone) > 0 &&
+		len(targetInstance.GetLocation().GetZone().GetValue()) == 0 {
+		if targetInstance.Location == nil {
+			targetInstance.Location = &apimodel.Location{}
+		}
+		targetInstance.Location.Zone = &wrappers.StringValue{Value: zone}
+	}
 	targetInstance.Weight = &wrappers.UInt32Value{Value: 100}
 	buildHealthCheck(instance, targetInstance, eurekaMetadata)
 	buildStatus(instance, targetInstance)
@@ -198,9 +205,9 @@ func convertEurekaInstance(
 	targetInstance.Metadata[MetadataInsecurePortEnabled] = strconv.FormatBool(insecureEnable)
 	targetInstance.Metadata[MetadataSecurePort] = strconv.Itoa(securePort)
 	targetInstance.Metadata[MetadataSecurePortEnabled] = strconv.FormatBool(secureEnable)
-	// 保存客户端注册时设置的 status 信息，该信息不会随着心跳的变化而调整
+	// 保存客户端注册时设置的 status 以及 overriddenStatus 信息，注册时会再根据覆盖状态规则计算最终状态
 	targetInstance.Metadata[InternalMetadataStatus] = instance.Status
-	targetInstance.Metadata[InternalMetadataOverriddenStatus] = instance.OverriddenStatus
+	targetInstance.Metadata[InternalMetadataOverriddenStatus] = normalizeOverriddenStatus(instance.OverriddenStatus)
 	return targetInstance
 }
 
@@ -212,6 +219,10 @@ func (h *EurekaServer) registerInstances(
 	appId = formatWriteName(appId)
 	// 1. 先转换数据结构
 	totalInstance := convertEurekaInstance(instance, namespace, h.namespace, appId, h.generateUniqueInstId)
+	// 2. 结合已持久化的覆盖状态计算实例最终状态
+	if code := h.resolveRegisterStatus(totalInstance, replicated); code != api.ExecuteSuccess {
+		return code
+	}
 	// 3. 注册实例
 	resp := h.namingServer.RegisterInstance(ctx, totalInstance)
 	// 4. 注册成功，则返回
@@ -248,13 +259,60 @@ func (h *EurekaServer) deregisterInstance(
 	return resp.GetCode().GetValue()
 }
 
+// resolveRegisterStatus 按照 eureka 的覆盖状态规则计算注册实例的最终状态，已持久化的覆盖状态在重新注册后依然生效
+func (h *EurekaServer) resolveRegisterStatus(totalInstance *apiservice.Instance, replicated bool) uint32 {
+	metadata := totalInstance.GetMetadata()
+	sc := &statusContext{
+		status:           metadata[InternalMetadataStatus],
+		overriddenStatus: metadata[InternalMetadataOverriddenStatus],
+		replicated:       replicated,
+	}
+	svr := h.originDiscoverSvr.(*service.Server)
+	saveIns, err := svr.Store().GetInstance(totalInstance.GetId().GetValue())
+	if err != nil {
+		eurekalog.Error("[EUREKA-SERVER] get instance from store when register", zap.Error(err))
+		return uint32(commonstore.StoreCode2APICode(err))
+	}
+	if saveIns != nil {
+		saveMetadata := saveIns.Metadata()
+		if saveOverridden := saveMetadata[InternalMetadataOverriddenStatus]; hasOverriddenStatus(saveOverridden) {
+			sc.overriddenStatus = saveOverridden
+		}
+		sc.existStatus = saveMetadata[InternalMetadataStatus]
+	}
+	status := resolveInstanceStatus(sc)
+	metadata[InternalMetadataStatus] = status
+	metadata[InternalMetadataOverriddenStatus] = normalizeOverriddenStatus(sc.overriddenStatus)
+	totalInstance.Isolate = &wrappers.BoolValue{Value: status != StatusUp}
+	return api.ExecuteSuccess
+}
+
+// updateStatus 设置实例的覆盖状态，对应 eureka 的 PUT /apps/{app}/{id}/status
 func (h *EurekaServer) updateStatus(
 	ctx context.Context, namespace string, appId string, instanceId string, status string, replicated bool) uint32 {
+	return h.saveInstanceStatus(ctx, namespace, appId, instanceId, status, status, actionStatusUpdate, replicated)
+}
+
+// deleteStatusOverride 删除实例的覆盖状态，对应 eureka 的 DELETE /apps/{app}/{id}/status
+func (h *EurekaServer) deleteStatusOverride(
+	ctx context.Context, namespace string, appId string, instanceId string, status string, replicated bool) uint32 {
+	if !isValidStatus(status) {
+		status = StatusUnknown
+	}
+	return h.saveInstanceStatus(ctx, namespace, appId, instanceId, status, StatusUnknown,
+		actionDeleteStatusOverride, replicated)
+}
+
+func (h *EurekaServer) saveInstanceStatus(ctx context.Context, namespace string, appId string, instanceId string,
+	status string, overriddenStatus string, action string, replicated bool) uint32 {
 	ctx = context.WithValue(
 		ctx, model.CtxEventKeyMetadata, map[string]string{
-			MetadataReplicate:  strconv.FormatBool(replicated),
-			MetadataInstanceId: instanceId,
+			MetadataReplicate:    strconv.FormatBool(replicated),
+			MetadataInstanceId:   instanceId,
+			MetadataStatusAction: action,
 		})
+	// 覆盖状态由 eureka 自身维护，无需再经过 EurekaInstanceChain 根据隔离状态回写
+	ctx = context.WithValue(ctx, sourceFromEureka{}, true)
 	instanceId = checkOrBuildNewInstanceIdByNamespace(namespace, h.namespace, appId, instanceId, h.generateUniqueInstId)
 
 	svr := h.originDiscoverSvr.(*service.Server)
@@ -269,6 +327,7 @@ func (h *EurekaServer) updateStatus(
 
 	metadata := saveIns.Metadata()
 	metadata[InternalMetadataStatus] = status
+	metadata[InternalMetadataOverriddenStatus] = overriddenStatus
 	isolated := status != StatusUp
 
 	updateIns := &apiservice.Instance{
@@ -281,8 +340,10 @@ func (h *EurekaServer) updateStatus(
 	return resp.GetCode().GetValue()
 }
 
+// renew 处理实例心跳，status 以及 overriddenStatus 为心跳请求中携带的实例状态以及覆盖状态
 func (h *EurekaServer) renew(ctx context.Context, namespace string, appId string,
-	instanceId string, replicated bool) uint32 {
+	instanceId string, status string, overriddenStatus string, replicated bool) uint32 {
+	eurekaInstanceId := instanceId
 	ctx = context.WithValue(
 		ctx, model.CtxEventKeyMetadata, map[string]string{
 			MetadataReplicate:  strconv.FormatBool(replicated),
@@ -296,7 +357,52 @@ func (h *EurekaServer) renew(ctx context.Context, namespace string, appId string
 	if code == api.HeartbeatOnDisabledIns {
 		return api.ExecuteSuccess
 	}
-	return code
+	if code != api.ExecuteSuccess {
+		return code
+	}
+	return h.checkRenewStatus(ctx, namespace, appId, eurekaInstanceId, instanceId,
+		status, overriddenStatus, replicated)
+}
+
+// checkRenewStatus 心跳成功后根据覆盖状态规则校验实例状态，状态不一致时返回 NotFound 让客户端重新注册
+func (h *EurekaServer) checkRenewStatus(ctx context.Context, namespace string, appId string,
+	eurekaInstanceId string, instanceId string, status string, overriddenStatus string, replicated bool) uint32 {
+	cacheProvider, err := h.healthCheckServer.CacheProvider()
+	if err != nil {
+		return api.ExecuteSuccess
+	}
+	ins := cacheProvider.GetInstance(instanceId)
+	if ins == nil {
+		return api.ExecuteSuccess
+	}
+	metadata := ins.Metadata()
+	saveStatus, ok := metadata[InternalMetadataStatus]
+	if !ok {
+		return api.ExecuteSuccess
+	}
+	saveOverridden := normalizeOverriddenStatus(metadata[InternalMetadataOverriddenStatus])
+	// 对端节点同步过来的覆盖状态需要在本节点持久化
+	if replicated && hasOverriddenStatus(overriddenStatus) && overriddenStatus != saveOverridden {
+		return h.updateStatus(ctx, namespace, appId, eurekaInstanceId, overriddenStatus, true)
+	}
+	sc := &statusContext{
+		status:           saveStatus,
+		overriddenStatus: saveOverridden,
+		existStatus:      saveStatus,
+		replicated:       replicated,
+	}
+	if !replicated && isValidStatus(status) {
+		sc.status = status
+	}
+	newStatus := resolveInstanceStatus(sc)
+	if newStatus == saveStatus && newStatus != StatusUnknown {
+		return api.ExecuteSuccess
+	}
+	if replicated && newStatus != StatusUnknown {
+		return h.saveInstanceStatus(ctx, namespace, appId, eurekaInstanceId, newStatus, saveOverridden,
+			actionStatusUpdate, true)
+	}
+	return api.NotFoundResource
 }
 
 func (h *EurekaServer) updateMetadata(
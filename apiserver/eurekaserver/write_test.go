@@ -155,7 +155,7 @@ func TestEurekaServer_renew(t *testing.T) {
 		svr := &EurekaServer{
 			healthCheckServer: eurekaSuit.HealthCheckServer(),
 		}
-		code := svr.renew(context.Background(), ins.Namespace(), "", insId, false)
+		code := svr.renew(context.Background(), ins.Namespace(), "", insId, "", "", false)
 		assert.Equalf(t, api.ExecuteSuccess, code, "code need success, actual : %d", code)
 	})
 
@@ -163,7 +163,7 @@ func TestEurekaServer_renew(t *testing.T) {
 		svr := &EurekaServer{
 			healthCheckServer: eurekaSuit.HealthCheckServer(),
 		}
-		code := svr.renew(context.Background(), ins.Namespace(), "", disableBeatInsId, false)
+		code := svr.renew(context.Background(), ins.Namespace(), "", disableBeatInsId, "", "", false)
 		assert.Equalf(t, api.ExecuteSuccess, code, "code need success, actual : %d", code)
 	})
 
@@ -174,7 +174,7 @@ func TestEurekaServer_renew(t *testing.T) {
 		instId := utils.NewUUID()
 		var code uint32
 		for i := 0; i < 5; i++ {
-			code = svr.renew(context.Background(), ins.Namespace(), "", instId, false)
+			code = svr.renew(context.Background(), ins.Namespace(), "", instId, "", "", false)
 			time.Sleep(time.Second)
 		}
 		assert.Equalf(t, api.NotFoundResource, code, "code need notfound, actual : %d", code)
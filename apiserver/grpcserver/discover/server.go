@@ -89,11 +89,23 @@ func (g *GRPCServer) Initialize(ctx context.Context, option map[string]interface
 		return err
 	}
 
+	raw, _ := option["discoverSubscribe"].(map[interface{}]interface{})
+	subscribeConfig, err := v1.ParseSubscribeConfig(raw)
+	if err != nil {
+		namingLog.Errorf("[Grpc][Discover] parse discover subscribe config: %v", err)
+		return err
+	}
+
+	// 重启时需要停止旧的订阅模式变更分发
+	if g.v1server != nil {
+		g.v1server.Stop()
+	}
 	g.v1server = v1.NewDiscoverServer(
 		v1.WithAllowAccess(g.allowAccess),
 		v1.WithEnterRateLimit(g.enterRateLimit),
 		v1.WithHealthCheckerServer(g.healthCheckServer),
 		v1.WithNamingServer(g.namingServer),
+		v1.WithSubscribeConfig(subscribeConfig),
 	)
 	return nil
 }
@@ -127,6 +139,9 @@ func (g *GRPCServer) Run(errCh chan error) {
 // Stop 关闭GRPC
 func (g *GRPCServer) Stop() {
 	g.BaseGrpcServer.Stop(g.GetProtocol())
+	if g.v1server != nil {
+		g.v1server.Stop()
+	}
 }
 
 // Restart 重启Server
@@ -93,6 +93,15 @@ func (g *DiscoverServer) Discover(server apiservice.PolarisGRPC_DiscoverServer)
 	userAgent, _ := ctx.Value(utils.StringContext("user-agent")).(string)
 	method, _ := grpc.MethodFromServerStream(server)
 
+	send := server.Send
+	// 订阅模式下推送和请求的应答共用一个流，需要通过订阅者串行发送
+	var subscriber *streamSubscriber
+	if g.subscribeCenter != nil && isSubscribeStream(ctx) {
+		subscriber = g.subscribeCenter.newSubscriber(server.Context(), g.discover, server.Send)
+		defer subscriber.close()
+		send = subscriber.send
+	}
+
 	for {
 		in, err := server.Recv()
 		if err != nil {
@@ -113,7 +122,7 @@ func (g *DiscoverServer) Discover(server apiservice.PolarisGRPC_DiscoverServer)
 		// 是否允许访问
 		if ok := g.allowAccess(method); !ok {
 			resp := api.NewDiscoverResponse(apimodel.Code_ClientAPINotOpen)
-			if sendErr := server.Send(resp); sendErr != nil {
+			if sendErr := send(resp); sendErr != nil {
 				return sendErr
 			}
 			continue
@@ -122,7 +131,7 @@ func (g *DiscoverServer) Discover(server apiservice.PolarisGRPC_DiscoverServer)
 		// stream模式，需要对每个包进行检测
 		if code := g.enterRateLimit(clientIP, method); code != uint32(apimodel.Code_ExecuteSuccess) {
 			resp := api.NewDiscoverResponse(apimodel.Code(code))
-			if err = server.Send(resp); err != nil {
+			if err = send(resp); err != nil {
 				return err
 			}
 			continue
@@ -137,30 +146,12 @@ func (g *DiscoverServer) Discover(server apiservice.PolarisGRPC_DiscoverServer)
 			ctx = context.WithValue(ctx, utils.ContextAuthTokenKey, in.GetService().GetToken().GetValue())
 		}
 
-		switch in.Type {
-		case apiservice.DiscoverRequest_INSTANCE:
-			action = metrics.ActionDiscoverInstance
-			out = g.namingServer.ServiceInstancesCache(ctx, &apiservice.DiscoverFilter{}, in.Service)
-		case apiservice.DiscoverRequest_ROUTING:
-			action = metrics.ActionDiscoverRouterRule
-			out = g.namingServer.GetRoutingConfigWithCache(ctx, in.Service)
-		case apiservice.DiscoverRequest_RATE_LIMIT:
-			action = metrics.ActionDiscoverRateLimit
-			out = g.namingServer.GetRateLimitWithCache(ctx, in.Service)
-		case apiservice.DiscoverRequest_CIRCUIT_BREAKER:
-			action = metrics.ActionDiscoverCircuitBreaker
-			out = g.namingServer.GetCircuitBreakerWithCache(ctx, in.Service)
-		case apiservice.DiscoverRequest_SERVICES:
-			action = metrics.ActionDiscoverServices
-			out = g.namingServer.GetServiceWithCache(ctx, in.Service)
-		case apiservice.DiscoverRequest_FAULT_DETECTOR:
-			action = metrics.ActionDiscoverFaultDetect
-			out = g.namingServer.GetFaultDetectWithCache(ctx, in.Service)
-		default:
-			out = api.NewDiscoverRoutingResponse(apimodel.Code_InvalidDiscoverResource, in.Service)
+		if _, ok := subscribeTypes[in.Type]; ok && subscriber != nil {
+			out, action, err = subscriber.subscribe(ctx, in)
+		} else {
+			out, action = g.discover(ctx, in)
+			err = send(out)
 		}
-
-		err = server.Send(out)
 		if err != nil {
 			return err
 		}
@@ -178,6 +169,36 @@ func (g *DiscoverServer) Discover(server apiservice.PolarisGRPC_DiscoverServer)
 	}
 }
 
+// discover 查询缓存中请求的资源
+func (g *DiscoverServer) discover(ctx context.Context,
+	in *apiservice.DiscoverRequest) (*apiservice.DiscoverResponse, string) {
+	var out *apiservice.DiscoverResponse
+	var action string
+	switch in.Type {
+	case apiservice.DiscoverRequest_INSTANCE:
+		action = metrics.ActionDiscoverInstance
+		out = g.namingServer.ServiceInstancesCache(ctx, &apiservice.DiscoverFilter{}, in.Service)
+	case apiservice.DiscoverRequest_ROUTING:
+		action = metrics.ActionDiscoverRouterRule
+		out = g.namingServer.GetRoutingConfigWithCache(ctx, in.Service)
+	case apiservice.DiscoverRequest_RATE_LIMIT:
+		action = metrics.ActionDiscoverRateLimit
+		out = g.namingServer.GetRateLimitWithCache(ctx, in.Service)
+	case apiservice.DiscoverRequest_CIRCUIT_BREAKER:
+		action = metrics.ActionDiscoverCircuitBreaker
+		out = g.namingServer.GetCircuitBreakerWithCache(ctx, in.Service)
+	case apiservice.DiscoverRequest_SERVICES:
+		action = metrics.ActionDiscoverServices
+		out = g.namingServer.GetServiceWithCache(ctx, in.Service)
+	case apiservice.DiscoverRequest_FAULT_DETECTOR:
+		action = metrics.ActionDiscoverFaultDetect
+		out = g.namingServer.GetFaultDetectWithCache(ctx, in.Service)
+	default:
+		out = api.NewDiscoverRoutingResponse(apimodel.Code_InvalidDiscoverResource, in.Service)
+	}
+	return out, action
+}
+
 func (g *DiscoverServer) ReportServiceContract(ctx context.Context, in *apiservice.ServiceContract) (*apiservice.Response, error) {
 	// 需要记录操作来源，提高效率，只针对特殊接口添加operator
 	rCtx := utils.ConvertGRPCContext(ctx)
@@ -27,6 +27,7 @@ type DiscoverServer struct {
 	healthCheckServer *healthcheck.Server
 	enterRateLimit    func(ip string, method string) uint32
 	allowAccess       func(method string) bool
+	subscribeCenter   *subscribeCenter
 }
 
 func NewDiscoverServer(options ...Option) *DiscoverServer {
@@ -39,6 +40,13 @@ func NewDiscoverServer(options ...Option) *DiscoverServer {
 	return s
 }
 
+// Stop 停止 Discover 订阅模式的变更分发
+func (g *DiscoverServer) Stop() {
+	if g.subscribeCenter != nil {
+		g.subscribeCenter.stop()
+	}
+}
+
 type Option func(s *DiscoverServer)
 
 func WithNamingServer(svr service.DiscoverServer) Option {
@@ -64,3 +72,12 @@ func WithAllowAccess(f func(method string) bool) Option {
 		s.allowAccess = f
 	}
 }
+
+// WithSubscribeConfig 设置 Discover 订阅模式配置，未开启时客户端只能使用请求应答模式
+func WithSubscribeConfig(cfg *SubscribeConfig) Option {
+	return func(s *DiscoverServer) {
+		if cfg != nil && cfg.Open {
+			s.subscribeCenter = newSubscribeCenter(cfg)
+		}
+	}
+}
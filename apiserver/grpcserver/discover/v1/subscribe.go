@@ -0,0 +1,451 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package v1
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/mitchellh/mapstructure"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/eventhub"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+const (
+	// SubscribeHeader 客户端在 Discover 流的 metadata 中携带该头部，开启订阅模式
+	SubscribeHeader = "discover-subscribe"
+
+	// DefaultMaxSubscriptionsPerConn 单个连接默认允许的最大订阅数
+	DefaultMaxSubscriptionsPerConn = 1024
+	// DefaultPushDelay 默认的推送合并窗口
+	DefaultPushDelay = 200 * time.Millisecond
+	// DefaultCheckInterval 默认的订阅资源版本号全量检查周期
+	DefaultCheckInterval = 5 * time.Second
+)
+
+// SubscribeConfig Discover 订阅模式配置
+type SubscribeConfig struct {
+	// Open 是否允许客户端开启订阅模式
+	Open bool `mapstructure:"open"`
+	// MaxSubscriptionsPerConn 单个连接允许的最大订阅数，超过后新的订阅请求返回 BatchSizeOverLimit
+	MaxSubscriptionsPerConn int `mapstructure:"maxSubscriptionsPerConn"`
+	// PushDelay 收到变更后等待的时间，窗口期内同一个资源的多次变更合并为一次推送
+	PushDelay time.Duration `mapstructure:"pushDelay"`
+	// CheckInterval 全量检查订阅资源版本号的周期，用于发现没有变更事件的规则类资源
+	CheckInterval time.Duration `mapstructure:"checkInterval"`
+}
+
+// DefaultSubscribeConfig 默认的订阅模式配置
+func DefaultSubscribeConfig() *SubscribeConfig {
+	return &SubscribeConfig{
+		Open:                    true,
+		MaxSubscriptionsPerConn: DefaultMaxSubscriptionsPerConn,
+		PushDelay:               DefaultPushDelay,
+		CheckInterval:           DefaultCheckInterval,
+	}
+}
+
+// ParseSubscribeConfig 解析订阅模式配置，没有配置的字段使用默认值
+func ParseSubscribeConfig(raw map[interface{}]interface{}) (*SubscribeConfig, error) {
+	cfg := DefaultSubscribeConfig()
+	if raw == nil {
+		return cfg, nil
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     cfg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(raw); err != nil {
+		return nil, err
+	}
+	if cfg.MaxSubscriptionsPerConn <= 0 {
+		cfg.MaxSubscriptionsPerConn = DefaultMaxSubscriptionsPerConn
+	}
+	if cfg.PushDelay < 0 {
+		cfg.PushDelay = DefaultPushDelay
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = DefaultCheckInterval
+	}
+	return cfg, nil
+}
+
+// isSubscribeStream 判断客户端是否在流上开启了订阅模式
+func isSubscribeStream(ctx context.Context) bool {
+	md, ok := ctx.Value(utils.ContextGrpcHeader).(metadata.MD)
+	if !ok {
+		return false
+	}
+	values := md.Get(SubscribeHeader)
+	return len(values) != 0 && values[0] == "true"
+}
+
+var (
+	// subscribeTypes 支持订阅的资源类型
+	subscribeTypes = map[apiservice.DiscoverRequest_DiscoverRequestType]struct{}{
+		apiservice.DiscoverRequest_INSTANCE:        {},
+		apiservice.DiscoverRequest_ROUTING:         {},
+		apiservice.DiscoverRequest_RATE_LIMIT:      {},
+		apiservice.DiscoverRequest_CIRCUIT_BREAKER: {},
+		apiservice.DiscoverRequest_SERVICES:        {},
+		apiservice.DiscoverRequest_FAULT_DETECTOR:  {},
+	}
+)
+
+type (
+	// discoverFunc 根据请求查询缓存中的资源
+	discoverFunc func(ctx context.Context, in *apiservice.DiscoverRequest) (*apiservice.DiscoverResponse, string)
+	// sendFunc 向客户端发送应答
+	sendFunc func(resp *apiservice.DiscoverResponse) error
+)
+
+// subscribeKey 订阅的资源
+type subscribeKey struct {
+	Type      apiservice.DiscoverRequest_DiscoverRequestType
+	Namespace string
+	Name      string
+}
+
+func newSubscribeKey(in *apiservice.DiscoverRequest) subscribeKey {
+	return subscribeKey{
+		Type:      in.GetType(),
+		Namespace: in.GetService().GetNamespace().GetValue(),
+		Name:      in.GetService().GetName().GetValue(),
+	}
+}
+
+// subscription 单个资源的订阅状态
+type subscription struct {
+	ctx context.Context
+	req *apiservice.DiscoverRequest
+	// code 最近一次发送给客户端的应答码
+	code uint32
+	// revision 客户端当前持有的资源版本号
+	revision string
+}
+
+// subscribeCenter 管理所有订阅模式的连接，把资源变更分发给订阅了该资源的连接
+type subscribeCenter struct {
+	cfg    *SubscribeConfig
+	cancel context.CancelFunc
+	subCtx *eventhub.SubscribtionContext
+
+	lock        sync.RWMutex
+	subscribers map[*streamSubscriber]struct{}
+	// index 资源到订阅了该资源的连接的索引
+	index map[subscribeKey]map[*streamSubscriber]struct{}
+}
+
+func newSubscribeCenter(cfg *SubscribeConfig) *subscribeCenter {
+	ctx, cancel := context.WithCancel(context.Background())
+	center := &subscribeCenter{
+		cfg:         cfg,
+		cancel:      cancel,
+		subscribers: map[*streamSubscriber]struct{}{},
+		index:       map[subscribeKey]map[*streamSubscriber]struct{}{},
+	}
+	subCtx, err := eventhub.SubscribeWithFunc(eventhub.CacheInstanceEventTopic, center.onInstanceEvent)
+	if err != nil {
+		// 没有实例变更事件时仍然可以依赖周期性检查发现变更
+		accesslog.Warn("[Grpc][Discover] subscribe cache instance event fail", zap.Error(err))
+	}
+	center.subCtx = subCtx
+	go center.runCheck(ctx)
+	return center
+}
+
+// onInstanceEvent 实例发生变更，通知订阅了对应服务实例的连接
+func (c *subscribeCenter) onInstanceEvent(_ context.Context, value any) error {
+	event, ok := value.(*eventhub.CacheInstanceEvent)
+	if !ok || event.Instance == nil {
+		return nil
+	}
+	key := subscribeKey{
+		Type:      apiservice.DiscoverRequest_INSTANCE,
+		Namespace: event.Instance.Namespace(),
+		Name:      event.Instance.Service(),
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	for s := range c.index[key] {
+		s.markDirty(key)
+	}
+	return nil
+}
+
+// runCheck 周期性检查所有订阅的资源，规则类资源以及别名服务没有变更事件，依赖这里发现变更
+func (c *subscribeCenter) runCheck(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.lock.RLock()
+			for s := range c.subscribers {
+				s.markAllDirty()
+			}
+			c.lock.RUnlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// newSubscriber 为开启订阅模式的流创建订阅者
+func (c *subscribeCenter) newSubscriber(ctx context.Context, discover discoverFunc,
+	send sendFunc) *streamSubscriber {
+	s := &streamSubscriber{
+		center:        c,
+		discover:      discover,
+		sender:        send,
+		subscriptions: map[subscribeKey]*subscription{},
+		dirty:         map[subscribeKey]struct{}{},
+		notifyCh:      make(chan struct{}, 1),
+		closeCh:       make(chan struct{}),
+	}
+	c.lock.Lock()
+	c.subscribers[s] = struct{}{}
+	c.lock.Unlock()
+	go s.run(ctx)
+	return s
+}
+
+func (c *subscribeCenter) addIndex(key subscribeKey, s *streamSubscriber) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	subscribers, ok := c.index[key]
+	if !ok {
+		subscribers = map[*streamSubscriber]struct{}{}
+		c.index[key] = subscribers
+	}
+	subscribers[s] = struct{}{}
+}
+
+func (c *subscribeCenter) removeSubscriber(s *streamSubscriber, keys []subscribeKey) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.subscribers, s)
+	for _, key := range keys {
+		subscribers := c.index[key]
+		delete(subscribers, s)
+		if len(subscribers) == 0 {
+			delete(c.index, key)
+		}
+	}
+}
+
+// stop 停止分发资源变更
+func (c *subscribeCenter) stop() {
+	c.cancel()
+	if c.subCtx != nil {
+		c.subCtx.Cancel()
+	}
+}
+
+// streamSubscriber 单个 Discover 流上的订阅。所有的发送都经过 sendLock 串行化，
+// 客户端消费过慢时发送会阻塞，期间发生的变更只在 dirty 中标记，同一个资源的多次变更只会推送最新的数据
+type streamSubscriber struct {
+	center   *subscribeCenter
+	discover discoverFunc
+	sender   sendFunc
+
+	// sendLock 保证查询资源、发送应答以及更新订阅状态是原子的，避免旧的数据覆盖新的数据
+	sendLock sync.Mutex
+
+	lock          sync.Mutex
+	subscriptions map[subscribeKey]*subscription
+	dirty         map[subscribeKey]struct{}
+
+	notifyCh  chan struct{}
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+// send 发送非订阅资源的应答
+func (s *streamSubscriber) send(resp *apiservice.DiscoverResponse) error {
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+	return s.sender(resp)
+}
+
+// subscribe 订阅请求中的资源并立即返回当前的数据，超过连接的最大订阅数时返回 BatchSizeOverLimit，客户端需要回退为轮询
+func (s *streamSubscriber) subscribe(ctx context.Context,
+	in *apiservice.DiscoverRequest) (*apiservice.DiscoverResponse, string, error) {
+	key := newSubscribeKey(in)
+
+	s.lock.Lock()
+	item, ok := s.subscriptions[key]
+	if !ok && len(s.subscriptions) >= s.center.cfg.MaxSubscriptionsPerConn {
+		s.lock.Unlock()
+		out := api.NewDiscoverResponse(apimodel.Code_BatchSizeOverLimit)
+		out.Type = apiservice.DiscoverResponse_DiscoverResponseType(in.GetType())
+		out.Service = in.GetService()
+		return out, "", s.send(out)
+	}
+	if !ok {
+		item = &subscription{}
+		s.subscriptions[key] = item
+	}
+	s.lock.Unlock()
+	if !ok {
+		s.center.addIndex(key, s)
+	}
+
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+	out, action := s.discover(ctx, in)
+	if err := s.sender(out); err != nil {
+		return out, action, err
+	}
+	item.ctx = ctx
+	item.req = in
+	item.code = out.GetCode().GetValue()
+	item.revision = out.GetService().GetRevision().GetValue()
+	if item.code == uint32(apimodel.Code_DataNoChange) {
+		item.revision = in.GetService().GetRevision().GetValue()
+	}
+	return out, action, nil
+}
+
+// markDirty 标记资源发生了变更，等待合并推送
+func (s *streamSubscriber) markDirty(key subscribeKey) {
+	s.lock.Lock()
+	if _, ok := s.subscriptions[key]; ok {
+		s.dirty[key] = struct{}{}
+	}
+	s.lock.Unlock()
+	s.notify()
+}
+
+// markAllDirty 标记所有订阅的资源需要检查
+func (s *streamSubscriber) markAllDirty() {
+	s.lock.Lock()
+	for key := range s.subscriptions {
+		s.dirty[key] = struct{}{}
+	}
+	s.lock.Unlock()
+	s.notify()
+}
+
+func (s *streamSubscriber) notify() {
+	select {
+	case s.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *streamSubscriber) takeDirty() []subscribeKey {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	keys := make([]subscribeKey, 0, len(s.dirty))
+	for key := range s.dirty {
+		keys = append(keys, key)
+	}
+	s.dirty = map[subscribeKey]struct{}{}
+	return keys
+}
+
+// run 等待合并窗口结束后推送发生变更的资源
+func (s *streamSubscriber) run(ctx context.Context) {
+	for {
+		select {
+		case <-s.notifyCh:
+		case <-s.closeCh:
+			return
+		case <-ctx.Done():
+			return
+		}
+		if s.center.cfg.PushDelay > 0 {
+			select {
+			case <-time.After(s.center.cfg.PushDelay):
+			case <-s.closeCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+		for _, key := range s.takeDirty() {
+			if err := s.push(key); err != nil {
+				accesslog.Warn("[Grpc][Discover] push subscribe resource fail", zap.Any("resource", key),
+					zap.Error(err))
+				return
+			}
+		}
+	}
+}
+
+// push 资源的版本号发生变化时推送最新的数据
+func (s *streamSubscriber) push(key subscribeKey) error {
+	s.lock.Lock()
+	item, ok := s.subscriptions[key]
+	s.lock.Unlock()
+	if !ok {
+		return nil
+	}
+
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+	if item.req == nil {
+		return nil
+	}
+	req := proto.Clone(item.req).(*apiservice.DiscoverRequest)
+	if req.Service == nil {
+		req.Service = &apiservice.Service{}
+	}
+	req.Service.Revision = utils.NewStringValue(item.revision)
+	out, _ := s.discover(item.ctx, req)
+	code := out.GetCode().GetValue()
+	if code == uint32(apimodel.Code_DataNoChange) {
+		return nil
+	}
+	revision := out.GetService().GetRevision().GetValue()
+	if code == item.code && revision == item.revision {
+		return nil
+	}
+	if err := s.sender(out); err != nil {
+		return err
+	}
+	item.code = code
+	item.revision = revision
+	return nil
+}
+
+// close 流结束时清理所有订阅
+func (s *streamSubscriber) close() {
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+		s.lock.Lock()
+		keys := make([]subscribeKey, 0, len(s.subscriptions))
+		for key := range s.subscriptions {
+			keys = append(keys, key)
+		}
+		s.lock.Unlock()
+		s.center.removeSubscriber(s, keys)
+	})
+}
@@ -0,0 +1,201 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package v1
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/eventhub"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+// fakeDiscover 按照资源记录当前的版本号，模拟缓存的 revision 对比逻辑
+type fakeDiscover struct {
+	lock      sync.Mutex
+	revisions map[string]string
+}
+
+func (f *fakeDiscover) setRevision(name, revision string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.revisions[name] = revision
+}
+
+func (f *fakeDiscover) discover(_ context.Context,
+	in *apiservice.DiscoverRequest) (*apiservice.DiscoverResponse, string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	revision := f.revisions[in.GetService().GetName().GetValue()]
+	if revision == in.GetService().GetRevision().GetValue() {
+		return api.NewDiscoverInstanceResponse(apimodel.Code_DataNoChange, in.GetService()), "instance"
+	}
+	return api.NewDiscoverInstanceResponse(apimodel.Code_ExecuteSuccess, &apiservice.Service{
+		Name:      in.GetService().GetName(),
+		Namespace: in.GetService().GetNamespace(),
+		Revision:  utils.NewStringValue(revision),
+	}), "instance"
+}
+
+// fakeSender 记录发送给客户端的应答
+type fakeSender struct {
+	lock  sync.Mutex
+	resps []*apiservice.DiscoverResponse
+}
+
+func (f *fakeSender) send(resp *apiservice.DiscoverResponse) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.resps = append(f.resps, resp)
+	return nil
+}
+
+func (f *fakeSender) count() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.resps)
+}
+
+func (f *fakeSender) last() *apiservice.DiscoverResponse {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.resps[len(f.resps)-1]
+}
+
+func newInstanceRequest(name, revision string) *apiservice.DiscoverRequest {
+	return &apiservice.DiscoverRequest{
+		Type: apiservice.DiscoverRequest_INSTANCE,
+		Service: &apiservice.Service{
+			Name:      utils.NewStringValue(name),
+			Namespace: utils.NewStringValue("default"),
+			Revision:  utils.NewStringValue(revision),
+		},
+	}
+}
+
+func newInstanceEvent(name string) *eventhub.CacheInstanceEvent {
+	return &eventhub.CacheInstanceEvent{
+		Instance: &model.Instance{Proto: &apiservice.Instance{
+			Service:   utils.NewStringValue(name),
+			Namespace: utils.NewStringValue("default"),
+		}},
+		EventType: eventhub.EventUpdated,
+	}
+}
+
+func TestStreamSubscriber_Push(t *testing.T) {
+	center := newSubscribeCenter(&SubscribeConfig{
+		Open:                    true,
+		MaxSubscriptionsPerConn: 2,
+		PushDelay:               50 * time.Millisecond,
+		CheckInterval:           time.Hour,
+	})
+	defer center.stop()
+
+	discover := &fakeDiscover{revisions: map[string]string{"svc-a": "1", "svc-b": "1", "svc-c": "1"}}
+	sender := &fakeSender{}
+	subscriber := center.newSubscriber(context.Background(), discover.discover, sender.send)
+
+	// 订阅时立即返回当前数据
+	out, action, err := subscriber.subscribe(context.Background(), newInstanceRequest("svc-a", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, "instance", action)
+	assert.Equal(t, "1", out.GetService().GetRevision().GetValue())
+	out, _, err = subscriber.subscribe(context.Background(), newInstanceRequest("svc-b", "1"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(apimodel.Code_DataNoChange), out.GetCode().GetValue())
+	assert.Equal(t, 2, sender.count())
+
+	t.Run("超过单个连接的最大订阅数", func(t *testing.T) {
+		out, _, err := subscriber.subscribe(context.Background(), newInstanceRequest("svc-c", ""))
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(apimodel.Code_BatchSizeOverLimit), out.GetCode().GetValue())
+		assert.Equal(t, apiservice.DiscoverResponse_INSTANCE, out.GetType())
+		assert.Equal(t, 3, sender.count())
+		center.lock.RLock()
+		_, ok := center.index[subscribeKey{Type: apiservice.DiscoverRequest_INSTANCE, Namespace: "default",
+			Name: "svc-c"}]
+		center.lock.RUnlock()
+		assert.False(t, ok)
+	})
+
+	t.Run("窗口期内的多次变更合并为一次推送", func(t *testing.T) {
+		discover.setRevision("svc-a", "2")
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, center.onInstanceEvent(context.Background(), newInstanceEvent("svc-a")))
+		}
+		assert.Eventually(t, func() bool {
+			return sender.count() == 4
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, "svc-a", sender.last().GetService().GetName().GetValue())
+		assert.Equal(t, "2", sender.last().GetService().GetRevision().GetValue())
+
+		time.Sleep(200 * time.Millisecond)
+		assert.Equal(t, 4, sender.count())
+	})
+
+	t.Run("版本号没有变化时不推送", func(t *testing.T) {
+		assert.NoError(t, center.onInstanceEvent(context.Background(), newInstanceEvent("svc-a")))
+		assert.NoError(t, center.onInstanceEvent(context.Background(), newInstanceEvent("svc-b")))
+		assert.NoError(t, center.onInstanceEvent(context.Background(), newInstanceEvent("svc-c")))
+		time.Sleep(200 * time.Millisecond)
+		assert.Equal(t, 4, sender.count())
+	})
+
+	t.Run("周期性检查发现没有变更事件的资源", func(t *testing.T) {
+		discover.setRevision("svc-b", "2")
+		subscriber.markAllDirty()
+		assert.Eventually(t, func() bool {
+			return sender.count() == 5
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, "svc-b", sender.last().GetService().GetName().GetValue())
+	})
+
+	t.Run("连接关闭后清理订阅", func(t *testing.T) {
+		subscriber.close()
+		center.lock.RLock()
+		defer center.lock.RUnlock()
+		assert.Empty(t, center.subscribers)
+		assert.Empty(t, center.index)
+	})
+}
+
+func TestParseSubscribeConfig(t *testing.T) {
+	cfg, err := ParseSubscribeConfig(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultSubscribeConfig(), cfg)
+
+	cfg, err = ParseSubscribeConfig(map[interface{}]interface{}{
+		"open":                    false,
+		"maxSubscriptionsPerConn": 16,
+		"pushDelay":               "1s",
+	})
+	assert.NoError(t, err)
+	assert.False(t, cfg.Open)
+	assert.Equal(t, 16, cfg.MaxSubscriptionsPerConn)
+	assert.Equal(t, time.Second, cfg.PushDelay)
+	assert.Equal(t, DefaultCheckInterval, cfg.CheckInterval)
+}
@@ -0,0 +1,79 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package heartbeatserver
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	DefaultListenPort   = 8095
+	DefaultMaxClockSkew = 3 * time.Second
+	DefaultRateLimit    = 20
+	DefaultRateBurst    = 40
+)
+
+// Config UDP 心跳服务器配置
+type Config struct {
+	ListenIP   string `mapstructure:"listenIP"`
+	ListenPort uint32 `mapstructure:"listenPort"`
+	// SecretKey 计算心跳报文签名的共享密钥，必须配置
+	SecretKey string `mapstructure:"secretKey"`
+	// MaxClockSkew 报文时间戳与服务端时间允许的最大偏差，超出的报文视为过期，同时也是防重放的窗口。
+	// 防重放的记录只保存在接收报文的节点上，截获的报文在该窗口内仍然可以被重放到集群中的其他节点，
+	// 最多让实例的心跳多维持一个窗口的时间，因此窗口应当尽量小
+	MaxClockSkew time.Duration `mapstructure:"maxClockSkew"`
+	// RateLimit 每个来源 IP 每秒允许的心跳报文数量，小于等于 0 表示不限制
+	RateLimit float64 `mapstructure:"rateLimit"`
+	// RateBurst 每个来源 IP 允许的突发报文数量
+	RateBurst int `mapstructure:"rateBurst"`
+}
+
+func parseConfig(option map[string]interface{}) (*Config, error) {
+	cfg := &Config{
+		ListenIP:     "0.0.0.0",
+		ListenPort:   DefaultListenPort,
+		MaxClockSkew: DefaultMaxClockSkew,
+		RateLimit:    DefaultRateLimit,
+		RateBurst:    DefaultRateBurst,
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           cfg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(option); err != nil {
+		return nil, err
+	}
+	if cfg.SecretKey == "" {
+		return nil, errors.New("udp heartbeat secretKey must not be empty")
+	}
+	if cfg.MaxClockSkew <= 0 {
+		return nil, errors.New("udp heartbeat maxClockSkew must be positive")
+	}
+	if cfg.RateLimit > 0 && cfg.RateBurst <= 0 {
+		return nil, errors.New("udp heartbeat rateBurst must be positive when rateLimit is set")
+	}
+	return cfg, nil
+}
@@ -0,0 +1,121 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package heartbeatserver
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// replayGuard 记录每个实例最近一次接受的报文时间戳，只接受时间戳严格递增的报文。
+// 时间窗口之外的报文已经被时钟偏差校验拒绝，因此只需要保留窗口内的记录
+// 记录只保存在当前节点，不能阻止同一个报文在时间窗口内被重放到其他节点
+type replayGuard struct {
+	lock   sync.Mutex
+	window time.Duration
+	last   map[string]time.Time
+}
+
+func newReplayGuard(window time.Duration) *replayGuard {
+	return &replayGuard{
+		window: window,
+		last:   map[string]time.Time{},
+	}
+}
+
+// accept 报文时间戳不晚于该实例上一次接受的时间戳时，视为重放
+func (g *replayGuard) accept(instanceID string, timestamp time.Time) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if last, ok := g.last[instanceID]; ok && !timestamp.After(last) {
+		return false
+	}
+	g.last[instanceID] = timestamp
+	return true
+}
+
+// expire 清理已经落在时间窗口之外的记录
+func (g *replayGuard) expire(now time.Time) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for id, last := range g.last {
+		if now.Sub(last) > g.window {
+			delete(g.last, id)
+		}
+	}
+}
+
+// sourceLimiter 按照来源 IP 进行令牌桶限流。UDP 的来源 IP 可以伪造，记录数达到上限后，
+// 新的来源共用同一个溢出令牌桶，避免伪造大量来源 IP 撑大内存
+type sourceLimiter struct {
+	lock       sync.Mutex
+	limit      rate.Limit
+	burst      int
+	idle       time.Duration
+	maxSources int
+	limiters   map[string]*sourceBucket
+	overflow   *rate.Limiter
+}
+
+type sourceBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newSourceLimiter(limit float64, burst int, idle time.Duration, maxSources int) *sourceLimiter {
+	return &sourceLimiter{
+		limit:      rate.Limit(limit),
+		burst:      burst,
+		idle:       idle,
+		maxSources: maxSources,
+		limiters:   map[string]*sourceBucket{},
+		overflow:   rate.NewLimiter(rate.Limit(limit), burst),
+	}
+}
+
+// allow 判断来源 IP 的报文是否允许通过
+func (l *sourceLimiter) allow(source string, now time.Time) bool {
+	if l.limit <= 0 {
+		return true
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	bucket, ok := l.limiters[source]
+	if !ok {
+		if len(l.limiters) >= l.maxSources {
+			return l.overflow.AllowN(now, 1)
+		}
+		bucket = &sourceBucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[source] = bucket
+	}
+	bucket.lastSeen = now
+	return bucket.limiter.AllowN(now, 1)
+}
+
+// expire 清理长时间没有报文的来源
+func (l *sourceLimiter) expire(now time.Time) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for source, bucket := range l.limiters {
+		if now.Sub(bucket.lastSeen) > l.idle {
+			delete(l.limiters, source)
+		}
+	}
+}
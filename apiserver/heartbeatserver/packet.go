@@ -0,0 +1,115 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package heartbeatserver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// 心跳报文格式（大端序）：
+//
+//	| version(1) | timestamp(8) | idLen(1) | instanceId(idLen) | tokenDigest(32) | digest(32) |
+//
+// timestamp 为客户端发送时的 Unix 毫秒时间；tokenDigest 为使用实例所属服务的 token 对
+// version、timestamp、idLen、instanceId 计算的 HMAC-SHA256，用于证明上报方持有该服务的 token；
+// digest 为使用共享密钥对前面所有字节计算的 HMAC-SHA256
+const (
+	packetVersion = 1
+	headerSize    = 1 + 8 + 1
+	digestSize    = sha256.Size
+	maxIDSize     = 255
+	// MaxPacketSize 心跳报文的最大长度
+	MaxPacketSize = headerSize + maxIDSize + 2*digestSize
+)
+
+var (
+	errMalformed    = errors.New("malformed heartbeat packet")
+	errBadSignature = errors.New("heartbeat packet signature mismatch")
+	errBadToken     = errors.New("heartbeat packet service token digest mismatch")
+)
+
+// beatPacket 解析后的心跳报文
+type beatPacket struct {
+	instanceID  string
+	timestamp   time.Time
+	identity    []byte
+	tokenDigest []byte
+	payload     []byte
+	digest      []byte
+}
+
+// decodePacket 解析心跳报文，不做签名校验
+func decodePacket(data []byte) (*beatPacket, error) {
+	if len(data) < headerSize+1+digestSize || data[0] != packetVersion {
+		return nil, errMalformed
+	}
+	idLen := int(data[headerSize-1])
+	if idLen == 0 || len(data) != headerSize+idLen+2*digestSize {
+		return nil, errMalformed
+	}
+	identityLen := headerSize + idLen
+	payloadLen := identityLen + digestSize
+	return &beatPacket{
+		instanceID:  string(data[headerSize:identityLen]),
+		timestamp:   time.UnixMilli(int64(binary.BigEndian.Uint64(data[1:9]))),
+		identity:    data[:identityLen],
+		tokenDigest: data[identityLen:payloadLen],
+		payload:     data[:payloadLen],
+		digest:      data[payloadLen:],
+	}, nil
+}
+
+// verify 使用共享密钥校验报文签名
+func (p *beatPacket) verify(secret []byte) error {
+	if !hmac.Equal(p.digest, sign(secret, p.payload)) {
+		return errBadSignature
+	}
+	return nil
+}
+
+// verifyToken 使用实例所属服务的 token 校验报文的 token 摘要
+func (p *beatPacket) verifyToken(token string) error {
+	if token == "" || !hmac.Equal(p.tokenDigest, sign([]byte(token), p.identity)) {
+		return errBadToken
+	}
+	return nil
+}
+
+// encodePacket 构造签名后的心跳报文，供客户端以及测试使用，token 为实例所属服务的 token
+func encodePacket(secret []byte, token, instanceID string, timestamp time.Time) ([]byte, error) {
+	if len(instanceID) == 0 || len(instanceID) > maxIDSize {
+		return nil, errMalformed
+	}
+	data := make([]byte, headerSize, headerSize+len(instanceID)+2*digestSize)
+	data[0] = packetVersion
+	binary.BigEndian.PutUint64(data[1:9], uint64(timestamp.UnixMilli()))
+	data[headerSize-1] = byte(len(instanceID))
+	data = append(data, instanceID...)
+	data = append(data, sign([]byte(token), data)...)
+	return append(data, sign(secret, data)...), nil
+}
+
+func sign(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(payload)
+	return mac.Sum(nil)
+}
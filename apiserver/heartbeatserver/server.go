@@ -0,0 +1,236 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package heartbeatserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+
+	"github.com/polarismesh/polaris/apiserver"
+	"github.com/polarismesh/polaris/common/metrics"
+	"github.com/polarismesh/polaris/service/healthcheck"
+)
+
+// 报文被丢弃的原因，作为监控指标的标签
+const (
+	dropRateLimited  = "rate_limited"
+	dropMalformed    = "malformed"
+	dropExpired      = "expired"
+	dropBadSignature = "bad_signature"
+	dropBadToken     = "bad_token"
+	dropReplayed     = "replayed"
+	dropQueueFull    = "queue_full"
+	dropRejected     = "rejected"
+)
+
+const (
+	// sourceIdleTimeout 来源 IP 超过该时间没有报文时，回收其限流器
+	sourceIdleTimeout = time.Minute
+	// maxSourceLimiters 最多单独限流的来源 IP 数量，超出后的来源共用一个溢出令牌桶
+	maxSourceLimiters = 65536
+)
+
+// beatReporter 心跳处理接口，由健康检查模块实现
+type beatReporter interface {
+	AsyncReport(ctx context.Context, beat *apiservice.InstanceHeartbeat) apimodel.Code
+	// GetInstanceServiceToken 查询实例所属服务的 token，实例或者服务不存在时返回 false
+	GetInstanceServiceToken(instanceID string) (string, bool)
+}
+
+// HeartbeatServer 基于 UDP 的轻量心跳接入服务器，接收带签名的紧凑心跳报文，
+// 校验通过后通过批量合并交给健康检查处理，不回复任何应答
+type HeartbeatServer struct {
+	cfg *Config
+
+	lock   sync.Mutex
+	conn   net.PacketConn
+	cancel context.CancelFunc
+
+	reporter beatReporter
+	guard    *replayGuard
+	limiter  *sourceLimiter
+}
+
+// GetPort 获取端口
+func (h *HeartbeatServer) GetPort() uint32 {
+	return h.cfg.ListenPort
+}
+
+// GetProtocol 获取Server的协议
+func (h *HeartbeatServer) GetProtocol() string {
+	return "udp"
+}
+
+// Initialize 初始化 UDP 心跳服务器
+func (h *HeartbeatServer) Initialize(_ context.Context, option map[string]interface{},
+	_ map[string]apiserver.APIConfig) error {
+	cfg, err := parseConfig(option)
+	if err != nil {
+		return err
+	}
+	h.cfg = cfg
+	h.guard = newReplayGuard(cfg.MaxClockSkew)
+	h.limiter = newSourceLimiter(cfg.RateLimit, cfg.RateBurst, sourceIdleTimeout, maxSourceLimiters)
+	return nil
+}
+
+// Run 启动 UDP 心跳服务器
+func (h *HeartbeatServer) Run(errCh chan error) {
+	log.Infof("start udp heartbeat server")
+
+	healthServer, err := healthcheck.GetServer()
+	if err != nil {
+		log.Errorf("%v", err)
+		errCh <- err
+		return
+	}
+	h.reporter = healthServer
+
+	address := fmt.Sprintf("%v:%v", h.cfg.ListenIP, h.cfg.ListenPort)
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		log.Errorf("listen udp error: %v", err)
+		errCh <- err
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.lock.Lock()
+	h.conn = conn
+	h.cancel = cancel
+	h.lock.Unlock()
+
+	go h.runCleaner(ctx)
+	h.serve(ctx, conn)
+}
+
+// Stop server
+func (h *HeartbeatServer) Stop() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.cancel != nil {
+		h.cancel()
+	}
+	if h.conn != nil {
+		_ = h.conn.Close()
+	}
+}
+
+// Restart restart server
+func (h *HeartbeatServer) Restart(option map[string]interface{}, api map[string]apiserver.APIConfig,
+	errCh chan error) error {
+	log.Infof("restart udp heartbeat server with new config: %+v", option)
+
+	h.Stop()
+	if err := h.Initialize(context.Background(), option, api); err != nil {
+		return err
+	}
+	go h.Run(errCh)
+	return nil
+}
+
+// serve 报文的处理足够轻量，直接在读协程中完成，避免报文洪泛时创建大量协程
+func (h *HeartbeatServer) serve(ctx context.Context, conn net.PacketConn) {
+	buf := make([]byte, MaxPacketSize+1)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Errorf("[Heartbeat][UDP] read udp packet error: %v", err)
+			continue
+		}
+		if reason := h.handle(ctx, buf[:n], addr, time.Now()); reason != "" {
+			metrics.ReportUDPHeartbeatDropped(reason)
+		}
+	}
+}
+
+// handle 处理一个心跳报文，返回丢弃的原因，为空表示心跳已经被接受
+func (h *HeartbeatServer) handle(ctx context.Context, data []byte, addr net.Addr, now time.Time) string {
+	if !h.limiter.allow(sourceIP(addr), now) {
+		return dropRateLimited
+	}
+	packet, err := decodePacket(data)
+	if err != nil {
+		return dropMalformed
+	}
+	if skew := now.Sub(packet.timestamp); skew > h.cfg.MaxClockSkew || skew < -h.cfg.MaxClockSkew {
+		return dropExpired
+	}
+	if err := packet.verify([]byte(h.cfg.SecretKey)); err != nil {
+		log.Debugf("[Heartbeat][UDP] drop packet from %s for instance %s: %v", addr, packet.instanceID, err)
+		return dropBadSignature
+	}
+	// 共享密钥只能证明报文来自可信的客户端，还需要证明上报方持有实例所属服务的 token，与 gRPC 心跳的鉴权保持一致
+	token, ok := h.reporter.GetInstanceServiceToken(packet.instanceID)
+	if !ok {
+		return dropRejected
+	}
+	if err := packet.verifyToken(token); err != nil {
+		log.Debugf("[Heartbeat][UDP] drop packet from %s for instance %s: %v", addr, packet.instanceID, err)
+		return dropBadToken
+	}
+	// 签名校验通过后才记录时间戳，避免伪造的报文推高时间戳导致正常心跳被拒绝
+	if !h.guard.accept(packet.instanceID, packet.timestamp) {
+		return dropReplayed
+	}
+	code := h.reporter.AsyncReport(ctx, &apiservice.InstanceHeartbeat{InstanceId: packet.instanceID})
+	switch code {
+	case apimodel.Code_ExecuteSuccess:
+		return ""
+	case apimodel.Code_InstanceTooManyRequests:
+		return dropQueueFull
+	default:
+		log.Debugf("[Heartbeat][UDP] report heartbeat for instance %s, code is %v", packet.instanceID, code)
+		return dropRejected
+	}
+}
+
+// runCleaner 定期清理过期的防重放记录以及空闲的来源限流器
+func (h *HeartbeatServer) runCleaner(ctx context.Context) {
+	ticker := time.NewTicker(h.cfg.MaxClockSkew)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			h.guard.expire(now)
+			h.limiter.expire(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func sourceIP(addr net.Addr) string {
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		return udpAddr.IP.String()
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -0,0 +1,278 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package heartbeatserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testSecret = "polaris-udp-heartbeat"
+	testToken  = "service-token"
+)
+
+// fakeReporter 记录收到的心跳，按照 code 返回处理结果，除了 unknown 之外的实例所属服务的 token 都为 testToken
+type fakeReporter struct {
+	code  apimodel.Code
+	beats []*apiservice.InstanceHeartbeat
+}
+
+func (f *fakeReporter) AsyncReport(_ context.Context, beat *apiservice.InstanceHeartbeat) apimodel.Code {
+	f.beats = append(f.beats, beat)
+	return f.code
+}
+
+func (f *fakeReporter) GetInstanceServiceToken(instanceID string) (string, bool) {
+	return testToken, instanceID != "unknown"
+}
+
+func newTestServer(t *testing.T, option map[string]interface{}) (*HeartbeatServer, *fakeReporter) {
+	if option == nil {
+		option = map[string]interface{}{}
+	}
+	option["secretKey"] = testSecret
+	svr := &HeartbeatServer{}
+	assert.NoError(t, svr.Initialize(context.Background(), option, nil))
+	reporter := &fakeReporter{code: apimodel.Code_ExecuteSuccess}
+	svr.reporter = reporter
+	return svr, reporter
+}
+
+func mustEncode(t *testing.T, secret, id string, ts time.Time) []byte {
+	return mustEncodeWithToken(t, secret, testToken, id, ts)
+}
+
+func mustEncodeWithToken(t *testing.T, secret, token, id string, ts time.Time) []byte {
+	data, err := encodePacket([]byte(secret), token, id, ts)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  map[string]interface{}
+		wantErr bool
+	}{
+		{name: "no_secret", option: map[string]interface{}{}, wantErr: true},
+		{name: "default", option: map[string]interface{}{"secretKey": testSecret}},
+		{
+			name:   "duration",
+			option: map[string]interface{}{"secretKey": testSecret, "maxClockSkew": "30s"},
+		},
+		{
+			name:    "bad_skew",
+			option:  map[string]interface{}{"secretKey": testSecret, "maxClockSkew": "0s"},
+			wantErr: true,
+		},
+		{
+			name:    "bad_burst",
+			option:  map[string]interface{}{"secretKey": testSecret, "rateLimit": 10, "rateBurst": 0},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfig(tt.option)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestDecodePacket(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	data := mustEncode(t, testSecret, "ins-1", now)
+	packet, err := decodePacket(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "ins-1", packet.instanceID)
+	assert.True(t, now.Equal(packet.timestamp))
+	assert.NoError(t, packet.verify([]byte(testSecret)))
+	assert.ErrorIs(t, packet.verify([]byte("other")), errBadSignature)
+	assert.NoError(t, packet.verifyToken(testToken))
+	assert.ErrorIs(t, packet.verifyToken("other"), errBadToken)
+	assert.ErrorIs(t, packet.verifyToken(""), errBadToken)
+
+	_, err = decodePacket(data[:len(data)-1])
+	assert.ErrorIs(t, err, errMalformed)
+	_, err = decodePacket(append(data, 0))
+	assert.ErrorIs(t, err, errMalformed)
+	_, err = encodePacket([]byte(testSecret), testToken, "", now)
+	assert.ErrorIs(t, err, errMalformed)
+}
+
+func TestHandle(t *testing.T) {
+	addr := &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}
+	now := time.Now()
+	tampered := mustEncode(t, testSecret, "ins-1", now)
+	tampered[len(tampered)-1] ^= 0xff
+
+	tests := []struct {
+		name string
+		data []byte
+		code apimodel.Code
+		want string
+	}{
+		{name: "accepted", data: mustEncode(t, testSecret, "ins-1", now), code: apimodel.Code_ExecuteSuccess},
+		{name: "malformed", data: []byte{1, 2, 3}, want: dropMalformed},
+		{name: "expired", data: mustEncode(t, testSecret, "ins-1", now.Add(-time.Minute)), want: dropExpired},
+		{name: "future", data: mustEncode(t, testSecret, "ins-1", now.Add(time.Minute)), want: dropExpired},
+		{name: "bad_secret", data: mustEncode(t, "other", "ins-1", now), want: dropBadSignature},
+		{name: "tampered", data: tampered, want: dropBadSignature},
+		{
+			name: "bad_token",
+			data: mustEncodeWithToken(t, testSecret, "other", "ins-1", now),
+			want: dropBadToken,
+		},
+		{name: "unknown_instance", data: mustEncode(t, testSecret, "unknown", now), want: dropRejected},
+		{
+			name: "queue_full",
+			data: mustEncode(t, testSecret, "ins-1", now),
+			code: apimodel.Code_InstanceTooManyRequests,
+			want: dropQueueFull,
+		},
+		{
+			name: "rejected",
+			data: mustEncode(t, testSecret, "ins-1", now),
+			code: apimodel.Code_HealthCheckNotOpen,
+			want: dropRejected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svr, reporter := newTestServer(t, nil)
+			reporter.code = tt.code
+			assert.Equal(t, tt.want, svr.handle(context.Background(), tt.data, addr, now))
+			if tt.want == "" {
+				assert.Len(t, reporter.beats, 1)
+				assert.Equal(t, "ins-1", reporter.beats[0].GetInstanceId())
+			}
+		})
+	}
+}
+
+func TestHandleReplay(t *testing.T) {
+	svr, reporter := newTestServer(t, nil)
+	addr := &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}
+	now := time.Now()
+	first := mustEncode(t, testSecret, "ins-1", now)
+
+	assert.Equal(t, "", svr.handle(context.Background(), first, addr, now))
+	// 同一个报文再次发送被视为重放
+	assert.Equal(t, dropReplayed, svr.handle(context.Background(), first, addr, now))
+	// 时间戳更早的报文同样被拒绝
+	older := mustEncode(t, testSecret, "ins-1", now.Add(-time.Second))
+	assert.Equal(t, dropReplayed, svr.handle(context.Background(), older, addr, now))
+	// 其他实例不受影响
+	other := mustEncode(t, testSecret, "ins-2", now)
+	assert.Equal(t, "", svr.handle(context.Background(), other, addr, now))
+	newer := mustEncode(t, testSecret, "ins-1", now.Add(time.Second))
+	assert.Equal(t, "", svr.handle(context.Background(), newer, addr, now))
+	assert.Len(t, reporter.beats, 3)
+
+	// 超出时间窗口的记录被清理
+	svr.guard.expire(now.Add(time.Minute))
+	assert.Empty(t, svr.guard.last)
+}
+
+func TestHandleRateLimit(t *testing.T) {
+	svr, reporter := newTestServer(t, map[string]interface{}{
+		"rateLimit": 1,
+		"rateBurst": 2,
+	})
+	now := time.Now()
+	limited := &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}
+	for i := 0; i < 2; i++ {
+		data := mustEncode(t, testSecret, "ins-1", now.Add(time.Duration(i)*time.Millisecond))
+		assert.Equal(t, "", svr.handle(context.Background(), data, limited, now))
+	}
+	data := mustEncode(t, testSecret, "ins-1", now.Add(10*time.Millisecond))
+	assert.Equal(t, dropRateLimited, svr.handle(context.Background(), data, limited, now))
+
+	// 其他来源单独计算
+	other := &net.UDPAddr{IP: net.ParseIP("10.0.0.2"), Port: 5000}
+	assert.Equal(t, "", svr.handle(context.Background(), data, other, now))
+	assert.Len(t, reporter.beats, 3)
+
+	svr.limiter.expire(now.Add(2 * sourceIdleTimeout))
+	assert.Empty(t, svr.limiter.limiters)
+}
+
+func TestSourceLimiterOverflow(t *testing.T) {
+	limiter := newSourceLimiter(1, 1, sourceIdleTimeout, 2)
+	now := time.Now()
+	assert.True(t, limiter.allow("10.0.0.1", now))
+	assert.True(t, limiter.allow("10.0.0.2", now))
+	// 记录数达到上限后，伪造的新来源共用溢出令牌桶，不再增加记录
+	assert.True(t, limiter.allow("10.0.0.3", now))
+	for i := 4; i < 100; i++ {
+		assert.False(t, limiter.allow(fmt.Sprintf("10.0.0.%d", i), now))
+	}
+	assert.Len(t, limiter.limiters, 2)
+	assert.False(t, limiter.allow("10.0.0.1", now))
+
+	// 空闲的来源被回收后，新的来源重新单独限流
+	limiter.expire(now.Add(2 * sourceIdleTimeout))
+	later := now.Add(2 * sourceIdleTimeout)
+	assert.True(t, limiter.allow("10.0.0.5", later))
+	assert.Len(t, limiter.limiters, 1)
+}
+
+func TestServeUDP(t *testing.T) {
+	svr, _ := newTestServer(t, nil)
+	reported := make(chan string, 1)
+	svr.reporter = reporterFunc(func(beat *apiservice.InstanceHeartbeat) apimodel.Code {
+		reported <- beat.GetInstanceId()
+		return apimodel.Code_ExecuteSuccess
+	})
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	svr.conn = conn
+	go svr.serve(context.Background(), conn)
+	defer svr.Stop()
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	assert.NoError(t, err)
+	defer client.Close()
+	_, err = client.Write(mustEncode(t, testSecret, "ins-udp", time.Now()))
+	assert.NoError(t, err)
+
+	select {
+	case id := <-reported:
+		assert.Equal(t, "ins-udp", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("heartbeat not reported")
+	}
+}
+
+type reporterFunc func(beat *apiservice.InstanceHeartbeat) apimodel.Code
+
+func (f reporterFunc) AsyncReport(_ context.Context, beat *apiservice.InstanceHeartbeat) apimodel.Code {
+	return f(beat)
+}
+
+func (f reporterFunc) GetInstanceServiceToken(_ string) (string, bool) {
+	return testToken, true
+}
@@ -20,8 +20,11 @@ package httpserver
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/emicklei/go-restful/v3"
 	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
@@ -30,8 +33,11 @@ import (
 	"github.com/polarismesh/polaris/apiserver/httpserver/docs"
 	httpcommon "github.com/polarismesh/polaris/apiserver/httpserver/utils"
 	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/common/model/admin"
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
 	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store/backup"
 )
 
 // GetIndexServer get index server
@@ -68,6 +74,24 @@ func (h *HTTPServer) GetAdminAccessServer() *restful.WebService {
 	ws.Route(docs.EnrichGetReportClientsApiDocs(ws.GET("/report/clients").To(h.GetReportClients)))
 	ws.Route(docs.EnrichEnablePprofApiDocs(ws.POST("/pprof/enable").To(h.EnablePprof)))
 	ws.Route(docs.EnrichGetServerFunctionsApiDocs(ws.GET("/server/functions").To(h.GetServerFunctions)))
+	ws.Route(docs.EnrichGetCacheStatusApiDocs(ws.GET("/cache/status").To(h.GetCacheStatus)))
+	ws.Route(docs.EnrichBackupDataApiDocs(ws.GET("/backup").Produces(mimeGzip).To(h.BackupData)))
+	ws.Route(docs.EnrichRestoreDataApiDocs(ws.POST("/restore").Consumes(mimeGzip, mimeOctetStream).
+		To(h.RestoreData)))
+	ws.Route(docs.EnrichListServiceAccessPoliciesApiDocs(ws.GET("/service-access/policies").
+		To(h.ListServiceAccessPolicies)))
+	ws.Route(docs.EnrichCreateServiceAccessPolicyApiDocs(ws.POST("/service-access/policies").
+		To(h.CreateServiceAccessPolicy)))
+	ws.Route(docs.EnrichUpdateServiceAccessPolicyApiDocs(ws.PUT("/service-access/policies").
+		To(h.UpdateServiceAccessPolicy)))
+	ws.Route(docs.EnrichDeleteServiceAccessPolicyApiDocs(ws.POST("/service-access/policies/delete").
+		To(h.DeleteServiceAccessPolicy)))
+	ws.Route(docs.EnrichListHealthCheckSuspensionsApiDocs(ws.GET("/healthcheck/suspensions").
+		To(h.ListHealthCheckSuspensions)))
+	ws.Route(docs.EnrichCreateHealthCheckSuspensionApiDocs(ws.POST("/healthcheck/suspensions").
+		To(h.CreateHealthCheckSuspension)))
+	ws.Route(docs.EnrichCancelHealthCheckSuspensionApiDocs(ws.POST("/healthcheck/suspensions/cancel").
+		To(h.CancelHealthCheckSuspension)))
 	return ws
 }
 
@@ -316,6 +340,191 @@ func (h *HTTPServer) GetServerFunctions(req *restful.Request, rsp *restful.Respo
 	_ = rsp.WriteAsJson(ret)
 }
 
+// GetCacheStatus 查询缓存和存储的同步状态
+func (h *HTTPServer) GetCacheStatus(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+
+	ret, err := h.maintainServer.GetCacheStatus(ctx)
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteAsJson(ret)
+}
+
+// ListServiceAccessPolicies 查询服务访问策略
+// query参数：namespace、service，可选，只查询指定目标服务的策略
+func (h *HTTPServer) ListServiceAccessPolicies(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+	params := httpcommon.ParseQueryParams(req)
+
+	ret, err := h.maintainServer.ListServiceAccessPolicies(ctx, params["namespace"], params["service"])
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteAsJson(ret)
+}
+
+// CreateServiceAccessPolicy 创建服务访问策略
+func (h *HTTPServer) CreateServiceAccessPolicy(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+	policy := &authcommon.ServiceAccessPolicy{}
+	if err := httpcommon.ParseJsonBody(req, policy); err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ret, err := h.maintainServer.CreateServiceAccessPolicy(ctx, policy)
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteAsJson(ret)
+}
+
+// UpdateServiceAccessPolicy 更新服务访问策略
+func (h *HTTPServer) UpdateServiceAccessPolicy(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+	policy := &authcommon.ServiceAccessPolicy{}
+	if err := httpcommon.ParseJsonBody(req, policy); err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ret, err := h.maintainServer.UpdateServiceAccessPolicy(ctx, policy)
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteAsJson(ret)
+}
+
+// DeleteServiceAccessPolicy 删除服务访问策略
+func (h *HTTPServer) DeleteServiceAccessPolicy(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+	var deleted struct {
+		ID string `json:"id"`
+	}
+	if err := httpcommon.ParseJsonBody(req, &deleted); err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.maintainServer.DeleteServiceAccessPolicy(ctx, deleted.ID); err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteEntity("ok")
+}
+
+// ListHealthCheckSuspensions 查询还没有过期的按范围暂停健康检查的规则
+func (h *HTTPServer) ListHealthCheckSuspensions(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+
+	ret, err := h.maintainServer.ListHealthCheckSuspensions(ctx)
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteAsJson(ret)
+}
+
+// CreateHealthCheckSuspension 按范围暂停健康检查，ttl 到期之前范围内的实例不会被置为不健康
+func (h *HTTPServer) CreateHealthCheckSuspension(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+	suspension := &model.HealthCheckSuspension{}
+	if err := httpcommon.ParseJsonBody(req, suspension); err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ret, err := h.maintainServer.CreateHealthCheckSuspension(ctx, suspension)
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteAsJson(ret)
+}
+
+// CancelHealthCheckSuspension 提前取消按范围暂停健康检查的规则
+func (h *HTTPServer) CancelHealthCheckSuspension(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+	var canceled struct {
+		ID string `json:"id"`
+	}
+	if err := httpcommon.ParseJsonBody(req, &canceled); err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.maintainServer.CancelHealthCheckSuspension(ctx, canceled.ID); err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteEntity("ok")
+}
+
+const (
+	mimeGzip        = "application/gzip"
+	mimeOctetStream = "application/octet-stream"
+)
+
+// BackupData 下载备份归档文件
+// query参数：namespace，可选，只备份指定的命名空间，多个使用逗号分隔
+func (h *HTTPServer) BackupData(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+	params := httpcommon.ParseQueryParams(req)
+	opt := &backup.BackupOption{Namespaces: splitNamespaces(params["namespace"])}
+
+	filename := fmt.Sprintf("polaris-backup-%s.tar.gz", time.Now().Format("20060102150405"))
+	rsp.AddHeader("Content-Type", mimeGzip)
+	rsp.AddHeader("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	// 归档文件边生成边写入，出错时响应已经开始发送，只能中断，恢复时会因为缺少清单而拒绝该文件
+	if err := h.maintainServer.BackupData(ctx, rsp, opt); err != nil {
+		if rsp.ContentLength() == 0 {
+			_ = rsp.WriteErrorString(http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+}
+
+// RestoreData 使用请求体中的备份归档文件恢复数据
+// query参数：namespace，可选，只恢复指定的命名空间，多个使用逗号分隔
+//
+//	conflict，可选，数据已存在时的处理策略，skip 或 overwrite，默认为 skip
+func (h *HTTPServer) RestoreData(req *restful.Request, rsp *restful.Response) {
+	ctx := initContext(req)
+	params := httpcommon.ParseQueryParams(req)
+	conflict, err := backup.ParseConflictPolicy(params["conflict"])
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	opt := &backup.RestoreOption{
+		Namespaces: splitNamespaces(params["namespace"]),
+		Conflict:   conflict,
+	}
+
+	report, err := h.maintainServer.RestoreData(ctx, req.Request.Body, opt)
+	if err != nil {
+		_ = rsp.WriteErrorString(http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = rsp.WriteAsJson(report)
+}
+
+func splitNamespaces(value string) []string {
+	if value == "" {
+		return nil
+	}
+	namespaces := make([]string, 0, 4)
+	for _, ns := range strings.Split(value, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
 func initContext(req *restful.Request) context.Context {
 	ctx := context.Background()
 
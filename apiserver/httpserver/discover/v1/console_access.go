@@ -20,6 +20,7 @@ package v1
 import (
 	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/emicklei/go-restful/v3"
 	"github.com/golang/protobuf/proto"
@@ -30,6 +31,7 @@ import (
 
 	httpcommon "github.com/polarismesh/polaris/apiserver/httpserver/utils"
 	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/common/utils"
 )
 
@@ -545,6 +547,51 @@ func (h *HTTPServerV1) GetInstanceLabels(req *restful.Request, rsp *restful.Resp
 	handler.WriteHeaderAndProto(ret)
 }
 
+// GetInstanceHealthHistory 查询实例的健康状态变化时间线
+// query参数：id，必填；limit，可选，返回最近的多少条变更记录
+func (h *HTTPServerV1) GetInstanceHealthHistory(req *restful.Request, rsp *restful.Response) {
+	handler := &httpcommon.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	queryParams := httpcommon.ParseQueryParams(req)
+	instanceID := queryParams["id"]
+	if instanceID == "" {
+		handler.WriteHeaderAndProto(api.NewBatchQueryResponse(apimodel.Code_InvalidInstanceID))
+		return
+	}
+	// 通过实例查询接口完成鉴权，同时获取实例的基础信息
+	ret := h.namingServer.GetInstances(handler.ParseHeaderContext(), map[string]string{"id": instanceID})
+	if ret.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
+		handler.WriteHeaderAndProto(ret)
+		return
+	}
+	if len(ret.GetInstances()) == 0 {
+		handler.WriteHeaderAndProto(api.NewBatchQueryResponse(apimodel.Code_NotFoundInstance))
+		return
+	}
+	limit, _ := strconv.Atoi(queryParams["limit"])
+	transitions, err := h.healthCheckServer.GetInstanceHealthHistory(instanceID, limit)
+	if err != nil {
+		handler.WriteHeaderAndProto(api.NewBatchQueryResponseWithMsg(apimodel.Code_StoreLayerException, err.Error()))
+		return
+	}
+	if transitions == nil {
+		transitions = []*model.InstanceHealthTransition{}
+	}
+	instance := ret.GetInstances()[0]
+	_ = rsp.WriteAsJson(&model.InstanceHealthTimeline{
+		InstanceID:  instanceID,
+		Namespace:   instance.GetNamespace().GetValue(),
+		Service:     instance.GetService().GetValue(),
+		Host:        instance.GetHost().GetValue(),
+		Port:        instance.GetPort().GetValue(),
+		Healthy:     instance.GetHealthy().GetValue(),
+		Transitions: transitions,
+	})
+}
+
 // CreateRoutings 创建规则路由
 func (h *HTTPServerV1) CreateRoutings(req *restful.Request, rsp *restful.Response) {
 	handler := &httpcommon.Handler{
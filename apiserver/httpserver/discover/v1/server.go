@@ -111,6 +111,7 @@ func (h *HTTPServerV1) addDefaultReadAccess(ws *restful.WebService) {
 
 	ws.Route(docs.EnrichGetInstancesApiDocs(ws.GET("/instances").To(h.GetInstances)))
 	ws.Route(docs.EnrichGetInstancesCountApiDocs(ws.GET("/instances/count").To(h.GetInstancesCount)))
+	ws.Route(ws.GET("/instance/health/history").To(h.GetInstanceHealthHistory))
 	ws.Route(docs.EnrichGetRateLimitsApiDocs(ws.GET("/ratelimits").To(h.GetRateLimits)))
 	ws.Route(docs.EnrichGetCircuitBreakerRulesApiDocs(
 		ws.GET("/circuitbreaker/rules").To(h.GetCircuitBreakerRules)))
@@ -179,6 +180,7 @@ func (h *HTTPServerV1) addServiceAccess(ws *restful.WebService) {
 	ws.Route(docs.EnrichGetInstancesApiDocs(ws.GET("/instances").To(h.GetInstances)))
 	ws.Route(docs.EnrichGetInstancesCountApiDocs(ws.GET("/instances/count").To(h.GetInstancesCount)))
 	ws.Route(docs.EnrichGetInstanceLabelsApiDocs(ws.GET("/instances/labels").To(h.GetInstanceLabels)))
+	ws.Route(ws.GET("/instance/health/history").To(h.GetInstanceHealthHistory))
 
 	// 服务契约相关
 	ws.Route(docs.EnrichCreateServiceContractsApiDocs(
@@ -25,6 +25,8 @@ import (
 
 	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/common/model/admin"
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	"github.com/polarismesh/polaris/store/backup"
 )
 
 var (
@@ -156,3 +158,87 @@ func EnrichGetServerFunctionsApiDocs(r *restful.RouteBuilder) *restful.RouteBuil
 		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
 		Returns(0, "", map[string][]string{})
 }
+
+func EnrichGetCacheStatusApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("查询缓存和存储的同步状态，存在尚未同步的缓存时处于降级状态").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Returns(0, "", admin.CacheStatus{})
+}
+
+func EnrichBackupDataApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("导出全量数据的备份归档文件(tar.gz)").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Param(restful.QueryParameter("namespace", "只备份指定的命名空间，多个使用逗号分隔").
+			DataType(typeNameString).Required(false))
+}
+
+func EnrichRestoreDataApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("使用备份归档文件恢复数据").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Param(restful.QueryParameter("namespace", "只恢复指定的命名空间，多个使用逗号分隔").
+			DataType(typeNameString).Required(false)).
+		Param(restful.QueryParameter("conflict", "数据已存在时的处理策略: skip(默认)/overwrite").
+			DataType(typeNameString).Required(false)).
+		Returns(0, "", backup.RestoreReport{})
+}
+
+func EnrichListServiceAccessPoliciesApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("查询服务间访问策略").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Param(restful.QueryParameter("namespace", "目标服务所在的命名空间").DataType(typeNameString).Required(false)).
+		Param(restful.QueryParameter("service", "目标服务").DataType(typeNameString).Required(false)).
+		Returns(0, "", []authcommon.ServiceAccessPolicy{})
+}
+
+func EnrichCreateServiceAccessPolicyApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("创建服务间访问策略，由目标服务 Sidecar 的 ext_authz 过滤器执行").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Reads(authcommon.ServiceAccessPolicy{}).
+		Returns(0, "", authcommon.ServiceAccessPolicy{})
+}
+
+func EnrichUpdateServiceAccessPolicyApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("更新服务间访问策略，目标服务不允许修改").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Reads(authcommon.ServiceAccessPolicy{}).
+		Returns(0, "", authcommon.ServiceAccessPolicy{})
+}
+
+func EnrichDeleteServiceAccessPolicyApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("删除服务间访问策略").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Reads(struct {
+			ID string `json:"id"`
+		}{})
+}
+
+func EnrichListHealthCheckSuspensionsApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("查询按范围暂停健康检查的规则").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Returns(0, "", []model.HealthCheckSuspension{})
+}
+
+func EnrichCreateHealthCheckSuspensionApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("按命名空间、服务或者实例位置暂停健康检查，ttl 到期之前范围内的实例不会被置为不健康").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Reads(model.HealthCheckSuspension{}).
+		Returns(0, "", model.HealthCheckSuspension{})
+}
+
+func EnrichCancelHealthCheckSuspensionApiDocs(r *restful.RouteBuilder) *restful.RouteBuilder {
+	return r.
+		Doc("取消按范围暂停健康检查的规则").
+		Metadata(restfulspec.KeyOpenAPITags, maintainApiTags).
+		Reads(struct {
+			ID string `json:"id"`
+		}{})
+}
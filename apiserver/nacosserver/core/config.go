@@ -0,0 +1,116 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+
+	"github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	cachetypes "github.com/polarismesh/polaris/cache/api"
+	api "github.com/polarismesh/polaris/common/api/v1"
+	commonmodel "github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/config"
+)
+
+// PublishGrayConfig nacos 的 beta/tag 发布，先更新配置文件内容，再按照客户端标签做北极星的灰度发布。
+// 北极星同一个配置文件只能存在一个灰度发布，因此一个配置同时只支持一个 beta 或者一个 tag，
+// 新的 beta/tag 发布会先停止已有的灰度发布并替换它
+func PublishGrayConfig(ctx context.Context, configSvr config.ConfigCenterServer,
+	req *config_manage.ConfigFilePublishInfo, labels []*apimodel.ClientLabel) *config_manage.ConfigResponse {
+	if rsp := StopGrayConfig(ctx, configSvr, req.GetNamespace().GetValue(), req.GetGroup().GetValue(),
+		req.GetFileName().GetValue()); rsp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
+		return rsp
+	}
+
+	specFile := &config_manage.ConfigFile{
+		Namespace:   req.GetNamespace(),
+		Group:       req.GetGroup(),
+		Name:        req.GetFileName(),
+		Content:     req.GetContent(),
+		Format:      req.GetFormat(),
+		Comment:     req.GetComment(),
+		Tags:        req.GetTags(),
+		Encrypted:   req.GetEncrypted(),
+		EncryptAlgo: req.GetEncryptAlgo(),
+		CreateBy:    utils.NewStringValue(utils.ParseUserName(ctx)),
+		ModifyBy:    utils.NewStringValue(utils.ParseUserName(ctx)),
+	}
+	upsertRsp := configSvr.CreateConfigFileFromClient(ctx, specFile)
+	if upsertRsp.GetCode().GetValue() == uint32(apimodel.Code_ExistedResource) {
+		upsertRsp = configSvr.UpdateConfigFileFromClient(ctx, specFile)
+	}
+	switch upsertRsp.GetCode().GetValue() {
+	case uint32(apimodel.Code_ExecuteSuccess), uint32(apimodel.Code_NoNeedUpdate):
+	default:
+		return &config_manage.ConfigResponse{Code: upsertRsp.GetCode(), Info: upsertRsp.GetInfo()}
+	}
+
+	releaseRsp := configSvr.PublishConfigFileFromClient(ctx, &config_manage.ConfigFileRelease{
+		Namespace:          req.GetNamespace(),
+		Group:              req.GetGroup(),
+		FileName:           req.GetFileName(),
+		ReleaseDescription: req.GetReleaseDescription(),
+		ReleaseType:        wrapperspb.String(commonmodel.ReleaseTypeGray),
+		BetaLabels:         labels,
+		CreateBy:           utils.NewStringValue(utils.ParseUserName(ctx)),
+		ModifyBy:           utils.NewStringValue(utils.ParseUserName(ctx)),
+	})
+	return &config_manage.ConfigResponse{Code: releaseRsp.GetCode(), Info: releaseRsp.GetInfo()}
+}
+
+// StopGrayConfig 停止配置文件的灰度发布，对应 nacos 的 stop beta 以及删除 tag 配置
+func StopGrayConfig(ctx context.Context, configSvr config.ConfigCenterServer,
+	namespace, group, dataId string) *config_manage.ConfigResponse {
+	rsp := configSvr.StopGrayConfigFileReleases(ctx, []*config_manage.ConfigFileRelease{
+		{
+			Namespace: wrapperspb.String(namespace),
+			Group:     wrapperspb.String(group),
+			FileName:  wrapperspb.String(dataId),
+		},
+	})
+	return &config_manage.ConfigResponse{Code: rsp.GetCode(), Info: rsp.GetInfo()}
+}
+
+// CheckNoActiveGrayConfig 北极星存在灰度发布时无法进行全量发布，全量发布也不能替用户停止正在进行的
+// beta/tag 发布，因此存在灰度发布时直接拒绝，需要先停止 beta 或者删除 tag 配置后再全量发布
+func CheckNoActiveGrayConfig(cacheMgr cachetypes.CacheManager, namespace, group,
+	dataId string) *config_manage.ConfigResponse {
+	if cacheMgr.ConfigFile().GetActiveGrayRelease(namespace, group, dataId) != nil {
+		return api.NewConfigResponseWithInfo(apimodel.Code_DataConflict,
+			"config has an active beta or tag release, stop it before publishing the full config")
+	}
+	return &config_manage.ConfigResponse{Code: wrapperspb.UInt32(uint32(apimodel.Code_ExecuteSuccess))}
+}
+
+// MatchGrayConfig 获取客户端命中的灰度发布以及对应的灰度规则，未命中时返回 nil
+func MatchGrayConfig(cacheMgr cachetypes.CacheManager, namespace, group, dataId string,
+	labels map[string]string) (*commonmodel.ConfigFileRelease, []*apimodel.ClientLabel) {
+	gray := cacheMgr.ConfigFile().GetActiveGrayRelease(namespace, group, dataId)
+	if gray == nil {
+		return nil, nil
+	}
+	grayKey := commonmodel.GetGrayConfigRealseKey(gray.SimpleConfigFileRelease)
+	if !cacheMgr.Gray().HitGrayRule(grayKey, labels) {
+		return nil, nil
+	}
+	return gray, cacheMgr.Gray().GetGrayRule(grayKey)
+}
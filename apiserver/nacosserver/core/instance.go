@@ -0,0 +1,68 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+
+	nacosmodel "github.com/polarismesh/polaris/apiserver/nacosserver/model"
+	cachetypes "github.com/polarismesh/polaris/cache/api"
+)
+
+// CheckInstanceEphemeral nacos 中同一个服务下只能存在临时实例或者持久化实例中的一种，
+// 服务下已有实例的类型和本次注册的实例类型不一致时拒绝注册
+// com.alibaba.nacos.naming.core.v2.service.impl.EphemeralClientOperationServiceImpl#registerInstance
+func CheckInstanceEphemeral(cacheMgr cachetypes.CacheManager, namespace, service string, ephemeral bool) error {
+	svc := cacheMgr.Service().GetServiceByName(nacosmodel.ReplaceNacosService(service), namespace)
+	if svc == nil {
+		return nil
+	}
+	for _, ins := range cacheMgr.Instance().GetInstancesByServiceID(svc.ID) {
+		if (ins.Metadata()[nacosmodel.InternalNacosEphemeral] != "false") == ephemeral {
+			continue
+		}
+		groupedName := nacosmodel.GetGroupName(service) + nacosmodel.DefaultNacosGroupConnectStr +
+			nacosmodel.GetServiceName(service)
+		if ephemeral {
+			return &nacosmodel.NacosError{
+				ErrCode: int32(nacosmodel.ExceptionCode_InvalidParam),
+				ErrMsg: fmt.Sprintf("Current service %s is persistent service, can't register ephemeral instance.",
+					groupedName),
+			}
+		}
+		return &nacosmodel.NacosError{
+			ErrCode: int32(nacosmodel.ExceptionCode_InvalidParam),
+			ErrMsg: fmt.Sprintf("Current service %s is ephemeral service, can't register persistent instance.",
+				groupedName),
+		}
+	}
+	return nil
+}
+
+// GetClusterMetadata 获取持久化实例所在集群的配置，服务不存在时使用默认配置
+func GetClusterMetadata(cacheMgr cachetypes.CacheManager, namespace, service,
+	cluster string) *nacosmodel.ClusterMetadata {
+	if cluster == "" {
+		cluster = nacosmodel.DefaultServiceClusterName
+	}
+	svc := cacheMgr.Service().GetServiceByName(nacosmodel.ReplaceNacosService(service), namespace)
+	if svc == nil {
+		return nacosmodel.DefaultClusterMetadata()
+	}
+	return nacosmodel.GetClusterMetadata(svc.Meta, cluster)
+}
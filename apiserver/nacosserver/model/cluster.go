@@ -0,0 +1,144 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+const (
+	HealthCheckerTCP  = "TCP"
+	HealthCheckerHTTP = "HTTP"
+	HealthCheckerNone = "NONE"
+
+	DefaultClusterCheckPort = 80
+)
+
+// ClusterMetadata nacos 集群的配置，北极星中没有集群资源，保存在服务的元数据中
+type ClusterMetadata struct {
+	HealthChecker    *HealthChecker    `json:"healthChecker"`
+	DefaultCheckPort int               `json:"defaultCheckPort"`
+	UseIPPort4Check  bool              `json:"useIPPort4Check"`
+	Metadata         map[string]string `json:"metadata"`
+}
+
+// DefaultClusterMetadata nacos 中集群默认使用实例端口进行 TCP 健康检查
+func DefaultClusterMetadata() *ClusterMetadata {
+	return &ClusterMetadata{
+		HealthChecker:    &HealthChecker{Type: HealthCheckerTCP},
+		DefaultCheckPort: DefaultClusterCheckPort,
+		UseIPPort4Check:  true,
+		Metadata:         map[string]string{},
+	}
+}
+
+// ClusterMetadataKey 集群配置在服务元数据中的 key
+func ClusterMetadataKey(cluster string) string {
+	return InternalNacosClusterPrefix + cluster
+}
+
+// GetClusterMetadata 从服务元数据中获取集群配置，没有配置时使用默认值
+func GetClusterMetadata(svcMeta map[string]string, cluster string) *ClusterMetadata {
+	val, ok := svcMeta[ClusterMetadataKey(cluster)]
+	if !ok {
+		return DefaultClusterMetadata()
+	}
+	ret := DefaultClusterMetadata()
+	if err := json.Unmarshal([]byte(val), ret); err != nil || ret.HealthChecker == nil {
+		return DefaultClusterMetadata()
+	}
+	if ret.Metadata == nil {
+		ret.Metadata = map[string]string{}
+	}
+	return ret
+}
+
+// ListClusterNames 获取服务元数据中配置过的集群名称
+func ListClusterNames(svcMeta map[string]string) []string {
+	ret := make([]string, 0, 4)
+	for k := range svcMeta {
+		if strings.HasPrefix(k, InternalNacosClusterPrefix) {
+			ret = append(ret, strings.TrimPrefix(k, InternalNacosClusterPrefix))
+		}
+	}
+	return ret
+}
+
+// ParseHealthChecker 解析集群的健康检查方式，目前只支持 TCP、HTTP 以及 NONE
+func ParseHealthChecker(val string) (*HealthChecker, error) {
+	checker := &HealthChecker{}
+	if err := json.Unmarshal([]byte(val), checker); err != nil {
+		return nil, &NacosError{
+			ErrCode: int32(ExceptionCode_InvalidParam),
+			ErrMsg:  "healthChecker format incorrect: " + val,
+		}
+	}
+	checker.Type = strings.ToUpper(checker.Type)
+	switch checker.Type {
+	case HealthCheckerTCP, HealthCheckerNone:
+	case HealthCheckerHTTP:
+		if checker.ExpectedResponseCode == 0 {
+			checker.ExpectedResponseCode = 200
+		}
+	default:
+		return nil, &NacosError{
+			ErrCode: int32(ExceptionCode_InvalidParam),
+			ErrMsg:  fmt.Sprintf("unknown health check type:%s", val),
+		}
+	}
+	return checker, nil
+}
+
+// PreparePersistentInstance 持久化实例由服务端主动探测健康状态，按照集群的健康检查方式设置实例的探测参数
+func PreparePersistentInstance(ins *apiservice.Instance, cluster *ClusterMetadata) {
+	if ins.Metadata == nil {
+		ins.Metadata = map[string]string{}
+	}
+	ins.Metadata[InternalNacosEphemeral] = "false"
+	// 持久化实例不会上报心跳，健康检查的开关始终打开，探测方式由元数据决定，NONE 类型不配置探测方式即不做检查
+	ins.EnableHealthCheck = wrapperspb.Bool(true)
+	ins.HealthCheck = nil
+	// 清理探测方式相关的参数，探测周期以及阈值等参数允许用户在实例元数据中自行调整
+	delete(ins.Metadata, model.MetaKeyHealthProbeType)
+	delete(ins.Metadata, model.MetaKeyHealthProbePath)
+	delete(ins.Metadata, model.MetaKeyHealthProbeStatus)
+	delete(ins.Metadata, model.MetaKeyHealthProbePort)
+	switch cluster.HealthChecker.Type {
+	case HealthCheckerTCP:
+		ins.Metadata[model.MetaKeyHealthProbeType] = "tcp"
+	case HealthCheckerHTTP:
+		ins.Metadata[model.MetaKeyHealthProbeType] = "http"
+		ins.Metadata[model.MetaKeyHealthProbePath] = cluster.HealthChecker.Path
+		if cluster.HealthChecker.ExpectedResponseCode > 0 {
+			ins.Metadata[model.MetaKeyHealthProbeStatus] = strconv.Itoa(cluster.HealthChecker.ExpectedResponseCode)
+		}
+	default:
+		return
+	}
+	if !cluster.UseIPPort4Check && cluster.DefaultCheckPort > 0 {
+		ins.Metadata[model.MetaKeyHealthProbePort] = strconv.Itoa(cluster.DefaultCheckPort)
+	}
+}
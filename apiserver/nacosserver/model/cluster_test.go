@@ -0,0 +1,147 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+func TestParseHealthChecker(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     string
+		want    *HealthChecker
+		wantErr bool
+	}{
+		{
+			name: "tcp",
+			val:  `{"type":"tcp"}`,
+			want: &HealthChecker{Type: HealthCheckerTCP},
+		},
+		{
+			name: "http",
+			val:  `{"type":"HTTP","path":"/health"}`,
+			want: &HealthChecker{Type: HealthCheckerHTTP, Path: "/health", ExpectedResponseCode: 200},
+		},
+		{
+			name:    "unknown",
+			val:     `{"type":"MYSQL"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			val:     `TCP`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHealthChecker(tt.val)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHealthChecker() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseHealthChecker() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreparePersistentInstance(t *testing.T) {
+	tests := []struct {
+		name    string
+		cluster *ClusterMetadata
+		want    map[string]string
+	}{
+		{
+			name:    "default",
+			cluster: DefaultClusterMetadata(),
+			want: map[string]string{
+				InternalNacosEphemeral:       "false",
+				model.MetaKeyHealthProbeType: "tcp",
+			},
+		},
+		{
+			name: "http",
+			cluster: &ClusterMetadata{
+				HealthChecker:    &HealthChecker{Type: HealthCheckerHTTP, Path: "/health", ExpectedResponseCode: 204},
+				DefaultCheckPort: 8080,
+			},
+			want: map[string]string{
+				InternalNacosEphemeral:         "false",
+				model.MetaKeyHealthProbeType:   "http",
+				model.MetaKeyHealthProbePath:   "/health",
+				model.MetaKeyHealthProbeStatus: "204",
+				model.MetaKeyHealthProbePort:   "8080",
+			},
+		},
+		{
+			name:    "none",
+			cluster: &ClusterMetadata{HealthChecker: &HealthChecker{Type: HealthCheckerNone}},
+			want: map[string]string{
+				InternalNacosEphemeral: "false",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ins := PrepareSpecInstance("default", "svc", &Instance{IP: "127.0.0.1", Port: 8080, Ephemeral: false})
+			// 切换健康检查方式时需要清理之前的探测参数
+			ins.Metadata[model.MetaKeyHealthProbePath] = "/old"
+			PreparePersistentInstance(ins, tt.cluster)
+			if !ins.GetEnableHealthCheck().GetValue() || ins.GetHealthCheck() != nil {
+				t.Fatalf("unexpected health check %v %v", ins.GetEnableHealthCheck(), ins.GetHealthCheck())
+			}
+			delete(ins.Metadata, InternalNacosCluster)
+			delete(ins.Metadata, InternalNacosServiceName)
+			if !reflect.DeepEqual(ins.Metadata, tt.want) {
+				t.Errorf("PreparePersistentInstance() = %v, want %v", ins.Metadata, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetClusterMetadata(t *testing.T) {
+	svcMeta := map[string]string{
+		ClusterMetadataKey("c1"): `{"healthChecker":{"type":"NONE"},"defaultCheckPort":80,"useIPPort4Check":true}`,
+		ClusterMetadataKey("c2"): `invalid`,
+	}
+	if got := GetClusterMetadata(svcMeta, "c1"); got.HealthChecker.Type != HealthCheckerNone || got.Metadata == nil {
+		t.Errorf("GetClusterMetadata(c1) = %+v", got)
+	}
+	if got := GetClusterMetadata(svcMeta, "c2"); !reflect.DeepEqual(got, DefaultClusterMetadata()) {
+		t.Errorf("GetClusterMetadata(c2) = %+v", got)
+	}
+	if got := GetClusterMetadata(svcMeta, "c3"); !reflect.DeepEqual(got, DefaultClusterMetadata()) {
+		t.Errorf("GetClusterMetadata(c3) = %+v", got)
+	}
+}
+
+func TestInstanceEphemeral(t *testing.T) {
+	for _, ephemeral := range []bool{true, false} {
+		specIns := PrepareSpecInstance("default", "svc", &Instance{IP: "127.0.0.1", Port: 8080, Ephemeral: ephemeral})
+		ins := &Instance{}
+		ins.FromSpecInstance(&model.Instance{Proto: specIns})
+		if ins.Ephemeral != ephemeral {
+			t.Errorf("FromSpecInstance() ephemeral = %v, want %v", ins.Ephemeral, ephemeral)
+		}
+	}
+}
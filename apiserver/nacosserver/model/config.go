@@ -224,3 +224,36 @@ func ParseConfigListenContext(req *restful.Request, configKeysString string) (*C
 
 	return watchCtx, nil
 }
+
+const (
+	// ConfigOpTypeUpdate 配置发布
+	ConfigOpTypeUpdate = "U"
+	// ConfigOpTypeDelete 配置删除
+	ConfigOpTypeDelete = "D"
+)
+
+// ConfigHistoryInfo /nacos/v1/cs/history 中的配置历史信息
+type ConfigHistoryInfo struct {
+	Id               uint64 `json:"id,string"`
+	LastId           int64  `json:"lastId"`
+	DataId           string `json:"dataId"`
+	Group            string `json:"group"`
+	Tenant           string `json:"tenant"`
+	AppName          string `json:"appName"`
+	Md5              string `json:"md5"`
+	Content          string `json:"content"`
+	SrcIp            string `json:"srcIp"`
+	SrcUser          string `json:"srcUser"`
+	OpType           string `json:"opType"`
+	CreatedTime      int64  `json:"createdTime"`
+	LastModifiedTime int64  `json:"lastModifiedTime"`
+	EncryptedDataKey string `json:"encryptedDataKey"`
+}
+
+// Page nacos 分页查询的应答
+type Page struct {
+	TotalCount     int         `json:"totalCount"`
+	PageNumber     int         `json:"pageNumber"`
+	PagesAvailable int         `json:"pagesAvailable"`
+	PageItems      interface{} `json:"pageItems"`
+}
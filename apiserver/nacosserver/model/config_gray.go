@@ -0,0 +1,138 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"strings"
+
+	"github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	commonmodel "github.com/polarismesh/polaris/common/model"
+)
+
+const (
+	// ConfigClientLabelTag nacos 客户端配置的 tag，作为北极星灰度发布规则中的客户端标签
+	ConfigClientLabelTag = "NACOS_CONFIG_TAG"
+	// HeaderBetaIps nacos beta 发布时通过该请求头指定灰度的客户端 IP 列表
+	HeaderBetaIps = "betaIps"
+	// HeaderVipserverTag nacos 客户端监听配置时通过该请求头携带自身的 tag
+	HeaderVipserverTag = "Vipserver-Tag"
+	// HeaderIsBeta 客户端查询到的是 beta 发布的配置
+	HeaderIsBeta = "isBeta"
+
+	ParamBeta = "beta"
+	ParamTag  = "tag"
+)
+
+// ConfigInfo4Beta /nacos/v1/cs/configs?beta=true 中的 beta 配置信息
+type ConfigInfo4Beta struct {
+	DataId           string `json:"dataId"`
+	Group            string `json:"group"`
+	Tenant           string `json:"tenant"`
+	Content          string `json:"content"`
+	Md5              string `json:"md5"`
+	BetaIps          string `json:"betaIps"`
+	EncryptedDataKey string `json:"encryptedDataKey"`
+	LastModified     int64  `json:"lastModified"`
+}
+
+// BuildBetaGrayLabels nacos beta 发布转为北极星灰度规则，客户端 IP 在 betaIps 中即命中
+func BuildBetaGrayLabels(betaIps string) []*apimodel.ClientLabel {
+	ips := make([]string, 0, 4)
+	for _, ip := range strings.Split(betaIps, ",") {
+		if ip = strings.TrimSpace(ip); ip != "" {
+			ips = append(ips, ip)
+		}
+	}
+	return []*apimodel.ClientLabel{
+		{
+			Key: commonmodel.ClientLabel_IP,
+			Value: &apimodel.MatchString{
+				Type:  apimodel.MatchString_IN,
+				Value: wrapperspb.String(strings.Join(ips, ",")),
+			},
+		},
+	}
+}
+
+// BuildTagGrayLabels nacos tag 发布转为北极星灰度规则，客户端的 tag 相同即命中
+func BuildTagGrayLabels(tag string) []*apimodel.ClientLabel {
+	return []*apimodel.ClientLabel{
+		{
+			Key: ConfigClientLabelTag,
+			Value: &apimodel.MatchString{
+				Type:  apimodel.MatchString_EXACT,
+				Value: wrapperspb.String(tag),
+			},
+		},
+	}
+}
+
+// ToGrayLabels nacos 的 beta 发布优先于 tag 发布，两者都没有时为全量发布
+func (i *ConfigFile) ToGrayLabels() []*apimodel.ClientLabel {
+	if strings.TrimSpace(i.BetaIps) != "" {
+		return BuildBetaGrayLabels(i.BetaIps)
+	}
+	if i.Tag != "" {
+		return BuildTagGrayLabels(i.Tag)
+	}
+	return nil
+}
+
+// ParseBetaIps 灰度规则是由 nacos beta 发布产生时返回 betaIps
+func ParseBetaIps(rule []*apimodel.ClientLabel) (string, bool) {
+	if len(rule) != 1 || rule[0].GetKey() != commonmodel.ClientLabel_IP ||
+		rule[0].GetValue().GetType() != apimodel.MatchString_IN {
+		return "", false
+	}
+	return rule[0].GetValue().GetValue().GetValue(), true
+}
+
+// ParseGrayTag 灰度规则是由 nacos tag 发布产生时返回 tag
+func ParseGrayTag(rule []*apimodel.ClientLabel) (string, bool) {
+	if len(rule) != 1 || rule[0].GetKey() != ConfigClientLabelTag {
+		return "", false
+	}
+	return rule[0].GetValue().GetValue().GetValue(), true
+}
+
+// ToConfigClientLabels nacos 客户端用于匹配灰度规则的标签
+func ToConfigClientLabels(clientIP, tag string) map[string]string {
+	labels := map[string]string{
+		commonmodel.ClientLabel_IP: clientIP,
+	}
+	if tag != "" {
+		labels[ConfigClientLabelTag] = tag
+	}
+	return labels
+}
+
+// ToConfigClientTags nacos 客户端查询配置时携带的标签，北极星根据标签匹配灰度发布的配置
+func ToConfigClientTags(clientIP, tag string) []*config_manage.ConfigFileTag {
+	labels := ToConfigClientLabels(clientIP, tag)
+	tags := make([]*config_manage.ConfigFileTag, 0, len(labels))
+	for k, v := range labels {
+		tags = append(tags, &config_manage.ConfigFileTag{
+			Key:   wrapperspb.String(k),
+			Value: wrapperspb.String(v),
+		})
+	}
+	return tags
+}
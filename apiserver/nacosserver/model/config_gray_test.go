@@ -0,0 +1,74 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFile_ToGrayLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    *ConfigFile
+		betaIps string
+		tag     string
+	}{
+		{
+			name: "full",
+			file: &ConfigFile{},
+		},
+		{
+			name:    "beta",
+			file:    &ConfigFile{BetaIps: " 127.0.0.1, 127.0.0.2 ,"},
+			betaIps: "127.0.0.1,127.0.0.2",
+		},
+		{
+			name:    "beta-before-tag",
+			file:    &ConfigFile{BetaIps: "127.0.0.1", Tag: "gray"},
+			betaIps: "127.0.0.1",
+		},
+		{
+			name: "tag",
+			file: &ConfigFile{Tag: "gray"},
+			tag:  "gray",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := tt.file.ToGrayLabels()
+			betaIps, isBeta := ParseBetaIps(labels)
+			tag, isTag := ParseGrayTag(labels)
+			assert.Equal(t, tt.betaIps != "", isBeta)
+			assert.Equal(t, tt.betaIps, betaIps)
+			assert.Equal(t, tt.tag != "", isTag)
+			assert.Equal(t, tt.tag, tag)
+			if tt.betaIps == "" && tt.tag == "" {
+				assert.Empty(t, labels)
+			}
+		})
+	}
+}
+
+func TestToConfigClientLabels(t *testing.T) {
+	assert.Equal(t, map[string]string{"CLIENT_IP": "127.0.0.1"}, ToConfigClientLabels("127.0.0.1", ""))
+	assert.Equal(t, map[string]string{"CLIENT_IP": "127.0.0.1", ConfigClientLabelTag: "gray"},
+		ToConfigClientLabels("127.0.0.1", "gray"))
+	assert.Len(t, ToConfigClientTags("127.0.0.1", "gray"), 2)
+}
@@ -54,6 +54,10 @@ const (
 	ParamPageSize          = "pageSize"
 	ParamSelector          = "selector"
 	ParamTenant            = "tenant"
+	ParamProtectThreshold  = "protectThreshold"
+	ParamHealthChecker     = "healthChecker"
+	ParamCheckPort         = "checkPort"
+	ParamUseInstancePort   = "useInstancePort4Check"
 )
 
 const (
@@ -74,10 +78,14 @@ const (
 )
 
 const (
+	InternalMetadataPrefix               = "internal-"
 	InternalNacosCluster                 = "internal-nacos-cluster"
 	InternalNacosServiceName             = "internal-nacos-service"
 	InternalNacosServiceProtectThreshold = "internal-nacos-protectThreshold"
 	InternalNacosClientConnectionID      = "internal-nacos-clientconnId"
+	InternalNacosNamespaceShowName       = "internal-nacos-namespace-showName"
+	InternalNacosEphemeral               = "internal-nacos-ephemeral"
+	InternalNacosClusterPrefix           = "internal-nacos-cluster-"
 )
 
 const (
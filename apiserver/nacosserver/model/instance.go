@@ -85,7 +85,7 @@ func (i *Instance) FromSpecInstance(specIns *model.Instance) {
 	i.IP = specIns.Host()
 	i.Port = int32(specIns.Port())
 	i.Weight = float64(specIns.Weight())
-	i.Ephemeral = true
+	i.Ephemeral = specIns.Metadata()[InternalNacosEphemeral] != "false"
 	i.Healthy = specIns.Healthy()
 	i.Enabled = !specIns.Isolate()
 
@@ -171,6 +171,9 @@ func PrepareSpecInstance(namespace, service string, ins *Instance) *apiservice.I
 
 	specIns.Metadata[InternalNacosCluster] = ins.ClusterName
 	specIns.Metadata[InternalNacosServiceName] = service
+	if !ins.Ephemeral {
+		specIns.Metadata[InternalNacosEphemeral] = "false"
+	}
 
 	return specIns
 }
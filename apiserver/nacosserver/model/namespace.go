@@ -0,0 +1,44 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+const (
+	// NamespaceTypeGlobal nacos 的 public 命名空间
+	NamespaceTypeGlobal = 0
+	// NamespaceTypeCustom 用户创建的命名空间
+	NamespaceTypeCustom = 2
+	// DefaultNamespaceQuota nacos 命名空间下默认的配置数量配额
+	DefaultNamespaceQuota = 200
+)
+
+// Namespace /nacos/v1/console/namespaces 中的命名空间信息
+type Namespace struct {
+	Namespace         string `json:"namespace"`
+	NamespaceShowName string `json:"namespaceShowName"`
+	NamespaceDesc     string `json:"namespaceDesc"`
+	Quota             int    `json:"quota"`
+	ConfigCount       int    `json:"configCount"`
+	Type              int    `json:"type"`
+}
+
+// RestResult nacos 控制台接口的通用应答
+type RestResult struct {
+	Code    int         `json:"code"`
+	Message *string     `json:"message"`
+	Data    interface{} `json:"data"`
+}
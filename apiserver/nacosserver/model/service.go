@@ -77,3 +77,78 @@ func HandleServiceListRequest(discoverSvr service.DiscoverServer, namespace stri
 	}
 	return viewList, len(services)
 }
+
+// Selector 服务的实例选择器，北极星不支持 nacos 的选择器，固定为 none
+type Selector struct {
+	Type        string `json:"type"`
+	ContextType string `json:"contextType"`
+}
+
+// NoneSelector .
+var NoneSelector = &Selector{Type: "none", ContextType: "NONE"}
+
+// HealthChecker 集群的健康检查方式
+type HealthChecker struct {
+	Type                 string `json:"type"`
+	Path                 string `json:"path,omitempty"`
+	Headers              string `json:"headers,omitempty"`
+	ExpectedResponseCode int    `json:"expectedResponseCode,omitempty"`
+}
+
+// ClusterInfo 服务下的集群信息，北极星中集群只是实例的一个标签
+type ClusterInfo struct {
+	ServiceName   string            `json:"serviceName,omitempty"`
+	Name          string            `json:"name"`
+	HealthChecker *HealthChecker    `json:"healthChecker"`
+	Metadata      map[string]string `json:"metadata"`
+}
+
+// ServiceDetail /nacos/v1/ns/service 查询服务详情的应答
+type ServiceDetail struct {
+	NamespaceId      string            `json:"namespaceId,omitempty"`
+	GroupName        string            `json:"groupName"`
+	Name             string            `json:"name"`
+	ProtectThreshold float64           `json:"protectThreshold"`
+	Metadata         map[string]string `json:"metadata"`
+	Selector         *Selector         `json:"selector"`
+	Clusters         []*ClusterInfo    `json:"clusters,omitempty"`
+}
+
+// CatalogService /nacos/v1/ns/catalog/services 服务列表中的服务概要
+type CatalogService struct {
+	Name                 string `json:"name"`
+	GroupName            string `json:"groupName"`
+	ClusterCount         int    `json:"clusterCount"`
+	IpCount              int    `json:"ipCount"`
+	HealthyInstanceCount int    `json:"healthyInstanceCount"`
+	TriggerFlag          string `json:"triggerFlag"`
+}
+
+// CatalogCluster 服务列表中携带实例时的集群信息
+type CatalogCluster struct {
+	ServiceName   string            `json:"serviceName"`
+	ClusterName   string            `json:"clusterName"`
+	HealthChecker *HealthChecker    `json:"healthChecker"`
+	Metadata      map[string]string `json:"metadata"`
+	Hosts         []*Instance       `json:"hosts"`
+}
+
+// CatalogServiceDetail 服务列表中携带实例时的服务信息
+type CatalogServiceDetail struct {
+	ServiceName string                     `json:"serviceName"`
+	GroupName   string                     `json:"groupName"`
+	ClusterMap  map[string]*CatalogCluster `json:"clusterMap"`
+	Metadata    map[string]string          `json:"metadata"`
+}
+
+// ToNacosServiceMetadata 过滤掉服务元数据中 internal- 开头的内部数据
+func ToNacosServiceMetadata(meta map[string]string) map[string]string {
+	ret := make(map[string]string, len(meta))
+	for k, v := range meta {
+		if strings.HasPrefix(k, InternalMetadataPrefix) {
+			continue
+		}
+		ret[k] = v
+	}
+	return ret
+}
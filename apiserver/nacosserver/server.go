@@ -51,14 +51,15 @@ type NacosServer struct {
 	pushCenter core.PushCenter
 	store      *core.NacosDataStorage
 
-	userSvr           auth.UserServer
-	strategySvr       auth.StrategyServer
-	namespaceSvr      namespace.NamespaceOperateServer
-	discoverSvr       service.DiscoverServer
-	originDiscoverSvr service.DiscoverServer
-	configSvr         config.ConfigCenterServer
-	originConfigSvr   config.ConfigCenterServer
-	healthSvr         *healthcheck.Server
+	userSvr            auth.UserServer
+	strategySvr        auth.StrategyServer
+	namespaceSvr       namespace.NamespaceOperateServer
+	originNamespaceSvr namespace.NamespaceOperateServer
+	discoverSvr        service.DiscoverServer
+	originDiscoverSvr  service.DiscoverServer
+	configSvr          config.ConfigCenterServer
+	originConfigSvr    config.ConfigCenterServer
+	healthSvr          *healthcheck.Server
 
 	v1Svr *nacosv1.NacosV1Server
 	v2Svr *nacosv2.NacosV2Server
@@ -148,7 +149,11 @@ func copyOption(m map[string]interface{}) map[string]interface{} {
 
 func (n *NacosServer) initPolarisResource() error {
 	var err error
-	n.namespaceSvr, err = namespace.GetOriginServer()
+	n.namespaceSvr, err = namespace.GetServer()
+	if err != nil {
+		return err
+	}
+	n.originNamespaceSvr, err = namespace.GetOriginServer()
 	if err != nil {
 		return err
 	}
@@ -196,7 +201,7 @@ func (n *NacosServer) prepareRun() error {
 	n.v1Svr, err = nacosv1.NewNacosV1Server(n.store,
 		nacosv1.WithConnLimitConfig(n.connLimitConfig),
 		nacosv1.WithTLS(n.tlsInfo),
-		nacosv1.WithNamespaceSvr(n.namespaceSvr),
+		nacosv1.WithNamespaceSvr(n.namespaceSvr, n.originNamespaceSvr),
 		nacosv1.WithDiscoverSvr(n.discoverSvr, n.originDiscoverSvr, n.healthSvr),
 		nacosv1.WithConfigSvr(n.configSvr, n.originConfigSvr),
 		nacosv1.WithAuthSvr(n.userSvr),
@@ -208,7 +213,7 @@ func (n *NacosServer) prepareRun() error {
 	n.v2Svr, err = nacosv2.NewNacosV2Server(n.v1Svr, n.store,
 		nacosv2.WithConnLimitConfig(n.connLimitConfig),
 		nacosv2.WithTLS(n.tlsInfo),
-		nacosv2.WithNamespaceSvr(n.namespaceSvr),
+		nacosv2.WithNamespaceSvr(n.originNamespaceSvr),
 		nacosv2.WithDiscoverSvr(n.discoverSvr, n.originDiscoverSvr, n.healthSvr),
 		nacosv2.WithConfigSvr(n.configSvr, n.originConfigSvr),
 		nacosv2.WithAuthSvr(n.userSvr),
@@ -19,6 +19,7 @@ package config
 
 import (
 	"archive/zip"
+	"context"
 	"net/http"
 
 	"github.com/emicklei/go-restful/v3"
@@ -82,8 +83,18 @@ func (n *ConfigServer) GetConfig(req *restful.Request, rsp *restful.Response) {
 		nacoshttp.WrirteNacosErrorResponse(err, rsp)
 		return
 	}
+	if isBetaRequest(req) {
+		msg := "query beta ok"
+		nacoshttp.WrirteNacosResponse(model.RestResult{
+			Code:    http.StatusOK,
+			Message: &msg,
+			Data:    n.handleGetBetaConfig(baseInfo),
+		}, rsp)
+		return
+	}
 	ret, err := n.handleGetConfig(handler.ParseHeaderContext(), &model.ConfigFile{
 		ConfigFileBase: *baseInfo,
+		Tag:            nacoshttp.Optional(req, model.ParamTag, ""),
 	}, rsp)
 	if err != nil {
 		nacoshttp.WrirteNacosErrorResponse(err, rsp)
@@ -102,8 +113,13 @@ func (n *ConfigServer) DeleteConfig(req *restful.Request, rsp *restful.Response)
 		nacoshttp.WrirteNacosErrorResponse(err, rsp)
 		return
 	}
+	if isBetaRequest(req) {
+		n.StopBeta(handler.ParseHeaderContext(), baseInfo, rsp)
+		return
+	}
 	ret, err := n.handleDeleteConfig(handler.ParseHeaderContext(), &model.ConfigFile{
 		ConfigFileBase: *baseInfo,
+		Tag:            nacoshttp.Optional(req, model.ParamTag, ""),
 	})
 	if err != nil {
 		nacoshttp.WrirteNacosErrorResponse(err, rsp)
@@ -112,6 +128,21 @@ func (n *ConfigServer) DeleteConfig(req *restful.Request, rsp *restful.Response)
 	nacoshttp.WrirteNacosResponse(ret, rsp)
 }
 
+// StopBeta 对应 nacos 控制台的停止 beta 发布 DELETE /nacos/v1/cs/configs?beta=true
+func (n *ConfigServer) StopBeta(ctx context.Context, baseInfo *model.ConfigFileBase, rsp *restful.Response) {
+	ret, err := n.handleStopBeta(ctx, baseInfo)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	msg := "stop beta ok"
+	nacoshttp.WrirteNacosResponse(model.RestResult{
+		Code:    http.StatusOK,
+		Message: &msg,
+		Data:    ret,
+	}, rsp)
+}
+
 func (n *ConfigServer) WatchConfigs(req *restful.Request, rsp *restful.Response) {
 	handler := nacoshttp.Handler{
 		Request:  req,
@@ -185,3 +216,7 @@ func parseConfigFileBase(req *restful.Request) (*model.ConfigFileBase, error) {
 		DataId:    dataId,
 	}, nil
 }
+
+func isBetaRequest(req *restful.Request) bool {
+	return nacoshttp.Optional(req, model.ParamBeta, "false") == "true"
+}
@@ -42,6 +42,8 @@ func BuildConfigFile(req *restful.Request) (*model.ConfigFile, error) {
 		SrcUser:        nacoshttp.Optional(req, "src_user", ""),
 		Labels:         nacoshttp.Optional(req, "config_tags", ""),
 		Description:    nacoshttp.Optional(req, "desc", ""),
+		Tag:            nacoshttp.Optional(req, model.ParamTag, ""),
+		BetaIps:        req.HeaderParameter(model.HeaderBetaIps),
 	}
 
 	return configfile, nil
@@ -30,6 +30,7 @@ import (
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
 
+	"github.com/polarismesh/polaris/apiserver/nacosserver/core"
 	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
 	nacoshttp "github.com/polarismesh/polaris/apiserver/nacosserver/v1/http"
 	api "github.com/polarismesh/polaris/common/api/v1"
@@ -43,10 +44,11 @@ import (
 
 func (n *ConfigServer) handlePublishConfig(ctx context.Context, req *model.ConfigFile) (bool, error) {
 	var resp *config_manage.ConfigResponse
-	if req.CasMd5 != "" {
-		resp = n.configSvr.CasUpsertAndReleaseConfigFileFromClient(ctx, req.ToSpecConfigFile())
+	specFile := req.ToSpecConfigFile()
+	if labels := req.ToGrayLabels(); len(labels) != 0 {
+		resp = core.PublishGrayConfig(ctx, n.configSvr, specFile, labels)
 	} else {
-		resp = n.configSvr.UpsertAndReleaseConfigFileFromClient(ctx, req.ToSpecConfigFile())
+		resp = n.handleFullPublish(ctx, req.CasMd5, specFile)
 	}
 
 	if resp.GetCode().GetValue() == uint32(apimodel.Code_ExecuteSuccess) {
@@ -60,8 +62,26 @@ func (n *ConfigServer) handlePublishConfig(ctx context.Context, req *model.Confi
 	}
 }
 
+func (n *ConfigServer) handleFullPublish(ctx context.Context, casMd5 string,
+	specFile *config_manage.ConfigFilePublishInfo) *config_manage.ConfigResponse {
+	resp := core.CheckNoActiveGrayConfig(n.cacheSvr, specFile.GetNamespace().GetValue(),
+		specFile.GetGroup().GetValue(), specFile.GetFileName().GetValue())
+	if resp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
+		return resp
+	}
+	if casMd5 != "" {
+		return n.configSvr.CasUpsertAndReleaseConfigFileFromClient(ctx, specFile)
+	}
+	return n.configSvr.UpsertAndReleaseConfigFileFromClient(ctx, specFile)
+}
+
 func (n *ConfigServer) handleDeleteConfig(ctx context.Context, req *model.ConfigFile) (bool, error) {
-	resp := n.configSvr.DeleteConfigFileFromClient(ctx, req.ToDeleteSpec())
+	var resp *config_manage.ConfigResponse
+	if req.Tag != "" {
+		resp = n.handleDeleteTagConfig(ctx, req)
+	} else {
+		resp = n.configSvr.DeleteConfigFileFromClient(ctx, req.ToDeleteSpec())
+	}
 	if resp.GetCode().GetValue() == uint32(apimodel.Code_ExecuteSuccess) {
 		return true, nil
 	}
@@ -73,6 +93,56 @@ func (n *ConfigServer) handleDeleteConfig(ctx context.Context, req *model.Config
 	}
 }
 
+// handleDeleteTagConfig 删除 tag 配置，只有当前的灰度发布是该 tag 的发布时才停止灰度发布
+func (n *ConfigServer) handleDeleteTagConfig(ctx context.Context, req *model.ConfigFile) *config_manage.ConfigResponse {
+	namespace := model.ToPolarisNamespace(req.Namespace)
+	if gray := n.cacheSvr.ConfigFile().GetActiveGrayRelease(namespace, req.Group, req.DataId); gray != nil {
+		rule := n.cacheSvr.Gray().GetGrayRule(commonmodel.GetGrayConfigRealseKey(gray.SimpleConfigFileRelease))
+		if tag, ok := model.ParseGrayTag(rule); ok && tag == req.Tag {
+			return core.StopGrayConfig(ctx, n.configSvr, namespace, req.Group, req.DataId)
+		}
+	}
+	return &config_manage.ConfigResponse{Code: utils.NewUInt32Value(uint32(apimodel.Code_ExecuteSuccess))}
+}
+
+// handleStopBeta 停止 beta 发布
+func (n *ConfigServer) handleStopBeta(ctx context.Context, req *model.ConfigFileBase) (bool, error) {
+	resp := core.StopGrayConfig(ctx, n.configSvr, model.ToPolarisNamespace(req.Namespace), req.Group, req.DataId)
+	if resp.GetCode().GetValue() == uint32(apimodel.Code_ExecuteSuccess) {
+		return true, nil
+	}
+	nacoslog.Error("[NACOS-V1][Config] stop beta config file fail",
+		zap.Uint32("code", resp.GetCode().GetValue()), zap.String("msg", resp.GetInfo().GetValue()))
+	return false, &model.NacosError{
+		ErrCode: int32(model.ExceptionCode_ServerError),
+		ErrMsg:  resp.GetInfo().GetValue(),
+	}
+}
+
+// handleGetBetaConfig 查询 beta 发布的配置，不存在 beta 发布时返回 nil
+func (n *ConfigServer) handleGetBetaConfig(req *model.ConfigFileBase) *model.ConfigInfo4Beta {
+	namespace := model.ToPolarisNamespace(req.Namespace)
+	gray := n.cacheSvr.ConfigFile().GetActiveGrayRelease(namespace, req.Group, req.DataId)
+	if gray == nil {
+		return nil
+	}
+	rule := n.cacheSvr.Gray().GetGrayRule(commonmodel.GetGrayConfigRealseKey(gray.SimpleConfigFileRelease))
+	betaIps, ok := model.ParseBetaIps(rule)
+	if !ok {
+		return nil
+	}
+	return &model.ConfigInfo4Beta{
+		DataId:           req.DataId,
+		Group:            req.Group,
+		Tenant:           model.ToNacosConfigNamespace(namespace),
+		Content:          gray.Content,
+		Md5:              gray.Md5,
+		BetaIps:          betaIps,
+		EncryptedDataKey: gray.GetEncryptDataKey(),
+		LastModified:     gray.ModifyTime.UnixMilli(),
+	}
+}
+
 func (n *ConfigServer) handleGetConfig(ctx context.Context, req *model.ConfigFile, rsp *restful.Response) (string, error) {
 	var queryResp *config_manage.ConfigClientResponse
 	startTime := commontime.CurrentMillisecond()
@@ -89,7 +159,9 @@ func (n *ConfigServer) handleGetConfig(ctx context.Context, req *model.ConfigFil
 		})
 	}()
 
-	queryResp = n.configSvr.GetConfigFileWithCache(ctx, req.ToQuerySpec())
+	querySpec := req.ToQuerySpec()
+	querySpec.Tags = model.ToConfigClientTags(utils.ParseClientIP(ctx), req.Tag)
+	queryResp = n.configSvr.GetConfigFileWithCache(ctx, querySpec)
 	if queryResp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
 		nacoslog.Error("[NACOS-V1][Config] query config file fail",
 			zap.Uint32("code", queryResp.GetCode().GetValue()), zap.String("msg", queryResp.GetInfo().GetValue()))
@@ -111,6 +183,12 @@ func (n *ConfigServer) handleGetConfig(ctx context.Context, req *model.ConfigFil
 	disableCache(rsp)
 	rsp.AddHeader(model.HeaderLastModified, viewRelease.GetReleaseTime().GetValue())
 	rsp.AddHeader(model.HeaderContentMD5, viewRelease.GetMd5().GetValue())
+	clientLabels := model.ToConfigClientLabels(utils.ParseClientIP(ctx), req.Tag)
+	_, rule := core.MatchGrayConfig(n.cacheSvr, querySpec.GetNamespace().GetValue(), req.Group, req.DataId,
+		clientLabels)
+	if _, ok := model.ParseBetaIps(rule); ok {
+		rsp.AddHeader(model.HeaderIsBeta, "true")
+	}
 
 	return viewRelease.GetContent().GetValue(), nil
 }
@@ -119,9 +197,11 @@ func (n *ConfigServer) handleWatch(ctx context.Context, listenCtx *model.ConfigW
 	rsp *restful.Response) {
 
 	specWatchReq := listenCtx.ToSpecWatch()
+	clientLabels := model.ToConfigClientLabels(utils.ParseClientIP(ctx),
+		listenCtx.Request.HeaderParameter(model.HeaderVipserverTag))
 	timeout, ok := listenCtx.IsSupportLongPolling()
 	if !ok {
-		changeKeys := n.diffChangeFiles(ctx, specWatchReq)
+		changeKeys := n.diffChangeFiles(specWatchReq, clientLabels)
 		oldResult := md5OldResult(changeKeys)
 		newResult := md5ResultString(changeKeys)
 
@@ -132,7 +212,7 @@ func (n *ConfigServer) handleWatch(ctx context.Context, listenCtx *model.ConfigW
 		listenCtx.Request.SetAttribute(model.HeaderContent, newResult)
 		return
 	}
-	if changeKeys := n.diffChangeFiles(ctx, specWatchReq); len(changeKeys) > 0 {
+	if changeKeys := n.diffChangeFiles(specWatchReq, clientLabels); len(changeKeys) > 0 {
 		newResult := md5ResultString(changeKeys)
 		nacoslog.Info("[NACOS-V1][Config] client quick compare file result.", zap.String("result", newResult))
 		rsp.WriteHeader(http.StatusOK)
@@ -149,7 +229,7 @@ func (n *ConfigServer) handleWatch(ctx context.Context, listenCtx *model.ConfigW
 	clientId := utils.ParseClientAddress(ctx) + "@" + utils.NewUUID()[0:8]
 	configSvr := n.originConfigSvr.(*config.Server)
 	watchCtx := configSvr.WatchCenter().AddWatcher(clientId, specWatchReq.GetWatchFiles(),
-		n.BuildTimeoutWatchCtx(ctx, timeout))
+		n.BuildTimeoutWatchCtx(clientLabels, timeout))
 	nacoslog.Info("[NACOS-V1][Config] client start waitting server send notify message")
 	notifyRet := (watchCtx.(*LongPollWatchContext)).GetNotifieResult()
 	notifyCode := notifyRet.GetCode().GetValue()
@@ -164,7 +244,7 @@ func (n *ConfigServer) handleWatch(ctx context.Context, listenCtx *model.ConfigW
 	var changeKeys []*model.ConfigListenItem
 	if notifyCode == uint32(apimodel.Code_DataNoChange) {
 		// 按照 Nacos 原本的设计，只有 WatchClient 超时后才会再次全部 diff 比较
-		changeKeys = n.diffChangeFiles(ctx, specWatchReq)
+		changeKeys = n.diffChangeFiles(specWatchReq, clientLabels)
 	} else {
 		// 如果收到一个事件变化，就立即通知这个文件的变化信息
 		changeKeys = []*model.ConfigListenItem{
@@ -188,11 +268,8 @@ func (n *ConfigServer) handleWatch(ctx context.Context, listenCtx *model.ConfigW
 	return
 }
 
-func (n *ConfigServer) diffChangeFiles(ctx context.Context,
-	listenCtx *config_manage.ClientWatchConfigFileRequest) []*model.ConfigListenItem {
-	clientLabels := map[string]string{
-		commonmodel.ClientLabel_IP: utils.ParseClientIP(ctx),
-	}
+func (n *ConfigServer) diffChangeFiles(listenCtx *config_manage.ClientWatchConfigFileRequest,
+	clientLabels map[string]string) []*model.ConfigListenItem {
 	changeKeys := make([]*model.ConfigListenItem, 0, 4)
 	// quick get file and compare
 	for _, item := range listenCtx.WatchFiles {
@@ -201,15 +278,15 @@ func (n *ConfigServer) diffChangeFiles(ctx context.Context,
 		dataId := item.GetFileName().GetValue()
 		mdval := item.GetMd5().GetValue()
 
-		if beta := n.cacheSvr.ConfigFile().GetActiveGrayRelease(namespace, group, dataId); beta != nil {
-			if n.cacheSvr.Gray().HitGrayRule(beta.FileKey(), clientLabels) {
+		if beta, _ := core.MatchGrayConfig(n.cacheSvr, namespace, group, dataId, clientLabels); beta != nil {
+			if beta.Md5 != mdval {
 				changeKeys = append(changeKeys, &model.ConfigListenItem{
 					Tenant: model.ToNacosConfigNamespace(beta.Namespace),
 					Group:  beta.Group,
 					DataId: dataId,
 				})
-				continue
 			}
+			continue
 		}
 
 		active := n.cacheSvr.ConfigFile().GetActiveRelease(namespace, group, dataId)
@@ -224,10 +301,8 @@ func (n *ConfigServer) diffChangeFiles(ctx context.Context,
 	return changeKeys
 }
 
-func (n *ConfigServer) BuildTimeoutWatchCtx(ctx context.Context, watchTimeOut time.Duration) config.WatchContextFactory {
-	labels := map[string]string{}
-	labels[commonmodel.ClientLabel_IP] = utils.ParseClientIP(ctx)
-
+func (n *ConfigServer) BuildTimeoutWatchCtx(labels map[string]string,
+	watchTimeOut time.Duration) config.WatchContextFactory {
 	return func(clientId string, matcher config.BetaReleaseMatcher) config.WatchContext {
 		watchCtx := &LongPollWatchContext{
 			clientId:         clientId,
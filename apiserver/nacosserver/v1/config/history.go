@@ -0,0 +1,273 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package config
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
+	nacoshttp "github.com/polarismesh/polaris/apiserver/nacosserver/v1/http"
+	commonmodel "github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+const (
+	defaultHistoryPageSize = 100
+	maxHistoryPageSize     = 500
+)
+
+func (n *ConfigServer) GetHistoryServer() (*restful.WebService, error) {
+	ws := new(restful.WebService)
+	ws.Path("/nacos/v1/cs/history").Consumes(restful.MIME_JSON, model.MIME).Produces(restful.MIME_JSON)
+	ws.Route(ws.GET("/").To(n.GetConfigHistory))
+	ws.Route(ws.GET("/previous").To(n.GetPreviousConfigHistory))
+	return ws, nil
+}
+
+// GetConfigHistory 携带 nid 参数时查询单条历史记录，否则分页查询配置的历史记录
+func (n *ConfigServer) GetConfigHistory(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	baseInfo, err := parseConfigFileBase(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	ctx := handler.ParseHeaderContext()
+	if nidStr := req.QueryParameter("nid"); nidStr != "" {
+		nid, err := strconv.ParseUint(nidStr, 10, 64)
+		if err != nil {
+			nacoshttp.WrirteNacosErrorResponse(&model.NacosError{
+				ErrCode: int32(model.ExceptionCode_InvalidParam),
+				ErrMsg:  "invalid nid " + nidStr,
+			}, rsp)
+			return
+		}
+		ret, err := n.handleGetConfigHistory(ctx, baseInfo, nid)
+		if err != nil {
+			nacoshttp.WrirteNacosErrorResponse(err, rsp)
+			return
+		}
+		nacoshttp.WrirteNacosResponse(ret, rsp)
+		return
+	}
+
+	pageNo, _ := strconv.Atoi(nacoshttp.Optional(req, model.ParamPageNo, "1"))
+	pageSize, _ := strconv.Atoi(nacoshttp.Optional(req, model.ParamPageSize, strconv.Itoa(defaultHistoryPageSize)))
+	ret, err := n.handleListConfigHistory(ctx, baseInfo, pageNo, pageSize)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(ret, rsp)
+}
+
+// GetPreviousConfigHistory 查询指定历史记录的上一条历史记录
+func (n *ConfigServer) GetPreviousConfigHistory(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	baseInfo, err := parseConfigFileBase(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	idStr, err := nacoshttp.Required(req, "id")
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(&model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  "invalid id " + idStr,
+		}, rsp)
+		return
+	}
+	ret, err := n.handleGetPreviousConfigHistory(handler.ParseHeaderContext(), baseInfo, id)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(ret, rsp)
+}
+
+// handleListConfigHistory com.alibaba.nacos.config.server.controller.HistoryController#listConfigHistory
+func (n *ConfigServer) handleListConfigHistory(ctx context.Context, baseInfo *model.ConfigFileBase,
+	pageNo, pageSize int) (*model.Page, error) {
+	if pageNo < 1 {
+		pageNo = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultHistoryPageSize
+	}
+	if pageSize > maxHistoryPageSize {
+		pageSize = maxHistoryPageSize
+	}
+	histories, err := n.queryConfigHistories(ctx, baseInfo, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*model.ConfigHistoryInfo, 0, pageSize)
+	for i := (pageNo - 1) * pageSize; i < len(histories) && len(items) < pageSize; i++ {
+		items = append(items, histories[i])
+	}
+	return &model.Page{
+		TotalCount:     len(histories),
+		PageNumber:     pageNo,
+		PagesAvailable: (len(histories) + pageSize - 1) / pageSize,
+		PageItems:      items,
+	}, nil
+}
+
+// handleGetConfigHistory com.alibaba.nacos.config.server.controller.HistoryController#getConfigHistoryInfo
+func (n *ConfigServer) handleGetConfigHistory(ctx context.Context, baseInfo *model.ConfigFileBase,
+	nid uint64) (*model.ConfigHistoryInfo, error) {
+	histories, err := n.queryConfigHistories(ctx, baseInfo, nid+1, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(histories) == 0 || histories[0].Id != nid {
+		return nil, &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_NotFound),
+			ErrMsg:  "certain config history for nid = " + strconv.FormatUint(nid, 10) + " not exist",
+		}
+	}
+	return histories[0], nil
+}
+
+// handleGetPreviousConfigHistory com.alibaba.nacos.config.server.controller.HistoryController#getPreviousConfigHistoryInfo
+func (n *ConfigServer) handleGetPreviousConfigHistory(ctx context.Context, baseInfo *model.ConfigFileBase,
+	id uint64) (*model.ConfigHistoryInfo, error) {
+	histories, err := n.queryConfigHistories(ctx, baseInfo, id, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(histories) == 0 {
+		return nil, &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_NotFound),
+			ErrMsg:  "previous config history for id = " + strconv.FormatUint(id, 10) + " not exist",
+		}
+	}
+	return histories[0], nil
+}
+
+// queryConfigHistories 按照 id 倒序查询配置的发布历史，endId 大于 0 时只查询 id 小于 endId 的记录，
+// limit 大于 0 时最多返回 limit 条记录；北极星对分组以及文件名是模糊查询，这里需要再做一次精确过滤
+func (n *ConfigServer) queryConfigHistories(ctx context.Context, baseInfo *model.ConfigFileBase,
+	endId uint64, limit int) ([]*model.ConfigHistoryInfo, error) {
+	ret := make([]*model.ConfigHistoryInfo, 0, 16)
+	for offset := 0; ; {
+		filter := map[string]string{
+			"namespace": model.ToPolarisNamespace(baseInfo.Namespace),
+			"group":     baseInfo.Group,
+			"file_name": baseInfo.DataId,
+			"offset":    strconv.Itoa(offset),
+			"limit":     strconv.Itoa(utils.QueryMaxLimit),
+		}
+		if endId > 0 {
+			filter["endId"] = strconv.FormatUint(endId, 10)
+		}
+		resp := n.configSvr.GetConfigFileReleaseHistories(ctx, filter)
+		if resp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
+			nacoslog.Error("[NACOS-V1][Config] query config file release history fail",
+				zap.Uint32("code", resp.GetCode().GetValue()), zap.String("msg", resp.GetInfo().GetValue()))
+			return nil, &model.NacosError{
+				ErrCode: int32(model.ExceptionCode_ServerError),
+				ErrMsg:  resp.GetInfo().GetValue(),
+			}
+		}
+		items := resp.GetConfigFileReleaseHistories()
+		for _, item := range items {
+			if !isNacosConfigHistory(baseInfo, item) {
+				continue
+			}
+			ret = append(ret, toNacosConfigHistory(item))
+			if limit > 0 && len(ret) >= limit {
+				return ret, nil
+			}
+		}
+		offset += len(items)
+		if len(items) == 0 || offset >= int(resp.GetTotal().GetValue()) {
+			return ret, nil
+		}
+	}
+}
+
+// isNacosConfigHistory 只保留和 nacos 语义一致的全量发布以及删除成功的记录
+func isNacosConfigHistory(baseInfo *model.ConfigFileBase, item *config_manage.ConfigFileReleaseHistory) bool {
+	if item.GetGroup().GetValue() != baseInfo.Group || item.GetFileName().GetValue() != baseInfo.DataId {
+		return false
+	}
+	if item.GetStatus().GetValue() == utils.ReleaseStatusFail {
+		return false
+	}
+	switch item.GetType().GetValue() {
+	case utils.ReleaseTypeGray, utils.ReleaseTypeCancelGray:
+		return false
+	}
+	return true
+}
+
+func toNacosConfigHistory(item *config_manage.ConfigFileReleaseHistory) *model.ConfigHistoryInfo {
+	ret := &model.ConfigHistoryInfo{
+		Id:               item.GetId().GetValue(),
+		LastId:           -1,
+		DataId:           item.GetFileName().GetValue(),
+		Group:            item.GetGroup().GetValue(),
+		Tenant:           model.ToNacosConfigNamespace(item.GetNamespace().GetValue()),
+		Md5:              item.GetMd5().GetValue(),
+		Content:          item.GetContent().GetValue(),
+		SrcUser:          item.GetCreateBy().GetValue(),
+		OpType:           model.ConfigOpTypeUpdate,
+		CreatedTime:      parseHistoryTime(item.GetCreateTime().GetValue()),
+		LastModifiedTime: parseHistoryTime(item.GetModifyTime().GetValue()),
+	}
+	switch item.GetType().GetValue() {
+	case utils.ReleaseTypeDelete, utils.ReleaseTypeClean:
+		ret.OpType = model.ConfigOpTypeDelete
+	}
+	for _, tag := range item.GetTags() {
+		if tag.GetKey().GetValue() == commonmodel.MetaKeyConfigFileDataKey {
+			ret.EncryptedDataKey = tag.GetValue().GetValue()
+		}
+	}
+	return ret
+}
+
+// parseHistoryTime 历史记录中的时间为本地时间格式的字符串，nacos 中为毫秒时间戳
+func parseHistoryTime(val string) int64 {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", val, time.Local)
+	if err != nil {
+		return 0
+	}
+	return t.UnixMilli()
+}
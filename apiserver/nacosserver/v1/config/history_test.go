@@ -0,0 +1,75 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+func Test_isNacosConfigHistory(t *testing.T) {
+	baseInfo := &model.ConfigFileBase{Namespace: "", Group: "DEFAULT_GROUP", DataId: "app.yaml"}
+	newItem := func(group, file, typ string) *config_manage.ConfigFileReleaseHistory {
+		return &config_manage.ConfigFileReleaseHistory{
+			Group:    wrapperspb.String(group),
+			FileName: wrapperspb.String(file),
+			Type:     wrapperspb.String(typ),
+		}
+	}
+	tests := []struct {
+		name string
+		item *config_manage.ConfigFileReleaseHistory
+		want bool
+	}{
+		{name: "normal", item: newItem("DEFAULT_GROUP", "app.yaml", utils.ReleaseTypeNormal), want: true},
+		{name: "delete", item: newItem("DEFAULT_GROUP", "app.yaml", utils.ReleaseTypeDelete), want: true},
+		{name: "gray", item: newItem("DEFAULT_GROUP", "app.yaml", utils.ReleaseTypeGray), want: false},
+		{name: "fuzzy-file", item: newItem("DEFAULT_GROUP", "app.yaml.bak", utils.ReleaseTypeNormal), want: false},
+		{name: "fuzzy-group", item: newItem("DEFAULT_GROUP_2", "app.yaml", utils.ReleaseTypeNormal), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNacosConfigHistory(baseInfo, tt.item); got != tt.want {
+				t.Errorf("isNacosConfigHistory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_toNacosConfigHistory(t *testing.T) {
+	item := &config_manage.ConfigFileReleaseHistory{
+		Id:         wrapperspb.UInt64(10),
+		Namespace:  wrapperspb.String("default"),
+		Group:      wrapperspb.String("DEFAULT_GROUP"),
+		FileName:   wrapperspb.String("app.yaml"),
+		Type:       wrapperspb.String(utils.ReleaseTypeDelete),
+		CreateTime: wrapperspb.String("2023-01-02 03:04:05"),
+	}
+	ret := toNacosConfigHistory(item)
+	if ret.Tenant != "" || ret.OpType != model.ConfigOpTypeDelete || ret.Id != 10 || ret.LastId != -1 {
+		t.Fatalf("unexpected history %+v", ret)
+	}
+	if ret.CreatedTime != parseHistoryTime("2023-01-02 03:04:05") || ret.CreatedTime == 0 {
+		t.Fatalf("unexpected created time %d", ret.CreatedTime)
+	}
+}
@@ -76,6 +76,9 @@ func (c *LongPollWatchContext) ClientID() string {
 
 // ShouldNotify .
 func (c *LongPollWatchContext) ShouldNotify(event *model.SimpleConfigFileRelease) bool {
+	if event.ReleaseType == model.ReleaseTypeGray && !c.betaMatcher(c.ClientLabels(), event) {
+		return false
+	}
 	key := event.FileKey()
 	watchFile, ok := c.watchConfigFiles[key]
 	if !ok {
@@ -0,0 +1,237 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package v1
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/emicklei/go-restful/v3"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
+	nacoshttp "github.com/polarismesh/polaris/apiserver/nacosserver/v1/http"
+	commonmodel "github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+var namespaceIDRegex = regexp.MustCompile(`^[\w-]+$`)
+
+const maxNamespaceIDLength = 128
+
+func (n *NacosV1Server) GetConsoleServer() (*restful.WebService, error) {
+	ws := new(restful.WebService)
+	ws.Path("/nacos/v1/console").Consumes(restful.MIME_JSON, model.MIME).Produces(restful.MIME_JSON)
+	n.addNamespaceAccess(ws)
+	return ws, nil
+}
+
+func (n *NacosV1Server) addNamespaceAccess(ws *restful.WebService) {
+	ws.Route(ws.GET("/namespaces").To(n.GetNamespaces))
+	ws.Route(ws.POST("/namespaces").To(n.CreateNamespace))
+	ws.Route(ws.PUT("/namespaces").To(n.UpdateNamespace))
+	ws.Route(ws.DELETE("/namespaces").To(n.DeleteNamespace))
+}
+
+// GetNamespaces 同一个路径根据参数的不同分别为检查命名空间是否存在、查询单个命名空间以及查询命名空间列表
+func (n *NacosV1Server) GetNamespaces(req *restful.Request, rsp *restful.Response) {
+	if req.QueryParameter("checkNamespaceIdExist") == "true" {
+		namespaceID := nacoshttp.Optional(req, "customNamespaceId", "")
+		exist := namespaceID != "" && n.store.Cache().Namespace().GetNamespace(namespaceID) != nil
+		nacoshttp.WrirteNacosResponse(exist, rsp)
+		return
+	}
+	if req.QueryParameter("show") == "all" {
+		namespaceID := nacoshttp.Optional(req, model.ParamNamespaceID, model.DefaultNacosConfigNamespace)
+		ns := n.store.Cache().Namespace().GetNamespace(model.ToPolarisNamespace(namespaceID))
+		if ns == nil {
+			nacoshttp.WrirteNacosErrorResponse(&model.NacosError{
+				ErrCode: int32(model.ExceptionCode_NotFound),
+				ErrMsg:  "namespaceId [ " + namespaceID + " ] not exist",
+			}, rsp)
+			return
+		}
+		nacoshttp.WrirteNacosResponse(n.toNacosNamespace(ns), rsp)
+		return
+	}
+
+	namespaces := n.store.Cache().Namespace().GetNamespaceList()
+	ret := make([]*model.Namespace, 0, len(namespaces))
+	for _, ns := range namespaces {
+		ret = append(ret, n.toNacosNamespace(ns))
+	}
+	// public 命名空间固定在第一个
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Type != ret[j].Type {
+			return ret[i].Type < ret[j].Type
+		}
+		return ret[i].Namespace < ret[j].Namespace
+	})
+	nacoshttp.WrirteNacosResponse(&model.RestResult{
+		Code: http.StatusOK,
+		Data: ret,
+	}, rsp)
+}
+
+func (n *NacosV1Server) CreateNamespace(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	namespaceID := nacoshttp.Optional(req, "customNamespaceId", "")
+	if namespaceID == "" {
+		namespaceID = utils.NewUUID()
+	}
+	if !namespaceIDRegex.MatchString(namespaceID) || len(namespaceID) > maxNamespaceIDLength {
+		nacoshttp.WrirteNacosErrorResponse(&model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  "namespaceId [" + namespaceID + "] mismatch the pattern or exceeds the length",
+		}, rsp)
+		return
+	}
+	showName, err := nacoshttp.Required(req, "namespaceName")
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	if n.store.Cache().Namespace().GetNamespace(namespaceID) != nil {
+		nacoshttp.WrirteNacosResponse(false, rsp)
+		return
+	}
+
+	resp := n.namespaceSvr.CreateNamespace(handler.ParseHeaderContext(), &apimodel.Namespace{
+		Name:    utils.NewStringValue(namespaceID),
+		Comment: utils.NewStringValue(nacoshttp.Optional(req, "namespaceDesc", "")),
+		Metadata: map[string]string{
+			model.InternalNacosNamespaceShowName: showName,
+		},
+	})
+	n.writeNamespaceResult(resp.GetCode().GetValue(), resp.GetInfo().GetValue(), rsp)
+}
+
+func (n *NacosV1Server) UpdateNamespace(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	namespaceID, err := nacoshttp.Required(req, "namespace")
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	showName, err := nacoshttp.Required(req, "namespaceShowName")
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	ns := n.store.Cache().Namespace().GetNamespace(model.ToPolarisNamespace(namespaceID))
+	if ns == nil {
+		nacoshttp.WrirteNacosErrorResponse(&model.NacosError{
+			ErrCode: int32(model.ExceptionCode_NotFound),
+			ErrMsg:  "namespaceId [ " + namespaceID + " ] not exist",
+		}, rsp)
+		return
+	}
+
+	// 北极星更新命名空间时会覆盖元数据以及服务可见性，这里需要带上原有的数据
+	metadata := make(map[string]string, len(ns.Metadata)+1)
+	for k, v := range ns.Metadata {
+		metadata[k] = v
+	}
+	metadata[model.InternalNacosNamespaceShowName] = showName
+	resp := n.namespaceSvr.UpdateNamespaces(handler.ParseHeaderContext(), []*apimodel.Namespace{
+		{
+			Name:            utils.NewStringValue(ns.Name),
+			Comment:         utils.NewStringValue(nacoshttp.Optional(req, "namespaceDesc", "")),
+			Owners:          utils.NewStringValue(ns.Owner),
+			ServiceExportTo: ns.ListServiceExportTo(),
+			Metadata:        metadata,
+		},
+	})
+	n.writeNamespaceResult(resp.GetCode().GetValue(), resp.GetInfo().GetValue(), rsp)
+}
+
+func (n *NacosV1Server) DeleteNamespace(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	namespaceID, err := nacoshttp.Required(req, model.ParamNamespaceID)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	resp := n.namespaceSvr.DeleteNamespaces(handler.ParseHeaderContext(), []*apimodel.Namespace{
+		{
+			Name: utils.NewStringValue(model.ToPolarisNamespace(namespaceID)),
+		},
+	})
+	n.writeNamespaceResult(resp.GetCode().GetValue(), resp.GetInfo().GetValue(), rsp)
+}
+
+func (n *NacosV1Server) writeNamespaceResult(code uint32, info string, rsp *restful.Response) {
+	if code == uint32(apimodel.Code_ExecuteSuccess) || code == uint32(apimodel.Code_NoNeedUpdate) {
+		nacoshttp.WrirteNacosResponse(true, rsp)
+		return
+	}
+	nacoslog.Error("[NACOS-V1][Console] operate namespace fail", zap.Uint32("code", code), zap.String("msg", info))
+	nacoshttp.WrirteNacosErrorResponse(&model.NacosError{
+		ErrCode: int32(model.ExceptionCode_ServerError),
+		ErrMsg:  info,
+	}, rsp)
+}
+
+// toNacosNamespace 北极星的默认命名空间对应 nacos 的 public 命名空间
+func (n *NacosV1Server) toNacosNamespace(ns *commonmodel.Namespace) *model.Namespace {
+	ret := &model.Namespace{
+		Namespace:         model.ToNacosConfigNamespace(ns.Name),
+		NamespaceShowName: ns.Metadata[model.InternalNacosNamespaceShowName],
+		NamespaceDesc:     ns.Comment,
+		Quota:             model.DefaultNamespaceQuota,
+		ConfigCount:       n.countConfigs(ns.Name),
+		Type:              model.NamespaceTypeCustom,
+	}
+	if ret.Namespace == model.DefaultNacosConfigNamespace {
+		ret.NamespaceShowName = model.DefaultNacosNamespace
+		ret.Type = model.NamespaceTypeGlobal
+	}
+	if ret.NamespaceShowName == "" {
+		ret.NamespaceShowName = ns.Name
+	}
+	return ret
+}
+
+// countConfigs 统计命名空间下已发布的配置数量
+func (n *NacosV1Server) countConfigs(namespace string) int {
+	groups, _ := n.store.Cache().ConfigGroup().ListGroups(namespace)
+	count := 0
+	for _, group := range groups {
+		releases, _ := n.store.Cache().ConfigFile().GetGroupActiveReleases(namespace, group.Name)
+		for _, release := range releases {
+			if release.ReleaseType == commonmodel.ReleaseTypeFull {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -19,6 +19,7 @@ package discover
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/emicklei/go-restful/v3"
 
@@ -32,11 +33,23 @@ func (n *DiscoverServer) GetClientServer() (*restful.WebService, error) {
 	n.addInstanceAccess(ws)
 	n.addSystemAccess(ws)
 	n.AddServiceAccess(ws)
+	n.addCatalogAccess(ws)
+	n.addClusterAccess(ws)
 	return ws, nil
 }
 
 func (n *DiscoverServer) AddServiceAccess(ws *restful.WebService) {
 	ws.Route(ws.GET("/service/list").To(n.ListServices))
+	ws.Route(ws.POST("/service").To(n.CreateService))
+	ws.Route(ws.PUT("/service").To(n.UpdateService))
+	ws.Route(ws.DELETE("/service").To(n.DeleteService))
+	ws.Route(ws.GET("/service").To(n.GetService))
+}
+
+func (n *DiscoverServer) addCatalogAccess(ws *restful.WebService) {
+	ws.Route(ws.GET("/catalog/services").To(n.ListCatalogServices))
+	ws.Route(ws.GET("/catalog/service").To(n.GetCatalogService))
+	ws.Route(ws.GET("/catalog/instances").To(n.ListCatalogInstances))
 }
 
 func (n *DiscoverServer) addInstanceAccess(ws *restful.WebService) {
@@ -45,6 +58,7 @@ func (n *DiscoverServer) addInstanceAccess(ws *restful.WebService) {
 	ws.Route(ws.DELETE("/instance").To(n.DeRegisterInstance))
 	ws.Route(ws.PUT("/instance/beat").To(n.Heartbeat))
 	ws.Route(ws.GET("/instance/list").To(n.ListInstances))
+	ws.Route(ws.GET("/instance").To(n.GetInstance))
 }
 
 func (n *DiscoverServer) addSystemAccess(ws *restful.WebService) {
@@ -184,3 +198,168 @@ func (n *DiscoverServer) ServerHealthStatus(req *restful.Request, rsp *restful.R
 		"status": "UP",
 	}, rsp)
 }
+
+func (n *DiscoverServer) CreateService(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	key, err := parseServiceKey(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	threshold, metadata, err := parseServiceAttributes(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	if err := n.handleCreateService(handler.ParseHeaderContext(), key, threshold, metadata); err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteSimpleResponse("ok", http.StatusOK, rsp)
+}
+
+func (n *DiscoverServer) UpdateService(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	key, err := parseServiceKey(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	threshold, metadata, err := parseServiceAttributes(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	if err := n.handleUpdateService(handler.ParseHeaderContext(), key, threshold, metadata); err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteSimpleResponse("ok", http.StatusOK, rsp)
+}
+
+func (n *DiscoverServer) DeleteService(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	key, err := parseServiceKey(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	if err := n.handleDeleteService(handler.ParseHeaderContext(), key); err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteSimpleResponse("ok", http.StatusOK, rsp)
+}
+
+func (n *DiscoverServer) GetService(req *restful.Request, rsp *restful.Response) {
+	key, err := parseServiceKey(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	data, err := n.handleGetService(key)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(data, rsp)
+}
+
+func (n *DiscoverServer) GetInstance(req *restful.Request, rsp *restful.Response) {
+	key, err := parseServiceKey(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	ip, err := nacoshttp.Required(req, model.ParamInstanceIP)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	port, err := nacoshttp.RequiredInt(req, model.ParamInstancePort)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	cluster := nacoshttp.Optional(req, model.ParamClusterName, "")
+	if len(cluster) == 0 {
+		cluster = nacoshttp.Optional(req, model.ParamCluster, model.DefaultServiceClusterName)
+	}
+	data, err := n.handleGetInstance(key, cluster, ip, port)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(data, rsp)
+}
+
+func (n *DiscoverServer) ListCatalogServices(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	pageNo, pageSize, err := requiredPage(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	namespace := nacoshttp.Optional(req, model.ParamNamespaceID, model.DefaultNacosNamespace)
+	hasIpCount, _ := strconv.ParseBool(nacoshttp.Optional(req, "hasIpCount", "false"))
+	withInstances, _ := strconv.ParseBool(nacoshttp.Optional(req, "withInstances", "true"))
+	data := n.handleListCatalogServices(handler.ParseHeaderContext(), &catalogQuery{
+		namespace:     model.ToPolarisNamespace(namespace),
+		serviceName:   nacoshttp.Optional(req, "serviceNameParam", ""),
+		groupName:     nacoshttp.Optional(req, "groupNameParam", ""),
+		hasIpCount:    hasIpCount,
+		withInstances: withInstances,
+		pageNo:        pageNo,
+		pageSize:      pageSize,
+	})
+	nacoshttp.WrirteNacosResponse(data, rsp)
+}
+
+func (n *DiscoverServer) GetCatalogService(req *restful.Request, rsp *restful.Response) {
+	key, err := parseServiceKey(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	data, err := n.handleGetCatalogService(key)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(data, rsp)
+}
+
+func (n *DiscoverServer) ListCatalogInstances(req *restful.Request, rsp *restful.Response) {
+	key, err := parseServiceKey(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	cluster, err := nacoshttp.Required(req, model.ParamClusterName)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	pageNo, pageSize, err := requiredPage(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteNacosResponse(n.handleListCatalogInstances(key, cluster, pageNo, pageSize), rsp)
+}
@@ -54,6 +54,9 @@ func BuildInstance(namespace string, req *restful.Request, onlybase bool) (*mode
 		Port:        int32(port),
 		Ephemeral:   true,
 	}
+	if ephemeral, err := strconv.ParseBool(nacoshttp.Optional(req, model.ParamInstanceEphemeral, "true")); err == nil {
+		nacosIns.Ephemeral = ephemeral
+	}
 
 	if !onlybase {
 		weightStr := nacoshttp.Optional(req, model.ParamInstanceWeight, "1")
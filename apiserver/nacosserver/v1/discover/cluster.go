@@ -0,0 +1,164 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package discover
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/emicklei/go-restful/v3"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
+	nacoshttp "github.com/polarismesh/polaris/apiserver/nacosserver/v1/http"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+func (n *DiscoverServer) addClusterAccess(ws *restful.WebService) {
+	ws.Route(ws.PUT("/cluster").To(n.UpdateCluster))
+}
+
+func (n *DiscoverServer) UpdateCluster(req *restful.Request, rsp *restful.Response) {
+	handler := nacoshttp.Handler{
+		Request:  req,
+		Response: rsp,
+	}
+
+	key, err := parseServiceKey(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	cluster, err := parseClusterMetadata(req)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	clusterName, err := nacoshttp.Required(req, model.ParamClusterName)
+	if err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	if err := n.handleUpdateCluster(handler.ParseHeaderContext(), key, clusterName, cluster); err != nil {
+		nacoshttp.WrirteNacosErrorResponse(err, rsp)
+		return
+	}
+	nacoshttp.WrirteSimpleResponse("ok", http.StatusOK, rsp)
+}
+
+// parseClusterMetadata 解析集群的健康检查配置以及元数据
+func parseClusterMetadata(req *restful.Request) (*model.ClusterMetadata, error) {
+	checkPort, err := nacoshttp.RequiredInt(req, model.ParamCheckPort)
+	if err != nil {
+		return nil, err
+	}
+	useInstancePortStr, err := nacoshttp.Required(req, model.ParamUseInstancePort)
+	if err != nil {
+		return nil, err
+	}
+	useInstancePort, err := strconv.ParseBool(useInstancePortStr)
+	if err != nil {
+		return nil, &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  "invalid " + model.ParamUseInstancePort + ": " + useInstancePortStr,
+		}
+	}
+	checkerStr, err := nacoshttp.Required(req, model.ParamHealthChecker)
+	if err != nil {
+		return nil, err
+	}
+	checker, err := model.ParseHealthChecker(checkerStr)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := parseServiceMetadata(nacoshttp.Optional(req, model.ParamInstanceMetadata, ""))
+	if err != nil {
+		return nil, err
+	}
+	return &model.ClusterMetadata{
+		HealthChecker:    checker,
+		DefaultCheckPort: checkPort,
+		UseIPPort4Check:  useInstancePort,
+		Metadata:         metadata,
+	}, nil
+}
+
+// handleUpdateCluster com.alibaba.nacos.naming.controllers.ClusterController#update
+func (n *DiscoverServer) handleUpdateCluster(ctx context.Context, key *model.ServiceKey, clusterName string,
+	cluster *model.ClusterMetadata) error {
+	svc := n.getService(key)
+	if svc == nil {
+		return &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  "service not found:" + groupedServiceName(key),
+		}
+	}
+	data, err := json.Marshal(cluster)
+	if err != nil {
+		return &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_ServerError),
+			ErrMsg:  err.Error(),
+		}
+	}
+	specSvc := svc.ToSpec()
+	specSvc.Metadata = make(map[string]string, len(svc.Meta)+1)
+	for k, v := range svc.Meta {
+		specSvc.Metadata[k] = v
+	}
+	specSvc.Metadata[model.ClusterMetadataKey(clusterName)] = string(data)
+	resp := n.discoverSvr.UpdateServices(ctx, []*apiservice.Service{specSvc})
+	if code := batchCode(resp); code != apimodel.Code_ExecuteSuccess && code != apimodel.Code_NoNeedUpdate {
+		return &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_ServerError),
+			ErrMsg:  resp.GetInfo().GetValue(),
+		}
+	}
+
+	// 集群的健康检查方式变化后，需要同步调整集群下持久化实例的探测参数
+	for _, ins := range n.discoverSvr.Cache().Instance().GetInstancesByServiceID(svc.ID) {
+		if ins.Metadata()[model.InternalNacosEphemeral] != "false" {
+			continue
+		}
+		if utils.DefaultString(ins.Metadata()[model.InternalNacosCluster], model.DefaultServiceClusterName) != clusterName {
+			continue
+		}
+		req := &apiservice.Instance{
+			Id:        utils.NewStringValue(ins.ID()),
+			Service:   utils.NewStringValue(ins.Service()),
+			Namespace: utils.NewStringValue(ins.Namespace()),
+			Host:      utils.NewStringValue(ins.Host()),
+			Port:      utils.NewUInt32Value(ins.Port()),
+			Metadata:  make(map[string]string, len(ins.Metadata())),
+		}
+		for k, v := range ins.Metadata() {
+			req.Metadata[k] = v
+		}
+		model.PreparePersistentInstance(req, cluster)
+		resp := n.discoverSvr.UpdateInstance(ctx, req)
+		if code := apimodel.Code(resp.GetCode().GetValue()); code != apimodel.Code_ExecuteSuccess &&
+			code != apimodel.Code_NoNeedUpdate {
+			nacoslog.Error("[NACOS-V1][Cluster] update persistent instance health checker fail",
+				zap.String("id", ins.ID()), zap.String("msg", resp.GetInfo().GetValue()))
+		}
+	}
+	return nil
+}
@@ -35,7 +35,10 @@ import (
 )
 
 func (n *DiscoverServer) handleRegister(ctx context.Context, namespace, serviceName string, ins *model.Instance) error {
-	specIns := model.PrepareSpecInstance(namespace, serviceName, ins)
+	specIns, err := n.prepareSpecInstance(namespace, serviceName, ins)
+	if err != nil {
+		return err
+	}
 	resp := n.discoverSvr.RegisterInstance(ctx, specIns)
 	if apimodel.Code(resp.GetCode().GetValue()) != apimodel.Code_ExecuteSuccess {
 		return &model.NacosError{
@@ -47,7 +50,10 @@ func (n *DiscoverServer) handleRegister(ctx context.Context, namespace, serviceN
 }
 
 func (n *DiscoverServer) handleUpdate(ctx context.Context, namespace, serviceName string, ins *model.Instance) error {
-	specIns := model.PrepareSpecInstance(namespace, serviceName, ins)
+	specIns, err := n.prepareSpecInstance(namespace, serviceName, ins)
+	if err != nil {
+		return err
+	}
 	if specIns.Id == nil || specIns.GetId().GetValue() == "" {
 		insId, errRsp := utils.CheckInstanceTetrad(specIns)
 		if errRsp != nil {
@@ -77,6 +83,20 @@ func (n *DiscoverServer) handleUpdate(ctx context.Context, namespace, serviceNam
 	return nil
 }
 
+// prepareSpecInstance 转换为北极星实例，临时实例通过客户端心跳保持健康状态，持久化实例按照集群配置由服务端主动探测
+func (n *DiscoverServer) prepareSpecInstance(namespace, serviceName string,
+	ins *model.Instance) (*apiservice.Instance, error) {
+	if err := core.CheckInstanceEphemeral(n.discoverSvr.Cache(), namespace, serviceName, ins.Ephemeral); err != nil {
+		return nil, err
+	}
+	specIns := model.PrepareSpecInstance(namespace, serviceName, ins)
+	if !ins.Ephemeral {
+		cluster := core.GetClusterMetadata(n.discoverSvr.Cache(), namespace, serviceName, ins.ClusterName)
+		model.PreparePersistentInstance(specIns, cluster)
+	}
+	return specIns, nil
+}
+
 func (n *DiscoverServer) handleDeregister(ctx context.Context, namespace, svcName string, ins *model.Instance) error {
 	specIns := model.PrepareSpecInstance(namespace, svcName, ins)
 	resp := n.discoverSvr.DeregisterInstance(ctx, specIns)
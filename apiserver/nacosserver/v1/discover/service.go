@@ -0,0 +1,464 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package discover
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emicklei/go-restful/v3"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+
+	"github.com/polarismesh/polaris/apiserver/nacosserver/core"
+	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
+	nacoshttp "github.com/polarismesh/polaris/apiserver/nacosserver/v1/http"
+	commonmodel "github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+// parseServiceKey 解析请求中的服务信息，serviceName 可以是 group@@service 的形式
+func parseServiceKey(req *restful.Request) (*model.ServiceKey, error) {
+	serviceName, err := nacoshttp.Required(req, model.ParamServiceName)
+	if err != nil {
+		return nil, err
+	}
+	groupName := nacoshttp.Optional(req, model.ParamGroupName, model.DefaultServiceGroup)
+	if strings.Contains(serviceName, model.DefaultNacosGroupConnectStr) {
+		items := strings.SplitN(serviceName, model.DefaultNacosGroupConnectStr, 2)
+		groupName, serviceName = items[0], items[1]
+	}
+	namespace := nacoshttp.Optional(req, model.ParamNamespaceID, model.DefaultNacosNamespace)
+	return &model.ServiceKey{
+		Namespace: model.ToPolarisNamespace(namespace),
+		Group:     groupName,
+		Name:      serviceName,
+	}, nil
+}
+
+// parseServiceAttributes 解析服务的保护阈值以及元数据
+func parseServiceAttributes(req *restful.Request) (float64, map[string]string, error) {
+	thresholdStr := nacoshttp.Optional(req, model.ParamProtectThreshold, "0")
+	threshold, err := strconv.ParseFloat(thresholdStr, 64)
+	if err != nil || threshold < 0 || threshold > 1 {
+		return 0, nil, &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  "protectThreshold must be in [0, 1]: " + thresholdStr,
+		}
+	}
+	metadata, err := parseServiceMetadata(nacoshttp.Optional(req, model.ParamInstanceMetadata, ""))
+	if err != nil {
+		return 0, nil, err
+	}
+	return threshold, metadata, nil
+}
+
+// parseServiceMetadata 服务元数据支持 json 以及 k1=v1,k2=v2 两种格式
+func parseServiceMetadata(metadataStr string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if metadataStr == "" {
+		return metadata, nil
+	}
+	if json.Valid([]byte(metadataStr)) {
+		if err := json.Unmarshal([]byte(metadataStr), &metadata); err != nil {
+			return nil, &model.NacosError{
+				ErrCode: int32(model.ExceptionCode_InvalidParam),
+				ErrMsg:  fmt.Sprintf("metadata format incorrect:%s", metadataStr),
+			}
+		}
+		return metadata, nil
+	}
+	for _, item := range strings.Split(metadataStr, ",") {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return nil, &model.NacosError{
+				ErrCode: int32(model.ExceptionCode_InvalidParam),
+				ErrMsg:  fmt.Sprintf("metadata format incorrect:%s", metadataStr),
+			}
+		}
+		metadata[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return metadata, nil
+}
+
+// buildServiceMetadata 保护阈值保存在北极星服务的元数据中，保留已有的内部元数据
+func buildServiceMetadata(saved map[string]string, threshold float64,
+	metadata map[string]string) map[string]string {
+	ret := make(map[string]string, len(metadata)+len(saved))
+	for k, v := range saved {
+		if strings.HasPrefix(k, model.InternalMetadataPrefix) {
+			ret[k] = v
+		}
+	}
+	for k, v := range metadata {
+		ret[k] = v
+	}
+	ret[model.InternalNacosServiceProtectThreshold] = strconv.FormatFloat(threshold, 'f', -1, 64)
+	return ret
+}
+
+// batchCode 获取批量写请求中单个资源的返回码
+func batchCode(resp *apiservice.BatchWriteResponse) apimodel.Code {
+	if len(resp.GetResponses()) == 1 {
+		return apimodel.Code(resp.GetResponses()[0].GetCode().GetValue())
+	}
+	return apimodel.Code(resp.GetCode().GetValue())
+}
+
+func groupedServiceName(key *model.ServiceKey) string {
+	return key.Group + model.DefaultNacosGroupConnectStr + key.Name
+}
+
+func (n *DiscoverServer) getService(key *model.ServiceKey) *commonmodel.Service {
+	return n.discoverSvr.Cache().Service().GetServiceByName(model.BuildServiceName(key.Name, key.Group),
+		key.Namespace)
+}
+
+// listServiceInstances 查询服务下的全部实例，包括不健康以及隔离的实例
+func (n *DiscoverServer) listServiceInstances(key *model.ServiceKey) []*model.Instance {
+	filterCtx := &core.FilterContext{
+		Service: core.ToNacosService(n.discoverSvr.Cache(), key.Namespace, key.Name, key.Group),
+	}
+	return n.store.ListInstances(filterCtx, core.NoopSelectInstances).Hosts
+}
+
+func clusterOf(ins *model.Instance) string {
+	if ins.ClusterName == "" {
+		return model.DefaultServiceClusterName
+	}
+	return ins.ClusterName
+}
+
+// listClusters 北极星中没有集群资源，集群信息从实例以及服务元数据中的集群配置汇总得到
+func listClusters(key *model.ServiceKey, svc *commonmodel.Service,
+	instances []*model.Instance) []*model.ClusterInfo {
+	names := map[string]struct{}{}
+	for _, ins := range instances {
+		names[clusterOf(ins)] = struct{}{}
+	}
+	for _, name := range model.ListClusterNames(svc.Meta) {
+		names[name] = struct{}{}
+	}
+	ret := make([]*model.ClusterInfo, 0, len(names))
+	for name := range names {
+		cluster := model.GetClusterMetadata(svc.Meta, name)
+		ret = append(ret, &model.ClusterInfo{
+			ServiceName:   key.Name,
+			Name:          name,
+			HealthChecker: cluster.HealthChecker,
+			Metadata:      cluster.Metadata,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// handleCreateService com.alibaba.nacos.naming.controllers.ServiceController#create
+func (n *DiscoverServer) handleCreateService(ctx context.Context, key *model.ServiceKey, threshold float64,
+	metadata map[string]string) error {
+	resp := n.discoverSvr.CreateServices(ctx, []*apiservice.Service{
+		{
+			Name:      utils.NewStringValue(model.BuildServiceName(key.Name, key.Group)),
+			Namespace: utils.NewStringValue(key.Namespace),
+			Metadata:  buildServiceMetadata(nil, threshold, metadata),
+		},
+	})
+	switch batchCode(resp) {
+	case apimodel.Code_ExecuteSuccess:
+		return nil
+	case apimodel.Code_ExistedResource:
+		return &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  "specified service already exists, serviceName : " + groupedServiceName(key),
+		}
+	default:
+		return &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_ServerError),
+			ErrMsg:  resp.GetInfo().GetValue(),
+		}
+	}
+}
+
+// handleUpdateService com.alibaba.nacos.naming.controllers.ServiceController#update
+func (n *DiscoverServer) handleUpdateService(ctx context.Context, key *model.ServiceKey, threshold float64,
+	metadata map[string]string) error {
+	svc := n.getService(key)
+	if svc == nil {
+		return &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  "service " + groupedServiceName(key) + " not found!",
+		}
+	}
+	specSvc := svc.ToSpec()
+	specSvc.Metadata = buildServiceMetadata(svc.Meta, threshold, metadata)
+	resp := n.discoverSvr.UpdateServices(ctx, []*apiservice.Service{specSvc})
+	if code := batchCode(resp); code != apimodel.Code_ExecuteSuccess && code != apimodel.Code_NoNeedUpdate {
+		return &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_ServerError),
+			ErrMsg:  resp.GetInfo().GetValue(),
+		}
+	}
+	return nil
+}
+
+// handleDeleteService com.alibaba.nacos.naming.controllers.ServiceController#remove
+func (n *DiscoverServer) handleDeleteService(ctx context.Context, key *model.ServiceKey) error {
+	if n.getService(key) == nil {
+		return &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  "specified service not exist, serviceName : " + groupedServiceName(key),
+		}
+	}
+	resp := n.discoverSvr.DeleteServices(ctx, []*apiservice.Service{
+		{
+			Name:      utils.NewStringValue(model.BuildServiceName(key.Name, key.Group)),
+			Namespace: utils.NewStringValue(key.Namespace),
+		},
+	})
+	switch batchCode(resp) {
+	case apimodel.Code_ExecuteSuccess:
+		return nil
+	case apimodel.Code_ServiceExistedInstances:
+		return &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  "Service " + groupedServiceName(key) + " is not empty, can't be delete. Please unregister instance first",
+		}
+	default:
+		return &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_ServerError),
+			ErrMsg:  resp.GetInfo().GetValue(),
+		}
+	}
+}
+
+// handleGetService com.alibaba.nacos.naming.controllers.ServiceController#detail
+func (n *DiscoverServer) handleGetService(key *model.ServiceKey) (*model.ServiceDetail, error) {
+	svc := n.getService(key)
+	if svc == nil {
+		return nil, &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_InvalidParam),
+			ErrMsg:  "service " + groupedServiceName(key) + " is not found!",
+		}
+	}
+	detail := toServiceDetail(key, svc)
+	detail.NamespaceId = model.ToNacosNamespace(key.Namespace)
+	detail.Clusters = listClusters(key, svc, n.listServiceInstances(key))
+	// 服务详情中的集群不需要再携带服务名
+	for _, cluster := range detail.Clusters {
+		cluster.ServiceName = ""
+	}
+	return detail, nil
+}
+
+func toServiceDetail(key *model.ServiceKey, svc *commonmodel.Service) *model.ServiceDetail {
+	threshold, _ := strconv.ParseFloat(svc.Meta[model.InternalNacosServiceProtectThreshold], 64)
+	return &model.ServiceDetail{
+		GroupName:        key.Group,
+		Name:             key.Name,
+		ProtectThreshold: threshold,
+		Metadata:         model.ToNacosServiceMetadata(svc.Meta),
+		Selector:         model.NoneSelector,
+	}
+}
+
+// handleGetInstance com.alibaba.nacos.naming.controllers.InstanceController#detail
+func (n *DiscoverServer) handleGetInstance(key *model.ServiceKey, cluster, ip string,
+	port int) (map[string]interface{}, error) {
+	for _, ins := range n.listServiceInstances(key) {
+		if ins.IP != ip || int(ins.Port) != port || clusterOf(ins) != cluster {
+			continue
+		}
+		return map[string]interface{}{
+			"service":     groupedServiceName(key),
+			"ip":          ins.IP,
+			"port":        ins.Port,
+			"clusterName": clusterOf(ins),
+			"weight":      ins.Weight,
+			"healthy":     ins.Healthy,
+			"instanceId":  ins.Id,
+			"metadata":    ins.Metadata,
+		}, nil
+	}
+	return nil, &model.NacosError{
+		ErrCode: int32(model.ExceptionCode_NotFound),
+		ErrMsg:  "no matched ip found!",
+	}
+}
+
+// catalogQuery /nacos/v1/ns/catalog/services 的查询条件
+type catalogQuery struct {
+	namespace     string
+	serviceName   string
+	groupName     string
+	hasIpCount    bool
+	withInstances bool
+	pageNo        int
+	pageSize      int
+}
+
+// handleListCatalogServices com.alibaba.nacos.naming.controllers.CatalogController#listDetail
+func (n *DiscoverServer) handleListCatalogServices(ctx context.Context, query *catalogQuery) interface{} {
+	_, services := n.discoverSvr.Cache().Service().ListServices(ctx, query.namespace)
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+
+	summaries := make([]*model.CatalogService, 0, len(services))
+	details := make([]*model.CatalogServiceDetail, 0, len(services))
+	for _, svc := range services {
+		key := &model.ServiceKey{
+			Namespace: query.namespace,
+			Group:     model.GetGroupName(svc.Name),
+			Name:      model.GetServiceName(svc.Name),
+		}
+		if !strings.Contains(key.Name, query.serviceName) || !strings.Contains(key.Group, query.groupName) {
+			continue
+		}
+		instances := n.listServiceInstances(key)
+		if query.hasIpCount && len(instances) == 0 {
+			continue
+		}
+		if query.withInstances {
+			details = append(details, toCatalogServiceDetail(key, svc, instances))
+			continue
+		}
+		summary := &model.CatalogService{
+			Name:         key.Name,
+			GroupName:    key.Group,
+			ClusterCount: len(listClusters(key, svc, instances)),
+			IpCount:      len(instances),
+			TriggerFlag:  "false",
+		}
+		for _, ins := range instances {
+			if ins.Healthy {
+				summary.HealthyInstanceCount++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if query.withInstances {
+		return pageSlice(details, query.pageNo, query.pageSize)
+	}
+	return map[string]interface{}{
+		"count":       len(summaries),
+		"serviceList": pageSlice(summaries, query.pageNo, query.pageSize),
+	}
+}
+
+func toCatalogServiceDetail(key *model.ServiceKey, svc *commonmodel.Service,
+	instances []*model.Instance) *model.CatalogServiceDetail {
+	detail := &model.CatalogServiceDetail{
+		ServiceName: key.Name,
+		GroupName:   key.Group,
+		ClusterMap:  map[string]*model.CatalogCluster{},
+		Metadata:    model.ToNacosServiceMetadata(svc.Meta),
+	}
+	for _, ins := range instances {
+		name := clusterOf(ins)
+		cluster, ok := detail.ClusterMap[name]
+		if !ok {
+			clusterMeta := model.GetClusterMetadata(svc.Meta, name)
+			cluster = &model.CatalogCluster{
+				ServiceName:   key.Name,
+				ClusterName:   name,
+				HealthChecker: clusterMeta.HealthChecker,
+				Metadata:      clusterMeta.Metadata,
+				Hosts:         []*model.Instance{},
+			}
+			detail.ClusterMap[name] = cluster
+		}
+		cluster.Hosts = append(cluster.Hosts, ins)
+	}
+	return detail
+}
+
+// handleGetCatalogService com.alibaba.nacos.naming.controllers.CatalogController#serviceDetail
+func (n *DiscoverServer) handleGetCatalogService(key *model.ServiceKey) (map[string]interface{}, error) {
+	svc := n.getService(key)
+	if svc == nil {
+		return nil, &model.NacosError{
+			ErrCode: int32(model.ExceptionCode_NotFound),
+			ErrMsg:  "service " + groupedServiceName(key) + " is not found!",
+		}
+	}
+	return map[string]interface{}{
+		"service":  toServiceDetail(key, svc),
+		"clusters": listClusters(key, svc, n.listServiceInstances(key)),
+	}, nil
+}
+
+// handleListCatalogInstances com.alibaba.nacos.naming.controllers.CatalogController#instanceList
+func (n *DiscoverServer) handleListCatalogInstances(key *model.ServiceKey, cluster string,
+	pageNo, pageSize int) map[string]interface{} {
+	instances := make([]*model.Instance, 0, 8)
+	for _, ins := range n.listServiceInstances(key) {
+		if clusterOf(ins) == cluster {
+			instances = append(instances, ins)
+		}
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].IP != instances[j].IP {
+			return instances[i].IP < instances[j].IP
+		}
+		return instances[i].Port < instances[j].Port
+	})
+	return map[string]interface{}{
+		"count": len(instances),
+		"list":  pageSlice(instances, pageNo, pageSize),
+	}
+}
+
+// pageSlice 按照 nacos 的分页参数截取列表，页码从 1 开始
+func pageSlice[T any](items []T, pageNo, pageSize int) []T {
+	if pageNo < 1 {
+		pageNo = 1
+	}
+	if pageSize <= 0 {
+		return []T{}
+	}
+	start := (pageNo - 1) * pageSize
+	if start >= len(items) {
+		return []T{}
+	}
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+// requiredPage 解析必填的分页参数
+func requiredPage(req *restful.Request) (int, int, error) {
+	pageNo, err := nacoshttp.RequiredInt(req, model.ParamPageNo)
+	if err != nil {
+		return 0, 0, &model.NacosError{ErrCode: http.StatusBadRequest, ErrMsg: err.Error()}
+	}
+	pageSize, err := nacoshttp.RequiredInt(req, model.ParamPageSize)
+	if err != nil {
+		return 0, 0, &model.NacosError{ErrCode: http.StatusBadRequest, ErrMsg: err.Error()}
+	}
+	return pageNo, pageSize, nil
+}
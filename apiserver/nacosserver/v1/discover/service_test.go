@@ -0,0 +1,69 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package discover
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseServiceMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty", arg: "", want: map[string]string{}},
+		{name: "json", arg: `{"k1":"v1","k2":"v2"}`, want: map[string]string{"k1": "v1", "k2": "v2"}},
+		{name: "kv", arg: "k1=v1, k2=v2", want: map[string]string{"k1": "v1", "k2": "v2"}},
+		{name: "invalid", arg: "k1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseServiceMetadata(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseServiceMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseServiceMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pageSlice(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		name     string
+		pageNo   int
+		pageSize int
+		want     []int
+	}{
+		{name: "first", pageNo: 1, pageSize: 2, want: []int{1, 2}},
+		{name: "last", pageNo: 3, pageSize: 2, want: []int{5}},
+		{name: "overflow", pageNo: 4, pageSize: 2, want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageSlice(items, tt.pageNo, tt.pageSize); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pageSlice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
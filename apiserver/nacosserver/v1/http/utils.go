@@ -97,6 +97,7 @@ func (h *Handler) ParseHeaderContext() context.Context {
 	}
 	ctx = context.WithValue(ctx, utils.StringContext("operator"), operator)
 	ctx = context.WithValue(ctx, utils.ContextClientAddress, h.Request.Request.RemoteAddr)
+	ctx = context.WithValue(ctx, utils.ContextAuthTokenKey, h.Request.HeaderParameter(utils.HeaderAuthTokenKey))
 	return ctx
 }
 
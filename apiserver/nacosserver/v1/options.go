@@ -41,10 +41,12 @@ func WithConnLimitConfig(connLimitConfig *connlimit.Config) option {
 	}
 }
 
-func WithNamespaceSvr(namespaceSvr namespace.NamespaceOperateServer) option {
+func WithNamespaceSvr(namespaceSvr namespace.NamespaceOperateServer,
+	originSvr namespace.NamespaceOperateServer) option {
 	return func(svr *NacosV1Server) {
-		svr.discoverOpt.NamespaceSvr = namespaceSvr
-		svr.configOpt.NamespaceSvr = namespaceSvr
+		svr.namespaceSvr = namespaceSvr
+		svr.discoverOpt.NamespaceSvr = originSvr
+		svr.configOpt.NamespaceSvr = originSvr
 	}
 }
 
@@ -43,6 +43,7 @@ import (
 	"github.com/polarismesh/polaris/common/metrics"
 	"github.com/polarismesh/polaris/common/secure"
 	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/namespace"
 	"github.com/polarismesh/polaris/plugin"
 )
 
@@ -89,7 +90,8 @@ type NacosV1Server struct {
 	pushCenter core.PushCenter
 	store      *core.NacosDataStorage
 
-	checker auth.UserServer
+	checker      auth.UserServer
+	namespaceSvr namespace.NamespaceOperateServer
 
 	discoverOpt *discover.ServerOption
 	discoverSvr *discover.DiscoverServer
@@ -225,6 +227,18 @@ func (h *NacosV1Server) createRestfulContainer() (*restful.Container, error) {
 	}
 	wsContainer.Add(authSvc)
 
+	historySvc, err := h.configSvr.GetHistoryServer()
+	if err != nil {
+		return nil, err
+	}
+	wsContainer.Add(historySvc)
+
+	consoleSvc, err := h.GetConsoleServer()
+	if err != nil {
+		return nil, err
+	}
+	wsContainer.Add(consoleSvc)
+
 	return wsContainer, nil
 }
 
@@ -25,6 +25,7 @@ import (
 	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
 	"go.uber.org/zap"
 
+	"github.com/polarismesh/polaris/apiserver/nacosserver/core"
 	nacosmodel "github.com/polarismesh/polaris/apiserver/nacosserver/model"
 	nacospb "github.com/polarismesh/polaris/apiserver/nacosserver/v2/pb"
 	"github.com/polarismesh/polaris/apiserver/nacosserver/v2/remote"
@@ -62,10 +63,11 @@ func (h *ConfigServer) handlePublishConfigRequest(ctx context.Context, req nacos
 		})
 	}()
 
-	if configReq.CasMd5 != "" {
-		resp = h.configSvr.CasUpsertAndReleaseConfigFileFromClient(ctx, configReq.ToSpec())
+	specFile := configReq.ToSpec()
+	if labels := configReq.ToGrayLabels(); len(labels) != 0 {
+		resp = core.PublishGrayConfig(ctx, h.configSvr, specFile, labels)
 	} else {
-		resp = h.configSvr.UpsertAndReleaseConfigFileFromClient(ctx, configReq.ToSpec())
+		resp = h.handleFullPublish(ctx, configReq.CasMd5, specFile)
 	}
 	if resp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
 		nacoslog.Error("[NACOS-V2][Config] publish config file fail", zap.String("tenant", configReq.Tenant),
@@ -90,6 +92,19 @@ func (h *ConfigServer) handlePublishConfigRequest(ctx context.Context, req nacos
 	}, nil
 }
 
+func (h *ConfigServer) handleFullPublish(ctx context.Context, casMd5 string,
+	specFile *config_manage.ConfigFilePublishInfo) *config_manage.ConfigResponse {
+	resp := core.CheckNoActiveGrayConfig(h.cacheSvr, specFile.GetNamespace().GetValue(),
+		specFile.GetGroup().GetValue(), specFile.GetFileName().GetValue())
+	if resp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
+		return resp
+	}
+	if casMd5 != "" {
+		return h.configSvr.CasUpsertAndReleaseConfigFileFromClient(ctx, specFile)
+	}
+	return h.configSvr.UpsertAndReleaseConfigFileFromClient(ctx, specFile)
+}
+
 func (h *ConfigServer) handleGetConfigRequest(ctx context.Context, req nacospb.BaseRequest,
 	meta nacospb.RequestMeta) (nacospb.BaseResponse, error) {
 	configReq, ok := req.(*nacospb.ConfigQueryRequest)
@@ -113,6 +128,8 @@ func (h *ConfigServer) handleGetConfigRequest(ctx context.Context, req nacospb.B
 	}()
 
 	queryReq := configReq.ToQuerySpec()
+	clientIP := utils.ParseClientIP(ctx)
+	queryReq.Tags = nacosmodel.ToConfigClientTags(clientIP, configReq.Tag)
 	queryResp := h.configSvr.GetConfigFileWithCache(ctx, queryReq)
 	if queryResp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
 		nacoslog.Error("[NACOS-V2][Config] query config file fail", zap.String("tenant", configReq.Tenant),
@@ -152,6 +169,10 @@ func (h *ConfigServer) handleGetConfigRequest(ctx context.Context, req nacospb.B
 		Md5:          viewRelease.GetMd5().GetValue(),
 		LastModified: stringToTimestamp(viewRelease.GetReleaseTime().GetValue()),
 	}
+	_, rule := core.MatchGrayConfig(h.cacheSvr, queryReq.GetNamespace().GetValue(), configReq.Group,
+		configReq.DataId, nacosmodel.ToConfigClientLabels(clientIP, configReq.Tag))
+	_, rsp.IsBeta = nacosmodel.ParseBetaIps(rule)
+	_, rsp.Tag = nacosmodel.ParseGrayTag(rule)
 	return rsp, nil
 }
 
@@ -161,7 +182,12 @@ func (h *ConfigServer) handleDeleteConfigRequest(ctx context.Context, req nacosp
 	if !ok {
 		return nil, remote.ErrorInvalidRequestBodyType
 	}
-	delResp := h.configSvr.DeleteConfigFileFromClient(ctx, configReq.ToSpec())
+	var delResp *config_manage.ConfigResponse
+	if configReq.Tag != "" {
+		delResp = h.handleDeleteTagConfig(ctx, configReq)
+	} else {
+		delResp = h.configSvr.DeleteConfigFileFromClient(ctx, configReq.ToSpec())
+	}
 	if delResp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
 		nacoslog.Error("[NACOS-V2][Config] delete config file fail", zap.String("tenant", configReq.Tenant),
 			utils.ZapGroup(configReq.Group), utils.ZapFileName(configReq.DataId),
@@ -185,6 +211,19 @@ func (h *ConfigServer) handleDeleteConfigRequest(ctx context.Context, req nacosp
 	}, nil
 }
 
+// handleDeleteTagConfig 删除 tag 配置，只有当前的灰度发布是该 tag 的发布时才停止灰度发布
+func (h *ConfigServer) handleDeleteTagConfig(ctx context.Context,
+	req *nacospb.ConfigRemoveRequest) *config_manage.ConfigResponse {
+	namespace := nacosmodel.ToPolarisNamespace(req.Tenant)
+	if gray := h.cacheSvr.ConfigFile().GetActiveGrayRelease(namespace, req.Group, req.DataId); gray != nil {
+		rule := h.cacheSvr.Gray().GetGrayRule(model.GetGrayConfigRealseKey(gray.SimpleConfigFileRelease))
+		if tag, ok := nacosmodel.ParseGrayTag(rule); ok && tag == req.Tag {
+			return core.StopGrayConfig(ctx, h.configSvr, namespace, req.Group, req.DataId)
+		}
+	}
+	return &config_manage.ConfigResponse{Code: utils.NewUInt32Value(uint32(apimodel.Code_ExecuteSuccess))}
+}
+
 func (h *ConfigServer) handleWatchConfigRequest(ctx context.Context, req nacospb.BaseRequest,
 	meta nacospb.RequestMeta) (nacospb.BaseResponse, error) {
 	watchReq, ok := req.(*nacospb.ConfigBatchListenRequest)
@@ -205,13 +244,8 @@ func (h *ConfigServer) handleWatchConfigRequest(ctx context.Context, req nacospb
 			dataId := item.GetFileName().GetValue()
 			mdval := item.GetMd5().GetValue()
 
-			var active *model.ConfigFileRelease
-			var match bool
-			if betaActive := h.cacheSvr.ConfigFile().GetActiveGrayRelease(namespace, group, dataId); betaActive != nil {
-				match = h.cacheSvr.Gray().HitGrayRule(model.GetGrayConfigRealseKey(betaActive.SimpleConfigFileRelease), watchCtx.ClientLabels())
-				active = betaActive
-			}
-			if !match {
+			active, _ := core.MatchGrayConfig(h.cacheSvr, namespace, group, dataId, watchCtx.ClientLabels())
+			if active == nil {
 				active = h.cacheSvr.ConfigFile().GetActiveRelease(namespace, group, dataId)
 			}
 
@@ -238,8 +272,8 @@ func (h *ConfigServer) handleWatchConfigRequest(ctx context.Context, req nacospb
 
 // BuildGrpcWatchCtx .
 func (h *ConfigServer) BuildGrpcWatchCtx(ctx context.Context) config.WatchContextFactory {
-	labels := map[string]string{}
-	labels[model.ClientLabel_IP] = utils.ParseClientIP(ctx)
+	tag, _ := ctx.Value(utils.StringContext(nacosmodel.HeaderVipserverTag)).(string)
+	labels := nacosmodel.ToConfigClientLabels(utils.ParseClientIP(ctx), tag)
 
 	return func(clientId string, matcher config.BetaReleaseMatcher) config.WatchContext {
 		watchCtx := &StreamWatchContext{
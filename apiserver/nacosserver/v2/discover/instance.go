@@ -48,6 +48,7 @@ func (h *DiscoverServer) handleInstanceRequest(ctx context.Context, req nacospb.
 	}
 	namespace = nacosmodel.ToPolarisNamespace(namespace)
 	svcName := nacosmodel.BuildServiceName(insReq.ServiceName, insReq.GroupName)
+	insReq.Instance.Ephemeral = true
 	ins := nacosmodel.PrepareSpecInstance(namespace, svcName, &insReq.Instance)
 	// 设置连接 ID 作为实例的 metadata 属性信息
 	ins.Metadata[nacosmodel.InternalNacosClientConnectionID] = remote.ValueConnID(ctx)
@@ -62,6 +63,9 @@ func (h *DiscoverServer) handleInstanceRequest(ctx context.Context, req nacospb.
 	switch insReq.Type {
 	case "registerInstance":
 		respType = "registerInstance"
+		if err := core.CheckInstanceEphemeral(h.discoverSvr.Cache(), namespace, svcName, true); err != nil {
+			return nil, err
+		}
 		resp = h.discoverSvr.RegisterInstance(ctx, ins)
 		insID := resp.GetInstance().GetId().GetValue()
 		h.clientManager.addServiceInstance(meta.ConnectionID, model.ServiceKey{
@@ -124,20 +128,21 @@ func (h *DiscoverServer) handlePersistentInstanceRequest(ctx context.Context, re
 	}
 	namespace = nacosmodel.ToPolarisNamespace(namespace)
 	svcName := nacosmodel.BuildServiceName(insReq.ServiceName, insReq.GroupName)
+	// 持久化实例不和 Grpc Connection 绑定，连接断开时不会被反注册，健康状态由服务端按照集群配置主动探测
+	insReq.Instance.Ephemeral = false
 	ins := nacosmodel.PrepareSpecInstance(namespace, svcName, &insReq.Instance)
-	ins.EnableHealthCheck = wrapperspb.Bool(false)
-	ins.HealthCheck = nil
+	cluster := core.GetClusterMetadata(h.discoverSvr.Cache(), namespace, svcName, insReq.Instance.ClusterName)
+	nacosmodel.PreparePersistentInstance(ins, cluster)
 
 	var resp *service_manage.Response
 	var respType string
 
-	errCode := int(nacosmodel.ErrorCode_Success.Code)
-	resultCode := int(nacosmodel.Response_Success.Code)
-	success := true
-
 	switch insReq.Type {
 	case "registerInstance":
 		respType = "registerInstance"
+		if err := core.CheckInstanceEphemeral(h.discoverSvr.Cache(), namespace, svcName, false); err != nil {
+			return nil, err
+		}
 		resp = h.discoverSvr.RegisterInstance(ctx, ins)
 	case "deregisterInstance":
 		respType = "deregisterInstance"
@@ -157,6 +162,16 @@ func (h *DiscoverServer) handlePersistentInstanceRequest(ctx context.Context, re
 		}
 	}
 
+	errCode := int(nacosmodel.ErrorCode_Success.Code)
+	resultCode := int(nacosmodel.Response_Success.Code)
+	success := true
+
+	if resp.GetCode().GetValue() != uint32(apimodel.Code_ExecuteSuccess) {
+		success = false
+		errCode = int(nacosmodel.ErrorCode_ServerError.Code)
+		resultCode = int(nacosmodel.Response_Fail.Code)
+	}
+
 	return &nacospb.InstanceResponse{
 		Response: &nacospb.Response{
 			ResultCode: resultCode,
@@ -188,8 +203,12 @@ func (h *DiscoverServer) handleBatchInstanceRequest(ctx context.Context, req nac
 	ctx = context.WithValue(ctx, utils.ContextOpenAsyncRegis, true)
 	switch batchInsReq.Type {
 	case "batchRegisterInstance":
+		if err := core.CheckInstanceEphemeral(h.discoverSvr.Cache(), namespace, svcName, true); err != nil {
+			return nil, err
+		}
 		for i := range batchInsReq.Instances {
 			insReq := batchInsReq.Instances[i]
+			insReq.Ephemeral = true
 			ins := nacosmodel.PrepareSpecInstance(namespace, svcName, insReq)
 			ins.Metadata[nacosmodel.InternalNacosClientConnectionID] = remote.ValueConnID(ctx)
 			// 显示关闭实例的健康检查能力
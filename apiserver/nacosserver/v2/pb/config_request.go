@@ -18,6 +18,7 @@ package nacos_grpc_service
 
 import (
 	"github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/polarismesh/polaris/apiserver/nacosserver/model"
@@ -173,6 +174,10 @@ func (c *ConfigPublishRequest) ToSpec() *config_manage.ConfigFilePublishInfo {
 	}
 
 	for k, v := range c.AdditionMap {
+		// beta/tag 发布的信息转为北极星的灰度发布规则，不作为配置文件的标签
+		if k == model.HeaderBetaIps || k == model.ParamTag {
+			continue
+		}
 		ret.Tags = append(ret.Tags, &config_manage.ConfigFileTag{
 			Key:   wrapperspb.String(k),
 			Value: wrapperspb.String(v),
@@ -182,6 +187,14 @@ func (c *ConfigPublishRequest) ToSpec() *config_manage.ConfigFilePublishInfo {
 	return ret
 }
 
+// ToGrayLabels nacos 的 beta 发布优先于 tag 发布，两者都没有时为全量发布
+func (c *ConfigPublishRequest) ToGrayLabels() []*apimodel.ClientLabel {
+	return (&model.ConfigFile{
+		BetaIps: c.AdditionMap[model.HeaderBetaIps],
+		Tag:     c.AdditionMap[model.ParamTag],
+	}).ToGrayLabels()
+}
+
 func (c *ConfigPublishRequest) RequestMeta() interface{} {
 	return c
 }
@@ -199,6 +212,7 @@ func (r *ConfigPublishRequest) GetRequestType() string {
 
 type ConfigRemoveRequest struct {
 	*ConfigRequest
+	Tag string `json:"tag"`
 }
 
 func (c *ConfigRemoveRequest) ToSpec() *config_manage.ConfigFile {
@@ -0,0 +1,400 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package ca
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store"
+)
+
+// Config 内置证书颁发机构的配置
+type Config struct {
+	Enable bool `mapstructure:"enable"`
+	// CryptoAlgo 加密根证书私钥使用的 crypto 插件
+	CryptoAlgo string `mapstructure:"cryptoAlgo"`
+	// DataKey base64 编码的根证书私钥加密密钥，开启内置 CA 时必须配置，密钥只存在于配置中不会写入存储
+	DataKey string `mapstructure:"dataKey"`
+	// RootCertTTL 根证书有效期
+	RootCertTTL time.Duration `mapstructure:"rootCertTTL"`
+	// RootRotateBefore 根证书在到期前多久开始轮换
+	RootRotateBefore time.Duration `mapstructure:"rootRotateBefore"`
+	// TrustBundleOverlap 根证书轮换后旧根证书继续保留在信任列表中的时长
+	TrustBundleOverlap time.Duration `mapstructure:"trustBundleOverlap"`
+	// WorkloadCertTTL 工作负载证书有效期
+	WorkloadCertTTL time.Duration `mapstructure:"workloadCertTTL"`
+	// WorkloadRotateBefore 工作负载证书在到期前多久重新签发
+	WorkloadRotateBefore time.Duration `mapstructure:"workloadRotateBefore"`
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		CryptoAlgo:           "AES",
+		RootCertTTL:          365 * 24 * time.Hour,
+		RootRotateBefore:     30 * 24 * time.Hour,
+		TrustBundleOverlap:   48 * time.Hour,
+		WorkloadCertTTL:      24 * time.Hour,
+		WorkloadRotateBefore: 8 * time.Hour,
+	}
+}
+
+// ParseConfig 解析 xds-v3 option 中的 ca 配置
+func ParseConfig(raw interface{}) (*Config, error) {
+	cfg := DefaultConfig()
+	if raw == nil {
+		return cfg, nil
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     cfg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(raw); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	// 加密密钥和密文保存在一起等同于明文保存私钥
+	if c.Enable && c.DataKey == "" {
+		return errors.New("ca dataKey is required when the built-in ca is enabled")
+	}
+	if c.WorkloadCertTTL <= 0 || c.RootCertTTL <= 0 {
+		return errors.New("ca cert ttl must be positive")
+	}
+	if c.WorkloadRotateBefore <= 0 || c.WorkloadRotateBefore >= c.WorkloadCertTTL {
+		return errors.New("ca workloadRotateBefore must be in (0, workloadCertTTL)")
+	}
+	// 旧根证书签发的工作负载证书在重叠期内都要能够通过校验
+	if c.TrustBundleOverlap < c.WorkloadCertTTL {
+		c.TrustBundleOverlap = c.WorkloadCertTTL
+	}
+	if c.RootRotateBefore <= c.TrustBundleOverlap || c.RootRotateBefore >= c.RootCertTTL {
+		return errors.New("ca rootRotateBefore must be in (trustBundleOverlap, rootCertTTL)")
+	}
+	return nil
+}
+
+// WorkloadCert 签发给工作负载的证书
+type WorkloadCert struct {
+	// CertChain PEM 格式的证书
+	CertChain []byte
+	// PrivateKey PEM 格式的私钥
+	PrivateKey []byte
+	// TrustBundle PEM 格式的全部有效根证书
+	TrustBundle []byte
+	NotAfter    time.Time
+	// BundleRevision 签发时信任列表的版本，根证书发生轮换后会变化
+	BundleRevision string
+}
+
+// RootView 根证书信息，用于调试接口展示
+type RootView struct {
+	Generation uint64    `json:"generation"`
+	NotBefore  time.Time `json:"notBefore"`
+	NotAfter   time.Time `json:"notAfter"`
+	RetireTime time.Time `json:"retireTime,omitempty"`
+	Signing    bool      `json:"signing"`
+}
+
+type rootCA struct {
+	pair *model.CAKeyPair
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// Authority 内置证书颁发机构，根证书保存在存储层中由多个节点共享
+type Authority struct {
+	cfg     *Config
+	storage store.CAStore
+	crypto  plugin.Crypto
+	dataKey []byte
+	now     func() time.Time
+
+	lock sync.RWMutex
+	// roots 按照 Generation 升序排列，最后一个未退役的根证书用于签发
+	roots []*rootCA
+}
+
+// NewAuthority 创建证书颁发机构，存储中没有可用的根证书时会自动生成
+func NewAuthority(cfg *Config, storage store.CAStore, crypto plugin.Crypto) (*Authority, error) {
+	return newAuthority(cfg, storage, crypto, time.Now)
+}
+
+func newAuthority(cfg *Config, storage store.CAStore, crypto plugin.Crypto,
+	now func() time.Time) (*Authority, error) {
+	a := &Authority{
+		cfg:     cfg,
+		storage: storage,
+		crypto:  crypto,
+		now:     now,
+	}
+	dataKey, err := base64.StdEncoding.DecodeString(cfg.DataKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ca dataKey: %w", err)
+	}
+	if len(dataKey) == 0 {
+		return nil, errors.New("ca dataKey is required")
+	}
+	a.dataKey = dataKey
+	if err := a.Refresh(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Refresh 从存储中重新加载根证书，清理重叠期已经结束的旧根证书，签发根证书临近过期时自动轮换
+func (a *Authority) Refresh() error {
+	pairs, err := a.load()
+	if err != nil {
+		return err
+	}
+	signing := a.signingRoot()
+	if signing != nil && signing.cert.NotAfter.Sub(a.now()) > a.cfg.RootRotateBefore {
+		return nil
+	}
+	return a.rotate(pairs)
+}
+
+// RotateRoot 立即轮换根证书，旧根证书在 TrustBundleOverlap 内仍然保留在信任列表中
+func (a *Authority) RotateRoot() error {
+	pairs, err := a.load()
+	if err != nil {
+		return err
+	}
+	return a.rotate(pairs)
+}
+
+func (a *Authority) load() ([]*model.CAKeyPair, error) {
+	pairs, err := a.storage.GetCAKeyPairs()
+	if err != nil {
+		return nil, err
+	}
+	now := a.now()
+	roots := make([]*rootCA, 0, len(pairs))
+	for _, pair := range pairs {
+		if pair.Retired() && !now.Before(pair.RetireTime) {
+			if err := a.storage.DeleteCAKeyPair(pair.Generation); err != nil {
+				log.Warnf("[XDS][CA] delete retired root(%d) err: %v", pair.Generation, err)
+			}
+			continue
+		}
+		root, err := a.decodeRoot(pair)
+		if err != nil {
+			return nil, fmt.Errorf("decode ca root(%d): %w", pair.Generation, err)
+		}
+		roots = append(roots, root)
+	}
+	a.lock.Lock()
+	a.roots = roots
+	a.lock.Unlock()
+	return pairs, nil
+}
+
+// rotate 新增下一代根证书并将现有根证书标记为退役
+func (a *Authority) rotate(pairs []*model.CAKeyPair) error {
+	var generation uint64
+	for _, pair := range pairs {
+		if pair.Generation > generation {
+			generation = pair.Generation
+		}
+	}
+	generation++
+	now := a.now()
+	pair, err := a.newRootPair(generation, now)
+	if err != nil {
+		return err
+	}
+	if err := a.storage.AddCAKeyPair(pair); err != nil {
+		if store.Code(err) != store.DuplicateEntryErr {
+			return err
+		}
+		// 其他节点已经生成了这一代根证书，直接使用即可
+		log.Infof("[XDS][CA] root(%d) already created by other server", generation)
+	} else {
+		log.Infof("[XDS][CA] create root(%d), not after %s", generation, pair.NotAfter.Format(time.RFC3339))
+	}
+	retireTime := now.Add(a.cfg.TrustBundleOverlap)
+	for _, item := range pairs {
+		if item.Generation >= generation || item.Retired() {
+			continue
+		}
+		if err := a.storage.RetireCAKeyPair(item.Generation, retireTime); err != nil {
+			return err
+		}
+		log.Infof("[XDS][CA] retire root(%d) at %s", item.Generation, retireTime.Format(time.RFC3339))
+	}
+	_, err = a.load()
+	return err
+}
+
+func (a *Authority) newRootPair(generation uint64, now time.Time) (*model.CAKeyPair, error) {
+	cert, key, err := newRootCert(now, a.cfg.RootCertTTL)
+	if err != nil {
+		return nil, err
+	}
+	keyPem, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	pair := &model.CAKeyPair{
+		Generation: generation,
+		Cert:       string(encodeCert(cert.Raw)),
+		CryptoAlgo: a.crypto.Name(),
+		NotBefore:  cert.NotBefore,
+		NotAfter:   cert.NotAfter,
+	}
+	if pair.KeyCipher, err = a.crypto.Encrypt(string(keyPem), a.dataKey); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+func (a *Authority) decodeRoot(pair *model.CAKeyPair) (*rootCA, error) {
+	if pair.CryptoAlgo != a.crypto.Name() {
+		return nil, fmt.Errorf("crypto algo %s not match %s", pair.CryptoAlgo, a.crypto.Name())
+	}
+	keyPem, err := a.crypto.Decrypt(pair.KeyCipher, a.dataKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := decodeKey([]byte(keyPem))
+	if err != nil {
+		return nil, err
+	}
+	cert, err := decodeCert([]byte(pair.Cert))
+	if err != nil {
+		return nil, err
+	}
+	return &rootCA{pair: pair, cert: cert, key: key}, nil
+}
+
+func (a *Authority) signingRoot() *rootCA {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.signingRootLocked()
+}
+
+func (a *Authority) signingRootLocked() *rootCA {
+	for i := len(a.roots) - 1; i >= 0; i-- {
+		if !a.roots[i].pair.Retired() {
+			return a.roots[i]
+		}
+	}
+	return nil
+}
+
+func (a *Authority) trustBundleLocked() ([]byte, string) {
+	now := a.now()
+	buf := bytes.NewBuffer(nil)
+	generations := make([]string, 0, len(a.roots))
+	for _, root := range a.roots {
+		if now.After(root.cert.NotAfter) {
+			continue
+		}
+		buf.WriteString(root.pair.Cert)
+		generations = append(generations, strconv.FormatUint(root.pair.Generation, 10))
+	}
+	return buf.Bytes(), strings.Join(generations, ",")
+}
+
+// TrustBundle 返回 PEM 格式的全部有效根证书以及其版本
+func (a *Authority) TrustBundle() ([]byte, string) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.trustBundleLocked()
+}
+
+// Roots 返回当前的根证书信息
+func (a *Authority) Roots() []*RootView {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	signing := a.signingRootLocked()
+	ret := make([]*RootView, 0, len(a.roots))
+	for _, root := range a.roots {
+		ret = append(ret, &RootView{
+			Generation: root.pair.Generation,
+			NotBefore:  root.cert.NotBefore,
+			NotAfter:   root.cert.NotAfter,
+			RetireTime: root.pair.RetireTime,
+			Signing:    root == signing,
+		})
+	}
+	return ret
+}
+
+// WorkloadRotateBefore 工作负载证书在到期前多久需要重新签发
+func (a *Authority) WorkloadRotateBefore() time.Duration {
+	return a.cfg.WorkloadRotateBefore
+}
+
+// Issue 为指定服务签发 spiffe://<namespace>/<service> 身份的工作负载证书
+func (a *Authority) Issue(namespace, service string) (*WorkloadCert, error) {
+	if namespace == "" || service == "" {
+		return nil, errors.New("namespace and service are required")
+	}
+	a.lock.RLock()
+	signing := a.signingRootLocked()
+	bundle, revision := a.trustBundleLocked()
+	a.lock.RUnlock()
+	if signing == nil {
+		return nil, errors.New("no signing root available")
+	}
+
+	now := a.now()
+	notAfter := now.Add(a.cfg.WorkloadCertTTL)
+	if notAfter.After(signing.cert.NotAfter) {
+		notAfter = signing.cert.NotAfter
+	}
+	der, key, err := newWorkloadCert(signing.cert, signing.key, SpiffeID(namespace, service),
+		now.Add(-clockSkew), notAfter)
+	if err != nil {
+		return nil, err
+	}
+	keyPem, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &WorkloadCert{
+		CertChain:      encodeCert(der),
+		PrivateKey:     keyPem,
+		TrustBundle:    bundle,
+		NotAfter:       notAfter,
+		BundleRevision: revision,
+	}, nil
+}
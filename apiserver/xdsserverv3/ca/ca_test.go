@@ -0,0 +1,286 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package ca
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin/crypto/aes"
+	"github.com/polarismesh/polaris/store"
+)
+
+type memoryCAStore struct {
+	lock  sync.Mutex
+	pairs map[uint64]*model.CAKeyPair
+}
+
+func newMemoryCAStore() *memoryCAStore {
+	return &memoryCAStore{pairs: map[uint64]*model.CAKeyPair{}}
+}
+
+func (m *memoryCAStore) AddCAKeyPair(pair *model.CAKeyPair) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.pairs[pair.Generation]; ok {
+		return store.NewStatusError(store.DuplicateEntryErr, "duplicate "+strconv.FormatUint(pair.Generation, 10))
+	}
+	copied := *pair
+	m.pairs[pair.Generation] = &copied
+	return nil
+}
+
+func (m *memoryCAStore) RetireCAKeyPair(generation uint64, retireTime time.Time) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if pair, ok := m.pairs[generation]; ok {
+		pair.RetireTime = retireTime
+	}
+	return nil
+}
+
+func (m *memoryCAStore) GetCAKeyPairs() ([]*model.CAKeyPair, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ret := make([]*model.CAKeyPair, 0, len(m.pairs))
+	for _, pair := range m.pairs {
+		copied := *pair
+		ret = append(ret, &copied)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Generation < ret[j].Generation
+	})
+	return ret, nil
+}
+
+func (m *memoryCAStore) DeleteCAKeyPair(generation uint64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.pairs, generation)
+	return nil
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func verifyCert(t *testing.T, cert *WorkloadCert, bundle []byte, at time.Time) error {
+	block, _ := pem.Decode(cert.CertChain)
+	assert.NotNil(t, block)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+	pool := x509.NewCertPool()
+	assert.True(t, pool.AppendCertsFromPEM(bundle))
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:       pool,
+		CurrentTime: at,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// testConfig 默认配置加上随机生成的加密密钥
+func testConfig(t *testing.T) *Config {
+	key, err := (&aes.AESCrypto{}).GenerateKey()
+	assert.NoError(t, err)
+	cfg := DefaultConfig()
+	cfg.DataKey = base64.StdEncoding.EncodeToString(key)
+	return cfg
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     interface{}
+		wantErr bool
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name:    "enable-without-data-key",
+			raw:     map[string]interface{}{"enable": true},
+			wantErr: true,
+		},
+		{
+			name: "default",
+			raw:  map[string]interface{}{"enable": true, "dataKey": "MTIzNDU2Nzg5MDEyMzQ1Ng=="},
+			check: func(t *testing.T, cfg *Config) {
+				assert.True(t, cfg.Enable)
+				assert.Equal(t, "AES", cfg.CryptoAlgo)
+				assert.Equal(t, 24*time.Hour, cfg.WorkloadCertTTL)
+				assert.Equal(t, 48*time.Hour, cfg.TrustBundleOverlap)
+			},
+		},
+		{
+			name: "yaml-option",
+			raw: map[interface{}]interface{}{"enable": true, "dataKey": "MTIzNDU2Nzg5MDEyMzQ1Ng==",
+				"workloadCertTTL": "12h", "workloadRotateBefore": "4h"},
+			check: func(t *testing.T, cfg *Config) {
+				assert.True(t, cfg.Enable)
+				assert.Equal(t, 12*time.Hour, cfg.WorkloadCertTTL)
+				assert.Equal(t, 4*time.Hour, cfg.WorkloadRotateBefore)
+			},
+		},
+		{
+			name: "overlap-at-least-workload-ttl",
+			raw: map[string]interface{}{
+				"workloadCertTTL":      "72h",
+				"workloadRotateBefore": "24h",
+				"trustBundleOverlap":   "1h",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, 72*time.Hour, cfg.TrustBundleOverlap)
+			},
+		},
+		{
+			name:    "rotate-before-exceed-ttl",
+			raw:     map[string]interface{}{"workloadCertTTL": "1h", "workloadRotateBefore": "2h"},
+			wantErr: true,
+		},
+		{
+			name:    "root-rotate-before-overlap",
+			raw:     map[string]interface{}{"rootRotateBefore": "24h"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseConfig(tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestAuthority_Issue(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	storage := newMemoryCAStore()
+	cfg := testConfig(t)
+	authority, err := newAuthority(cfg, storage, &aes.AESCrypto{}, clock.Now)
+	assert.NoError(t, err)
+
+	pairs, _ := storage.GetCAKeyPairs()
+	assert.Len(t, pairs, 1)
+	assert.NotContains(t, pairs[0].KeyCipher, pemPrivateKey)
+
+	cert, err := authority.Issue("default", "echo")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", cert.BundleRevision)
+	assert.NoError(t, verifyCert(t, cert, cert.TrustBundle, clock.now))
+
+	block, _ := pem.Decode(cert.CertChain)
+	leaf, _ := x509.ParseCertificate(block.Bytes)
+	assert.Len(t, leaf.URIs, 1)
+	assert.Equal(t, "spiffe://default/echo", leaf.URIs[0].String())
+	assert.Equal(t, clock.now.Add(24*time.Hour).Unix(), leaf.NotAfter.Unix())
+
+	_, err = authority.Issue("default", "")
+	assert.Error(t, err)
+
+	// 另一个节点共享同一份根证书
+	other, err := newAuthority(cfg, storage, &aes.AESCrypto{}, clock.Now)
+	assert.NoError(t, err)
+	otherCert, err := other.Issue("default", "echo")
+	assert.NoError(t, err)
+	assert.NoError(t, verifyCert(t, otherCert, cert.TrustBundle, clock.now))
+}
+
+func TestAuthority_ConfiguredDataKey(t *testing.T) {
+	storage := newMemoryCAStore()
+	// 没有配置加密密钥时拒绝启动
+	_, err := NewAuthority(DefaultConfig(), storage, &aes.AESCrypto{})
+	assert.Error(t, err)
+	pairs, _ := storage.GetCAKeyPairs()
+	assert.Empty(t, pairs)
+
+	cfg := testConfig(t)
+	_, err = NewAuthority(cfg, storage, &aes.AESCrypto{})
+	assert.NoError(t, err)
+	pairs, _ = storage.GetCAKeyPairs()
+	assert.Len(t, pairs, 1)
+
+	// 密钥不一致时无法解密根证书
+	otherKey, _ := (&aes.AESCrypto{}).GenerateKey()
+	cfg.DataKey = base64.StdEncoding.EncodeToString(otherKey)
+	_, err = NewAuthority(cfg, storage, &aes.AESCrypto{})
+	assert.Error(t, err)
+}
+
+func TestAuthority_RotateRoot(t *testing.T) {
+	cfg := testConfig(t)
+	clock := &fakeClock{now: time.Now()}
+	storage := newMemoryCAStore()
+	authority, err := newAuthority(cfg, storage, &aes.AESCrypto{}, clock.Now)
+	assert.NoError(t, err)
+	oldCert, err := authority.Issue("default", "echo")
+	assert.NoError(t, err)
+
+	// 根证书临近过期，刷新时自动轮换
+	clock.now = clock.now.Add(cfg.RootCertTTL - cfg.RootRotateBefore + time.Hour)
+	assert.NoError(t, authority.Refresh())
+	roots := authority.Roots()
+	assert.Len(t, roots, 2)
+	assert.False(t, roots[0].Signing)
+	assert.Equal(t, clock.now.Add(cfg.TrustBundleOverlap).Unix(), roots[0].RetireTime.Unix())
+	assert.True(t, roots[1].Signing)
+
+	// 重叠期内旧根证书签发的证书仍然可以通过新的信任列表校验
+	bundle, revision := authority.TrustBundle()
+	assert.Equal(t, "1,2", revision)
+	assert.NoError(t, verifyCert(t, oldCert, bundle, oldCert.NotAfter.Add(-time.Minute)))
+	newCert, err := authority.Issue("default", "echo")
+	assert.NoError(t, err)
+	assert.NoError(t, verifyCert(t, newCert, bundle, clock.now))
+	assert.Error(t, verifyCert(t, newCert, oldCert.TrustBundle, clock.now))
+
+	// 重复刷新不会再次轮换
+	assert.NoError(t, authority.Refresh())
+	assert.Len(t, authority.Roots(), 2)
+
+	// 重叠期结束后旧根证书被清理
+	clock.now = clock.now.Add(cfg.TrustBundleOverlap)
+	assert.NoError(t, authority.Refresh())
+	roots = authority.Roots()
+	assert.Len(t, roots, 1)
+	assert.Equal(t, uint64(2), roots[0].Generation)
+	_, revision = authority.TrustBundle()
+	assert.Equal(t, "2", revision)
+	pairs, _ := storage.GetCAKeyPairs()
+	assert.Len(t, pairs, 1)
+
+	// 手动轮换
+	assert.NoError(t, authority.RotateRoot())
+	_, revision = authority.TrustBundle()
+	assert.Equal(t, "2,3", revision)
+}
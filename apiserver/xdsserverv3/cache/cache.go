@@ -26,6 +26,7 @@ import (
 	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/server/stream/v3"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
 	"github.com/polarismesh/polaris/common/utils"
@@ -74,7 +75,9 @@ func NewUpdateResourcesRequest() *UpdateResourcesRequest {
 	return &UpdateResourcesRequest{
 		lock:               &sync.Mutex{},
 		Lds:                map[string]map[string]types.Resource{},
+		Sds:                map[string]map[string]types.Resource{},
 		NamespaceResources: map[string]*NamespaceUpdateResourcesRequest{},
+		RunTypeResources:   map[resource.RunType]map[string]*NamespaceUpdateResourcesRequest{},
 	}
 }
 
@@ -83,8 +86,12 @@ type UpdateResourcesRequest struct {
 	lock *sync.Mutex
 	// Lds LDS 相关的资源
 	Lds map[string]map[string]types.Resource
+	// Sds 内置 CA 为 Envoy Node 签发的证书, 每次更新整体替换
+	Sds map[string]map[string]types.Resource
 	// NamespaceResources .
 	NamespaceResources map[string]*NamespaceUpdateResourcesRequest
+	// RunTypeResources 不和 sidecar/gateway 共用的资源，按照运行模式以及命名空间隔离，目前只有 proxyless grpc
+	RunTypeResources map[resource.RunType]map[string]*NamespaceUpdateResourcesRequest
 }
 
 // ------------- 针对普通的 xDS 资源 -------------
@@ -111,6 +118,37 @@ func (r *UpdateResourcesRequest) RemoveNormalNamespaces(namespace string, tls re
 	r.NamespaceResources[namespace].RemoveNormal(xdsType, res)
 }
 
+// ------------- 针对特定运行模式的 xDS 资源 -------------
+
+// AddRunTypeNamespaces .
+func (r *UpdateResourcesRequest) AddRunTypeNamespaces(runType resource.RunType, namespace string,
+	xdsType resource.XDSType, res []types.Resource) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.loadRunTypeNamespace(runType, namespace).AddNormals(xdsType, res)
+}
+
+// RemoveRunTypeNamespaces .
+func (r *UpdateResourcesRequest) RemoveRunTypeNamespaces(runType resource.RunType, namespace string,
+	xdsType resource.XDSType, res []types.Resource) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.loadRunTypeNamespace(runType, namespace).RemoveNormal(xdsType, res)
+}
+
+func (r *UpdateResourcesRequest) loadRunTypeNamespace(runType resource.RunType,
+	namespace string) *NamespaceUpdateResourcesRequest {
+	if _, ok := r.RunTypeResources[runType]; !ok {
+		r.RunTypeResources[runType] = map[string]*NamespaceUpdateResourcesRequest{}
+	}
+	if _, ok := r.RunTypeResources[runType][namespace]; !ok {
+		r.RunTypeResources[runType][namespace] = NewNamespaceUpdateResourcesRequest()
+	}
+	return r.RunTypeResources[runType][namespace]
+}
+
 // ------------- 针对 TLS 相关的 xDS 资源 -------------
 
 // AddTlsNamespaces .
@@ -200,10 +238,15 @@ type ResourcesContainer struct {
 	// instantiated by calling ConstructVersionMap().
 	// VersionMap is only to be used with delta xDS.
 	VersionMap map[string]string
+	// ackedSnapshots 最近被 Envoy 确认过的资源快照，按照确认顺序由旧到新排列
+	ackedSnapshots []*ResourcesContainer
+	// quarantinedVersion 被大量 Envoy 拒绝而隔离的资源版本
+	quarantinedVersion string
 }
 
 func (s *ResourcesContainer) updateGlobalRevision() {
 	s.GlobalVersion = utils.NewUUID()
+	s.quarantinedVersion = ""
 }
 
 // ConstructVersionMap will construct a version map based on the current state of a snapshot
@@ -293,10 +336,16 @@ type ResourceCache struct {
 	deltaWatchCount int64
 	// ads flag to hold responses until all resources are named
 	ads bool
+	// quarantineThreshold 同一资源版本被多少个 Envoy 节点拒绝后进行隔离，小于等于 0 表示不开启
+	quarantineThreshold int
 	// ldsResources 记录 Envoy Node LDS 的资源记录信息
 	ldsResources map[string]*ResourcesContainer
+	// sdsResources 记录 Envoy Node 的证书信息
+	sdsResources map[string]*ResourcesContainer
 	// namespaceContainer 按照命名空间级别隔离 xDS resources
 	namespaceContainer map[string]*NamespaceResourcesContainer
+	// runTypeContainer 不和 sidecar/gateway 共用的 xDS resources, 按照运行模式以及命名空间隔离
+	runTypeContainer map[resource.RunType]map[string]*NamespaceResourcesContainer
 	// status information for all nodes indexed by node IDs
 	status map[string]*NamespaceStatusInfo
 
@@ -317,7 +366,9 @@ func NewResourceCache(hook CacheHook) *ResourceCache {
 		hook:               hook,
 		ads:                true,
 		ldsResources:       make(map[string]*ResourcesContainer),
+		sdsResources:       make(map[string]*ResourcesContainer),
 		namespaceContainer: make(map[string]*NamespaceResourcesContainer),
+		runTypeContainer:   make(map[resource.RunType]map[string]*NamespaceResourcesContainer),
 		status:             make(map[string]*NamespaceStatusInfo),
 	}
 	return cache
@@ -329,28 +380,77 @@ func (sc *ResourceCache) CleanEnvoyNodeCache(node *corev3.Node) error {
 	defer sc.mu.Unlock()
 
 	delete(sc.ldsResources, node.GetId())
+	delete(sc.sdsResources, node.GetId())
 	return nil
 }
 
+// HasRunTypeNamespace 是否已经为运行模式构建了命名空间下的 xDS 资源
+func (sc *ResourceCache) HasRunTypeNamespace(runType resource.RunType, namespace string) bool {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	_, ok := sc.runTypeContainer[runType][namespace]
+	return ok
+}
+
+// CleanRunTypeNamespace 清理运行模式在命名空间下的 xDS 资源
+func (sc *ResourceCache) CleanRunTypeNamespace(runType resource.RunType, namespace string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	delete(sc.runTypeContainer[runType], namespace)
+}
+
+// HasNodeSecrets Envoy Node 的证书是否仍然在缓存中
+func (sc *ResourceCache) HasNodeSecrets(nodeId string) bool {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	_, ok := sc.sdsResources[nodeId]
+	return ok
+}
+
 func (sc *ResourceCache) updateResourceContainer(ctx context.Context, req *UpdateResourcesRequest) {
 	// 更新 LDS 资源信息
 	for nodeId, resources := range req.Lds {
-		if _, ok := sc.ldsResources[nodeId]; !ok {
-			sc.ldsResources[nodeId] = &ResourcesContainer{
-				Resources: resources,
-			}
-			sc.ldsResources[nodeId].updateGlobalRevision()
-			_ = sc.ldsResources[nodeId].ConstructVersionMap(nil)
+		// 每次 Watch 都会重新构建 LDS, 只有内容发生变化时才更新版本
+		if container, ok := sc.ldsResources[nodeId]; ok && equalResources(container.Resources, resources) {
+			continue
+		}
+		sc.ldsResources[nodeId] = &ResourcesContainer{
+			Resources: resources,
 		}
+		sc.ldsResources[nodeId].updateGlobalRevision()
+		_ = sc.ldsResources[nodeId].ConstructVersionMap(nil)
 	}
 
-	namespaceResources := req.NamespaceResources
+	// 更新 SDS 资源信息
+	for nodeId, resources := range req.Sds {
+		container := &ResourcesContainer{
+			Resources: resources,
+		}
+		container.updateGlobalRevision()
+		_ = container.ConstructVersionMap(nil)
+		sc.sdsResources[nodeId] = container
+	}
+
+	updateNamespaceContainers(sc.namespaceContainer, req.NamespaceResources)
+	for runType, namespaceResources := range req.RunTypeResources {
+		if _, ok := sc.runTypeContainer[runType]; !ok {
+			sc.runTypeContainer[runType] = map[string]*NamespaceResourcesContainer{}
+		}
+		updateNamespaceContainers(sc.runTypeContainer[runType], namespaceResources)
+	}
+}
+
+func updateNamespaceContainers(containers map[string]*NamespaceResourcesContainer,
+	namespaceResources map[string]*NamespaceUpdateResourcesRequest) {
 	for ns, nsResources := range namespaceResources {
-		if _, ok := sc.namespaceContainer[ns]; !ok {
-			sc.namespaceContainer[ns] = newNamespaceResourcesContainer(ns)
+		if _, ok := containers[ns]; !ok {
+			containers[ns] = newNamespaceResourcesContainer(ns)
 		}
 
-		namespaceContainer := sc.namespaceContainer[ns]
+		namespaceContainer := containers[ns]
 
 		// OnDemand 场景下的资源直接一把更新
 		demandResources := map[resource.XDSType]*ResourcesContainer{}
@@ -411,6 +511,19 @@ func (sc *ResourceCache) updateResourceContainer(ctx context.Context, req *Updat
 	}
 }
 
+func equalResources(a, b map[string]types.Resource) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, res := range a {
+		other, ok := b[name]
+		if !ok || !proto.Equal(res, other) {
+			return false
+		}
+	}
+	return true
+}
+
 // UpdateResources updates a snapshot for a node.
 func (sc *ResourceCache) UpdateResources(ctx context.Context, req *UpdateResourcesRequest) error {
 	sc.mu.Lock()
@@ -425,7 +538,7 @@ func (sc *ResourceCache) UpdateResources(ctx context.Context, req *UpdateResourc
 			defer info.mu.Unlock()
 			for id, watch := range info.watches {
 				watchType := resource.FormatTypeUrl(watch.Request.TypeUrl)
-				container, exists := sc.loadResourceContainer(info.client, watchType)
+				container, exists := sc.loadServingContainer(info.client, watchType)
 				if !exists {
 					continue
 				}
@@ -443,7 +556,7 @@ func (sc *ResourceCache) UpdateResources(ctx context.Context, req *UpdateResourc
 				}
 			}
 
-			namespaceContainer, exist := sc.namespaceContainer[ns]
+			namespaceContainer, exist := sc.loadNamespaceContainers(info.client)[ns]
 			if !exist {
 				continue
 			}
@@ -462,7 +575,7 @@ func (sc *ResourceCache) UpdateResources(ctx context.Context, req *UpdateResourc
 			// process our delta watches
 			for id, watch := range info.deltaWatches {
 				watchType := resource.FormatTypeUrl(watch.Request.TypeUrl)
-				container, exist := sc.loadResourceContainer(info.client, watchType)
+				container, exist := sc.loadServingContainer(info.client, watchType)
 				if !exist {
 					continue
 				}
@@ -525,7 +638,7 @@ func (sc *ResourceCache) CreateWatch(request *cachev3.Request, streamState strea
 
 	var version string
 
-	container, exists := sc.loadResourceContainer(client, resource.FormatTypeUrl(request.GetTypeUrl()))
+	container, exists := sc.loadServingContainer(client, resource.FormatTypeUrl(request.GetTypeUrl()))
 
 	if exists {
 		version = container.GlobalVersion
@@ -673,7 +786,7 @@ func (sc *ResourceCache) CreateDeltaWatch(request *cachev3.DeltaRequest, state s
 	// update last watch request time
 	info.setLastDeltaWatchRequestTime(time.Now())
 
-	container, exists := sc.loadResourceContainer(client, resource.FormatTypeUrl(request.GetTypeUrl()))
+	container, exists := sc.loadServingContainer(client, resource.FormatTypeUrl(request.GetTypeUrl()))
 
 	// There are three different cases that leads to a delayed watch trigger:
 	// - no snapshot exists for the requested nodeID
@@ -721,6 +834,11 @@ func (sc *ResourceCache) respondDelta(ctx context.Context, container *ResourcesC
 	state stream.StreamState,
 ) (*cachev3.RawDeltaResponse, error) {
 
+	if container.VersionMap == nil {
+		if err := container.ConstructVersionMap(nil); err != nil {
+			return nil, err
+		}
+	}
 	resp := createDeltaResponse(ctx, request, state, resourceContainer{
 		resourceMap:   container.Resources,
 		versionMap:    container.VersionMap,
@@ -776,7 +894,7 @@ func (sc *ResourceCache) Fetch(ctx context.Context, request *cachev3.Request) (c
 	sc.mu.RLock()
 	defer sc.mu.RUnlock()
 
-	container, exists := sc.loadResourceContainer(client, resource.FormatTypeUrl(request.GetTypeUrl()))
+	container, exists := sc.loadServingContainer(client, resource.FormatTypeUrl(request.GetTypeUrl()))
 
 	if exists {
 		// Respond only if the request version is distinct from the current snapshot state.
@@ -810,9 +928,22 @@ func (sc *ResourceCache) loadWatchStatus(node *corev3.Node) (*statusInfo, *resou
 	return info, client
 }
 
+// loadNamespaceContainers proxyless grpc 使用独立的资源容器，其余节点共用 namespaceContainer
+func (sc *ResourceCache) loadNamespaceContainers(client *resource.XDSClient) map[string]*NamespaceResourcesContainer {
+	if client.IsProxyless() {
+		return sc.runTypeContainer[client.RunType]
+	}
+	return sc.namespaceContainer
+}
+
 func (sc *ResourceCache) loadResourceContainer(client *resource.XDSClient, watchType resource.XDSType) (*ResourcesContainer, bool) {
+	if watchType == resource.SDS {
+		// 证书是 Envoy Node 维度的资源，不依赖命名空间下的 xDS 资源
+		container, exists := sc.sdsResources[client.GetNodeID()]
+		return container, exists
+	}
 
-	namespaceContainer, ok := sc.namespaceContainer[client.GetSelfNamespace()]
+	namespaceContainer, ok := sc.loadNamespaceContainers(client)[client.GetSelfNamespace()]
 	if !ok {
 		log.Error("load resource container not found namespace", zap.String("id", client.GetNodeID()),
 			zap.String("namespace", client.GetSelfNamespace()))
@@ -824,6 +955,11 @@ func (sc *ResourceCache) loadResourceContainer(client *resource.XDSClient, watch
 
 	switch watchType {
 	case resource.LDS:
+		if client.IsProxyless() {
+			// proxyless grpc 的 API listener 是命名空间维度的资源
+			container, exists = namespaceContainer.resourcesContainer[watchType]
+			break
+		}
 		// 获取到 Envoy Node 对应希望看到的 ldsRes 资源
 		container, exists = sc.ldsResources[client.GetNodeID()]
 	case resource.CDS:
@@ -863,6 +999,11 @@ func (sc *ResourceCache) GetResources(typeUrl resource.XDSType, ns, nodeId strin
 	sc.mu.RLock()
 	defer sc.mu.RUnlock()
 
+	if typeUrl == resource.SDS {
+		// 证书中包含私钥，不对外展示
+		return map[string]types.Resource{}
+	}
+
 	var data *ResourcesContainer
 	if typeUrl == resource.LDS {
 		val, ok := sc.ldsResources[nodeId]
@@ -887,3 +1028,22 @@ func (sc *ResourceCache) GetResources(typeUrl resource.XDSType, ns, nodeId strin
 	}
 	return copyData
 }
+
+// GetRunTypeResources 获取运行模式独立的 xDS 资源
+func (sc *ResourceCache) GetRunTypeResources(runType resource.RunType, typeUrl resource.XDSType,
+	ns string) map[string]types.Resource {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	copyData := make(map[string]types.Resource)
+	container, ok := sc.runTypeContainer[runType][ns]
+	if !ok {
+		return copyData
+	}
+	if val, ok := container.resourcesContainer[typeUrl]; ok {
+		for k, v := range val.Resources {
+			copyData[k] = v
+		}
+	}
+	return copyData
+}
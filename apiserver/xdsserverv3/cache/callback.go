@@ -23,20 +23,23 @@ import (
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/status"
 
 	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
 )
 
-func NewCallback(cacheMgr *ResourceCache, nodeMgr *resource.XDSNodeManager) *Callbacks {
+func NewCallback(cacheMgr *ResourceCache, nodeMgr *resource.XDSNodeManager, syncTracker *SyncTracker) *Callbacks {
 	return &Callbacks{
-		cacheMgr: cacheMgr,
-		nodeMgr:  nodeMgr,
+		cacheMgr:    cacheMgr,
+		nodeMgr:     nodeMgr,
+		syncTracker: syncTracker,
 	}
 }
 
 type Callbacks struct {
-	cacheMgr *ResourceCache
-	nodeMgr  *resource.XDSNodeManager
+	cacheMgr    *ResourceCache
+	nodeMgr     *resource.XDSNodeManager
+	syncTracker *SyncTracker
 }
 
 func (cb *Callbacks) OnStreamOpen(_ context.Context, id int64, typ string) error {
@@ -49,12 +52,14 @@ func (cb *Callbacks) OnDeltaStreamOpen(_ context.Context, id int64, typ string)
 
 func (cb *Callbacks) OnStreamClosed(id int64, node *corev3.Node) {
 	cb.nodeMgr.DelNode(id)
+	cb.syncTracker.RemoveNode(node)
 	// 清理 cache
 	_ = cb.cacheMgr.CleanEnvoyNodeCache(node)
 }
 
 func (cb *Callbacks) OnDeltaStreamClosed(id int64, node *corev3.Node) {
 	cb.nodeMgr.DelNode(id)
+	cb.syncTracker.RemoveNode(node)
 	// 清理 cache
 	_ = cb.cacheMgr.CleanEnvoyNodeCache(node)
 }
@@ -65,6 +70,7 @@ func (cb *Callbacks) OnStreamRequest(id int64, req *discovery.DiscoveryRequest)
 	req.Node = nil
 	log.Info("[XDSV3][Receive] receive stream request", zap.Int64("stream-id", id), zap.String("node-id", node.Id), zap.Any("req", req))
 	req.Node = node
+	cb.trackRequest(node, req.GetTypeUrl(), req.GetVersionInfo(), req.GetResponseNonce(), req.GetErrorDetail())
 	return nil
 }
 
@@ -74,6 +80,7 @@ func (cb *Callbacks) OnStreamResponse(_ context.Context, id int64, req *discover
 	req.Node = nil
 	log.Info("[XDSV3][Receive] send stream response", zap.Int64("stream-id", id), zap.String("node-id", node.Id), zap.Any("req", req))
 	req.Node = node
+	cb.syncTracker.OnSent(node, resp.GetTypeUrl(), resp.GetVersionInfo(), resp.GetNonce())
 }
 
 func (cb *Callbacks) OnStreamDeltaRequest(id int64, req *discovery.DeltaDiscoveryRequest) error {
@@ -82,6 +89,7 @@ func (cb *Callbacks) OnStreamDeltaRequest(id int64, req *discovery.DeltaDiscover
 	req.Node = nil
 	log.Info("[XDSV3][Receive] receive delta stream request", zap.Int64("stream-id", id), zap.String("node-id", node.Id), zap.Any("req", req))
 	req.Node = node
+	cb.trackRequest(node, req.GetTypeUrl(), "", req.GetResponseNonce(), req.GetErrorDetail())
 	return nil
 }
 
@@ -91,6 +99,22 @@ func (cb *Callbacks) OnStreamDeltaResponse(id int64, req *discovery.DeltaDiscove
 	req.Node = nil
 	log.Info("[XDSV3][Receive] send delta stream response", zap.Int64("stream-id", id), zap.String("node-id", node.Id), zap.Any("req", req))
 	req.Node = node
+	cb.syncTracker.OnSent(node, resp.GetTypeUrl(), resp.GetSystemVersionInfo(), resp.GetNonce())
+}
+
+// trackRequest 记录 Envoy 对下发资源的 ACK/NACK，NACK 达到阈值时由 ResourceCache 隔离对应的资源版本
+func (cb *Callbacks) trackRequest(node *corev3.Node, typeUrl, ackVersion, nonce string, errDetail *status.Status) {
+	version, nackNodes := cb.syncTracker.OnRequest(node, typeUrl, ackVersion, nonce, errDetail)
+	if len(version) == 0 {
+		return
+	}
+	if errDetail != nil {
+		log.Warn("[XDSV3][Receive] envoy rejected xds resource", zap.String("node-id", node.GetId()),
+			zap.String("type", typeUrl), zap.String("version", version), zap.String("error", errDetail.GetMessage()))
+		cb.cacheMgr.OnNack(node, typeUrl, version, nackNodes)
+		return
+	}
+	cb.cacheMgr.OnAck(node, typeUrl, version)
 }
 
 func (cb *Callbacks) OnFetchRequest(_ context.Context, req *discovery.DiscoveryRequest) error {
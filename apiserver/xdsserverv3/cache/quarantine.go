@@ -0,0 +1,114 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cache
+
+import (
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
+	"github.com/polarismesh/polaris/common/metrics"
+)
+
+// maxAckedSnapshots 每个资源容器最多保留的已确认快照数量
+const maxAckedSnapshots = 2
+
+// SetQuarantineThreshold 设置资源版本隔离的阈值，同一版本被 threshold 个 Envoy 节点拒绝后回退到最近一次被确认的快照
+func (sc *ResourceCache) SetQuarantineThreshold(threshold int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.quarantineThreshold = threshold
+}
+
+// OnAck Envoy 确认了资源版本，记录该版本的资源快照用于后续回退
+func (sc *ResourceCache) OnAck(node *corev3.Node, typeUrl, version string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.quarantineThreshold <= 0 {
+		return
+	}
+	container, exists := sc.loadResourceContainer(resource.ParseXDSClient(node), resource.FormatTypeUrl(typeUrl))
+	if !exists {
+		return
+	}
+	container.recordAcked(version)
+}
+
+// OnNack Envoy 拒绝了资源版本，当拒绝该版本的节点数量达到阈值时隔离该版本
+func (sc *ResourceCache) OnNack(node *corev3.Node, typeUrl, version string, nackNodes int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.quarantineThreshold <= 0 || nackNodes < sc.quarantineThreshold {
+		return
+	}
+	container, exists := sc.loadResourceContainer(resource.ParseXDSClient(node), resource.FormatTypeUrl(typeUrl))
+	if !exists || container.GlobalVersion != version || container.quarantinedVersion == version {
+		return
+	}
+	container.quarantinedVersion = version
+	fallback := container.effective()
+	log.Warn("[XDSV3] quarantine xds resource version rejected by too many nodes",
+		zap.String("type", typeUrl), zap.String("version", version), zap.Int("nack-nodes", nackNodes),
+		zap.String("fallback", fallback.GlobalVersion))
+	metrics.ReportXDSQuarantine(typeUrl)
+}
+
+// loadServingContainer 获取实际用于下发的资源容器，当前版本被隔离时返回最近一次被确认的快照
+func (sc *ResourceCache) loadServingContainer(client *resource.XDSClient,
+	watchType resource.XDSType) (*ResourcesContainer, bool) {
+	container, exists := sc.loadResourceContainer(client, watchType)
+	if !exists {
+		return nil, false
+	}
+	return container.effective(), true
+}
+
+// effective 返回当前实际用于下发的资源，当前版本被隔离时回退到最近一次被确认的资源快照
+func (s *ResourcesContainer) effective() *ResourcesContainer {
+	if len(s.quarantinedVersion) == 0 || s.quarantinedVersion != s.GlobalVersion {
+		return s
+	}
+	for i := len(s.ackedSnapshots) - 1; i >= 0; i-- {
+		if s.ackedSnapshots[i].GlobalVersion != s.quarantinedVersion {
+			return s.ackedSnapshots[i]
+		}
+	}
+	return s
+}
+
+// recordAcked 记录被确认版本的资源快照，资源容器会被原地更新，因此需要拷贝一份资源列表
+func (s *ResourcesContainer) recordAcked(version string) {
+	if s.GlobalVersion != version || s.quarantinedVersion == version {
+		return
+	}
+	if n := len(s.ackedSnapshots); n > 0 && s.ackedSnapshots[n-1].GlobalVersion == version {
+		return
+	}
+	snapshot := &ResourcesContainer{
+		GlobalVersion: s.GlobalVersion,
+		Resources:     make(map[string]types.Resource, len(s.Resources)),
+	}
+	for name, res := range s.Resources {
+		snapshot.Resources[name] = res
+	}
+	s.ackedSnapshots = append(s.ackedSnapshots, snapshot)
+	if len(s.ackedSnapshots) > maxAckedSnapshots {
+		s.ackedSnapshots = s.ackedSnapshots[len(s.ackedSnapshots)-maxAckedSnapshots:]
+	}
+}
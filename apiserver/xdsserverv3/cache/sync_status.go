@@ -0,0 +1,265 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cache
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"google.golang.org/genproto/googleapis/rpc/status"
+
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
+	"github.com/polarismesh/polaris/common/metrics"
+)
+
+// SyncStatus xDS 资源在 Envoy 节点上的同步状态，语义与 istioctl proxy-status 保持一致
+type SyncStatus string
+
+const (
+	// SyncStatusSynced 最近一次下发的版本已经被 Envoy 确认
+	SyncStatusSynced SyncStatus = "SYNCED"
+	// SyncStatusStale 已经下发但是 Envoy 尚未确认
+	SyncStatusStale SyncStatus = "STALE"
+	// SyncStatusNacked 最近一次下发的版本被 Envoy 拒绝
+	SyncStatusNacked SyncStatus = "NACKED"
+	// SyncStatusNotSent 尚未下发过任何资源
+	SyncStatusNotSent SyncStatus = "NOT_SENT"
+)
+
+// maxPendingNonces 每类资源最多记录的待确认 nonce 数量
+const maxPendingNonces = 16
+
+// TypeSyncStatus 记录 Envoy 节点某一类 xDS 资源的下发以及确认情况
+type TypeSyncStatus struct {
+	TypeUrl       string     `json:"typeUrl"`
+	Status        SyncStatus `json:"status"`
+	SentVersion   string     `json:"sentVersion"`
+	SentNonce     string     `json:"sentNonce"`
+	SentTime      time.Time  `json:"sentTime"`
+	AckedVersion  string     `json:"ackedVersion"`
+	AckedTime     time.Time  `json:"ackedTime"`
+	NackedVersion string     `json:"nackedVersion,omitempty"`
+	NackedTime    time.Time  `json:"nackedTime,omitempty"`
+	NackError     string     `json:"nackError,omitempty"`
+	NackCount     int64      `json:"nackCount"`
+	// pendingNonces 已下发但尚未收到 ACK/NACK 的 nonce -> version
+	pendingNonces map[string]string
+}
+
+func (t *TypeSyncStatus) syncStatus() SyncStatus {
+	switch {
+	case len(t.SentVersion) == 0:
+		return SyncStatusNotSent
+	case t.AckedVersion == t.SentVersion:
+		return SyncStatusSynced
+	case t.NackedVersion == t.SentVersion:
+		return SyncStatusNacked
+	default:
+		return SyncStatusStale
+	}
+}
+
+// NodeSyncStatus 记录 Envoy 节点所有 xDS 资源的同步情况
+type NodeSyncStatus struct {
+	NodeID    string                     `json:"nodeId"`
+	Namespace string                     `json:"namespace"`
+	Types     map[string]*TypeSyncStatus `json:"types"`
+}
+
+// SyncTracker 跟踪每个 Envoy 节点每类 xDS 资源的下发、ACK 以及 NACK 情况
+type SyncTracker struct {
+	lock  sync.RWMutex
+	nodes map[string]*NodeSyncStatus
+	// nackNodes version -> 拒绝了该版本的 Envoy 节点
+	nackNodes map[string]map[string]struct{}
+}
+
+// NewSyncTracker .
+func NewSyncTracker() *SyncTracker {
+	return &SyncTracker{
+		nodes:     map[string]*NodeSyncStatus{},
+		nackNodes: map[string]map[string]struct{}{},
+	}
+}
+
+func (st *SyncTracker) loadTypeStatus(node *corev3.Node, typeUrl string) *TypeSyncStatus {
+	client := resource.ParseXDSClient(node)
+	nodeStatus, ok := st.nodes[client.GetNodeID()]
+	if !ok {
+		nodeStatus = &NodeSyncStatus{
+			NodeID:    client.GetNodeID(),
+			Namespace: client.GetSelfNamespace(),
+			Types:     map[string]*TypeSyncStatus{},
+		}
+		st.nodes[client.GetNodeID()] = nodeStatus
+	}
+	typeStatus, ok := nodeStatus.Types[typeUrl]
+	if !ok {
+		typeStatus = &TypeSyncStatus{
+			TypeUrl:       typeUrl,
+			pendingNonces: map[string]string{},
+		}
+		nodeStatus.Types[typeUrl] = typeStatus
+		metrics.ReportXDSSyncStatusChange(typeUrl, "", string(typeStatus.syncStatus()))
+	}
+	return typeStatus
+}
+
+// OnSent 记录下发给 Envoy 节点的资源版本
+func (st *SyncTracker) OnSent(node *corev3.Node, typeUrl, version, nonce string) {
+	if node == nil {
+		return
+	}
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	typeStatus := st.loadTypeStatus(node, typeUrl)
+	before := typeStatus.syncStatus()
+	typeStatus.SentVersion = version
+	typeStatus.SentNonce = nonce
+	typeStatus.SentTime = time.Now()
+	if len(typeStatus.pendingNonces) >= maxPendingNonces {
+		typeStatus.pendingNonces = map[string]string{}
+	}
+	typeStatus.pendingNonces[nonce] = version
+	metrics.ReportXDSSyncStatusChange(typeUrl, string(before), string(typeStatus.syncStatus()))
+}
+
+// OnRequest 处理 Envoy 对上一次下发的响应，ackVersion 为 SotW 请求中携带的 version_info，
+// 返回被确认或者被拒绝的版本，当请求为 NACK 时同时返回拒绝该版本的节点数量
+func (st *SyncTracker) OnRequest(node *corev3.Node, typeUrl, ackVersion, nonce string,
+	errDetail *status.Status) (string, int) {
+	if node == nil || len(nonce) == 0 {
+		return "", 0
+	}
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	typeStatus := st.loadTypeStatus(node, typeUrl)
+	before := typeStatus.syncStatus()
+	defer func() {
+		metrics.ReportXDSSyncStatusChange(typeUrl, string(before), string(typeStatus.syncStatus()))
+	}()
+
+	version, ok := typeStatus.pendingNonces[nonce]
+	if ok {
+		delete(typeStatus.pendingNonces, nonce)
+	}
+	nodeId := node.GetId()
+	if errDetail != nil {
+		if !ok {
+			return "", 0
+		}
+		typeStatus.NackedVersion = version
+		typeStatus.NackedTime = time.Now()
+		typeStatus.NackError = errDetail.GetMessage()
+		typeStatus.NackCount++
+		metrics.ReportXDSNack(typeUrl)
+		if _, exist := st.nackNodes[version]; !exist {
+			st.nackNodes[version] = map[string]struct{}{}
+		}
+		st.nackNodes[version][nodeId] = struct{}{}
+		return version, len(st.nackNodes[version])
+	}
+
+	if len(ackVersion) != 0 {
+		version = ackVersion
+	}
+	if len(version) == 0 {
+		return "", 0
+	}
+	typeStatus.AckedVersion = version
+	typeStatus.AckedTime = time.Now()
+	// 节点已经确认了新的版本，不再计入之前拒绝版本的统计
+	if len(typeStatus.NackedVersion) != 0 && typeStatus.NackedVersion != version {
+		st.removeNackNode(typeStatus.NackedVersion, nodeId)
+	}
+	return version, 0
+}
+
+func (st *SyncTracker) removeNackNode(version, nodeId string) {
+	nodes, ok := st.nackNodes[version]
+	if !ok {
+		return
+	}
+	delete(nodes, nodeId)
+	if len(nodes) == 0 {
+		delete(st.nackNodes, version)
+	}
+}
+
+// RemoveNode 清理 Envoy 节点的同步记录
+func (st *SyncTracker) RemoveNode(node *corev3.Node) {
+	if node == nil {
+		return
+	}
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	nodeStatus, ok := st.nodes[node.GetId()]
+	if !ok {
+		return
+	}
+	delete(st.nodes, node.GetId())
+	for typeUrl, typeStatus := range nodeStatus.Types {
+		metrics.ReportXDSSyncStatusChange(typeUrl, string(typeStatus.syncStatus()), "")
+		if len(typeStatus.NackedVersion) != 0 {
+			st.removeNackNode(typeStatus.NackedVersion, node.GetId())
+		}
+	}
+}
+
+// ListSyncStatus 查询 Envoy 节点的同步状态，namespace、nodeId、syncStatus 为空时不进行过滤
+func (st *SyncTracker) ListSyncStatus(namespace, nodeId string, syncStatus SyncStatus) []*NodeSyncStatus {
+	st.lock.RLock()
+	defer st.lock.RUnlock()
+
+	ret := make([]*NodeSyncStatus, 0, len(st.nodes))
+	for id, nodeStatus := range st.nodes {
+		if len(nodeId) != 0 && id != nodeId {
+			continue
+		}
+		if len(namespace) != 0 && nodeStatus.Namespace != namespace {
+			continue
+		}
+		item := &NodeSyncStatus{
+			NodeID:    nodeStatus.NodeID,
+			Namespace: nodeStatus.Namespace,
+			Types:     make(map[string]*TypeSyncStatus, len(nodeStatus.Types)),
+		}
+		matched := len(syncStatus) == 0
+		for typeUrl, typeStatus := range nodeStatus.Types {
+			copyStatus := *typeStatus
+			copyStatus.pendingNonces = nil
+			copyStatus.Status = typeStatus.syncStatus()
+			item.Types[typeUrl] = &copyStatus
+			if copyStatus.Status == syncStatus {
+				matched = true
+			}
+		}
+		if matched {
+			ret = append(ret, item)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].NodeID < ret[j].NodeID
+	})
+	return ret
+}
@@ -0,0 +1,122 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/status"
+
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
+)
+
+func TestSyncTracker(t *testing.T) {
+	tracker := NewSyncTracker()
+	node1 := &corev3.Node{Id: "sidecar~default/pod-1~10.0.0.1"}
+	node2 := &corev3.Node{Id: "sidecar~default/pod-2~10.0.0.2"}
+
+	// 初始订阅请求不携带 nonce，不做任何记录
+	version, _ := tracker.OnRequest(node1, resourcev3.ClusterType, "", "", nil)
+	assert.Empty(t, version)
+	assert.Empty(t, tracker.ListSyncStatus("", "", ""))
+
+	tracker.OnSent(node1, resourcev3.ClusterType, "v1", "1")
+	tracker.OnSent(node2, resourcev3.ClusterType, "v1", "1")
+	ret := tracker.ListSyncStatus("", node1.Id, "")
+	assert.Len(t, ret, 1)
+	assert.Equal(t, SyncStatusStale, ret[0].Types[resourcev3.ClusterType].Status)
+
+	// SotW ACK
+	version, _ = tracker.OnRequest(node1, resourcev3.ClusterType, "v1", "1", nil)
+	assert.Equal(t, "v1", version)
+	assert.Len(t, tracker.ListSyncStatus("", "", SyncStatusSynced), 1)
+
+	// NACK 统计拒绝该版本的节点数量
+	tracker.OnSent(node1, resourcev3.ClusterType, "v2", "2")
+	tracker.OnSent(node2, resourcev3.ClusterType, "v2", "2")
+	version, nackNodes := tracker.OnRequest(node1, resourcev3.ClusterType, "v1", "2",
+		&status.Status{Message: "invalid cluster"})
+	assert.Equal(t, "v2", version)
+	assert.Equal(t, 1, nackNodes)
+	_, nackNodes = tracker.OnRequest(node2, resourcev3.ClusterType, "", "2",
+		&status.Status{Message: "invalid cluster"})
+	assert.Equal(t, 2, nackNodes)
+
+	ret = tracker.ListSyncStatus("default", "", SyncStatusNacked)
+	assert.Len(t, ret, 2)
+	typeStatus := ret[0].Types[resourcev3.ClusterType]
+	assert.Equal(t, "v1", typeStatus.AckedVersion)
+	assert.Equal(t, "v2", typeStatus.NackedVersion)
+	assert.Equal(t, "invalid cluster", typeStatus.NackError)
+	assert.Equal(t, int64(1), typeStatus.NackCount)
+
+	// delta ACK 通过 nonce 找到对应的版本
+	tracker.OnSent(node1, resourcev3.ClusterType, "v3", "3")
+	version, _ = tracker.OnRequest(node1, resourcev3.ClusterType, "", "3", nil)
+	assert.Equal(t, "v3", version)
+	assert.Equal(t, map[string]struct{}{node2.Id: {}}, tracker.nackNodes["v2"])
+
+	tracker.RemoveNode(node2)
+	assert.Empty(t, tracker.nackNodes)
+	assert.Len(t, tracker.ListSyncStatus("", "", ""), 1)
+}
+
+func TestResourceCache_Quarantine(t *testing.T) {
+	sc := NewResourceCache(nil)
+	sc.SetQuarantineThreshold(2)
+	node := &corev3.Node{Id: "sidecar~default/pod-1~10.0.0.1"}
+	client := resource.ParseXDSClient(node)
+
+	update := func(name string) string {
+		req := NewUpdateResourcesRequest()
+		req.AddNormalNamespaces("default", resource.CDS, []types.Resource{&clusterv3.Cluster{Name: name}})
+		assert.NoError(t, sc.UpdateResources(context.Background(), req))
+		container, ok := sc.loadResourceContainer(client, resource.CDS)
+		assert.True(t, ok)
+		return container.GlobalVersion
+	}
+
+	goodVersion := update("good")
+	sc.OnAck(node, resourcev3.ClusterType, goodVersion)
+	badVersion := update("bad")
+
+	// 未达到阈值时不隔离
+	sc.OnNack(node, resourcev3.ClusterType, badVersion, 1)
+	serving, ok := sc.loadServingContainer(client, resource.CDS)
+	assert.True(t, ok)
+	assert.Equal(t, badVersion, serving.GlobalVersion)
+
+	sc.OnNack(node, resourcev3.ClusterType, badVersion, 2)
+	serving, ok = sc.loadServingContainer(client, resource.CDS)
+	assert.True(t, ok)
+	assert.Equal(t, goodVersion, serving.GlobalVersion)
+	assert.Len(t, serving.Resources, 1)
+	assert.Contains(t, serving.Resources, "good")
+
+	// 新版本生成后解除隔离
+	newVersion := update("fixed")
+	serving, ok = sc.loadServingContainer(client, resource.CDS)
+	assert.True(t, ok)
+	assert.Equal(t, newVersion, serving.GlobalVersion)
+}
@@ -52,6 +52,11 @@ const (
 func (cds *CDSBuilder) Generate(option *resource.BuildOption) (interface{}, error) {
 	var clusters []types.Resource
 
+	// proxyless grpc 不需要 passthrough cluster
+	if option.RunType == resource.RunTypeProxylessGRPC {
+		return cds.makeProxylessClusters(option), nil
+	}
+
 	// 默认 passthrough cluster
 	clusters = append(clusters, resource.PassthroughCluster)
 
@@ -121,6 +126,38 @@ func (cds *CDSBuilder) GenerateByDirection(option *resource.BuildOption,
 	return clusters, nil
 }
 
+// makeProxylessClusters 每一个 polaris service 以及路由规则中的每一个 subset 对应一个 grpc cluster
+func (cds *CDSBuilder) makeProxylessClusters(option *resource.BuildOption) []types.Resource {
+	var clusters []types.Resource
+	for svcKey, svc := range option.Services {
+		names := []string{resource.MakeProxylessClusterName(svcKey, "")}
+		for _, subset := range resource.ListProxylessSubsets(svc) {
+			names = append(names, resource.MakeProxylessClusterName(svcKey, subset.Name))
+		}
+		for _, name := range names {
+			c := &cluster.Cluster{
+				Name:                 name,
+				ClusterDiscoveryType: &cluster.Cluster_Type{Type: cluster.Cluster_EDS},
+				EdsClusterConfig: &cluster.Cluster_EdsClusterConfig{
+					ServiceName: name,
+					EdsConfig: &core.ConfigSource{
+						ResourceApiVersion: resourcev3.DefaultAPIVersion,
+						ConfigSourceSpecifier: &core.ConfigSource_Ads{
+							Ads: &core.AggregatedConfigSource{},
+						},
+					},
+				},
+			}
+			if !option.ForceDelete {
+				resource.MakeProxylessLbPolicy(c, svc)
+				c.OutlierDetection = resource.MakeProxylessOutlierDetection(svc)
+			}
+			clusters = append(clusters, c)
+		}
+	}
+	return clusters
+}
+
 func (cds *CDSBuilder) makeCluster(svcInfo *resource.ServiceInfo,
 	trafficDirection corev3.TrafficDirection, opt *resource.BuildOption) *cluster.Cluster {
 
@@ -24,6 +24,8 @@ import (
 	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
 	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
 
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/ca"
+	xdscache "github.com/polarismesh/polaris/apiserver/xdsserverv3/cache"
 	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
 	"github.com/polarismesh/polaris/common/utils"
 )
@@ -50,7 +52,13 @@ func (x *XDSServer) listXDSResource(resp http.ResponseWriter, req *http.Request)
 		namespace = "default"
 	}
 
-	res := x.cache.GetResources(resource.FromSimpleXDS(cType), namespace, nodeId)
+	var res map[string]types.Resource
+	// proxyless grpc 的资源和 sidecar/gateway 分开存放
+	if runType := resource.RunType(req.URL.Query().Get("runType")); runType == resource.RunTypeProxylessGRPC {
+		res = x.cache.GetRunTypeResources(runType, resource.FromSimpleXDS(cType), namespace)
+	} else {
+		res = x.cache.GetResources(resource.FromSimpleXDS(cType), namespace, nodeId)
+	}
 	if len(service) != 0 {
 		copyData := make(map[string]types.Resource, len(res))
 		hasSvc := len(service) != 0
@@ -73,3 +81,35 @@ func (x *XDSServer) listXDSResource(resp http.ResponseWriter, req *http.Request)
 	resp.WriteHeader(http.StatusOK)
 	_, _ = resp.Write([]byte(ret))
 }
+
+func (x *XDSServer) listXDSSyncStatus(resp http.ResponseWriter, req *http.Request) {
+	namespace := req.URL.Query().Get("namespace")
+	nodeId := req.URL.Query().Get("nodeId")
+	status := strings.ToUpper(req.URL.Query().Get("status"))
+
+	data := map[string]interface{}{
+		"code": apimodel.Code_ExecuteSuccess,
+		"info": "execute success",
+		"data": x.syncTracker.ListSyncStatus(namespace, nodeId, xdscache.SyncStatus(status)),
+	}
+
+	ret := utils.MustJson(data)
+	resp.WriteHeader(http.StatusOK)
+	_, _ = resp.Write([]byte(ret))
+}
+
+func (x *XDSServer) listCARoots(resp http.ResponseWriter, req *http.Request) {
+	var roots []*ca.RootView
+	if x.authority != nil {
+		roots = x.authority.Roots()
+	}
+	data := map[string]interface{}{
+		"code": apimodel.Code_ExecuteSuccess,
+		"info": "execute success",
+		"data": roots,
+	}
+
+	ret := utils.MustJson(data)
+	resp.WriteHeader(http.StatusOK)
+	_, _ = resp.Write([]byte(ret))
+}
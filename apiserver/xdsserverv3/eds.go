@@ -25,6 +25,7 @@ import (
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
@@ -42,6 +43,9 @@ func (eds *EDSBuilder) Init(svr service.DiscoverServer) {
 
 func (eds *EDSBuilder) Generate(option *resource.BuildOption) (interface{}, error) {
 	var resources []types.Resource
+	if option.RunType == resource.RunTypeProxylessGRPC {
+		return eds.makeProxylessEndpoints(option), nil
+	}
 	// sidecar 场景，如果流量方向是 envoy -> 业务 POD，那么 endpoint 只能是 本地 127.0.0.1
 	switch option.TrafficDirection {
 	case core.TrafficDirection_INBOUND:
@@ -74,6 +78,38 @@ func (eds *EDSBuilder) makeBoundEndpoints(option *resource.BuildOption,
 	return clusterLoads
 }
 
+// makeProxylessEndpoints 和 CDS 一一对应, subset cluster 只包含匹配标签的实例
+func (eds *EDSBuilder) makeProxylessEndpoints(option *resource.BuildOption) []types.Resource {
+	var clusterLoads []types.Resource
+	for svcKey, serviceInfo := range option.Services {
+		subsets := append([]*resource.ProxylessSubset{{}}, resource.ListProxylessSubsets(serviceInfo)...)
+		for _, subset := range subsets {
+			cla := &endpoint.ClusterLoadAssignment{
+				ClusterName: resource.MakeProxylessClusterName(svcKey, subset.Name),
+			}
+			if !option.ForceDelete {
+				instances := make([]*apiservice.Instance, 0, len(serviceInfo.Instances))
+				for _, ins := range serviceInfo.Instances {
+					if subset.Match(ins) {
+						instances = append(instances, ins)
+					}
+				}
+				cla.Endpoints = eds.buildServiceEndpoint(&resource.ServiceInfo{Instances: instances})
+				// grpc 会忽略没有设置权重的 locality
+				for _, localityEndpoints := range cla.Endpoints {
+					var weight uint32
+					for _, ep := range localityEndpoints.LbEndpoints {
+						weight += ep.GetLoadBalancingWeight().GetValue()
+					}
+					localityEndpoints.LoadBalancingWeight = utils.NewUInt32Value(weight)
+				}
+			}
+			clusterLoads = append(clusterLoads, cla)
+		}
+	}
+	return clusterLoads
+}
+
 func (eds *EDSBuilder) buildServiceEndpoint(serviceInfo *resource.ServiceInfo) []*endpoint.LocalityLbEndpoints {
 	locality := map[string]map[string]map[string][]*endpoint.LbEndpoint{}
 	for _, instance := range serviceInfo.Instances {
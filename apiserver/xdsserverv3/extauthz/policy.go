@@ -0,0 +1,230 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package extauthz
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/common/model"
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	"github.com/polarismesh/polaris/store"
+)
+
+// accessPolicy 预先编译好接口条件的服务访问策略
+type accessPolicy struct {
+	*authcommon.ServiceAccessPolicy
+	conditions []*accessCondition
+}
+
+type accessCondition struct {
+	methods  map[string]struct{}
+	pathType string
+	path     string
+	regex    *regexp.Regexp
+}
+
+func newAccessPolicy(policy *authcommon.ServiceAccessPolicy) (*accessPolicy, error) {
+	ret := &accessPolicy{
+		ServiceAccessPolicy: policy,
+		conditions:          make([]*accessCondition, 0, len(policy.Conditions)),
+	}
+	for _, item := range policy.Conditions {
+		condition := &accessCondition{
+			methods:  make(map[string]struct{}, len(item.Methods)),
+			pathType: item.PathType,
+			path:     item.Path,
+		}
+		for _, method := range item.Methods {
+			condition.methods[strings.ToUpper(method)] = struct{}{}
+		}
+		if item.Path != "" && item.PathType == authcommon.PathMatchRegex {
+			regex, err := regexp.Compile(item.Path)
+			if err != nil {
+				return nil, err
+			}
+			condition.regex = regex
+		}
+		ret.conditions = append(ret.conditions, condition)
+	}
+	return ret, nil
+}
+
+func (p *accessPolicy) destination() model.ServiceKey {
+	return model.ServiceKey{Namespace: p.Namespace, Name: p.Service}
+}
+
+// match 来源服务以及接口条件同时满足时命中策略
+func (p *accessPolicy) match(caller *model.ServiceKey, method, path string) bool {
+	if len(p.Sources) != 0 && !p.matchSource(caller) {
+		return false
+	}
+	if len(p.conditions) == 0 {
+		return true
+	}
+	for _, condition := range p.conditions {
+		if condition.match(method, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSource 无法识别身份的调用方可能是任意来源，限制来源的 DENY 策略按照命中处理，
+// 避免不带身份的请求绕过拒绝策略；限制来源的 ALLOW 策略则不会命中
+func (p *accessPolicy) matchSource(caller *model.ServiceKey) bool {
+	if caller == nil {
+		return p.IsDeny()
+	}
+	for _, source := range p.Sources {
+		if source.Match(caller.Namespace, caller.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *accessCondition) match(method, path string) bool {
+	if len(c.methods) != 0 {
+		if _, ok := c.methods[strings.ToUpper(method)]; !ok {
+			return false
+		}
+	}
+	if c.path == "" {
+		return true
+	}
+	switch c.pathType {
+	case authcommon.PathMatchPrefix:
+		return strings.HasPrefix(path, c.path)
+	case authcommon.PathMatchRegex:
+		return c.regex.MatchString(path)
+	default:
+		return path == c.path
+	}
+}
+
+// policyStore 按照目标服务维护服务访问策略，定时从存储增量拉取
+type policyStore struct {
+	storage store.ServiceAccessPolicyStore
+
+	lock        sync.RWMutex
+	policies    map[string]*accessPolicy
+	services    map[model.ServiceKey][]*accessPolicy
+	lastMtime   time.Time
+	firstUpdate bool
+}
+
+func newPolicyStore(storage store.ServiceAccessPolicyStore) *policyStore {
+	return &policyStore{
+		storage:     storage,
+		policies:    map[string]*accessPolicy{},
+		services:    map[model.ServiceKey][]*accessPolicy{},
+		firstUpdate: true,
+	}
+}
+
+// refresh 拉取增量的服务访问策略，返回策略发生变化的目标服务
+func (ps *policyStore) refresh() (map[model.ServiceKey]struct{}, error) {
+	ps.lock.RLock()
+	lastMtime, firstUpdate := ps.lastMtime, ps.firstUpdate
+	ps.lock.RUnlock()
+
+	policies, err := ps.storage.GetMoreServiceAccessPolicies(lastMtime, firstUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	ps.firstUpdate = false
+	changed := map[model.ServiceKey]struct{}{}
+	for _, item := range policies {
+		if item.ModifyTime.After(ps.lastMtime) {
+			ps.lastMtime = item.ModifyTime
+		}
+		old, exist := ps.policies[item.ID]
+		// 存储按照秒级精度的修改时间增量查询，同一条数据可能会被重复拉取
+		if exist && item.Valid && old.Revision == item.Revision {
+			continue
+		}
+		if !exist && !item.Valid {
+			continue
+		}
+		if exist {
+			changed[old.destination()] = struct{}{}
+			delete(ps.policies, item.ID)
+		}
+		if !item.Valid {
+			continue
+		}
+		policy, err := newAccessPolicy(item)
+		if err != nil {
+			log.Error("[XDS][ExtAuthz] invalid service access policy", zap.String("id", item.ID), zap.Error(err))
+			continue
+		}
+		ps.policies[item.ID] = policy
+		changed[policy.destination()] = struct{}{}
+	}
+	if len(changed) == 0 {
+		return changed, nil
+	}
+
+	services := make(map[model.ServiceKey][]*accessPolicy, len(ps.services))
+	for _, policy := range ps.policies {
+		key := policy.destination()
+		services[key] = append(services[key], policy)
+	}
+	ps.services = services
+	return changed, nil
+}
+
+func (ps *policyStore) get(svcKey model.ServiceKey) []*accessPolicy {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+	return ps.services[svcKey]
+}
+
+func (ps *policyStore) has(svcKey model.ServiceKey) bool {
+	return len(ps.get(svcKey)) != 0
+}
+
+// decide DENY 策略优先；目标服务存在 ALLOW 策略时，只有命中 ALLOW 策略的请求才会被放行
+func decide(policies []*accessPolicy, caller *model.ServiceKey, method, path string) (bool, *accessPolicy) {
+	hasAllow := false
+	var allowed *accessPolicy
+	for _, policy := range policies {
+		if policy.IsDeny() {
+			if policy.match(caller, method, path) {
+				return false, policy
+			}
+			continue
+		}
+		hasAllow = true
+		if allowed == nil && policy.match(caller, method, path) {
+			allowed = policy
+		}
+	}
+	if !hasAllow || allowed != nil {
+		return true, allowed
+	}
+	return false, nil
+}
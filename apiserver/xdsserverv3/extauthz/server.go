@@ -0,0 +1,234 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package extauthz
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/mitchellh/mapstructure"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/ca"
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	// DefaultRefreshInterval 默认拉取服务访问策略的间隔
+	DefaultRefreshInterval = 5 * time.Second
+)
+
+// Config ext_authz 服务配置
+type Config struct {
+	// CallerHeader 调用方没有 mTLS 证书时用于识别其身份的请求头，值为 <namespace>/<service>，默认为空表示只信任 mTLS 身份，
+	// 配置后调用方 sidecar 的 OUTBOUND 流量会用其自身的服务身份覆盖该请求头
+	CallerHeader string `mapstructure:"callerHeader"`
+	// RefreshInterval 从存储拉取服务访问策略的间隔
+	RefreshInterval time.Duration `mapstructure:"refreshInterval"`
+}
+
+// ParseConfig 解析 xds-v3 option 中的 extAuthz 配置
+func ParseConfig(raw interface{}) (*Config, error) {
+	cfg := &Config{
+		RefreshInterval: DefaultRefreshInterval,
+	}
+	if raw == nil {
+		return cfg, nil
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     cfg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(raw); err != nil {
+		return nil, err
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = DefaultRefreshInterval
+	}
+	cfg.CallerHeader = strings.ToLower(cfg.CallerHeader)
+	return cfg, nil
+}
+
+// Server 实现 envoy.service.auth.v3.Authorization，根据服务访问策略判断调用方能否访问目标服务
+type Server struct {
+	authv3.UnimplementedAuthorizationServer
+
+	cfg      *Config
+	policies *policyStore
+}
+
+// NewServer 创建 ext_authz 服务
+func NewServer(cfg *Config, storage store.ServiceAccessPolicyStore) *Server {
+	return &Server{
+		cfg:      cfg,
+		policies: newPolicyStore(storage),
+	}
+}
+
+// HasPolicies 目标服务是否存在服务访问策略
+func (s *Server) HasPolicies(svcKey model.ServiceKey) bool {
+	return s.policies.has(svcKey)
+}
+
+// Refresh 从存储增量拉取服务访问策略，返回策略发生变化的目标服务
+func (s *Server) Refresh() (map[model.ServiceKey]struct{}, error) {
+	return s.policies.refresh()
+}
+
+// Run 定时刷新服务访问策略，目标服务的策略发生变化时通过 onChange 通知重新构建 LDS
+func (s *Server) Run(ctx context.Context, onChange func(changed map[model.ServiceKey]struct{})) {
+	ticker := time.NewTicker(s.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			changed, err := s.Refresh()
+			if err != nil {
+				log.Error("[XDS][ExtAuthz] refresh service access policies", zap.Error(err))
+				continue
+			}
+			if len(changed) != 0 {
+				onChange(changed)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check 目标服务来自 Envoy 在 gRPC metadata 中携带的信息，调用方身份优先使用 mTLS 证书中的 SpiffeID
+func (s *Server) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	destination, ok := destinationFromContext(ctx)
+	if !ok {
+		return deniedResponse(codes.InvalidArgument, "missing destination service"), nil
+	}
+	policies := s.policies.get(destination)
+	if len(policies) == 0 {
+		return okResponse(), nil
+	}
+
+	httpReq := req.GetAttributes().GetRequest().GetHttp()
+	caller := s.resolveCaller(req.GetAttributes())
+	path := normalizePath(httpReq.GetPath())
+	allowed, policy := decide(policies, caller, httpReq.GetMethod(), path)
+	if allowed {
+		return okResponse(), nil
+	}
+	if log.DebugEnabled() {
+		fields := []zap.Field{zap.String("namespace", destination.Namespace),
+			zap.String("service", destination.Name), zap.String("method", httpReq.GetMethod()),
+			zap.String("path", path)}
+		if caller != nil {
+			fields = append(fields, zap.String("caller-namespace", caller.Namespace),
+				zap.String("caller-service", caller.Name))
+		}
+		if policy != nil {
+			fields = append(fields, zap.String("policy", policy.ID))
+		}
+		log.Debug("[XDS][ExtAuthz] request denied", fields...)
+	}
+	return deniedResponse(codes.PermissionDenied, "access denied by polaris service access policy"), nil
+}
+
+// normalizePath 和 Envoy 的 normalize_path、merge_slashes 保持一致，避免 //admin、/x/../admin、%2Fadmin
+// 这类路径绕过前缀以及正则条件
+func normalizePath(raw string) string {
+	p := raw
+	if i := strings.IndexAny(p, "?#"); i >= 0 {
+		p = p[:i]
+	}
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		p = unescaped
+	}
+	p = strings.ReplaceAll(p, "\\", "/")
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	trailingSlash := strings.HasSuffix(p, "/") || strings.HasSuffix(p, "/.") || strings.HasSuffix(p, "/..")
+	p = path.Clean(p)
+	if trailingSlash && p != "/" {
+		p += "/"
+	}
+	return p
+}
+
+// resolveCaller mTLS 证书身份优先，没有证书时才使用请求头中声明的身份
+func (s *Server) resolveCaller(attrs *authv3.AttributeContext) *model.ServiceKey {
+	if namespace, service, ok := ca.ParseSpiffeID(attrs.GetSource().GetPrincipal()); ok {
+		return &model.ServiceKey{Namespace: namespace, Name: service}
+	}
+	if s.cfg.CallerHeader == "" {
+		return nil
+	}
+	value := attrs.GetRequest().GetHttp().GetHeaders()[s.cfg.CallerHeader]
+	namespace, service, ok := strings.Cut(value, "/")
+	if !ok || namespace == "" || service == "" {
+		return nil
+	}
+	return &model.ServiceKey{Namespace: namespace, Name: service}
+}
+
+func destinationFromContext(ctx context.Context) (model.ServiceKey, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return model.ServiceKey{}, false
+	}
+	namespaces, services := md.Get(resource.ExtAuthzNamespaceKey), md.Get(resource.ExtAuthzServiceKey)
+	if len(namespaces) == 0 || len(services) == 0 || namespaces[0] == "" || services[0] == "" {
+		return model.ServiceKey{}, false
+	}
+	return model.ServiceKey{Namespace: namespaces[0], Name: services[0]}, true
+}
+
+func okResponse() *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: &status.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{
+			OkResponse: &authv3.OkHttpResponse{},
+		},
+	}
+}
+
+func deniedResponse(code codes.Code, message string) *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: &status.Status{Code: int32(code), Message: message},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{
+			DeniedResponse: &authv3.DeniedHttpResponse{
+				Status: &typev3.HttpStatus{Code: typev3.StatusCode_Forbidden},
+				Headers: []*corev3.HeaderValueOption{
+					{Header: &corev3.HeaderValue{Key: "content-type", Value: "text/plain"}},
+				},
+				Body: message,
+			},
+		},
+	}
+}
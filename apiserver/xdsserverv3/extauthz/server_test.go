@@ -0,0 +1,335 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package extauthz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
+	"github.com/polarismesh/polaris/common/model"
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+)
+
+// fakeAccessStore 按照修改时间返回增量数据
+type fakeAccessStore struct {
+	policies []*authcommon.ServiceAccessPolicy
+}
+
+func (f *fakeAccessStore) AddServiceAccessPolicy(*authcommon.ServiceAccessPolicy) error { return nil }
+func (f *fakeAccessStore) UpdateServiceAccessPolicy(*authcommon.ServiceAccessPolicy) error {
+	return nil
+}
+func (f *fakeAccessStore) DeleteServiceAccessPolicy(string) error { return nil }
+
+func (f *fakeAccessStore) GetServiceAccessPolicy(string) (*authcommon.ServiceAccessPolicy, error) {
+	return nil, nil
+}
+
+func (f *fakeAccessStore) GetMoreServiceAccessPolicies(mtime time.Time,
+	firstUpdate bool) ([]*authcommon.ServiceAccessPolicy, error) {
+	ret := make([]*authcommon.ServiceAccessPolicy, 0, len(f.policies))
+	for _, policy := range f.policies {
+		if firstUpdate && !policy.Valid {
+			continue
+		}
+		if !firstUpdate && policy.ModifyTime.Before(mtime) {
+			continue
+		}
+		ret = append(ret, policy)
+	}
+	return ret, nil
+}
+
+const testCallerHeader = "x-polaris-caller"
+
+func newCheckRequest(principal, method, path string, headers map[string]string) *authv3.CheckRequest {
+	return &authv3.CheckRequest{
+		Attributes: &authv3.AttributeContext{
+			Source: &authv3.AttributeContext_Peer{Principal: principal},
+			Request: &authv3.AttributeContext_Request{
+				Http: &authv3.AttributeContext_HttpRequest{
+					Method:  method,
+					Path:    path,
+					Headers: headers,
+				},
+			},
+		},
+	}
+}
+
+func destinationContext(namespace, service string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		resource.ExtAuthzNamespaceKey, namespace, resource.ExtAuthzServiceKey, service))
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Now()
+	accessStore := &fakeAccessStore{
+		policies: []*authcommon.ServiceAccessPolicy{
+			{
+				ID: "allow-order", Namespace: "default", Service: "payment", Action: "ALLOW",
+				Sources: []authcommon.ServiceAccessSource{{Namespace: "default", Service: "order"}},
+				Conditions: []authcommon.ServiceAccessCondition{
+					{Methods: []string{"GET", "POST"}, Path: "/pay", PathType: authcommon.PathMatchPrefix},
+				},
+				Revision: "1", Valid: true, ModifyTime: now,
+			},
+			{
+				ID: "deny-refund", Namespace: "default", Service: "payment", Action: "DENY",
+				Sources: []authcommon.ServiceAccessSource{{Namespace: "*", Service: "*"}},
+				Conditions: []authcommon.ServiceAccessCondition{
+					{Path: "^/pay/[0-9]+/refund$", PathType: authcommon.PathMatchRegex},
+				},
+				Revision: "1", Valid: true, ModifyTime: now,
+			},
+		},
+	}
+	cfg, err := ParseConfig(map[interface{}]interface{}{"callerHeader": testCallerHeader})
+	assert.NoError(t, err)
+	svr := NewServer(cfg, accessStore)
+	changed, err := svr.Refresh()
+	assert.NoError(t, err)
+	payment := model.ServiceKey{Namespace: "default", Name: "payment"}
+	assert.Equal(t, map[model.ServiceKey]struct{}{payment: {}}, changed)
+	assert.True(t, svr.HasPolicies(payment))
+	assert.False(t, svr.HasPolicies(model.ServiceKey{Namespace: "default", Name: "order"}))
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		req     *authv3.CheckRequest
+		allowed bool
+	}{
+		{
+			name:    "mtls caller allowed",
+			ctx:     destinationContext("default", "payment"),
+			req:     newCheckRequest("spiffe://default/order", "POST", "/pay/1?from=app", nil),
+			allowed: true,
+		},
+		{
+			name:    "header caller allowed",
+			ctx:     destinationContext("default", "payment"),
+			req:     newCheckRequest("", "GET", "/pay", map[string]string{testCallerHeader: "default/order"}),
+			allowed: true,
+		},
+		{
+			name: "mtls identity takes precedence over header",
+			ctx:  destinationContext("default", "payment"),
+			req: newCheckRequest("spiffe://default/user", "GET", "/pay",
+				map[string]string{testCallerHeader: "default/order"}),
+			allowed: false,
+		},
+		{
+			name:    "method not allowed",
+			ctx:     destinationContext("default", "payment"),
+			req:     newCheckRequest("spiffe://default/order", "DELETE", "/pay/1", nil),
+			allowed: false,
+		},
+		{
+			name:    "deny policy first",
+			ctx:     destinationContext("default", "payment"),
+			req:     newCheckRequest("spiffe://default/order", "POST", "/pay/1/refund", nil),
+			allowed: false,
+		},
+		{
+			name:    "unknown caller",
+			ctx:     destinationContext("default", "payment"),
+			req:     newCheckRequest("", "GET", "/pay", nil),
+			allowed: false,
+		},
+		{
+			name:    "service without policies",
+			ctx:     destinationContext("default", "order"),
+			req:     newCheckRequest("", "GET", "/", nil),
+			allowed: true,
+		},
+		{
+			name:    "missing destination",
+			ctx:     context.Background(),
+			req:     newCheckRequest("spiffe://default/order", "GET", "/pay", nil),
+			allowed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rsp, err := svr.Check(tt.ctx, tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.allowed, rsp.GetStatus().GetCode() == int32(codes.OK))
+			if !tt.allowed {
+				assert.NotNil(t, rsp.GetDeniedResponse())
+			}
+		})
+	}
+	// 默认不配置调用方请求头，只信任 mTLS 身份
+	cfg, err = ParseConfig(nil)
+	assert.NoError(t, err)
+	svr = NewServer(cfg, accessStore)
+	_, err = svr.Refresh()
+	assert.NoError(t, err)
+	rsp, err := svr.Check(destinationContext("default", "payment"),
+		newCheckRequest("", "GET", "/pay", map[string]string{testCallerHeader: "default/order"}))
+	assert.NoError(t, err)
+	assert.NotEqual(t, int32(codes.OK), rsp.GetStatus().GetCode())
+}
+
+func TestCheck_PathNormalization(t *testing.T) {
+	now := time.Now()
+	accessStore := &fakeAccessStore{
+		policies: []*authcommon.ServiceAccessPolicy{
+			{
+				ID: "allow-all", Namespace: "default", Service: "payment", Action: "ALLOW",
+				Sources:  []authcommon.ServiceAccessSource{{Namespace: "*", Service: "*"}},
+				Revision: "1", Valid: true, ModifyTime: now,
+			},
+			{
+				ID: "deny-admin", Namespace: "default", Service: "payment", Action: "DENY",
+				Sources: []authcommon.ServiceAccessSource{{Namespace: "*", Service: "*"}},
+				Conditions: []authcommon.ServiceAccessCondition{
+					{Path: "/admin", PathType: authcommon.PathMatchPrefix},
+					{Path: "^/internal/.*$", PathType: authcommon.PathMatchRegex},
+				},
+				Revision: "1", Valid: true, ModifyTime: now,
+			},
+		},
+	}
+	cfg, err := ParseConfig(nil)
+	assert.NoError(t, err)
+	svr := NewServer(cfg, accessStore)
+	_, err = svr.Refresh()
+	assert.NoError(t, err)
+
+	check := func(path string) bool {
+		rsp, err := svr.Check(destinationContext("default", "payment"),
+			newCheckRequest("spiffe://default/order", "GET", path, nil))
+		assert.NoError(t, err)
+		return rsp.GetStatus().GetCode() == int32(codes.OK)
+	}
+	assert.True(t, check("/pay"))
+	assert.True(t, check("/pay/admin"))
+	for _, path := range []string{
+		"/admin", "//admin", "/x/../admin", "/./admin", "%2Fadmin", "/%2Fadmin", "/%2fadmin/users",
+		"/x%2F..%2Fadmin", "\\admin", "/admin/?debug=1", "//internal/users", "/x/../internal/users",
+		"/%2Finternal/users",
+	} {
+		assert.False(t, check(path), path)
+	}
+}
+
+func TestCheck_UnidentifiedCaller(t *testing.T) {
+	now := time.Now()
+	accessStore := &fakeAccessStore{
+		policies: []*authcommon.ServiceAccessPolicy{
+			{
+				ID: "deny-order-admin", Namespace: "default", Service: "payment", Action: "DENY",
+				Sources: []authcommon.ServiceAccessSource{{Namespace: "default", Service: "order"}},
+				Conditions: []authcommon.ServiceAccessCondition{
+					{Path: "/admin", PathType: authcommon.PathMatchPrefix},
+				},
+				Revision: "1", Valid: true, ModifyTime: now,
+			},
+		},
+	}
+	cfg, err := ParseConfig(nil)
+	assert.NoError(t, err)
+	svr := NewServer(cfg, accessStore)
+	_, err = svr.Refresh()
+	assert.NoError(t, err)
+
+	check := func(principal, path string) bool {
+		rsp, err := svr.Check(destinationContext("default", "payment"),
+			newCheckRequest(principal, "GET", path, nil))
+		assert.NoError(t, err)
+		return rsp.GetStatus().GetCode() == int32(codes.OK)
+	}
+	assert.False(t, check("spiffe://default/order", "/admin"))
+	assert.True(t, check("spiffe://default/user", "/admin"))
+	// 无法识别身份的调用方可能就是被拒绝的来源
+	assert.False(t, check("", "/admin"))
+	assert.True(t, check("", "/pay"))
+}
+
+func TestNormalizePath(t *testing.T) {
+	for raw, expect := range map[string]string{
+		"":                 "/",
+		"/":                "/",
+		"/pay?from=app":    "/pay",
+		"//pay//1/":        "/pay/1/",
+		"/pay/1/..":        "/pay/",
+		"/pay/./1#x":       "/pay/1",
+		"%2Fpay%2F1":       "/pay/1",
+		"/../../pay":       "/pay",
+		"/pay/%zz/../1":    "/pay/1",
+		"/pay%20order/%41": "/pay order/A",
+	} {
+		assert.Equal(t, expect, normalizePath(raw), raw)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	now := time.Now()
+	policy := &authcommon.ServiceAccessPolicy{
+		ID: "p1", Namespace: "default", Service: "payment", Action: "DENY",
+		Revision: "1", Valid: true, ModifyTime: now,
+	}
+	accessStore := &fakeAccessStore{policies: []*authcommon.ServiceAccessPolicy{policy}}
+	svr := NewServer(&Config{RefreshInterval: time.Second}, accessStore)
+	payment := model.ServiceKey{Namespace: "default", Name: "payment"}
+
+	changed, err := svr.Refresh()
+	assert.NoError(t, err)
+	assert.Len(t, changed, 1)
+
+	// 相同版本的数据被重复拉取时不认为发生了变化
+	changed, err = svr.Refresh()
+	assert.NoError(t, err)
+	assert.Len(t, changed, 0)
+
+	// 没有 ALLOW 策略时，未命中 DENY 策略的请求都放行
+	rsp, err := svr.Check(destinationContext("default", "payment"), newCheckRequest("", "GET", "/", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(codes.PermissionDenied), rsp.GetStatus().GetCode())
+
+	policy.Valid = false
+	policy.ModifyTime = now.Add(time.Second)
+	changed, err = svr.Refresh()
+	assert.NoError(t, err)
+	assert.Equal(t, map[model.ServiceKey]struct{}{payment: {}}, changed)
+	assert.False(t, svr.HasPolicies(payment))
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(map[interface{}]interface{}{
+		"callerHeader":    "X-Caller",
+		"refreshInterval": "10s",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "x-caller", cfg.CallerHeader)
+	assert.Equal(t, 10*time.Second, cfg.RefreshInterval)
+
+	cfg, err = ParseConfig(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", cfg.CallerHeader)
+	assert.Equal(t, DefaultRefreshInterval, cfg.RefreshInterval)
+}
@@ -45,6 +45,8 @@ var (
 type (
 	ServiceInfos               map[string]map[model.ServiceKey]*resource.ServiceInfo
 	CurrentServiceInfoProvider func() ServiceInfos
+	// AccessPolicyProvider 判断服务是否存在服务访问策略
+	AccessPolicyProvider func(svcKey model.ServiceKey) bool
 )
 
 // XdsResourceGenerator is the xDS resource generator
@@ -54,6 +56,14 @@ type XdsResourceGenerator struct {
 	versionNum      *atomic.Uint64
 	xdsNodesMgr     *resource.XDSNodeManager
 	svcInfoProvider CurrentServiceInfoProvider
+	// accessPolicyProvider 未开启 ext_authz 时为空
+	accessPolicyProvider AccessPolicyProvider
+	// callerHeader ext_authz 识别调用方身份的请求头，为空表示只信任 mTLS 身份
+	callerHeader string
+	// builtinCA 是否开启了内置 CA
+	builtinCA bool
+	// rateLimitServing RLS 是否对外提供全局限流，为空表示不提供
+	rateLimitServing func() bool
 }
 
 // Generate 构建 XDS 资源缓存数据信息
@@ -93,6 +103,18 @@ func (x *XdsResourceGenerator) Generate(versionLocal string, needUpdate, needRem
 				Services:         services,
 				TrafficDirection: direction,
 			}
+			if runType == resource.RunTypeProxylessGRPC {
+				// 只为有 proxyless grpc 节点连接且已经构建过资源的命名空间增量更新，首次构建由节点连接时触发
+				if !x.cache.HasRunTypeNamespace(runType, namespace) {
+					continue
+				}
+				if !x.xdsNodesMgr.HasProxylessNodes(namespace) {
+					x.cache.CleanRunTypeNamespace(runType, namespace)
+					continue
+				}
+				x.buildProxylessRequest(updateRequest, opt, isRemove)
+				continue
+			}
 			// sidecar 和 gateway 大部份资源都是复用的，所以这里只需要构建一次即可，gateway 只有 RDS/LDS 存在特别，单独针对构建即可
 			if runType == resource.RunTypeSidecar {
 				generate(opt)
@@ -111,7 +133,7 @@ func (x *XdsResourceGenerator) Generate(versionLocal string, needUpdate, needRem
 	}
 
 	wg := &sync.WaitGroup{}
-	wg.Add(2)
+	wg.Add(3)
 	go func() {
 		defer wg.Done()
 		// 处理 Sideacr
@@ -126,14 +148,70 @@ func (x *XdsResourceGenerator) Generate(versionLocal string, needUpdate, needRem
 		deltaOp(resource.RunTypeGateway, needRemove, true)
 	}()
 
+	go func() {
+		defer wg.Done()
+		// 处理 Proxyless gRPC
+		deltaOp(resource.RunTypeProxylessGRPC, needUpdate, false)
+		deltaOp(resource.RunTypeProxylessGRPC, needRemove, true)
+	}()
+
 	wg.Wait()
 
 	if err := x.cache.UpdateResources(context.Background(), updateRequest); err != nil {
 		log.Error("[XDS][Envoy] update xds resource fail", zap.Error(err))
 	}
+	x.refreshEnvoyNodesLDS(needUpdate)
+}
+
+// refreshEnvoyNodesLDS 服务发生变化时重新构建对应 Envoy Node 的 LDS, 例如新增了全局限流规则需要开启 RLS 过滤器
+func (x *XdsResourceGenerator) refreshEnvoyNodesLDS(needUpdate ServiceInfos) {
+	x.refreshEnvoyNodesLDSBy(func(svcKey model.ServiceKey) bool {
+		_, ok := needUpdate[svcKey.Namespace][svcKey]
+		return ok
+	})
+}
+
+// refreshEnvoyNodesLDSBy 重新构建所属服务满足 match 的 Envoy Node 的 LDS
+func (x *XdsResourceGenerator) refreshEnvoyNodesLDSBy(match func(svcKey model.ServiceKey) bool) {
+	for _, node := range x.xdsNodesMgr.ListEnvoyNodes() {
+		if !match(node.GetSelfServiceKey()) {
+			continue
+		}
+		if err := x.buildOneEnvoyXDSCache(node); err != nil {
+			log.Error("[XDS][Envoy] refresh envoy node lds fail", zap.String("node", node.GetNodeID()),
+				zap.Error(err))
+		}
+	}
+}
+
+// buildProxylessRequest proxyless grpc 的资源单独构建，LDS 也按照命名空间维度下发，不涉及 TLS 以及按需加载
+func (x *XdsResourceGenerator) buildProxylessRequest(req *cache.UpdateResourcesRequest,
+	opt *resource.BuildOption, isRemove bool) {
+	for _, xdsType := range []resource.XDSType{resource.LDS, resource.RDS, resource.CDS, resource.EDS} {
+		x.buildUpdateRequest(req, xdsType, opt, isRemove)
+	}
+}
+
+// buildProxylessNamespace 命名空间下第一个 proxyless grpc 节点连接时，全量构建该命名空间的资源
+func (x *XdsResourceGenerator) buildProxylessNamespace(node *resource.XDSClient) error {
+	namespace := node.GetSelfNamespace()
+	if x.cache.HasRunTypeNamespace(node.RunType, namespace) {
+		return nil
+	}
+	req := cache.NewUpdateResourcesRequest()
+	x.buildProxylessRequest(req, &resource.BuildOption{
+		RunType:   node.RunType,
+		Namespace: namespace,
+		Services:  x.svcInfoProvider()[namespace],
+	}, false)
+	return x.cache.UpdateResources(context.Background(), req)
 }
 
 func (x *XdsResourceGenerator) buildOneEnvoyXDSCache(node *resource.XDSClient) error {
+	// proxyless grpc 的资源按照命名空间构建，不存在节点维度的资源
+	if node.IsProxyless() {
+		return x.buildProxylessNamespace(node)
+	}
 	opt := &resource.BuildOption{
 		RunType:   node.RunType,
 		Client:    node,
@@ -147,6 +225,14 @@ func (x *XdsResourceGenerator) buildOneEnvoyXDSCache(node *resource.XDSClient) e
 	if node.OpenOnDemand {
 		opt.OpenEnvoyDemand()
 	}
+	if x.accessPolicyProvider != nil {
+		opt.ExtAuthz = x.accessPolicyProvider(opt.SelfService)
+	}
+	opt.CallerHeader = x.callerHeader
+	opt.BuiltinCA = x.builtinCA
+	if x.rateLimitServing != nil {
+		opt.RateLimitServing = x.rateLimitServing()
+	}
 
 	finalResources := make([]types.Resource, 0, 4)
 	buildCache := func(xdsType resource.XDSType, opt *resource.BuildOption) {
@@ -182,6 +268,15 @@ func (x *XdsResourceGenerator) buildUpdateRequest(req *cache.UpdateResourcesRequ
 		return
 	}
 
+	if opt.RunType == resource.RunTypeProxylessGRPC {
+		if opt.ForceDelete {
+			req.RemoveRunTypeNamespaces(opt.RunType, opt.Namespace, xdsType, xxds)
+		} else {
+			req.AddRunTypeNamespaces(opt.RunType, opt.Namespace, xdsType, xxds)
+		}
+		return
+	}
+
 	switch opt.TLSMode {
 	case resource.TLSModeNone:
 		if opt.ForceDelete {
@@ -22,6 +22,8 @@ import (
 
 	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/server/stream/v3"
+
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
 )
 
 // OnCreateWatch before call cachev3.SnapshotCache CreateWatch
@@ -33,6 +35,7 @@ func (x *XDSServer) OnCreateWatch(request *cachev3.Request, streamState stream.S
 	if client == nil {
 		return
 	}
+	x.ensureNodeSecrets(client)
 	_ = x.resourceGenerator.buildOneEnvoyXDSCache(client)
 }
 
@@ -44,6 +47,7 @@ func (x *XDSServer) OnCreateDeltaWatch(request *cachev3.DeltaRequest, state stre
 	if client == nil {
 		return
 	}
+	x.ensureNodeSecrets(client)
 	_ = x.resourceGenerator.buildOneEnvoyXDSCache(client)
 }
 
@@ -54,5 +58,14 @@ func (x *XDSServer) OnFetch(ctx context.Context, request *cachev3.Request) {
 	if client == nil {
 		return
 	}
+	x.ensureNodeSecrets(client)
 	_ = x.resourceGenerator.buildOneEnvoyXDSCache(client)
 }
+
+// ensureNodeSecrets 开启内置 CA 时，确保 Envoy Node 在请求 SDS 之前已经持有证书
+func (x *XDSServer) ensureNodeSecrets(client *resource.XDSClient) {
+	if x.secretMgr == nil {
+		return
+	}
+	x.secretMgr.ensureNode(client)
+}
@@ -105,16 +105,37 @@ func (lds *LDSBuilder) Generate(option *resource.BuildOption) (interface{}, erro
 			}
 			resources = append(resources, outBoundListener...)
 		}
+	case resource.RunTypeProxylessGRPC:
+		resources = lds.makeProxylessListeners(option)
 	}
 	return resources, nil
 }
 
+// makeProxylessListeners grpc 只支持 API listener, 每个服务域名对应一个 listener
+func (lds *LDSBuilder) makeProxylessListeners(option *resource.BuildOption) []types.Resource {
+	var listeners []types.Resource
+	for svcKey, serviceInfo := range option.Services {
+		apiListener := &listenerv3.ApiListener{
+			ApiListener: resource.MustNewAny(resource.MakeProxylessHCM(svcKey)),
+		}
+		for _, name := range resource.MakeProxylessListenerNames(serviceInfo) {
+			listeners = append(listeners, &listenerv3.Listener{
+				Name:        name,
+				ApiListener: apiListener,
+			})
+		}
+	}
+	return listeners
+}
+
 func (lds *LDSBuilder) makeListener(option *resource.BuildOption,
 	direction corev3.TrafficDirection) ([]types.Resource, error) {
 	isGateway := option.RunType == resource.RunTypeGateway
 
 	var boundHCM *hcm.HttpConnectionManager
 	selfService := option.SelfService
+	option.GlobalRateLimit = option.RateLimitServing &&
+		resource.HasGlobalRateLimit(lds.svr.Cache().RateLimit(), selfService)
 	if isGateway {
 		boundHCM = resource.MakeGatewayBoundHCM(selfService, option)
 	} else {
@@ -136,7 +157,7 @@ func (lds *LDSBuilder) makeListener(option *resource.BuildOption,
 					TransportProtocol: "tls",
 				},
 				TransportSocket: resource.MakeTLSTransportSocket(&tlstrans.DownstreamTlsContext{
-					CommonTlsContext: resource.InboundCommonTLSContext,
+					CommonTlsContext: resource.MakeInboundCommonTLSContext(option),
 					RequireClientCertificate: &wrappers.BoolValue{
 						Value: true,
 					},
@@ -0,0 +1,85 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package xdsserverv3
+
+import (
+	"testing"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/golang/mock/gomock"
+	apitraffic "github.com/polarismesh/specification/source/go/api/v1/traffic_manage"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
+	"github.com/polarismesh/polaris/cache/mock"
+	"github.com/polarismesh/polaris/common/model"
+)
+
+// listenerHTTPFilters 返回 listener 中全部 HCM 的 http 过滤器名称
+func listenerHTTPFilters(t *testing.T, listener *listenerv3.Listener) []string {
+	chains := append([]*listenerv3.FilterChain{listener.GetDefaultFilterChain()}, listener.GetFilterChains()...)
+	var names []string
+	for _, chain := range chains {
+		for _, filter := range chain.GetFilters() {
+			manager := &hcm.HttpConnectionManager{}
+			if err := filter.GetTypedConfig().UnmarshalTo(manager); err != nil {
+				continue
+			}
+			for _, httpFilter := range manager.GetHttpFilters() {
+				names = append(names, httpFilter.GetName())
+			}
+		}
+	}
+	assert.NotEmpty(t, names)
+	return names
+}
+
+func TestLDSBuilder_RateLimitServing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svcKey := model.ServiceKey{Namespace: "default", Name: "echo"}
+	rateLimitCache := mock.NewMockRateLimitCache(ctrl)
+	rateLimitCache.EXPECT().GetRateLimitRules(gomock.Any()).Return([]*model.RateLimit{
+		{ID: "ratelimit-1", Proto: &apitraffic.Rule{Type: apitraffic.Rule_GLOBAL}},
+	}, "").AnyTimes()
+	cacheMgr := mock.NewMockCacheManager(ctrl)
+	cacheMgr.EXPECT().RateLimit().Return(rateLimitCache).AnyTimes()
+	lds := &LDSBuilder{}
+	lds.Init(&fakeDiscoverServer{cacheMgr: cacheMgr})
+
+	filterNames := func(serving bool) []string {
+		opt := &resource.BuildOption{
+			RunType:          resource.RunTypeSidecar,
+			Namespace:        svcKey.Namespace,
+			SelfService:      svcKey,
+			TrafficDirection: core.TrafficDirection_INBOUND,
+			RateLimitServing: serving,
+		}
+		listeners, err := lds.makeListener(opt, core.TrafficDirection_INBOUND)
+		assert.NoError(t, err)
+		assert.Len(t, listeners, 1)
+		return listenerHTTPFilters(t, listeners[0].(*listenerv3.Listener))
+	}
+
+	// 存在全局限流规则，但是 RLS 无法统计到全部节点的请求时不开启 RLS 过滤器
+	assert.NotContains(t, filterNames(false), "envoy.filters.http.ratelimit")
+	assert.Contains(t, filterNames(true), "envoy.filters.http.ratelimit")
+}
@@ -0,0 +1,358 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package xdsserverv3
+
+import (
+	"testing"
+
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/mock/gomock"
+	apifault "github.com/polarismesh/specification/source/go/api/v1/fault_tolerance"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	apitraffic "github.com/polarismesh/specification/source/go/api/v1/traffic_manage"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	xdscache "github.com/polarismesh/polaris/apiserver/xdsserverv3/cache"
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
+	cachetypes "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/cache/mock"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/service"
+)
+
+func buildProxylessTestService(t *testing.T) *resource.ServiceInfo {
+	svcKey := model.ServiceKey{Namespace: "default", Name: "echo"}
+	ruleConf, err := anypb.New(&apitraffic.RuleRoutingConfig{
+		Rules: []*apitraffic.SubRuleRouting{
+			{
+				Sources: []*apitraffic.SourceService{
+					{
+						Service:   "*",
+						Namespace: "*",
+						Arguments: []*apitraffic.SourceMatch{
+							{
+								Type: apitraffic.SourceMatch_HEADER,
+								Key:  "env",
+								Value: &apimodel.MatchString{
+									Type:  apimodel.MatchString_EXACT,
+									Value: utils.NewStringValue("gray"),
+								},
+							},
+						},
+					},
+				},
+				Destinations: []*apitraffic.DestinationGroup{
+					{
+						Service:   svcKey.Name,
+						Namespace: svcKey.Namespace,
+						Weight:    80,
+						Labels: map[string]*apimodel.MatchString{
+							"version": {Value: utils.NewStringValue("v2")},
+						},
+					},
+					{
+						Service:   svcKey.Name,
+						Namespace: svcKey.Namespace,
+						Weight:    20,
+						Labels: map[string]*apimodel.MatchString{
+							"version": {Value: utils.NewStringValue("v1")},
+						},
+					},
+				},
+			},
+			{
+				Sources: []*apitraffic.SourceService{
+					{
+						Service:   "*",
+						Namespace: "*",
+						Arguments: []*apitraffic.SourceMatch{
+							{
+								Type: apitraffic.SourceMatch_QUERY,
+								Key:  "uid",
+								Value: &apimodel.MatchString{
+									Type:  apimodel.MatchString_EXACT,
+									Value: utils.NewStringValue("1"),
+								},
+							},
+						},
+					},
+				},
+				Destinations: []*apitraffic.DestinationGroup{
+					{
+						Service:   svcKey.Name,
+						Namespace: svcKey.Namespace,
+						Weight:    100,
+						Labels: map[string]*apimodel.MatchString{
+							"version": {Value: utils.NewStringValue("v3")},
+						},
+					},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	newInstance := func(host, version string) *apiservice.Instance {
+		return &apiservice.Instance{
+			Host:     utils.NewStringValue(host),
+			Port:     utils.NewUInt32Value(8080),
+			Weight:   utils.NewUInt32Value(100),
+			Healthy:  utils.NewBoolValue(true),
+			Metadata: map[string]string{"version": version},
+		}
+	}
+
+	return &resource.ServiceInfo{
+		Name:       svcKey.Name,
+		Namespace:  svcKey.Namespace,
+		ServiceKey: svcKey,
+		Metadata: map[string]string{
+			resource.ServiceLbPolicyTag: string(resource.LbPolicyRingHash),
+		},
+		Instances: []*apiservice.Instance{
+			newInstance("127.0.0.1", "v1"),
+			newInstance("127.0.0.2", "v2"),
+			newInstance("127.0.0.3", "v2"),
+		},
+		Routing: &apitraffic.Routing{
+			Rules: []*apitraffic.RouteRule{
+				{
+					Enable:        true,
+					RoutingPolicy: apitraffic.RoutingPolicy_RulePolicy,
+					RoutingConfig: ruleConf,
+				},
+			},
+		},
+		CircuitBreaker: &apifault.CircuitBreaker{
+			Rules: []*apifault.CircuitBreakerRule{
+				{
+					Enable: true,
+					Level:  apifault.Level_INSTANCE,
+					TriggerCondition: []*apifault.TriggerCondition{
+						{
+							ErrorCount:     5,
+							ErrorPercent:   50,
+							Interval:       10,
+							MinimumRequest: 20,
+						},
+					},
+					RecoverCondition: &apifault.RecoverCondition{SleepWindow: 30},
+				},
+			},
+		},
+	}
+}
+
+func indexProxylessResources(ret interface{}) map[string]types.Resource {
+	index := map[string]types.Resource{}
+	for _, item := range ret.([]types.Resource) {
+		switch res := item.(type) {
+		case *listenerv3.Listener:
+			index[res.GetName()] = res
+		case *route.RouteConfiguration:
+			index[res.GetName()] = res
+		case *cluster.Cluster:
+			index[res.GetName()] = res
+		case *endpoint.ClusterLoadAssignment:
+			index[res.GetClusterName()] = res
+		}
+	}
+	return index
+}
+
+func TestProxylessGRPCResources(t *testing.T) {
+	svc := buildProxylessTestService(t)
+	opt := &resource.BuildOption{
+		RunType:   resource.RunTypeProxylessGRPC,
+		Namespace: svc.Namespace,
+		Services: map[model.ServiceKey]*resource.ServiceInfo{
+			svc.ServiceKey: svc,
+		},
+	}
+	baseCluster := resource.MakeProxylessClusterName(svc.ServiceKey, "")
+	v1Cluster := resource.MakeProxylessClusterName(svc.ServiceKey, "version=v1")
+	v2Cluster := resource.MakeProxylessClusterName(svc.ServiceKey, "version=v2")
+
+	t.Run("lds", func(t *testing.T) {
+		ret, err := (&LDSBuilder{}).Generate(opt)
+		assert.NoError(t, err)
+		listeners := indexProxylessResources(ret)
+		assert.Len(t, listeners, len(resource.GenerateServiceDomains(svc)))
+
+		listener, ok := listeners["echo.default"].(*listenerv3.Listener)
+		assert.True(t, ok)
+		assert.Nil(t, listener.GetAddress())
+		manager := &hcm.HttpConnectionManager{}
+		assert.NoError(t, listener.GetApiListener().GetApiListener().UnmarshalTo(manager))
+		assert.Equal(t, resource.MakeProxylessRouteConfigName(svc.ServiceKey), manager.GetRds().GetRouteConfigName())
+		assert.Len(t, manager.GetHttpFilters(), 1)
+		assert.Equal(t, wellknown.Router, manager.GetHttpFilters()[0].GetName())
+	})
+
+	t.Run("rds", func(t *testing.T) {
+		builder := &RDSBuilder{}
+		ret, err := builder.Generate(opt)
+		assert.NoError(t, err)
+		routeConfs := indexProxylessResources(ret)
+		routeConf, ok := routeConfs[resource.MakeProxylessRouteConfigName(svc.ServiceKey)].(*route.RouteConfiguration)
+		assert.True(t, ok)
+		routes := routeConf.GetVirtualHosts()[0].GetRoutes()
+		// query 参数匹配的路由不下发, 最后追加默认路由
+		assert.Len(t, routes, 2)
+
+		grayRoute := routes[0]
+		assert.Len(t, grayRoute.GetMatch().GetHeaders(), 1)
+		assert.Empty(t, grayRoute.GetTypedPerFilterConfig())
+		clusters := grayRoute.GetRoute().GetWeightedClusters().GetClusters()
+		assert.Len(t, clusters, 2)
+		weights := map[string]uint32{}
+		for _, c := range clusters {
+			assert.Nil(t, c.GetMetadataMatch())
+			weights[c.GetName()] = c.GetWeight().GetValue()
+		}
+		assert.Equal(t, map[string]uint32{v2Cluster: 80, v1Cluster: 20}, weights)
+		assert.Len(t, grayRoute.GetRoute().GetHashPolicy(), 1)
+
+		assert.Equal(t, baseCluster, routes[1].GetRoute().GetCluster())
+	})
+
+	t.Run("cds", func(t *testing.T) {
+		ret, err := (&CDSBuilder{}).Generate(opt)
+		assert.NoError(t, err)
+		clusters := indexProxylessResources(ret)
+		_, hasPassthrough := clusters[resource.PassthroughClusterName]
+		assert.False(t, hasPassthrough)
+		assert.Len(t, clusters, 4)
+
+		c, ok := clusters[v2Cluster].(*cluster.Cluster)
+		assert.True(t, ok)
+		assert.Equal(t, cluster.Cluster_EDS, c.GetType())
+		assert.Equal(t, cluster.Cluster_RING_HASH, c.GetLbPolicy())
+		assert.Nil(t, c.GetLbSubsetConfig())
+		assert.Empty(t, c.GetTransportSocketMatches())
+		assert.Nil(t, c.GetOutlierDetection().GetConsecutive_5Xx())
+		assert.Equal(t, uint32(50), c.GetOutlierDetection().GetFailurePercentageThreshold().GetValue())
+		assert.Equal(t, uint32(100), c.GetOutlierDetection().GetEnforcingFailurePercentage().GetValue())
+		assert.Equal(t, uint32(0), c.GetOutlierDetection().GetEnforcingSuccessRate().GetValue())
+	})
+
+	t.Run("eds", func(t *testing.T) {
+		ret, err := (&EDSBuilder{}).Generate(opt)
+		assert.NoError(t, err)
+		loads := indexProxylessResources(ret)
+		assert.Len(t, loads, 4)
+
+		countEndpoints := func(name string) int {
+			cla, ok := loads[name].(*endpoint.ClusterLoadAssignment)
+			assert.True(t, ok)
+			var cnt int
+			for _, locality := range cla.GetEndpoints() {
+				assert.NotZero(t, locality.GetLoadBalancingWeight().GetValue())
+				cnt += len(locality.GetLbEndpoints())
+			}
+			return cnt
+		}
+		assert.Equal(t, 3, countEndpoints(baseCluster))
+		assert.Equal(t, 1, countEndpoints(v1Cluster))
+		assert.Equal(t, 2, countEndpoints(v2Cluster))
+		assert.Equal(t, 0, countEndpoints(resource.MakeProxylessClusterName(svc.ServiceKey, "version=v3")))
+	})
+}
+
+// fakeDiscoverServer 只提供构建 sidecar 资源时需要的缓存
+type fakeDiscoverServer struct {
+	service.DiscoverServer
+	cacheMgr cachetypes.CacheManager
+}
+
+func (s *fakeDiscoverServer) Cache() cachetypes.CacheManager {
+	return s.cacheMgr
+}
+
+func TestProxylessResourcesIsolatedFromSidecar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rateLimitCache := mock.NewMockRateLimitCache(ctrl)
+	rateLimitCache.EXPECT().GetRateLimitRules(gomock.Any()).Return(nil, "").AnyTimes()
+	cacheMgr := mock.NewMockCacheManager(ctrl)
+	cacheMgr.EXPECT().RateLimit().Return(rateLimitCache).AnyTimes()
+	namingServer := &fakeDiscoverServer{cacheMgr: cacheMgr}
+
+	svc := buildProxylessTestService(t)
+	infos := ServiceInfos{
+		svc.Namespace: {svc.ServiceKey: svc},
+		"other":       {},
+	}
+	newGenerator := func() *XdsResourceGenerator {
+		return &XdsResourceGenerator{
+			namingServer: namingServer,
+			cache:        xdscache.NewResourceCache(nil),
+			versionNum:   atomic.NewUint64(0),
+			xdsNodesMgr:  resource.NewXDSNodeManager(),
+			svcInfoProvider: func() ServiceInfos {
+				return infos
+			},
+		}
+	}
+
+	sidecarOnly := newGenerator()
+	sidecarOnly.Generate("1", infos, nil)
+
+	mixed := newGenerator()
+	proxylessNode := &core.Node{Id: "proxyless~default/12345~127.0.0.1"}
+	mixed.xdsNodesMgr.AddNodeIfAbsent(1, proxylessNode)
+	mixed.Generate("1", infos, nil)
+	// 没有节点连接之前不会构建 proxyless 资源
+	assert.False(t, mixed.cache.HasRunTypeNamespace(resource.RunTypeProxylessGRPC, svc.Namespace))
+	assert.NoError(t, mixed.buildOneEnvoyXDSCache(mixed.xdsNodesMgr.GetNode(proxylessNode.Id)))
+	mixed.Generate("2", infos, nil)
+
+	// 只为有 proxyless 节点连接的命名空间构建资源
+	assert.True(t, mixed.cache.HasRunTypeNamespace(resource.RunTypeProxylessGRPC, svc.Namespace))
+	assert.False(t, mixed.cache.HasRunTypeNamespace(resource.RunTypeProxylessGRPC, "other"))
+	proxylessClusters := mixed.cache.GetRunTypeResources(resource.RunTypeProxylessGRPC, resource.CDS, svc.Namespace)
+	assert.Contains(t, proxylessClusters, resource.MakeProxylessClusterName(svc.ServiceKey, ""))
+
+	// sidecar 的资源不受 proxyless 节点的影响
+	for _, xdsType := range []resource.XDSType{resource.CDS, resource.EDS, resource.RDS} {
+		expect := sidecarOnly.cache.GetResources(xdsType, svc.Namespace, "")
+		actual := mixed.cache.GetResources(xdsType, svc.Namespace, "")
+		assert.NotEmpty(t, expect, xdsType.String())
+		assert.Equal(t, len(expect), len(actual), xdsType.String())
+		for name, res := range expect {
+			assert.True(t, proto.Equal(res, actual[name]), name)
+		}
+	}
+
+	// 命名空间下的 proxyless 节点全部断开后清理资源
+	mixed.xdsNodesMgr.DelNode(1)
+	mixed.Generate("3", infos, nil)
+	assert.False(t, mixed.cache.HasRunTypeNamespace(resource.RunTypeProxylessGRPC, svc.Namespace))
+}
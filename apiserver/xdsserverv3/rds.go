@@ -65,6 +65,8 @@ func (rds *RDSBuilder) Generate(option *resource.BuildOption) (interface{}, erro
 		case corev3.TrafficDirection_OUTBOUND:
 			resources = append(resources, rds.makeSidecarOutBoundRouteConfiguration(option)...)
 		}
+	case resource.RunTypeProxylessGRPC:
+		resources = rds.makeProxylessRouteConfiguration(option)
 	}
 	return resources, nil
 }
@@ -178,6 +180,113 @@ func (rds *RDSBuilder) makeSidecarInBoundRoutes(selfService model.ServiceKey,
 	}
 }
 
+// ---------------------- Proxyless gRPC ---------------------- //
+func (rds *RDSBuilder) makeProxylessRouteConfiguration(option *resource.BuildOption) []types.Resource {
+	var routeConfs []types.Resource
+	for svcKey, serviceInfo := range option.Services {
+		routeConf := &route.RouteConfiguration{
+			Name: resource.MakeProxylessRouteConfigName(svcKey),
+		}
+		if !option.ForceDelete {
+			// 每个 RouteConfiguration 只被对应服务的 API listener 引用，因此 domain 直接匹配全部即可
+			routeConf.VirtualHosts = []*route.VirtualHost{
+				{
+					Name:    svcKey.Domain(),
+					Domains: []string{"*"},
+					Routes:  rds.makeProxylessRoutes(serviceInfo),
+				},
+			}
+		}
+		routeConfs = append(routeConfs, routeConf)
+	}
+	return routeConfs
+}
+
+// makeProxylessRoutes 和 sidecar 的 OUTBOUND 路由逻辑保持一致，区别在于
+// 1. 路由目标的实例标签通过 subset cluster 实现，grpc 不支持 MetadataMatch
+// 2. 不下发 local ratelimit、on demand 等 grpc 不支持的 TypedPerFilterConfig
+func (rds *RDSBuilder) makeProxylessRoutes(serviceInfo *resource.ServiceInfo) []*route.Route {
+	var (
+		routes        []*route.Route
+		matchAllRoute *route.Route
+	)
+	hashPolicy := resource.MakeProxylessHashPolicy(serviceInfo)
+	rules := resource.FilterInboundRouterRule(serviceInfo)
+	for _, rule := range rules {
+		var (
+			matchAll     bool
+			destinations []*traffic_manage.DestinationGroup
+		)
+		for _, dest := range rule.GetDestinations() {
+			if !serviceInfo.MatchService(dest.GetNamespace(), dest.GetService()) {
+				continue
+			}
+			destinations = append(destinations, dest)
+		}
+		weightClusters := resource.MakeProxylessWeightClusters(serviceInfo, destinations)
+		if weightClusters == nil {
+			continue
+		}
+
+		routeMatch := &route.RouteMatch{
+			PathSpecifier: &route.RouteMatch_Prefix{Prefix: "/"},
+		}
+		for _, source := range rule.GetSources() {
+			if len(source.GetArguments()) == 0 {
+				matchAll = true
+				break
+			}
+			for _, arg := range source.GetArguments() {
+				if arg.Key == utils.MatchAll {
+					matchAll = true
+					break
+				}
+			}
+			if matchAll {
+				break
+			}
+			resource.BuildSidecarRouteMatch(routeMatch, source)
+		}
+		// grpc 会忽略带有 query 参数匹配的路由，这里直接不下发
+		if !matchAll && len(routeMatch.QueryParameters) != 0 {
+			continue
+		}
+
+		currentRoute := &route.Route{
+			Match: routeMatch,
+			Action: &route.Route_Route{
+				Route: &route.RouteAction{
+					ClusterSpecifier: &route.RouteAction_WeightedClusters{
+						WeightedClusters: weightClusters,
+					},
+					HashPolicy: hashPolicy,
+				},
+			},
+		}
+		if matchAll {
+			matchAllRoute = currentRoute
+		} else {
+			routes = append(routes, currentRoute)
+		}
+	}
+	if matchAllRoute == nil {
+		matchAllRoute = &route.Route{
+			Match: &route.RouteMatch{
+				PathSpecifier: &route.RouteMatch_Prefix{Prefix: "/"},
+			},
+			Action: &route.Route_Route{
+				Route: &route.RouteAction{
+					ClusterSpecifier: &route.RouteAction_Cluster{
+						Cluster: resource.MakeProxylessClusterName(serviceInfo.ServiceKey, ""),
+					},
+					HashPolicy: hashPolicy,
+				},
+			},
+		}
+	}
+	return append(routes, matchAllRoute)
+}
+
 // ---------------------- Envoy Gateway ---------------------- //
 func (rds *RDSBuilder) makeGatewayRouteConfiguration(option *resource.BuildOption) ([]types.Resource, error) {
 	// 每个 polaris serviceInfo 对应一个 virtualHost
@@ -47,6 +47,16 @@ type BuildOption struct {
 	TrafficDirection corev3.TrafficDirection
 	// ForceDelete 如果设置了该字段值为 true, 则不会真正执行 XDS 的构建工作, 仅仅生成对应资源的 Name 名称用于清理
 	ForceDelete bool
+	// RateLimitServing RLS 能够统计到全部 Polaris 节点收到的请求，只有此时才会开启 envoy.filters.http.ratelimit
+	RateLimitServing bool
+	// GlobalRateLimit 当前服务存在全局限流规则，需要在 HCM 中开启 envoy.filters.http.ratelimit
+	GlobalRateLimit bool
+	// ExtAuthz 当前服务存在服务访问策略，需要在 INBOUND HCM 中开启 envoy.filters.http.ext_authz
+	ExtAuthz bool
+	// CallerHeader ext_authz 识别调用方身份的请求头，OUTBOUND HCM 会用当前服务身份覆盖该请求头，为空表示不处理
+	CallerHeader string
+	// BuiltinCA 开启了内置 CA, INBOUND 校验客户端证书时接受内置 CA 签发的任意 spiffe:// 身份
+	BuiltinCA bool
 }
 
 func (opt *BuildOption) CloseEnvoyDemand() {
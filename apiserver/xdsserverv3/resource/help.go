@@ -27,6 +27,7 @@ import (
 
 	accesslog "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
 	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	mutationrulesv3 "github.com/envoyproxy/go-control-plane/envoy/config/common/mutation_rules/v3"
 	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
@@ -35,12 +36,14 @@ import (
 	filev3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/file/v3"
 	envoy_extensions_common_ratelimit_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/common/ratelimit/v3"
 	ratelimitv32 "github.com/envoyproxy/go-control-plane/envoy/extensions/common/ratelimit/v3"
+	extauthzv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
 	lrl "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
 	on_demandv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/on_demand/v3"
 	ratelimitfilter "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ratelimit/v3"
 	routerv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
 	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	tcp "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	headermutationv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/http/early_header_mutation/header_mutation/v3"
 	v32 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
@@ -707,8 +710,8 @@ func MakeDefaultFilterChain() *listenerv3.FilterChain {
 	}
 }
 
-func makeRateLimitHCMFilter(svcKey model.ServiceKey) []*hcm.HttpFilter {
-	return []*hcm.HttpFilter{
+func makeRateLimitHCMFilter(svcKey model.ServiceKey, opt *BuildOption) []*hcm.HttpFilter {
+	filters := []*hcm.HttpFilter{
 		{
 			Name: "envoy.filters.http.local_ratelimit",
 			ConfigType: &hcm.HttpFilter_TypedConfig{
@@ -718,27 +721,99 @@ func makeRateLimitHCMFilter(svcKey model.ServiceKey) []*hcm.HttpFilter {
 				}),
 			},
 		},
-		{
-			Name: "envoy.filters.http.ratelimit",
-			ConfigType: &hcm.HttpFilter_TypedConfig{
-				TypedConfig: MustNewAny(&ratelimitfilter.RateLimit{
-					Domain:      fmt.Sprintf("%s.%s", svcKey.Name, svcKey.Namespace),
-					Stage:       DistributedRateLimitStage,
-					RequestType: "external",
-					Timeout:     durationpb.New(2 * time.Second),
-					RateLimitService: &ratelimitconfv3.RateLimitServiceConfig{
-						GrpcService: &corev3.GrpcService{
-							TargetSpecifier: &corev3.GrpcService_EnvoyGrpc_{
-								EnvoyGrpc: &corev3.GrpcService_EnvoyGrpc{
-									ClusterName: "polaris_ratelimit",
-								},
+	}
+	if !opt.GlobalRateLimit {
+		return filters
+	}
+	// 只有存在全局限流规则时才需要访问 RLS
+	return append(filters, &hcm.HttpFilter{
+		Name: "envoy.filters.http.ratelimit",
+		ConfigType: &hcm.HttpFilter_TypedConfig{
+			TypedConfig: MustNewAny(&ratelimitfilter.RateLimit{
+				Domain:      MakeRateLimitDomain(svcKey),
+				Stage:       DistributedRateLimitStage,
+				RequestType: "external",
+				Timeout:     durationpb.New(2 * time.Second),
+				RateLimitService: &ratelimitconfv3.RateLimitServiceConfig{
+					GrpcService: &corev3.GrpcService{
+						TargetSpecifier: &corev3.GrpcService_EnvoyGrpc_{
+							EnvoyGrpc: &corev3.GrpcService_EnvoyGrpc{
+								ClusterName: RateLimitClusterName,
 							},
-							Timeout: durationpb.New(time.Second),
 						},
-						TransportApiVersion: core.ApiVersion_V3,
+						Timeout: durationpb.New(time.Second),
 					},
-				}),
+					TransportApiVersion: core.ApiVersion_V3,
+				},
+			}),
+		},
+	})
+}
+
+// makeCallerHeaderMutation 调用方的 OUTBOUND 流量使用当前服务身份覆盖调用方请求头，避免业务进程伪造其他服务的身份，
+// 当前服务未知时直接删除该请求头
+func makeCallerHeaderMutation(svcKey model.ServiceKey, opt *BuildOption) []*core.TypedExtensionConfig {
+	if opt.CallerHeader == "" {
+		return nil
+	}
+	mutation := &mutationrulesv3.HeaderMutation{
+		Action: &mutationrulesv3.HeaderMutation_Remove{
+			Remove: opt.CallerHeader,
+		},
+	}
+	if svcKey.IsExact() {
+		mutation.Action = &mutationrulesv3.HeaderMutation_Append{
+			Append: &core.HeaderValueOption{
+				Header: &core.HeaderValue{
+					Key:   opt.CallerHeader,
+					Value: svcKey.Namespace + "/" + svcKey.Name,
+				},
+				AppendAction: core.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
 			},
+		}
+	}
+	return []*core.TypedExtensionConfig{
+		{
+			Name: "envoy.http.early_header_mutation.header_mutation",
+			TypedConfig: MustNewAny(&headermutationv3.HeaderMutation{
+				Mutations: []*mutationrulesv3.HeaderMutation{mutation},
+			}),
+		},
+	}
+}
+
+// setPathNormalization ext_authz 按照 :path 匹配服务访问策略，需要先规范化路径，
+// 否则 //admin、/x/../admin、%2Fadmin 这类路径可以绕过前缀以及正则条件
+func setPathNormalization(manager *hcm.HttpConnectionManager) {
+	manager.NormalizePath = wrapperspb.Bool(true)
+	manager.MergeSlashes = true
+	manager.PathWithEscapedSlashesAction = hcm.HttpConnectionManager_UNESCAPE_AND_REDIRECT
+}
+
+// makeExtAuthzHCMFilter 通过 gRPC metadata 告知 ext_authz 服务当前被访问的目标服务
+func makeExtAuthzHCMFilter(svcKey model.ServiceKey) *hcm.HttpFilter {
+	return &hcm.HttpFilter{
+		Name: "envoy.filters.http.ext_authz",
+		ConfigType: &hcm.HttpFilter_TypedConfig{
+			TypedConfig: MustNewAny(&extauthzv3.ExtAuthz{
+				Services: &extauthzv3.ExtAuthz_GrpcService{
+					GrpcService: &corev3.GrpcService{
+						TargetSpecifier: &corev3.GrpcService_EnvoyGrpc_{
+							EnvoyGrpc: &corev3.GrpcService_EnvoyGrpc{
+								ClusterName: ExtAuthzClusterName,
+							},
+						},
+						Timeout: durationpb.New(time.Second),
+						InitialMetadata: []*corev3.HeaderValue{
+							{Key: ExtAuthzNamespaceKey, Value: svcKey.Namespace},
+							{Key: ExtAuthzServiceKey, Value: svcKey.Name},
+						},
+					},
+				},
+				TransportApiVersion: core.ApiVersion_V3,
+				// 鉴权服务不可用时拒绝请求，避免访问策略失效
+				FailureModeAllow: false,
+			}),
 		},
 	}
 }
@@ -771,6 +846,8 @@ func MakeSidecarOnDemandOutBoundHCM(svcKey model.ServiceKey, option *BuildOption
 		AccessLog:           accessLog(),
 		HttpFilters:         hcmFilters,
 		HttpProtocolOptions: &core.Http1ProtocolOptions{AcceptHttp_10: true},
+
+		EarlyHeaderMutationExtensions: makeCallerHeaderMutation(svcKey, option),
 	}
 	return manager
 }
@@ -785,7 +862,11 @@ func MakeSidecarBoundHCM(svcKey model.ServiceKey, trafficDirection corev3.Traffi
 		},
 	}
 	if trafficDirection == corev3.TrafficDirection_INBOUND {
-		hcmFilters = append(makeRateLimitHCMFilter(svcKey), hcmFilters...)
+		hcmFilters = append(makeRateLimitHCMFilter(svcKey, opt), hcmFilters...)
+		if opt.ExtAuthz {
+			// 鉴权先于限流执行，被拒绝的请求不占用限流配额
+			hcmFilters = append([]*hcm.HttpFilter{makeExtAuthzHCMFilter(svcKey)}, hcmFilters...)
+		}
 	}
 	if opt.IsDemand() {
 		hcmFilters = append([]*hcm.HttpFilter{
@@ -812,13 +893,18 @@ func MakeSidecarBoundHCM(svcKey model.ServiceKey, trafficDirection corev3.Traffi
 	// 重写 RouteSpecifier 的路由规则数据信息
 	if trafficDirection == core.TrafficDirection_INBOUND {
 		manager.GetRds().RouteConfigName = MakeInBoundRouteConfigName(svcKey, opt.IsDemand())
+		if opt.ExtAuthz {
+			setPathNormalization(manager)
+		}
+	} else {
+		manager.EarlyHeaderMutationExtensions = makeCallerHeaderMutation(svcKey, opt)
 	}
 
 	return manager
 }
 
 func MakeGatewayBoundHCM(svcKey model.ServiceKey, opt *BuildOption) *hcm.HttpConnectionManager {
-	hcmFilters := makeRateLimitHCMFilter(svcKey)
+	hcmFilters := makeRateLimitHCMFilter(svcKey, opt)
 	hcmFilters = append(hcmFilters, &hcm.HttpFilter{
 		Name: wellknown.Router,
 		ConfigType: &hcm.HttpFilter_TypedConfig{
@@ -885,29 +971,9 @@ func MakeGatewayLocalRateLimit(rateLimitCache types.RateLimitCache, pathSpecifie
 	confKey := fmt.Sprintf("INBOUND|GATEWAY|%s|%s|%s", svcKey.Namespace, svcKey.Name, pathSpecifier)
 	rateLimitConf := BuildRateLimitConf(confKey)
 	filters := make(map[string]*anypb.Any)
-	ratelimits := make([]*route.RateLimit, 0, len(conf))
-	for _, c := range conf {
-		rule := c.Proto
-		if rule == nil {
-			continue
-		}
-		if rule.GetDisable().GetValue() {
-			continue
-		}
-		if rule.GetMethod().GetValue().GetValue() != pathSpecifier {
-			continue
-		}
-		actions, descriptors := BuildRateLimitDescriptors(rule)
-		rateLimitConf.Descriptors = descriptors
-		ratelimitRule := &route.RateLimit{Actions: actions}
-		switch rule.GetType() {
-		case apitraffic.Rule_LOCAL:
-			ratelimitRule.Stage = wrapperspb.UInt32(LocalRateLimitStage)
-		case apitraffic.Rule_GLOBAL:
-			ratelimitRule.Stage = wrapperspb.UInt32(DistributedRateLimitStage)
-		}
-		ratelimits = append(ratelimits, ratelimitRule)
-	}
+	ratelimits := makeRouteRateLimits(conf, rateLimitConf, func(rule *apitraffic.Rule) bool {
+		return rule.GetMethod().GetValue().GetValue() == pathSpecifier
+	})
 	if len(ratelimits) == 0 {
 		return nil, nil, nil
 	}
@@ -924,6 +990,16 @@ func MakeSidecarLocalRateLimit(rateLimitCache types.RateLimitCache,
 	confKey := fmt.Sprintf("INBOUND|SIDECAR|%s|%s", svcKey.Namespace, svcKey.Name)
 	rateLimitConf := BuildRateLimitConf(confKey)
 	filters := make(map[string]*anypb.Any)
+	ratelimits := makeRouteRateLimits(conf, rateLimitConf, func(rule *apitraffic.Rule) bool {
+		return true
+	})
+	filters["envoy.filters.http.local_ratelimit"] = MustNewAny(rateLimitConf)
+	return ratelimits, filters, nil
+}
+
+// makeRouteRateLimits 本地限流规则的 descriptor 交给 local_ratelimit 处理，全局限流规则额外携带规则 ID 交给 RLS 计数
+func makeRouteRateLimits(conf []*model.RateLimit, rateLimitConf *lrl.LocalRateLimit,
+	match func(rule *apitraffic.Rule) bool) []*route.RateLimit {
 	ratelimits := make([]*route.RateLimit, 0, len(conf))
 	for _, c := range conf {
 		rule := c.Proto
@@ -933,19 +1009,49 @@ func MakeSidecarLocalRateLimit(rateLimitCache types.RateLimitCache,
 		if rule.GetDisable().GetValue() {
 			continue
 		}
+		if !match(rule) {
+			continue
+		}
 		actions, descriptors := BuildRateLimitDescriptors(rule)
-		rateLimitConf.Descriptors = descriptors
 		ratelimitRule := &route.RateLimit{Actions: actions}
 		switch rule.GetType() {
 		case apitraffic.Rule_LOCAL:
 			ratelimitRule.Stage = wrapperspb.UInt32(LocalRateLimitStage)
+			rateLimitConf.Descriptors = append(rateLimitConf.Descriptors, descriptors...)
 		case apitraffic.Rule_GLOBAL:
 			ratelimitRule.Stage = wrapperspb.UInt32(DistributedRateLimitStage)
+			ratelimitRule.Actions = append(ratelimitRule.Actions, &route.RateLimit_Action{
+				ActionSpecifier: &route.RateLimit_Action_GenericKey_{
+					GenericKey: &route.RateLimit_Action_GenericKey{
+						DescriptorKey:   RateLimitRuleIDKey,
+						DescriptorValue: c.ID,
+					},
+				},
+			})
 		}
 		ratelimits = append(ratelimits, ratelimitRule)
 	}
-	filters["envoy.filters.http.local_ratelimit"] = MustNewAny(rateLimitConf)
-	return ratelimits, filters, nil
+	return ratelimits
+}
+
+// HasGlobalRateLimit 服务是否存在生效中的全局限流规则
+func HasGlobalRateLimit(rateLimitCache types.RateLimitCache, svcKey model.ServiceKey) bool {
+	conf, _ := rateLimitCache.GetRateLimitRules(svcKey)
+	for _, c := range conf {
+		rule := c.Proto
+		if rule == nil || rule.GetDisable().GetValue() {
+			continue
+		}
+		if rule.GetType() == apitraffic.Rule_GLOBAL {
+			return true
+		}
+	}
+	return false
+}
+
+// MakeRateLimitDomain 全局限流的 domain, 格式为 <service>.<namespace>
+func MakeRateLimitDomain(svcKey model.ServiceKey) string {
+	return fmt.Sprintf("%s.%s", svcKey.Name, svcKey.Namespace)
 }
 
 // Translate the circuit breaker configuration of Polaris into OutlierDetection
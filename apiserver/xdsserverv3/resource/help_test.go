@@ -0,0 +1,188 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package resource
+
+import (
+	"testing"
+	"time"
+
+	mutationrulesv3 "github.com/envoyproxy/go-control-plane/envoy/config/common/mutation_rules/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extauthzv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	lrl "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	headermutationv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/http/early_header_mutation/header_mutation/v3"
+	"github.com/golang/mock/gomock"
+	apitraffic "github.com/polarismesh/specification/source/go/api/v1/traffic_manage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/polarismesh/polaris/cache/mock"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+func newTestRateLimit(id string, ruleType apitraffic.Rule_Type) *model.RateLimit {
+	return &model.RateLimit{
+		ID: id,
+		Proto: &apitraffic.Rule{
+			Id:   utils.NewStringValue(id),
+			Type: ruleType,
+			Amounts: []*apitraffic.Amount{
+				{
+					MaxAmount:     utils.NewUInt32Value(10),
+					ValidDuration: durationpb.New(time.Second),
+				},
+			},
+		},
+	}
+}
+
+func TestMakeSidecarLocalRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svcKey := model.ServiceKey{Namespace: "default", Name: "echo"}
+	rateLimitCache := mock.NewMockRateLimitCache(ctrl)
+	rateLimitCache.EXPECT().GetRateLimitRules(svcKey).Return([]*model.RateLimit{
+		newTestRateLimit("local-1", apitraffic.Rule_LOCAL),
+		newTestRateLimit("local-2", apitraffic.Rule_LOCAL),
+		newTestRateLimit("global", apitraffic.Rule_GLOBAL),
+	}, "").AnyTimes()
+
+	limits, filters, err := MakeSidecarLocalRateLimit(rateLimitCache, svcKey)
+	assert.NoError(t, err)
+	assert.Len(t, limits, 3)
+	assert.Equal(t, uint32(LocalRateLimitStage), limits[0].GetStage().GetValue())
+	assert.Equal(t, uint32(DistributedRateLimitStage), limits[2].GetStage().GetValue())
+
+	// 全局限流规则携带规则 ID，交由 RLS 计数
+	actions := limits[2].GetActions()
+	genericKey := actions[len(actions)-1].GetGenericKey()
+	assert.Equal(t, RateLimitRuleIDKey, genericKey.GetDescriptorKey())
+	assert.Equal(t, "global", genericKey.GetDescriptorValue())
+
+	// 只有本地限流规则的 descriptor 交给 local_ratelimit
+	conf := &lrl.LocalRateLimit{}
+	assert.NoError(t, filters["envoy.filters.http.local_ratelimit"].UnmarshalTo(conf))
+	assert.Len(t, conf.GetDescriptors(), 2)
+
+	assert.True(t, HasGlobalRateLimit(rateLimitCache, svcKey))
+	other := model.ServiceKey{Namespace: "default", Name: "other"}
+	rateLimitCache.EXPECT().GetRateLimitRules(other).Return([]*model.RateLimit{
+		newTestRateLimit("local", apitraffic.Rule_LOCAL),
+	}, "").AnyTimes()
+	assert.False(t, HasGlobalRateLimit(rateLimitCache, other))
+}
+
+func TestMakeSidecarBoundHCM_GlobalRateLimit(t *testing.T) {
+	svcKey := model.ServiceKey{Namespace: "default", Name: "echo"}
+	filterNames := func(opt *BuildOption) []string {
+		manager := MakeSidecarBoundHCM(svcKey, corev3.TrafficDirection_INBOUND, opt)
+		names := make([]string, 0, len(manager.GetHttpFilters()))
+		for _, filter := range manager.GetHttpFilters() {
+			names = append(names, filter.GetName())
+		}
+		return names
+	}
+
+	opt := &BuildOption{RunType: RunTypeSidecar, Namespace: "default", SelfService: svcKey}
+	assert.NotContains(t, filterNames(opt), "envoy.filters.http.ratelimit")
+	opt.GlobalRateLimit = true
+	assert.Contains(t, filterNames(opt), "envoy.filters.http.ratelimit")
+}
+
+func TestMakeSidecarBoundHCM_ExtAuthz(t *testing.T) {
+	svcKey := model.ServiceKey{Namespace: "default", Name: "echo"}
+	opt := &BuildOption{RunType: RunTypeSidecar, Namespace: "default", SelfService: svcKey}
+	manager := MakeSidecarBoundHCM(svcKey, corev3.TrafficDirection_INBOUND, opt)
+	assert.NotEqual(t, "envoy.filters.http.ext_authz", manager.GetHttpFilters()[0].GetName())
+	assert.Nil(t, manager.GetNormalizePath())
+
+	opt.ExtAuthz = true
+	manager = MakeSidecarBoundHCM(svcKey, corev3.TrafficDirection_INBOUND, opt)
+	// //admin、/x/../admin、%2Fadmin 在鉴权前被规范化为 /admin
+	assert.True(t, manager.GetNormalizePath().GetValue())
+	assert.True(t, manager.GetMergeSlashes())
+	assert.Equal(t, hcm.HttpConnectionManager_UNESCAPE_AND_REDIRECT, manager.GetPathWithEscapedSlashesAction())
+	filter := manager.GetHttpFilters()[0]
+	assert.Equal(t, "envoy.filters.http.ext_authz", filter.GetName())
+	conf := &extauthzv3.ExtAuthz{}
+	assert.NoError(t, filter.GetTypedConfig().UnmarshalTo(conf))
+	assert.False(t, conf.GetFailureModeAllow())
+	grpcService := conf.GetGrpcService()
+	assert.Equal(t, ExtAuthzClusterName, grpcService.GetEnvoyGrpc().GetClusterName())
+	metadata := map[string]string{}
+	for _, header := range grpcService.GetInitialMetadata() {
+		metadata[header.GetKey()] = header.GetValue()
+	}
+	assert.Equal(t, map[string]string{ExtAuthzNamespaceKey: "default", ExtAuthzServiceKey: "echo"}, metadata)
+
+	// OUTBOUND 方向不做鉴权
+	manager = MakeSidecarBoundHCM(svcKey, corev3.TrafficDirection_OUTBOUND, opt)
+	for _, item := range manager.GetHttpFilters() {
+		assert.NotEqual(t, "envoy.filters.http.ext_authz", item.GetName())
+	}
+}
+
+func TestMakeInboundCommonTLSContext(t *testing.T) {
+	sanPrefix := func(opt *BuildOption) string {
+		ctx := MakeInboundCommonTLSContext(opt)
+		validation := ctx.GetCombinedValidationContext().GetDefaultValidationContext()
+		return validation.GetMatchSubjectAltNames()[0].GetPrefix()
+	}
+
+	opt := &BuildOption{RunType: RunTypeSidecar, Namespace: "default", TLSMode: TLSModeStrict}
+	assert.Equal(t, "spiffe://cluster.local/", sanPrefix(opt))
+	opt.BuiltinCA = true
+	assert.Equal(t, "spiffe://", sanPrefix(opt))
+}
+
+func TestMakeSidecarBoundHCM_CallerHeader(t *testing.T) {
+	svcKey := model.ServiceKey{Namespace: "default", Name: "echo"}
+	opt := &BuildOption{RunType: RunTypeSidecar, Namespace: "default", SelfService: svcKey}
+	manager := MakeSidecarBoundHCM(svcKey, corev3.TrafficDirection_OUTBOUND, opt)
+	assert.Empty(t, manager.GetEarlyHeaderMutationExtensions())
+
+	mutation := func(manager *hcm.HttpConnectionManager) *mutationrulesv3.HeaderMutation {
+		extensions := manager.GetEarlyHeaderMutationExtensions()
+		assert.Len(t, extensions, 1)
+		conf := &headermutationv3.HeaderMutation{}
+		assert.NoError(t, extensions[0].GetTypedConfig().UnmarshalTo(conf))
+		assert.Len(t, conf.GetMutations(), 1)
+		return conf.GetMutations()[0]
+	}
+
+	// 调用方 OUTBOUND 流量使用自身服务身份覆盖调用方请求头
+	opt.CallerHeader = "x-polaris-caller"
+	header := mutation(MakeSidecarBoundHCM(svcKey, corev3.TrafficDirection_OUTBOUND, opt)).GetAppend()
+	assert.Equal(t, "x-polaris-caller", header.GetHeader().GetKey())
+	assert.Equal(t, "default/echo", header.GetHeader().GetValue())
+	assert.Equal(t, corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD, header.GetAppendAction())
+	header = mutation(MakeSidecarOnDemandOutBoundHCM(svcKey, opt)).GetAppend()
+	assert.Equal(t, "default/echo", header.GetHeader().GetValue())
+
+	// 当前服务未知时删除调用方请求头
+	unknown := model.ServiceKey{Namespace: "default"}
+	assert.Equal(t, "x-polaris-caller",
+		mutation(MakeSidecarBoundHCM(unknown, corev3.TrafficDirection_OUTBOUND, opt)).GetRemove())
+
+	// INBOUND 方向不处理
+	manager = MakeSidecarBoundHCM(svcKey, corev3.TrafficDirection_INBOUND, opt)
+	assert.Empty(t, manager.GetEarlyHeaderMutationExtensions())
+}
@@ -48,6 +48,19 @@ const (
 	LocalRateLimitStage = 0
 	// DistributedRateLimitStage envoy remote ratelimit stage
 	DistributedRateLimitStage = 1
+	// RateLimitRuleIDKey 全局限流 descriptor 中携带限流规则 ID 的 key，RLS 根据该 key 定位限流规则
+	RateLimitRuleIDKey = "polaris.ratelimit_rule_id"
+	// RateLimitClusterName envoy 访问 RLS 使用的集群名称
+	RateLimitClusterName = "polaris_ratelimit"
+)
+
+const (
+	// ExtAuthzClusterName envoy 访问 ext_authz 服务使用的集群名称
+	ExtAuthzClusterName = "polaris_ext_authz"
+	// ExtAuthzNamespaceKey ext_authz 请求的 gRPC metadata 中携带目标服务命名空间的 key
+	ExtAuthzNamespaceKey = "x-polaris-namespace"
+	// ExtAuthzServiceKey ext_authz 请求的 gRPC metadata 中携带目标服务名称的 key
+	ExtAuthzServiceKey = "x-polaris-service"
 )
 
 var (
@@ -103,6 +116,8 @@ func FromSimpleXDS(s string) XDSType {
 		return RLS
 	case "vhds":
 		return VHDS
+	case "sds":
+		return SDS
 	default:
 		return UnknownXDS
 	}
@@ -122,6 +137,8 @@ func FormatTypeUrl(typeUrl string) XDSType {
 		return RLS
 	case resourcev3.VirtualHostType:
 		return VHDS
+	case resourcev3.SecretType:
+		return SDS
 	default:
 		return UnknownXDS
 	}
@@ -146,6 +163,9 @@ func (x XDSType) ResourceType() resourcev3.Type {
 	if x == VHDS {
 		return resourcev3.VirtualHostType
 	}
+	if x == SDS {
+		return resourcev3.SecretType
+	}
 	return resourcev3.AnyType
 }
 
@@ -168,6 +188,9 @@ func (x XDSType) String() string {
 	if x == VHDS {
 		return resourcev3.VirtualHostType
 	}
+	if x == SDS {
+		return resourcev3.SecretType
+	}
 	return resourcev3.AnyType
 }
 
@@ -186,6 +209,9 @@ const (
 	TLSModePermissive TLSMode = "permissive"
 )
 
+// ServiceTokenTag Envoy Node 在 metadata 中携带所属服务的 token，内置 CA 校验通过后才会签发证书
+const ServiceTokenTag = "polarismesh.cn/service-token"
+
 func EnableTLS(t TLSMode) bool {
 	return t == TLSModePermissive || t == TLSModeStrict
 }
@@ -202,6 +228,8 @@ type ServiceInfo struct {
 	Namespace              string
 	ServiceKey             model.ServiceKey
 	AliasFor               *model.Service
+	Metadata               map[string]string
+	SvcRevision            string
 	Instances              []*apiservice.Instance
 	SvcInsRevision         string
 	Routing                *traffic_manage.Routing
@@ -217,6 +245,9 @@ type ServiceInfo struct {
 
 func (s *ServiceInfo) Equal(o *ServiceInfo) bool {
 	// 通过 revision 判断
+	if s.SvcRevision != o.SvcRevision {
+		return false
+	}
 	if s.SvcInsRevision != o.SvcInsRevision {
 		return false
 	}
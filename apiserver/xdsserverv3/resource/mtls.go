@@ -26,6 +26,17 @@ import (
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+const (
+	// SDSDefaultSecretName 工作负载证书的 SDS secret 名称
+	SDSDefaultSecretName = "default"
+	// SDSRootCASecretName 信任列表的 SDS secret 名称
+	SDSRootCASecretName = "ROOTCA"
+	// SpiffeURIPrefix 外部 CA 签发的工作负载身份 URI SAN 前缀
+	SpiffeURIPrefix = "spiffe://cluster.local/"
+	// BuiltinCASpiffeURIPrefix 内置 CA 签发的工作负载身份 URI SAN 前缀, 完整格式为 spiffe://<namespace>/<service>
+	BuiltinCASpiffeURIPrefix = "spiffe://"
+)
+
 var DefaultSdsConfig = &core.ConfigSource{
 	ConfigSourceSpecifier: &core.ConfigSource_ApiConfigSource{
 		ApiConfigSource: &core.ApiConfigSource{
@@ -55,7 +66,7 @@ var MTLSTransportSocketMatch = &structpb.Struct{
 var OutboundCommonTLSContext = &tlstrans.CommonTlsContext{
 	TlsCertificateSdsSecretConfigs: []*tlstrans.SdsSecretConfig{
 		{
-			Name:      "default",
+			Name:      SDSDefaultSecretName,
 			SdsConfig: DefaultSdsConfig,
 		},
 	},
@@ -63,48 +74,63 @@ var OutboundCommonTLSContext = &tlstrans.CommonTlsContext{
 		CombinedValidationContext: &tlstrans.CommonTlsContext_CombinedCertificateValidationContext{
 			DefaultValidationContext: &tlstrans.CertificateValidationContext{},
 			ValidationContextSdsSecretConfig: &tlstrans.SdsSecretConfig{
-				Name:      "ROOTCA",
+				Name:      SDSRootCASecretName,
 				SdsConfig: DefaultSdsConfig,
 			},
 		},
 	},
 }
 
-var InboundCommonTLSContext = &tlstrans.CommonTlsContext{
-	TlsParams: &tlstrans.TlsParameters{
-		TlsMinimumProtocolVersion: tlstrans.TlsParameters_TLSv1_2,
-		CipherSuites: []string{
-			"ECDHE-ECDSA-AES256-GCM-SHA384",
-			"ECDHE-RSA-AES256-GCM-SHA384",
-			"ECDHE-ECDSA-AES128-GCM-SHA256",
-			"ECDHE-RSA-AES128-GCM-SHA256",
-			"AES256-GCM-SHA384",
-			"AES128-GCM-SHA256",
+var InboundCommonTLSContext = makeInboundCommonTLSContext(SpiffeURIPrefix)
+
+// BuiltinCAInboundCommonTLSContext 开启内置 CA 时使用, 客户端证书的身份不再限定在 cluster.local 下
+var BuiltinCAInboundCommonTLSContext = makeInboundCommonTLSContext(BuiltinCASpiffeURIPrefix)
+
+// MakeInboundCommonTLSContext 根据证书来源选择 INBOUND 的 TLS 配置
+func MakeInboundCommonTLSContext(option *BuildOption) *tlstrans.CommonTlsContext {
+	if option.BuiltinCA {
+		return BuiltinCAInboundCommonTLSContext
+	}
+	return InboundCommonTLSContext
+}
+
+func makeInboundCommonTLSContext(sanPrefix string) *tlstrans.CommonTlsContext {
+	return &tlstrans.CommonTlsContext{
+		TlsParams: &tlstrans.TlsParameters{
+			TlsMinimumProtocolVersion: tlstrans.TlsParameters_TLSv1_2,
+			CipherSuites: []string{
+				"ECDHE-ECDSA-AES256-GCM-SHA384",
+				"ECDHE-RSA-AES256-GCM-SHA384",
+				"ECDHE-ECDSA-AES128-GCM-SHA256",
+				"ECDHE-RSA-AES128-GCM-SHA256",
+				"AES256-GCM-SHA384",
+				"AES128-GCM-SHA256",
+			},
 		},
-	},
-	TlsCertificateSdsSecretConfigs: []*tlstrans.SdsSecretConfig{
-		{
-			Name:      "default",
-			SdsConfig: DefaultSdsConfig,
+		TlsCertificateSdsSecretConfigs: []*tlstrans.SdsSecretConfig{
+			{
+				Name:      SDSDefaultSecretName,
+				SdsConfig: DefaultSdsConfig,
+			},
 		},
-	},
-	ValidationContextType: &tlstrans.CommonTlsContext_CombinedValidationContext{
-		CombinedValidationContext: &tlstrans.CommonTlsContext_CombinedCertificateValidationContext{
-			DefaultValidationContext: &tlstrans.CertificateValidationContext{
-				MatchSubjectAltNames: []*matcherv3.StringMatcher{
-					{
-						MatchPattern: &matcherv3.StringMatcher_Prefix{
-							Prefix: "spiffe://cluster.local/",
+		ValidationContextType: &tlstrans.CommonTlsContext_CombinedValidationContext{
+			CombinedValidationContext: &tlstrans.CommonTlsContext_CombinedCertificateValidationContext{
+				DefaultValidationContext: &tlstrans.CertificateValidationContext{
+					MatchSubjectAltNames: []*matcherv3.StringMatcher{
+						{
+							MatchPattern: &matcherv3.StringMatcher_Prefix{
+								Prefix: sanPrefix,
+							},
 						},
 					},
 				},
-			},
-			ValidationContextSdsSecretConfig: &tlstrans.SdsSecretConfig{
-				Name:      "ROOTCA",
-				SdsConfig: DefaultSdsConfig,
+				ValidationContextSdsSecretConfig: &tlstrans.SdsSecretConfig{
+					Name:      SDSRootCASecretName,
+					SdsConfig: DefaultSdsConfig,
+				},
 			},
 		},
-	},
+	}
 }
 
 func MakeTLSTransportSocket(ctx proto.Message) *core.TransportSocket {
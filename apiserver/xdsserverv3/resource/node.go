@@ -41,6 +41,8 @@ var (
 	RunTypeGateway RunType = "gateway"
 	// RunTypeSidecar xds node run type is sidecar
 	RunTypeSidecar RunType = "sidecar"
+	// RunTypeProxylessGRPC xds node run type is proxyless grpc, the grpc client use xds:/// resolver directly
+	RunTypeProxylessGRPC RunType = "proxyless"
 )
 
 const (
@@ -68,6 +70,12 @@ const (
 	SidecarOpenOnDemandFeature = "sidecar.polarismesh.cn/openOnDemand"
 	// SidecarOpenOnDemandServer .
 	SidecarOpenOnDemandServer = "sidecar.polarismesh.cn/demandServer"
+	// ProxylessGRPCTag xds metadata key, value is true means node is a proxyless grpc client
+	ProxylessGRPCTag = "proxyless.polarismesh.cn/enable"
+	// ProxylessNamespaceName xds metadata key when node is run in proxyless grpc mode
+	ProxylessNamespaceName = "proxyless.polarismesh.cn/serviceNamespace"
+	// grpcUserAgentPrefix grpc xds client 上报的 user_agent_name 均以 gRPC 开头, 例如 gRPC Go、gRPC Java
+	grpcUserAgentPrefix = "gRPC"
 )
 
 type EnvoyNodeView struct {
@@ -85,10 +93,11 @@ type EnvoyNodeView struct {
 
 func NewXDSNodeManager() *XDSNodeManager {
 	return &XDSNodeManager{
-		nodes:         map[string]*XDSClient{},
-		streamTonodes: map[int64]*XDSClient{},
-		sidecarNodes:  map[string]*XDSClient{},
-		gatewayNodes:  map[string]*XDSClient{},
+		nodes:          map[string]*XDSClient{},
+		streamTonodes:  map[int64]*XDSClient{},
+		sidecarNodes:   map[string]*XDSClient{},
+		gatewayNodes:   map[string]*XDSClient{},
+		proxylessNodes: map[string]*XDSClient{},
 	}
 }
 
@@ -100,6 +109,8 @@ type XDSNodeManager struct {
 	sidecarNodes map[string]*XDSClient
 	// gatewayNodes The XDS client is the node list of the Gateway run mode
 	gatewayNodes map[string]*XDSClient
+	// proxylessNodes The XDS client is the node list of the proxyless grpc run mode
+	proxylessNodes map[string]*XDSClient
 }
 
 func (x *XDSNodeManager) AddNodeIfAbsent(streamId int64, node *core.Node) {
@@ -125,6 +136,12 @@ func (x *XDSNodeManager) AddNodeIfAbsent(streamId int64, node *core.Node) {
 			log.Info("[XDS][Node][V3] add gateway xds node", zap.Int64("stream", streamId),
 				zap.String("info", p.String()))
 		}
+	case RunTypeProxylessGRPC:
+		if _, ok := x.proxylessNodes[node.Id]; !ok {
+			x.proxylessNodes[node.Id] = p
+			log.Info("[XDS][Node][V3] add proxyless grpc xds node", zap.Int64("stream", streamId),
+				zap.String("info", p.String()))
+		}
 	default:
 		if _, ok := x.sidecarNodes[node.Id]; !ok {
 			x.sidecarNodes[node.Id] = p
@@ -140,6 +157,7 @@ func (x *XDSNodeManager) DelNode(streamId int64) {
 
 	if p, ok := x.streamTonodes[streamId]; ok {
 		delete(x.nodes, p.Node.Id)
+		delete(x.proxylessNodes, p.Node.Id)
 		log.Info("[XDS][Node][V3] remove xds node", zap.Int64("stream", streamId),
 			zap.String("info", p.String()))
 	}
@@ -203,10 +221,41 @@ func (x *XDSNodeManager) ListSidecarNodes() []*XDSClient {
 	return ret
 }
 
+func (x *XDSNodeManager) ListProxylessNodes() []*XDSClient {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	ret := make([]*XDSClient, 0, len(x.proxylessNodes))
+	for i := range x.proxylessNodes {
+		ret = append(ret, x.proxylessNodes[i])
+	}
+	return ret
+}
+
+// HasProxylessNodes 命名空间下是否有 proxyless grpc 节点连接
+func (x *XDSNodeManager) HasProxylessNodes(namespace string) bool {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	for _, node := range x.proxylessNodes {
+		if node.GetSelfNamespace() == namespace {
+			return true
+		}
+	}
+	return false
+}
+
 func (x *XDSNodeManager) ListEnvoyNodesView(run RunType) []*EnvoyNodeView {
 	x.lock.RLock()
 	defer x.lock.RUnlock()
 
+	if run == RunTypeProxylessGRPC {
+		ret := make([]*EnvoyNodeView, 0, len(x.proxylessNodes))
+		for i := range x.proxylessNodes {
+			ret = append(ret, x.proxylessNodes[i].toView())
+		}
+		return ret
+	}
 	if run == RunTypeSidecar {
 		ret := make([]*EnvoyNodeView, 0, len(x.sidecarNodes))
 		for i := range x.sidecarNodes {
@@ -273,6 +322,8 @@ type XDSClient struct {
 	TLSMode      TLSMode
 	OpenOnDemand bool
 	DemandServer string
+	// ServiceToken 所属服务的 token，只用于内置 CA 校验节点身份，不会保留在 Metadata 中
+	ServiceToken string
 }
 
 func (n *XDSClient) toView() *EnvoyNodeView {
@@ -346,8 +397,19 @@ func (n *XDSClient) GetSelfService() string {
 	return n.Metadata[SidecarServiceName]
 }
 
+// IsProxyless 是否为 proxyless grpc 客户端
+func (n *XDSClient) IsProxyless() bool {
+	return n.RunType == RunTypeProxylessGRPC
+}
+
 // GetSelfNamespace 获取 envoy 对应的 namespace 信息
 func (n *XDSClient) GetSelfNamespace() string {
+	if n.IsProxyless() {
+		if val, ok := n.Metadata[ProxylessNamespaceName]; ok && val != "" {
+			return val
+		}
+		return n.Namespace
+	}
 	if n.IsGateway() {
 		val, ok := n.Metadata[GatewayNamespaceName]
 		if ok {
@@ -395,9 +457,25 @@ func parseNodeProxy(node *core.Node) *XDSClient {
 		if onDemand, ok := getEnvoyMetaField(node.Metadata, SidecarOpenOnDemandFeature, ""); ok {
 			proxy.OpenOnDemand = onDemand == "true"
 		}
+		if proxyless, ok := getEnvoyMetaField(node.Metadata, ProxylessGRPCTag, ""); ok && proxyless == "true" {
+			proxy.RunType = RunTypeProxylessGRPC
+		}
+	}
+	if strings.HasPrefix(node.GetUserAgentName(), grpcUserAgentPrefix) {
+		proxy.RunType = RunTypeProxylessGRPC
+	}
+	if proxy.IsProxyless() {
+		// proxyless grpc 不支持 Envoy 的 mTLS 以及按需加载能力
+		proxy.TLSMode = TLSModeNone
+		proxy.OpenOnDemand = false
 	}
 
 	proxy.Metadata = parseMetadata(node.GetMetadata())
+	// 避免服务 token 出现在日志以及节点查询接口中
+	if token, ok := proxy.Metadata[ServiceTokenTag]; ok {
+		proxy.ServiceToken = token
+		delete(proxy.Metadata, ServiceTokenTag)
+	}
 	return proxy
 }
 
@@ -17,7 +17,12 @@
 
 package resource
 
-import "testing"
+import (
+	"testing"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"google.golang.org/protobuf/types/known/structpb"
+)
 
 func Test_parseNodeID(t *testing.T) {
 	type args struct {
@@ -110,3 +115,61 @@ func Test_parseNodeID(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseNodeProxyRunType(t *testing.T) {
+	proxylessMeta, _ := structpb.NewStruct(map[string]interface{}{
+		ProxylessGRPCTag:       "true",
+		ProxylessNamespaceName: "bookinfo",
+		TLSModeTag:             string(TLSModeStrict),
+	})
+	tests := []struct {
+		name          string
+		node          *core.Node
+		wantRunType   RunType
+		wantNamespace string
+		wantTLSMode   TLSMode
+	}{
+		{
+			name:          "sidecar",
+			node:          &core.Node{Id: "sidecar~default/12345~127.0.0.1", UserAgentName: "envoy"},
+			wantRunType:   RunTypeSidecar,
+			wantNamespace: "default",
+			wantTLSMode:   TLSModeNone,
+		},
+		{
+			name:          "proxyless-node-id",
+			node:          &core.Node{Id: "proxyless~default/12345~127.0.0.1"},
+			wantRunType:   RunTypeProxylessGRPC,
+			wantNamespace: "default",
+			wantTLSMode:   TLSModeNone,
+		},
+		{
+			name:          "proxyless-user-agent",
+			node:          &core.Node{Id: "default/12345~127.0.0.1", UserAgentName: "gRPC Go"},
+			wantRunType:   RunTypeProxylessGRPC,
+			wantNamespace: "default",
+			wantTLSMode:   TLSModeNone,
+		},
+		{
+			name:          "proxyless-metadata",
+			node:          &core.Node{Id: "grpc-client-1", Metadata: proxylessMeta},
+			wantRunType:   RunTypeProxylessGRPC,
+			wantNamespace: "bookinfo",
+			wantTLSMode:   TLSModeNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := parseNodeProxy(tt.node)
+			if client.RunType != tt.wantRunType {
+				t.Errorf("parseNodeProxy() RunType = %v, want %v", client.RunType, tt.wantRunType)
+			}
+			if client.GetSelfNamespace() != tt.wantNamespace {
+				t.Errorf("parseNodeProxy() namespace = %v, want %v", client.GetSelfNamespace(), tt.wantNamespace)
+			}
+			if client.TLSMode != tt.wantTLSMode {
+				t.Errorf("parseNodeProxy() TLSMode = %v, want %v", client.TLSMode, tt.wantTLSMode)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package resource
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	routerv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/polarismesh/specification/source/go/api/v1/traffic_manage"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+const (
+	// ProxylessRouteConfigName proxyless grpc 场景下 RDS 的名称前缀
+	ProxylessRouteConfigName = "polaris-proxyless-router"
+	// ProxylessClusterPrefix proxyless grpc 场景下 CDS/EDS 的名称前缀
+	ProxylessClusterPrefix = "proxyless"
+	// ServiceLbPolicyTag 服务元数据, 设置 proxyless grpc 客户端使用的负载均衡策略
+	ServiceLbPolicyTag = "polarismesh.cn/lb-policy"
+	// ServiceLbHashHeaderTag 服务元数据, 一致性 hash 场景下用于计算 hash 值的请求头
+	ServiceLbHashHeaderTag = "polarismesh.cn/lb-hash-header"
+	// grpcChannelIDFilterState grpc 支持的 filter_state key, 同一个 channel 的请求会落到同一个实例
+	grpcChannelIDFilterState = "io.grpc.channel_id"
+)
+
+// LbPolicy proxyless grpc 客户端的负载均衡策略
+type LbPolicy string
+
+const (
+	LbPolicyRoundRobin   LbPolicy = "roundRobin"
+	LbPolicyRingHash     LbPolicy = "ringHash"
+	LbPolicyLeastRequest LbPolicy = "leastRequest"
+)
+
+// GetServiceLbPolicy 从服务元数据中获取负载均衡策略, 未设置或者不支持时使用 roundRobin
+func GetServiceLbPolicy(svc *ServiceInfo) LbPolicy {
+	switch LbPolicy(svc.Metadata[ServiceLbPolicyTag]) {
+	case LbPolicyRingHash:
+		return LbPolicyRingHash
+	case LbPolicyLeastRequest:
+		return LbPolicyLeastRequest
+	default:
+		return LbPolicyRoundRobin
+	}
+}
+
+// MakeProxylessRouteConfigName .
+func MakeProxylessRouteConfigName(svcKey model.ServiceKey) string {
+	return ProxylessRouteConfigName + "|" + svcKey.Domain()
+}
+
+// MakeProxylessClusterName subset 为空时表示服务下的全部实例
+func MakeProxylessClusterName(svcKey model.ServiceKey, subset string) string {
+	name := fmt.Sprintf("%s|%s|%s", ProxylessClusterPrefix, svcKey.Namespace, svcKey.Name)
+	if subset == "" {
+		return name
+	}
+	return name + "|" + subset
+}
+
+// ProxylessSubset grpc 不支持 Envoy 的 LbSubsetConfig 以及 MetadataMatch, 路由目标中的实例标签需要转为独立的 cluster
+type ProxylessSubset struct {
+	// Name 由排序后的 key=value 拼接而成, 为空表示服务下的全部实例
+	Name   string
+	Labels map[string]string
+}
+
+// Match 判断实例是否属于该 subset
+func (s *ProxylessSubset) Match(ins *apiservice.Instance) bool {
+	for k, v := range s.Labels {
+		if ins.GetMetadata()[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// MakeProxylessSubset 根据路由目标的实例标签生成 subset
+func MakeProxylessSubset(dest *traffic_manage.DestinationGroup) *ProxylessSubset {
+	labels := make(map[string]string, len(dest.GetLabels()))
+	for k, v := range dest.GetLabels() {
+		if k == utils.MatchAll && v.GetValue().GetValue() == utils.MatchAll {
+			return &ProxylessSubset{Labels: map[string]string{}}
+		}
+		labels[k] = v.GetValue().GetValue()
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return &ProxylessSubset{
+		Name:   strings.Join(pairs, ","),
+		Labels: labels,
+	}
+}
+
+// ListProxylessSubsets 列出服务被调路由规则中引用到的所有 subset, 不包含服务本身
+func ListProxylessSubsets(svc *ServiceInfo) []*ProxylessSubset {
+	ret := make([]*ProxylessSubset, 0, 4)
+	exists := map[string]struct{}{}
+	for _, rule := range FilterInboundRouterRule(svc) {
+		for _, dest := range rule.GetDestinations() {
+			if dest.GetWeight() == 0 || !svc.MatchService(dest.GetNamespace(), dest.GetService()) {
+				continue
+			}
+			subset := MakeProxylessSubset(dest)
+			if subset.Name == "" {
+				continue
+			}
+			if _, ok := exists[subset.Name]; ok {
+				continue
+			}
+			exists[subset.Name] = struct{}{}
+			ret = append(ret, subset)
+		}
+	}
+	return ret
+}
+
+// MakeProxylessWeightClusters 将路由目标转为 subset cluster 的权重配置, 没有可用的目标时返回 nil
+func MakeProxylessWeightClusters(svc *ServiceInfo,
+	destinations []*traffic_manage.DestinationGroup) *route.WeightedCluster {
+	var (
+		weightedClusters []*route.WeightedCluster_ClusterWeight
+		totalWeight      uint32
+	)
+	for _, destination := range destinations {
+		if destination.GetWeight() == 0 {
+			continue
+		}
+		subset := MakeProxylessSubset(destination)
+		weightedClusters = append(weightedClusters, &route.WeightedCluster_ClusterWeight{
+			Name:   MakeProxylessClusterName(svc.ServiceKey, subset.Name),
+			Weight: utils.NewUInt32Value(destination.GetWeight()),
+		})
+		totalWeight += destination.GetWeight()
+	}
+	if totalWeight == 0 {
+		return nil
+	}
+	return &route.WeightedCluster{
+		TotalWeight: wrapperspb.UInt32(totalWeight),
+		Clusters:    weightedClusters,
+	}
+}
+
+// MakeProxylessHashPolicy 一致性 hash 场景下的 hash 计算方式, 未指定请求头时按照 grpc channel 计算
+func MakeProxylessHashPolicy(svc *ServiceInfo) []*route.RouteAction_HashPolicy {
+	if GetServiceLbPolicy(svc) != LbPolicyRingHash {
+		return nil
+	}
+	if header := svc.Metadata[ServiceLbHashHeaderTag]; header != "" {
+		return []*route.RouteAction_HashPolicy{
+			{
+				PolicySpecifier: &route.RouteAction_HashPolicy_Header_{
+					Header: &route.RouteAction_HashPolicy_Header{
+						HeaderName: header,
+					},
+				},
+			},
+		}
+	}
+	return []*route.RouteAction_HashPolicy{
+		{
+			PolicySpecifier: &route.RouteAction_HashPolicy_FilterState_{
+				FilterState: &route.RouteAction_HashPolicy_FilterState{
+					Key: grpcChannelIDFilterState,
+				},
+			},
+		},
+	}
+}
+
+// MakeProxylessLbPolicy 设置 cluster 的负载均衡策略, 只使用 grpc 支持的 ROUND_ROBIN/RING_HASH/LEAST_REQUEST
+func MakeProxylessLbPolicy(c *cluster.Cluster, svc *ServiceInfo) {
+	switch GetServiceLbPolicy(svc) {
+	case LbPolicyRingHash:
+		c.LbPolicy = cluster.Cluster_RING_HASH
+		c.LbConfig = &cluster.Cluster_RingHashLbConfig_{
+			RingHashLbConfig: &cluster.Cluster_RingHashLbConfig{
+				// grpc 只支持 XX_HASH
+				HashFunction: cluster.Cluster_RingHashLbConfig_XX_HASH,
+			},
+		}
+	case LbPolicyLeastRequest:
+		c.LbPolicy = cluster.Cluster_LEAST_REQUEST
+		c.LbConfig = &cluster.Cluster_LeastRequestLbConfig_{
+			LeastRequestLbConfig: &cluster.Cluster_LeastRequestLbConfig{
+				ChoiceCount: wrapperspb.UInt32(2),
+			},
+		}
+	default:
+		c.LbPolicy = cluster.Cluster_ROUND_ROBIN
+	}
+}
+
+// MakeProxylessOutlierDetection 将熔断规则转为 grpc 可以识别的 OutlierDetection
+func MakeProxylessOutlierDetection(svc *ServiceInfo) *cluster.OutlierDetection {
+	outlierDetection := MakeOutlierDetection(svc)
+	if outlierDetection == nil {
+		return nil
+	}
+	// grpc 会拒绝超过 100 的失败率阈值
+	if outlierDetection.GetFailurePercentageThreshold().GetValue() > 100 {
+		return nil
+	}
+	// grpc 不支持 consecutive_5xx, 只按照失败率进行实例摘除
+	outlierDetection.Consecutive_5Xx = nil
+	// enforcing_failure_percentage 默认为 0, grpc 会因此关闭失败率摘除
+	outlierDetection.EnforcingFailurePercentage = wrapperspb.UInt32(100)
+	outlierDetection.FailurePercentageMinimumHosts = wrapperspb.UInt32(1)
+	// enforcing_success_rate 未设置时 grpc 默认开启成功率摘除, 北极星的熔断规则没有对应的配置
+	outlierDetection.EnforcingSuccessRate = wrapperspb.UInt32(0)
+	if outlierDetection.GetInterval().AsDuration() <= 0 {
+		outlierDetection.Interval = nil
+	}
+	if outlierDetection.GetBaseEjectionTime().AsDuration() <= 0 {
+		outlierDetection.BaseEjectionTime = nil
+	}
+	return outlierDetection
+}
+
+// MakeProxylessHCM grpc API listener 使用的 HCM, 只能包含 router filter
+func MakeProxylessHCM(svcKey model.ServiceKey) *hcm.HttpConnectionManager {
+	return &hcm.HttpConnectionManager{
+		RouteSpecifier: &hcm.HttpConnectionManager_Rds{
+			Rds: &hcm.Rds{
+				ConfigSource: &core.ConfigSource{
+					ResourceApiVersion: core.ApiVersion_V3,
+					ConfigSourceSpecifier: &core.ConfigSource_Ads{
+						Ads: &core.AggregatedConfigSource{},
+					},
+				},
+				RouteConfigName: MakeProxylessRouteConfigName(svcKey),
+			},
+		},
+		HttpFilters: []*hcm.HttpFilter{
+			{
+				Name: wellknown.Router,
+				ConfigType: &hcm.HttpFilter_TypedConfig{
+					TypedConfig: MustNewAny(&routerv3.Router{}),
+				},
+			},
+		},
+	}
+}
+
+// MakeProxylessListenerNames grpc 使用 xds:///{target} 中的 target 作为 LDS 的资源名称
+func MakeProxylessListenerNames(svc *ServiceInfo) []string {
+	return GenerateServiceDomains(svc)
+}
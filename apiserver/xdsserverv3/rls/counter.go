@@ -0,0 +1,240 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package rls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mitchellh/mapstructure"
+	"go.uber.org/atomic"
+
+	"github.com/polarismesh/polaris/common/redispool"
+)
+
+const (
+	// CounterMemory 计数器保存在当前节点内存中，仅适用于单节点部署
+	CounterMemory = "memory"
+	// CounterRedis 计数器保存在 redis 中，由全部 Polaris 节点共享
+	CounterRedis = "redis"
+
+	counterKeyPrefix = "polaris_rls:"
+
+	// serverCheckInterval 内存计数器检查 Polaris 节点数量的间隔
+	serverCheckInterval = 10 * time.Second
+)
+
+// ErrMemoryCounterInCluster 多个 Polaris 节点时内存计数器只能统计到本节点收到的请求，全局限流的阈值会被放大
+var ErrMemoryCounterInCluster = errors.New("memory ratelimit counter can not be shared by multiple polaris servers, " +
+	"use redis counter instead")
+
+// Config 全局限流服务配置
+type Config struct {
+	// Counter 计数器类型，支持 memory、redis
+	Counter string `mapstructure:"counter"`
+	// Redis 计数器类型为 redis 时的连接配置，格式同 heartbeatRedis 插件
+	Redis map[string]interface{} `mapstructure:"redis"`
+}
+
+// ParseConfig 解析 xds-v3 option 中的 rateLimit 配置
+func ParseConfig(raw interface{}) (*Config, error) {
+	cfg := &Config{Counter: CounterMemory}
+	if raw == nil {
+		return cfg, nil
+	}
+	if err := mapstructure.Decode(raw, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Counter 固定窗口限流计数器
+type Counter interface {
+	// Incr 增加 key 在当前窗口内的计数，返回增加后的计数
+	Incr(ctx context.Context, key string, hits uint64, window time.Duration) (uint64, error)
+	// Available 计数器能否统计到全部 Polaris 节点收到的请求，不能时不对外提供全局限流
+	Available() bool
+}
+
+// ServerCounter 返回当前集群中 Polaris 节点的数量
+type ServerCounter func() int
+
+// NewCounter 根据配置创建计数器，servers 用于发现多节点部署，多节点时内存计数器不可用；
+// onChange 在计数器是否可用发生变化时回调
+func NewCounter(ctx context.Context, cfg *Config, servers ServerCounter,
+	onChange func(available bool)) (Counter, error) {
+	switch cfg.Counter {
+	case "", CounterMemory:
+		log.Warnf("[XDS][RLS] ratelimit counter is memory, global ratelimit is only served with a single " +
+			"polaris server, counter redis is required when polaris servers are deployed as a cluster")
+		return newMemoryCounter(ctx, servers, onChange), nil
+	case CounterRedis:
+		data, err := json.Marshal(cfg.Redis)
+		if err != nil {
+			return nil, err
+		}
+		redisCfg := redispool.DefaultConfig()
+		if err := json.Unmarshal(data, redisCfg); err != nil {
+			return nil, err
+		}
+		return &redisCounter{client: redispool.NewRedisClient(redisCfg)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported ratelimit counter: %s", cfg.Counter)
+	}
+}
+
+// windowKey 计数器在窗口内的 key，窗口切换后自动使用新的 key
+func windowKey(key string, window time.Duration, now time.Time) string {
+	start := now.Truncate(window).Unix()
+	return counterKeyPrefix + key + "|" + window.String() + "|" + strconv.FormatInt(start, 10)
+}
+
+type counterItem struct {
+	count    uint64
+	expireAt time.Time
+}
+
+// memoryCounter 内存计数器，发现多个 Polaris 节点后不可用
+type memoryCounter struct {
+	lock      sync.Mutex
+	items     map[string]*counterItem
+	now       func() time.Time
+	servers   ServerCounter
+	onChange  func(available bool)
+	clustered *atomic.Bool
+}
+
+func newMemoryCounter(ctx context.Context, servers ServerCounter, onChange func(available bool)) *memoryCounter {
+	c := &memoryCounter{
+		items:     map[string]*counterItem{},
+		now:       time.Now,
+		servers:   servers,
+		onChange:  onChange,
+		clustered: atomic.NewBool(false),
+	}
+	go c.cleanExpired(ctx)
+	if servers != nil {
+		c.clustered.Store(servers() > 1)
+		go c.watchServers(ctx)
+	}
+	return c
+}
+
+// Available 只有单个 Polaris 节点时内存计数器才可用
+func (c *memoryCounter) Available() bool {
+	return !c.clustered.Load()
+}
+
+func (c *memoryCounter) Incr(_ context.Context, key string, hits uint64, window time.Duration) (uint64, error) {
+	if c.clustered.Load() {
+		return 0, ErrMemoryCounterInCluster
+	}
+	now := c.now()
+	key = windowKey(key, window, now)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	item, ok := c.items[key]
+	if !ok {
+		item = &counterItem{expireAt: now.Truncate(window).Add(window)}
+		c.items[key] = item
+	}
+	item.count += hits
+	return item.count, nil
+}
+
+func (c *memoryCounter) cleanExpired(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			now := c.now()
+			c.lock.Lock()
+			for key, item := range c.items {
+				if !now.Before(item.expireAt) {
+					delete(c.items, key)
+				}
+			}
+			c.lock.Unlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// watchServers 有新的 Polaris 节点加入时，内存计数器不再计数，避免各节点按各自的计数放通请求
+func (c *memoryCounter) watchServers(ctx context.Context) {
+	ticker := time.NewTicker(serverCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.checkServers()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *memoryCounter) checkServers() {
+	num := c.servers()
+	clustered := num > 1
+	if c.clustered.Swap(clustered) == clustered {
+		return
+	}
+	if clustered {
+		log.Errorf("[XDS][RLS] found %d polaris servers, memory ratelimit counter is unavailable and global "+
+			"ratelimit is not served, set counter to redis", num)
+	} else {
+		log.Infof("[XDS][RLS] found %d polaris server, memory ratelimit counter is available again", num)
+	}
+	if c.onChange != nil {
+		c.onChange(!clustered)
+	}
+}
+
+// redisCounter redis 计数器，多个 Polaris 节点共享
+type redisCounter struct {
+	client redis.UniversalClient
+}
+
+// Available redis 计数器由全部节点共享，总是可用
+func (c *redisCounter) Available() bool {
+	return true
+}
+
+func (c *redisCounter) Incr(ctx context.Context, key string, hits uint64, window time.Duration) (uint64, error) {
+	key = windowKey(key, window, time.Now())
+	var incr *redis.IntCmd
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.IncrBy(ctx, key, int64(hits))
+		pipe.Expire(ctx, key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return uint64(incr.Val()), nil
+}
@@ -0,0 +1,256 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package rls
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	regexp "github.com/dlclark/regexp2"
+	ratelimitv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/common/ratelimit/v3"
+	rlsv3 "github.com/envoyproxy/go-control-plane/envoy/service/ratelimit/v3"
+	apitraffic "github.com/polarismesh/specification/source/go/api/v1/traffic_manage"
+	"golang.org/x/time/rate"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
+	types "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+const (
+	remoteAddressKey = "remote_address"
+	// errLogInterval 计数器异常的日志打印间隔，避免每个请求都打印一次
+	errLogInterval = time.Minute
+)
+
+// Server 基于 Polaris 全局限流规则实现 envoy.service.ratelimit.v3.RateLimitService
+type Server struct {
+	rlsv3.UnimplementedRateLimitServiceServer
+	rateLimitCache types.RateLimitCache
+	counter        Counter
+	now            func() time.Time
+	errLog         *rate.Sometimes
+}
+
+// NewServer 创建全局限流服务
+func NewServer(rateLimitCache types.RateLimitCache, counter Counter) *Server {
+	return &Server{
+		rateLimitCache: rateLimitCache,
+		counter:        counter,
+		now:            time.Now,
+		errLog:         &rate.Sometimes{Interval: errLogInterval},
+	}
+}
+
+// Available 计数器可用时才对外提供全局限流，Envoy 才需要开启 envoy.filters.http.ratelimit
+func (s *Server) Available() bool {
+	return s.counter.Available()
+}
+
+// ShouldRateLimit 对 envoy.filters.http.ratelimit 上报的每个 descriptor 进行计数
+func (s *Server) ShouldRateLimit(ctx context.Context,
+	req *rlsv3.RateLimitRequest) (*rlsv3.RateLimitResponse, error) {
+	hits := uint64(req.GetHitsAddend())
+	if hits == 0 {
+		hits = 1
+	}
+	resp := &rlsv3.RateLimitResponse{
+		OverallCode: rlsv3.RateLimitResponse_OK,
+		Statuses:    make([]*rlsv3.RateLimitResponse_DescriptorStatus, 0, len(req.GetDescriptors())),
+	}
+	if !s.Available() {
+		// Envoy 尚未收到关闭 RLS 的 LDS 时仍然会请求，直接放通
+		s.logError("[XDS][RLS] ratelimit counter is unavailable, allow request of domain %s", req.GetDomain())
+		for range req.GetDescriptors() {
+			resp.Statuses = append(resp.Statuses,
+				&rlsv3.RateLimitResponse_DescriptorStatus{Code: rlsv3.RateLimitResponse_OK})
+		}
+		return resp, nil
+	}
+	rules := s.findRules(req.GetDomain())
+	for _, descriptor := range req.GetDescriptors() {
+		status := s.checkDescriptor(ctx, rules, descriptor, hits)
+		if status.GetCode() == rlsv3.RateLimitResponse_OVER_LIMIT {
+			resp.OverallCode = rlsv3.RateLimitResponse_OVER_LIMIT
+		}
+		resp.Statuses = append(resp.Statuses, status)
+	}
+	return resp, nil
+}
+
+// logError 限制计数器异常日志的打印频率
+func (s *Server) logError(format string, args ...interface{}) {
+	s.errLog.Do(func() {
+		log.Errorf(format, args...)
+	})
+}
+
+// findRules domain 的格式为 <service>.<namespace>，服务名本身可能包含 '.'，因此从右往左逐个尝试
+func (s *Server) findRules(domain string) []*model.RateLimit {
+	for i := strings.LastIndex(domain, "."); i > 0; i = strings.LastIndex(domain[:i], ".") {
+		rules, _ := s.rateLimitCache.GetRateLimitRules(model.ServiceKey{
+			Namespace: domain[i+1:],
+			Name:      domain[:i],
+		})
+		if len(rules) > 0 {
+			return rules
+		}
+	}
+	return nil
+}
+
+func (s *Server) checkDescriptor(ctx context.Context, rules []*model.RateLimit,
+	descriptor *ratelimitv3.RateLimitDescriptor, hits uint64) *rlsv3.RateLimitResponse_DescriptorStatus {
+	ok := &rlsv3.RateLimitResponse_DescriptorStatus{Code: rlsv3.RateLimitResponse_OK}
+
+	entries := descriptor.GetEntries()
+	rule := findRule(rules, entries)
+	if rule == nil {
+		return ok
+	}
+	key, matched := counterKey(rule, entries)
+	if !matched {
+		return ok
+	}
+
+	now := s.now()
+	var ret *rlsv3.RateLimitResponse_DescriptorStatus
+	for _, amount := range rule.Proto.GetAmounts() {
+		window := amount.GetValidDuration().AsDuration()
+		maxAmount := amount.GetMaxAmount().GetValue()
+		if window <= 0 {
+			continue
+		}
+		count, err := s.counter.Incr(ctx, key, hits, window)
+		if err != nil {
+			// 计数器不可用时放通请求，避免影响业务流量
+			s.logError("[XDS][RLS] incr rule(%s) counter fail: %v", rule.ID, err)
+			continue
+		}
+		status := &rlsv3.RateLimitResponse_DescriptorStatus{
+			Code: rlsv3.RateLimitResponse_OK,
+			CurrentLimit: &rlsv3.RateLimitResponse_RateLimit{
+				Name:            rule.Name,
+				RequestsPerUnit: maxAmount,
+				Unit:            toUnit(window),
+			},
+			DurationUntilReset: durationpb.New(now.Truncate(window).Add(window).Sub(now)),
+		}
+		if count > uint64(maxAmount) {
+			status.Code = rlsv3.RateLimitResponse_OVER_LIMIT
+		} else {
+			status.LimitRemaining = maxAmount - uint32(count)
+		}
+		if ret == nil || betterStatus(status, ret) {
+			ret = status
+		}
+	}
+	if ret == nil {
+		return ok
+	}
+	return ret
+}
+
+// betterStatus 优先返回超限的状态，否则返回剩余配额最少的状态
+func betterStatus(a, b *rlsv3.RateLimitResponse_DescriptorStatus) bool {
+	if a.GetCode() != b.GetCode() {
+		return a.GetCode() == rlsv3.RateLimitResponse_OVER_LIMIT
+	}
+	return a.GetLimitRemaining() < b.GetLimitRemaining()
+}
+
+// findRule 根据 descriptor 中携带的规则 ID 查找生效中的全局限流规则
+func findRule(rules []*model.RateLimit, entries []*ratelimitv3.RateLimitDescriptor_Entry) *model.RateLimit {
+	var ruleID string
+	for _, entry := range entries {
+		if entry.GetKey() == resource.RateLimitRuleIDKey {
+			ruleID = entry.GetValue()
+			break
+		}
+	}
+	if ruleID == "" {
+		return nil
+	}
+	for _, rule := range rules {
+		if rule.ID != ruleID || rule.Proto == nil {
+			continue
+		}
+		if rule.Proto.GetDisable().GetValue() || rule.Proto.GetType() != apitraffic.Rule_GLOBAL {
+			return nil
+		}
+		return rule
+	}
+	return nil
+}
+
+// counterKey 校验 Envoy 无法在本地匹配的参数，例如请求方法以及调用方 IP，并生成计数器的 key.
+// 未开启 RegexCombine 时，这些参数的每个取值单独计数
+func counterKey(rule *model.RateLimit, entries []*ratelimitv3.RateLimitDescriptor_Entry) (string, bool) {
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		values[entry.GetKey()] = entry.GetValue()
+	}
+	key := rule.ID
+	combine := rule.Proto.GetRegexCombine().GetValue()
+	for _, arg := range rule.Proto.GetArguments() {
+		var entryKey string
+		switch arg.GetType() {
+		case apitraffic.MatchArgument_METHOD:
+			entryKey = strings.ToLower(arg.GetType().String()) + "." + arg.GetKey()
+		case apitraffic.MatchArgument_CALLER_IP:
+			entryKey = remoteAddressKey
+		default:
+			continue
+		}
+		value, ok := values[entryKey]
+		if !ok || !utils.MatchString(value, arg.GetValue(), compileRegex) {
+			return "", false
+		}
+		if !combine {
+			key += "|" + entryKey + "=" + value
+		}
+	}
+	return key, true
+}
+
+func compileRegex(s string) *regexp.Regexp {
+	regex, err := regexp.Compile(s, regexp.RE2)
+	if err != nil {
+		log.Errorf("[XDS][RLS] compile regex(%s) fail: %v", s, err)
+		return nil
+	}
+	return regex
+}
+
+func toUnit(window time.Duration) rlsv3.RateLimitResponse_RateLimit_Unit {
+	switch window {
+	case time.Second:
+		return rlsv3.RateLimitResponse_RateLimit_SECOND
+	case time.Minute:
+		return rlsv3.RateLimitResponse_RateLimit_MINUTE
+	case time.Hour:
+		return rlsv3.RateLimitResponse_RateLimit_HOUR
+	case 24 * time.Hour:
+		return rlsv3.RateLimitResponse_RateLimit_DAY
+	default:
+		return rlsv3.RateLimitResponse_RateLimit_UNKNOWN
+	}
+}
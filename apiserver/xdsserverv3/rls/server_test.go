@@ -0,0 +1,277 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package rls
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ratelimitv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/common/ratelimit/v3"
+	rlsv3 "github.com/envoyproxy/go-control-plane/envoy/service/ratelimit/v3"
+	"github.com/golang/mock/gomock"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apitraffic "github.com/polarismesh/specification/source/go/api/v1/traffic_manage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
+	"github.com/polarismesh/polaris/cache/mock"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+func newGlobalRule(id string, maxAmount uint32, args ...*apitraffic.MatchArgument) *model.RateLimit {
+	return &model.RateLimit{
+		ID:   id,
+		Name: id,
+		Proto: &apitraffic.Rule{
+			Id:        utils.NewStringValue(id),
+			Type:      apitraffic.Rule_GLOBAL,
+			Arguments: args,
+			Amounts: []*apitraffic.Amount{
+				{
+					MaxAmount:     utils.NewUInt32Value(maxAmount),
+					ValidDuration: durationpb.New(time.Second),
+				},
+			},
+		},
+	}
+}
+
+func newRequest(domain string, entries ...string) *rlsv3.RateLimitRequest {
+	descriptor := &ratelimitv3.RateLimitDescriptor{}
+	for i := 0; i+1 < len(entries); i += 2 {
+		descriptor.Entries = append(descriptor.Entries, &ratelimitv3.RateLimitDescriptor_Entry{
+			Key:   entries[i],
+			Value: entries[i+1],
+		})
+	}
+	return &rlsv3.RateLimitRequest{
+		Domain:      domain,
+		Descriptors: []*ratelimitv3.RateLimitDescriptor{descriptor},
+	}
+}
+
+func newTestServer(t *testing.T, svcKey model.ServiceKey, rules []*model.RateLimit) *Server {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	rateLimitCache := mock.NewMockRateLimitCache(ctrl)
+	rateLimitCache.EXPECT().GetRateLimitRules(gomock.Any()).DoAndReturn(
+		func(key model.ServiceKey) ([]*model.RateLimit, string) {
+			if key == svcKey {
+				return rules, ""
+			}
+			return nil, ""
+		}).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	counter := newMemoryCounter(ctx, nil, nil)
+	now := time.Unix(1700000000, 0)
+	counter.now = func() time.Time { return now }
+	svr := NewServer(rateLimitCache, counter)
+	svr.now = counter.now
+	return svr
+}
+
+func shouldRateLimit(t *testing.T, svr *Server, req *rlsv3.RateLimitRequest) *rlsv3.RateLimitResponse {
+	resp, err := svr.ShouldRateLimit(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Statuses, len(req.Descriptors))
+	return resp
+}
+
+func TestShouldRateLimit(t *testing.T) {
+	svcKey := model.ServiceKey{Namespace: "default", Name: "echo.server"}
+	disabled := newGlobalRule("disabled", 1)
+	disabled.Proto.Disable = utils.NewBoolValue(true)
+	local := newGlobalRule("local", 1)
+	local.Proto.Type = apitraffic.Rule_LOCAL
+	svr := newTestServer(t, svcKey, []*model.RateLimit{newGlobalRule("r1", 2), disabled, local})
+	domain := resource.MakeRateLimitDomain(svcKey)
+
+	req := newRequest(domain, ":path", "/", resource.RateLimitRuleIDKey, "r1")
+	resp := shouldRateLimit(t, svr, req)
+	assert.Equal(t, rlsv3.RateLimitResponse_OK, resp.OverallCode)
+	assert.Equal(t, uint32(1), resp.Statuses[0].LimitRemaining)
+	assert.Equal(t, uint32(2), resp.Statuses[0].CurrentLimit.RequestsPerUnit)
+	assert.Equal(t, rlsv3.RateLimitResponse_RateLimit_SECOND, resp.Statuses[0].CurrentLimit.Unit)
+
+	resp = shouldRateLimit(t, svr, req)
+	assert.Equal(t, rlsv3.RateLimitResponse_OK, resp.OverallCode)
+	assert.Equal(t, uint32(0), resp.Statuses[0].LimitRemaining)
+
+	resp = shouldRateLimit(t, svr, req)
+	assert.Equal(t, rlsv3.RateLimitResponse_OVER_LIMIT, resp.OverallCode)
+	assert.Equal(t, rlsv3.RateLimitResponse_OVER_LIMIT, resp.Statuses[0].Code)
+
+	// 不属于全局限流规则的 descriptor 直接放通
+	for _, id := range []string{"", "disabled", "local", "not-exist"} {
+		resp = shouldRateLimit(t, svr, newRequest(domain, ":path", "/", resource.RateLimitRuleIDKey, id))
+		assert.Equal(t, rlsv3.RateLimitResponse_OK, resp.OverallCode, id)
+		assert.Nil(t, resp.Statuses[0].CurrentLimit, id)
+	}
+
+	// 未知的 domain 直接放通
+	resp = shouldRateLimit(t, svr, newRequest("echo.server.other", resource.RateLimitRuleIDKey, "r1"))
+	assert.Equal(t, rlsv3.RateLimitResponse_OK, resp.OverallCode)
+}
+
+func TestShouldRateLimit_CallerIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		combine bool
+		// 每个 IP 依次请求一次后的结果
+		requests []string
+		want     []rlsv3.RateLimitResponse_Code
+	}{
+		{
+			name:     "per-ip",
+			requests: []string{"10.0.0.1", "10.0.0.2", "10.0.0.1", "10.0.1.1"},
+			want: []rlsv3.RateLimitResponse_Code{rlsv3.RateLimitResponse_OK, rlsv3.RateLimitResponse_OK,
+				rlsv3.RateLimitResponse_OVER_LIMIT, rlsv3.RateLimitResponse_OK},
+		},
+		{
+			name:     "combine",
+			combine:  true,
+			requests: []string{"10.0.0.1", "10.0.0.2", "10.0.1.1"},
+			want: []rlsv3.RateLimitResponse_Code{rlsv3.RateLimitResponse_OK,
+				rlsv3.RateLimitResponse_OVER_LIMIT, rlsv3.RateLimitResponse_OK},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svcKey := model.ServiceKey{Namespace: "default", Name: "echo"}
+			rule := newGlobalRule("r1", 1, &apitraffic.MatchArgument{
+				Type: apitraffic.MatchArgument_CALLER_IP,
+				Value: &apimodel.MatchString{
+					Type:  apimodel.MatchString_REGEX,
+					Value: utils.NewStringValue(`^10\.0\.0\.`),
+				},
+			})
+			rule.Proto.RegexCombine = utils.NewBoolValue(tt.combine)
+			svr := newTestServer(t, svcKey, []*model.RateLimit{rule})
+			for i, ip := range tt.requests {
+				resp := shouldRateLimit(t, svr, newRequest(resource.MakeRateLimitDomain(svcKey),
+					resource.RateLimitRuleIDKey, "r1", remoteAddressKey, ip))
+				assert.Equal(t, tt.want[i], resp.OverallCode, ip)
+			}
+		})
+	}
+}
+
+func TestMemoryCounter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	counter := newMemoryCounter(ctx, nil, nil)
+	now := time.Unix(1700000000, 0)
+	counter.now = func() time.Time { return now }
+
+	count, _ := counter.Incr(ctx, "k", 2, time.Second)
+	assert.Equal(t, uint64(2), count)
+	count, _ = counter.Incr(ctx, "k", 1, time.Second)
+	assert.Equal(t, uint64(3), count)
+	count, _ = counter.Incr(ctx, "k", 1, time.Minute)
+	assert.Equal(t, uint64(1), count)
+
+	// 进入下一个窗口后重新计数
+	now = now.Add(time.Second)
+	count, _ = counter.Incr(ctx, "k", 1, time.Second)
+	assert.Equal(t, uint64(1), count)
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, CounterMemory, cfg.Counter)
+
+	cfg, err = ParseConfig(map[interface{}]interface{}{
+		"counter": "redis",
+		"redis":   map[interface{}]interface{}{"kvAddr": "127.0.0.1:6379"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, CounterRedis, cfg.Counter)
+	counter, err := NewCounter(context.Background(), cfg, nil, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &redisCounter{}, counter)
+
+	_, err = NewCounter(context.Background(), &Config{Counter: "unknown"}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestMemoryCounter_MultipleServers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	servers := 2
+	serverCounter := func() int { return servers }
+	var changes []bool
+	onChange := func(available bool) { changes = append(changes, available) }
+	// 多个节点时不影响启动，只是不提供全局限流
+	counter, err := NewCounter(ctx, &Config{Counter: CounterMemory}, serverCounter, onChange)
+	assert.NoError(t, err)
+	assert.False(t, counter.Available())
+	_, err = counter.Incr(ctx, "k", 1, time.Second)
+	assert.ErrorIs(t, err, ErrMemoryCounterInCluster)
+
+	servers = 1
+	counter, err = NewCounter(ctx, &Config{Counter: CounterMemory}, serverCounter, onChange)
+	assert.NoError(t, err)
+	assert.True(t, counter.Available())
+	memCounter := counter.(*memoryCounter)
+	count, err := memCounter.Incr(ctx, "k", 1, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+
+	// 启动后加入了新的节点，内存计数器不可用
+	servers = 3
+	memCounter.checkServers()
+	memCounter.checkServers()
+	assert.False(t, memCounter.Available())
+	_, err = memCounter.Incr(ctx, "k", 1, time.Second)
+	assert.ErrorIs(t, err, ErrMemoryCounterInCluster)
+
+	servers = 1
+	memCounter.checkServers()
+	assert.True(t, memCounter.Available())
+	_, err = memCounter.Incr(ctx, "k", 1, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, true}, changes)
+}
+
+func TestShouldRateLimit_CounterUnavailable(t *testing.T) {
+	svcKey := model.ServiceKey{Namespace: "default", Name: "echo"}
+	svr := newTestServer(t, svcKey, []*model.RateLimit{newGlobalRule("r1", 1)})
+	domain := resource.MakeRateLimitDomain(svcKey)
+	req := newRequest(domain, ":path", "/", resource.RateLimitRuleIDKey, "r1")
+	shouldRateLimit(t, svr, req)
+	resp := shouldRateLimit(t, svr, req)
+	assert.Equal(t, rlsv3.RateLimitResponse_OVER_LIMIT, resp.OverallCode)
+
+	// 加入新的节点后内存计数器不可用，RLS 不再计数，直接放通
+	memCounter := svr.counter.(*memoryCounter)
+	memCounter.servers = func() int { return 2 }
+	memCounter.checkServers()
+	assert.False(t, svr.Available())
+	for i := 0; i < 3; i++ {
+		resp = shouldRateLimit(t, svr, req)
+		assert.Equal(t, rlsv3.RateLimitResponse_OK, resp.OverallCode)
+		assert.Equal(t, rlsv3.RateLimitResponse_OK, resp.Statuses[0].Code)
+	}
+}
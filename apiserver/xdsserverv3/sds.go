@@ -0,0 +1,205 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package xdsserverv3
+
+import (
+	"context"
+	"crypto/subtle"
+	"sync"
+	"time"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	tlstrans "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/ca"
+	xdscache "github.com/polarismesh/polaris/apiserver/xdsserverv3/cache"
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
+	"github.com/polarismesh/polaris/common/model"
+)
+
+const (
+	// secretRefreshInterval 检查根证书轮换以及工作负载证书到期的周期
+	secretRefreshInterval = time.Minute
+)
+
+// issuedSecret 已经下发给 Envoy Node 的证书信息
+type issuedSecret struct {
+	notAfter       time.Time
+	bundleRevision string
+}
+
+// ServiceTokenProvider 查询服务的 token，服务不存在时返回 false
+type ServiceTokenProvider func(svcKey model.ServiceKey) (string, bool)
+
+// secretManager 使用内置 CA 为开启 mTLS 的 Envoy Node 签发证书，并通过 SDS 下发。
+// xDS 连接本身不校验客户端身份，节点只有携带所属服务的 token 时才会签发该服务身份的证书
+type secretManager struct {
+	authority *ca.Authority
+	cache     *xdscache.ResourceCache
+	nodeMgr   *resource.XDSNodeManager
+	tokens    ServiceTokenProvider
+
+	lock sync.Mutex
+	// issued nodeId -> 已经下发的证书信息
+	issued map[string]*issuedSecret
+	// rejected 身份校验失败的 nodeId，每个节点只打印一次告警日志
+	rejected map[string]struct{}
+}
+
+func newSecretManager(authority *ca.Authority, cache *xdscache.ResourceCache,
+	nodeMgr *resource.XDSNodeManager, tokens ServiceTokenProvider) *secretManager {
+	return &secretManager{
+		authority: authority,
+		cache:     cache,
+		nodeMgr:   nodeMgr,
+		tokens:    tokens,
+		issued:    map[string]*issuedSecret{},
+		rejected:  map[string]struct{}{},
+	}
+}
+
+func (m *secretManager) run(ctx context.Context) {
+	ticker := time.NewTicker(secretRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.refresh()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refresh 根证书按需轮换后，为证书即将到期或者信任列表发生变化的 Envoy Node 重新签发证书
+func (m *secretManager) refresh() {
+	if err := m.authority.Refresh(); err != nil {
+		log.Errorf("[XDS][SDS] refresh ca root fail: %v", err)
+	}
+	alive := map[string]struct{}{}
+	for _, node := range m.nodeMgr.ListEnvoyNodes() {
+		alive[node.GetNodeID()] = struct{}{}
+		m.ensureNode(node)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for id := range m.issued {
+		if _, ok := alive[id]; !ok {
+			delete(m.issued, id)
+		}
+	}
+	for id := range m.rejected {
+		if _, ok := alive[id]; !ok {
+			delete(m.rejected, id)
+		}
+	}
+}
+
+// ensureNode 确保 Envoy Node 持有有效的证书
+func (m *secretManager) ensureNode(node *resource.XDSClient) {
+	if !resource.EnableTLS(node.TLSMode) {
+		return
+	}
+	svc := node.GetSelfServiceKey()
+	if svc.Namespace == "" || svc.Name == "" {
+		log.Debugf("[XDS][SDS] node(%s) without service identity, skip issue cert", node.GetNodeID())
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	id := node.GetNodeID()
+	if !m.authenticate(node, svc) {
+		// 服务 token 变更后不再为持有旧 token 的节点续签证书
+		delete(m.issued, id)
+		if _, ok := m.rejected[id]; !ok {
+			m.rejected[id] = struct{}{}
+			log.Warnf("[XDS][SDS] node(%s) without valid token of service(%s/%s), refuse to issue cert",
+				id, svc.Namespace, svc.Name)
+		}
+		return
+	}
+	delete(m.rejected, id)
+	_, revision := m.authority.TrustBundle()
+	if item, ok := m.issued[id]; ok && item.bundleRevision == revision &&
+		time.Until(item.notAfter) > m.authority.WorkloadRotateBefore() && m.cache.HasNodeSecrets(id) {
+		return
+	}
+
+	cert, err := m.authority.Issue(svc.Namespace, svc.Name)
+	if err != nil {
+		log.Errorf("[XDS][SDS] issue cert for node(%s) fail: %v", id, err)
+		return
+	}
+	req := xdscache.NewUpdateResourcesRequest()
+	req.Sds[id] = cachev3.IndexRawResourcesByName(makeSecrets(cert))
+	if err := m.cache.UpdateResources(context.Background(), req); err != nil {
+		log.Errorf("[XDS][SDS] update secrets for node(%s) fail: %v", id, err)
+		return
+	}
+	m.issued[id] = &issuedSecret{
+		notAfter:       cert.NotAfter,
+		bundleRevision: cert.BundleRevision,
+	}
+	log.Infof("[XDS][SDS] issue cert %s for node(%s), not after %s", ca.SpiffeID(svc.Namespace, svc.Name),
+		id, cert.NotAfter.Format(time.RFC3339))
+}
+
+// authenticate 节点声明的服务身份只有在携带该服务的 token 时才可信
+func (m *secretManager) authenticate(node *resource.XDSClient, svc model.ServiceKey) bool {
+	if node.ServiceToken == "" {
+		return false
+	}
+	token, ok := m.tokens(svc)
+	if !ok || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(node.ServiceToken)) == 1
+}
+
+func makeSecrets(cert *ca.WorkloadCert) []types.Resource {
+	return []types.Resource{
+		&tlstrans.Secret{
+			Name: resource.SDSDefaultSecretName,
+			Type: &tlstrans.Secret_TlsCertificate{
+				TlsCertificate: &tlstrans.TlsCertificate{
+					CertificateChain: &core.DataSource{
+						Specifier: &core.DataSource_InlineBytes{InlineBytes: cert.CertChain},
+					},
+					PrivateKey: &core.DataSource{
+						Specifier: &core.DataSource_InlineBytes{InlineBytes: cert.PrivateKey},
+					},
+				},
+			},
+		},
+		&tlstrans.Secret{
+			Name: resource.SDSRootCASecretName,
+			Type: &tlstrans.Secret_ValidationContext{
+				ValidationContext: &tlstrans.CertificateValidationContext{
+					TrustedCa: &core.DataSource{
+						Specifier: &core.DataSource_InlineBytes{InlineBytes: cert.TrustBundle},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,190 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package xdsserverv3
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	tlstrans "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/ca"
+	xdscache "github.com/polarismesh/polaris/apiserver/xdsserverv3/cache"
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin/crypto/aes"
+	"github.com/polarismesh/polaris/store"
+)
+
+type fakeCAStore struct {
+	lock  sync.Mutex
+	pairs map[uint64]*model.CAKeyPair
+}
+
+func (f *fakeCAStore) AddCAKeyPair(pair *model.CAKeyPair) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.pairs[pair.Generation]; ok {
+		return store.NewStatusError(store.DuplicateEntryErr, "duplicate")
+	}
+	copied := *pair
+	f.pairs[pair.Generation] = &copied
+	return nil
+}
+
+func (f *fakeCAStore) RetireCAKeyPair(generation uint64, retireTime time.Time) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if pair, ok := f.pairs[generation]; ok {
+		pair.RetireTime = retireTime
+	}
+	return nil
+}
+
+func (f *fakeCAStore) GetCAKeyPairs() ([]*model.CAKeyPair, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	ret := make([]*model.CAKeyPair, 0, len(f.pairs))
+	for _, pair := range f.pairs {
+		copied := *pair
+		ret = append(ret, &copied)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Generation < ret[j].Generation
+	})
+	return ret, nil
+}
+
+func (f *fakeCAStore) DeleteCAKeyPair(generation uint64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.pairs, generation)
+	return nil
+}
+
+func fetchSecrets(t *testing.T, xdsCache *xdscache.ResourceCache, node *core.Node) map[string]*tlstrans.Secret {
+	resp, err := xdsCache.Fetch(context.Background(), &cachev3.Request{
+		Node:    node,
+		TypeUrl: resourcev3.SecretType,
+	})
+	if err != nil {
+		return nil
+	}
+	ret := map[string]*tlstrans.Secret{}
+	for _, item := range resp.(*cachev3.RawResponse).Resources {
+		secret := item.Resource.(*tlstrans.Secret)
+		ret[secret.GetName()] = secret
+	}
+	return ret
+}
+
+func TestSecretManager(t *testing.T) {
+	dataKey, err := (&aes.AESCrypto{}).GenerateKey()
+	assert.NoError(t, err)
+	caConfig := ca.DefaultConfig()
+	caConfig.DataKey = base64.StdEncoding.EncodeToString(dataKey)
+	authority, err := ca.NewAuthority(caConfig, &fakeCAStore{pairs: map[uint64]*model.CAKeyPair{}},
+		&aes.AESCrypto{})
+	assert.NoError(t, err)
+
+	xdsCache := xdscache.NewResourceCache(nil)
+	nodeMgr := resource.NewXDSNodeManager()
+	tokens := map[model.ServiceKey]string{{Namespace: "default", Name: "echo"}: "echo-token"}
+	mgr := newSecretManager(authority, xdsCache, nodeMgr, func(svcKey model.ServiceKey) (string, bool) {
+		token, ok := tokens[svcKey]
+		return token, ok
+	})
+
+	newNode := func(id, service, token string) *core.Node {
+		fields := map[string]*structpb.Value{
+			resource.TLSModeTag:         structpb.NewStringValue(string(resource.TLSModeStrict)),
+			resource.SidecarServiceName: structpb.NewStringValue(service),
+		}
+		if token != "" {
+			fields[resource.ServiceTokenTag] = structpb.NewStringValue(token)
+		}
+		return &core.Node{Id: id, Metadata: &structpb.Struct{Fields: fields}}
+	}
+	strictNode := newNode("sidecar~default/echo-1~127.0.0.1", "echo", "echo-token")
+	plainNode := &core.Node{
+		Id: "sidecar~default/echo-2~127.0.0.2",
+		Metadata: &structpb.Struct{Fields: map[string]*structpb.Value{
+			resource.SidecarServiceName: structpb.NewStringValue("echo"),
+			resource.ServiceTokenTag:    structpb.NewStringValue("echo-token"),
+		}},
+	}
+	// 节点自行声明的服务身份没有对应的 token 时不签发证书
+	noTokenNode := newNode("sidecar~default/echo-3~127.0.0.3", "echo", "")
+	badTokenNode := newNode("sidecar~default/echo-4~127.0.0.4", "echo", "other-token")
+	unknownSvcNode := newNode("sidecar~default/admin-1~127.0.0.5", "admin", "echo-token")
+	nodeMgr.AddNodeIfAbsent(1, strictNode)
+	nodeMgr.AddNodeIfAbsent(2, plainNode)
+	nodeMgr.AddNodeIfAbsent(3, noTokenNode)
+	nodeMgr.AddNodeIfAbsent(4, badTokenNode)
+	nodeMgr.AddNodeIfAbsent(5, unknownSvcNode)
+
+	mgr.refresh()
+	assert.Nil(t, fetchSecrets(t, xdsCache, plainNode))
+	assert.Nil(t, fetchSecrets(t, xdsCache, noTokenNode))
+	assert.Nil(t, fetchSecrets(t, xdsCache, badTokenNode))
+	assert.Nil(t, fetchSecrets(t, xdsCache, unknownSvcNode))
+	// token 不会保留在节点的 metadata 中
+	assert.NotContains(t, nodeMgr.GetNode(strictNode.Id).Metadata, resource.ServiceTokenTag)
+	secrets := fetchSecrets(t, xdsCache, strictNode)
+	assert.Len(t, secrets, 2)
+
+	chain := secrets[resource.SDSDefaultSecretName].GetTlsCertificate().GetCertificateChain().GetInlineBytes()
+	block, _ := pem.Decode(chain)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+	assert.Equal(t, "spiffe://default/echo", leaf.URIs[0].String())
+	bundle := secrets[resource.SDSRootCASecretName].GetValidationContext().GetTrustedCa().GetInlineBytes()
+	pool := x509.NewCertPool()
+	assert.True(t, pool.AppendCertsFromPEM(bundle))
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	assert.NoError(t, err)
+
+	// 证书仍然有效时不会重复签发
+	mgr.refresh()
+	again := fetchSecrets(t, xdsCache, strictNode)
+	assert.Equal(t, chain, again[resource.SDSDefaultSecretName].GetTlsCertificate().GetCertificateChain().GetInlineBytes())
+
+	// 根证书轮换后重新签发, 信任列表同时包含新旧根证书
+	assert.NoError(t, authority.RotateRoot())
+	mgr.refresh()
+	rotated := fetchSecrets(t, xdsCache, strictNode)
+	assert.NotEqual(t, chain, rotated[resource.SDSDefaultSecretName].GetTlsCertificate().GetCertificateChain().GetInlineBytes())
+	pool = x509.NewCertPool()
+	assert.True(t, pool.AppendCertsFromPEM(rotated[resource.SDSRootCASecretName].GetValidationContext().GetTrustedCa().GetInlineBytes()))
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	assert.NoError(t, err)
+
+	// 证书不会通过调试接口暴露
+	assert.Empty(t, xdsCache.GetResources(resource.SDS, "default", strictNode.Id))
+}
@@ -24,11 +24,13 @@ import (
 	"strconv"
 	"time"
 
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	clusterservice "github.com/envoyproxy/go-control-plane/envoy/service/cluster/v3"
 	discoverygrpc "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	endpointservice "github.com/envoyproxy/go-control-plane/envoy/service/endpoint/v3"
 	healthservice "github.com/envoyproxy/go-control-plane/envoy/service/health/v3"
 	listenerservice "github.com/envoyproxy/go-control-plane/envoy/service/listener/v3"
+	rlsv3 "github.com/envoyproxy/go-control-plane/envoy/service/ratelimit/v3"
 	routeservice "github.com/envoyproxy/go-control-plane/envoy/service/route/v3"
 	runtimeservice "github.com/envoyproxy/go-control-plane/envoy/service/runtime/v3"
 	secretservice "github.com/envoyproxy/go-control-plane/envoy/service/secret/v3"
@@ -41,15 +43,20 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/polarismesh/polaris/apiserver"
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/ca"
 	xdscache "github.com/polarismesh/polaris/apiserver/xdsserverv3/cache"
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/extauthz"
 	"github.com/polarismesh/polaris/apiserver/xdsserverv3/resource"
+	"github.com/polarismesh/polaris/apiserver/xdsserverv3/rls"
 	"github.com/polarismesh/polaris/cache"
 	api "github.com/polarismesh/polaris/common/api/v1"
 	connlimit "github.com/polarismesh/polaris/common/conn/limit"
 	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/plugin"
 	"github.com/polarismesh/polaris/service"
 	"github.com/polarismesh/polaris/service/healthcheck"
+	"github.com/polarismesh/polaris/store"
 )
 
 type ResourceServer interface {
@@ -72,8 +79,13 @@ type XDSServer struct {
 	connLimitConfig *connlimit.Config
 
 	nodeMgr           *resource.XDSNodeManager
+	syncTracker       *xdscache.SyncTracker
 	registryInfo      *utils.AtomicValue[ServiceInfos]
 	resourceGenerator *XdsResourceGenerator
+	authority         *ca.Authority
+	secretMgr         *secretManager
+	rateLimitServer   *rls.Server
+	extAuthzServer    *extauthz.Server
 
 	active         *atomic.Bool
 	finishCtx      context.Context
@@ -90,6 +102,11 @@ func (x *XDSServer) Initialize(ctx context.Context, option map[string]interface{
 	x.listenIP = option["listenIP"].(string)
 	x.nodeMgr = resource.NewXDSNodeManager()
 	x.cache = xdscache.NewResourceCache(x)
+	x.syncTracker = xdscache.NewSyncTracker()
+	// 同一资源版本被多少个 Envoy 节点 NACK 后回退到最近一次被 ACK 的快照，0 表示不开启
+	if threshold, _ := option["nackQuarantineThreshold"].(int); threshold > 0 {
+		x.cache.SetQuarantineThreshold(threshold)
+	}
 	x.active = atomic.NewBool(false)
 	x.versionNum = atomic.NewUint64(0)
 	x.ctx = ctx
@@ -121,15 +138,115 @@ func (x *XDSServer) Initialize(ctx context.Context, option map[string]interface{
 		xdsNodesMgr:     x.nodeMgr,
 		svcInfoProvider: x.fetchCurrentServices,
 	}
+	rlsConfig, err := rls.ParseConfig(option["rateLimit"])
+	if err != nil {
+		return err
+	}
+	counter, err := rls.NewCounter(ctx, rlsConfig, func() int {
+		return len(x.healthSvr.ListCheckerServer())
+	}, x.onRateLimitServingChange)
+	if err != nil {
+		log.Errorf("[XDS][RLS] init ratelimit counter fail: %v", err)
+		return err
+	}
+	x.rateLimitServer = rls.NewServer(x.namingServer.Cache().RateLimit(), counter)
+	x.resourceGenerator.rateLimitServing = x.rateLimitServer.Available
+	if err := x.initExtAuthz(option["extAuthz"]); err != nil {
+		log.Errorf("[XDS][ExtAuthz] init ext_authz server fail: %v", err)
+		return err
+	}
+	if err := x.initCA(option["ca"]); err != nil {
+		log.Errorf("[XDS][CA] init built-in ca fail: %v", err)
+		return err
+	}
 	resource.Init()
 	return nil
 }
 
+// initExtAuthz 存储支持服务访问策略时开启 ext_authz 服务，存在策略的服务会在 INBOUND HCM 中开启 ext_authz 过滤器
+func (x *XDSServer) initExtAuthz(raw interface{}) error {
+	cfg, err := extauthz.ParseConfig(raw)
+	if err != nil {
+		return err
+	}
+	s, err := store.GetStore()
+	if err != nil {
+		return err
+	}
+	accessStore, ok := s.(store.ServiceAccessPolicyStore)
+	if !ok {
+		log.Warnf("[XDS][ExtAuthz] store %s not support service access policy, ext_authz disabled", s.Name())
+		return nil
+	}
+	x.extAuthzServer = extauthz.NewServer(cfg, accessStore)
+	if _, err := x.extAuthzServer.Refresh(); err != nil {
+		log.Errorf("[XDS][ExtAuthz] load service access policies fail: %v", err)
+	}
+	x.resourceGenerator.accessPolicyProvider = x.extAuthzServer.HasPolicies
+	x.resourceGenerator.callerHeader = cfg.CallerHeader
+	return nil
+}
+
+// onRateLimitServingChange RLS 是否提供全局限流发生变化后，为全部 Envoy Node 重新构建 LDS，开启或关闭 envoy.filters.http.ratelimit
+func (x *XDSServer) onRateLimitServingChange(available bool) {
+	log.Infof("[XDS][RLS] global ratelimit serving changed to %v, refresh lds of all envoy nodes", available)
+	x.resourceGenerator.refreshEnvoyNodesLDSBy(func(svcKey model.ServiceKey) bool {
+		return true
+	})
+}
+
+// onAccessPoliciesChange 服务访问策略发生变化后，为目标服务的 Envoy Node 重新构建 LDS
+func (x *XDSServer) onAccessPoliciesChange(changed map[model.ServiceKey]struct{}) {
+	x.resourceGenerator.refreshEnvoyNodesLDSBy(func(svcKey model.ServiceKey) bool {
+		_, ok := changed[svcKey]
+		return ok
+	})
+}
+
+// initCA 开启内置 CA 后，为 mTLS 模式的 Envoy Node 通过 SDS 下发证书
+func (x *XDSServer) initCA(raw interface{}) error {
+	cfg, err := ca.ParseConfig(raw)
+	if err != nil {
+		return err
+	}
+	if !cfg.Enable {
+		return nil
+	}
+	s, err := store.GetStore()
+	if err != nil {
+		return err
+	}
+	caStore, ok := s.(store.CAStore)
+	if !ok {
+		return fmt.Errorf("store %s not support built-in ca", s.Name())
+	}
+	crypto, err := plugin.GetCryptoManager().GetCrypto(cfg.CryptoAlgo)
+	if err != nil {
+		return err
+	}
+	x.authority, err = ca.NewAuthority(cfg, caStore, crypto)
+	if err != nil {
+		return err
+	}
+	x.secretMgr = newSecretManager(x.authority, x.cache, x.nodeMgr, x.getServiceToken)
+	x.resourceGenerator.builtinCA = true
+	return nil
+}
+
+// getServiceToken 内置 CA 签发证书前通过服务 token 校验 Envoy Node 声明的服务身份
+func (x *XDSServer) getServiceToken(svcKey model.ServiceKey) (string, bool) {
+	svc := x.namingServer.Cache().Service().GetServiceByName(svcKey.Name, svcKey.Namespace)
+	if svc == nil {
+		return "", false
+	}
+	return svc.Token, true
+}
+
 // Run 启动运行
 func (x *XDSServer) Run(errCh chan error) {
 	// 启动 grpc server
 	ctx := context.Background()
-	cb := xdscache.NewCallback(x.cache, x.nodeMgr)
+	cb := xdscache.NewCallback(x.cache, x.nodeMgr, x.syncTracker)
 	srv := serverv3.NewServer(ctx, x.cache, cb, sotw.WithOrderedADS())
 	var grpcOptions []grpc.ServerOption
 	grpcOptions = append(grpcOptions, grpc.MaxConcurrentStreams(1000))
@@ -155,6 +272,12 @@ func (x *XDSServer) Run(errCh chan error) {
 	}
 
 	registerServer(grpcServer, srv, x)
+	if x.secretMgr != nil {
+		go x.secretMgr.run(x.ctx)
+	}
+	if x.extAuthzServer != nil {
+		go x.extAuthzServer.Run(x.ctx, x.onAccessPoliciesChange)
+	}
 	log.Infof("management server listening on %d\n", x.listenPort)
 	if err = grpcServer.Serve(listener); err != nil {
 		log.Errorf("%v", err)
@@ -175,6 +298,10 @@ func registerServer(grpcServer *grpc.Server, server serverv3.Server, x *XDSServe
 	secretservice.RegisterSecretDiscoveryServiceServer(grpcServer, server)
 	runtimeservice.RegisterRuntimeDiscoveryServiceServer(grpcServer, server)
 	healthservice.RegisterHealthDiscoveryServiceServer(grpcServer, x)
+	rlsv3.RegisterRateLimitServiceServer(grpcServer, x.rateLimitServer)
+	if x.extAuthzServer != nil {
+		authv3.RegisterAuthorizationServer(grpcServer, x.extAuthzServer)
+	}
 }
 
 // Stop 停止服务
@@ -353,12 +480,14 @@ func (x *XDSServer) getRegistryInfoWithCache(ctx context.Context,
 		}
 
 		info := &resource.ServiceInfo{
-			ID:         value.ID,
-			Name:       value.Name,
-			Namespace:  value.Namespace,
-			ServiceKey: svcKey,
-			Instances:  []*apiservice.Instance{},
-			Ports:      value.ServicePorts,
+			ID:          value.ID,
+			Name:        value.Name,
+			Namespace:   value.Namespace,
+			ServiceKey:  svcKey,
+			Metadata:    value.Meta,
+			SvcRevision: value.Revision,
+			Instances:   []*apiservice.Instance{},
+			Ports:       value.ServicePorts,
 		}
 		registryInfo[value.Namespace][svcKey] = info
 		return true, nil
@@ -461,13 +590,23 @@ func (x *XDSServer) DebugHandlers() []model.DebugHandler {
 	return []model.DebugHandler{
 		{
 			Path:    "/debug/apiserver/xds/envoy_nodes",
-			Desc:    "Query the list of Envoy nodes, query parameter name is 'type', value is [sidecar, gateway]",
+			Desc:    "Query the list of Envoy nodes, query parameter name is 'type', value is [sidecar, gateway, proxyless]",
 			Handler: x.listXDSNodes,
 		},
 		{
 			Path:    "/debug/apiserver/xds/resources",
-			Desc:    "Query the list of Envoy nodes, eg. /debug/apiserver/xds/resources?type=&nodeId=, type is [eds,cds,rds,vhds,lds]",
+			Desc:    "Query the list of Envoy nodes, eg. /debug/apiserver/xds/resources?type=&nodeId=, type is [eds,cds,rds,vhds,lds], runType=proxyless for proxyless grpc resources",
 			Handler: x.listXDSResource,
 		},
+		{
+			Path:    "/debug/apiserver/xds/sync_status",
+			Desc:    "Query the xDS sync status of Envoy nodes, eg. /debug/apiserver/xds/sync_status?namespace=&nodeId=&status=, status is [SYNCED,STALE,NACKED,NOT_SENT]",
+			Handler: x.listXDSSyncStatus,
+		},
+		{
+			Path:    "/debug/apiserver/xds/ca_roots",
+			Desc:    "Query the root certificates of the built-in CA",
+			Handler: x.listCARoots,
+		},
 	}
 }
@@ -29,6 +29,7 @@ import (
 	"github.com/polarismesh/polaris/auth"
 	cachetypes "github.com/polarismesh/polaris/cache/api"
 	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/eventhub"
 	"github.com/polarismesh/polaris/common/model"
 	authcommon "github.com/polarismesh/polaris/common/model/auth"
 	"github.com/polarismesh/polaris/common/utils"
@@ -164,6 +165,10 @@ func (svr *Server) Login(req *apisecurity.LoginRequest) *apiservice.Response {
 
 // RecordHistory Server对外提供history插件的简单封装
 func (svr *Server) RecordHistory(entry *model.RecordEntry) {
+	// 数据已经写入存储，通知缓存按需刷新
+	if entry != nil {
+		eventhub.PublishCacheChange(entry.ResourceType)
+	}
 	plugin.GetHistory().Record(entry)
 }
 
@@ -134,8 +134,13 @@ func Start(configFilePath string) {
 	}
 
 	if err := polarisServiceRegister(&cfg.Bootstrap.PolarisService, cfg.APIServers); err != nil {
-		fmt.Printf("[ERROR] register polaris service fail: %v\n", err)
-		return
+		if store.CheckWritable() == nil {
+			fmt.Printf("[ERROR] register polaris service fail: %v\n", err)
+			return
+		}
+		// 从缓存快照启动后存储暂时只读，等待可写后再完成自注册
+		log.Warnf("[Bootstrap] store is read-only, register polaris service later: %v", err)
+		go registerWhenWritable(&cfg.Bootstrap.PolarisService, cfg.APIServers)
 	}
 	_ = FinishBootstrapOrder(tx) // 启动完成，解锁
 	fmt.Println("finish starting server")
@@ -240,6 +245,7 @@ func StartDiscoverComponents(ctx context.Context, cfg *boot_config.Config, s sto
 		ClientRegister:   namingBatchConfig.ClientRegister,
 		ClientDeregister: namingBatchConfig.ClientDeregister,
 		Heartbeat:        healthBatchConfig.Heartbeat,
+		Report:           healthBatchConfig.Report,
 	}
 
 	bc, err := batch.NewBatchCtrlWithConfig(s, cacheMgn, batchConfig)
@@ -558,6 +564,24 @@ func polarisServiceRegister(polarisService *boot_config.PolarisService, apiServe
 	return nil
 }
 
+// registerWhenWritable 等待存储可写后重新进行服务自注册
+func registerWhenWritable(polarisService *boot_config.PolarisService, apiServers []apiserver.Config) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		if store.CheckWritable() != nil {
+			continue
+		}
+		SelfServiceInstance = nil
+		if err := polarisServiceRegister(polarisService, apiServers); err != nil {
+			log.Errorf("[Bootstrap] register polaris service fail: %v", err)
+			continue
+		}
+		log.Infof("[Bootstrap] register polaris service success")
+		return
+	}
+}
+
 // selfRegister 服务自注册
 func selfRegister(
 	host string, port uint32, protocol string, isolated bool, polarisService *boot_config.Service, hbInterval int) error {
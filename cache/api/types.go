@@ -115,6 +115,15 @@ type Cache interface {
 	Close() error
 }
 
+// SnapshotCache 支持本地快照的缓存，快照中保存的是从存储拉取到的原始数据
+type SnapshotCache interface {
+	Cache
+	// DumpSnapshot 导出缓存中的数据，以及这批数据对应的从存储拉取数据的时间
+	DumpSnapshot() (interface{}, time.Time)
+	// LoadSnapshot 使用快照数据填充缓存，之后从 fetchTime 开始增量拉取存储中的数据
+	LoadSnapshot(data []byte, fetchTime time.Time) error
+}
+
 // ConfigEntry 单个缓存资源配置
 type ConfigEntry struct {
 	Name   string                 `yaml:"name"`
@@ -856,6 +865,27 @@ func (bc *BaseCache) DoCacheUpdate(name string, executor func() (map[string]time
 	return nil
 }
 
+// DoSnapshotLoad 使用快照数据填充缓存，加载完成后和正常的增量更新一样，从 fetchTime 开始拉取存储中的变更
+func (bc *BaseCache) DoSnapshotLoad(name string, fetchTime time.Time,
+	executor func() (map[string]time.Time, int64, error)) error {
+	start := time.Now()
+	lastMtimes, total, err := executor()
+	if err != nil {
+		return err
+	}
+
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+	if len(lastMtimes) != 0 {
+		bc.lastMtimes = lastMtimes
+	}
+	bc.lastFetchTime = fetchTime.Unix()
+	bc.firstUpdate = false
+	log.Infof("[Cache][%s] load %d items from snapshot, fetch time %s, used %s",
+		name, total, fetchTime, time.Since(start))
+	return nil
+}
+
 func (bc *BaseCache) Clear() {
 	bc.lock.Lock()
 	defer bc.lock.Unlock()
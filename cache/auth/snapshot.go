@@ -0,0 +1,101 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package auth
+
+import (
+	"encoding/json"
+	"time"
+
+	types "github.com/polarismesh/polaris/cache/api"
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+)
+
+var (
+	_ types.SnapshotCache = (*userCache)(nil)
+	_ types.SnapshotCache = (*policyCache)(nil)
+	_ types.SnapshotCache = (*roleCache)(nil)
+)
+
+// userSnapshot 用户以及用户组快照
+type userSnapshot struct {
+	Users  []*authcommon.User            `json:"users"`
+	Groups []*authcommon.UserGroupDetail `json:"groups"`
+}
+
+// DumpSnapshot 导出用户以及用户组快照
+func (uc *userCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := uc.OriginLastFetchTime()
+	return &userSnapshot{
+		Users:  uc.users.Values(),
+		Groups: uc.groups.Values(),
+	}, fetchTime
+}
+
+// LoadSnapshot 加载用户以及用户组快照
+func (uc *userCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	snapshot := &userSnapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return err
+	}
+	return uc.DoSnapshotLoad(uc.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		lastMimes, _ := uc.setUserAndGroups(snapshot.Users, snapshot.Groups)
+		return lastMimes, int64(len(snapshot.Users) + len(snapshot.Groups)), nil
+	})
+}
+
+// DumpSnapshot 导出鉴权策略快照
+func (sc *policyCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := sc.OriginLastFetchTime()
+	ret := make([]*authcommon.StrategyDetail, 0, sc.rules.Len())
+	sc.rules.ReadRange(func(_ string, val *authcommon.PolicyDetailCache) {
+		ret = append(ret, val.StrategyDetail)
+	})
+	return ret, fetchTime
+}
+
+// LoadSnapshot 加载鉴权策略快照
+func (sc *policyCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	var strategies []*authcommon.StrategyDetail
+	if err := json.Unmarshal(data, &strategies); err != nil {
+		return err
+	}
+	return sc.DoSnapshotLoad(sc.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		lastMtimes, _, _, _ := sc.setStrategys(strategies)
+		return lastMtimes, int64(len(strategies)), nil
+	})
+}
+
+// DumpSnapshot 导出角色快照
+func (r *roleCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := r.OriginLastFetchTime()
+	return r.roles.Values(), fetchTime
+}
+
+// LoadSnapshot 加载角色快照
+func (r *roleCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	var roles []*authcommon.Role
+	if err := json.Unmarshal(data, &roles); err != nil {
+		return err
+	}
+	return r.DoSnapshotLoad(r.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		lastMtime, _, _, _ := r.setRoles(roles)
+		return map[string]time.Time{
+			r.Name(): lastMtime,
+		}, int64(len(roles)), nil
+	})
+}
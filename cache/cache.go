@@ -20,10 +20,13 @@ package cache
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	types "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/metrics"
+	"github.com/polarismesh/polaris/common/model/admin"
 	"github.com/polarismesh/polaris/common/utils"
 	"github.com/polarismesh/polaris/store"
 )
@@ -46,6 +49,12 @@ type CacheManager struct {
 	storage  store.Store
 	caches   []types.Cache
 	needLoad *utils.SyncSet[string]
+	notifier *changeNotifier
+	snapshot *snapshotter
+	// unsynced 数据尚未和存储完成同步的缓存，存在这类缓存时认为处于降级状态
+	unsynced *utils.SyncSet[string]
+	// reconciling 从快照启动后尚未和存储完成首次对账的缓存，全部完成对账之前存储只读
+	reconciling *utils.SyncSet[string]
 }
 
 // Initialize 缓存对象初始化
@@ -93,7 +102,7 @@ func (nc *CacheManager) warmUp() error {
 		wg.Add(1)
 		go func(c types.Cache) {
 			defer wg.Done()
-			_ = c.Update()
+			nc.update(c)
 		}(nc.caches[index])
 	}
 
@@ -114,6 +123,9 @@ func (nc *CacheManager) clear() error {
 
 // Close 关闭所有的 Cache 缓存
 func (nc *CacheManager) Close() error {
+	if nc.notifier != nil {
+		nc.notifier.stop()
+	}
 	for _, obj := range nc.caches {
 		if err := obj.Close(); err != nil {
 			return err
@@ -126,39 +138,182 @@ func (nc *CacheManager) Close() error {
 func (nc *CacheManager) Start(ctx context.Context) error {
 	log.Infof("[Cache] cache goroutine start")
 
-	// 启动的时候，先更新一版缓存
-	log.Infof("[Cache] cache update now first time")
-	if err := nc.warmUp(); err != nil {
-		return err
+	entries := nc.needLoad.ToSlice()
+	triggers := map[string]<-chan struct{}{}
+	if config != nil && config.ChangeNotify.Open {
+		// 先订阅资源变更通知再加载缓存，加载期间发生的变更会在加载完成后再刷新一次
+		nc.notifier = newChangeNotifier(config.ChangeNotify, nc.storage)
+		for _, name := range entries {
+			if isNotifiedCache(name) {
+				triggers[name] = nc.notifier.register(name)
+			}
+		}
+		if err := nc.notifier.start(ctx); err != nil {
+			return err
+		}
+	}
+
+	var loaded []string
+	if config != nil && config.Snapshot.Open {
+		nc.snapshot = newSnapshotter(config.Snapshot)
+		loaded = nc.snapshot.load(nc.snapshotCaches())
+	}
+	if len(loaded) == 0 {
+		// 启动的时候，先更新一版缓存
+		log.Infof("[Cache] cache update now first time")
+		if err := nc.warmUp(); err != nil {
+			return err
+		}
+		log.Infof("[Cache] cache update done")
+	} else {
+		// 已经从本地快照恢复了数据，不再阻塞等待存储，各个缓存在自己的协程中和存储完成对账
+		log.Infof("[Cache] cache loaded from snapshot, serve in degraded mode until synchronized with store")
+		nc.reconciling = utils.NewSyncSet[string]()
+		for _, name := range entries {
+			nc.unsynced.Add(name)
+			nc.reconciling.Add(name)
+		}
+		// 快照中的数据可能已经过期，首次对账完成之前拒绝写入
+		store.SetReadOnly(true)
+	}
+	for _, name := range entries {
+		metrics.ReportCacheDegraded(name, nc.unsynced.Contains(name))
 	}
-	log.Infof("[Cache] cache update done")
 
 	// 启动协程，开始定时更新缓存数据
-	entries := nc.needLoad.ToSlice()
 	for i := range entries {
 		name := entries[i]
 		index, exist := cacheSet[name]
 		if !exist {
 			return fmt.Errorf("cache resource %s not exists", name)
 		}
+		interval := nc.GetUpdateCacheInterval()
+		trigger, notified := triggers[name]
+		if notified {
+			// 能够收到变更通知的缓存按需刷新，定时刷新仅作为兜底
+			interval = nc.notifier.cfg.SafetyInterval
+		}
 		// 每个缓存各自在自己的协程内部按照期望的缓存更新时间完成数据缓存刷新
-		go func(c types.Cache) {
-			ticker := time.NewTicker(nc.GetUpdateCacheInterval())
-			for {
-				select {
-				case <-ticker.C:
-					_ = c.Update()
-				case <-ctx.Done():
-					ticker.Stop()
-					return
-				}
-			}
-		}(nc.caches[index])
+		go nc.runUpdate(ctx, nc.caches[index], interval, trigger, len(loaded) != 0)
+	}
+	if nc.snapshot != nil {
+		go nc.snapshot.run(ctx, nc)
 	}
 
 	return nil
 }
 
+// runUpdate 定时刷新缓存，trigger 收到资源变更通知时立即刷新，syncNow 为 true 时立即和存储对账一次
+func (nc *CacheManager) runUpdate(ctx context.Context, c types.Cache, interval time.Duration,
+	trigger <-chan struct{}, syncNow bool) {
+	if syncNow {
+		nc.update(c)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			nc.update(c)
+		case <-trigger:
+			nc.update(c)
+			// 间隔期内的变更通知合并为下一次刷新
+			select {
+			case <-time.After(nc.notifier.cfg.MinInterval):
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// syncLock 保证缓存的同步状态和存储的只读状态一起变更
+var syncLock sync.Mutex
+
+// update 刷新缓存，并记录缓存和存储的同步状态。运行期间刷新失败只标记降级，不会让存储只读
+func (nc *CacheManager) update(c types.Cache) {
+	err := c.Update()
+	name := c.Name()
+	synced := err == nil
+	syncLock.Lock()
+	defer syncLock.Unlock()
+	if synced {
+		nc.finishReconcile(name)
+	}
+	// 同步状态没有发生变化
+	if synced == !nc.unsynced.Contains(name) {
+		return
+	}
+	if synced {
+		nc.unsynced.Remove(name)
+		log.Infof("[Cache][%s] synchronized with store", name)
+	} else {
+		nc.unsynced.Add(name)
+		log.Warnf("[Cache][%s] fail to synchronize with store, keep serving cached data, err: %v", name, err)
+	}
+	metrics.ReportCacheDegraded(name, !synced)
+}
+
+// finishReconcile 记录缓存完成了从快照启动后的首次对账，全部缓存完成后解除存储只读
+func (nc *CacheManager) finishReconcile(name string) {
+	if nc.reconciling == nil || !nc.reconciling.Contains(name) {
+		return
+	}
+	nc.reconciling.Remove(name)
+	if nc.reconciling.Len() == 0 {
+		nc.reconciling = nil
+		store.SetReadOnly(false)
+		log.Infof("[Cache] all caches reconciled with store after booting from snapshot, store is writable")
+	}
+}
+
+// IsDegraded 是否存在尚未和存储完成同步的缓存
+func (nc *CacheManager) IsDegraded() bool {
+	return nc.unsynced.Len() != 0
+}
+
+// GetCacheStatus 获取缓存和存储的同步状态
+func (nc *CacheManager) GetCacheStatus() *admin.CacheStatus {
+	unsynced := nc.unsynced.ToSlice()
+	sort.Strings(unsynced)
+	status := &admin.CacheStatus{
+		Degraded:       len(unsynced) != 0,
+		UnsyncedCaches: unsynced,
+	}
+	if nc.snapshot != nil {
+		if loadTime := nc.snapshot.loadTime; !loadTime.IsZero() {
+			status.SnapshotLoadTime = &loadTime
+		}
+		if dumpTime := nc.snapshot.dumpTime.Load(); !dumpTime.IsZero() {
+			status.SnapshotDumpTime = &dumpTime
+		}
+	}
+	return status
+}
+
+// snapshotCaches 已开启并且支持本地快照的缓存，命名空间先于服务、服务先于实例加载
+func (nc *CacheManager) snapshotCaches() []types.SnapshotCache {
+	ret := make([]types.SnapshotCache, 0, len(nc.caches))
+	appendCache := func(c types.Cache) {
+		if c == nil || !nc.needLoad.Contains(c.Name()) {
+			return
+		}
+		if sc, ok := c.(types.SnapshotCache); ok {
+			ret = append(ret, sc)
+		}
+	}
+	appendCache(nc.caches[types.CacheNamespace])
+	for i := range nc.caches {
+		if types.CacheIndex(i) == types.CacheNamespace {
+			continue
+		}
+		appendCache(nc.caches[i])
+	}
+	return ret
+}
+
 // Clear 主动清除缓存数据
 func (nc *CacheManager) Clear() error {
 	return nc.clear()
@@ -0,0 +1,256 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	types "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/eventhub"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	// changeLogPageSize 单次拉取的变更日志条数
+	changeLogPageSize = 1000
+	// changeLogCleanInterval 清理过期变更日志的周期
+	changeLogCleanInterval = time.Minute
+)
+
+// resourceCaches 资源发生变更时需要刷新的缓存，不在其中的缓存（client、l5）仍然按照 UpdateCacheInterval 定时刷新
+var resourceCaches = map[model.Resource][]string{
+	model.RNamespace:          {types.NamespaceName},
+	model.RService:            {types.ServiceName},
+	model.RInstance:           {types.InstanceName},
+	model.RRouting:            {types.RoutingConfigName},
+	model.RRateLimit:          {types.RateLimitConfigName},
+	model.RCircuitBreaker:     {types.CircuitBreakerName},
+	model.RCircuitBreakerRule: {types.CircuitBreakerName},
+	model.RFaultDetectRule:    {types.FaultDetectRuleName},
+	model.RServiceContract:    {types.ServiceContractName},
+	model.RLaneGroup:          {types.LaneRuleName},
+	model.RLaneRule:           {types.LaneRuleName},
+	model.RConfigGroup:        {types.ConfigGroupCacheName},
+	model.RConfigFile:         {types.ConfigFileCacheName},
+	model.RConfigFileRelease:  {types.ConfigFileCacheName, types.GrayName},
+	// 删除用户、用户组时会一并删除其默认鉴权策略
+	model.RUser:              {types.UsersName, types.StrategyRuleName},
+	model.RUserGroup:         {types.UsersName, types.StrategyRuleName},
+	model.RUserGroupRelation: {types.UsersName},
+	model.RAuthStrategy:      {types.StrategyRuleName},
+	model.RAuthRole:          {types.RolesName},
+}
+
+// isNotifiedCache 缓存是否能够通过资源变更通知触发刷新
+func isNotifiedCache(name string) bool {
+	for _, names := range resourceCaches {
+		for _, item := range names {
+			if item == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// changeNotifier 接收资源变更通知并触发对应缓存刷新
+// 本节点的写操作通过 eventhub 通知，其他节点的写操作通过存储中的变更日志感知
+type changeNotifier struct {
+	cfg      ChangeNotifyConfig
+	logStore store.ChangeLogStore
+	// server 写入变更日志时的节点标识，轮询时忽略本节点写入的日志
+	server   string
+	triggers map[string]chan struct{}
+	subCtx   *eventhub.SubscribtionContext
+
+	lock sync.Mutex
+	// pending 等待写入变更日志的资源
+	pending map[model.Resource]struct{}
+	// lastID 已经处理过的最大变更日志 ID
+	lastID    uint64
+	watching  bool
+	lastClean time.Time
+}
+
+func newChangeNotifier(cfg ChangeNotifyConfig, s store.Store) *changeNotifier {
+	cfg.fillDefault()
+	n := &changeNotifier{
+		cfg:      cfg,
+		server:   fmt.Sprintf("%s:%d", utils.LocalHost, os.Getpid()),
+		triggers: map[string]chan struct{}{},
+		pending:  map[model.Resource]struct{}{},
+	}
+	// 只有多个节点共享的存储才需要变更日志，boltdb 单机部署不需要
+	if logStore, ok := s.(store.ChangeLogStore); ok {
+		n.logStore = logStore
+	}
+	return n
+}
+
+// register 注册缓存的刷新通知
+func (n *changeNotifier) register(name string) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	n.triggers[name] = ch
+	return ch
+}
+
+// start 订阅本节点的变更通知，并开始轮询存储中的变更日志
+func (n *changeNotifier) start(ctx context.Context) error {
+	subCtx, err := eventhub.SubscribeWithFunc(eventhub.CacheChangeEventTopic, n.onChangeEvent)
+	if err != nil {
+		return err
+	}
+	n.subCtx = subCtx
+	if n.logStore != nil {
+		// 在缓存首次全量加载之前记录变更日志的位置，避免遗漏加载期间其他节点的变更
+		n.initLastID()
+		go n.watch(ctx)
+	}
+	return nil
+}
+
+func (n *changeNotifier) initLastID() bool {
+	lastID, err := n.logStore.GetLatestChangeLogID()
+	if err != nil {
+		log.Errorf("[Cache] get latest change log id fail: %s", err.Error())
+		return false
+	}
+	n.lastID = lastID
+	n.watching = true
+	return true
+}
+
+func (n *changeNotifier) stop() {
+	if n.subCtx != nil {
+		n.subCtx.Cancel()
+	}
+}
+
+func (n *changeNotifier) onChangeEvent(_ context.Context, event any) error {
+	changeEvent, ok := event.(*eventhub.CacheChangeEvent)
+	if !ok {
+		return nil
+	}
+	n.notify(changeEvent.Resource)
+	if n.logStore != nil {
+		n.lock.Lock()
+		n.pending[changeEvent.Resource] = struct{}{}
+		n.lock.Unlock()
+	}
+	return nil
+}
+
+// notify 触发资源对应的缓存刷新，缓存正在刷新时最多合并保留一次通知
+func (n *changeNotifier) notify(resource model.Resource) {
+	for _, name := range resourceCaches[resource] {
+		ch, ok := n.triggers[name]
+		if !ok {
+			continue
+		}
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (n *changeNotifier) watch(ctx context.Context) {
+	ticker := time.NewTicker(n.cfg.WatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			n.flush()
+			n.pull()
+			n.clean()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// flush 将本节点的变更写入变更日志，同一个周期内同类资源的多次变更只写入一条
+func (n *changeNotifier) flush() {
+	n.lock.Lock()
+	if len(n.pending) == 0 {
+		n.lock.Unlock()
+		return
+	}
+	logs := make([]*model.ChangeLog, 0, len(n.pending))
+	for resource := range n.pending {
+		logs = append(logs, &model.ChangeLog{Resource: resource, Server: n.server})
+	}
+	n.pending = map[model.Resource]struct{}{}
+	n.lock.Unlock()
+
+	if err := n.logStore.AppendChangeLogs(logs); err != nil {
+		// 写入失败时其他节点只能依赖兜底的定时刷新
+		log.Errorf("[Cache] append %d change logs fail: %s", len(logs), err.Error())
+	}
+}
+
+// pull 拉取其他节点写入的变更日志并触发缓存刷新
+// 自增 ID 的提交顺序与分配顺序可能不一致，极端情况下遗漏的变更由兜底的定时刷新处理
+func (n *changeNotifier) pull() {
+	if !n.watching {
+		_ = n.initLastID()
+		return
+	}
+	changed := map[model.Resource]struct{}{}
+	for {
+		logs, err := n.logStore.GetChangeLogs(n.lastID, changeLogPageSize)
+		if err != nil {
+			log.Errorf("[Cache] get change logs after %d fail: %s", n.lastID, err.Error())
+			break
+		}
+		for _, item := range logs {
+			n.lastID = item.ID
+			if item.Server != n.server {
+				changed[item.Resource] = struct{}{}
+			}
+		}
+		if len(logs) < changeLogPageSize {
+			break
+		}
+	}
+	for resource := range changed {
+		n.notify(resource)
+	}
+}
+
+// clean 清理过期的变更日志，各个节点都会执行，删除操作是幂等的
+func (n *changeNotifier) clean() {
+	if time.Since(n.lastClean) < changeLogCleanInterval {
+		return
+	}
+	n.lastClean = time.Now()
+	count, err := n.logStore.CleanChangeLogs(n.cfg.Retention)
+	if err != nil {
+		log.Errorf("[Cache] clean change logs fail: %s", err.Error())
+		return
+	}
+	if count > 0 {
+		log.Infof("[Cache] clean %d expired change logs", count)
+	}
+}
@@ -0,0 +1,126 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	types "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/eventhub"
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store/mock"
+)
+
+// changeLogStore 在内存中记录变更日志的 mock store
+type changeLogStore struct {
+	*mock.MockStore
+	logs []*model.ChangeLog
+}
+
+func (s *changeLogStore) AppendChangeLogs(logs []*model.ChangeLog) error {
+	for _, item := range logs {
+		item.ID = uint64(len(s.logs) + 1)
+		s.logs = append(s.logs, item)
+	}
+	return nil
+}
+
+func (s *changeLogStore) GetChangeLogs(lastID uint64, limit uint32) ([]*model.ChangeLog, error) {
+	ret := make([]*model.ChangeLog, 0, limit)
+	for _, item := range s.logs {
+		if item.ID > lastID && len(ret) < int(limit) {
+			ret = append(ret, item)
+		}
+	}
+	return ret, nil
+}
+
+func (s *changeLogStore) GetLatestChangeLogID() (uint64, error) {
+	return uint64(len(s.logs)), nil
+}
+
+func (s *changeLogStore) CleanChangeLogs(retention time.Duration) (uint64, error) {
+	return 0, nil
+}
+
+func triggered(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestChangeNotifier_Notify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	n := newChangeNotifier(ChangeNotifyConfig{Open: true}, mock.NewMockStore(ctrl))
+	assert.Nil(t, n.logStore)
+	assert.Equal(t, DefaultSafetyInterval, n.cfg.SafetyInterval)
+
+	files := n.register(types.ConfigFileCacheName)
+	gray := n.register(types.GrayName)
+	instances := n.register(types.InstanceName)
+
+	n.notify(model.RConfigFileRelease)
+	// 多次通知合并为一次刷新
+	n.notify(model.RConfigFileRelease)
+	assert.True(t, triggered(files))
+	assert.False(t, triggered(files))
+	assert.True(t, triggered(gray))
+	assert.False(t, triggered(instances))
+
+	assert.True(t, isNotifiedCache(types.InstanceName))
+	assert.False(t, isNotifiedCache(types.ClientName))
+	assert.False(t, isNotifiedCache(types.L5Name))
+}
+
+func TestChangeNotifier_ChangeLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := &changeLogStore{MockStore: mock.NewMockStore(ctrl)}
+	s.logs = []*model.ChangeLog{{ID: 1, Resource: model.RService, Server: "other"}}
+	n := newChangeNotifier(ChangeNotifyConfig{Open: true}, s)
+	assert.NotNil(t, n.logStore)
+	services := n.register(types.ServiceName)
+	instances := n.register(types.InstanceName)
+	assert.True(t, n.initLastID())
+	assert.Equal(t, uint64(1), n.lastID)
+
+	// 本节点的变更直接触发刷新，并写入变更日志
+	assert.NoError(t, n.onChangeEvent(context.TODO(), &eventhub.CacheChangeEvent{Resource: model.RInstance}))
+	assert.True(t, triggered(instances))
+	n.flush()
+	assert.Len(t, s.logs, 2)
+	assert.Equal(t, n.server, s.logs[1].Server)
+
+	// 忽略本节点写入的变更日志，只处理其他节点的变更
+	s.logs = append(s.logs, &model.ChangeLog{ID: 3, Resource: model.RService, Server: "other"})
+	n.pull()
+	assert.Equal(t, uint64(3), n.lastID)
+	assert.False(t, triggered(instances))
+	assert.True(t, triggered(services))
+}
@@ -25,6 +25,86 @@ type Config struct {
 	DiffTime time.Duration `yaml:"diffTime"`
 	// ReportInterval 监控数据上报周期
 	ReportInterval time.Duration `yaml:"reportInterval"`
+	// ChangeNotify 资源变更通知配置
+	ChangeNotify ChangeNotifyConfig `yaml:"changeNotify"`
+	// Snapshot 缓存本地快照配置
+	Snapshot SnapshotConfig `yaml:"snapshot"`
+}
+
+// ChangeNotifyConfig 资源变更通知配置，开启后缓存收到资源变更通知时按需刷新，定时拉取仅作为兜底
+type ChangeNotifyConfig struct {
+	// Open 是否开启资源变更通知
+	Open bool `yaml:"open"`
+	// SafetyInterval 兜底的定时刷新周期
+	SafetyInterval time.Duration `yaml:"safetyInterval"`
+	// MinInterval 同一个缓存两次按需刷新之间的最小间隔，合并短时间内的多次变更
+	MinInterval time.Duration `yaml:"minInterval"`
+	// WatchInterval 写入以及轮询存储中变更日志的周期，决定了其他节点感知变更的延迟
+	WatchInterval time.Duration `yaml:"watchInterval"`
+	// Retention 变更日志在存储中的保留时长
+	Retention time.Duration `yaml:"retention"`
+}
+
+const (
+	// DefaultSafetyInterval 默认的兜底刷新周期
+	DefaultSafetyInterval = 30 * time.Second
+	// DefaultMinInterval 默认的按需刷新最小间隔
+	DefaultMinInterval = 100 * time.Millisecond
+	// DefaultWatchInterval 默认的变更日志轮询周期
+	DefaultWatchInterval = 500 * time.Millisecond
+	// DefaultChangeLogRetention 默认的变更日志保留时长
+	DefaultChangeLogRetention = 10 * time.Minute
+)
+
+// fillDefault 填充未配置的参数
+func (c *ChangeNotifyConfig) fillDefault() {
+	if c.SafetyInterval <= 0 {
+		c.SafetyInterval = DefaultSafetyInterval
+	}
+	if c.MinInterval <= 0 {
+		c.MinInterval = DefaultMinInterval
+	}
+	if c.WatchInterval <= 0 {
+		c.WatchInterval = DefaultWatchInterval
+	}
+	if c.Retention <= 0 {
+		c.Retention = DefaultChangeLogRetention
+	}
+}
+
+// SnapshotConfig 缓存本地快照配置，开启后定期把缓存数据写入本地文件，启动时先加载快照对外提供服务，再和存储完成对账
+type SnapshotConfig struct {
+	// Open 是否开启本地快照
+	Open bool `yaml:"open"`
+	// Path 快照文件路径
+	Path string `yaml:"path"`
+	// Interval 写入快照的周期
+	Interval time.Duration `yaml:"interval"`
+	// MaxAge 快照的最长有效期，超过有效期的快照在启动时不会被加载，
+	// 不应该超过已删除资源的清理周期，否则快照中可能残留已经从存储中物理删除的数据
+	MaxAge time.Duration `yaml:"maxAge"`
+}
+
+const (
+	// DefaultSnapshotPath 默认的快照文件路径
+	DefaultSnapshotPath = "./data/cache/snapshot.json.gz"
+	// DefaultSnapshotInterval 默认的快照写入周期
+	DefaultSnapshotInterval = time.Minute
+	// DefaultSnapshotMaxAge 默认的快照有效期
+	DefaultSnapshotMaxAge = 10 * time.Minute
+)
+
+// fillDefault 填充未配置的参数
+func (c *SnapshotConfig) fillDefault() {
+	if c.Path == "" {
+		c.Path = DefaultSnapshotPath
+	}
+	if c.Interval <= 0 {
+		c.Interval = DefaultSnapshotInterval
+	}
+	if c.MaxAge <= 0 {
+		c.MaxAge = DefaultSnapshotMaxAge
+	}
 }
 
 var (
@@ -182,6 +182,11 @@ func (fc *fileCache) setReleases(releases []*model.ConfigFileRelease) (map[strin
 
 		if item.Active {
 			fc.sendEvent(item)
+		} else if item.ReleaseType == model.ReleaseTypeGray && oldVal != nil && oldVal.Active {
+			// 灰度发布停止后，命中灰度的客户端需要重新感知到当前的全量发布
+			if active := fc.GetActiveRelease(item.Namespace, item.Group, item.FileName); active != nil {
+				fc.sendEvent(active)
+			}
 		}
 	}
 	fc.postProcessUpdatedRelease(affect)
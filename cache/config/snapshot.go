@@ -0,0 +1,86 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"time"
+
+	types "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/model"
+)
+
+var (
+	_ types.SnapshotCache = (*fileCache)(nil)
+	_ types.SnapshotCache = (*configGroupCache)(nil)
+)
+
+// DumpSnapshot 导出配置发布快照，只有处于激活状态的发布记录需要携带配置内容
+func (fc *fileCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := fc.OriginLastFetchTime()
+	ret := make([]*model.ConfigFileRelease, 0, fc.releases.Count())
+	fc.releases.Range(func(_ uint64, val *model.SimpleConfigFileRelease) {
+		release := &model.ConfigFileRelease{
+			SimpleConfigFileRelease: val,
+		}
+		if val.Active {
+			fc.loadValueCache(release)
+		}
+		ret = append(ret, release)
+	})
+	return ret, fetchTime
+}
+
+// LoadSnapshot 加载配置发布快照
+func (fc *fileCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	var releases []*model.ConfigFileRelease
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return err
+	}
+	return fc.DoSnapshotLoad(fc.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		if len(releases) == 0 {
+			return nil, 0, nil
+		}
+		lastMtimes, _, _, err := fc.setReleases(releases)
+		if err != nil {
+			return nil, 0, err
+		}
+		return lastMtimes, int64(len(releases)), nil
+	})
+}
+
+// DumpSnapshot 导出配置分组快照
+func (fc *configGroupCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := fc.OriginLastFetchTime()
+	return fc.groups.Values(), fetchTime
+}
+
+// LoadSnapshot 加载配置分组快照
+func (fc *configGroupCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	var groups []*model.ConfigFileGroup
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return err
+	}
+	return fc.DoSnapshotLoad(fc.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		if len(groups) == 0 {
+			return nil, 0, nil
+		}
+		lastMtimes, _, _ := fc.setConfigGroups(groups)
+		return lastMtimes, int64(len(groups)), nil
+	})
+}
@@ -87,6 +87,7 @@ func newCacheManager(ctx context.Context, cacheOpt *Config, storage store.Store)
 		storage:  storage,
 		caches:   make([]types.Cache, types.CacheLast),
 		needLoad: utils.NewSyncSet[string](),
+		unsynced: utils.NewSyncSet[string](),
 	}
 
 	// 命名空间缓存
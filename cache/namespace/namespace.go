@@ -19,6 +19,7 @@ package namespace
 
 import (
 	"context"
+	"encoding/json"
 	"math"
 	"sort"
 	"time"
@@ -36,6 +37,7 @@ import (
 
 var (
 	_ types.NamespaceCache = (*namespaceCache)(nil)
+	_ types.SnapshotCache  = (*namespaceCache)(nil)
 )
 
 type namespaceCache struct {
@@ -114,6 +116,23 @@ func (nsCache *namespaceCache) setNamespaces(nsSlice []*model.Namespace) map[str
 	}
 }
 
+// DumpSnapshot 导出命名空间快照
+func (nsCache *namespaceCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := nsCache.OriginLastFetchTime()
+	return nsCache.ids.Values(), fetchTime
+}
+
+// LoadSnapshot 加载命名空间快照
+func (nsCache *namespaceCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	var namespaces []*model.Namespace
+	if err := json.Unmarshal(data, &namespaces); err != nil {
+		return err
+	}
+	return nsCache.DoSnapshotLoad(nsCache.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		return nsCache.setNamespaces(namespaces), int64(len(namespaces)), nil
+	})
+}
+
 func (nsCache *namespaceCache) handleNamespaceChange(et eventhub.EventType, oldItem, item *model.Namespace) {
 	switch et {
 	case eventhub.EventUpdated, eventhub.EventCreated:
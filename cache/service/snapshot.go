@@ -0,0 +1,191 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	types "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/eventhub"
+	"github.com/polarismesh/polaris/common/model"
+)
+
+var (
+	_ types.SnapshotCache = (*serviceCache)(nil)
+	_ types.SnapshotCache = (*instanceCache)(nil)
+	_ types.SnapshotCache = (*RouteRuleCache)(nil)
+	_ types.SnapshotCache = (*rateLimitCache)(nil)
+	_ types.SnapshotCache = (*circuitBreakerCache)(nil)
+	_ types.SnapshotCache = (*faultDetectCache)(nil)
+	_ types.SnapshotCache = (*LaneCache)(nil)
+)
+
+// DumpSnapshot 导出服务快照
+func (sc *serviceCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := sc.OriginLastFetchTime()
+	return sc.ids.Values(), fetchTime
+}
+
+// LoadSnapshot 加载服务快照
+func (sc *serviceCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	var services []*model.Service
+	if err := json.Unmarshal(data, &services); err != nil {
+		return err
+	}
+	return sc.DoSnapshotLoad(sc.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		values := make(map[string]*model.Service, len(services))
+		for i := range services {
+			values[services[i].ID] = services[i]
+		}
+		lastMtimes, _, _ := sc.setServices(values)
+		return lastMtimes, int64(len(services)), nil
+	})
+}
+
+// DumpSnapshot 导出实例快照
+func (ic *instanceCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := ic.OriginLastFetchTime()
+	return ic.ids.Values(), fetchTime
+}
+
+// LoadSnapshot 加载实例快照，实例所属的服务需要先完成加载
+func (ic *instanceCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	var instances []*model.Instance
+	if err := json.Unmarshal(data, &instances); err != nil {
+		return err
+	}
+	return ic.DoSnapshotLoad(ic.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		values := make(map[string]*model.Instance, len(instances))
+		for i := range instances {
+			values[instances[i].ID()] = instances[i]
+		}
+		events, lastMtimes, _, _ := ic.setInstances(values)
+		for i := range events {
+			_ = eventhub.Publish(eventhub.CacheInstanceEventTopic, events[i])
+		}
+		return lastMtimes, int64(len(instances)), nil
+	})
+}
+
+// DumpSnapshot 导出路由规则快照
+func (rc *RouteRuleCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := rc.OriginLastFetchTime()
+	rules := rc.container.rules.Values()
+	ret := make([]*model.RouterConfig, 0, len(rules))
+	for i := range rules {
+		ret = append(ret, rules[i].RouterConfig)
+	}
+	return ret, fetchTime
+}
+
+// LoadSnapshot 加载路由规则快照
+func (rc *RouteRuleCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	var rules []*model.RouterConfig
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+	return rc.DoSnapshotLoad(rc.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		lastMtimes := map[string]time.Time{}
+		rc.setRouterRules(lastMtimes, rules)
+		rc.container.reload()
+		return lastMtimes, int64(len(rules)), nil
+	})
+}
+
+// DumpSnapshot 导出限流规则快照
+func (rlc *rateLimitCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := rlc.OriginLastFetchTime()
+	return rlc.rules.ids.Values(), fetchTime
+}
+
+// LoadSnapshot 加载限流规则快照
+func (rlc *rateLimitCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	var rules []*model.RateLimit
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+	return rlc.DoSnapshotLoad(rlc.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		rlc.setRateLimit(rules)
+		return nil, int64(len(rules)), nil
+	})
+}
+
+// DumpSnapshot 导出熔断规则快照
+func (c *circuitBreakerCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := c.OriginLastFetchTime()
+	return c.rules.Values(), fetchTime
+}
+
+// LoadSnapshot 加载熔断规则快照
+func (c *circuitBreakerCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	var rules []*model.CircuitBreakerRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+	return c.DoSnapshotLoad(c.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		lastMtimes, _, _ := c.setCircuitBreaker(rules)
+		return lastMtimes, int64(len(rules)), nil
+	})
+}
+
+// DumpSnapshot 导出探测规则快照
+func (f *faultDetectCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := f.OriginLastFetchTime()
+	return f.rules.Values(), fetchTime
+}
+
+// LoadSnapshot 加载探测规则快照
+func (f *faultDetectCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	var rules []*model.FaultDetectRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+	return f.DoSnapshotLoad(f.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		return f.setFaultDetectRules(rules), int64(len(rules)), nil
+	})
+}
+
+// DumpSnapshot 导出泳道规则快照
+func (lc *LaneCache) DumpSnapshot() (interface{}, time.Time) {
+	fetchTime := lc.OriginLastFetchTime()
+	rules := lc.rules.Values()
+	ret := make([]*model.LaneGroup, 0, len(rules))
+	for i := range rules {
+		ret = append(ret, rules[i].LaneGroup)
+	}
+	return ret, fetchTime
+}
+
+// LoadSnapshot 加载泳道规则快照
+func (lc *LaneCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	var rules []*model.LaneGroup
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+	return lc.DoSnapshotLoad(lc.Name(), fetchTime, func() (map[string]time.Time, int64, error) {
+		values := make(map[string]*model.LaneGroup, len(rules))
+		for i := range rules {
+			values[rules[i].ID] = rules[i]
+		}
+		mtime, _, _, _ := lc.setLaneRules(values)
+		return map[string]time.Time{
+			lc.Name(): mtime,
+		}, int64(len(rules)), nil
+	})
+}
@@ -0,0 +1,96 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+func TestServiceCache_Snapshot(t *testing.T) {
+	ctl, storage, sc, ic := newTestServiceCache(t)
+	defer ctl.Finish()
+
+	services := genModelService(5)
+	_, instances := genModelInstancesByServices(services, 3)
+	storage.EXPECT().GetMoreServices(gomock.Any(), true, gomock.Any(), gomock.Any()).Return(services, nil)
+	storage.EXPECT().GetMoreInstances(gomock.Any(), gomock.Any(), true, gomock.Any(), gomock.Any()).
+		Return(instances, nil)
+	storage.EXPECT().GetInstancesCountTx(gomock.Any()).Return(uint32(len(instances)), nil).AnyTimes()
+	storage.EXPECT().GetServicesCount().Return(uint32(len(services)), nil).AnyTimes()
+	assert.NoError(t, sc.Update())
+	assert.NoError(t, ic.Update())
+
+	svcData, svcFetchTime := sc.DumpSnapshot()
+	svcRaw, err := json.Marshal(svcData)
+	assert.NoError(t, err)
+	insData, insFetchTime := ic.DumpSnapshot()
+	insRaw, err := json.Marshal(insData)
+	assert.NoError(t, err)
+
+	t.Run("加载快照后缓存可以直接提供服务", func(t *testing.T) {
+		ctl, storage, sc, ic := newTestServiceCache(t)
+		defer ctl.Finish()
+
+		assert.NoError(t, sc.LoadSnapshot(svcRaw, svcFetchTime))
+		assert.NoError(t, ic.LoadSnapshot(insRaw, insFetchTime))
+		assert.False(t, sc.IsFirstUpdate())
+		assert.False(t, ic.IsFirstUpdate())
+		assert.Equal(t, svcFetchTime, sc.OriginLastFetchTime())
+		assert.Equal(t, len(services), getServiceCacheCount(sc))
+		assert.Equal(t, len(instances), ic.GetInstancesCount())
+		for id, item := range instances {
+			ret := ic.GetInstance(id)
+			assert.NotNil(t, ret)
+			assert.Equal(t, item.Proto.GetHost().GetValue(), ret.Proto.GetHost().GetValue())
+			assert.Equal(t, services[item.ServiceID].Name, ret.Proto.GetService().GetValue())
+		}
+
+		// 之后从快照的拉取时间开始增量对账，快照之后被删除的服务需要从缓存中移除
+		var deleted *model.Service
+		for _, item := range services {
+			deleted = &model.Service{
+				ID:         item.ID,
+				Name:       item.Name,
+				Namespace:  item.Namespace,
+				Valid:      false,
+				ModifyTime: item.ModifyTime,
+			}
+			break
+		}
+		storage.EXPECT().GetMoreServices(gomock.Any(), false, gomock.Any(), gomock.Any()).
+			Return(map[string]*model.Service{deleted.ID: deleted}, nil)
+		storage.EXPECT().GetServicesCount().Return(uint32(len(services)-1), nil).AnyTimes()
+		assert.NoError(t, sc.Update())
+		assert.Equal(t, len(services)-1, getServiceCacheCount(sc))
+		assert.Nil(t, sc.GetServiceByID(deleted.ID))
+	})
+
+	t.Run("快照数据格式错误", func(t *testing.T) {
+		ctl, _, sc, _ := newTestServiceCache(t)
+		defer ctl.Finish()
+
+		assert.Error(t, sc.LoadSnapshot([]byte("{"), svcFetchTime))
+		assert.True(t, sc.IsFirstUpdate())
+	})
+}
@@ -0,0 +1,207 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cache
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+
+	types "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+const (
+	// snapshotVersion 快照文件的格式版本
+	snapshotVersion = 1
+)
+
+// cacheSnapshot 快照文件内容
+type cacheSnapshot struct {
+	Version    int                       `json:"version"`
+	CreateTime time.Time                 `json:"createTime"`
+	Caches     map[string]*snapshotEntry `json:"caches"`
+}
+
+// snapshotEntry 单个缓存的快照数据
+type snapshotEntry struct {
+	// FetchTime 快照数据对应的从存储拉取数据的时间，加载快照后从这个时间开始增量拉取
+	FetchTime time.Time       `json:"fetchTime"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// snapshotter 定期把缓存数据写入本地快照文件，启动时从快照文件中恢复缓存数据
+type snapshotter struct {
+	cfg SnapshotConfig
+	// loadTime 启动时加载的快照的生成时间
+	loadTime time.Time
+	// dumpTime 最近一次写入快照的时间
+	dumpTime *utils.AtomicValue[time.Time]
+}
+
+func newSnapshotter(cfg SnapshotConfig) *snapshotter {
+	cfg.fillDefault()
+	return &snapshotter{
+		cfg:      cfg,
+		dumpTime: utils.NewAtomicValue[time.Time](time.Time{}),
+	}
+}
+
+// load 从快照文件中恢复缓存数据，返回成功加载的缓存
+func (s *snapshotter) load(caches []types.SnapshotCache) []string {
+	snapshot, err := readSnapshot(s.cfg.Path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warn("[Cache][Snapshot] read snapshot file fail", zap.String("path", s.cfg.Path), zap.Error(err))
+		}
+		return nil
+	}
+	if snapshot.Version != snapshotVersion {
+		log.Warn("[Cache][Snapshot] ignore snapshot with unknown version", zap.Int("version", snapshot.Version))
+		return nil
+	}
+	if age := time.Since(snapshot.CreateTime); age > s.cfg.MaxAge {
+		log.Warn("[Cache][Snapshot] ignore expired snapshot", zap.Time("create", snapshot.CreateTime),
+			zap.Duration("max-age", s.cfg.MaxAge))
+		return nil
+	}
+
+	loaded := make([]string, 0, len(caches))
+	for _, c := range caches {
+		entry, ok := snapshot.Caches[c.Name()]
+		if !ok {
+			continue
+		}
+		if err := c.LoadSnapshot(entry.Data, entry.FetchTime); err != nil {
+			log.Error("[Cache][Snapshot] load cache snapshot fail", zap.String("cache", c.Name()), zap.Error(err))
+			// 加载失败的缓存清空后按照正常流程从存储全量加载
+			_ = c.Clear()
+			continue
+		}
+		loaded = append(loaded, c.Name())
+	}
+	if len(loaded) != 0 {
+		s.loadTime = snapshot.CreateTime
+	}
+	log.Info("[Cache][Snapshot] load snapshot", zap.Time("create", snapshot.CreateTime),
+		zap.Strings("caches", loaded))
+	return loaded
+}
+
+// dump 把缓存数据写入快照文件
+func (s *snapshotter) dump(caches []types.SnapshotCache) error {
+	snapshot := &cacheSnapshot{
+		Version:    snapshotVersion,
+		CreateTime: time.Now(),
+		Caches:     make(map[string]*snapshotEntry, len(caches)),
+	}
+	for _, c := range caches {
+		data, fetchTime := c.DumpSnapshot()
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("marshal %s snapshot: %w", c.Name(), err)
+		}
+		snapshot.Caches[c.Name()] = &snapshotEntry{
+			FetchTime: fetchTime,
+			Data:      raw,
+		}
+	}
+	if err := writeSnapshot(s.cfg.Path, snapshot); err != nil {
+		return err
+	}
+	s.dumpTime.Store(snapshot.CreateTime)
+	return nil
+}
+
+// run 定期写入快照，存在尚未和存储完成同步的缓存时跳过，避免刷新快照的生成时间后让过期数据被当作有效快照加载
+func (s *snapshotter) run(ctx context.Context, mgr *CacheManager) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if mgr.IsDegraded() {
+				log.Info("[Cache][Snapshot] cache is degraded, skip dump snapshot")
+				continue
+			}
+			start := time.Now()
+			if err := s.dump(mgr.snapshotCaches()); err != nil {
+				log.Error("[Cache][Snapshot] dump snapshot fail", zap.String("path", s.cfg.Path), zap.Error(err))
+				continue
+			}
+			log.Debug("[Cache][Snapshot] dump snapshot", zap.Duration("used", time.Since(start)))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func readSnapshot(path string) (*cacheSnapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	reader, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	snapshot := &cacheSnapshot{}
+	if err := json.NewDecoder(reader).Decode(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// writeSnapshot 先写入临时文件再重命名，避免进程退出时留下不完整的快照文件
+func writeSnapshot(path string, snapshot *cacheSnapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	// 快照中包含用户的 token 等敏感数据，只允许当前用户读写
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(f)
+	err = json.NewEncoder(writer).Encode(snapshot)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,162 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	types "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+// snapshotTestCache 在内存中记录快照数据的缓存
+type snapshotTestCache struct {
+	name      string
+	values    []string
+	fetchTime time.Time
+	updateErr error
+}
+
+func (c *snapshotTestCache) Initialize(_ map[string]interface{}) error {
+	return nil
+}
+
+func (c *snapshotTestCache) Update() error {
+	return c.updateErr
+}
+
+func (c *snapshotTestCache) Clear() error {
+	c.values = nil
+	return nil
+}
+
+func (c *snapshotTestCache) Name() string {
+	return c.name
+}
+
+func (c *snapshotTestCache) Close() error {
+	return nil
+}
+
+func (c *snapshotTestCache) DumpSnapshot() (interface{}, time.Time) {
+	return c.values, c.fetchTime
+}
+
+func (c *snapshotTestCache) LoadSnapshot(data []byte, fetchTime time.Time) error {
+	if err := json.Unmarshal(data, &c.values); err != nil {
+		return err
+	}
+	c.fetchTime = fetchTime
+	return nil
+}
+
+func TestSnapshotter_DumpAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "snapshot.json.gz")
+	fetchTime := time.Unix(time.Now().Unix(), 0)
+	s := newSnapshotter(SnapshotConfig{Open: true, Path: path})
+	assert.Equal(t, DefaultSnapshotMaxAge, s.cfg.MaxAge)
+
+	// 快照文件不存在时不加载任何缓存
+	assert.Empty(t, s.load([]types.SnapshotCache{&snapshotTestCache{name: types.ServiceName}}))
+
+	err := s.dump([]types.SnapshotCache{
+		&snapshotTestCache{name: types.ServiceName, values: []string{"a", "b"}, fetchTime: fetchTime},
+		&snapshotTestCache{name: types.InstanceName, values: []string{"c"}, fetchTime: fetchTime},
+	})
+	assert.NoError(t, err)
+	assert.False(t, s.dumpTime.Load().IsZero())
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	services := &snapshotTestCache{name: types.ServiceName}
+	configs := &snapshotTestCache{name: types.ConfigFileCacheName}
+	loaded := newSnapshotter(SnapshotConfig{Open: true, Path: path}).load([]types.SnapshotCache{services, configs})
+	assert.Equal(t, []string{types.ServiceName}, loaded)
+	assert.Equal(t, []string{"a", "b"}, services.values)
+	assert.True(t, fetchTime.Equal(services.fetchTime))
+	assert.Empty(t, configs.values)
+
+	// 超过有效期的快照不会被加载
+	expired := newSnapshotter(SnapshotConfig{Open: true, Path: path, MaxAge: time.Nanosecond})
+	assert.Empty(t, expired.load([]types.SnapshotCache{&snapshotTestCache{name: types.ServiceName}}))
+}
+
+func TestCacheManager_Degraded(t *testing.T) {
+	mgr := &CacheManager{
+		needLoad: utils.NewSyncSet[string](),
+		unsynced: utils.NewSyncSet[string](),
+	}
+	item := &snapshotTestCache{name: types.ServiceName, updateErr: errors.New("store unavailable")}
+
+	mgr.update(item)
+	assert.True(t, mgr.IsDegraded())
+	// 运行期间刷新失败只标记降级，存储仍然可写
+	assert.NoError(t, store.CheckWritable())
+	status := mgr.GetCacheStatus()
+	assert.True(t, status.Degraded)
+	assert.Equal(t, []string{types.ServiceName}, status.UnsyncedCaches)
+	assert.Nil(t, status.SnapshotLoadTime)
+
+	item.updateErr = nil
+	mgr.update(item)
+	assert.False(t, mgr.IsDegraded())
+	assert.Empty(t, mgr.GetCacheStatus().UnsyncedCaches)
+	assert.NoError(t, store.CheckWritable())
+}
+
+func TestCacheManager_ReconcileAfterSnapshot(t *testing.T) {
+	mgr := &CacheManager{
+		needLoad:    utils.NewSyncSet[string](),
+		unsynced:    utils.NewSyncSet[string](),
+		reconciling: utils.NewSyncSet[string](),
+	}
+	services := &snapshotTestCache{name: types.ServiceName, updateErr: errors.New("store unavailable")}
+	configs := &snapshotTestCache{name: types.ConfigFileCacheName}
+	for _, name := range []string{services.name, configs.name} {
+		mgr.unsynced.Add(name)
+		mgr.reconciling.Add(name)
+	}
+	store.SetReadOnly(true)
+	defer store.SetReadOnly(false)
+
+	// 从快照启动后，全部缓存完成首次对账之前存储只读
+	mgr.update(configs)
+	mgr.update(services)
+	assert.Equal(t, store.StoreUnavailable, store.Code(store.CheckWritable()))
+
+	services.updateErr = nil
+	mgr.update(services)
+	assert.NoError(t, store.CheckWritable())
+
+	// 完成对账之后刷新失败不会再让存储只读
+	configs.updateErr = errors.New("store unavailable")
+	mgr.update(configs)
+	assert.True(t, mgr.IsDegraded())
+	assert.NoError(t, store.CheckWritable())
+}
@@ -0,0 +1,141 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	boot_config "github.com/polarismesh/polaris/bootstrap/config"
+	"github.com/polarismesh/polaris/store/backup"
+)
+
+var (
+	backupConfigPath  = ""
+	backupFile        = ""
+	backupNamespaces  []string
+	restoreConflict   = ""
+	restoreNamespaces []string
+
+	backupCmd = &cobra.Command{
+		Use:   "backup",
+		Short: "backup data of the store into an archive",
+		Long: "take a consistent snapshot of all data in the store configured in --config and write it " +
+			"into a versioned tar.gz archive, the archive contains password hashes and tokens",
+		RunE: func(c *cobra.Command, args []string) error {
+			return runBackup()
+		},
+	}
+
+	restoreCmd = &cobra.Command{
+		Use:   "restore",
+		Short: "restore data from a backup archive",
+		Long: "offline restore a backup archive into the store configured in --config, " +
+			"polaris server must be stopped during restore",
+		RunE: func(c *cobra.Command, args []string) error {
+			return runRestore()
+		},
+	}
+)
+
+// init 解析命令参数
+func init() {
+	backupCmd.Flags().StringVarP(&backupConfigPath, "config", "c", "conf/polaris-server.yaml", "config file path")
+	backupCmd.Flags().StringVarP(&backupFile, "output", "o", "", "path of the backup archive to write")
+	backupCmd.Flags().StringSliceVarP(&backupNamespaces, "namespace", "n", nil,
+		"only backup these namespaces, global resources such as users are skipped")
+	_ = backupCmd.MarkFlagRequired("output")
+
+	restoreCmd.Flags().StringVarP(&backupConfigPath, "config", "c", "conf/polaris-server.yaml", "config file path")
+	restoreCmd.Flags().StringVarP(&backupFile, "input", "i", "", "path of the backup archive to restore")
+	restoreCmd.Flags().StringSliceVarP(&restoreNamespaces, "namespace", "n", nil,
+		"only restore these namespaces, global resources such as users are skipped")
+	restoreCmd.Flags().StringVar(&restoreConflict, "conflict", string(backup.ConflictSkip),
+		"policy when a record already exists in the store: skip or overwrite")
+	_ = restoreCmd.MarkFlagRequired("input")
+}
+
+func runBackup() error {
+	cfg, err := boot_config.Load(backupConfigPath)
+	if err != nil {
+		return err
+	}
+	s, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.Destroy()
+	}()
+
+	f, err := os.OpenFile(backupFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	manifest, err := backup.Backup(s, f, &backup.BackupOption{Namespaces: backupNamespaces})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(backupFile)
+		return err
+	}
+	for _, entry := range manifest.Resources {
+		fmt.Printf("%-24s %d\n", entry.Name, entry.Count)
+	}
+	fmt.Printf("\nbackup of %s written to %s\n", manifest.Store, backupFile)
+	return nil
+}
+
+func runRestore() error {
+	conflict, err := backup.ParseConflictPolicy(restoreConflict)
+	if err != nil {
+		return err
+	}
+	cfg, err := boot_config.Load(backupConfigPath)
+	if err != nil {
+		return err
+	}
+	s, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.Destroy()
+	}()
+
+	f, err := os.Open(backupFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	// 服务端已经停止，可以回写备份中记录的创建、修改时间
+	report, err := backup.Restore(s, f, &backup.RestoreOption{
+		Namespaces:        restoreNamespaces,
+		Conflict:          conflict,
+		RestoreTimestamps: true,
+	})
+	if report != nil {
+		report.Print(os.Stdout)
+	}
+	return err
+}
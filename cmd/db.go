@@ -0,0 +1,87 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	boot_config "github.com/polarismesh/polaris/bootstrap/config"
+	"github.com/polarismesh/polaris/store"
+)
+
+var (
+	dbConfigPath = ""
+	dryRun       = false
+	baseline     = ""
+
+	dbCmd = &cobra.Command{
+		Use:   "db",
+		Short: "database schema management",
+		Long:  "database schema management",
+	}
+
+	dbUpgradeCmd = &cobra.Command{
+		Use:   "upgrade",
+		Short: "upgrade database schema",
+		Long:  "apply the pending schema delta scripts of the store configured in the config file",
+		RunE: func(c *cobra.Command, args []string) error {
+			return runDBUpgrade()
+		},
+	}
+)
+
+// init 解析命令参数
+func init() {
+	dbCmd.PersistentFlags().StringVarP(&dbConfigPath, "config", "c", "conf/polaris-server.yaml", "config file path")
+	dbUpgradeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "only print the pending delta scripts")
+	dbUpgradeCmd.Flags().StringVar(&baseline, "baseline", "",
+		"schema version of a database which has no version record, e.g. 1.18.1")
+	dbCmd.AddCommand(dbUpgradeCmd)
+}
+
+func runDBUpgrade() error {
+	cfg, err := boot_config.Load(dbConfigPath)
+	if err != nil {
+		return err
+	}
+	if cfg.Store.Option == nil {
+		cfg.Store.Option = map[string]interface{}{}
+	}
+	// 由命令行控制升级过程，初始化时不自动执行升级
+	cfg.Store.Option["autoUpgrade"] = false
+	s, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.Destroy()
+	}()
+
+	schemaStore, ok := s.(store.SchemaStore)
+	if !ok {
+		return fmt.Errorf("store `%s` does not support schema upgrade", s.Name())
+	}
+	return schemaStore.UpgradeSchema(&store.SchemaUpgradeOption{
+		DryRun:   dryRun,
+		Baseline: baseline,
+		Output:   os.Stdout,
+	})
+}
@@ -0,0 +1,105 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	boot_config "github.com/polarismesh/polaris/bootstrap/config"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/migrate"
+)
+
+var (
+	sourceConfigPath = ""
+	targetConfigPath = ""
+
+	migrateCmd = &cobra.Command{
+		Use:   "migrate",
+		Short: "migrate data between stores",
+		Long: "offline migrate all data from the store configured in --source to the store configured in --target, " +
+			"polaris server must be stopped during migration",
+		RunE: func(c *cobra.Command, args []string) error {
+			return runMigrate()
+		},
+	}
+)
+
+// init 解析命令参数
+func init() {
+	migrateCmd.Flags().StringVarP(&sourceConfigPath, "source", "s", "", "config file path of the source store")
+	migrateCmd.Flags().StringVarP(&targetConfigPath, "target", "t", "", "config file path of the target store")
+	_ = migrateCmd.MarkFlagRequired("source")
+	_ = migrateCmd.MarkFlagRequired("target")
+}
+
+func runMigrate() error {
+	sourceCfg, err := boot_config.Load(sourceConfigPath)
+	if err != nil {
+		return err
+	}
+	targetCfg, err := boot_config.Load(targetConfigPath)
+	if err != nil {
+		return err
+	}
+	source, err := openStore(sourceCfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = source.Destroy()
+	}()
+	target, err := openStore(targetCfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = target.Destroy()
+	}()
+
+	report, err := migrate.NewMigrator(source, target).Run()
+	if report != nil {
+		report.Print(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+	if !report.Passed() {
+		return errors.New("verify migrated data failed")
+	}
+	return nil
+}
+
+// openStore 按照配置文件中的 store 配置打开对应的存储插件
+func openStore(cfg *boot_config.Config) (store.Store, error) {
+	s, ok := store.StoreSlots[cfg.Store.Name]
+	if !ok {
+		return nil, fmt.Errorf("store `%s` not found", cfg.Store.Name)
+	}
+	// 数据库密码解析插件依赖插件配置
+	plugin.SetPluginConfig(&cfg.Plugin)
+	if err := s.Initialize(&cfg.Store); err != nil {
+		return nil, fmt.Errorf("initialize store `%s` fail: %w", s.Name(), err)
+	}
+	return s, nil
+}
@@ -17,7 +17,11 @@
 
 package eventhub
 
-import "github.com/polarismesh/polaris/common/model"
+import (
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+)
 
 // 事件主题
 const (
@@ -35,6 +39,10 @@ const (
 	CacheNamespaceEventTopic = "cache_namespace_event"
 	// ClientEventTopic .
 	ClientEventTopic = "client_event"
+	// CacheChangeEventTopic record resource data changed in store, cache refresh on demand
+	CacheChangeEventTopic = "cache_change_event"
+	// CheckerMembersChangeTopic polaris nodes which take part in health check changed
+	CheckerMembersChangeTopic = "checker_members_change"
 )
 
 // PublishConfigFileEvent 事件对象，包含类型和事件消息
@@ -42,6 +50,12 @@ type PublishConfigFileEvent struct {
 	Message *model.SimpleConfigFileRelease
 }
 
+// CheckerMembersChangeEvent 参与健康检查的 Polaris 节点列表，由健康检查分发器在重建一致性哈希环时发布
+type CheckerMembersChangeEvent struct {
+	// Members 存活的 Polaris 节点地址，按照字典序排列
+	Members []string
+}
+
 // EventType common event type
 type EventType int
 
@@ -69,3 +83,17 @@ type CacheNamespaceEvent struct {
 	Item      *model.Namespace
 	EventType EventType
 }
+
+// CacheChangeEvent 资源数据变更通知，只携带发生变更的资源类型，缓存收到后按需拉取增量数据
+type CacheChangeEvent struct {
+	Resource model.Resource
+	Mtime    time.Time
+}
+
+// PublishCacheChange 写操作完成后通知缓存对应的资源数据已经发生变更
+func PublishCacheChange(resource model.Resource) {
+	_ = Publish(CacheChangeEventTopic, &CacheChangeEvent{
+		Resource: resource,
+		Mtime:    time.Now(),
+	})
+}
@@ -61,4 +61,6 @@ func InitMetrics() {
 	registerClientMetrics()
 	registerConfigFileMetrics()
 	registerDiscoveryMetrics()
+	registerXDSMetrics()
+	registerUDPHeartbeatMetrics()
 }
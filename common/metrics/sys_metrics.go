@@ -93,6 +93,14 @@ func registerSysMetrics() {
 		labelBatchJobLabel,
 	})
 
+	cacheDegraded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cache_degraded",
+		Help: "cache is serving data not yet synchronized with the store",
+		ConstLabels: map[string]string{
+			"polaris_server_instance": utils.LocalHost,
+		},
+	}, []string{labelCacheType})
+
 	_ = registry.Register(instanceAsyncRegisCost)
 	_ = registry.Register(instanceRegisTaskExpire)
 	_ = registry.Register(redisReadFailure)
@@ -100,6 +108,7 @@ func registerSysMetrics() {
 	_ = registry.Register(redisAliveStatus)
 	_ = registry.Register(cacheUpdateCost)
 	_ = registry.Register(batchJobUnFinishJobs)
+	_ = registry.Register(cacheDegraded)
 
 	go func() {
 		lastRedisReadFailureReport.Store(time.Now())
@@ -179,3 +188,17 @@ func ReportFinishBatchJob(label string, count int64) {
 		labelBatchJobLabel: label,
 	}).Sub(float64(count))
 }
+
+// ReportCacheDegraded 上报缓存是否处于降级状态
+func ReportCacheDegraded(cacheType string, degraded bool) {
+	if cacheDegraded == nil {
+		return
+	}
+	val := float64(0)
+	if degraded {
+		val = 1
+	}
+	cacheDegraded.With(map[string]string{
+		labelCacheType: cacheType,
+	}).Set(val)
+}
@@ -38,6 +38,9 @@ const (
 	labelCacheType        = "cache_type"
 	labelCacheUpdateCount = "cache_update_count"
 	labelBatchJobLabel    = "batch_label"
+	labelXDSTypeUrl       = "type_url"
+	labelXDSSyncStatus    = "sync_status"
+	labelDropReason       = "reason"
 )
 
 // CallMetricType .
@@ -182,6 +185,22 @@ var (
 	releaseConfigFileTotal *prometheus.GaugeVec
 )
 
+// xds sync metrics
+var (
+	// xdsNodeSyncStatus 各个同步状态下的 Envoy 节点数量
+	xdsNodeSyncStatus *prometheus.GaugeVec
+	// xdsNackTotal Envoy 拒绝 xDS 资源的次数
+	xdsNackTotal *prometheus.CounterVec
+	// xdsQuarantineTotal 因被大量 NACK 而隔离的 xDS 资源版本数量
+	xdsQuarantineTotal *prometheus.CounterVec
+)
+
+// udp heartbeat metrics
+var (
+	// udpHeartbeatDropTotal 被丢弃的 UDP 心跳报文数量，按丢弃原因区分
+	udpHeartbeatDropTotal *prometheus.CounterVec
+)
+
 // instance astbc registry metrics
 var (
 	// instanceAsyncRegisCost 实例异步注册任务耗费时间
@@ -200,4 +219,6 @@ var (
 	cacheUpdateCost *prometheus.HistogramVec
 	// batchJobUnFinishJobs .
 	batchJobUnFinishJobs *prometheus.GaugeVec
+	// cacheDegraded 缓存是否处于降级状态，即数据尚未和存储完成同步
+	cacheDegraded *prometheus.GaugeVec
 )
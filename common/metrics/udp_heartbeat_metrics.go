@@ -0,0 +1,44 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+func registerUDPHeartbeatMetrics() {
+	udpHeartbeatDropTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "udp_heartbeat_drop_total",
+		Help: "total number of udp heartbeat packets dropped, labeled by reason",
+		ConstLabels: map[string]string{
+			LabelServerNode: utils.LocalHost,
+		},
+	}, []string{labelDropReason})
+
+	_ = GetRegistry().Register(udpHeartbeatDropTotal)
+}
+
+// ReportUDPHeartbeatDropped 上报被丢弃的 UDP 心跳报文，reason 为丢弃原因
+func ReportUDPHeartbeatDropped(reason string) {
+	if udpHeartbeatDropTotal == nil {
+		return
+	}
+	udpHeartbeatDropTotal.With(map[string]string{labelDropReason: reason}).Inc()
+}
@@ -0,0 +1,89 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+func registerXDSMetrics() {
+	xdsNodeSyncStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "xds_node_sync_status",
+		Help: "number of envoy nodes in each xds sync status",
+		ConstLabels: map[string]string{
+			LabelServerNode: utils.LocalHost,
+		},
+	}, []string{labelXDSTypeUrl, labelXDSSyncStatus})
+
+	xdsNackTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "xds_nack_total",
+		Help: "total number of xds responses rejected by envoy nodes",
+		ConstLabels: map[string]string{
+			LabelServerNode: utils.LocalHost,
+		},
+	}, []string{labelXDSTypeUrl})
+
+	xdsQuarantineTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "xds_quarantine_total",
+		Help: "total number of xds resource versions quarantined after too many nacks",
+		ConstLabels: map[string]string{
+			LabelServerNode: utils.LocalHost,
+		},
+	}, []string{labelXDSTypeUrl})
+
+	_ = GetRegistry().Register(xdsNodeSyncStatus)
+	_ = GetRegistry().Register(xdsNackTotal)
+	_ = GetRegistry().Register(xdsQuarantineTotal)
+}
+
+// ReportXDSSyncStatusChange 上报 Envoy 节点某类 xDS 资源同步状态的变化，from/to 为空表示新增/移除
+func ReportXDSSyncStatusChange(typeUrl, from, to string) {
+	if xdsNodeSyncStatus == nil || from == to {
+		return
+	}
+	if len(from) != 0 {
+		xdsNodeSyncStatus.With(map[string]string{
+			labelXDSTypeUrl:    typeUrl,
+			labelXDSSyncStatus: from,
+		}).Dec()
+	}
+	if len(to) != 0 {
+		xdsNodeSyncStatus.With(map[string]string{
+			labelXDSTypeUrl:    typeUrl,
+			labelXDSSyncStatus: to,
+		}).Inc()
+	}
+}
+
+// ReportXDSNack 上报 Envoy 拒绝 xDS 资源
+func ReportXDSNack(typeUrl string) {
+	if xdsNackTotal == nil {
+		return
+	}
+	xdsNackTotal.With(map[string]string{labelXDSTypeUrl: typeUrl}).Inc()
+}
+
+// ReportXDSQuarantine 上报 xDS 资源版本被隔离
+func ReportXDSQuarantine(typeUrl string) {
+	if xdsQuarantineTotal == nil {
+		return
+	}
+	xdsQuarantineTotal.With(map[string]string{labelXDSTypeUrl: typeUrl}).Inc()
+}
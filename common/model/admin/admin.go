@@ -59,3 +59,15 @@ type ScopeLevel struct {
 	Name  string
 	Level string
 }
+
+// CacheStatus 缓存和存储的同步状态
+type CacheStatus struct {
+	// Degraded 是否处于降级状态，降级时缓存使用本地快照或者最后一次同步成功的数据对外提供服务
+	Degraded bool `json:"degraded"`
+	// UnsyncedCaches 尚未和存储完成同步的缓存
+	UnsyncedCaches []string `json:"unsyncedCaches"`
+	// SnapshotLoadTime 启动时加载的本地快照的生成时间，未加载快照时为空
+	SnapshotLoadTime *time.Time `json:"snapshotLoadTime,omitempty"`
+	// SnapshotDumpTime 最近一次写入本地快照的时间，未写入过快照时为空
+	SnapshotDumpTime *time.Time `json:"snapshotDumpTime,omitempty"`
+}
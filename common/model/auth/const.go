@@ -229,15 +229,25 @@ const (
 
 // 运维接口
 const (
-	DescribeServerConnections ServerFunctionName = "DescribeServerConnections"
-	DescribeServerConnStats   ServerFunctionName = "DescribeServerConnStats"
-	CloseConnections          ServerFunctionName = "CloseConnections"
-	FreeOSMemory              ServerFunctionName = "FreeOSMemory"
-	DescribeLeaderElections   ServerFunctionName = "DescribeLeaderElections"
-	ReleaseLeaderElection     ServerFunctionName = "ReleaseLeaderElection"
-	DescribeGetLogOutputLevel ServerFunctionName = "DescribeGetLogOutputLevel"
-	UpdateLogOutputLevel      ServerFunctionName = "UpdateLogOutputLevel"
-	DescribeCMDBInfo          ServerFunctionName = "DescribeCMDBInfo"
+	DescribeServerConnections      ServerFunctionName = "DescribeServerConnections"
+	DescribeServerConnStats        ServerFunctionName = "DescribeServerConnStats"
+	CloseConnections               ServerFunctionName = "CloseConnections"
+	FreeOSMemory                   ServerFunctionName = "FreeOSMemory"
+	DescribeLeaderElections        ServerFunctionName = "DescribeLeaderElections"
+	ReleaseLeaderElection          ServerFunctionName = "ReleaseLeaderElection"
+	DescribeGetLogOutputLevel      ServerFunctionName = "DescribeGetLogOutputLevel"
+	UpdateLogOutputLevel           ServerFunctionName = "UpdateLogOutputLevel"
+	DescribeCMDBInfo               ServerFunctionName = "DescribeCMDBInfo"
+	BackupData                     ServerFunctionName = "BackupData"
+	RestoreData                    ServerFunctionName = "RestoreData"
+	DescribeCacheStatus            ServerFunctionName = "DescribeCacheStatus"
+	DescribeServiceAccessPolicies  ServerFunctionName = "DescribeServiceAccessPolicies"
+	CreateServiceAccessPolicy      ServerFunctionName = "CreateServiceAccessPolicy"
+	UpdateServiceAccessPolicy      ServerFunctionName = "UpdateServiceAccessPolicy"
+	DeleteServiceAccessPolicy      ServerFunctionName = "DeleteServiceAccessPolicy"
+	DescribeHealthCheckSuspensions ServerFunctionName = "DescribeHealthCheckSuspensions"
+	CreateHealthCheckSuspension    ServerFunctionName = "CreateHealthCheckSuspension"
+	CancelHealthCheckSuspension    ServerFunctionName = "CancelHealthCheckSuspension"
 )
 
 type ServerFunctionGroup struct {
@@ -0,0 +1,82 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package auth
+
+import (
+	"time"
+
+	apisecurity "github.com/polarismesh/specification/source/go/api/v1/security"
+)
+
+const (
+	// ServiceAccessAny 服务访问策略中表示任意命名空间或者服务
+	ServiceAccessAny = "*"
+
+	// PathMatchExact 接口路径精确匹配
+	PathMatchExact = "EXACT"
+	// PathMatchPrefix 接口路径前缀匹配
+	PathMatchPrefix = "PREFIX"
+	// PathMatchRegex 接口路径正则匹配
+	PathMatchRegex = "REGEX"
+)
+
+// ServiceAccessPolicy 服务间访问策略，控制来源服务能否访问目标服务的接口
+type ServiceAccessPolicy struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Namespace 被访问的目标服务所在的命名空间
+	Namespace string `json:"namespace"`
+	// Service 被访问的目标服务
+	Service string `json:"service"`
+	// Action: 只有 ALLOW 以及 DENY
+	Action string `json:"action"`
+	// Sources 来源服务，为空表示任意来源
+	Sources []ServiceAccessSource `json:"sources"`
+	// Conditions 接口条件，满足任意一个即可，为空表示目标服务的全部接口
+	Conditions []ServiceAccessCondition `json:"conditions"`
+	Comment    string                   `json:"comment"`
+	Revision   string                   `json:"revision"`
+	Valid      bool                     `json:"-"`
+	CreateTime time.Time                `json:"ctime"`
+	ModifyTime time.Time                `json:"mtime"`
+}
+
+// IsDeny 是否为拒绝访问的策略
+func (p *ServiceAccessPolicy) IsDeny() bool {
+	return p.Action == apisecurity.AuthAction_DENY.String()
+}
+
+// ServiceAccessSource 来源服务，Namespace 或者 Service 为 * 时表示任意
+type ServiceAccessSource struct {
+	Namespace string `json:"namespace"`
+	Service   string `json:"service"`
+}
+
+// Match 判断调用方是否为该来源服务
+func (s ServiceAccessSource) Match(namespace, service string) bool {
+	return (s.Namespace == ServiceAccessAny || s.Namespace == namespace) &&
+		(s.Service == ServiceAccessAny || s.Service == service)
+}
+
+// ServiceAccessCondition 接口条件，Methods 为空表示任意 HTTP 方法，Path 为空表示任意路径
+type ServiceAccessCondition struct {
+	Methods []string `json:"methods,omitempty"`
+	Path    string   `json:"path,omitempty"`
+	// PathType 路径匹配方式，EXACT、PREFIX 以及 REGEX，默认为 EXACT
+	PathType string `json:"pathType,omitempty"`
+}
@@ -0,0 +1,42 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package model
+
+import "time"
+
+// CAKeyPair 内置证书颁发机构的根证书，私钥经过 crypto 插件加密后保存，加密密钥只存在于配置中
+type CAKeyPair struct {
+	// Generation 根证书的代数，每次轮换加一，同时作为主键避免多个节点重复生成
+	Generation uint64
+	// Cert PEM 格式的根证书
+	Cert string
+	// KeyCipher 加密后的 PEM 格式私钥
+	KeyCipher string
+	// CryptoAlgo 加密私钥使用的算法
+	CryptoAlgo string
+	NotBefore  time.Time
+	NotAfter   time.Time
+	// RetireTime 根证书被轮换后从信任列表中移除的时间，零值表示仍然是签发证书的根证书
+	RetireTime time.Time
+	CreateTime time.Time
+	ModifyTime time.Time
+}
+
+// Retired 根证书是否已经轮换
+func (c *CAKeyPair) Retired() bool {
+	return !c.RetireTime.IsZero()
+}
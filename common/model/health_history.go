@@ -0,0 +1,60 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import "time"
+
+const (
+	// HealthReasonHeartbeatExpired 心跳超时
+	HealthReasonHeartbeatExpired = "heartbeat_expired"
+	// HealthReasonHeartbeatResumed 心跳恢复
+	HealthReasonHeartbeatResumed = "heartbeat_resumed"
+	// HealthReasonProbeFailed 主动探测失败
+	HealthReasonProbeFailed = "probe_failed"
+	// HealthReasonProbeSucceeded 主动探测成功
+	HealthReasonProbeSucceeded = "probe_succeeded"
+)
+
+// InstanceHealthTransition 实例的一次健康状态变更记录
+type InstanceHealthTransition struct {
+	// InstanceID 实例 ID
+	InstanceID string `json:"instance_id"`
+	// Healthy 变更后的健康状态
+	Healthy bool `json:"healthy"`
+	// Reason 变更原因，取值为 HealthReason*
+	Reason string `json:"reason"`
+	// Detail 补充说明，例如连续检查的次数、抖动抑制的截止时间
+	Detail string `json:"detail,omitempty"`
+	// Server 做出判断的 Polaris 节点
+	Server string `json:"server"`
+	// LastHeartbeatSec 变更时实例最近一次的心跳时间，单位为秒
+	LastHeartbeatSec int64 `json:"last_heartbeat_sec,omitempty"`
+	// Time 变更时间
+	Time time.Time `json:"time"`
+}
+
+// InstanceHealthTimeline 实例的健康状态变化时间线，按照时间倒序排列
+type InstanceHealthTimeline struct {
+	InstanceID  string                      `json:"instance_id"`
+	Namespace   string                      `json:"namespace"`
+	Service     string                      `json:"service"`
+	Host        string                      `json:"host"`
+	Port        uint32                      `json:"port"`
+	Healthy     bool                        `json:"healthy"`
+	Transitions []*InstanceHealthTransition `json:"transitions"`
+}
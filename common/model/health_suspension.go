@@ -0,0 +1,77 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// HealthCheckSuspension 按照范围暂停实例被置为不健康，用于局部故障时避免大面积摘除实例
+type HealthCheckSuspension struct {
+	ID       string                         `json:"id"`
+	Selector *HealthCheckSuspensionSelector `json:"selector"`
+	// TTL 暂停的时长，例如 30m，只在创建时使用
+	TTL     string `json:"ttl,omitempty"`
+	Comment string `json:"comment"`
+	// CreateTime 创建时间
+	CreateTime time.Time `json:"ctime"`
+	// ExpireTime 到期时间，到期后自动失效
+	ExpireTime time.Time `json:"expire_time"`
+}
+
+// HealthCheckSuspensionSelector 暂停的范围，非空的字段需要全部匹配，至少需要指定一个字段
+type HealthCheckSuspensionSelector struct {
+	Namespace string `json:"namespace,omitempty"`
+	Service   string `json:"service,omitempty"`
+	Region    string `json:"region,omitempty"`
+	Zone      string `json:"zone,omitempty"`
+	Campus    string `json:"campus,omitempty"`
+}
+
+// Validate 检查暂停范围
+func (s *HealthCheckSuspensionSelector) Validate() error {
+	if s == nil || (s.Namespace == "" && s.Service == "" && s.Region == "" && s.Zone == "" && s.Campus == "") {
+		return errors.New("selector must specify at least one of namespace, service, region, zone and campus")
+	}
+	if s.Service != "" && s.Namespace == "" {
+		return errors.New("selector with service must specify namespace")
+	}
+	return nil
+}
+
+// Match 判断实例是否在暂停范围内
+func (s *HealthCheckSuspensionSelector) Match(instance *Instance) bool {
+	if s.Namespace != "" && s.Namespace != instance.Namespace() {
+		return false
+	}
+	if s.Service != "" && s.Service != instance.Service() {
+		return false
+	}
+	location := instance.Location()
+	if s.Region != "" && s.Region != location.GetRegion().GetValue() {
+		return false
+	}
+	if s.Zone != "" && s.Zone != location.GetZone().GetValue() {
+		return false
+	}
+	if s.Campus != "" && s.Campus != location.GetCampus().GetValue() {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package model
+
+// HeartbeatRecord 持久化在存储层的实例心跳记录
+type HeartbeatRecord struct {
+	// InstanceID 实例 ID
+	InstanceID string
+	// Server 最近一次接收到心跳的 Polaris 节点
+	Server string
+	// CurTimeSec 最近一次心跳的时间，单位为秒
+	CurTimeSec int64
+	// Count 心跳上报次数，仅在实例不存在时用于判断是否需要返回 NotFound
+	Count int64
+}
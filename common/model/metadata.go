@@ -42,3 +42,37 @@ const (
 	// MetaKey3RdPlatform 第三方平台标签
 	MetaKey3RdPlatform = "internal-3rd-platform"
 )
+
+const (
+	// MetaKeyHealthProbeType 服务端主动探测实例健康状态的方式，取值为 http/tcp/grpc，可以配置在实例或者服务的元数据中，实例优先
+	MetaKeyHealthProbeType = "internal-health-probe-type"
+	// MetaKeyHealthProbePort 探测的端口，默认为实例端口
+	MetaKeyHealthProbePort = "internal-health-probe-port"
+	// MetaKeyHealthProbePath http 探测的请求路径
+	MetaKeyHealthProbePath = "internal-health-probe-path"
+	// MetaKeyHealthProbeStatus http 探测认为健康的状态码，例如 200,204,300-399
+	MetaKeyHealthProbeStatus = "internal-health-probe-status"
+	// MetaKeyHealthProbeGrpcService grpc 探测时健康检查协议中的服务名，为空表示检查整个 server
+	MetaKeyHealthProbeGrpcService = "internal-health-probe-grpc-service"
+	// MetaKeyHealthProbeInterval 探测周期，例如 5s
+	MetaKeyHealthProbeInterval = "internal-health-probe-interval"
+	// MetaKeyHealthProbeTimeout 单次探测的超时时间，例如 2s
+	MetaKeyHealthProbeTimeout = "internal-health-probe-timeout"
+	// MetaKeyHealthProbeRise 连续探测成功多少次后实例变为健康
+	MetaKeyHealthProbeRise = "internal-health-probe-rise"
+	// MetaKeyHealthProbeFall 连续探测失败多少次后实例变为不健康
+	MetaKeyHealthProbeFall = "internal-health-probe-fall"
+)
+
+const (
+	// MetaKeyHealthDampingFall 连续多少次检查不健康后才将实例置为不健康，可以配置在实例或者服务的元数据中，实例优先
+	MetaKeyHealthDampingFall = "internal-health-damping-fall"
+	// MetaKeyHealthDampingRise 连续多少次检查健康后才将实例恢复为健康
+	MetaKeyHealthDampingRise = "internal-health-damping-rise"
+	// MetaKeyHealthFlapThreshold 统计窗口内健康状态变化超过多少次认为实例在抖动，0 表示不做抖动抑制
+	MetaKeyHealthFlapThreshold = "internal-health-flap-threshold"
+	// MetaKeyHealthFlapWindow 抖动的统计窗口，例如 5m
+	MetaKeyHealthFlapWindow = "internal-health-flap-window"
+	// MetaKeyHealthFlapSuppress 抖动实例的惩罚时长，期间保持不健康，例如 10m
+	MetaKeyHealthFlapSuppress = "internal-health-flap-suppress"
+)
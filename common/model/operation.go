@@ -97,3 +97,11 @@ func (r *RecordEntry) String() string {
 		r.Server,
 	)
 }
+
+// ChangeLog 资源数据变更日志，集群内的其他节点据此感知数据变更并刷新缓存
+type ChangeLog struct {
+	ID         uint64
+	Resource   Resource
+	Server     string
+	CreateTime time.Time
+}
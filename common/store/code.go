@@ -33,6 +33,7 @@ var storeCodeAPICodeMap = map[store.StatusCode]apimodel.Code{
 	store.NotFoundTagConfigOrService: apimodel.Code_NotFoundTagConfigOrService,
 	store.ExistReleasedConfig:        apimodel.Code_ExistReleasedConfig,
 	store.DuplicateEntryErr:          apimodel.Code_ExistedResource,
+	store.StoreUnavailable:           apimodel.Code_StoreLayerException,
 }
 
 // StoreCode2APICode store code to api code
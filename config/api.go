@@ -107,6 +107,9 @@ type ConfigFileClientOperate interface {
 	UpsertAndReleaseConfigFileFromClient(ctx context.Context, req *apiconfig.ConfigFilePublishInfo) *apiconfig.ConfigResponse
 	// CasUpsertAndReleaseConfigFileFromClient 创建/更新配置文件并发布
 	CasUpsertAndReleaseConfigFileFromClient(ctx context.Context, req *apiconfig.ConfigFilePublishInfo) *apiconfig.ConfigResponse
+	// VersionCasUpsertAndReleaseConfigFileFromClient 当前生效的发布版本与 version 一致时创建/更新配置文件并发布
+	VersionCasUpsertAndReleaseConfigFileFromClient(ctx context.Context, req *apiconfig.ConfigFilePublishInfo,
+		version uint64) *apiconfig.ConfigResponse
 	// LongPullWatchFile 客户端监听配置文件
 	LongPullWatchFile(ctx context.Context, req *apiconfig.ClientWatchConfigFileRequest) (WatchCallback, error)
 	// GetConfigFileNamesWithCache 获取某个配置分组下的配置文件
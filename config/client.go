@@ -333,3 +333,9 @@ func (s *Server) CasUpsertAndReleaseConfigFileFromClient(ctx context.Context,
 	req *apiconfig.ConfigFilePublishInfo) *apiconfig.ConfigResponse {
 	return s.CasUpsertAndReleaseConfigFile(ctx, req)
 }
+
+// VersionCasUpsertAndReleaseConfigFileFromClient 当前生效的发布版本与 version 一致时创建/更新配置文件并发布
+func (s *Server) VersionCasUpsertAndReleaseConfigFileFromClient(ctx context.Context,
+	req *apiconfig.ConfigFilePublishInfo, version uint64) *apiconfig.ConfigResponse {
+	return s.VersionCasUpsertAndReleaseConfigFile(ctx, req, version)
+}
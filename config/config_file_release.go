@@ -494,6 +494,55 @@ func (s *Server) handleRollbackConfigFileRelease(ctx context.Context, tx store.T
 // CasUpsertAndReleaseConfigFile 根据版本比对决定是否允许进行配置修改发布
 func (s *Server) CasUpsertAndReleaseConfigFile(ctx context.Context,
 	req *apiconfig.ConfigFilePublishInfo) *apiconfig.ConfigResponse {
+	return s.casUpsertAndReleaseConfigFile(ctx, req, func(tx store.Tx, saveFile *model.ConfigFile) *apiconfig.ConfigResponse {
+		// 首次创建时不做比对
+		if saveFile == nil {
+			return nil
+		}
+		actualMd5 := CalMd5(saveFile.Content)
+		if req.GetMd5().GetValue() != actualMd5 {
+			log.Error("[Config][File] cas compare config file.", utils.RequestID(ctx),
+				zap.String("namespace", req.GetNamespace().GetValue()), zap.String("group", req.GetGroup().GetValue()),
+				zap.String("fileName", req.GetFileName().GetValue()),
+				zap.String("expect", req.GetMd5().GetValue()), zap.String("actual", actualMd5))
+			return api.NewConfigResponse(apimodel.Code_DataConflict)
+		}
+		return nil
+	})
+}
+
+// VersionCasUpsertAndReleaseConfigFile 在事务内比对当前生效的发布版本，一致时才允许进行配置修改发布，
+// version 为 0 表示只在配置文件不存在时写入
+func (s *Server) VersionCasUpsertAndReleaseConfigFile(ctx context.Context,
+	req *apiconfig.ConfigFilePublishInfo, version uint64) *apiconfig.ConfigResponse {
+	return s.casUpsertAndReleaseConfigFile(ctx, req, func(tx store.Tx, saveFile *model.ConfigFile) *apiconfig.ConfigResponse {
+		var actual uint64
+		if saveFile != nil {
+			release, err := s.storage.GetConfigFileActiveReleaseTx(tx, saveFile.Key())
+			if err != nil {
+				log.Error("[Config][File] cas get active release.", utils.RequestID(ctx),
+					zap.String("namespace", saveFile.Namespace), zap.String("group", saveFile.Group),
+					zap.String("fileName", saveFile.Name), zap.Error(err))
+				return api.NewConfigResponse(commonstore.StoreCode2APICode(err))
+			}
+			if release != nil {
+				actual = release.Version
+			}
+		}
+		if actual != version {
+			log.Info("[Config][File] cas compare config release version.", utils.RequestID(ctx),
+				zap.String("namespace", req.GetNamespace().GetValue()), zap.String("group", req.GetGroup().GetValue()),
+				zap.String("fileName", req.GetFileName().GetValue()),
+				zap.Uint64("expect", version), zap.Uint64("actual", actual))
+			return api.NewConfigResponse(apimodel.Code_DataConflict)
+		}
+		return nil
+	})
+}
+
+// casUpsertAndReleaseConfigFile 锁定配置文件后调用 check 进行比对，比对通过时在同一事务中完成修改和发布
+func (s *Server) casUpsertAndReleaseConfigFile(ctx context.Context, req *apiconfig.ConfigFilePublishInfo,
+	check func(tx store.Tx, saveFile *model.ConfigFile) *apiconfig.ConfigResponse) *apiconfig.ConfigResponse {
 	upsertFileReq := &apiconfig.ConfigFile{
 		Name:        req.GetFileName(),
 		Namespace:   req.GetNamespace(),
@@ -533,6 +582,10 @@ func (s *Server) CasUpsertAndReleaseConfigFile(ctx context.Context,
 		return api.NewConfigResponse(commonstore.StoreCode2APICode(err))
 	}
 
+	if rsp := check(tx, saveFile); rsp != nil {
+		return rsp
+	}
+
 	historyRecords := []func(){}
 
 	var upsertResp *apiconfig.ConfigResponse
@@ -542,14 +595,6 @@ func (s *Server) CasUpsertAndReleaseConfigFile(ctx context.Context,
 			s.RecordHistory(ctx, configFileRecordEntry(ctx, upsertFileReq, model.OCreate))
 		})
 	} else {
-		actualMd5 := CalMd5(saveFile.Content)
-		if req.GetMd5().GetValue() != actualMd5 {
-			log.Error("[Config][File] cas compare config file.", utils.RequestID(ctx),
-				zap.String("namespace", req.GetNamespace().GetValue()), zap.String("group", req.GetGroup().GetValue()),
-				zap.String("fileName", req.GetFileName().GetValue()),
-				zap.String("expect", req.GetMd5().GetValue()), zap.String("actual", actualMd5))
-			return api.NewConfigResponse(apimodel.Code_DataConflict)
-		}
 		upsertResp = s.handleUpdateConfigFile(ctx, tx, upsertFileReq)
 		historyRecords = append(historyRecords, func() {
 			s.RecordHistory(ctx, configFileRecordEntry(ctx, upsertFileReq, model.OUpdate))
@@ -18,6 +18,9 @@
 package config_test
 
 import (
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -804,3 +807,72 @@ func TestServer_CasUpsertAndReleaseConfigFile(t *testing.T) {
 		})
 	})
 }
+
+func TestServer_VersionCasUpsertAndReleaseConfigFile(t *testing.T) {
+	testSuit := newConfigCenterTestSuit(t)
+
+	var (
+		mockNamespace = "mock_namespace_version_cas"
+		mockGroup     = "mock_group"
+		mockFileName  = "mock_filename"
+		concurrency   = 8
+	)
+
+	nsRsp := testSuit.NamespaceServer().CreateNamespace(testSuit.DefaultCtx, &apimodel.Namespace{
+		Name: utils.NewStringValue(mockNamespace),
+	})
+	assert.Equal(t, uint32(apimodel.Code_ExecuteSuccess), nsRsp.GetCode().GetValue(), nsRsp.GetInfo().GetValue())
+
+	// 并发使用同一个版本号写入，只能有一个成功，其余都返回数据冲突
+	casPublish := func(version uint64) int {
+		var (
+			wg      sync.WaitGroup
+			success int32
+		)
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				rsp := testSuit.ConfigServer().VersionCasUpsertAndReleaseConfigFileFromClient(testSuit.DefaultCtx,
+					&config_manage.ConfigFilePublishInfo{
+						Namespace: utils.NewStringValue(mockNamespace),
+						Group:     utils.NewStringValue(mockGroup),
+						FileName:  utils.NewStringValue(mockFileName),
+						Content:   utils.NewStringValue(fmt.Sprintf("content-%d-%d", version, i)),
+					}, version)
+				switch rsp.GetCode().GetValue() {
+				case uint32(apimodel.Code_ExecuteSuccess):
+					atomic.AddInt32(&success, 1)
+				case uint32(apimodel.Code_DataConflict):
+				default:
+					t.Errorf("unexpected code %d, info %s", rsp.GetCode().GetValue(), rsp.GetInfo().GetValue())
+				}
+			}(i)
+		}
+		wg.Wait()
+		return int(success)
+	}
+
+	activeVersion := func() uint64 {
+		rsp := testSuit.ConfigServer().GetConfigFileRelease(testSuit.DefaultCtx, &config_manage.ConfigFileRelease{
+			Namespace: utils.NewStringValue(mockNamespace),
+			Group:     utils.NewStringValue(mockGroup),
+			FileName:  utils.NewStringValue(mockFileName),
+		})
+		assert.Equal(t, uint32(apimodel.Code_ExecuteSuccess), rsp.GetCode().GetValue(), rsp.GetInfo().GetValue())
+		return rsp.GetConfigFileRelease().GetVersion().GetValue()
+	}
+
+	t.Run("create_only_once", func(t *testing.T) {
+		assert.Equal(t, 1, casPublish(0))
+	})
+
+	t.Run("update_only_once", func(t *testing.T) {
+		version := activeVersion()
+		assert.NotZero(t, version)
+		assert.Equal(t, 1, casPublish(version))
+		assert.NotEqual(t, version, activeVersion())
+		// 旧的版本号不能再写入
+		assert.Equal(t, 0, casPublish(version))
+	})
+}
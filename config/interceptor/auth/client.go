@@ -200,3 +200,19 @@ func (s *Server) CasUpsertAndReleaseConfigFileFromClient(ctx context.Context,
 
 	return s.nextServer.CasUpsertAndReleaseConfigFileFromClient(ctx, req)
 }
+
+// VersionCasUpsertAndReleaseConfigFileFromClient 当前生效的发布版本与 version 一致时创建/更新配置文件并发布
+func (s *Server) VersionCasUpsertAndReleaseConfigFileFromClient(ctx context.Context,
+	req *apiconfig.ConfigFilePublishInfo, version uint64) *apiconfig.ConfigResponse {
+
+	authCtx := s.collectConfigFilePublishAuthContext(ctx, []*apiconfig.ConfigFilePublishInfo{req},
+		auth.Modify, auth.UpsertAndReleaseConfigFile)
+	if _, err := s.policySvr.GetAuthChecker().CheckClientPermission(authCtx); err != nil {
+		return api.NewConfigFileResponse(auth.ConvertToErrCode(err), nil)
+	}
+
+	ctx = authCtx.GetRequestContext()
+	ctx = context.WithValue(ctx, utils.ContextAuthContextKey, authCtx)
+
+	return s.nextServer.VersionCasUpsertAndReleaseConfigFileFromClient(ctx, req, version)
+}
@@ -217,3 +217,18 @@ func (s *Server) CasUpsertAndReleaseConfigFileFromClient(ctx context.Context,
 	}
 	return s.nextServer.CasUpsertAndReleaseConfigFileFromClient(ctx, req)
 }
+
+// VersionCasUpsertAndReleaseConfigFileFromClient 当前生效的发布版本与 version 一致时创建/更新配置文件并发布
+func (s *Server) VersionCasUpsertAndReleaseConfigFileFromClient(ctx context.Context,
+	req *apiconfig.ConfigFilePublishInfo, version uint64) *apiconfig.ConfigResponse {
+	if err := CheckFileName(req.GetFileName()); err != nil {
+		return api.NewConfigResponse(apimodel.Code_InvalidConfigFileName)
+	}
+	if err := utils.CheckResourceName(req.GetNamespace()); err != nil {
+		return api.NewConfigResponse(apimodel.Code_InvalidNamespaceName)
+	}
+	if err := utils.CheckResourceName(req.GetGroup()); err != nil {
+		return api.NewConfigResponse(apimodel.Code_InvalidConfigFileGroupName)
+	}
+	return s.nextServer.VersionCasUpsertAndReleaseConfigFileFromClient(ctx, req, version)
+}
@@ -25,6 +25,7 @@ import (
 	apiconfig "github.com/polarismesh/specification/source/go/api/v1/config_manage"
 
 	cachetypes "github.com/polarismesh/polaris/cache/api"
+	"github.com/polarismesh/polaris/common/eventhub"
 	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/common/utils"
 	"github.com/polarismesh/polaris/namespace"
@@ -240,6 +241,10 @@ func (s *Server) afterConfigGroupResource(ctx context.Context, req *apiconfig.Co
 
 // RecordHistory server对外提供history插件的简单封装
 func (s *Server) RecordHistory(ctx context.Context, entry *model.RecordEntry) {
+	// 数据已经写入存储，通知缓存按需刷新
+	if entry != nil {
+		eventhub.PublishCacheChange(entry.ResourceType)
+	}
 	// 如果插件没有初始化，那么不记录history
 	if s.history == nil {
 		return
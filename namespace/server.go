@@ -24,6 +24,7 @@ import (
 	"golang.org/x/sync/singleflight"
 
 	"github.com/polarismesh/polaris/cache"
+	"github.com/polarismesh/polaris/common/eventhub"
 	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/plugin"
 	"github.com/polarismesh/polaris/store"
@@ -59,6 +60,10 @@ func (s *Server) afterNamespaceResource(ctx context.Context, req *apimodel.Names
 
 // RecordHistory server对外提供history插件的简单封装
 func (s *Server) RecordHistory(entry *model.RecordEntry) {
+	// 数据已经写入存储，通知缓存按需刷新
+	if entry != nil {
+		eventhub.PublishCacheChange(entry.ResourceType)
+	}
 	// 如果插件没有初始化，那么不记录history
 	if plugin.GetHistory() == nil {
 		return
@@ -19,9 +19,12 @@ package main
 
 import (
 	_ "github.com/polarismesh/polaris/admin/interceptor"
+	_ "github.com/polarismesh/polaris/apiserver/consulserver"
+	_ "github.com/polarismesh/polaris/apiserver/dnsserver"
 	_ "github.com/polarismesh/polaris/apiserver/eurekaserver"
 	_ "github.com/polarismesh/polaris/apiserver/grpcserver/config"
 	_ "github.com/polarismesh/polaris/apiserver/grpcserver/discover"
+	_ "github.com/polarismesh/polaris/apiserver/heartbeatserver"
 	_ "github.com/polarismesh/polaris/apiserver/httpserver"
 	_ "github.com/polarismesh/polaris/apiserver/l5pbserver"
 	_ "github.com/polarismesh/polaris/apiserver/nacosserver"
@@ -41,7 +44,9 @@ import (
 	_ "github.com/polarismesh/polaris/plugin/discoverevent/local"
 	_ "github.com/polarismesh/polaris/plugin/healthchecker/leader"
 	_ "github.com/polarismesh/polaris/plugin/healthchecker/memory"
+	_ "github.com/polarismesh/polaris/plugin/healthchecker/probe"
 	_ "github.com/polarismesh/polaris/plugin/healthchecker/redis"
+	_ "github.com/polarismesh/polaris/plugin/healthchecker/store"
 	_ "github.com/polarismesh/polaris/plugin/history/logger"
 	_ "github.com/polarismesh/polaris/plugin/password"
 	_ "github.com/polarismesh/polaris/plugin/ratelimit/token"
@@ -51,4 +56,5 @@ import (
 	_ "github.com/polarismesh/polaris/service/interceptor"
 	_ "github.com/polarismesh/polaris/store/boltdb"
 	_ "github.com/polarismesh/polaris/store/mysql"
+	_ "github.com/polarismesh/polaris/store/postgresql"
 )
@@ -31,6 +31,8 @@ type ReportRequest struct {
 	LocalHost  string
 	CurTimeSec int64
 	Count      int64
+	// TtlSec heartbeat ttl of the instance, 0 means the instance is not found in cache
+	TtlSec uint32
 }
 
 // CheckRequest check heartbeat request
@@ -38,6 +40,8 @@ type CheckRequest struct {
 	QueryRequest
 	ExpireDurationSec uint32
 	CurTimeSec        func() int64
+	// Metadata merged service and instance metadata, only filled for probe health checker
+	Metadata map[string]string
 }
 
 // CheckResponse check heartbeat response
@@ -46,6 +50,9 @@ type CheckResponse struct {
 	LastHeartbeatTimeSec int64
 	StayUnchanged        bool
 	Regular              bool
+	// NextCheckIntervalSec delay to the next check, used by probe health checker to control the probe interval,
+	// 0 means scheduling by the heartbeat expire duration
+	NextCheckIntervalSec int64
 }
 
 // QueryRequest query heartbeat request
@@ -85,10 +92,13 @@ type HealthCheckType int32
 
 const (
 	HealthCheckerHeartbeat HealthCheckType = iota + 1
+	// HealthCheckerProbe server side active probe, instances choose it by metadata instead of the health check type
+	HealthCheckerProbe
 )
 
 var (
-	healthCheckOnce = &sync.Once{}
+	// healthCheckOnces different type of health checkers can be used at the same time, initialize each plugin once
+	healthCheckOnces = &sync.Map{}
 )
 
 // HealthChecker health checker plugin interface
@@ -121,7 +131,8 @@ func GetHealthChecker(name string, cfg *ConfigEntry) HealthChecker {
 		return nil
 	}
 
-	healthCheckOnce.Do(func() {
+	once, _ := healthCheckOnces.LoadOrStore(name, &sync.Once{})
+	once.(*sync.Once).Do(func() {
 		if err := plugin.Initialize(cfg); err != nil {
 			log.Errorf("HealthChecker plugin init err: %s", err.Error())
 			os.Exit(-1)
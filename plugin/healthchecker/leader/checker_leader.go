@@ -86,6 +86,9 @@ var (
 //   - 非 Leader 节点
 //     a. 心跳写请求通过 gRPC 长连接直接发给 Leader 节点
 //     b. 心跳读请求通过 gRPC 长连接直接发给 Leader 节点，Leader 节点返回心跳时间戳信息
+//
+// 当 mode 配置为 sharded 时不再进行选主，心跳数据按照一致性哈希分散到所有存活的 Polaris 节点，
+// 每个节点只保存归属于自己的心跳数据，详见 shardState
 type LeaderHealthChecker struct {
 	initialize int32
 	// leaderChangeTimeSec last peer list start refresh occur timestamp
@@ -108,6 +111,10 @@ type LeaderHealthChecker struct {
 	s store.Store
 	// subCtx
 	subCtx *eventhub.SubscribtionContext
+	// shard 分片模式下的节点成员视图
+	shard *shardState
+	// cancel .
+	cancel context.CancelFunc
 }
 
 // Name .
@@ -128,6 +135,9 @@ func (c *LeaderHealthChecker) Initialize(entry *plugin.ConfigEntry) error {
 	if err := c.self.Serve(context.Background(), c, "", 0); err != nil {
 		return err
 	}
+	if c.isSharded() {
+		return c.initializeSharded()
+	}
 	subCtx, err := eventhub.Subscribe(eventhub.LeaderChangeEventTopic, c)
 	if err != nil {
 		return err
@@ -147,6 +157,21 @@ func (c *LeaderHealthChecker) Initialize(entry *plugin.ConfigEntry) error {
 	return nil
 }
 
+// initializeSharded 分片模式不需要选主，监听健康检查节点列表的变化
+func (c *LeaderHealthChecker) initializeSharded() error {
+	c.shard = newShardState()
+	subCtx, err := eventhub.Subscribe(eventhub.CheckerMembersChangeTopic, c)
+	if err != nil {
+		return err
+	}
+	c.subCtx = subCtx
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go c.runShardMaintainer(ctx)
+	registerMetrics()
+	return nil
+}
+
 // PreProcess do preprocess logic for event
 func (c *LeaderHealthChecker) PreProcess(ctx context.Context, value any) any {
 	return value
@@ -154,6 +179,10 @@ func (c *LeaderHealthChecker) PreProcess(ctx context.Context, value any) any {
 
 // OnEvent event trigger
 func (c *LeaderHealthChecker) OnEvent(ctx context.Context, i interface{}) error {
+	if members, ok := i.(eventhub.CheckerMembersChangeEvent); ok {
+		c.onMembersChange(members)
+		return nil
+	}
 	e, ok := i.(store.LeaderChangeEvent)
 	if !ok || e.Key != electionKey {
 		return nil
@@ -239,6 +268,12 @@ func (c *LeaderHealthChecker) becomeFollower(e store.LeaderChangeEvent, leaderVe
 // Destroy .
 func (c *LeaderHealthChecker) Destroy() error {
 	c.subCtx.Cancel()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	if c.isSharded() {
+		c.closeShardPeers()
+	}
 	return nil
 }
 
@@ -249,7 +284,7 @@ func (c *LeaderHealthChecker) Type() plugin.HealthCheckType {
 
 // Report process heartbeat info report
 func (c *LeaderHealthChecker) Report(ctx context.Context, request *plugin.ReportRequest) error {
-	if !c.isLeader() && isSendFromPeer(ctx) {
+	if c.rejectPeerRequest(ctx) {
 		plog.Error("[Health Check][Leader] follower checker receive other follower request")
 		return ErrorRedirectOnlyOnce
 	}
@@ -260,7 +295,10 @@ func (c *LeaderHealthChecker) Report(ctx context.Context, request *plugin.Report
 		plog.Debug("[Health Check][Leader] leader checker uninitialize, ignore report")
 		return ErrorLeaderNotInitialize
 	}
-	responsible := c.findLeaderPeer()
+	responsible, err := c.findResponsiblePeer(ctx, request.InstanceId)
+	if err != nil {
+		return err
+	}
 	record := WriteBeatRecord{
 		Record: RecordValue{
 			Server:     responsible.Host(),
@@ -324,7 +362,7 @@ func (c *LeaderHealthChecker) Check(request *plugin.CheckRequest) (*plugin.Check
 func (c *LeaderHealthChecker) BatchQuery(ctx context.Context,
 	request *plugin.BatchQueryRequest) (*plugin.BatchQueryResponse, error) {
 
-	if !c.isLeader() && isSendFromPeer(ctx) {
+	if c.rejectPeerRequest(ctx) {
 		return nil, ErrorRedirectOnlyOnce
 	}
 
@@ -334,20 +372,37 @@ func (c *LeaderHealthChecker) BatchQuery(ctx context.Context,
 		plog.Debug("[Health Check][Leader] leader checker uninitialize, ignore batch query")
 		return &plugin.BatchQueryResponse{}, ErrorLeaderNotInitialize
 	}
-	responsible := c.findLeaderPeer()
 
-	keys := make([]string, 0, len(request.Requests))
+	// 按照归属节点对 key 进行分组
+	responsibles := make(map[string]Peer, len(request.Requests))
+	groups := map[Peer][]string{}
 	for i := range request.Requests {
-		keys = append(keys, request.Requests[i].InstanceId)
+		key := request.Requests[i].InstanceId
+		responsible, err := c.findResponsiblePeer(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		responsibles[key] = responsible
+		groups[responsible] = append(groups[responsible], key)
 	}
-	ret, err := responsible.Storage().Get(keys...)
-	if err != nil {
-		return nil, err
+	ret := make(map[string]*ReadBeatRecord, len(request.Requests))
+	for responsible, keys := range groups {
+		records, err := responsible.Storage().Get(keys...)
+		if err != nil {
+			return nil, err
+		}
+		for key, record := range records {
+			ret[key] = record
+		}
+	}
+	if c.isSharded() && !isSendFromPeer(ctx) {
+		c.mergeHandoffRecords(ret)
 	}
 
 	rsp := &plugin.BatchQueryResponse{Responses: make([]*plugin.QueryResponse, 0, len(request.Requests))}
 	for i := range request.Requests {
 		req := request.Requests[i]
+		responsible := responsibles[req.InstanceId]
 		record, ok := ret[req.InstanceId]
 		if !ok {
 			rsp.Responses = append(rsp.Responses, &plugin.QueryResponse{
@@ -369,7 +424,7 @@ func (c *LeaderHealthChecker) BatchQuery(ctx context.Context,
 
 // Query queries the heartbeat time
 func (c *LeaderHealthChecker) Query(ctx context.Context, request *plugin.QueryRequest) (*plugin.QueryResponse, error) {
-	if !c.isLeader() && isSendFromPeer(ctx) {
+	if c.rejectPeerRequest(ctx) {
 		return nil, ErrorRedirectOnlyOnce
 	}
 
@@ -381,11 +436,17 @@ func (c *LeaderHealthChecker) Query(ctx context.Context, request *plugin.QueryRe
 			LastHeartbeatSec: 0,
 		}, ErrorLeaderNotInitialize
 	}
-	responsible := c.findLeaderPeer()
+	responsible, err := c.findResponsiblePeer(ctx, request.InstanceId)
+	if err != nil {
+		return nil, err
+	}
 	ret, err := responsible.Storage().Get(request.InstanceId)
 	if err != nil {
 		return nil, err
 	}
+	if c.isSharded() && !isSendFromPeer(ctx) {
+		c.mergeHandoffRecords(ret)
+	}
 	record := ret[request.InstanceId]
 	log.Debugf("[HealthCheck][Leader] query hb record, instanceId %s, record %+v", request.InstanceId, record)
 	return &plugin.QueryResponse{
@@ -398,7 +459,7 @@ func (c *LeaderHealthChecker) Query(ctx context.Context, request *plugin.QueryRe
 
 // Delete delete record by key
 func (c *LeaderHealthChecker) Delete(ctx context.Context, key string) error {
-	if !c.isLeader() && isSendFromPeer(ctx) {
+	if c.rejectPeerRequest(ctx) {
 		return ErrorRedirectOnlyOnce
 	}
 	if !c.isInitialize() {
@@ -407,7 +468,16 @@ func (c *LeaderHealthChecker) Delete(ctx context.Context, key string) error {
 	}
 	c.lock.RLock()
 	defer c.lock.RUnlock()
-	responsible := c.findLeaderPeer()
+	responsible, err := c.findResponsiblePeer(ctx, key)
+	if err != nil {
+		return err
+	}
+	if c.isSharded() && !isSendFromPeer(ctx) {
+		// 交接窗口内旧归属节点上的数据同样需要删除，避免读请求合并出已经删除的心跳
+		if peer, ok := c.findHandoffPeer(key); ok {
+			_ = peer.Storage().Del(key)
+		}
+	}
 	return responsible.Storage().Del(key)
 }
 
@@ -423,6 +493,23 @@ func (c *LeaderHealthChecker) SuspendTimeSec() int64 {
 	return atomic.LoadInt64(&c.suspendTimeSec)
 }
 
+// findResponsiblePeer 获取负责保存心跳 key 的节点，需要持有读锁
+func (c *LeaderHealthChecker) findResponsiblePeer(ctx context.Context, key string) (Peer, error) {
+	if !c.isSharded() {
+		return c.findLeaderPeer(), nil
+	}
+	// 对端转发过来的请求直接在本地处理，避免节点间成员视图不一致时来回转发
+	if isSendFromPeer(ctx) {
+		return c.self, nil
+	}
+	return c.findShardPeer(c.shard.current.owner(key))
+}
+
+// rejectPeerRequest Leader 模式下 follower 不处理其他 follower 转发过来的请求
+func (c *LeaderHealthChecker) rejectPeerRequest(ctx context.Context) bool {
+	return !c.isSharded() && !c.isLeader() && isSendFromPeer(ctx)
+}
+
 func (c *LeaderHealthChecker) findLeaderPeer() Peer {
 	if c.isLeader() {
 		return c.self
@@ -447,6 +534,14 @@ func (c *LeaderHealthChecker) skipCheck(key string, expireDurationSec int64) boo
 		return true
 	}
 
+	if c.isSharded() {
+		if c.skipShardCheck(key) {
+			log.Infof("[Health Check][Leader] health check shard on handoff, id %s", key)
+			return true
+		}
+		return false
+	}
+
 	// 当 T1 时刻出现 Leader 节点切换，到 T2 时刻 Leader 节点切换成，在这期间，可能会出现以下情况
 	// case 1: T1~T2 时刻不存在 Leader
 	// case 2: T1～T2 时刻存在多个 Leader
@@ -489,6 +584,11 @@ func (c *LeaderHealthChecker) DebugHandlers() []model.DebugHandler {
 			Desc:    "Query heart rate data information, only Leader node processing",
 			Handler: handleDescribeBeatCache(c),
 		},
+		{
+			Path:    "/debug/checker/leader/shards",
+			Desc:    "Query heartbeat shard ownership, only sharded mode processing",
+			Handler: handleDescribeShards(c),
+		},
 	}
 }
 
@@ -498,5 +598,11 @@ func isSendFromPeer(ctx context.Context) bool {
 			return true
 		}
 	}
+	// 经过 utils.ConvertGRPCContext 转换后的请求上下文
+	if md, ok := ctx.Value(utils.ContextGrpcHeader).(metadata.MD); ok {
+		if _, exist := md[sendResource]; exist {
+			return true
+		}
+	}
 	return false
 }
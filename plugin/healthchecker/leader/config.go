@@ -18,20 +18,38 @@
 package leader
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/mitchellh/mapstructure"
 )
 
+const (
+	// ModeLeader 心跳数据全部由 Leader 节点保存
+	ModeLeader = "leader"
+	// ModeSharded 心跳数据按照一致性哈希分散保存在所有存活的 Polaris 节点上
+	ModeSharded = "sharded"
+	// DefaultHandoffGrace 分片归属变化后的默认交接窗口
+	DefaultHandoffGrace = 30 * time.Second
+)
+
 type Config struct {
 	SoltNum   int32 `json:"soltNum"`
 	StreamNum int32 `json:"streamNum"`
+	// Mode 心跳数据的归属模式，leader 或 sharded
+	Mode string `json:"mode"`
+	// HandoffGrace 分片归属变化后，新旧归属节点共同提供心跳数据的时间窗口，需要大于实例的心跳 TTL
+	HandoffGrace time.Duration `json:"handoffGrace"`
 	// only use for test
 	checkLeader bool
 }
 
 func unmarshal(options map[string]interface{}) (*Config, error) {
 	config := &Config{
-		SoltNum:   DefaultSoltNum,
-		StreamNum: int32(streamNum),
+		SoltNum:      DefaultSoltNum,
+		StreamNum:    int32(streamNum),
+		Mode:         ModeLeader,
+		HandoffGrace: DefaultHandoffGrace,
 	}
 	decodeConfig := &mapstructure.DecoderConfig{
 		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
@@ -44,5 +62,11 @@ func unmarshal(options map[string]interface{}) (*Config, error) {
 	if err = decoder.Decode(options); err != nil {
 		return nil, err
 	}
+	if config.Mode != ModeLeader && config.Mode != ModeSharded {
+		return nil, fmt.Errorf("[HealthCheck][Leader] unsupported mode %s", config.Mode)
+	}
+	if config.HandoffGrace <= 0 {
+		config.HandoffGrace = DefaultHandoffGrace
+	}
 	return config, nil
 }
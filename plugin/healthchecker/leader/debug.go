@@ -20,6 +20,7 @@ package leader
 import (
 	"encoding/json"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/polarismesh/polaris/common/utils"
 )
@@ -59,7 +60,7 @@ func handleDescribeBeatCache(checker *LeaderHealthChecker) func(http.ResponseWri
 
 		ret := map[string]interface{}{}
 		ret["self"] = utils.LocalHost
-		if checker.isLeader() {
+		if checker.isSharded() || checker.isLeader() {
 			ret["data"] = checker.self.(*LocalPeer).Cache.Snapshot()
 		} else {
 			ret["data"] = "Not Leader"
@@ -70,3 +71,49 @@ func handleDescribeBeatCache(checker *LeaderHealthChecker) func(http.ResponseWri
 		_, _ = resp.Write(data)
 	}
 }
+
+func handleDescribeShards(checker *LeaderHealthChecker) func(http.ResponseWriter, *http.Request) {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if !checker.isSharded() {
+			resp.WriteHeader(http.StatusBadRequest)
+			_, _ = resp.Write([]byte("LeaderChecker not in sharded mode"))
+			return
+		}
+		if !checker.isInitialize() {
+			resp.WriteHeader(http.StatusTooEarly)
+			_, _ = resp.Write([]byte("LeaderChecker not initialize"))
+			return
+		}
+
+		checker.lock.RLock()
+		ret := map[string]interface{}{}
+		ret["self"] = utils.LocalHost
+		ret["members"] = checker.shard.current.members
+		ret["lastMembersChangeTimeSec"] = atomic.LoadInt64(&checker.shard.changeTimeSec)
+		ret["inHandoff"] = checker.inHandoff()
+		if checker.shard.previous != nil {
+			ret["previousMembers"] = checker.shard.previous.members
+		}
+		peers := map[string]bool{}
+		for host, peer := range checker.shard.peers {
+			peers[host] = peer.IsAlive()
+		}
+		ret["peers"] = peers
+		// 查询指定心跳 key 的归属节点
+		if key := req.URL.Query().Get("key"); key != "" {
+			owner := map[string]string{
+				"current": checker.shard.current.owner(key),
+			}
+			if checker.shard.previous != nil {
+				owner["previous"] = checker.shard.previous.owner(key)
+			}
+			ret["owner"] = owner
+		}
+		checker.lock.RUnlock()
+		ret["ownedKeys"] = len(checker.self.Storage().Snapshot())
+
+		data, _ := json.Marshal(ret)
+		resp.WriteHeader(http.StatusOK)
+		_, _ = resp.Write(data)
+	}
+}
@@ -189,10 +189,7 @@ func (p *RemotePeer) Ping() error {
 	if err != nil {
 		return err
 	}
-	_, err = client.BatchGetHeartbeat(context.Background(), &apiservice.GetHeartbeatsRequest{},
-		grpc.Header(&metadata.MD{
-			sendResource: []string{utils.LocalHost},
-		}))
+	_, err = client.BatchGetHeartbeat(newPeerContext(), &apiservice.GetHeartbeatsRequest{})
 	return err
 }
 
@@ -213,9 +210,7 @@ func (p *RemotePeer) GetFunc(req *apiservice.GetHeartbeatsRequest) (*apiservice.
 	if err != nil {
 		return nil, err
 	}
-	resp, err := client.BatchGetHeartbeat(context.Background(), req, grpc.Header(&metadata.MD{
-		sendResource: []string{utils.LocalHost},
-	}))
+	resp, err := client.BatchGetHeartbeat(newPeerContext(), req)
 	if err != nil {
 		code = "-1"
 		plog.Error("[HealthCheck][Leader] send get record request", zap.String("host", p.Host()),
@@ -268,9 +263,7 @@ func (p *RemotePeer) DelFunc(req *apiservice.DelHeartbeatsRequest) error {
 	if err != nil {
 		return err
 	}
-	if _, err := client.BatchDelHeartbeat(context.Background(), req, grpc.Header(&metadata.MD{
-		sendResource: []string{utils.LocalHost},
-	})); err != nil {
+	if _, err := client.BatchDelHeartbeat(newPeerContext(), req); err != nil {
 		code = "-1"
 		plog.Error("send del record request", zap.String("host", p.Host()),
 			zap.Uint32("port", p.port), zap.Error(err))
@@ -368,6 +361,11 @@ func (p *RemotePeer) doClose() {
 	}
 }
 
+// newPeerContext 转发给其他节点的请求携带来源标识，对端据此判断请求是否已经被转发过
+func newPeerContext() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), sendResource, utils.LocalHost)
+}
+
 func createBeatClient(conn *grpc.ClientConn) (apiservice.PolarisHeartbeatGRPCClient, error) {
 	return apiservice.NewPolarisHeartbeatGRPCClient(conn), nil
 }
@@ -460,9 +458,7 @@ func doConnect(p *RemotePeer) error {
 
 func newBeatSender(index int, conn *grpc.ClientConn, p *RemotePeer) (*beatSender, error) {
 	client := apiservice.NewPolarisHeartbeatGRPCClient(conn)
-	puter, err := client.BatchHeartbeat(context.Background(), grpc.Header(&metadata.MD{
-		sendResource: []string{utils.LocalHost},
-	}))
+	puter, err := client.BatchHeartbeat(newPeerContext())
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,313 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/polarismesh/polaris/common/eventhub"
+	commonhash "github.com/polarismesh/polaris/common/hash"
+	commontime "github.com/polarismesh/polaris/common/time"
+	"github.com/polarismesh/polaris/common/utils"
+)
+
+var (
+	ErrorShardPeerNotAvailable = errors.New("shard owner peer not available")
+)
+
+const (
+	// shardWeight 与健康检查分发器保持一致的节点权重，保证实例的心跳归属节点与检查节点相同
+	shardWeight = 100
+	// shardMaintainInterval 分片对端连接补偿以及交接窗口清理的执行间隔
+	shardMaintainInterval = time.Second
+)
+
+// shardRing 某一时刻存活节点列表对应的一致性哈希环
+type shardRing struct {
+	members   []string
+	continuum *commonhash.Continuum
+}
+
+func newShardRing(members []string) *shardRing {
+	buckets := make(map[commonhash.Bucket]bool, len(members))
+	for i := range members {
+		buckets[commonhash.Bucket{Host: members[i], Weight: shardWeight}] = true
+	}
+	return &shardRing{
+		members:   members,
+		continuum: commonhash.New(buckets),
+	}
+}
+
+// owner 获取心跳 key 的归属节点
+func (r *shardRing) owner(key string) string {
+	if r == nil || r.continuum == nil {
+		return ""
+	}
+	return r.continuum.Hash(commonhash.HashString(key))
+}
+
+func (r *shardRing) contains(host string) bool {
+	if r == nil {
+		return false
+	}
+	for i := range r.members {
+		if r.members[i] == host {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *shardRing) sameMembers(members []string) bool {
+	if r == nil || len(r.members) != len(members) {
+		return false
+	}
+	for i := range members {
+		if r.members[i] != members[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// shardState 分片模式下的节点成员视图
+// 1. 成员变化时，previous 保存变化前的哈希环，在 HandoffGrace 窗口内
+//   - 读请求同时查询新旧归属节点，取最新的心跳时间
+//   - 归属节点发生变化的 key 跳过健康检查，避免新归属节点还未收到心跳导致实例被误判为不健康
+//
+// 2. 窗口结束后清理不再归属自己的心跳数据，并关闭已经离开集群的节点连接
+type shardState struct {
+	// current 当前的哈希环
+	current *shardRing
+	// previous 成员变化前的哈希环，交接窗口结束后置空
+	previous *shardRing
+	// changeTimeSec 最近一次成员变化的时间
+	changeTimeSec int64
+	// peers 其他节点的连接，key 为节点地址
+	peers map[string]Peer
+}
+
+func newShardState() *shardState {
+	return &shardState{
+		peers: map[string]Peer{},
+	}
+}
+
+func (c *LeaderHealthChecker) isSharded() bool {
+	return c.conf != nil && c.conf.Mode == ModeSharded
+}
+
+// onMembersChange 处理健康检查节点列表变化事件
+func (c *LeaderHealthChecker) onMembersChange(e eventhub.CheckerMembersChangeEvent) {
+	if len(e.Members) == 0 {
+		return
+	}
+	// 建立连接比较耗时，在锁外完成，避免阻塞心跳的读写
+	peers := c.connectShardPeers(e.Members)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for host, peer := range peers {
+		if _, ok := c.shard.peers[host]; ok {
+			_ = peer.Close()
+			continue
+		}
+		c.shard.peers[host] = peer
+	}
+	if c.shard.current.sameMembers(e.Members) {
+		return
+	}
+	plog.Info("[HealthCheck][Leader] shard members change", zap.Strings("members", e.Members),
+		zap.Int("peers", len(c.shard.peers)))
+	if c.shard.current != nil {
+		c.shard.previous = c.shard.current
+	}
+	c.shard.current = newShardRing(e.Members)
+	atomic.StoreInt64(&c.shard.changeTimeSec, commontime.CurrentMillisecond()/1000)
+	atomic.StoreInt32(&c.initialize, initializedSignal)
+}
+
+// connectShardPeers 连接成员列表中尚未建立连接的节点
+func (c *LeaderHealthChecker) connectShardPeers(members []string) map[string]Peer {
+	c.lock.RLock()
+	waitConnect := make([]string, 0, len(members))
+	for i := range members {
+		if members[i] == c.self.Host() {
+			continue
+		}
+		if _, ok := c.shard.peers[members[i]]; !ok {
+			waitConnect = append(waitConnect, members[i])
+		}
+	}
+	c.lock.RUnlock()
+
+	peers := make(map[string]Peer, len(waitConnect))
+	for _, host := range waitConnect {
+		peer := NewRemotePeerFunc()
+		peer.Initialize(*c.conf)
+		if err := peer.Serve(context.Background(), c, host, uint32(utils.LocalPort)); err != nil {
+			_ = peer.Close()
+			plog.Error("[HealthCheck][Leader] connect shard peer, wait retry", zap.String("host", host),
+				zap.Error(err))
+			continue
+		}
+		peers[host] = peer
+	}
+	return peers
+}
+
+// runShardMaintainer 补偿连接失败的节点，交接窗口结束后清理过期的数据以及连接
+func (c *LeaderHealthChecker) runShardMaintainer(ctx context.Context) {
+	ticker := time.NewTicker(shardMaintainInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.lock.RLock()
+			current := c.shard.current
+			c.lock.RUnlock()
+			if current == nil {
+				continue
+			}
+			c.onMembersChange(eventhub.CheckerMembersChangeEvent{Members: current.members})
+			c.finishHandoff()
+		}
+	}
+}
+
+// finishHandoff 交接窗口结束，丢弃旧的哈希环
+func (c *LeaderHealthChecker) finishHandoff() {
+	c.lock.Lock()
+	if c.shard.previous == nil || c.inHandoff() {
+		c.lock.Unlock()
+		return
+	}
+	c.shard.previous = nil
+	current := c.shard.current
+	for host, peer := range c.shard.peers {
+		if current.contains(host) {
+			continue
+		}
+		plog.Info("[HealthCheck][Leader] close shard peer which leave cluster", zap.String("host", host))
+		_ = peer.Close()
+		delete(c.shard.peers, host)
+	}
+	c.lock.Unlock()
+
+	// 清理不再归属于自己的心跳数据
+	expired := make([]string, 0, 32)
+	for key := range c.self.Storage().Snapshot() {
+		if current.owner(key) != c.self.Host() {
+			expired = append(expired, key)
+		}
+	}
+	if len(expired) > 0 {
+		_ = c.self.Storage().Del(expired...)
+	}
+	plog.Info("[HealthCheck][Leader] shard handoff finished", zap.Int("removed", len(expired)))
+}
+
+func (c *LeaderHealthChecker) inHandoff() bool {
+	changeTimeSec := atomic.LoadInt64(&c.shard.changeTimeSec)
+	localCurTimeSec := commontime.CurrentMillisecond() / 1000
+	return changeTimeSec > 0 && localCurTimeSec-changeTimeSec < int64(c.conf.HandoffGrace/time.Second)
+}
+
+// findShardPeer 获取节点对应的 Peer，需要持有读锁
+func (c *LeaderHealthChecker) findShardPeer(host string) (Peer, error) {
+	if host == c.self.Host() {
+		return c.self, nil
+	}
+	peer, ok := c.shard.peers[host]
+	if !ok {
+		return nil, ErrorShardPeerNotAvailable
+	}
+	return peer, nil
+}
+
+// findHandoffPeer 交接窗口内获取 key 之前的归属节点，归属没有变化时返回 false，需要持有读锁
+func (c *LeaderHealthChecker) findHandoffPeer(key string) (Peer, bool) {
+	if c.shard.previous == nil || !c.inHandoff() {
+		return nil, false
+	}
+	previousOwner := c.shard.previous.owner(key)
+	if previousOwner == c.shard.current.owner(key) {
+		return nil, false
+	}
+	peer, err := c.findShardPeer(previousOwner)
+	if err != nil {
+		return nil, false
+	}
+	return peer, true
+}
+
+// mergeHandoffRecords 交接窗口内，从旧归属节点补充尚未在新归属节点出现的心跳数据，需要持有读锁
+func (c *LeaderHealthChecker) mergeHandoffRecords(ret map[string]*ReadBeatRecord) {
+	handoffKeys := map[Peer][]string{}
+	for key := range ret {
+		if peer, ok := c.findHandoffPeer(key); ok {
+			handoffKeys[peer] = append(handoffKeys[peer], key)
+		}
+	}
+	for peer, keys := range handoffKeys {
+		records, err := peer.Storage().Get(keys...)
+		if err != nil {
+			log.Debugf("[HealthCheck][Leader] query handoff records from %s fail: %s", peer.Host(), err)
+			continue
+		}
+		for key, record := range records {
+			if !record.Exist {
+				continue
+			}
+			if cur, ok := ret[key]; !ok || !cur.Exist || cur.Record.CurTimeSec < record.Record.CurTimeSec {
+				ret[key] = record
+			}
+		}
+	}
+}
+
+// skipShardCheck 交接窗口内，归属节点发生变化的 key 跳过检查
+func (c *LeaderHealthChecker) skipShardCheck(key string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if !c.inHandoff() {
+		return false
+	}
+	// 刚加入集群时没有旧的哈希环，所有 key 都视为刚完成迁移
+	if c.shard.previous == nil {
+		return true
+	}
+	return c.shard.previous.owner(key) != c.shard.current.owner(key)
+}
+
+func (c *LeaderHealthChecker) closeShardPeers() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for host, peer := range c.shard.peers {
+		_ = peer.Close()
+		delete(c.shard.peers, host)
+	}
+}
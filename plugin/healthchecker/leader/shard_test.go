@@ -0,0 +1,316 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/polarismesh/polaris/common/eventhub"
+	commonhash "github.com/polarismesh/polaris/common/hash"
+	commontime "github.com/polarismesh/polaris/common/time"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store/mock"
+)
+
+// mockShardPeer 模拟其他节点，心跳数据保存在固定的本地缓存中
+type mockShardPeer struct {
+	host   string
+	cache  BeatRecordCache
+	closed int32
+}
+
+func (mp *mockShardPeer) Initialize(conf Config) {}
+
+func (mp *mockShardPeer) Serve(ctx context.Context, checker *LeaderHealthChecker,
+	listenIP string, listenPort uint32) error {
+	mp.host = listenIP
+	return nil
+}
+
+func (mp *mockShardPeer) Close() error {
+	atomic.StoreInt32(&mp.closed, 1)
+	return nil
+}
+
+func (mp *mockShardPeer) Host() string {
+	return mp.host
+}
+
+func (mp *mockShardPeer) Storage() BeatRecordCache {
+	return mp.cache
+}
+
+func (mp *mockShardPeer) IsAlive() bool {
+	return atomic.LoadInt32(&mp.closed) == 0
+}
+
+func newShardedChecker(t *testing.T) (*LeaderHealthChecker, map[string]*mockShardPeer) {
+	ctrl := gomock.NewController(t)
+	eventhub.InitEventHub()
+	t.Cleanup(func() {
+		ctrl.Finish()
+	})
+	remotes := map[string]*mockShardPeer{}
+	oldNewRemoteFunc := NewRemotePeerFunc
+	NewRemotePeerFunc = func() Peer {
+		return &mockShardPeer{cache: newLocalBeatRecordCache(1, commonhash.Fnv32)}
+	}
+	t.Cleanup(func() {
+		NewRemotePeerFunc = oldNewRemoteFunc
+	})
+
+	checker := &LeaderHealthChecker{
+		self: NewLocalPeerFunc(),
+		s:    mock.NewMockStore(ctrl),
+	}
+	err := checker.Initialize(&plugin.ConfigEntry{
+		Option: map[string]interface{}{
+			"mode":         ModeSharded,
+			"handoffGrace": "30s",
+		},
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = checker.Destroy()
+	})
+	return checker, remotes
+}
+
+func collectRemotes(checker *LeaderHealthChecker, remotes map[string]*mockShardPeer) {
+	checker.lock.RLock()
+	defer checker.lock.RUnlock()
+	for host, peer := range checker.shard.peers {
+		remotes[host] = peer.(*mockShardPeer)
+	}
+}
+
+func reportBeat(t *testing.T, checker *LeaderHealthChecker, ctx context.Context, key string) {
+	err := checker.Report(ctx, &plugin.ReportRequest{
+		QueryRequest: plugin.QueryRequest{InstanceId: key},
+		LocalHost:    utils.LocalHost,
+		CurTimeSec:   commontime.CurrentMillisecond() / 1000,
+	})
+	assert.NoError(t, err)
+}
+
+func Test_ShardRing(t *testing.T) {
+	members := []string{"127.0.0.1", "127.0.0.2", "127.0.0.3"}
+	ring := newShardRing(members)
+
+	// 与健康检查分发器使用相同的哈希环，保证检查节点和心跳归属节点一致
+	buckets := map[commonhash.Bucket]bool{}
+	for _, member := range members {
+		buckets[commonhash.Bucket{Host: member, Weight: 100}] = true
+	}
+	continuum := commonhash.New(buckets)
+	owners := map[string]int{}
+	for i := 0; i < 300; i++ {
+		key := fmt.Sprintf("instance-%d", i)
+		owner := ring.owner(key)
+		assert.Equal(t, continuum.Hash(commonhash.HashString(key)), owner)
+		owners[owner]++
+	}
+	assert.Len(t, owners, 3)
+
+	assert.True(t, ring.sameMembers([]string{"127.0.0.1", "127.0.0.2", "127.0.0.3"}))
+	assert.False(t, ring.sameMembers([]string{"127.0.0.1", "127.0.0.2"}))
+	assert.True(t, ring.contains("127.0.0.2"))
+	assert.False(t, ring.contains("127.0.0.4"))
+
+	var nilRing *shardRing
+	assert.Equal(t, "", nilRing.owner("instance-1"))
+	assert.False(t, nilRing.sameMembers(members))
+}
+
+func Test_ShardedHealthChecker(t *testing.T) {
+	checker, remotes := newShardedChecker(t)
+	self := utils.LocalHost
+	remoteHost := "127.0.0.3"
+
+	t.Run("uninitialize", func(t *testing.T) {
+		err := checker.Report(context.Background(), &plugin.ReportRequest{})
+		assert.ErrorIs(t, err, ErrorLeaderNotInitialize)
+		assert.True(t, checker.skipCheck("instance-1", 15))
+	})
+
+	keys := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		keys = append(keys, fmt.Sprintf("instance-%d", i))
+	}
+
+	t.Run("single_member", func(t *testing.T) {
+		_ = checker.OnEvent(context.Background(), eventhub.CheckerMembersChangeEvent{Members: []string{self}})
+		assert.True(t, checker.isInitialize())
+		// 刚加入集群时，交接窗口内所有的 key 都不检查
+		assert.True(t, checker.skipCheck(keys[0], 15))
+		for _, key := range keys {
+			reportBeat(t, checker, context.Background(), key)
+		}
+		assert.Len(t, checker.self.Storage().Snapshot(), len(keys))
+		atomic.StoreInt64(&checker.shard.changeTimeSec, commontime.CurrentMillisecond()/1000-60)
+		assert.False(t, checker.skipCheck(keys[0], 15))
+	})
+
+	members := []string{self, remoteHost}
+	ring := newShardRing(members)
+	moved := make([]string, 0, len(keys))
+	stayed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if ring.owner(key) == remoteHost {
+			moved = append(moved, key)
+		} else {
+			stayed = append(stayed, key)
+		}
+	}
+	assert.NotEmpty(t, moved)
+	assert.NotEmpty(t, stayed)
+
+	t.Run("member_join", func(t *testing.T) {
+		_ = checker.OnEvent(context.Background(), eventhub.CheckerMembersChangeEvent{Members: members})
+		collectRemotes(checker, remotes)
+		assert.Contains(t, remotes, remoteHost)
+
+		// 归属变化的 key 在交接窗口内跳过检查
+		assert.True(t, checker.skipCheck(moved[0], 15))
+		assert.False(t, checker.skipCheck(stayed[0], 15))
+
+		// 新归属节点还没有收到心跳时，从旧归属节点读取
+		rsp, err := checker.Query(context.Background(), &plugin.QueryRequest{InstanceId: moved[0]})
+		assert.NoError(t, err)
+		assert.True(t, rsp.Exists)
+		assert.Equal(t, remoteHost, rsp.Server)
+
+		// 新的心跳写入新归属节点
+		reportBeat(t, checker, context.Background(), moved[0])
+		ret, err := remotes[remoteHost].cache.Get(moved[0])
+		assert.NoError(t, err)
+		assert.True(t, ret[moved[0]].Exist)
+
+		batchReq := &plugin.BatchQueryRequest{}
+		for _, key := range []string{moved[0], moved[len(moved)-1], stayed[0]} {
+			batchReq.Requests = append(batchReq.Requests, &plugin.QueryRequest{InstanceId: key})
+		}
+		batchRsp, err := checker.BatchQuery(context.Background(), batchReq)
+		assert.NoError(t, err)
+		assert.Len(t, batchRsp.Responses, 3)
+		for _, item := range batchRsp.Responses {
+			assert.True(t, item.Exists)
+		}
+		assert.Equal(t, remoteHost, batchRsp.Responses[0].Server)
+		assert.Equal(t, self, batchRsp.Responses[2].Server)
+	})
+
+	t.Run("peer_request", func(t *testing.T) {
+		// 对端转发的请求即使不归属于自己，也直接在本地处理
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{
+			sendResource: remoteHost,
+		}))
+		key := moved[len(moved)-1]
+		reportBeat(t, checker, ctx, key)
+		rsp, err := checker.Query(ctx, &plugin.QueryRequest{InstanceId: key})
+		assert.NoError(t, err)
+		assert.Equal(t, self, rsp.Server)
+
+		converted := context.WithValue(context.Background(), utils.ContextGrpcHeader, metadata.New(map[string]string{
+			sendResource: remoteHost,
+		}))
+		assert.True(t, isSendFromPeer(converted))
+		assert.False(t, isSendFromPeer(context.Background()))
+	})
+
+	t.Run("handoff_finish", func(t *testing.T) {
+		checker.finishHandoff()
+		checker.lock.RLock()
+		assert.NotNil(t, checker.shard.previous)
+		checker.lock.RUnlock()
+
+		atomic.StoreInt64(&checker.shard.changeTimeSec, commontime.CurrentMillisecond()/1000-60)
+		checker.finishHandoff()
+		checker.lock.RLock()
+		assert.Nil(t, checker.shard.previous)
+		checker.lock.RUnlock()
+		assert.False(t, checker.skipCheck(moved[0], 15))
+
+		// 已经迁移到其他节点的数据被清理
+		snapshot := checker.self.Storage().Snapshot()
+		assert.Len(t, snapshot, len(stayed))
+		for _, key := range moved {
+			assert.NotContains(t, snapshot, key)
+		}
+	})
+
+	t.Run("member_leave", func(t *testing.T) {
+		_ = checker.OnEvent(context.Background(), eventhub.CheckerMembersChangeEvent{Members: []string{self}})
+		assert.True(t, checker.skipCheck(moved[0], 15))
+		assert.False(t, checker.skipCheck(stayed[0], 15))
+
+		err := checker.Delete(context.Background(), moved[0])
+		assert.NoError(t, err)
+		rsp, err := checker.Query(context.Background(), &plugin.QueryRequest{InstanceId: moved[0]})
+		assert.NoError(t, err)
+		assert.False(t, rsp.Exists)
+
+		atomic.StoreInt64(&checker.shard.changeTimeSec, commontime.CurrentMillisecond()/1000-60)
+		checker.finishHandoff()
+		checker.lock.RLock()
+		assert.Empty(t, checker.shard.peers)
+		checker.lock.RUnlock()
+		assert.False(t, remotes[remoteHost].IsAlive())
+	})
+
+	t.Run("debug_handler", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handleDescribeShards(checker)(recorder,
+			httptest.NewRequest(http.MethodGet, "http://127.0.0.1:1234?key="+keys[0], nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		ret := map[string]interface{}{}
+		data, _ := io.ReadAll(recorder.Body)
+		assert.NoError(t, json.Unmarshal(data, &ret))
+		assert.Equal(t, self, ret["self"])
+		assert.Equal(t, []interface{}{self}, ret["members"])
+		assert.Equal(t, map[string]interface{}{"current": self}, ret["owner"])
+	})
+}
+
+func Test_ShardedConfig(t *testing.T) {
+	conf, err := unmarshal(map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, ModeLeader, conf.Mode)
+	assert.Equal(t, DefaultHandoffGrace, conf.HandoffGrace)
+
+	_, err = unmarshal(map[string]interface{}{"mode": "unknown"})
+	assert.Error(t, err)
+
+	recorder := httptest.NewRecorder()
+	handleDescribeShards(&LeaderHealthChecker{conf: conf})(recorder,
+		httptest.NewRequest(http.MethodGet, "http://127.0.0.1:1234", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
@@ -0,0 +1,218 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package probe
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	commonLog "github.com/polarismesh/polaris/common/log"
+	"github.com/polarismesh/polaris/common/model"
+	commontime "github.com/polarismesh/polaris/common/time"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+const (
+	// PluginName plugin name
+	PluginName = "probe"
+)
+
+var log = commonLog.GetScopeOrDefaultByName(commonLog.HealthcheckLoggerName)
+
+// probeRecord 实例的探测记录
+type probeRecord struct {
+	mutex sync.Mutex
+	// lastSuccessSec 最近一次探测成功的时间
+	lastSuccessSec int64
+	// successes 连续探测成功的次数
+	successes int
+	// failures 连续探测失败的次数
+	failures int
+}
+
+// ProbeHealthChecker 服务端主动探测实例的健康状态，适用于无法集成 SDK 上报心跳的实例
+type ProbeHealthChecker struct {
+	cfg            *Config
+	records        *utils.SyncMap[string, *probeRecord]
+	suspendTimeSec int64
+	probers        map[string]prober
+}
+
+// Name return plugin name
+func (r *ProbeHealthChecker) Name() string {
+	return PluginName
+}
+
+// Initialize initialize plugin
+func (r *ProbeHealthChecker) Initialize(c *plugin.ConfigEntry) error {
+	cfg, err := unmarshal(c.Option)
+	if err != nil {
+		return err
+	}
+	r.cfg = cfg
+	r.records = utils.NewSyncMap[string, *probeRecord]()
+	r.probers = probers
+	return nil
+}
+
+// Destroy plugin destruction
+func (r *ProbeHealthChecker) Destroy() error {
+	return nil
+}
+
+// Type for health check plugin, only one same type plugin is allowed
+func (r *ProbeHealthChecker) Type() plugin.HealthCheckType {
+	return plugin.HealthCheckerProbe
+}
+
+// Report 探测类的健康检查不依赖实例上报的心跳
+func (r *ProbeHealthChecker) Report(ctx context.Context, request *plugin.ReportRequest) error {
+	return nil
+}
+
+// Query queries the last probe success time
+func (r *ProbeHealthChecker) Query(ctx context.Context, request *plugin.QueryRequest) (*plugin.QueryResponse, error) {
+	record, ok := r.records.Load(request.InstanceId)
+	if !ok {
+		return &plugin.QueryResponse{
+			LastHeartbeatSec: 0,
+		}, nil
+	}
+	record.mutex.Lock()
+	defer record.mutex.Unlock()
+	return &plugin.QueryResponse{
+		Exists:           true,
+		LastHeartbeatSec: record.lastSuccessSec,
+		Count:            int64(record.successes),
+	}, nil
+}
+
+// BatchQuery batch queries the last probe success time
+func (r *ProbeHealthChecker) BatchQuery(ctx context.Context,
+	request *plugin.BatchQueryRequest) (*plugin.BatchQueryResponse, error) {
+	rsp := &plugin.BatchQueryResponse{Responses: make([]*plugin.QueryResponse, 0, len(request.Requests))}
+	for i := range request.Requests {
+		subRsp, err := r.Query(ctx, request.Requests[i])
+		if err != nil {
+			return nil, err
+		}
+		rsp.Responses = append(rsp.Responses, subRsp)
+	}
+	return rsp, nil
+}
+
+func (r *ProbeHealthChecker) skipCheck(instanceId string, expireDurationSec int64) bool {
+	suspendTimeSec := r.SuspendTimeSec()
+	localCurTimeSec := commontime.CurrentMillisecond() / 1000
+	if suspendTimeSec > 0 && localCurTimeSec >= suspendTimeSec && localCurTimeSec-suspendTimeSec < expireDurationSec {
+		log.Infof("[Health Check][ProbeCheck]health check probe suspended, "+
+			"suspendTimeSec is %d, localCurTimeSec is %d, expireDurationSec is %d, instanceId %s",
+			suspendTimeSec, localCurTimeSec, expireDurationSec, instanceId)
+		return true
+	}
+	return false
+}
+
+// Check 探测实例，连续成功 rise 次后变为健康，连续失败 fall 次后变为不健康
+func (r *ProbeHealthChecker) Check(request *plugin.CheckRequest) (*plugin.CheckResponse, error) {
+	cfg, err := parseProbeConfig(r.cfg, request.Port, request.Metadata)
+	if cfg == nil {
+		return nil, err
+	}
+	if err != nil {
+		log.Warnf("[Health Check][ProbeCheck]instanceId %s, %v", request.InstanceId, err)
+	}
+
+	record, _ := r.records.ComputeIfAbsent(request.InstanceId, func(_ string) *probeRecord {
+		return &probeRecord{}
+	})
+	record.mutex.Lock()
+	defer record.mutex.Unlock()
+
+	checkResp := &plugin.CheckResponse{
+		Regular:              true,
+		NextCheckIntervalSec: int64(cfg.interval.Seconds()),
+	}
+	// 暂停期间跳过 fall 次探测周期，避免集群节点变化后集中把实例置为不健康
+	if r.skipCheck(request.InstanceId, int64(cfg.fall)*checkResp.NextCheckIntervalSec) {
+		checkResp.Healthy = request.Healthy
+		checkResp.StayUnchanged = true
+		checkResp.LastHeartbeatTimeSec = record.lastSuccessSec
+		return checkResp, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.timeout)
+	defer cancel()
+	probeErr := r.probers[cfg.probeType](ctx, request.Host, cfg)
+	curTimeSec := request.CurTimeSec()
+	if probeErr == nil {
+		record.lastSuccessSec = curTimeSec
+		record.successes++
+		record.failures = 0
+	} else {
+		record.successes = 0
+		record.failures++
+		log.Debugf("[Health Check][ProbeCheck]%s probe %s:%d fail, instanceId %s, failures %d, err %v",
+			cfg.probeType, request.Host, cfg.port, request.InstanceId, record.failures, probeErr)
+	}
+	checkResp.LastHeartbeatTimeSec = record.lastSuccessSec
+
+	switch {
+	case !request.Healthy && record.successes >= cfg.rise:
+		checkResp.Healthy = true
+		log.Infof("[Health Check][ProbeCheck]%s probe resumed, address %s:%d, successes %d, instanceId %s",
+			cfg.probeType, request.Host, cfg.port, record.successes, request.InstanceId)
+	case request.Healthy && record.failures >= cfg.fall:
+		checkResp.Healthy = false
+		log.Infof("[Health Check][ProbeCheck]%s probe failed, address %s:%d, failures %d, instanceId %s, err %v",
+			cfg.probeType, request.Host, cfg.port, record.failures, request.InstanceId, probeErr)
+	default:
+		checkResp.Healthy = request.Healthy
+		checkResp.StayUnchanged = true
+	}
+	return checkResp, nil
+}
+
+// Delete delete the id
+func (r *ProbeHealthChecker) Delete(ctx context.Context, id string) error {
+	r.records.Delete(id)
+	return nil
+}
+
+// Suspend 暂停探测结果的生效
+func (r *ProbeHealthChecker) Suspend() {
+	curTimeSec := commontime.CurrentMillisecond() / 1000
+	log.Infof("[Health Check][ProbeCheck] suspend checker, start time %d", curTimeSec)
+	atomic.StoreInt64(&r.suspendTimeSec, curTimeSec)
+}
+
+// SuspendTimeSec get suspend time in seconds
+func (r *ProbeHealthChecker) SuspendTimeSec() int64 {
+	return atomic.LoadInt64(&r.suspendTimeSec)
+}
+
+func (r *ProbeHealthChecker) DebugHandlers() []model.DebugHandler {
+	return []model.DebugHandler{}
+}
+
+func init() {
+	d := &ProbeHealthChecker{}
+	plugin.RegisterPlugin(d.Name(), d)
+}
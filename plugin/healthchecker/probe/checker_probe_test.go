@@ -0,0 +1,251 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+func newTestChecker(t *testing.T) *ProbeHealthChecker {
+	checker := &ProbeHealthChecker{}
+	err := checker.Initialize(&plugin.ConfigEntry{
+		Name: PluginName,
+		Option: map[string]interface{}{
+			"interval": "1s",
+			"timeout":  "500ms",
+		},
+	})
+	assert.NoError(t, err)
+	return checker
+}
+
+func splitHostPort(t *testing.T, addr string) (string, uint32) {
+	host, portStr, err := net.SplitHostPort(addr)
+	assert.NoError(t, err)
+	port, err := strconv.ParseUint(portStr, 10, 32)
+	assert.NoError(t, err)
+	return host, uint32(port)
+}
+
+func newCheckRequest(host string, port uint32, healthy bool, metadata map[string]string) *plugin.CheckRequest {
+	return &plugin.CheckRequest{
+		QueryRequest: plugin.QueryRequest{
+			InstanceId: "instance-1",
+			Host:       host,
+			Port:       port,
+			Healthy:    healthy,
+		},
+		CurTimeSec: func() int64 {
+			return time.Now().Unix()
+		},
+		Metadata: metadata,
+	}
+}
+
+func TestProbeHealthChecker_HTTP(t *testing.T) {
+	status := http.StatusOK
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer svr.Close()
+	host, port := splitHostPort(t, svr.Listener.Addr().String())
+
+	checker := newTestChecker(t)
+	metadata := map[string]string{
+		model.MetaKeyHealthProbeType: ProbeHTTP,
+		model.MetaKeyHealthProbePath: "health",
+		model.MetaKeyHealthProbeRise: "2",
+		model.MetaKeyHealthProbeFall: "2",
+	}
+
+	// 连续成功 rise 次后才恢复健康
+	rsp, err := checker.Check(newCheckRequest(host, port, false, metadata))
+	assert.NoError(t, err)
+	assert.True(t, rsp.Regular)
+	assert.True(t, rsp.StayUnchanged)
+	assert.Equal(t, int64(1), rsp.NextCheckIntervalSec)
+	rsp, err = checker.Check(newCheckRequest(host, port, false, metadata))
+	assert.NoError(t, err)
+	assert.False(t, rsp.StayUnchanged)
+	assert.True(t, rsp.Healthy)
+	assert.True(t, rsp.LastHeartbeatTimeSec > 0)
+
+	// 状态码不符合预期，连续失败 fall 次后变为不健康
+	status = http.StatusInternalServerError
+	rsp, err = checker.Check(newCheckRequest(host, port, true, metadata))
+	assert.NoError(t, err)
+	assert.True(t, rsp.StayUnchanged)
+	assert.True(t, rsp.Healthy)
+	rsp, err = checker.Check(newCheckRequest(host, port, true, metadata))
+	assert.NoError(t, err)
+	assert.False(t, rsp.StayUnchanged)
+	assert.False(t, rsp.Healthy)
+
+	// 自定义状态码区间
+	metadata[model.MetaKeyHealthProbeStatus] = "500"
+	metadata[model.MetaKeyHealthProbeRise] = "1"
+	rsp, err = checker.Check(newCheckRequest(host, port, false, metadata))
+	assert.NoError(t, err)
+	assert.False(t, rsp.StayUnchanged)
+	assert.True(t, rsp.Healthy)
+
+	queryRsp, err := checker.Query(context.Background(), &plugin.QueryRequest{InstanceId: "instance-1"})
+	assert.NoError(t, err)
+	assert.True(t, queryRsp.Exists)
+	assert.NoError(t, checker.Delete(context.Background(), "instance-1"))
+	queryRsp, err = checker.Query(context.Background(), &plugin.QueryRequest{InstanceId: "instance-1"})
+	assert.NoError(t, err)
+	assert.False(t, queryRsp.Exists)
+}
+
+func TestProbeHealthChecker_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	host, port := splitHostPort(t, ln.Addr().String())
+
+	checker := newTestChecker(t)
+	metadata := map[string]string{
+		model.MetaKeyHealthProbeType: ProbeTCP,
+		model.MetaKeyHealthProbeRise: "1",
+		model.MetaKeyHealthProbeFall: "1",
+	}
+	rsp, err := checker.Check(newCheckRequest(host, port, false, metadata))
+	assert.NoError(t, err)
+	assert.True(t, rsp.Healthy)
+	assert.False(t, rsp.StayUnchanged)
+
+	_ = ln.Close()
+	rsp, err = checker.Check(newCheckRequest(host, port, true, metadata))
+	assert.NoError(t, err)
+	assert.False(t, rsp.Healthy)
+	assert.False(t, rsp.StayUnchanged)
+}
+
+func TestProbeHealthChecker_GRPC(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	healthSvr := health.NewServer()
+	svr := grpc.NewServer()
+	healthpb.RegisterHealthServer(svr, healthSvr)
+	go func() {
+		_ = svr.Serve(ln)
+	}()
+	defer svr.Stop()
+	host, port := splitHostPort(t, ln.Addr().String())
+
+	checker := newTestChecker(t)
+	metadata := map[string]string{
+		model.MetaKeyHealthProbeType:        ProbeGRPC,
+		model.MetaKeyHealthProbeGrpcService: "echo",
+		model.MetaKeyHealthProbeRise:        "1",
+		model.MetaKeyHealthProbeFall:        "1",
+	}
+	healthSvr.SetServingStatus("echo", healthpb.HealthCheckResponse_SERVING)
+	rsp, err := checker.Check(newCheckRequest(host, port, false, metadata))
+	assert.NoError(t, err)
+	assert.True(t, rsp.Healthy)
+
+	healthSvr.SetServingStatus("echo", healthpb.HealthCheckResponse_NOT_SERVING)
+	rsp, err = checker.Check(newCheckRequest(host, port, true, metadata))
+	assert.NoError(t, err)
+	assert.False(t, rsp.Healthy)
+}
+
+func TestProbeHealthChecker_Suspend(t *testing.T) {
+	checker := newTestChecker(t)
+	checker.Suspend()
+	metadata := map[string]string{
+		model.MetaKeyHealthProbeType: ProbeTCP,
+		model.MetaKeyHealthProbeFall: "1",
+	}
+	// 暂停期间即使探测不通也不修改健康状态
+	rsp, err := checker.Check(newCheckRequest("127.0.0.1", 1, true, metadata))
+	assert.NoError(t, err)
+	assert.True(t, rsp.Healthy)
+	assert.True(t, rsp.StayUnchanged)
+}
+
+func TestParseProbeConfig(t *testing.T) {
+	defaultCfg, err := unmarshal(nil)
+	assert.NoError(t, err)
+
+	_, err = parseProbeConfig(defaultCfg, 8080, map[string]string{})
+	assert.Error(t, err)
+
+	cfg, err := parseProbeConfig(defaultCfg, 8080, map[string]string{
+		model.MetaKeyHealthProbeType:     "HTTP",
+		model.MetaKeyHealthProbePort:     "9090",
+		model.MetaKeyHealthProbeInterval: "10s",
+		model.MetaKeyHealthProbeTimeout:  "20s",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ProbeHTTP, cfg.probeType)
+	assert.Equal(t, uint32(9090), cfg.port)
+	assert.Equal(t, DefaultPath, cfg.path)
+	assert.Equal(t, 10*time.Second, cfg.interval)
+	assert.Equal(t, 10*time.Second, cfg.timeout)
+	assert.True(t, cfg.expectStatus(http.StatusNoContent))
+	assert.False(t, cfg.expectStatus(http.StatusNotFound))
+
+	cfg, err = parseProbeConfig(defaultCfg, 8080, map[string]string{
+		model.MetaKeyHealthProbeType:   ProbeTCP,
+		model.MetaKeyHealthProbePort:   "abc",
+		model.MetaKeyHealthProbeStatus: "200-100",
+		model.MetaKeyHealthProbeRise:   "0",
+	})
+	assert.Error(t, err)
+	assert.Equal(t, uint32(8080), cfg.port)
+	assert.Equal(t, DefaultRise, cfg.rise)
+	assert.True(t, cfg.expectStatus(http.StatusOK))
+
+	statuses, err := parseStatuses("200, 204,300-399")
+	assert.NoError(t, err)
+	assert.Equal(t, []statusRange{{200, 200}, {204, 204}, {300, 399}}, statuses)
+	_, err = parseStatuses("abc")
+	assert.Error(t, err)
+
+	_, err = unmarshal(map[string]interface{}{"interval": "1s", "timeout": "2s"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,221 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package probe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+const (
+	// ProbeHTTP http 探测，请求返回的状态码符合预期认为健康
+	ProbeHTTP = "http"
+	// ProbeTCP tcp 探测，可以建立连接认为健康
+	ProbeTCP = "tcp"
+	// ProbeGRPC grpc 探测，使用 grpc 标准健康检查协议
+	ProbeGRPC = "grpc"
+)
+
+const (
+	DefaultInterval = 5 * time.Second
+	DefaultTimeout  = 2 * time.Second
+	DefaultRise     = 2
+	DefaultFall     = 3
+	DefaultPath     = "/"
+	DefaultStatus   = "200-399"
+)
+
+// Config 插件配置，作为实例和服务元数据中没有配置探测参数时的默认值
+type Config struct {
+	Interval time.Duration `mapstructure:"interval"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	Rise     int           `mapstructure:"rise"`
+	Fall     int           `mapstructure:"fall"`
+}
+
+func unmarshal(options map[string]interface{}) (*Config, error) {
+	config := &Config{
+		Interval: DefaultInterval,
+		Timeout:  DefaultTimeout,
+		Rise:     DefaultRise,
+		Fall:     DefaultFall,
+	}
+	decodeConfig := &mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     config,
+	}
+	decoder, err := mapstructure.NewDecoder(decodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err = decoder.Decode(options); err != nil {
+		return nil, err
+	}
+	if config.Interval < time.Second {
+		return nil, fmt.Errorf("probe interval %s must not be less than 1s", config.Interval)
+	}
+	if config.Timeout <= 0 || config.Timeout > config.Interval {
+		return nil, fmt.Errorf("probe timeout %s must be in (0, interval]", config.Timeout)
+	}
+	if config.Rise <= 0 || config.Fall <= 0 {
+		return nil, fmt.Errorf("probe rise %d and fall %d must be positive", config.Rise, config.Fall)
+	}
+	return config, nil
+}
+
+// statusRange http 探测认为健康的状态码区间
+type statusRange struct {
+	min int
+	max int
+}
+
+// probeConfig 单个实例的探测参数
+type probeConfig struct {
+	probeType   string
+	port        uint32
+	path        string
+	statuses    []statusRange
+	grpcService string
+	interval    time.Duration
+	timeout     time.Duration
+	rise        int
+	fall        int
+}
+
+func (p *probeConfig) expectStatus(code int) bool {
+	for _, item := range p.statuses {
+		if code >= item.min && code <= item.max {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProbeConfig 从实例以及服务的元数据中解析探测参数，没有配置或者配置错误的参数使用插件的默认值
+func parseProbeConfig(defaultCfg *Config, port uint32, metadata map[string]string) (*probeConfig, error) {
+	cfg := &probeConfig{
+		probeType:   strings.ToLower(metadata[model.MetaKeyHealthProbeType]),
+		port:        port,
+		path:        DefaultPath,
+		grpcService: metadata[model.MetaKeyHealthProbeGrpcService],
+		interval:    defaultCfg.Interval,
+		timeout:     defaultCfg.Timeout,
+		rise:        defaultCfg.Rise,
+		fall:        defaultCfg.Fall,
+	}
+	switch cfg.probeType {
+	case ProbeHTTP, ProbeTCP, ProbeGRPC:
+	default:
+		return nil, fmt.Errorf("unknown probe type %q", cfg.probeType)
+	}
+
+	var errs []string
+	if val, ok := metadata[model.MetaKeyHealthProbePort]; ok {
+		if port, err := strconv.ParseUint(val, 10, 16); err == nil && port > 0 {
+			cfg.port = uint32(port)
+		} else {
+			errs = append(errs, model.MetaKeyHealthProbePort)
+		}
+	}
+	if val := metadata[model.MetaKeyHealthProbePath]; val != "" {
+		if !strings.HasPrefix(val, "/") {
+			val = "/" + val
+		}
+		cfg.path = val
+	}
+	statuses, err := parseStatuses(DefaultStatus)
+	if val := metadata[model.MetaKeyHealthProbeStatus]; val != "" {
+		if statuses, err = parseStatuses(val); err != nil {
+			errs = append(errs, model.MetaKeyHealthProbeStatus)
+			statuses, _ = parseStatuses(DefaultStatus)
+		}
+	}
+	cfg.statuses = statuses
+	if val, ok := metadata[model.MetaKeyHealthProbeInterval]; ok {
+		if interval, err := time.ParseDuration(val); err == nil && interval >= time.Second {
+			cfg.interval = interval
+		} else {
+			errs = append(errs, model.MetaKeyHealthProbeInterval)
+		}
+	}
+	if val, ok := metadata[model.MetaKeyHealthProbeTimeout]; ok {
+		if timeout, err := time.ParseDuration(val); err == nil && timeout > 0 {
+			cfg.timeout = timeout
+		} else {
+			errs = append(errs, model.MetaKeyHealthProbeTimeout)
+		}
+	}
+	// 探测超时不能超过探测周期，避免同一个实例的探测堆积
+	if cfg.timeout > cfg.interval {
+		cfg.timeout = cfg.interval
+	}
+	if val, ok := metadata[model.MetaKeyHealthProbeRise]; ok {
+		if rise, err := strconv.Atoi(val); err == nil && rise > 0 {
+			cfg.rise = rise
+		} else {
+			errs = append(errs, model.MetaKeyHealthProbeRise)
+		}
+	}
+	if val, ok := metadata[model.MetaKeyHealthProbeFall]; ok {
+		if fall, err := strconv.Atoi(val); err == nil && fall > 0 {
+			cfg.fall = fall
+		} else {
+			errs = append(errs, model.MetaKeyHealthProbeFall)
+		}
+	}
+	if len(errs) != 0 {
+		return cfg, fmt.Errorf("invalid probe metadata %s, use default value instead", strings.Join(errs, ","))
+	}
+	return cfg, nil
+}
+
+// parseStatuses 解析状态码配置，多个状态码或者区间使用逗号分隔，例如 200,204,300-399
+func parseStatuses(val string) ([]statusRange, error) {
+	ret := make([]statusRange, 0, 2)
+	for _, item := range strings.Split(val, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		bounds := strings.SplitN(item, "-", 2)
+		min, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, err
+		}
+		max := min
+		if len(bounds) == 2 {
+			if max, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+				return nil, err
+			}
+		}
+		if min < 100 || max > 599 || min > max {
+			return nil, fmt.Errorf("invalid status range %s", item)
+		}
+		ret = append(ret, statusRange{min: min, max: max})
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("empty status")
+	}
+	return ret, nil
+}
@@ -0,0 +1,108 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package probe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// prober 执行一次探测，探测失败时返回具体原因
+type prober func(ctx context.Context, host string, cfg *probeConfig) error
+
+var (
+	probers = map[string]prober{
+		ProbeHTTP: httpProbe,
+		ProbeTCP:  tcpProbe,
+		ProbeGRPC: grpcProbe,
+	}
+
+	// httpClient 每次探测都新建连接，探测结果能够反映实例当前是否可以建立连接，也避免长期占用大量实例的连接
+	httpClient = &http.Client{
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+			Proxy:             nil,
+		},
+		// 重定向的状态码交给状态码配置判断
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+)
+
+func probeAddress(host string, cfg *probeConfig) string {
+	return net.JoinHostPort(host, strconv.FormatUint(uint64(cfg.port), 10))
+}
+
+func httpProbe(ctx context.Context, host string, cfg *probeConfig) error {
+	url := fmt.Sprintf("http://%s%s", probeAddress(host, cfg), cfg.path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "polaris-health-probe")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if !cfg.expectStatus(resp.StatusCode) {
+		return fmt.Errorf("unexpected http status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func tcpProbe(ctx context.Context, host string, cfg *probeConfig) error {
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", probeAddress(host, cfg))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func grpcProbe(ctx context.Context, host string, cfg *probeConfig) error {
+	conn, err := grpc.NewClient(probeAddress(host, cfg), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: cfg.grpcService,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("unexpected grpc health status %s", resp.GetStatus())
+	}
+	return nil
+}
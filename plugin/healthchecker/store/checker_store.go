@@ -0,0 +1,437 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package heartbeatstore
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	commonhash "github.com/polarismesh/polaris/common/hash"
+	commonlog "github.com/polarismesh/polaris/common/log"
+	"github.com/polarismesh/polaris/common/model"
+	commontime "github.com/polarismesh/polaris/common/time"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store"
+)
+
+var log = commonlog.GetScopeOrDefaultByName(commonlog.HealthcheckLoggerName)
+
+const (
+	// PluginName plugin name
+	PluginName = "heartbeatStore"
+	// DefaultFlushInterval 默认的心跳批量写入间隔
+	DefaultFlushInterval = time.Second
+	// DefaultBatchSize 默认单次写入或者查询存储的心跳数量
+	DefaultBatchSize = 500
+	// DefaultCoalesceRatio 默认的写合并比例
+	DefaultCoalesceRatio = 0.5
+)
+
+var (
+	// persistedSoltNum 本地已持久化心跳时间的分段数量
+	persistedSoltNum = runtime.GOMAXPROCS(0) * 16
+)
+
+// Config 插件配置
+type Config struct {
+	// FlushInterval 心跳批量写入存储的间隔
+	FlushInterval time.Duration `json:"flushInterval"`
+	// BatchSize 单次写入或者查询存储的心跳数量
+	BatchSize int `json:"batchSize"`
+	// CoalesceRatio 距离本节点上一次写入存储的心跳不足 TTL * CoalesceRatio 时，本次心跳不写入存储
+	CoalesceRatio float64 `json:"coalesceRatio"`
+}
+
+func unmarshal(options map[string]interface{}) (*Config, error) {
+	config := &Config{
+		FlushInterval: DefaultFlushInterval,
+		BatchSize:     DefaultBatchSize,
+		CoalesceRatio: DefaultCoalesceRatio,
+	}
+	decodeConfig := &mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     config,
+	}
+	decoder, err := mapstructure.NewDecoder(decodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err = decoder.Decode(options); err != nil {
+		return nil, err
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.CoalesceRatio < 0 || config.CoalesceRatio >= 1 {
+		return nil, fmt.Errorf("[Health Check][StoreCheck] coalesceRatio must be in [0, 1), got %v",
+			config.CoalesceRatio)
+	}
+	return config, nil
+}
+
+// StoreHealthChecker 基于存储层的心跳健康检查，心跳数据通过 store.HeartbeatStore 在所有节点间共享
+// 1. Report 只写入本地缓冲，由后台任务按照 FlushInterval 批量写入存储
+// 2. 距离本节点上一次写入的心跳时间不足 TTL * CoalesceRatio 的心跳直接丢弃，减少存储的写入压力
+// 3. Query 合并存储中的数据以及本地尚未写入的数据，返回最新的心跳时间
+type StoreHealthChecker struct {
+	conf    *Config
+	storage store.HeartbeatStore
+	// lock 保护 pending
+	lock sync.Mutex
+	// pending 等待写入存储的心跳
+	pending map[string]*model.HeartbeatRecord
+	// persisted 本节点最近一次成功写入存储的心跳时间
+	persisted *utils.SegmentMap[string, int64]
+	// suspendTimeSec 健康检查暂停的时间
+	suspendTimeSec int64
+	// flushFailed 最近一次写入存储是否失败
+	flushFailed int32
+	// recoverTimeSec 写入存储从失败中恢复的时间
+	recoverTimeSec int64
+	cancel         context.CancelFunc
+	flushDone      chan struct{}
+}
+
+// Name plugin name
+func (r *StoreHealthChecker) Name() string {
+	return PluginName
+}
+
+// Initialize initialize plugin
+func (r *StoreHealthChecker) Initialize(c *plugin.ConfigEntry) error {
+	conf, err := unmarshal(c.Option)
+	if err != nil {
+		return err
+	}
+	r.conf = conf
+	if r.storage == nil {
+		s, err := store.GetStore()
+		if err != nil {
+			return err
+		}
+		beatStore, ok := s.(store.HeartbeatStore)
+		if !ok {
+			return fmt.Errorf("store %s not support %s health checker", s.Name(), PluginName)
+		}
+		r.storage = beatStore
+	}
+	r.pending = map[string]*model.HeartbeatRecord{}
+	r.persisted = utils.NewSegmentMap[string, int64](persistedSoltNum, commonhash.Fnv32)
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.flushDone = make(chan struct{})
+	go r.runFlush(ctx)
+	return nil
+}
+
+// Destroy plugin destroy
+func (r *StoreHealthChecker) Destroy() error {
+	if r.cancel != nil {
+		r.cancel()
+		<-r.flushDone
+	}
+	return nil
+}
+
+// Type for health check plugin, only one same type plugin is allowed
+func (r *StoreHealthChecker) Type() plugin.HealthCheckType {
+	return plugin.HealthCheckerHeartbeat
+}
+
+// Report process heartbeat info report
+func (r *StoreHealthChecker) Report(ctx context.Context, request *plugin.ReportRequest) error {
+	record := &model.HeartbeatRecord{
+		InstanceID: request.InstanceId,
+		Server:     request.LocalHost,
+		CurTimeSec: request.CurTimeSec,
+		Count:      request.Count,
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.pending[request.InstanceId]; !ok && r.coalesce(request) {
+		log.Debugf("[Health Check][StoreCheck] coalesce heartbeat, id %s, curTimeSec %d",
+			request.InstanceId, request.CurTimeSec)
+		return nil
+	}
+	r.pending[request.InstanceId] = record
+	return nil
+}
+
+// coalesce 判断心跳是否可以不写入存储，实例不存在时需要记录上报次数，不做合并
+func (r *StoreHealthChecker) coalesce(request *plugin.ReportRequest) bool {
+	if request.TtlSec == 0 {
+		return false
+	}
+	lastTimeSec, ok := r.persisted.Get(request.InstanceId)
+	if !ok || request.CurTimeSec < lastTimeSec {
+		return false
+	}
+	return float64(request.CurTimeSec-lastTimeSec) < float64(request.TtlSec)*r.conf.CoalesceRatio
+}
+
+func (r *StoreHealthChecker) runFlush(ctx context.Context) {
+	defer close(r.flushDone)
+	ticker := time.NewTicker(r.conf.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.flush()
+			return
+		case <-ticker.C:
+			r.flush()
+		}
+	}
+}
+
+// flush 将缓冲的心跳批量写入存储，写入失败的心跳放回缓冲等待下一次写入
+func (r *StoreHealthChecker) flush() {
+	r.lock.Lock()
+	pending := r.pending
+	r.pending = make(map[string]*model.HeartbeatRecord, len(pending))
+	r.lock.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	records := make([]*model.HeartbeatRecord, 0, len(pending))
+	for _, record := range pending {
+		records = append(records, record)
+	}
+	var failed []*model.HeartbeatRecord
+	for start := 0; start < len(records); start += r.conf.BatchSize {
+		end := start + r.conf.BatchSize
+		if end > len(records) {
+			end = len(records)
+		}
+		batch := records[start:end]
+		if err := r.storage.BatchUpsertHeartbeats(batch); err != nil {
+			log.Errorf("[Health Check][StoreCheck] flush %d heartbeats err: %s", len(batch), err.Error())
+			failed = append(failed, batch...)
+			continue
+		}
+		for _, record := range batch {
+			r.persisted.Put(record.InstanceID, record.CurTimeSec)
+		}
+	}
+
+	if len(failed) == 0 {
+		if atomic.CompareAndSwapInt32(&r.flushFailed, 1, 0) {
+			recoverTimeSec := commontime.CurrentMillisecond() / 1000
+			log.Infof("[Health Check][StoreCheck] flush heartbeats recover, time %d", recoverTimeSec)
+			atomic.StoreInt64(&r.recoverTimeSec, recoverTimeSec)
+		}
+		return
+	}
+	atomic.StoreInt32(&r.flushFailed, 1)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, record := range failed {
+		if cur, ok := r.pending[record.InstanceID]; ok && cur.CurTimeSec >= record.CurTimeSec {
+			continue
+		}
+		r.pending[record.InstanceID] = record
+	}
+}
+
+// getRecords 查询存储中的心跳，并与本地尚未写入的心跳合并
+func (r *StoreHealthChecker) getRecords(keys []string) (map[string]*model.HeartbeatRecord, error) {
+	ret := make(map[string]*model.HeartbeatRecord, len(keys))
+	for start := 0; start < len(keys); start += r.conf.BatchSize {
+		end := start + r.conf.BatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		records, err := r.storage.GetHeartbeats(keys[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for id, record := range records {
+			ret[id] = record
+		}
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, key := range keys {
+		record, ok := r.pending[key]
+		if !ok {
+			continue
+		}
+		if cur, exist := ret[key]; !exist || cur.CurTimeSec <= record.CurTimeSec {
+			ret[key] = record
+		}
+	}
+	return ret, nil
+}
+
+// Query queries the heartbeat time
+func (r *StoreHealthChecker) Query(ctx context.Context, request *plugin.QueryRequest) (*plugin.QueryResponse, error) {
+	records, err := r.getRecords([]string{request.InstanceId})
+	if err != nil {
+		log.Errorf("[Health Check][StoreCheck]addr:%s:%d, id:%s, query heartbeat err:%s",
+			request.Host, request.Port, request.InstanceId, err)
+		return nil, err
+	}
+	return toQueryResponse(records[request.InstanceId]), nil
+}
+
+// BatchQuery batch queries the heartbeat time
+func (r *StoreHealthChecker) BatchQuery(ctx context.Context,
+	request *plugin.BatchQueryRequest) (*plugin.BatchQueryResponse, error) {
+	keys := make([]string, 0, len(request.Requests))
+	for i := range request.Requests {
+		keys = append(keys, request.Requests[i].InstanceId)
+	}
+	records, err := r.getRecords(keys)
+	if err != nil {
+		log.Errorf("[Health Check][StoreCheck] batch query heartbeat err:%s", err)
+		return nil, err
+	}
+	rsp := &plugin.BatchQueryResponse{
+		Responses: make([]*plugin.QueryResponse, 0, len(keys)),
+	}
+	for _, key := range keys {
+		rsp.Responses = append(rsp.Responses, toQueryResponse(records[key]))
+	}
+	return rsp, nil
+}
+
+func toQueryResponse(record *model.HeartbeatRecord) *plugin.QueryResponse {
+	if record == nil {
+		return &plugin.QueryResponse{}
+	}
+	return &plugin.QueryResponse{
+		Server:           record.Server,
+		Exists:           true,
+		LastHeartbeatSec: record.CurTimeSec,
+		Count:            record.Count,
+	}
+}
+
+func (r *StoreHealthChecker) skipCheck(instanceId string, expireDurationSec int64) bool {
+	suspendTimeSec := r.SuspendTimeSec()
+	localCurTimeSec := commontime.CurrentMillisecond() / 1000
+	if suspendTimeSec > 0 && localCurTimeSec >= suspendTimeSec && localCurTimeSec-suspendTimeSec < expireDurationSec {
+		log.Infof("[Health Check][StoreCheck]health check suspended, "+
+			"suspendTimeSec is %d, localCurTimeSec is %d, expireDurationSec is %d, id %s",
+			suspendTimeSec, localCurTimeSec, expireDurationSec, instanceId)
+		return true
+	}
+	// 心跳写入存储失败期间，其他节点看到的心跳时间不准确，不做变更
+	if atomic.LoadInt32(&r.flushFailed) == 1 {
+		log.Infof("[Health Check][StoreCheck]health check store on failure, id %s", instanceId)
+		return true
+	}
+	recoverTimeSec := atomic.LoadInt64(&r.recoverTimeSec)
+	if recoverTimeSec > 0 && localCurTimeSec >= recoverTimeSec && localCurTimeSec-recoverTimeSec < expireDurationSec {
+		log.Infof("[Health Check][StoreCheck]health check store on recover, "+
+			"recoverTimeSec is %d, localCurTimeSec is %d, expireDurationSec is %d, id %s",
+			recoverTimeSec, localCurTimeSec, expireDurationSec, instanceId)
+		return true
+	}
+	return false
+}
+
+// Check process the instance check
+func (r *StoreHealthChecker) Check(request *plugin.CheckRequest) (*plugin.CheckResponse, error) {
+	queryResp, err := r.Query(context.Background(), &request.QueryRequest)
+	if err != nil {
+		return nil, err
+	}
+	lastHeartbeatTime := queryResp.LastHeartbeatSec
+	checkResp := &plugin.CheckResponse{
+		LastHeartbeatTimeSec: lastHeartbeatTime,
+	}
+	curTimeSec := request.CurTimeSec()
+	if r.skipCheck(request.InstanceId, int64(request.ExpireDurationSec)) {
+		checkResp.StayUnchanged = true
+		return checkResp, nil
+	}
+	// 出现时间倒退，不对心跳状态做变更
+	if curTimeSec < lastHeartbeatTime {
+		log.Infof("[Health Check][StoreCheck]time reverse, curTime is %d, last heartbeat time is %d, id %s",
+			curTimeSec, lastHeartbeatTime, request.InstanceId)
+		checkResp.StayUnchanged = true
+		return checkResp, nil
+	}
+	// 正常进行心跳中
+	checkResp.Regular = true
+	if curTimeSec-lastHeartbeatTime >= int64(request.ExpireDurationSec) {
+		// 心跳超时
+		checkResp.Healthy = false
+		if request.Healthy {
+			log.Infof("[Health Check][StoreCheck]health check expired, "+
+				"last hb timestamp is %d, curTimeSec is %d, expireDurationSec is %d instanceId %s",
+				lastHeartbeatTime, curTimeSec, request.ExpireDurationSec, request.InstanceId)
+		} else {
+			checkResp.StayUnchanged = true
+		}
+	} else {
+		// 心跳恢复
+		checkResp.Healthy = true
+		if !request.Healthy {
+			log.Infof("[Health Check][StoreCheck]health check resumed, "+
+				"last hb timestamp is %d, curTimeSec is %d, expireDurationSec is %d instanceId %s",
+				lastHeartbeatTime, curTimeSec, request.ExpireDurationSec, request.InstanceId)
+		} else {
+			checkResp.StayUnchanged = true
+		}
+	}
+	log.Debugf("[Health Check][StoreCheck]instanceId is %s, healthy is %v", request.InstanceId, checkResp.Healthy)
+	return checkResp, nil
+}
+
+// Delete delete the target id
+func (r *StoreHealthChecker) Delete(ctx context.Context, id string) error {
+	r.lock.Lock()
+	delete(r.pending, id)
+	r.lock.Unlock()
+	r.persisted.Del(id)
+	return r.storage.DeleteHeartbeats([]string{id})
+}
+
+// Suspend checker for an entire expired interval
+func (r *StoreHealthChecker) Suspend() {
+	curTimeMilli := commontime.CurrentMillisecond() / 1000
+	log.Infof("[Health Check][StoreCheck] suspend checker, start time %d", curTimeMilli)
+	atomic.StoreInt64(&r.suspendTimeSec, curTimeMilli)
+}
+
+// SuspendTimeSec get suspend time in seconds
+func (r *StoreHealthChecker) SuspendTimeSec() int64 {
+	return atomic.LoadInt64(&r.suspendTimeSec)
+}
+
+func (r *StoreHealthChecker) DebugHandlers() []model.DebugHandler {
+	return []model.DebugHandler{}
+}
+
+func init() {
+	d := &StoreHealthChecker{}
+	plugin.RegisterPlugin(d.Name(), d)
+}
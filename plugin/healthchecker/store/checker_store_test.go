@@ -0,0 +1,257 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package heartbeatstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	commontime "github.com/polarismesh/polaris/common/time"
+	"github.com/polarismesh/polaris/plugin"
+)
+
+// fakeHeartbeatStore 内存实现的 store.HeartbeatStore，记录写入次数并支持模拟写入失败
+type fakeHeartbeatStore struct {
+	lock    sync.Mutex
+	records map[string]*model.HeartbeatRecord
+	writes  int
+	fail    bool
+}
+
+func newFakeHeartbeatStore() *fakeHeartbeatStore {
+	return &fakeHeartbeatStore{records: map[string]*model.HeartbeatRecord{}}
+}
+
+func (f *fakeHeartbeatStore) BatchUpsertHeartbeats(records []*model.HeartbeatRecord) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.fail {
+		return errors.New("mock store failure")
+	}
+	for _, record := range records {
+		f.writes++
+		if cur, ok := f.records[record.InstanceID]; ok && cur.CurTimeSec > record.CurTimeSec {
+			continue
+		}
+		saved := *record
+		f.records[record.InstanceID] = &saved
+	}
+	return nil
+}
+
+func (f *fakeHeartbeatStore) GetHeartbeats(instanceIDs []string) (map[string]*model.HeartbeatRecord, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	ret := map[string]*model.HeartbeatRecord{}
+	for _, id := range instanceIDs {
+		if record, ok := f.records[id]; ok {
+			saved := *record
+			ret[id] = &saved
+		}
+	}
+	return ret, nil
+}
+
+func (f *fakeHeartbeatStore) DeleteHeartbeats(instanceIDs []string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, id := range instanceIDs {
+		delete(f.records, id)
+	}
+	return nil
+}
+
+func (f *fakeHeartbeatStore) writeCount() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.writes
+}
+
+func newTestChecker(t *testing.T) (*StoreHealthChecker, *fakeHeartbeatStore) {
+	beatStore := newFakeHeartbeatStore()
+	checker := &StoreHealthChecker{storage: beatStore}
+	err := checker.Initialize(&plugin.ConfigEntry{
+		Option: map[string]interface{}{
+			// 由测试用例主动触发写入
+			"flushInterval": "1h",
+			"batchSize":     2,
+		},
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = checker.Destroy()
+	})
+	return checker, beatStore
+}
+
+func newReportRequest(id string, curTimeSec int64, ttl uint32) *plugin.ReportRequest {
+	return &plugin.ReportRequest{
+		QueryRequest: plugin.QueryRequest{InstanceId: id},
+		LocalHost:    "127.0.0.1",
+		CurTimeSec:   curTimeSec,
+		TtlSec:       ttl,
+	}
+}
+
+func TestStoreHealthChecker_Report(t *testing.T) {
+	checker, beatStore := newTestChecker(t)
+	var _ plugin.HealthChecker = checker
+
+	t.Run("query_pending", func(t *testing.T) {
+		assert.NoError(t, checker.Report(context.Background(), newReportRequest("ins-1", 100, 10)))
+		// 尚未写入存储时，从本地缓冲读取
+		rsp, err := checker.Query(context.Background(), &plugin.QueryRequest{InstanceId: "ins-1"})
+		assert.NoError(t, err)
+		assert.True(t, rsp.Exists)
+		assert.Equal(t, int64(100), rsp.LastHeartbeatSec)
+		assert.Equal(t, 0, beatStore.writeCount())
+	})
+
+	t.Run("coalesce", func(t *testing.T) {
+		checker.flush()
+		assert.Equal(t, 1, beatStore.writeCount())
+
+		// 距离上一次写入不足 TTL * 0.5，不写入存储
+		assert.NoError(t, checker.Report(context.Background(), newReportRequest("ins-1", 104, 10)))
+		checker.flush()
+		assert.Equal(t, 1, beatStore.writeCount())
+
+		assert.NoError(t, checker.Report(context.Background(), newReportRequest("ins-1", 105, 10)))
+		checker.flush()
+		assert.Equal(t, 2, beatStore.writeCount())
+
+		// 缓冲中已有数据时，后续的心跳直接覆盖缓冲
+		assert.NoError(t, checker.Report(context.Background(), newReportRequest("ins-1", 111, 10)))
+		assert.NoError(t, checker.Report(context.Background(), newReportRequest("ins-1", 112, 10)))
+		checker.flush()
+		assert.Equal(t, 3, beatStore.writeCount())
+		rsp, err := checker.Query(context.Background(), &plugin.QueryRequest{InstanceId: "ins-1"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(112), rsp.LastHeartbeatSec)
+	})
+
+	t.Run("not_exist_instance", func(t *testing.T) {
+		// 实例不存在时需要准确记录上报次数，不做合并
+		for i := 1; i <= 3; i++ {
+			req := newReportRequest("ins-404", 200, 0)
+			req.Count = int64(i)
+			assert.NoError(t, checker.Report(context.Background(), req))
+			checker.flush()
+		}
+		rsp, err := checker.Query(context.Background(), &plugin.QueryRequest{InstanceId: "ins-404"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), rsp.Count)
+	})
+
+	t.Run("batch_query", func(t *testing.T) {
+		for _, id := range []string{"ins-2", "ins-3", "ins-4"} {
+			assert.NoError(t, checker.Report(context.Background(), newReportRequest(id, 300, 10)))
+		}
+		checker.flush()
+		rsp, err := checker.BatchQuery(context.Background(), &plugin.BatchQueryRequest{
+			Requests: []*plugin.QueryRequest{
+				{InstanceId: "ins-2"}, {InstanceId: "ins-none"}, {InstanceId: "ins-3"}, {InstanceId: "ins-4"},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, rsp.Responses, 4)
+		assert.True(t, rsp.Responses[0].Exists)
+		assert.False(t, rsp.Responses[1].Exists)
+		assert.Equal(t, int64(300), rsp.Responses[3].LastHeartbeatSec)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		assert.NoError(t, checker.Report(context.Background(), newReportRequest("ins-2", 310, 10)))
+		assert.NoError(t, checker.Delete(context.Background(), "ins-2"))
+		rsp, err := checker.Query(context.Background(), &plugin.QueryRequest{InstanceId: "ins-2"})
+		assert.NoError(t, err)
+		assert.False(t, rsp.Exists)
+	})
+}
+
+func TestStoreHealthChecker_Check(t *testing.T) {
+	checker, beatStore := newTestChecker(t)
+	curTimeSec := commontime.CurrentMillisecond() / 1000
+	checkRequest := func(healthy bool) *plugin.CheckRequest {
+		return &plugin.CheckRequest{
+			QueryRequest:      plugin.QueryRequest{InstanceId: "ins-1", Healthy: healthy},
+			ExpireDurationSec: 15,
+			CurTimeSec: func() int64 {
+				return curTimeSec
+			},
+		}
+	}
+
+	assert.NoError(t, checker.Report(context.Background(), newReportRequest("ins-1", curTimeSec-20, 5)))
+	checker.flush()
+	rsp, err := checker.Check(checkRequest(true))
+	assert.NoError(t, err)
+	assert.False(t, rsp.Healthy)
+	assert.False(t, rsp.StayUnchanged)
+
+	assert.NoError(t, checker.Report(context.Background(), newReportRequest("ins-1", curTimeSec, 5)))
+	rsp, err = checker.Check(checkRequest(false))
+	assert.NoError(t, err)
+	assert.True(t, rsp.Healthy)
+	assert.False(t, rsp.StayUnchanged)
+
+	t.Run("store_failure", func(t *testing.T) {
+		beatStore.fail = true
+		checker.flush()
+		// 写入失败的心跳保留在缓冲中，检查暂停
+		rsp, err := checker.Check(checkRequest(true))
+		assert.NoError(t, err)
+		assert.True(t, rsp.StayUnchanged)
+
+		beatStore.fail = false
+		checker.flush()
+		saved, err := beatStore.GetHeartbeats([]string{"ins-1"})
+		assert.NoError(t, err)
+		assert.Equal(t, curTimeSec, saved["ins-1"].CurTimeSec)
+		// 恢复期内同样不做变更
+		assert.True(t, checker.skipCheck("ins-1", 15))
+	})
+
+	t.Run("suspend", func(t *testing.T) {
+		checker.Suspend()
+		assert.True(t, checker.skipCheck("ins-1", 15))
+		assert.True(t, checker.SuspendTimeSec() > 0)
+	})
+}
+
+func TestStoreHealthChecker_Config(t *testing.T) {
+	conf, err := unmarshal(map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultFlushInterval, conf.FlushInterval)
+	assert.Equal(t, DefaultBatchSize, conf.BatchSize)
+	assert.Equal(t, DefaultCoalesceRatio, conf.CoalesceRatio)
+
+	conf, err = unmarshal(map[string]interface{}{"flushInterval": "3s", "coalesceRatio": 0.8})
+	assert.NoError(t, err)
+	assert.Equal(t, 3*time.Second, conf.FlushInterval)
+	assert.Equal(t, 0.8, conf.CoalesceRatio)
+
+	_, err = unmarshal(map[string]interface{}{"coalesceRatio": 1.5})
+	assert.Error(t, err)
+}
@@ -24,6 +24,7 @@ import (
 	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
 
 	"github.com/polarismesh/polaris/cache"
+	"github.com/polarismesh/polaris/common/model"
 	"github.com/polarismesh/polaris/store"
 )
 
@@ -34,6 +35,8 @@ type Controller struct {
 	heartbeat        *InstanceCtrl
 	clientRegister   *ClientCtrl
 	clientDeregister *ClientCtrl
+	report           *ReportCtrl
+	healthHistory    *HealthHistoryCtrl
 }
 
 // NewBatchCtrlWithConfig 根据配置文件创建一个批量控制器
@@ -78,12 +81,28 @@ func NewBatchCtrlWithConfig(storage store.Store, cacheMgn *cache.CacheManager, c
 		return nil, err
 	}
 
+	var report *ReportCtrl
+	report, err = NewBatchReportCtrl(config.Report)
+	if err != nil {
+		log.Errorf("[Batch] new batch report ctrl err: %s", err.Error())
+		return nil, err
+	}
+
+	var healthHistory *HealthHistoryCtrl
+	healthHistory, err = NewBatchHealthHistoryCtrl(config.HealthHistory)
+	if err != nil {
+		log.Errorf("[Batch] new batch health history ctrl err: %s", err.Error())
+		return nil, err
+	}
+
 	bc := &Controller{
 		register:         register,
 		deregister:       deregister,
 		heartbeat:        heartbeat,
 		clientRegister:   clientRegister,
 		clientDeregister: clientDeregister,
+		report:           report,
+		healthHistory:    healthHistory,
 	}
 	return bc, nil
 }
@@ -108,6 +127,20 @@ func (bc *Controller) Start(ctx context.Context) {
 	}
 }
 
+// StartReport 开启心跳上报的合并，处理函数由健康检查模块提供，因此不在 Start 中启动
+func (bc *Controller) StartReport(ctx context.Context, handler func([]*apiservice.InstanceHeartbeat)) {
+	if bc.ReportOpen() {
+		bc.report.Start(ctx, handler)
+	}
+}
+
+// StartHealthHistory 开启健康状态变更记录的合并写入，处理函数由健康检查模块提供，因此不在 Start 中启动
+func (bc *Controller) StartHealthHistory(ctx context.Context, handler func([]*model.InstanceHealthTransition)) {
+	if bc.HealthHistoryOpen() {
+		bc.healthHistory.Start(ctx, handler)
+	}
+}
+
 // CreateInstanceOpen 创建是否开启
 func (bc *Controller) CreateInstanceOpen() bool {
 	return bc.register != nil
@@ -133,6 +166,16 @@ func (bc *Controller) ClientDeregisterOpen() bool {
 	return bc.clientDeregister != nil
 }
 
+// ReportOpen 心跳上报合并是否开启
+func (bc *Controller) ReportOpen() bool {
+	return bc.report != nil
+}
+
+// HealthHistoryOpen 健康状态变更记录合并写入是否开启
+func (bc *Controller) HealthHistoryOpen() bool {
+	return bc.healthHistory != nil
+}
+
 // AsyncCreateInstance 异步创建实例，返回一个future，根据future获取创建结果
 func (bc *Controller) AsyncCreateInstance(svcId string, instance *apiservice.Instance, needWait bool) *InstanceFuture {
 	future := &InstanceFuture{
@@ -199,3 +242,13 @@ func (bc *Controller) AsyncDeregisterClient(client *apiservice.Client) *ClientFu
 	bc.clientDeregister.queue <- future
 	return future
 }
+
+// AsyncReport 异步合并心跳上报，不等待处理结果，队列已满时返回 false
+func (bc *Controller) AsyncReport(beat *apiservice.InstanceHeartbeat) bool {
+	return bc.report.offer(beat)
+}
+
+// AsyncRecordHealthHistory 异步合并写入健康状态变更记录，不等待写入结果，队列已满时返回 false
+func (bc *Controller) AsyncRecordHealthHistory(record *model.InstanceHealthTransition) bool {
+	return bc.healthHistory.offer(record)
+}
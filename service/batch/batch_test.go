@@ -164,3 +164,65 @@ func TestSendReply(t *testing.T) {
 		SendClientReply("test string", 1, nil)
 	})
 }
+
+// TestAsyncReport 测试心跳上报的合并
+func TestAsyncReport(t *testing.T) {
+	bc, err := NewBatchCtrlWithConfig(nil, nil, &Config{
+		Report: &CtrlConfig{
+			Open:          true,
+			QueueSize:     2,
+			WaitTime:      "10ms",
+			MaxBatchCount: 2,
+			Concurrency:   1,
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, bc.ReportOpen())
+
+	// 未启动时不消费队列，队列满了之后丢弃
+	assert.True(t, bc.AsyncReport(&apiservice.InstanceHeartbeat{InstanceId: "ins-0"}))
+	assert.True(t, bc.AsyncReport(&apiservice.InstanceHeartbeat{InstanceId: "ins-1"}))
+	assert.False(t, bc.AsyncReport(&apiservice.InstanceHeartbeat{InstanceId: "ins-2"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan []*apiservice.InstanceHeartbeat, 1)
+	bc.StartReport(ctx, func(beats []*apiservice.InstanceHeartbeat) {
+		received <- beats
+	})
+	beats := <-received
+	assert.Len(t, beats, 2)
+	assert.Equal(t, "ins-0", beats[0].GetInstanceId())
+	assert.Equal(t, "ins-1", beats[1].GetInstanceId())
+}
+
+// TestAsyncRecordHealthHistory 测试健康状态变更记录的合并写入
+func TestAsyncRecordHealthHistory(t *testing.T) {
+	bc, err := NewBatchCtrlWithConfig(nil, nil, &Config{
+		HealthHistory: &CtrlConfig{
+			Open:          true,
+			QueueSize:     2,
+			WaitTime:      "10ms",
+			MaxBatchCount: 2,
+			Concurrency:   1,
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, bc.HealthHistoryOpen())
+
+	// 未启动时不消费队列，队列满了之后丢弃
+	assert.True(t, bc.AsyncRecordHealthHistory(&model.InstanceHealthTransition{InstanceID: "ins-0"}))
+	assert.True(t, bc.AsyncRecordHealthHistory(&model.InstanceHealthTransition{InstanceID: "ins-1"}))
+	assert.False(t, bc.AsyncRecordHealthHistory(&model.InstanceHealthTransition{InstanceID: "ins-2"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan []*model.InstanceHealthTransition, 1)
+	bc.StartHealthHistory(ctx, func(records []*model.InstanceHealthTransition) {
+		received <- records
+	})
+	records := <-received
+	assert.Len(t, records, 2)
+	assert.Equal(t, "ins-0", records[0].InstanceID)
+	assert.Equal(t, "ins-1", records[1].InstanceID)
+}
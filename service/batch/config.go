@@ -30,6 +30,10 @@ type Config struct {
 	Heartbeat        *CtrlConfig `mapstructure:"heartbeat"`
 	ClientRegister   *CtrlConfig `mapstructure:"clientRegister"`
 	ClientDeregister *CtrlConfig `mapstructure:"clientDeregister"`
+	// Report 心跳上报的合并，目前只有 UDP 心跳接入使用
+	Report *CtrlConfig `mapstructure:"report"`
+	// HealthHistory 实例健康状态变更记录的合并写入，由健康检查使用
+	HealthHistory *CtrlConfig `mapstructure:"healthHistory"`
 }
 
 // CtrlConfig batch控制配置项
@@ -86,6 +90,20 @@ func defaultBatchCtrlConfig() *Config {
 			MaxBatchCount: 32,
 			Concurrency:   64,
 		},
+		Report: &CtrlConfig{
+			Open:          true,
+			QueueSize:     10240,
+			WaitTime:      "32ms",
+			MaxBatchCount: 128,
+			Concurrency:   16,
+		},
+		HealthHistory: &CtrlConfig{
+			Open:          true,
+			QueueSize:     10240,
+			WaitTime:      "32ms",
+			MaxBatchCount: 128,
+			Concurrency:   4,
+		},
 	}
 }
 
@@ -122,6 +140,14 @@ func ParseBatchConfig(opt map[string]interface{}) (*Config, error) {
 		log.Errorf("[Controller] batch client deregister config is invalid: %+v", config)
 		return nil, errors.New("batch client deregister config is invalid")
 	}
+	if !checkCtrlConfig(config.Report) {
+		log.Errorf("[Controller] batch report config is invalid: %+v", config)
+		return nil, errors.New("batch report config is invalid")
+	}
+	if !checkCtrlConfig(config.HealthHistory) {
+		log.Errorf("[Controller] batch health history config is invalid: %+v", config)
+		return nil, errors.New("batch health history config is invalid")
+	}
 	return config, nil
 }
 
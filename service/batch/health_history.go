@@ -0,0 +1,133 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package batch
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+// HealthHistoryCtrl 合并实例健康状态变更记录的写入，批量交给健康检查落库，记录方不等待写入结果
+type HealthHistoryCtrl struct {
+	config       *CtrlConfig
+	workerCh     []chan []*model.InstanceHealthTransition
+	idleWorker   chan int
+	waitDuration time.Duration
+	queue        chan *model.InstanceHealthTransition
+	handler      func([]*model.InstanceHealthTransition)
+}
+
+// NewBatchHealthHistoryCtrl 健康状态变更记录批量操作对象
+func NewBatchHealthHistoryCtrl(config *CtrlConfig) (*HealthHistoryCtrl, error) {
+	if config == nil || !config.Open {
+		return nil, nil
+	}
+	duration, err := time.ParseDuration(config.WaitTime)
+	if err != nil {
+		log.Errorf("[Batch] parse waitTime(%s) err: %s", config.WaitTime, err.Error())
+		return nil, err
+	}
+	if duration == 0 {
+		log.Errorf("[Batch] config waitTime is invalid")
+		return nil, errors.New("config waitTime is invalid")
+	}
+
+	log.Infof("[Batch] open batch instance health history")
+	return &HealthHistoryCtrl{
+		config:       config,
+		workerCh:     make([]chan []*model.InstanceHealthTransition, 0, config.Concurrency),
+		idleWorker:   make(chan int, config.Concurrency),
+		queue:        make(chan *model.InstanceHealthTransition, config.QueueSize),
+		waitDuration: duration,
+	}, nil
+}
+
+// Start 开始启动批量写入变更记录的相关协程，handler 为真正写入一批记录的函数
+func (ctrl *HealthHistoryCtrl) Start(ctx context.Context, handler func([]*model.InstanceHealthTransition)) {
+	log.Infof("[Batch][HealthHistory] Start batch health history, config: %+v", ctrl.config)
+
+	ctrl.handler = handler
+	for i := 0; i < ctrl.config.Concurrency; i++ {
+		ctrl.workerCh = append(ctrl.workerCh, make(chan []*model.InstanceHealthTransition))
+	}
+	for i := 0; i < ctrl.config.Concurrency; i++ {
+		go ctrl.worker(ctx, i)
+	}
+
+	ctrl.mainLoop(ctx)
+}
+
+// offer 非阻塞地放入写入队列，队列已满时返回 false
+func (ctrl *HealthHistoryCtrl) offer(record *model.InstanceHealthTransition) bool {
+	select {
+	case ctrl.queue <- record:
+		return true
+	default:
+		return false
+	}
+}
+
+// mainLoop 从队列中获取变更记录，当达到 MaxBatchCount 或者到了 waitDuration 时，
+// 从空闲的 worker 中挑选一个写入这一批记录
+func (ctrl *HealthHistoryCtrl) mainLoop(ctx context.Context) {
+	records := make([]*model.InstanceHealthTransition, 0, ctrl.config.MaxBatchCount)
+	triggerConsume := func() {
+		if len(records) == 0 {
+			return
+		}
+		idleIdx := <-ctrl.idleWorker
+		ctrl.workerCh[idleIdx] <- records
+		records = make([]*model.InstanceHealthTransition, 0, ctrl.config.MaxBatchCount)
+	}
+	go func() {
+		ticker := time.NewTicker(ctrl.waitDuration)
+		defer ticker.Stop()
+		for {
+			select {
+			case record := <-ctrl.queue:
+				records = append(records, record)
+				if len(records) == ctrl.config.MaxBatchCount {
+					triggerConsume()
+				}
+			case <-ticker.C:
+				triggerConsume()
+			case <-ctx.Done():
+				log.Debugf("[Batch] health history main loop exited")
+				return
+			}
+		}
+	}()
+}
+
+// worker 写入协程的主循环，每次处理完，设置协程为空闲
+func (ctrl *HealthHistoryCtrl) worker(ctx context.Context, index int) {
+	log.Debugf("[Batch][HealthHistory] worker(%d) running in main loop", index)
+	ctrl.idleWorker <- index
+	for {
+		select {
+		case records := <-ctrl.workerCh[index]:
+			ctrl.handler(records)
+			ctrl.idleWorker <- index
+		case <-ctx.Done():
+			log.Infof("[Batch][HealthHistory] worker(%d) exited", index)
+			return
+		}
+	}
+}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/polarismesh/polaris/cache"
 	api "github.com/polarismesh/polaris/common/api/v1"
+	"github.com/polarismesh/polaris/common/eventhub"
 	"github.com/polarismesh/polaris/common/model"
 	commonstore "github.com/polarismesh/polaris/common/store"
 	"github.com/polarismesh/polaris/common/utils"
@@ -384,6 +385,7 @@ func (ctrl *InstanceCtrl) heartbeatHandler(futures []*InstanceFuture) error {
 			return err
 		}
 	}
+	eventhub.PublishCacheChange(model.RInstance)
 	sendReply(futures, apimodel.Code_ExecuteSuccess, nil)
 	return nil
 }
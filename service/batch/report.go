@@ -0,0 +1,134 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+)
+
+// ReportCtrl 合并心跳上报请求，批量交给健康检查处理，上报方不等待处理结果
+type ReportCtrl struct {
+	config       *CtrlConfig
+	workerCh     []chan []*apiservice.InstanceHeartbeat
+	idleWorker   chan int
+	waitDuration time.Duration
+	queue        chan *apiservice.InstanceHeartbeat
+	handler      func([]*apiservice.InstanceHeartbeat)
+}
+
+// NewBatchReportCtrl 心跳上报批量操作对象
+func NewBatchReportCtrl(config *CtrlConfig) (*ReportCtrl, error) {
+	if config == nil || !config.Open {
+		return nil, nil
+	}
+	duration, err := time.ParseDuration(config.WaitTime)
+	if err != nil {
+		log.Errorf("[Batch] parse waitTime(%s) err: %s", config.WaitTime, err.Error())
+		return nil, err
+	}
+	if duration == 0 {
+		log.Errorf("[Batch] config waitTime is invalid")
+		return nil, errors.New("config waitTime is invalid")
+	}
+
+	log.Infof("[Batch] open batch report heartbeat")
+	return &ReportCtrl{
+		config:       config,
+		workerCh:     make([]chan []*apiservice.InstanceHeartbeat, 0, config.Concurrency),
+		idleWorker:   make(chan int, config.Concurrency),
+		queue:        make(chan *apiservice.InstanceHeartbeat, config.QueueSize),
+		waitDuration: duration,
+	}, nil
+}
+
+// Start 开始启动批量上报心跳的相关协程，handler 为真正处理一批心跳的函数
+func (ctrl *ReportCtrl) Start(ctx context.Context, handler func([]*apiservice.InstanceHeartbeat)) {
+	log.Infof("[Batch][Report] Start batch report, config: %+v", ctrl.config)
+
+	ctrl.handler = handler
+	for i := 0; i < ctrl.config.Concurrency; i++ {
+		ctrl.workerCh = append(ctrl.workerCh, make(chan []*apiservice.InstanceHeartbeat))
+	}
+	for i := 0; i < ctrl.config.Concurrency; i++ {
+		go ctrl.worker(ctx, i)
+	}
+
+	ctrl.mainLoop(ctx)
+}
+
+// offer 非阻塞地放入上报队列，队列已满时返回 false
+func (ctrl *ReportCtrl) offer(beat *apiservice.InstanceHeartbeat) bool {
+	select {
+	case ctrl.queue <- beat:
+		return true
+	default:
+		return false
+	}
+}
+
+// mainLoop 从队列中获取心跳，当达到 MaxBatchCount 或者到了 waitDuration 时，
+// 从空闲的 worker 中挑选一个处理这一批心跳
+func (ctrl *ReportCtrl) mainLoop(ctx context.Context) {
+	beats := make([]*apiservice.InstanceHeartbeat, 0, ctrl.config.MaxBatchCount)
+	triggerConsume := func() {
+		if len(beats) == 0 {
+			return
+		}
+		idleIdx := <-ctrl.idleWorker
+		ctrl.workerCh[idleIdx] <- beats
+		beats = make([]*apiservice.InstanceHeartbeat, 0, ctrl.config.MaxBatchCount)
+	}
+	go func() {
+		ticker := time.NewTicker(ctrl.waitDuration)
+		defer ticker.Stop()
+		for {
+			select {
+			case beat := <-ctrl.queue:
+				beats = append(beats, beat)
+				if len(beats) == ctrl.config.MaxBatchCount {
+					triggerConsume()
+				}
+			case <-ticker.C:
+				triggerConsume()
+			case <-ctx.Done():
+				log.Debugf("[Batch] report main loop exited")
+				return
+			}
+		}
+	}()
+}
+
+// worker 处理协程的主循环，每次处理完，设置协程为空闲
+func (ctrl *ReportCtrl) worker(ctx context.Context, index int) {
+	log.Debugf("[Batch][Report] worker(%d) running in main loop", index)
+	ctrl.idleWorker <- index
+	for {
+		select {
+		case beats := <-ctrl.workerCh[index]:
+			ctrl.handler(beats)
+			ctrl.idleWorker <- index
+		case <-ctx.Done():
+			log.Infof("[Batch][Report] worker(%d) exited", index)
+			return
+		}
+	}
+}
@@ -115,7 +115,7 @@ func (c *CacheProvider) OnCreated(value interface{}) {
 			c.sendEvent(CacheEvent{selfServiceInstancesChanged: true})
 			return
 		}
-		hcEnable, checker := c.isHealthCheckEnable(instProto)
+		hcEnable, checker := c.isHealthCheckEnable(actual)
 		if !hcEnable {
 			return
 		}
@@ -136,11 +136,20 @@ func (c *CacheProvider) getHealthChecker(hcType apiservice.HealthCheck_HealthChe
 	return checker, ok
 }
 
-func (c *CacheProvider) isHealthCheckEnable(instance *apiservice.Instance) (bool, plugin.HealthChecker) {
-	if !instance.GetEnableHealthCheck().GetValue() || instance.GetHealthCheck() == nil {
+func (c *CacheProvider) isHealthCheckEnable(instance *model.Instance) (bool, plugin.HealthChecker) {
+	if !instance.EnableHealthCheck() {
 		return false, nil
 	}
-	checker, ok := c.getHealthChecker(instance.GetHealthCheck().GetType())
+	// 实例或者服务的元数据中配置了探测方式时，由服务端主动探测实例
+	if c.svr.probeType(instance) != "" {
+		if checker, ok := c.svr.checkers[int32(plugin.HealthCheckerProbe)]; ok {
+			return true, checker
+		}
+	}
+	if instance.HealthCheck() == nil {
+		return false, nil
+	}
+	checker, ok := c.getHealthChecker(instance.HealthCheck().GetType())
 	if !ok {
 		return false, nil
 	}
@@ -161,7 +170,7 @@ func (c *CacheProvider) OnUpdated(value interface{}) {
 		// check exists
 		instanceId := actual.ID()
 		value, exists := c.healthCheckInstances.Get(instanceId)
-		hcEnable, checker := c.isHealthCheckEnable(instProto)
+		hcEnable, checker := c.isHealthCheckEnable(actual)
 		if !hcEnable {
 			if !exists {
 				// instance is unhealthy, not exist, just return.
@@ -211,7 +220,7 @@ func (c *CacheProvider) OnDeleted(value interface{}) {
 			c.sendEvent(CacheEvent{selfServiceInstancesChanged: true})
 			return
 		}
-		if !instProto.GetEnableHealthCheck().GetValue() || instProto.GetHealthCheck() == nil {
+		if !instProto.GetEnableHealthCheck().GetValue() {
 			return
 		}
 		deleteServiceInstance(instProto, c.healthCheckInstances)
@@ -271,6 +280,15 @@ func (c *CacheProvider) GetInstance(instanceId string) *model.Instance {
 	return ins
 }
 
+// GetInstanceChecker get the health checker of instance by id
+func (c *CacheProvider) GetInstanceChecker(instanceId string) (plugin.HealthChecker, bool) {
+	value, ok := c.healthCheckInstances.Get(instanceId)
+	if !ok || value.GetChecker() == nil {
+		return nil, false
+	}
+	return value.GetChecker(), true
+}
+
 // GetInstance get instance by id
 func (c *CacheProvider) GetClient(clientId string) *model.Client {
 	value, ok := c.healthCheckClients.Get(clientId)
@@ -79,6 +79,7 @@ type itemValue struct {
 	ttlDurationSec    uint32
 	expireDurationSec uint32
 	checker           plugin.HealthChecker
+	damping           dampingState
 }
 
 type ResourceHealthCheckHandler struct {
@@ -108,12 +109,13 @@ func (handler *ResourceHealthCheckHandler) OnEvent(ctx context.Context, i interf
 		if event.EType != model.EventInstanceOffline {
 			return nil
 		}
+		s.deleteHealthHistory(event.Id)
 		insCache := s.cacheProvider.GetInstance(event.Id)
 		if insCache == nil {
 			log.Errorf("[Health Check] cannot get instance from cache, instance id is %s", event.Id)
 			break
 		}
-		checker, ok := s.checkers[int32(insCache.HealthCheck().GetType())]
+		checker, ok := s.cacheProvider.GetInstanceChecker(event.Id)
 		if !ok {
 			log.Errorf("[Health Check]heart beat type not found checkType %d",
 				int32(insCache.HealthCheck().GetType()))
@@ -318,6 +320,10 @@ func (c *CheckScheduler) addUnHealthyCallback(instance *itemValue) {
 	if c.maxCheckIntervalSec > 0 && int64(delaySec) > c.maxCheckIntervalSec {
 		delaySec = uint32(c.maxCheckIntervalSec)
 	}
+	// 主动探测的实例可以不配置心跳 ttl，避免检查失败时不断重试
+	if int64(delaySec) < c.minCheckIntervalSec {
+		delaySec = uint32(c.minCheckIntervalSec)
+	}
 	host := instance.host
 	port := instance.port
 	instanceId := instance.id
@@ -327,6 +333,17 @@ func (c *CheckScheduler) addUnHealthyCallback(instance *itemValue) {
 	c.timeWheel.AddTask(delayMilli, instanceId, c.checkCallbackInstance)
 }
 
+// addIntervalCallback 按照检查插件指定的周期调度下一次检查
+func (c *CheckScheduler) addIntervalCallback(instance *itemValue, intervalSec int64) {
+	if intervalSec < c.minCheckIntervalSec {
+		intervalSec = c.minCheckIntervalSec
+	}
+	delayMilli := uint32(intervalSec)*1000 + getRandDelayMilli()
+	log.Debugf("[Health Check][Check]add interval instance callback, addr is %s:%d, id is %s, delay is %d(ms)",
+		instance.host, instance.port, instance.id, delayMilli)
+	c.timeWheel.AddTask(delayMilli, instance.id, c.checkCallbackInstance)
+}
+
 func (c *CheckScheduler) checkCallbackClient(clientId string) *clientItemValue {
 	clientValue, ok := c.getClientValue(clientId)
 	if !ok {
@@ -389,9 +406,12 @@ func (c *CheckScheduler) checkCallbackInstance(value interface{}) {
 		err       error
 	)
 	defer func() {
-		if checkResp != nil && checkResp.Regular && checkResp.Healthy {
+		switch {
+		case checkResp != nil && checkResp.NextCheckIntervalSec > 0:
+			c.addIntervalCallback(instanceValue, checkResp.NextCheckIntervalSec)
+		case checkResp != nil && checkResp.Regular && checkResp.Healthy:
 			c.addHealthyCallback(instanceValue, checkResp.LastHeartbeatTimeSec)
-		} else {
+		default:
 			c.addUnHealthyCallback(instanceValue)
 		}
 	}()
@@ -411,31 +431,56 @@ func (c *CheckScheduler) checkCallbackInstance(value interface{}) {
 		CurTimeSec:        c.svr.currentTimeSec,
 		ExpireDurationSec: instanceValue.expireDurationSec,
 	}
+	if instanceValue.checker.Type() == plugin.HealthCheckerProbe {
+		request.Metadata = c.svr.probeMetadata(cachedInstance)
+	}
 	checkResp, err = instanceValue.checker.Check(request)
 	if err != nil {
 		log.Errorf("[Health Check][Check]fail to check instance %s:%d, id is %s, err is %v",
 			instanceValue.host, instanceValue.port, instanceValue.id, err)
 		return
 	}
-	if !checkResp.StayUnchanged {
-		code := setInsDbStatus(c.svr, cachedInstance, checkResp.Healthy, checkResp.LastHeartbeatTimeSec)
-		if checkResp.Healthy {
-			// from unhealthy to healthy
-			log.Infof(
-				"[Health Check][Check]instance change from unhealthy to healthy, id is %s, address is %s:%d",
-				instanceValue.id, instanceValue.host, instanceValue.port)
-		} else {
-			// from healthy to unhealthy
-			log.Infof(
-				"[Health Check][Check]instance change from healthy to unhealthy, id is %s, address is %s:%d",
-				instanceValue.id, instanceValue.host, instanceValue.port)
+	if checkResp.StayUnchanged {
+		instanceValue.damping.reset()
+		return
+	}
+	if !checkResp.Healthy {
+		if suspension := c.svr.matchSuspension(cachedInstance); suspension != nil {
+			log.Infof("[Health Check][Check]instance unhealthy marking is suspended by %s, id is %s, address is %s:%d",
+				suspension.ID, instanceValue.id, instanceValue.host, instanceValue.port)
+			return
 		}
-		if code != apimodel.Code_ExecuteSuccess {
-			log.Errorf(
-				"[Health Check][Check]fail to update instance, id is %s, address is %s:%d, code is %d",
-				instanceValue.id, instanceValue.host, instanceValue.port, code)
+	}
+	decision := instanceValue.damping.apply(c.svr.dampingPolicy(cachedInstance), checkResp.Healthy,
+		c.svr.currentTimeSec())
+	if !decision.change {
+		if decision.suppressUntil > 0 {
+			log.Infof("[Health Check][Check]instance is flapping, keep unhealthy until %d, id is %s, address is %s:%d",
+				decision.suppressUntil, instanceValue.id, instanceValue.host, instanceValue.port)
 		}
+		return
+	}
+	code := setInsDbStatus(c.svr, cachedInstance, checkResp.Healthy, checkResp.LastHeartbeatTimeSec)
+	if checkResp.Healthy {
+		// from unhealthy to healthy
+		log.Infof(
+			"[Health Check][Check]instance change from unhealthy to healthy, id is %s, address is %s:%d",
+			instanceValue.id, instanceValue.host, instanceValue.port)
+	} else {
+		// from healthy to unhealthy
+		log.Infof(
+			"[Health Check][Check]instance change from healthy to unhealthy, id is %s, address is %s:%d",
+			instanceValue.id, instanceValue.host, instanceValue.port)
+	}
+	if code != apimodel.Code_ExecuteSuccess {
+		log.Errorf(
+			"[Health Check][Check]fail to update instance, id is %s, address is %s:%d, code is %d",
+			instanceValue.id, instanceValue.host, instanceValue.port, code)
+		return
 	}
+	c.svr.recordHealthTransition(cachedInstance, checkResp.Healthy,
+		transitionReason(instanceValue.checker.Type(), checkResp.Healthy), transitionDetail(decision),
+		checkResp.LastHeartbeatTimeSec)
 }
 
 // DelClient del client from check
@@ -618,6 +663,7 @@ func serialSetInsDbStatus(svr *Server, ins *apiservice.Instance, healthStatus bo
 			return commonstore.StoreCode2APICode(err)
 		}
 	}
+	eventhub.PublishCacheChange(model.RInstance)
 	return apimodel.Code_ExecuteSuccess
 }
 
@@ -36,6 +36,28 @@ type Config struct {
 	ClientCheckTtl      time.Duration          `yaml:"clientCheckTtl"`
 	Checkers            []plugin.ConfigEntry   `yaml:"checkers"`
 	Batch               map[string]interface{} `yaml:"batch"`
+	// Damping 实例健康状态变更的默认抑制策略，可以被服务或者实例元数据中的配置覆盖
+	Damping DampingConfig `yaml:"damping"`
+	// HistorySize 每个实例保留的健康状态变更记录条数，小于 0 表示不记录
+	HistorySize int `yaml:"historySize"`
+	// SuspendRefreshInterval 从存储层同步按范围暂停规则的周期
+	SuspendRefreshInterval time.Duration `yaml:"suspendRefreshInterval"`
+	// MaxSuspendTtl 按范围暂停的最长时间
+	MaxSuspendTtl time.Duration `yaml:"maxSuspendTtl"`
+}
+
+// DampingConfig 健康状态变更的抑制配置
+type DampingConfig struct {
+	// Fall 连续多少次检查不健康后才将实例置为不健康
+	Fall int `yaml:"fall"`
+	// Rise 连续多少次检查健康后才将实例恢复为健康
+	Rise int `yaml:"rise"`
+	// FlapThreshold 统计窗口内健康状态变化超过多少次认为实例在抖动，0 表示不做抖动抑制
+	FlapThreshold int `yaml:"flapThreshold"`
+	// FlapWindow 抖动的统计窗口
+	FlapWindow time.Duration `yaml:"flapWindow"`
+	// FlapSuppress 抖动实例的惩罚时长，期间实例保持不健康
+	FlapSuppress time.Duration `yaml:"flapSuppress"`
 }
 
 const (
@@ -44,6 +66,11 @@ const (
 	defaultSlotNum             = 30
 	defaultClientReportTtl     = 120 * time.Second
 	defaultClientCheckInterval = 120 * time.Second
+	defaultFlapWindow          = 5 * time.Minute
+	defaultFlapSuppress        = 5 * time.Minute
+	defaultHistorySize         = 50
+	defaultSuspendRefresh      = 5 * time.Second
+	defaultMaxSuspendTtl       = 24 * time.Hour
 )
 
 func (c *Config) IsOpen() bool {
@@ -80,4 +107,25 @@ func (c *Config) SetDefault() {
 	if c.ClientCheckTtl == 0 {
 		c.ClientCheckTtl = defaultClientReportTtl
 	}
+	if c.Damping.Fall <= 0 {
+		c.Damping.Fall = 1
+	}
+	if c.Damping.Rise <= 0 {
+		c.Damping.Rise = 1
+	}
+	if c.Damping.FlapWindow <= 0 {
+		c.Damping.FlapWindow = defaultFlapWindow
+	}
+	if c.Damping.FlapSuppress <= 0 {
+		c.Damping.FlapSuppress = defaultFlapSuppress
+	}
+	if c.HistorySize == 0 {
+		c.HistorySize = defaultHistorySize
+	}
+	if c.SuspendRefreshInterval <= 0 {
+		c.SuspendRefreshInterval = defaultSuspendRefresh
+	}
+	if c.MaxSuspendTtl <= 0 {
+		c.MaxSuspendTtl = defaultMaxSuspendTtl
+	}
 }
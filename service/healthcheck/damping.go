@@ -0,0 +1,159 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+// dampingPolicy 实例健康状态变更的抑制策略
+type dampingPolicy struct {
+	fall          int
+	rise          int
+	flapThreshold int
+	flapWindowSec int64
+	suppressSec   int64
+}
+
+// dampingState 实例的抑制状态，由 itemValue 的锁保护
+type dampingState struct {
+	// failures 当前健康的实例连续检查不健康的次数
+	failures int
+	// successes 当前不健康的实例连续检查健康的次数
+	successes int
+	// flapTimes 统计窗口内状态变化的时间
+	flapTimes []int64
+	// suppressUntil 抖动抑制的截止时间，期间实例不会恢复为健康
+	suppressUntil int64
+}
+
+// dampingDecision 经过抑制策略之后的结果
+type dampingDecision struct {
+	// change 是否需要修改实例的健康状态
+	change bool
+	// streak 触发状态变更时连续检查的次数
+	streak int
+	// suppressUntil 本次检查触发了抖动抑制时，抑制的截止时间
+	suppressUntil int64
+}
+
+// reset 检查结果和实例当前状态一致，连续计数重新开始
+func (d *dampingState) reset() {
+	d.failures = 0
+	d.successes = 0
+}
+
+// apply 根据本次检查结果判断是否需要修改实例的健康状态，调用前已经确认检查结果和实例当前状态不一致
+func (d *dampingState) apply(policy *dampingPolicy, healthy bool, curTimeSec int64) dampingDecision {
+	if healthy {
+		d.successes++
+		d.failures = 0
+		if d.successes < policy.rise || curTimeSec < d.suppressUntil {
+			return dampingDecision{}
+		}
+		if d.flapping(policy, curTimeSec) {
+			// 恢复健康会导致抖动次数超出阈值，实例继续保持不健康
+			d.suppressUntil = curTimeSec + policy.suppressSec
+			return dampingDecision{suppressUntil: d.suppressUntil}
+		}
+		streak := d.successes
+		d.successes = 0
+		return dampingDecision{change: true, streak: streak}
+	}
+	d.failures++
+	d.successes = 0
+	if d.failures < policy.fall {
+		return dampingDecision{}
+	}
+	decision := dampingDecision{change: true, streak: d.failures}
+	d.failures = 0
+	if d.flapping(policy, curTimeSec) {
+		d.suppressUntil = curTimeSec + policy.suppressSec
+		decision.suppressUntil = d.suppressUntil
+	}
+	return decision
+}
+
+// flapping 记录一次状态变化，返回统计窗口内的变化次数是否超过了阈值
+func (d *dampingState) flapping(policy *dampingPolicy, curTimeSec int64) bool {
+	if policy.flapThreshold <= 0 {
+		return false
+	}
+	validIdx := 0
+	for _, flapTime := range d.flapTimes {
+		if curTimeSec-flapTime < policy.flapWindowSec {
+			d.flapTimes[validIdx] = flapTime
+			validIdx++
+		}
+	}
+	d.flapTimes = append(d.flapTimes[:validIdx], curTimeSec)
+	if len(d.flapTimes) <= policy.flapThreshold {
+		return false
+	}
+	d.flapTimes = nil
+	return true
+}
+
+// dampingPolicy 实例的抑制策略，实例元数据优先于服务元数据，都没有配置时使用全局配置
+func (s *Server) dampingPolicy(instance *model.Instance) *dampingPolicy {
+	conf := s.hcOpt.Damping
+	policy := &dampingPolicy{
+		fall:          conf.Fall,
+		rise:          conf.Rise,
+		flapThreshold: conf.FlapThreshold,
+		flapWindowSec: int64(conf.FlapWindow.Seconds()),
+		suppressSec:   int64(conf.FlapSuppress.Seconds()),
+	}
+	meta := func(key string) string {
+		return s.metadataValue(instance, key)
+	}
+	if val, err := strconv.Atoi(meta(model.MetaKeyHealthDampingFall)); err == nil && val > 0 {
+		policy.fall = val
+	}
+	if val, err := strconv.Atoi(meta(model.MetaKeyHealthDampingRise)); err == nil && val > 0 {
+		policy.rise = val
+	}
+	if val, err := strconv.Atoi(meta(model.MetaKeyHealthFlapThreshold)); err == nil && val >= 0 {
+		policy.flapThreshold = val
+	}
+	if val, err := time.ParseDuration(meta(model.MetaKeyHealthFlapWindow)); err == nil && val > 0 {
+		policy.flapWindowSec = int64(val.Seconds())
+	}
+	if val, err := time.ParseDuration(meta(model.MetaKeyHealthFlapSuppress)); err == nil && val > 0 {
+		policy.suppressSec = int64(val.Seconds())
+	}
+	return policy
+}
+
+// transitionDetail 健康状态变更记录中的补充说明
+func transitionDetail(decision dampingDecision) string {
+	details := make([]string, 0, 2)
+	if decision.streak > 1 {
+		details = append(details, fmt.Sprintf("%d consecutive checks", decision.streak))
+	}
+	if decision.suppressUntil > 0 {
+		details = append(details, "flapping, suppressed until "+
+			time.Unix(decision.suppressUntil, 0).Format(time.RFC3339))
+	}
+	return strings.Join(details, "; ")
+}
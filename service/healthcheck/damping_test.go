@@ -0,0 +1,117 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_dampingState(t *testing.T) {
+	type step struct {
+		healthy bool
+		timeSec int64
+		expect  dampingDecision
+	}
+	tests := []struct {
+		name   string
+		policy *dampingPolicy
+		steps  []step
+	}{
+		{
+			name:   "no_damping",
+			policy: &dampingPolicy{fall: 1, rise: 1},
+			steps: []step{
+				{healthy: false, timeSec: 1, expect: dampingDecision{change: true, streak: 1}},
+				{healthy: true, timeSec: 2, expect: dampingDecision{change: true, streak: 1}},
+			},
+		},
+		{
+			name:   "consecutive_threshold",
+			policy: &dampingPolicy{fall: 3, rise: 2},
+			steps: []step{
+				{healthy: false, timeSec: 1},
+				{healthy: false, timeSec: 2},
+				{healthy: false, timeSec: 3, expect: dampingDecision{change: true, streak: 3}},
+				{healthy: true, timeSec: 4},
+				{healthy: true, timeSec: 5, expect: dampingDecision{change: true, streak: 2}},
+			},
+		},
+		{
+			name:   "streak_broken",
+			policy: &dampingPolicy{fall: 2, rise: 1},
+			steps: []step{
+				{healthy: false, timeSec: 1},
+				// 实例仍然健康，检查结果一致时不会调用 apply，这里用一次恢复来打断连续失败
+				{healthy: true, timeSec: 2, expect: dampingDecision{change: true, streak: 1}},
+				{healthy: false, timeSec: 3},
+				{healthy: false, timeSec: 4, expect: dampingDecision{change: true, streak: 2}},
+			},
+		},
+		{
+			name:   "flap_suppress",
+			policy: &dampingPolicy{fall: 1, rise: 1, flapThreshold: 2, flapWindowSec: 60, suppressSec: 100},
+			steps: []step{
+				{healthy: false, timeSec: 10, expect: dampingDecision{change: true, streak: 1}},
+				{healthy: true, timeSec: 20, expect: dampingDecision{change: true, streak: 1}},
+				// 第三次变化超出阈值，实例置为不健康并开始抑制
+				{healthy: false, timeSec: 30, expect: dampingDecision{change: true, streak: 1, suppressUntil: 130}},
+				{healthy: true, timeSec: 40},
+				{healthy: true, timeSec: 129},
+				{healthy: true, timeSec: 130, expect: dampingDecision{change: true, streak: 3}},
+			},
+		},
+		{
+			name:   "flap_suppress_on_recover",
+			policy: &dampingPolicy{fall: 1, rise: 1, flapThreshold: 2, flapWindowSec: 60, suppressSec: 100},
+			steps: []step{
+				{healthy: true, timeSec: 10, expect: dampingDecision{change: true, streak: 1}},
+				{healthy: false, timeSec: 20, expect: dampingDecision{change: true, streak: 1}},
+				// 恢复健康会超出阈值，保持不健康
+				{healthy: true, timeSec: 30, expect: dampingDecision{suppressUntil: 130}},
+				{healthy: true, timeSec: 131, expect: dampingDecision{change: true, streak: 2}},
+			},
+		},
+		{
+			name:   "flap_outside_window",
+			policy: &dampingPolicy{fall: 1, rise: 1, flapThreshold: 2, flapWindowSec: 60, suppressSec: 100},
+			steps: []step{
+				{healthy: false, timeSec: 10, expect: dampingDecision{change: true, streak: 1}},
+				{healthy: true, timeSec: 20, expect: dampingDecision{change: true, streak: 1}},
+				{healthy: false, timeSec: 75, expect: dampingDecision{change: true, streak: 1}},
+				{healthy: true, timeSec: 85, expect: dampingDecision{change: true, streak: 1}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &dampingState{}
+			for i, s := range tt.steps {
+				assert.Equal(t, s.expect, state.apply(tt.policy, s.healthy, s.timeSec), "step %d", i)
+			}
+		})
+	}
+}
+
+func Test_transitionDetail(t *testing.T) {
+	assert.Equal(t, "", transitionDetail(dampingDecision{change: true, streak: 1}))
+	assert.Equal(t, "3 consecutive checks", transitionDetail(dampingDecision{change: true, streak: 3}))
+	assert.Contains(t, transitionDetail(dampingDecision{change: true, streak: 2, suppressUntil: 100}),
+		"2 consecutive checks; flapping, suppressed until ")
+}
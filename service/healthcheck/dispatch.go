@@ -19,12 +19,14 @@ package healthcheck
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
 
+	"github.com/polarismesh/polaris/common/eventhub"
 	commonhash "github.com/polarismesh/polaris/common/hash"
 	"github.com/polarismesh/polaris/common/model"
 )
@@ -127,6 +129,8 @@ func (d *Dispatcher) reloadSelfContinuum() bool {
 	})
 	if len(nextBuckets) == 0 {
 		d.noAvailableServers = true
+	} else {
+		publishCheckerMembers(nextBuckets)
 	}
 	originBucket := d.selfServiceBuckets
 	log.Debugf("[Health Check][Dispatcher]reload continuum by %v, origin is %v", nextBuckets, originBucket)
@@ -145,6 +149,18 @@ func (d *Dispatcher) reloadSelfContinuum() bool {
 	return true
 }
 
+// publishCheckerMembers 通知心跳插件当前存活的 Polaris 节点，即使节点列表未变化也会发布，保证后订阅的插件能拿到数据
+func publishCheckerMembers(buckets map[commonhash.Bucket]bool) {
+	members := make([]string, 0, len(buckets))
+	for bucket := range buckets {
+		members = append(members, bucket.Host)
+	}
+	sort.Strings(members)
+	_ = eventhub.Publish(eventhub.CheckerMembersChangeTopic, eventhub.CheckerMembersChangeEvent{
+		Members: members,
+	})
+}
+
 func (d *Dispatcher) reloadManagedClients() {
 	nextClients := make(map[string]*ClientWithChecker)
 
@@ -0,0 +1,111 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"errors"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store"
+)
+
+// ErrHealthHistoryDisabled 没有开启健康状态变更历史，或者存储层不支持
+var ErrHealthHistoryDisabled = errors.New("instance health history is disabled or not supported by the store")
+
+// transitionReason 根据检查插件的类型得到状态变更的原因
+func transitionReason(checkType plugin.HealthCheckType, healthy bool) string {
+	switch {
+	case checkType == plugin.HealthCheckerProbe && healthy:
+		return model.HealthReasonProbeSucceeded
+	case checkType == plugin.HealthCheckerProbe:
+		return model.HealthReasonProbeFailed
+	case healthy:
+		return model.HealthReasonHeartbeatResumed
+	default:
+		return model.HealthReasonHeartbeatExpired
+	}
+}
+
+func (s *Server) healthHistoryStore() (store.InstanceHealthHistoryStore, bool) {
+	if s.hcOpt.HistorySize < 0 {
+		return nil, false
+	}
+	historyStore, ok := s.storage.(store.InstanceHealthHistoryStore)
+	return historyStore, ok
+}
+
+// recordHealthTransition 记录实例的一次健康状态变更
+func (s *Server) recordHealthTransition(instance *model.Instance, healthy bool, reason, detail string,
+	lastBeatSec int64) {
+	if _, ok := s.healthHistoryStore(); !ok {
+		return
+	}
+	record := &model.InstanceHealthTransition{
+		InstanceID:       instance.ID(),
+		Healthy:          healthy,
+		Reason:           reason,
+		Detail:           detail,
+		Server:           s.localHost,
+		LastHeartbeatSec: lastBeatSec,
+		Time:             time.Unix(s.currentTimeSec(), 0),
+	}
+	// 开启合并写入时由 batch 异步落库，避免健康检查协程等待存储
+	if s.bc != nil && s.bc.HealthHistoryOpen() {
+		if !s.bc.AsyncRecordHealthHistory(record) {
+			log.Warnf("[Health Check][History]batch queue is full, drop transition of instance %s", instance.ID())
+		}
+		return
+	}
+	s.storeHealthHistory([]*model.InstanceHealthTransition{record})
+}
+
+// storeHealthHistory 写入一批健康状态变更记录，写入失败只打印日志
+func (s *Server) storeHealthHistory(records []*model.InstanceHealthTransition) {
+	historyStore, ok := s.healthHistoryStore()
+	if !ok {
+		return
+	}
+	if err := historyStore.BatchAppendInstanceHealthHistory(records, s.hcOpt.HistorySize); err != nil {
+		log.Errorf("[Health Check][History]fail to record %d transitions, err is %v", len(records), err)
+	}
+}
+
+// deleteHealthHistory 实例被删除时清理健康状态变更记录
+func (s *Server) deleteHealthHistory(instanceID string) {
+	historyStore, ok := s.healthHistoryStore()
+	if !ok {
+		return
+	}
+	if err := historyStore.DeleteInstanceHealthHistory(instanceID); err != nil {
+		log.Errorf("[Health Check][History]fail to delete history of instance %s, err is %v", instanceID, err)
+	}
+}
+
+// GetInstanceHealthHistory 查询实例最近的健康状态变更记录，按照时间倒序排列，limit 不超过 historySize
+func (s *Server) GetInstanceHealthHistory(instanceID string, limit int) ([]*model.InstanceHealthTransition, error) {
+	historyStore, ok := s.healthHistoryStore()
+	if !ok {
+		return nil, ErrHealthHistoryDisabled
+	}
+	if limit <= 0 || limit > s.hcOpt.HistorySize {
+		limit = s.hcOpt.HistorySize
+	}
+	return historyStore.GetInstanceHealthHistory(instanceID, limit)
+}
@@ -107,7 +107,7 @@ func (handler *LeaderChangeEventHandler) stopCheckSelfServiceInstances() {
 
 // startCheckSelfServiceInstances
 func (handler *LeaderChangeEventHandler) doCheckSelfServiceInstance(cachedInstance *model.Instance) {
-	hcEnable, checker := handler.cacheProvider.isHealthCheckEnable(cachedInstance.Proto)
+	hcEnable, checker := handler.cacheProvider.isHealthCheckEnable(cachedInstance)
 	if !hcEnable {
 		log.Warnf("[Health Check][Check] selfService instance %s:%d not enable healthcheck",
 			cachedInstance.Host(), cachedInstance.Port())
@@ -124,12 +124,22 @@ func (handler *LeaderChangeEventHandler) doCheckSelfServiceInstance(cachedInstan
 		CurTimeSec:        handler.svr.currentTimeSec,
 		ExpireDurationSec: getExpireDurationSec(cachedInstance.Proto),
 	}
+	if checker.Type() == plugin.HealthCheckerProbe {
+		request.Metadata = handler.svr.probeMetadata(cachedInstance)
+	}
 	checkResp, err := checker.Check(request)
 	if err != nil {
 		log.Errorf("[Health Check][Check]fail to check selfService instance %s:%d, id is %s, err is %v",
 			cachedInstance.Host(), cachedInstance.Port(), cachedInstance.ID(), err)
 		return
 	}
+	if !checkResp.StayUnchanged && !checkResp.Healthy {
+		if suspension := handler.svr.matchSuspension(cachedInstance); suspension != nil {
+			log.Infof("[Health Check][Check]selfService instance unhealthy marking is suspended by %s, id is %s",
+				suspension.ID, cachedInstance.ID())
+			return
+		}
+	}
 	if !checkResp.StayUnchanged {
 		code := setInsDbStatus(handler.svr, cachedInstance, checkResp.Healthy, checkResp.LastHeartbeatTimeSec)
 		if checkResp.Healthy {
@@ -147,6 +157,9 @@ func (handler *LeaderChangeEventHandler) doCheckSelfServiceInstance(cachedInstan
 			log.Errorf(
 				"[Health Check][Check]fail to update selfService instance, id is %s, address is %s:%d, code is %d",
 				cachedInstance.ID(), cachedInstance.Host(), cachedInstance.Port(), code)
+			return
 		}
+		handler.svr.recordHealthTransition(cachedInstance, checkResp.Healthy,
+			transitionReason(checker.Type(), checkResp.Healthy), "", checkResp.LastHeartbeatTimeSec)
 	}
 }
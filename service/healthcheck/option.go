@@ -73,7 +73,9 @@ func withChecker() serverOption {
 				return fmt.Errorf("[healthcheck]duplicate healthchecker %s, checkType %d", entry.Name, checker.Type())
 			}
 			svr.checkers[int32(checker.Type())] = checker
-			if nil == svr.defaultChecker {
+			// 心跳上报以及心跳记录的查询优先使用心跳类型的检查插件
+			if nil == svr.defaultChecker || (checker.Type() == plugin.HealthCheckerHeartbeat &&
+				svr.defaultChecker.Type() != plugin.HealthCheckerHeartbeat) {
 				svr.defaultChecker = checker
 			}
 		}
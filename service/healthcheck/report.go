@@ -141,6 +141,9 @@ func (s *Server) baseReport(ctx context.Context, id string, reportReq *plugin.Re
 	count, ins, code := s.checkInstanceExists(ctx, id)
 	checker := s.getHealthChecker(id)
 	reportReq.Count = count + 1
+	if nil != ins {
+		reportReq.TtlSec = ins.HealthCheck().GetHeartbeat().GetTtl().GetValue()
+	}
 	err := checker.Report(ctx, reportReq)
 	if nil != ins {
 		event := &model.InstanceEvent{
@@ -58,6 +58,7 @@ type Server struct {
 	bc             *batch.Controller
 	serviceCache   cachetypes.ServiceCache
 	instanceCache  cachetypes.InstanceCache
+	suspensions    suspensionHolder
 
 	subCtxs []*eventhub.SubscribtionContext
 }
@@ -135,6 +136,13 @@ func (s *Server) run(ctx context.Context) error {
 
 	s.checkScheduler.run(ctx)
 	s.timeAdjuster.doTimeAdjust(ctx)
+	s.runSuspensionRefresher(ctx)
+	if s.bc != nil {
+		s.bc.StartReport(ctx, func(beats []*apiservice.InstanceHeartbeat) {
+			_ = s.doReports(context.Background(), beats)
+		})
+		s.bc.StartHealthHistory(ctx, s.storeHealthHistory)
+	}
 	s.dispatcher.startDispatchingJob(ctx)
 	return nil
 }
@@ -154,6 +162,37 @@ func (s *Server) Reports(ctx context.Context, req []*apiservice.InstanceHeartbea
 	return s.doReports(ctx, req)
 }
 
+// AsyncReport 异步上报心跳，开启合并时交给 batch 批量处理，不等待处理结果；
+// 返回 Code_InstanceTooManyRequests 表示合并队列已满，心跳被丢弃
+func (s *Server) AsyncReport(ctx context.Context, beat *apiservice.InstanceHeartbeat) apimodel.Code {
+	if !s.isOpen() {
+		return apimodel.Code_HealthCheckNotOpen
+	}
+	if s.bc == nil || !s.bc.ReportOpen() {
+		return apimodel.Code(s.doReports(ctx, []*apiservice.InstanceHeartbeat{beat}).GetCode().GetValue())
+	}
+	if !s.bc.AsyncReport(beat) {
+		return apimodel.Code_InstanceTooManyRequests
+	}
+	return apimodel.Code_ExecuteSuccess
+}
+
+// GetInstanceServiceToken 查询实例所属服务的 token，实例或者服务不存在时返回 false
+func (s *Server) GetInstanceServiceToken(instanceID string) (string, bool) {
+	if s.instanceCache == nil || s.serviceCache == nil {
+		return "", false
+	}
+	instance := s.instanceCache.GetInstance(instanceID)
+	if instance == nil {
+		return "", false
+	}
+	svc := s.serviceCache.GetServiceByID(instance.ServiceID)
+	if svc == nil {
+		return "", false
+	}
+	return svc.Token, true
+}
+
 // ReportByClient report heartbeat request by client
 func (s *Server) ReportByClient(ctx context.Context, req *apiservice.Client) *apiservice.Response {
 	return s.doReportByClient(ctx, req)
@@ -213,6 +252,8 @@ func (s *Server) RecordHistory(entry *model.RecordEntry) {
 	if entry == nil {
 		return
 	}
+	// 数据已经写入存储，通知缓存按需刷新
+	eventhub.PublishCacheChange(entry.ResourceType)
 
 	// 调用插件记录history
 	plugin.GetHistory().Record(entry)
@@ -241,7 +282,7 @@ func (s *Server) GetLastHeartbeat(req *apiservice.Instance) *apiservice.Response
 	if insCache == nil {
 		return api.NewInstanceResponse(apimodel.Code_NotFoundResource, req)
 	}
-	checker, ok := s.checkers[int32(insCache.HealthCheck().GetType())]
+	checker, ok := s.cacheProvider.GetInstanceChecker(id)
 	if !ok {
 		return api.NewInstanceResponse(apimodel.Code_HeartbeatTypeNotFound, req)
 	}
@@ -271,6 +312,44 @@ func (s *Server) Checkers() map[int32]plugin.HealthChecker {
 	return s.checkers
 }
 
+// probeType 实例的主动探测方式，实例元数据中的配置优先于服务元数据
+func (s *Server) probeType(instance *model.Instance) string {
+	return s.metadataValue(instance, model.MetaKeyHealthProbeType)
+}
+
+// metadataValue 查询健康检查相关的元数据配置，实例元数据中的配置优先于服务元数据
+func (s *Server) metadataValue(instance *model.Instance, key string) string {
+	if val := instance.Metadata()[key]; val != "" {
+		return val
+	}
+	if s.serviceCache == nil {
+		return ""
+	}
+	if svc := s.serviceCache.GetServiceByID(instance.ServiceID); svc != nil {
+		return svc.Meta[key]
+	}
+	return ""
+}
+
+// probeMetadata 合并服务和实例的元数据作为主动探测的参数，实例上的配置优先
+func (s *Server) probeMetadata(instance *model.Instance) map[string]string {
+	var svcMeta map[string]string
+	if s.serviceCache != nil {
+		if svc := s.serviceCache.GetServiceByID(instance.ServiceID); svc != nil {
+			svcMeta = svc.Meta
+		}
+	}
+	insMeta := instance.Metadata()
+	ret := make(map[string]string, len(svcMeta)+len(insMeta))
+	for k, v := range svcMeta {
+		ret[k] = v
+	}
+	for k, v := range insMeta {
+		ret[k] = v
+	}
+	return ret
+}
+
 func (s *Server) isOpen() bool {
 	return s.hcOpt.IsOpen()
 }
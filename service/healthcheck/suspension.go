@@ -0,0 +1,204 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+var (
+	// ErrSuspensionNotSupport 存储插件没有实现 store.HealthCheckSuspensionStore
+	ErrSuspensionNotSupport = errors.New("store not support health check suspension")
+	// ErrSuspensionNotFound 暂停规则不存在或者已经过期
+	ErrSuspensionNotFound = errors.New("health check suspension not found")
+)
+
+// suspensionHolder 当前生效的暂停规则，定期从存储层同步，保证在所有节点上生效
+type suspensionHolder struct {
+	suspensions atomic.Value
+}
+
+func (h *suspensionHolder) load() []*model.HealthCheckSuspension {
+	val := h.suspensions.Load()
+	if val == nil {
+		return nil
+	}
+	return val.([]*model.HealthCheckSuspension)
+}
+
+func (h *suspensionHolder) store(suspensions []*model.HealthCheckSuspension) {
+	h.suspensions.Store(suspensions)
+}
+
+func (s *Server) suspensionStore() (store.HealthCheckSuspensionStore, error) {
+	suspensionStore, ok := s.storage.(store.HealthCheckSuspensionStore)
+	if !ok {
+		return nil, ErrSuspensionNotSupport
+	}
+	return suspensionStore, nil
+}
+
+// CreateSuspension 创建暂停规则，TTL 到期之前范围内的实例不会被置为不健康
+func (s *Server) CreateSuspension(suspension *model.HealthCheckSuspension) (*model.HealthCheckSuspension, error) {
+	suspensionStore, err := s.suspensionStore()
+	if err != nil {
+		return nil, err
+	}
+	if err := suspension.Selector.Validate(); err != nil {
+		return nil, err
+	}
+	ttl, err := time.ParseDuration(suspension.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ttl %q: %w", suspension.TTL, err)
+	}
+	if ttl <= 0 || ttl > s.hcOpt.MaxSuspendTtl {
+		return nil, fmt.Errorf("ttl must be in (0, %s]", s.hcOpt.MaxSuspendTtl)
+	}
+	suspension.ID = utils.NewUUID()
+	suspension.CreateTime = time.Unix(s.currentTimeSec(), 0)
+	suspension.ExpireTime = suspension.CreateTime.Add(ttl)
+	suspension.TTL = ""
+	if err := suspensionStore.AddHealthCheckSuspension(suspension); err != nil {
+		return nil, err
+	}
+	log.Infof("[Health Check][Suspend]create suspension %s, selector is %s, expire at %s", suspension.ID,
+		utils.MustJson(suspension.Selector), suspension.ExpireTime.Format(time.RFC3339))
+	s.refreshSuspensions()
+	return suspension, nil
+}
+
+// ListSuspensions 查询还没有过期的暂停规则，按照创建时间排序
+func (s *Server) ListSuspensions() ([]*model.HealthCheckSuspension, error) {
+	suspensionStore, err := s.suspensionStore()
+	if err != nil {
+		return nil, err
+	}
+	suspensions, err := suspensionStore.GetHealthCheckSuspensions()
+	if err != nil {
+		return nil, err
+	}
+	valid, _ := s.splitExpiredSuspensions(suspensions)
+	sort.Slice(valid, func(i, j int) bool {
+		return valid[i].CreateTime.Before(valid[j].CreateTime)
+	})
+	return valid, nil
+}
+
+// CancelSuspension 提前取消暂停规则
+func (s *Server) CancelSuspension(id string) error {
+	suspensionStore, err := s.suspensionStore()
+	if err != nil {
+		return err
+	}
+	suspensions, err := s.ListSuspensions()
+	if err != nil {
+		return err
+	}
+	var target *model.HealthCheckSuspension
+	for i := range suspensions {
+		if suspensions[i].ID == id {
+			target = suspensions[i]
+			break
+		}
+	}
+	if target == nil {
+		return ErrSuspensionNotFound
+	}
+	if err := suspensionStore.DeleteHealthCheckSuspension(id); err != nil {
+		return err
+	}
+	log.Infof("[Health Check][Suspend]cancel suspension %s", id)
+	s.refreshSuspensions()
+	return nil
+}
+
+// matchSuspension 查询实例命中的暂停规则，没有命中时返回 nil
+func (s *Server) matchSuspension(instance *model.Instance) *model.HealthCheckSuspension {
+	curTimeSec := s.currentTimeSec()
+	for _, suspension := range s.suspensions.load() {
+		if suspension.ExpireTime.Unix() > curTimeSec && suspension.Selector.Match(instance) {
+			return suspension
+		}
+	}
+	return nil
+}
+
+func (s *Server) splitExpiredSuspensions(
+	suspensions []*model.HealthCheckSuspension) ([]*model.HealthCheckSuspension, []*model.HealthCheckSuspension) {
+	curTimeSec := s.currentTimeSec()
+	valid := make([]*model.HealthCheckSuspension, 0, len(suspensions))
+	var expired []*model.HealthCheckSuspension
+	for _, suspension := range suspensions {
+		if suspension.ExpireTime.Unix() > curTimeSec {
+			valid = append(valid, suspension)
+		} else {
+			expired = append(expired, suspension)
+		}
+	}
+	return valid, expired
+}
+
+// refreshSuspensions 从存储层加载暂停规则，同时清理已经过期的规则
+func (s *Server) refreshSuspensions() {
+	suspensionStore, err := s.suspensionStore()
+	if err != nil {
+		return
+	}
+	suspensions, err := suspensionStore.GetHealthCheckSuspensions()
+	if err != nil {
+		log.Errorf("[Health Check][Suspend]fail to load suspensions, err is %v", err)
+		return
+	}
+	valid, expired := s.splitExpiredSuspensions(suspensions)
+	s.suspensions.store(valid)
+	for _, suspension := range expired {
+		log.Infof("[Health Check][Suspend]suspension %s expired", suspension.ID)
+		if err := suspensionStore.DeleteHealthCheckSuspension(suspension.ID); err != nil {
+			log.Errorf("[Health Check][Suspend]fail to delete expired suspension %s, err is %v", suspension.ID, err)
+		}
+	}
+}
+
+func (s *Server) runSuspensionRefresher(ctx context.Context) {
+	if _, err := s.suspensionStore(); err != nil {
+		log.Warnf("[Health Check][Suspend]scoped suspension is disabled, %v", err)
+		return
+	}
+	s.refreshSuspensions()
+	go func() {
+		ticker := time.NewTicker(s.hcOpt.SuspendRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.refreshSuspensions()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
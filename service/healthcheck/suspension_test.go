@@ -0,0 +1,121 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"testing"
+	"time"
+
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+type fakeSuspensionStore struct {
+	store.Store
+	suspensions map[string]*model.HealthCheckSuspension
+}
+
+func (f *fakeSuspensionStore) AddHealthCheckSuspension(suspension *model.HealthCheckSuspension) error {
+	f.suspensions[suspension.ID] = suspension
+	return nil
+}
+
+func (f *fakeSuspensionStore) DeleteHealthCheckSuspension(id string) error {
+	delete(f.suspensions, id)
+	return nil
+}
+
+func (f *fakeSuspensionStore) GetHealthCheckSuspensions() ([]*model.HealthCheckSuspension, error) {
+	ret := make([]*model.HealthCheckSuspension, 0, len(f.suspensions))
+	for _, suspension := range f.suspensions {
+		ret = append(ret, suspension)
+	}
+	return ret, nil
+}
+
+func mockSuspensionInstance(namespace, service, zone string) *model.Instance {
+	return &model.Instance{
+		Proto: &apiservice.Instance{
+			Namespace: utils.NewStringValue(namespace),
+			Service:   utils.NewStringValue(service),
+			Location:  &apimodel.Location{Zone: utils.NewStringValue(zone)},
+		},
+	}
+}
+
+func TestServer_Suspension(t *testing.T) {
+	storage := &fakeSuspensionStore{suspensions: map[string]*model.HealthCheckSuspension{}}
+	hcOpt := &Config{}
+	hcOpt.SetDefault()
+	svr := &Server{hcOpt: hcOpt, storage: storage, timeAdjuster: &TimeAdjuster{}}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := svr.CreateSuspension(&model.HealthCheckSuspension{TTL: "10m"})
+		assert.Error(t, err)
+		_, err = svr.CreateSuspension(&model.HealthCheckSuspension{
+			Selector: &model.HealthCheckSuspensionSelector{Service: "svc"},
+			TTL:      "10m",
+		})
+		assert.Error(t, err)
+		_, err = svr.CreateSuspension(&model.HealthCheckSuspension{
+			Selector: &model.HealthCheckSuspensionSelector{Zone: "zone-1"},
+			TTL:      "48h",
+		})
+		assert.Error(t, err)
+		assert.Empty(t, storage.suspensions)
+	})
+
+	t.Run("match_and_cancel", func(t *testing.T) {
+		suspension, err := svr.CreateSuspension(&model.HealthCheckSuspension{
+			Selector: &model.HealthCheckSuspensionSelector{Namespace: "default", Zone: "zone-1"},
+			TTL:      "10m",
+		})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, suspension.ID)
+
+		assert.NotNil(t, svr.matchSuspension(mockSuspensionInstance("default", "svc", "zone-1")))
+		assert.Nil(t, svr.matchSuspension(mockSuspensionInstance("default", "svc", "zone-2")))
+		assert.Nil(t, svr.matchSuspension(mockSuspensionInstance("test", "svc", "zone-1")))
+
+		suspensions, err := svr.ListSuspensions()
+		assert.NoError(t, err)
+		assert.Len(t, suspensions, 1)
+
+		assert.NoError(t, svr.CancelSuspension(suspension.ID))
+		assert.ErrorIs(t, svr.CancelSuspension(suspension.ID), ErrSuspensionNotFound)
+		assert.Nil(t, svr.matchSuspension(mockSuspensionInstance("default", "svc", "zone-1")))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		tN := time.Now()
+		storage.suspensions["expired"] = &model.HealthCheckSuspension{
+			ID:         "expired",
+			Selector:   &model.HealthCheckSuspensionSelector{Namespace: "default"},
+			CreateTime: tN.Add(-time.Hour),
+			ExpireTime: tN.Add(-time.Minute),
+		}
+		svr.refreshSuspensions()
+		assert.Nil(t, svr.matchSuspension(mockSuspensionInstance("default", "svc", "zone-1")))
+		assert.Empty(t, storage.suspensions)
+	})
+}
@@ -104,6 +104,10 @@ func (s *Server) SetResourceHooks(hooks ...ResourceHook) {
 
 // RecordHistory server对外提供history插件的简单封装
 func (s *Server) RecordHistory(ctx context.Context, entry *model.RecordEntry) {
+	// 数据已经写入存储，通知缓存按需刷新
+	if entry != nil {
+		eventhub.PublishCacheChange(entry.ResourceType)
+	}
 	// 如果插件没有初始化，那么不记录history
 	if s.history == nil {
 		return
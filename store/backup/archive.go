@@ -0,0 +1,238 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// FormatVersion 归档文件的格式版本，格式发生不兼容变化时递增
+	FormatVersion = 1
+	// manifestFile 归档文件中清单的文件名
+	manifestFile = "manifest.json"
+	// resourceFileSuffix 资源文件后缀，每行是一条 specification 中定义的 protobuf 消息的 JSON
+	resourceFileSuffix = ".jsonl"
+)
+
+var (
+	// ErrManifestNotFound 归档文件中没有清单，通常是归档文件不完整
+	ErrManifestNotFound = errors.New("manifest not found in backup archive, the archive may be truncated")
+)
+
+// Manifest 归档文件清单
+type Manifest struct {
+	// FormatVersion 归档文件的格式版本
+	FormatVersion int `json:"format_version"`
+	// ServerVersion 生成备份的服务端版本
+	ServerVersion string `json:"server_version"`
+	// Store 数据来源的存储插件
+	Store string `json:"store"`
+	// CreateTime 备份时间
+	CreateTime time.Time `json:"create_time"`
+	// Namespaces 备份时指定的命名空间，为空表示全量备份
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Resources 归档文件中包含的资源
+	Resources []*ManifestEntry `json:"resources"`
+}
+
+// ManifestEntry 单类资源在归档文件中的信息
+type ManifestEntry struct {
+	// Name 资源名称
+	Name string `json:"name"`
+	// File 资源在归档文件中的文件名
+	File string `json:"file"`
+	// Type 记录对应的 protobuf 消息类型
+	Type string `json:"type"`
+	// Count 记录数
+	Count int `json:"count"`
+	// Sha256 文件内容摘要
+	Sha256 string `json:"sha256"`
+}
+
+// archiveWriter 将资源逐个写入 tar.gz 归档文件
+type archiveWriter struct {
+	gw *gzip.Writer
+	tw *tar.Writer
+}
+
+func newArchiveWriter(w io.Writer) *archiveWriter {
+	gw := gzip.NewWriter(w)
+	return &archiveWriter{
+		gw: gw,
+		tw: tar.NewWriter(gw),
+	}
+}
+
+func (a *archiveWriter) writeResource(res Resource, items []proto.Message) (*ManifestEntry, error) {
+	buf := bytes.NewBuffer(nil)
+	for _, item := range items {
+		data, err := protojson.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	entry := &ManifestEntry{
+		Name:   res.Name(),
+		File:   res.Name() + resourceFileSuffix,
+		Type:   string(res.newSpec().ProtoReflect().Descriptor().FullName()),
+		Count:  len(items),
+		Sha256: digest(buf.Bytes()),
+	}
+	if err := a.writeFile(entry.File, buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (a *archiveWriter) writeManifest(manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return a.writeFile(manifestFile, data)
+}
+
+func (a *archiveWriter) writeFile(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := a.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := a.tw.Write(data)
+	return err
+}
+
+// Close 写入归档文件的结尾
+func (a *archiveWriter) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.gw.Close()
+}
+
+// archive 读取并校验过的归档文件，资源名称 -> 文件内容
+type archive struct {
+	manifest *Manifest
+	files    map[string][]byte
+}
+
+// readArchive 读取整个归档文件，并根据清单校验格式版本以及每个资源文件的摘要
+func readArchive(r io.Reader) (*archive, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup archive: %w", err)
+	}
+	defer func() {
+		_ = gr.Close()
+	}()
+
+	contents := map[string][]byte{}
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup archive: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s from backup archive: %w", header.Name, err)
+		}
+		contents[header.Name] = data
+	}
+
+	data, ok := contents[manifestFile]
+	if !ok {
+		return nil, ErrManifestNotFound
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported backup format version %d, expect %d",
+			manifest.FormatVersion, FormatVersion)
+	}
+
+	ret := &archive{
+		manifest: manifest,
+		files:    make(map[string][]byte, len(manifest.Resources)),
+	}
+	for _, entry := range manifest.Resources {
+		data, ok := contents[entry.File]
+		if !ok {
+			return nil, fmt.Errorf("file %s of %s not found in backup archive", entry.File, entry.Name)
+		}
+		if digest(data) != entry.Sha256 {
+			return nil, fmt.Errorf("checksum of %s mismatch", entry.File)
+		}
+		ret.files[entry.Name] = data
+	}
+	return ret, nil
+}
+
+// decodeResource 逐行解析资源文件
+func decodeResource(res Resource, data []byte) ([]proto.Message, error) {
+	items := make([]proto.Message, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	// 单条记录（例如配置文件）可能较大
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		item := res.newSpec()
+		if err := protojson.Unmarshal([]byte(text), item); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func digest(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,445 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package backup
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	apisecurity "github.com/polarismesh/specification/source/go/api/v1/security"
+
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/dataset"
+)
+
+const (
+	userResourceName      = "user"
+	userGroupResourceName = "user_group"
+)
+
+// strategyResourceNames 鉴权策略中引用的资源类型 -> 备份中对应的资源名称，用于转换冲突记录的 ID
+var strategyResourceNames = map[apisecurity.ResourceType]string{
+	apisecurity.ResourceType_Services:     "service",
+	apisecurity.ResourceType_ConfigGroups: "config_file_group",
+	apisecurity.ResourceType_LaneRules:    "lane_group",
+	apisecurity.ResourceType_Users:        userResourceName,
+	apisecurity.ResourceType_UserGroups:   userGroupResourceName,
+}
+
+func userResource() Resource {
+	return &resource[*authcommon.User, *apisecurity.User]{
+		kind: dataset.Users(),
+		spec: func() *apisecurity.User { return &apisecurity.User{} },
+		id:   func(item *authcommon.User) string { return item.ID },
+		toSpec: func(_ *dumpContext, item *authcommon.User) (*apisecurity.User, error) {
+			spec := item.ToSpec()
+			// 密码为加密后的摘要，连同 token 一起原样备份
+			spec.Mobile = utils.NewStringValue(item.Mobile)
+			spec.Email = utils.NewStringValue(item.Email)
+			spec.Metadata = item.Metadata
+			spec.Ctime = utils.NewStringValue(formatTime(item.CreateTime))
+			spec.Mtime = utils.NewStringValue(formatTime(item.ModifyTime))
+			return spec, nil
+		},
+		fromSpec: func(ctx *loadContext, spec *apisecurity.User) (*authcommon.User, error) {
+			userType, err := strconv.Atoi(spec.GetUserType().GetValue())
+			if err != nil {
+				return nil, err
+			}
+			return &authcommon.User{
+				ID:          spec.GetId().GetValue(),
+				Name:        spec.GetName().GetValue(),
+				Password:    spec.GetPassword().GetValue(),
+				Owner:       ctx.targetID(userResourceName, spec.GetOwner().GetValue()),
+				Source:      spec.GetSource().GetValue(),
+				Mobile:      spec.GetMobile().GetValue(),
+				Email:       spec.GetEmail().GetValue(),
+				Type:        authcommon.UserRoleType(userType),
+				Metadata:    spec.GetMetadata(),
+				Token:       spec.GetAuthToken().GetValue(),
+				TokenEnable: spec.GetTokenEnable().GetValue(),
+				Valid:       true,
+				Comment:     spec.GetComment().GetValue(),
+				CreateTime:  parseTime(spec.GetCtime().GetValue()),
+				ModifyTime:  parseTime(spec.GetMtime().GetValue()),
+			}, nil
+		},
+		update: func(s store.Store, item, old *authcommon.User) error {
+			item.ID = old.ID
+			return s.UpdateUser(item)
+		},
+	}
+}
+
+func userGroupResource() Resource {
+	return &resource[*authcommon.UserGroupDetail, *apisecurity.UserGroup]{
+		kind: dataset.UserGroups(),
+		spec: func() *apisecurity.UserGroup { return &apisecurity.UserGroup{} },
+		id:   func(item *authcommon.UserGroupDetail) string { return item.ID },
+		toSpec: func(_ *dumpContext, item *authcommon.UserGroupDetail) (*apisecurity.UserGroup, error) {
+			userIDs := item.ToUserIdSlice()
+			sort.Strings(userIDs)
+			users := make([]*apisecurity.User, 0, len(userIDs))
+			for _, id := range userIDs {
+				users = append(users, &apisecurity.User{Id: utils.NewStringValue(id)})
+			}
+			return &apisecurity.UserGroup{
+				Id:          utils.NewStringValue(item.ID),
+				Name:        utils.NewStringValue(item.Name),
+				Owner:       utils.NewStringValue(item.Owner),
+				AuthToken:   utils.NewStringValue(item.Token),
+				TokenEnable: utils.NewBoolValue(item.TokenEnable),
+				Comment:     utils.NewStringValue(item.Comment),
+				Source:      utils.NewStringValue(item.Source),
+				Metadata:    item.Metadata,
+				Ctime:       utils.NewStringValue(formatTime(item.CreateTime)),
+				Mtime:       utils.NewStringValue(formatTime(item.ModifyTime)),
+				Relation: &apisecurity.UserGroupRelation{
+					GroupId: utils.NewStringValue(item.ID),
+					Users:   users,
+				},
+			}, nil
+		},
+		fromSpec: func(ctx *loadContext, spec *apisecurity.UserGroup) (*authcommon.UserGroupDetail, error) {
+			userIDs := make(map[string]struct{}, len(spec.GetRelation().GetUsers()))
+			for _, user := range spec.GetRelation().GetUsers() {
+				userIDs[ctx.targetID(userResourceName, user.GetId().GetValue())] = struct{}{}
+			}
+			return &authcommon.UserGroupDetail{
+				UserGroup: &authcommon.UserGroup{
+					ID:          spec.GetId().GetValue(),
+					Name:        spec.GetName().GetValue(),
+					Owner:       ctx.targetID(userResourceName, spec.GetOwner().GetValue()),
+					Token:       spec.GetAuthToken().GetValue(),
+					TokenEnable: spec.GetTokenEnable().GetValue(),
+					Metadata:    spec.GetMetadata(),
+					Valid:       true,
+					Comment:     spec.GetComment().GetValue(),
+					Source:      spec.GetSource().GetValue(),
+					CreateTime:  parseTime(spec.GetCtime().GetValue()),
+					ModifyTime:  parseTime(spec.GetMtime().GetValue()),
+				},
+				UserIds: userIDs,
+			}, nil
+		},
+		update: func(s store.Store, item, old *authcommon.UserGroupDetail) error {
+			modify := &authcommon.ModifyUserGroup{
+				ID:          old.ID,
+				Owner:       old.Owner,
+				Token:       item.Token,
+				TokenEnable: item.TokenEnable,
+				Comment:     item.Comment,
+				Metadata:    item.Metadata,
+			}
+			for id := range item.UserIds {
+				if _, ok := old.UserIds[id]; !ok {
+					modify.AddUserIds = append(modify.AddUserIds, id)
+				}
+			}
+			for id := range old.UserIds {
+				if _, ok := item.UserIds[id]; !ok {
+					modify.RemoveUserIds = append(modify.RemoveUserIds, id)
+				}
+			}
+			return s.UpdateGroup(modify)
+		},
+	}
+}
+
+func strategyResource() Resource {
+	return &resource[*authcommon.StrategyDetail, *apisecurity.AuthStrategy]{
+		kind: dataset.Strategies(),
+		spec: func() *apisecurity.AuthStrategy { return &apisecurity.AuthStrategy{} },
+		toSpec: func(_ *dumpContext, item *authcommon.StrategyDetail) (*apisecurity.AuthStrategy, error) {
+			spec := &apisecurity.AuthStrategy{
+				Id:              utils.NewStringValue(item.ID),
+				Name:            utils.NewStringValue(item.Name),
+				Principals:      &apisecurity.Principals{},
+				Resources:       &apisecurity.StrategyResources{StrategyId: utils.NewStringValue(item.ID)},
+				Action:          apisecurity.AuthAction(apisecurity.AuthAction_value[item.Action]),
+				Comment:         utils.NewStringValue(item.Comment),
+				Owner:           utils.NewStringValue(item.Owner),
+				Ctime:           utils.NewStringValue(formatTime(item.CreateTime)),
+				Mtime:           utils.NewStringValue(formatTime(item.ModifyTime)),
+				DefaultStrategy: utils.NewBoolValue(item.Default),
+				Metadata:        item.Metadata,
+				Source:          utils.NewStringValue(item.Source),
+				Functions:       item.CalleeMethods,
+			}
+			for _, principal := range item.Principals {
+				entry := &apisecurity.Principal{
+					Id:   utils.NewStringValue(principal.PrincipalID),
+					Name: utils.NewStringValue(principal.Name),
+				}
+				switch principal.PrincipalType {
+				case authcommon.PrincipalUser:
+					spec.Principals.Users = append(spec.Principals.Users, entry)
+				case authcommon.PrincipalGroup:
+					spec.Principals.Groups = append(spec.Principals.Groups, entry)
+				case authcommon.PrincipalRole:
+					spec.Principals.Roles = append(spec.Principals.Roles, entry)
+				}
+			}
+			for _, res := range item.Resources {
+				entries := strategyResourceEntries(spec.Resources, apisecurity.ResourceType(res.ResType))
+				if entries == nil {
+					continue
+				}
+				*entries = append(*entries, &apisecurity.StrategyResourceEntry{Id: utils.NewStringValue(res.ResID)})
+			}
+			for _, condition := range item.Conditions {
+				spec.ResourceLabels = append(spec.ResourceLabels, &apisecurity.StrategyResourceLabel{
+					Key:         condition.Key,
+					Value:       condition.Value,
+					CompareType: condition.CompareFunc,
+				})
+			}
+			return spec, nil
+		},
+		fromSpec: func(ctx *loadContext, spec *apisecurity.AuthStrategy) (*authcommon.StrategyDetail, error) {
+			item := &authcommon.StrategyDetail{
+				ID:            spec.GetId().GetValue(),
+				Name:          spec.GetName().GetValue(),
+				Action:        spec.GetAction().String(),
+				Comment:       spec.GetComment().GetValue(),
+				Default:       spec.GetDefaultStrategy().GetValue(),
+				Owner:         ctx.targetID(userResourceName, spec.GetOwner().GetValue()),
+				Source:        spec.GetSource().GetValue(),
+				CalleeMethods: spec.GetFunctions(),
+				Valid:         true,
+				Revision:      utils.NewUUID(),
+				Metadata:      spec.GetMetadata(),
+				CreateTime:    parseTime(spec.GetCtime().GetValue()),
+				ModifyTime:    parseTime(spec.GetMtime().GetValue()),
+			}
+			principals := []struct {
+				principalType authcommon.PrincipalType
+				resource      string
+				entries       []*apisecurity.Principal
+			}{
+				{authcommon.PrincipalUser, userResourceName, spec.GetPrincipals().GetUsers()},
+				{authcommon.PrincipalGroup, userGroupResourceName, spec.GetPrincipals().GetGroups()},
+				{authcommon.PrincipalRole, "", spec.GetPrincipals().GetRoles()},
+			}
+			for _, principals := range principals {
+				for _, entry := range principals.entries {
+					item.Principals = append(item.Principals, authcommon.Principal{
+						StrategyID:    item.ID,
+						Name:          entry.GetName().GetValue(),
+						Owner:         item.Owner,
+						PrincipalID:   ctx.targetID(principals.resource, entry.GetId().GetValue()),
+						PrincipalType: principals.principalType,
+					})
+				}
+			}
+			for resType := range apisecurity.ResourceType_name {
+				entries := strategyResourceEntries(spec.GetResources(), apisecurity.ResourceType(resType))
+				if entries == nil {
+					continue
+				}
+				for _, entry := range *entries {
+					item.Resources = append(item.Resources, authcommon.StrategyResource{
+						StrategyID: item.ID,
+						ResType:    resType,
+						ResID: ctx.targetID(strategyResourceNames[apisecurity.ResourceType(resType)],
+							entry.GetId().GetValue()),
+					})
+				}
+			}
+			sort.Slice(item.Resources, func(i, j int) bool {
+				if item.Resources[i].ResType != item.Resources[j].ResType {
+					return item.Resources[i].ResType < item.Resources[j].ResType
+				}
+				return item.Resources[i].ResID < item.Resources[j].ResID
+			})
+			for _, label := range spec.GetResourceLabels() {
+				item.Conditions = append(item.Conditions, authcommon.Condition{
+					Key:         label.GetKey(),
+					Value:       label.GetValue(),
+					CompareFunc: label.GetCompareType(),
+				})
+			}
+			return item, nil
+		},
+		update: func(s store.Store, item, old *authcommon.StrategyDetail) error {
+			modify := &authcommon.ModifyStrategyDetail{
+				ID:            old.ID,
+				Name:          old.Name,
+				Action:        item.Action,
+				Comment:       item.Comment,
+				Metadata:      item.Metadata,
+				CalleeMethods: item.CalleeMethods,
+				Conditions:    item.Conditions,
+				ModifyTime:    time.Now(),
+			}
+			modify.AddPrincipals, modify.RemovePrincipals = diffPrincipals(old.ID, item.Principals, old.Principals)
+			modify.AddResources, modify.RemoveResources = diffResources(old.ID, item.Resources, old.Resources)
+			return s.UpdateStrategy(modify)
+		},
+	}
+}
+
+func roleResource() Resource {
+	return &resource[*authcommon.Role, *apisecurity.Role]{
+		kind: dataset.Roles(),
+		spec: func() *apisecurity.Role { return &apisecurity.Role{} },
+		toSpec: func(_ *dumpContext, item *authcommon.Role) (*apisecurity.Role, error) {
+			spec := &apisecurity.Role{
+				Id:       item.ID,
+				Name:     item.Name,
+				Owner:    item.Owner,
+				Source:   item.Source,
+				Metadata: item.Metadata,
+				Comment:  item.Comment,
+				Ctime:    formatTime(item.CreateTime),
+				Mtime:    formatTime(item.ModifyTime),
+			}
+			for _, user := range item.Users {
+				spec.Users = append(spec.Users, &apisecurity.User{Id: utils.NewStringValue(user.PrincipalID)})
+			}
+			for _, group := range item.UserGroups {
+				spec.UserGroups = append(spec.UserGroups,
+					&apisecurity.UserGroup{Id: utils.NewStringValue(group.PrincipalID)})
+			}
+			sort.Slice(spec.Users, func(i, j int) bool {
+				return spec.Users[i].GetId().GetValue() < spec.Users[j].GetId().GetValue()
+			})
+			sort.Slice(spec.UserGroups, func(i, j int) bool {
+				return spec.UserGroups[i].GetId().GetValue() < spec.UserGroups[j].GetId().GetValue()
+			})
+			return spec, nil
+		},
+		fromSpec: func(ctx *loadContext, spec *apisecurity.Role) (*authcommon.Role, error) {
+			item := &authcommon.Role{
+				ID:         spec.GetId(),
+				Name:       spec.GetName(),
+				Owner:      ctx.targetID(userResourceName, spec.GetOwner()),
+				Source:     spec.GetSource(),
+				Metadata:   spec.GetMetadata(),
+				Comment:    spec.GetComment(),
+				Valid:      true,
+				CreateTime: parseTime(spec.GetCtime()),
+				ModifyTime: parseTime(spec.GetMtime()),
+			}
+			// 成员引用的用户、用户组在目标端的 ID 可能与备份不同
+			for _, user := range spec.GetUsers() {
+				item.Users = append(item.Users, authcommon.Principal{
+					PrincipalID:   ctx.targetID(userResourceName, user.GetId().GetValue()),
+					PrincipalType: authcommon.PrincipalUser,
+				})
+			}
+			for _, group := range spec.GetUserGroups() {
+				item.UserGroups = append(item.UserGroups, authcommon.Principal{
+					PrincipalID:   ctx.targetID(userGroupResourceName, group.GetId().GetValue()),
+					PrincipalType: authcommon.PrincipalGroup,
+				})
+			}
+			return item, nil
+		},
+		update: func(s store.Store, item, old *authcommon.Role) error {
+			item.ID = old.ID
+			return s.UpdateRole(item)
+		},
+	}
+}
+
+// strategyResourceEntries 返回鉴权策略中对应资源类型的资源列表
+func strategyResourceEntries(resources *apisecurity.StrategyResources,
+	resType apisecurity.ResourceType) *[]*apisecurity.StrategyResourceEntry {
+	if resources == nil {
+		return nil
+	}
+	switch resType {
+	case apisecurity.ResourceType_Namespaces:
+		return &resources.Namespaces
+	case apisecurity.ResourceType_Services:
+		return &resources.Services
+	case apisecurity.ResourceType_ConfigGroups:
+		return &resources.ConfigGroups
+	case apisecurity.ResourceType_RouteRules:
+		return &resources.RouteRules
+	case apisecurity.ResourceType_RateLimitRules:
+		return &resources.RatelimitRules
+	case apisecurity.ResourceType_CircuitBreakerRules:
+		return &resources.CircuitbreakerRules
+	case apisecurity.ResourceType_FaultDetectRules:
+		return &resources.FaultdetectRules
+	case apisecurity.ResourceType_LaneRules:
+		return &resources.LaneRules
+	case apisecurity.ResourceType_Users:
+		return &resources.Users
+	case apisecurity.ResourceType_UserGroups:
+		return &resources.UserGroups
+	case apisecurity.ResourceType_Roles:
+		return &resources.Roles
+	case apisecurity.ResourceType_PolicyRules:
+		return &resources.AuthPolicies
+	default:
+		return nil
+	}
+}
+
+// diffPrincipals 计算覆盖鉴权策略 strategyID 时需要新增、删除的成员
+func diffPrincipals(strategyID string, expect, actual []authcommon.Principal) ([]authcommon.Principal,
+	[]authcommon.Principal) {
+	key := func(p authcommon.Principal) string {
+		return strconv.Itoa(int(p.PrincipalType)) + "/" + p.PrincipalID
+	}
+	return diff(expect, actual, key, func(p authcommon.Principal) authcommon.Principal {
+		p.StrategyID = strategyID
+		return p
+	})
+}
+
+// diffResources 计算覆盖鉴权策略 strategyID 时需要新增、删除的资源
+func diffResources(strategyID string, expect, actual []authcommon.StrategyResource) ([]authcommon.StrategyResource,
+	[]authcommon.StrategyResource) {
+	key := func(r authcommon.StrategyResource) string {
+		return strconv.Itoa(int(r.ResType)) + "/" + r.ResID
+	}
+	return diff(expect, actual, key, func(r authcommon.StrategyResource) authcommon.StrategyResource {
+		r.StrategyID = strategyID
+		return r
+	})
+}
+
+func diff[T any](expect, actual []T, key func(T) string, bind func(T) T) ([]T, []T) {
+	expectKeys := make(map[string]struct{}, len(expect))
+	for _, item := range expect {
+		expectKeys[key(item)] = struct{}{}
+	}
+	actualKeys := make(map[string]struct{}, len(actual))
+	for _, item := range actual {
+		actualKeys[key(item)] = struct{}{}
+	}
+	var added, removed []T
+	for _, item := range expect {
+		if _, ok := actualKeys[key(item)]; !ok {
+			added = append(added, bind(item))
+		}
+	}
+	for _, item := range actual {
+		if _, ok := expectKeys[key(item)]; !ok {
+			removed = append(removed, bind(item))
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,211 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package backup
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/polarismesh/polaris/common/version"
+	"github.com/polarismesh/polaris/store"
+)
+
+// ConflictPolicy 恢复时目标端已存在同名记录的处理策略
+type ConflictPolicy string
+
+const (
+	// ConflictSkip 保留目标端的记录
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite 使用备份中的记录覆盖目标端的记录
+	ConflictOverwrite ConflictPolicy = "overwrite"
+)
+
+// ParseConflictPolicy 解析冲突处理策略，为空时默认跳过
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch ConflictPolicy(s) {
+	case "", ConflictSkip:
+		return ConflictSkip, nil
+	case ConflictOverwrite:
+		return ConflictOverwrite, nil
+	default:
+		return "", fmt.Errorf("invalid conflict policy %q, must be %s or %s", s, ConflictSkip, ConflictOverwrite)
+	}
+}
+
+// BackupOption 备份参数
+type BackupOption struct {
+	// Namespaces 只备份这些命名空间下的资源，为空表示备份全部数据
+	Namespaces []string
+}
+
+// RestoreOption 恢复参数
+type RestoreOption struct {
+	// Namespaces 只恢复这些命名空间下的资源，为空表示恢复备份中的全部数据
+	Namespaces []string
+	// Conflict 目标端已存在同名记录时的处理策略
+	Conflict ConflictPolicy
+	// RestoreTimestamps 是否回写备份中记录的创建、修改时间，仅在存储支持 store.MigrateStore 时生效。
+	// 回写后记录的修改时间早于缓存的增量拉取时间，因此只适用于服务端停止时的离线恢复
+	RestoreTimestamps bool
+}
+
+// Backup 在同一个快照读视图中导出存储中的数据，并以归档文件的形式写入 w
+func Backup(s store.Store, w io.Writer, opt *BackupOption) (*Manifest, error) {
+	return backup(s, w, opt, DefaultResources())
+}
+
+func backup(s store.Store, w io.Writer, opt *BackupOption, resources []Resource) (*Manifest, error) {
+	if opt == nil {
+		opt = &BackupOption{}
+	}
+	viewer, ok := s.(store.ReadViewStore)
+	if !ok {
+		return nil, fmt.Errorf("store %s does not support consistent read view", s.Name())
+	}
+	tx, err := s.StartReadTx()
+	if err != nil {
+		return nil, fmt.Errorf("start read tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if err := tx.CreateReadView(); err != nil {
+		return nil, fmt.Errorf("create read view: %w", err)
+	}
+
+	// 全部资源都通过同一个只读事务读取，保证导出的数据来自同一个快照
+	view, err := viewer.ReadView(tx)
+	if err != nil {
+		return nil, fmt.Errorf("create read view: %w", err)
+	}
+	ctx := newDumpContext(view, opt.Namespaces)
+	manifest := &Manifest{
+		FormatVersion: FormatVersion,
+		ServerVersion: version.Get(),
+		Store:         s.Name(),
+		CreateTime:    time.Now(),
+		Namespaces:    opt.Namespaces,
+	}
+	aw := newArchiveWriter(w)
+	for _, res := range resources {
+		if res.global() && ctx.filtered() {
+			continue
+		}
+		items, err := res.dump(ctx)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := aw.writeResource(res, items)
+		if err != nil {
+			return nil, fmt.Errorf("write %s: %w", res.Name(), err)
+		}
+		manifest.Resources = append(manifest.Resources, entry)
+	}
+	// 清单最后写入，归档文件被截断时恢复会因为找不到清单而失败
+	if err := aw.writeManifest(manifest); err != nil {
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+	if err := aw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Restore 校验归档文件后按照依赖顺序将数据写入存储
+func Restore(s store.Store, r io.Reader, opt *RestoreOption) (*RestoreReport, error) {
+	return restore(s, r, opt, DefaultResources())
+}
+
+func restore(s store.Store, r io.Reader, opt *RestoreOption, resources []Resource) (*RestoreReport, error) {
+	if opt == nil {
+		opt = &RestoreOption{}
+	}
+	if opt.Conflict == "" {
+		opt.Conflict = ConflictSkip
+	}
+	archive, err := readArchive(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := newLoadContext(s, opt)
+	report := &RestoreReport{
+		Manifest: archive.manifest,
+		Target:   s.Name(),
+		Conflict: opt.Conflict,
+	}
+	for _, res := range resources {
+		data, ok := archive.files[res.Name()]
+		if !ok || (res.global() && ctx.filtered()) {
+			continue
+		}
+		items, err := decodeResource(res, data)
+		if err != nil {
+			return report, fmt.Errorf("decode %s: %w", res.Name(), err)
+		}
+		item, err := res.load(ctx, items)
+		if err != nil {
+			return report, err
+		}
+		report.Resources = append(report.Resources, item)
+	}
+	report.TimestampsRestored = ctx.restorer != nil
+	return report, nil
+}
+
+// ResourceReport 单类资源的恢复结果
+type ResourceReport struct {
+	// Resource 资源名称
+	Resource string `json:"resource"`
+	// Total 备份中符合过滤条件的记录数
+	Total int `json:"total"`
+	// Created 新写入的记录数
+	Created int `json:"created"`
+	// Updated 覆盖目标端已存在记录的数量
+	Updated int `json:"updated"`
+	// Skipped 目标端已存在而跳过的记录数
+	Skipped int `json:"skipped"`
+}
+
+// RestoreReport 恢复报告
+type RestoreReport struct {
+	Manifest  *Manifest         `json:"manifest"`
+	Target    string            `json:"target"`
+	Conflict  ConflictPolicy    `json:"conflict"`
+	Resources []*ResourceReport `json:"resources"`
+	// TimestampsRestored 是否回写了备份中记录的创建、修改时间
+	TimestampsRestored bool `json:"timestamps_restored"`
+}
+
+// Print 以表格的形式输出恢复报告
+func (r *RestoreReport) Print(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "restore backup of %s created at %s (server %s) into %s, conflict policy: %s\n\n",
+		r.Manifest.Store, r.Manifest.CreateTime.Format(time.RFC3339), r.Manifest.ServerVersion, r.Target, r.Conflict)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RESOURCE\tTOTAL\tCREATED\tUPDATED\tSKIPPED")
+	for _, item := range r.Resources {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", item.Resource, item.Total, item.Created,
+			item.Updated, item.Skipped)
+	}
+	_ = tw.Flush()
+	if !r.TimestampsRestored {
+		_, _ = fmt.Fprintln(w, "\nctime/mtime of restored records were reset")
+	}
+}
@@ -0,0 +1,291 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package backup
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	apiconfig "github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/dataset"
+	"github.com/polarismesh/polaris/store/mock"
+)
+
+// restorableStore 支持回写时间的 mock store
+type restorableStore struct {
+	*mock.MockStore
+	restored []interface{}
+}
+
+func (s *restorableStore) RestoreTimestamps(item interface{}) error {
+	s.restored = append(s.restored, item)
+	return nil
+}
+
+// viewStore 支持一致性读视图的 mock store，读视图即为自身
+type viewStore struct {
+	*mock.MockStore
+	views int
+}
+
+func (s *viewStore) ReadView(tx store.Tx) (store.Store, error) {
+	s.views++
+	return s, nil
+}
+
+func expectReadTx(ctrl *gomock.Controller, s *mock.MockStore, times int) *mock.MockTx {
+	tx := mock.NewMockTx(ctrl)
+	tx.EXPECT().Rollback().Return(nil).Times(times)
+	s.EXPECT().StartReadTx().Return(tx, nil).Times(times)
+	return tx
+}
+
+func testResources() []Resource {
+	return []Resource{namespaceResource(), serviceResource(), instanceResource()}
+}
+
+// backupTestData 在 source 中准备 default、test 两个命名空间的数据并导出 default 命名空间
+func backupTestData(t *testing.T, ctrl *gomock.Controller) []byte {
+	source := &viewStore{MockStore: mock.NewMockStore(ctrl)}
+	source.EXPECT().Name().Return("boltdbStore").AnyTimes()
+	// 备份开启的读事务，以及实例在读视图上开启的嵌套事务
+	tx := expectReadTx(ctrl, source.MockStore, 2)
+	tx.EXPECT().CreateReadView().Return(nil)
+
+	mtime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	source.EXPECT().GetMoreNamespaces(gomock.Any()).Return([]*model.Namespace{
+		{Name: "test", Token: "t2", Valid: true},
+		{Name: "default", Token: "t1", Comment: "default ns", Valid: true, ModifyTime: mtime},
+		{Name: "deleted", Valid: false},
+	}, nil)
+	services := map[string]*model.Service{
+		"svc-1": {ID: "svc-1", Name: "svc", Namespace: "default", Token: "st", Revision: "r1",
+			Meta: map[string]string{"k": "v"}, Valid: true, CreateTime: mtime, ModifyTime: mtime},
+		"svc-2": {ID: "svc-2", Name: "svc", Namespace: "test", Revision: "r2", Valid: true},
+	}
+	source.EXPECT().GetMoreServices(gomock.Any(), true, false, true).Return(services, nil)
+	source.EXPECT().GetMoreServices(gomock.Any(), true, false, false).Return(services, nil)
+	source.EXPECT().GetMoreInstances(tx, gomock.Any(), true, true, nil).Return(map[string]*model.Instance{
+		"ins-1": {
+			Proto: &apiservice.Instance{
+				Id:   utils.NewStringValue("ins-1"),
+				Host: utils.NewStringValue("127.0.0.1"),
+				Port: utils.NewUInt32Value(8080),
+			},
+			ServiceID:  "svc-1",
+			Valid:      true,
+			ModifyTime: mtime,
+		},
+		"ins-2": {
+			Proto:     &apiservice.Instance{Id: utils.NewStringValue("ins-2")},
+			ServiceID: "svc-2",
+			Valid:     true,
+		},
+	}, nil)
+
+	buf := bytes.NewBuffer(nil)
+	manifest, err := backup(source, buf, &BackupOption{Namespaces: []string{"default"}}, testResources())
+	assert.NoError(t, err)
+	assert.Equal(t, FormatVersion, manifest.FormatVersion)
+	assert.Equal(t, "boltdbStore", manifest.Store)
+	counts := map[string]int{}
+	for _, entry := range manifest.Resources {
+		counts[entry.Name] = entry.Count
+	}
+	assert.Equal(t, map[string]int{"namespace": 1, "service": 1, "instance": 1}, counts)
+	assert.Equal(t, 1, source.views)
+	return buf.Bytes()
+}
+
+func TestBackupRestore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	data := backupTestData(t, ctrl)
+
+	target := &restorableStore{MockStore: mock.NewMockStore(ctrl)}
+	target.EXPECT().Name().Return("defaultStore").AnyTimes()
+	// 只有实例的查询需要读事务
+	tx := expectReadTx(ctrl, target.MockStore, 1)
+	target.EXPECT().GetMoreNamespaces(gomock.Any()).Return([]*model.Namespace{
+		{Name: "default", Valid: true},
+	}, nil)
+	target.EXPECT().GetMoreServices(gomock.Any(), true, false, true).Return(map[string]*model.Service{}, nil)
+	target.EXPECT().AddService(gomock.Any()).DoAndReturn(func(svc *model.Service) error {
+		assert.Equal(t, "svc-1", svc.ID)
+		assert.Equal(t, "st", svc.Token)
+		assert.Equal(t, map[string]string{"k": "v"}, svc.Meta)
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local), svc.ModifyTime)
+		return nil
+	})
+	target.EXPECT().GetMoreInstances(tx, gomock.Any(), true, true, nil).Return(map[string]*model.Instance{}, nil)
+	// 实例关联目标端的服务 ID
+	target.EXPECT().GetService("svc", "default").Return(&model.Service{ID: "svc-new"}, nil)
+	target.EXPECT().BatchAddInstances(gomock.Any()).DoAndReturn(func(instances []*model.Instance) error {
+		assert.Len(t, instances, 1)
+		assert.Equal(t, "svc-new", instances[0].ServiceID)
+		assert.True(t, proto.Equal(&apiservice.Instance{
+			Id:        utils.NewStringValue("ins-1"),
+			Service:   utils.NewStringValue("svc"),
+			Namespace: utils.NewStringValue("default"),
+			Host:      utils.NewStringValue("127.0.0.1"),
+			Port:      utils.NewUInt32Value(8080),
+			Mtime:     utils.NewStringValue("2024-01-02 03:04:05"),
+		}, instances[0].Proto))
+		return nil
+	})
+
+	report, err := restore(target, bytes.NewReader(data), &RestoreOption{RestoreTimestamps: true}, testResources())
+	assert.NoError(t, err)
+	assert.Equal(t, ConflictSkip, report.Conflict)
+	assert.True(t, report.TimestampsRestored)
+	assert.Equal(t, []*ResourceReport{
+		{Resource: "namespace", Total: 1, Skipped: 1},
+		{Resource: "service", Total: 1, Created: 1},
+		{Resource: "instance", Total: 1, Created: 1},
+	}, report.Resources)
+	assert.Len(t, target.restored, 2)
+
+	buf := bytes.NewBuffer(nil)
+	report.Print(buf)
+	assert.Contains(t, buf.String(), "instance")
+}
+
+func TestRestoreOverwrite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	data := backupTestData(t, ctrl)
+
+	target := mock.NewMockStore(ctrl)
+	target.EXPECT().Name().Return("defaultStore").AnyTimes()
+	target.EXPECT().GetMoreNamespaces(gomock.Any()).Return([]*model.Namespace{
+		{Name: "default", Token: "old", Valid: true},
+	}, nil)
+	target.EXPECT().UpdateNamespace(gomock.Any()).DoAndReturn(func(ns *model.Namespace) error {
+		assert.Equal(t, "default ns", ns.Comment)
+		return nil
+	})
+	target.EXPECT().UpdateNamespaceToken("default", "t1").Return(nil)
+
+	report, err := restore(target, bytes.NewReader(data), &RestoreOption{Conflict: ConflictOverwrite},
+		[]Resource{namespaceResource()})
+	assert.NoError(t, err)
+	assert.False(t, report.TimestampsRestored)
+	assert.Equal(t, []*ResourceReport{
+		{Resource: "namespace", Total: 1, Updated: 1},
+	}, report.Resources)
+}
+
+func TestBackupWithoutReadView(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	source := mock.NewMockStore(ctrl)
+	source.EXPECT().Name().Return("boltdbStore").AnyTimes()
+	_, err := backup(source, bytes.NewBuffer(nil), &BackupOption{}, testResources())
+	assert.ErrorContains(t, err, "consistent read view")
+}
+
+func TestDefaultResources(t *testing.T) {
+	// 备份覆盖 dataset 中定义的全部资源，且顺序一致
+	expect := make([]string, 0)
+	for _, kind := range dataset.Resources() {
+		expect = append(expect, kind.Name())
+	}
+	names := make([]string, 0)
+	for _, r := range DefaultResources() {
+		names = append(names, r.Name())
+	}
+	assert.Equal(t, expect, names)
+}
+
+func TestRestoreInvalidArchive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	data := backupTestData(t, ctrl)
+	target := mock.NewMockStore(ctrl)
+
+	// 截断的归档文件
+	_, err := Restore(target, bytes.NewReader(data[:len(data)/2]), nil)
+	assert.Error(t, err)
+
+	// 没有清单的归档文件
+	buf := bytes.NewBuffer(nil)
+	aw := newArchiveWriter(buf)
+	_, err = aw.writeResource(namespaceResource(), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, aw.Close())
+	_, err = Restore(target, buf, nil)
+	assert.ErrorIs(t, err, ErrManifestNotFound)
+
+	// 资源文件与清单中的摘要不一致
+	buf.Reset()
+	aw = newArchiveWriter(buf)
+	entry, err := aw.writeResource(namespaceResource(), nil)
+	assert.NoError(t, err)
+	entry.Sha256 = digest([]byte("changed"))
+	assert.NoError(t, aw.writeManifest(&Manifest{FormatVersion: FormatVersion, Resources: []*ManifestEntry{entry}}))
+	assert.NoError(t, aw.Close())
+	_, err = Restore(target, buf, nil)
+	assert.ErrorContains(t, err, "checksum")
+
+	// 不支持的格式版本
+	buf.Reset()
+	aw = newArchiveWriter(buf)
+	assert.NoError(t, aw.writeManifest(&Manifest{FormatVersion: FormatVersion + 1}))
+	assert.NoError(t, aw.Close())
+	_, err = Restore(target, buf, nil)
+	assert.ErrorContains(t, err, "unsupported backup format version")
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	policy, err := ParseConflictPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, ConflictSkip, policy)
+
+	policy, err = ParseConflictPolicy("overwrite")
+	assert.NoError(t, err)
+	assert.Equal(t, ConflictOverwrite, policy)
+
+	_, err = ParseConflictPolicy("merge")
+	assert.Error(t, err)
+}
+
+func TestReleaseHistoryVersion(t *testing.T) {
+	r := configReleaseHistoryResource().(*resource[*model.ConfigFileReleaseHistory, *apiconfig.ConfigFileReleaseHistory])
+	history := &model.ConfigFileReleaseHistory{
+		Id: 1, Name: "v3", Namespace: "default", Group: "g", FileName: "a.yaml", Version: 3,
+		Metadata: map[string]string{"k": "v"},
+	}
+	spec, err := r.toSpec(nil, history)
+	assert.NoError(t, err)
+	ret, err := r.fromSpec(nil, spec)
+	assert.NoError(t, err)
+	// 版本号通过保留标签还原，且不出现在标签中
+	assert.Equal(t, uint64(3), ret.Version)
+	assert.Equal(t, map[string]string{"k": "v"}, ret.Metadata)
+	assert.Equal(t, dataset.ConfigReleaseHistories().Key(history), dataset.ConfigReleaseHistories().Key(ret))
+}
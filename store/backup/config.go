@@ -0,0 +1,361 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	apiconfig "github.com/polarismesh/specification/source/go/api/v1/config_manage"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/dataset"
+)
+
+// releaseVersionTag 发布历史的消息中没有版本号字段，备份时记录在该保留标签中
+const releaseVersionTag = "internal-backup-release-version"
+
+func configGroupResource() Resource {
+	return &resource[*model.ConfigFileGroup, *apiconfig.ConfigFileGroup]{
+		kind:      dataset.ConfigGroups(),
+		spec:      func() *apiconfig.ConfigFileGroup { return &apiconfig.ConfigFileGroup{} },
+		namespace: func(spec *apiconfig.ConfigFileGroup) string { return spec.GetNamespace().GetValue() },
+		id:        func(item *model.ConfigFileGroup) string { return strconv.FormatUint(item.Id, 10) },
+		toSpec: func(_ *dumpContext, item *model.ConfigFileGroup) (*apiconfig.ConfigFileGroup, error) {
+			return &apiconfig.ConfigFileGroup{
+				Id:         utils.NewUInt64Value(item.Id),
+				Name:       utils.NewStringValue(item.Name),
+				Namespace:  utils.NewStringValue(item.Namespace),
+				Comment:    utils.NewStringValue(item.Comment),
+				Owner:      utils.NewStringValue(item.Owner),
+				Business:   utils.NewStringValue(item.Business),
+				Department: utils.NewStringValue(item.Department),
+				Metadata:   item.Metadata,
+				CreateTime: utils.NewStringValue(formatTime(item.CreateTime)),
+				CreateBy:   utils.NewStringValue(item.CreateBy),
+				ModifyTime: utils.NewStringValue(formatTime(item.ModifyTime)),
+				ModifyBy:   utils.NewStringValue(item.ModifyBy),
+			}, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apiconfig.ConfigFileGroup) (*model.ConfigFileGroup, error) {
+			return &model.ConfigFileGroup{
+				Id:         spec.GetId().GetValue(),
+				Name:       spec.GetName().GetValue(),
+				Namespace:  spec.GetNamespace().GetValue(),
+				Comment:    spec.GetComment().GetValue(),
+				Owner:      spec.GetOwner().GetValue(),
+				Business:   spec.GetBusiness().GetValue(),
+				Department: spec.GetDepartment().GetValue(),
+				Metadata:   spec.GetMetadata(),
+				CreateTime: parseTime(spec.GetCreateTime().GetValue()),
+				CreateBy:   spec.GetCreateBy().GetValue(),
+				ModifyTime: parseTime(spec.GetModifyTime().GetValue()),
+				ModifyBy:   spec.GetModifyBy().GetValue(),
+				Valid:      true,
+			}, nil
+		},
+		update: func(s store.Store, item, _ *model.ConfigFileGroup) error {
+			return s.UpdateConfigFileGroup(item)
+		},
+	}
+}
+
+func configFileResource() Resource {
+	return &resource[*model.ConfigFile, *apiconfig.ConfigFile]{
+		kind:      dataset.ConfigFiles(),
+		spec:      func() *apiconfig.ConfigFile { return &apiconfig.ConfigFile{} },
+		namespace: func(spec *apiconfig.ConfigFile) string { return spec.GetNamespace().GetValue() },
+		toSpec: func(_ *dumpContext, item *model.ConfigFile) (*apiconfig.ConfigFile, error) {
+			return &apiconfig.ConfigFile{
+				Id:        utils.NewUInt64Value(item.Id),
+				Name:      utils.NewStringValue(item.Name),
+				Namespace: utils.NewStringValue(item.Namespace),
+				Group:     utils.NewStringValue(item.Group),
+				// 加密配置保存的是密文，数据密钥保存在标签中，原样备份
+				Content:     utils.NewStringValue(item.Content),
+				Format:      utils.NewStringValue(item.Format),
+				Comment:     utils.NewStringValue(item.Comment),
+				Status:      utils.NewStringValue(item.Status),
+				Tags:        toTags(item.Metadata),
+				CreateTime:  utils.NewStringValue(formatTime(item.CreateTime)),
+				CreateBy:    utils.NewStringValue(item.CreateBy),
+				ModifyTime:  utils.NewStringValue(formatTime(item.ModifyTime)),
+				ModifyBy:    utils.NewStringValue(item.ModifyBy),
+				ReleaseTime: utils.NewStringValue(formatTime(item.ReleaseTime)),
+				ReleaseBy:   utils.NewStringValue(item.ReleaseBy),
+				Encrypted:   utils.NewBoolValue(item.Encrypt),
+				EncryptAlgo: utils.NewStringValue(item.EncryptAlgo),
+			}, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apiconfig.ConfigFile) (*model.ConfigFile, error) {
+			return &model.ConfigFile{
+				Id:          spec.GetId().GetValue(),
+				Name:        spec.GetName().GetValue(),
+				Namespace:   spec.GetNamespace().GetValue(),
+				Group:       spec.GetGroup().GetValue(),
+				Content:     spec.GetContent().GetValue(),
+				Format:      spec.GetFormat().GetValue(),
+				Comment:     spec.GetComment().GetValue(),
+				Status:      spec.GetStatus().GetValue(),
+				Metadata:    model.ToTagMap(spec.GetTags()),
+				CreateTime:  parseTime(spec.GetCreateTime().GetValue()),
+				CreateBy:    spec.GetCreateBy().GetValue(),
+				ModifyTime:  parseTime(spec.GetModifyTime().GetValue()),
+				ModifyBy:    spec.GetModifyBy().GetValue(),
+				ReleaseTime: parseTime(spec.GetReleaseTime().GetValue()),
+				ReleaseBy:   spec.GetReleaseBy().GetValue(),
+				Encrypt:     spec.GetEncrypted().GetValue(),
+				EncryptAlgo: spec.GetEncryptAlgo().GetValue(),
+				Valid:       true,
+			}, nil
+		},
+		update: func(s store.Store, item, _ *model.ConfigFile) error {
+			return dataset.DoTransaction(s, func(tx store.Tx) error {
+				return s.UpdateConfigFileTx(tx, item)
+			})
+		},
+	}
+}
+
+func configReleaseResource() Resource {
+	return &resource[*model.ConfigFileRelease, *apiconfig.ConfigFileRelease]{
+		kind:      dataset.ConfigReleases(),
+		spec:      func() *apiconfig.ConfigFileRelease { return &apiconfig.ConfigFileRelease{} },
+		namespace: func(spec *apiconfig.ConfigFileRelease) string { return spec.GetNamespace().GetValue() },
+		toSpec: func(_ *dumpContext, item *model.ConfigFileRelease) (*apiconfig.ConfigFileRelease, error) {
+			return &apiconfig.ConfigFileRelease{
+				Id:                 utils.NewUInt64Value(item.Id),
+				Name:               utils.NewStringValue(item.Name),
+				Namespace:          utils.NewStringValue(item.Namespace),
+				Group:              utils.NewStringValue(item.Group),
+				FileName:           utils.NewStringValue(item.FileName),
+				Content:            utils.NewStringValue(item.Content),
+				Comment:            utils.NewStringValue(item.Comment),
+				Md5:                utils.NewStringValue(item.Md5),
+				Version:            utils.NewUInt64Value(item.Version),
+				CreateTime:         utils.NewStringValue(formatTime(item.CreateTime)),
+				CreateBy:           utils.NewStringValue(item.CreateBy),
+				ModifyTime:         utils.NewStringValue(formatTime(item.ModifyTime)),
+				ModifyBy:           utils.NewStringValue(item.ModifyBy),
+				Tags:               toTags(item.Metadata),
+				Active:             utils.NewBoolValue(item.Active),
+				Format:             utils.NewStringValue(item.Format),
+				ReleaseDescription: utils.NewStringValue(item.ReleaseDescription),
+				ReleaseType:        utils.NewStringValue(string(item.ReleaseType)),
+				BetaLabels:         item.BetaLabels,
+			}, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apiconfig.ConfigFileRelease) (*model.ConfigFileRelease, error) {
+			return &model.ConfigFileRelease{
+				SimpleConfigFileRelease: &model.SimpleConfigFileRelease{
+					ConfigFileReleaseKey: &model.ConfigFileReleaseKey{
+						Id:          spec.GetId().GetValue(),
+						Name:        spec.GetName().GetValue(),
+						Namespace:   spec.GetNamespace().GetValue(),
+						Group:       spec.GetGroup().GetValue(),
+						FileName:    spec.GetFileName().GetValue(),
+						ReleaseType: model.ReleaseType(spec.GetReleaseType().GetValue()),
+					},
+					Version:            spec.GetVersion().GetValue(),
+					Comment:            spec.GetComment().GetValue(),
+					Md5:                spec.GetMd5().GetValue(),
+					Active:             spec.GetActive().GetValue(),
+					Valid:              true,
+					Format:             spec.GetFormat().GetValue(),
+					Metadata:           model.ToTagMap(spec.GetTags()),
+					CreateTime:         parseTime(spec.GetCreateTime().GetValue()),
+					CreateBy:           spec.GetCreateBy().GetValue(),
+					ModifyTime:         parseTime(spec.GetModifyTime().GetValue()),
+					ModifyBy:           spec.GetModifyBy().GetValue(),
+					ReleaseDescription: spec.GetReleaseDescription().GetValue(),
+					BetaLabels:         spec.GetBetaLabels(),
+				},
+				Content: spec.GetContent().GetValue(),
+			}, nil
+		},
+		update: func(s store.Store, item, _ *model.ConfigFileRelease) error {
+			// 存储层没有更新发布的接口，先删除目标端的发布再重新写入
+			return dataset.DoTransaction(s, func(tx store.Tx) error {
+				if err := s.DeleteConfigFileReleaseTx(tx, item.ConfigFileReleaseKey); err != nil {
+					return err
+				}
+				return dataset.CreateReleaseTx(s, tx, item)
+			})
+		},
+	}
+}
+
+func configReleaseHistoryResource() Resource {
+	return &resource[*model.ConfigFileReleaseHistory, *apiconfig.ConfigFileReleaseHistory]{
+		kind:      dataset.ConfigReleaseHistories(),
+		spec:      func() *apiconfig.ConfigFileReleaseHistory { return &apiconfig.ConfigFileReleaseHistory{} },
+		namespace: func(spec *apiconfig.ConfigFileReleaseHistory) string { return spec.GetNamespace().GetValue() },
+		toSpec: func(_ *dumpContext, item *model.ConfigFileReleaseHistory) (*apiconfig.ConfigFileReleaseHistory, error) {
+			// 消息中没有发布版本号字段，记录在保留标签中
+			metadata := make(map[string]string, len(item.Metadata)+1)
+			for k, v := range item.Metadata {
+				metadata[k] = v
+			}
+			metadata[releaseVersionTag] = strconv.FormatUint(item.Version, 10)
+			return &apiconfig.ConfigFileReleaseHistory{
+				Id:                 utils.NewUInt64Value(item.Id),
+				Name:               utils.NewStringValue(item.Name),
+				Namespace:          utils.NewStringValue(item.Namespace),
+				Group:              utils.NewStringValue(item.Group),
+				FileName:           utils.NewStringValue(item.FileName),
+				Content:            utils.NewStringValue(item.Content),
+				Format:             utils.NewStringValue(item.Format),
+				Comment:            utils.NewStringValue(item.Comment),
+				Md5:                utils.NewStringValue(item.Md5),
+				Type:               utils.NewStringValue(item.Type),
+				Status:             utils.NewStringValue(item.Status),
+				Tags:               toTags(metadata),
+				CreateTime:         utils.NewStringValue(formatTime(item.CreateTime)),
+				CreateBy:           utils.NewStringValue(item.CreateBy),
+				ModifyTime:         utils.NewStringValue(formatTime(item.ModifyTime)),
+				ModifyBy:           utils.NewStringValue(item.ModifyBy),
+				Reason:             utils.NewStringValue(item.Reason),
+				ReleaseDescription: utils.NewStringValue(item.ReleaseDescription),
+			}, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apiconfig.ConfigFileReleaseHistory) (*model.ConfigFileReleaseHistory, error) {
+			metadata := model.ToTagMap(spec.GetTags())
+			version, err := strconv.ParseUint(metadata[releaseVersionTag], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid release version of history %d: %w", spec.GetId().GetValue(), err)
+			}
+			delete(metadata, releaseVersionTag)
+			return &model.ConfigFileReleaseHistory{
+				Id:                 spec.GetId().GetValue(),
+				Name:               spec.GetName().GetValue(),
+				Namespace:          spec.GetNamespace().GetValue(),
+				Group:              spec.GetGroup().GetValue(),
+				FileName:           spec.GetFileName().GetValue(),
+				Format:             spec.GetFormat().GetValue(),
+				Metadata:           metadata,
+				Content:            spec.GetContent().GetValue(),
+				Comment:            spec.GetComment().GetValue(),
+				Version:            version,
+				Md5:                spec.GetMd5().GetValue(),
+				Type:               spec.GetType().GetValue(),
+				Status:             spec.GetStatus().GetValue(),
+				CreateTime:         parseTime(spec.GetCreateTime().GetValue()),
+				CreateBy:           spec.GetCreateBy().GetValue(),
+				ModifyTime:         parseTime(spec.GetModifyTime().GetValue()),
+				ModifyBy:           spec.GetModifyBy().GetValue(),
+				Valid:              true,
+				Reason:             spec.GetReason().GetValue(),
+				ReleaseDescription: spec.GetReleaseDescription().GetValue(),
+			}, nil
+		},
+	}
+}
+
+func configTemplateResource() Resource {
+	return &resource[*model.ConfigFileTemplate, *apiconfig.ConfigFileTemplate]{
+		kind: dataset.ConfigTemplates(),
+		spec: func() *apiconfig.ConfigFileTemplate { return &apiconfig.ConfigFileTemplate{} },
+		toSpec: func(_ *dumpContext, item *model.ConfigFileTemplate) (*apiconfig.ConfigFileTemplate, error) {
+			return &apiconfig.ConfigFileTemplate{
+				Id:         utils.NewUInt64Value(item.Id),
+				Name:       utils.NewStringValue(item.Name),
+				Content:    utils.NewStringValue(item.Content),
+				Format:     utils.NewStringValue(item.Format),
+				Comment:    utils.NewStringValue(item.Comment),
+				CreateTime: utils.NewStringValue(formatTime(item.CreateTime)),
+				CreateBy:   utils.NewStringValue(item.CreateBy),
+				ModifyTime: utils.NewStringValue(formatTime(item.ModifyTime)),
+				ModifyBy:   utils.NewStringValue(item.ModifyBy),
+			}, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apiconfig.ConfigFileTemplate) (*model.ConfigFileTemplate, error) {
+			return &model.ConfigFileTemplate{
+				Id:         spec.GetId().GetValue(),
+				Name:       spec.GetName().GetValue(),
+				Content:    spec.GetContent().GetValue(),
+				Format:     spec.GetFormat().GetValue(),
+				Comment:    spec.GetComment().GetValue(),
+				CreateTime: parseTime(spec.GetCreateTime().GetValue()),
+				CreateBy:   spec.GetCreateBy().GetValue(),
+				ModifyTime: parseTime(spec.GetModifyTime().GetValue()),
+				ModifyBy:   spec.GetModifyBy().GetValue(),
+			}, nil
+		},
+	}
+}
+
+// grayResource 灰度规则没有对应的 protobuf 消息，使用 Struct 保存
+func grayResource() Resource {
+	return &resource[*model.GrayResource, *structpb.Struct]{
+		kind: dataset.GrayResources(),
+		spec: func() *structpb.Struct { return &structpb.Struct{} },
+		// 灰度规则的名称形如 module@namespace@..., 按照其中的命名空间过滤
+		namespace: func(spec *structpb.Struct) string {
+			parts := strings.Split(spec.GetFields()["name"].GetStringValue(), "@")
+			if len(parts) < 2 {
+				return ""
+			}
+			return parts[1]
+		},
+		toSpec: func(_ *dumpContext, item *model.GrayResource) (*structpb.Struct, error) {
+			return structpb.NewStruct(map[string]interface{}{
+				"name":        item.Name,
+				"match_rule":  item.MatchRule,
+				"create_by":   item.CreateBy,
+				"modify_by":   item.ModifyBy,
+				"create_time": formatTime(item.CreateTime),
+				"modify_time": formatTime(item.ModifyTime),
+			})
+		},
+		fromSpec: func(_ *loadContext, spec *structpb.Struct) (*model.GrayResource, error) {
+			fields := spec.GetFields()
+			if fields["name"].GetStringValue() == "" {
+				return nil, errors.New("gray resource without name")
+			}
+			return &model.GrayResource{
+				Name:       fields["name"].GetStringValue(),
+				MatchRule:  fields["match_rule"].GetStringValue(),
+				CreateBy:   fields["create_by"].GetStringValue(),
+				ModifyBy:   fields["modify_by"].GetStringValue(),
+				CreateTime: parseTime(fields["create_time"].GetStringValue()),
+				ModifyTime: parseTime(fields["modify_time"].GetStringValue()),
+				Valid:      true,
+			}, nil
+		},
+		update: func(s store.Store, item, _ *model.GrayResource) error {
+			return dataset.DoTransaction(s, func(tx store.Tx) error {
+				return s.CreateGrayResourceTx(tx, item)
+			})
+		},
+	}
+}
+
+// toTags 将元数据按照 key 排序后转换为标签，保证多次备份的结果一致
+func toTags(metadata map[string]string) []*apiconfig.ConfigFileTag {
+	tags := model.FromTagMap(metadata)
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].GetKey().GetValue() < tags[j].GetKey().GetValue()
+	})
+	return tags
+}
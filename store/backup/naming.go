@@ -0,0 +1,658 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package backup
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	apifault "github.com/polarismesh/specification/source/go/api/v1/fault_tolerance"
+	apimodel "github.com/polarismesh/specification/source/go/api/v1/model"
+	apiservice "github.com/polarismesh/specification/source/go/api/v1/service_manage"
+	apitraffic "github.com/polarismesh/specification/source/go/api/v1/traffic_manage"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/dataset"
+)
+
+func namespaceResource() Resource {
+	return &resource[*model.Namespace, *apimodel.Namespace]{
+		kind:      dataset.Namespaces(),
+		spec:      func() *apimodel.Namespace { return &apimodel.Namespace{} },
+		namespace: func(spec *apimodel.Namespace) string { return spec.GetName().GetValue() },
+		toSpec: func(_ *dumpContext, item *model.Namespace) (*apimodel.Namespace, error) {
+			return &apimodel.Namespace{
+				Name:            utils.NewStringValue(item.Name),
+				Comment:         utils.NewStringValue(item.Comment),
+				Owners:          utils.NewStringValue(item.Owner),
+				Token:           utils.NewStringValue(item.Token),
+				Ctime:           utils.NewStringValue(formatTime(item.CreateTime)),
+				Mtime:           utils.NewStringValue(formatTime(item.ModifyTime)),
+				ServiceExportTo: item.ListServiceExportTo(),
+				Metadata:        item.Metadata,
+			}, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apimodel.Namespace) (*model.Namespace, error) {
+			return &model.Namespace{
+				Name:            spec.GetName().GetValue(),
+				Comment:         spec.GetComment().GetValue(),
+				Owner:           spec.GetOwners().GetValue(),
+				Token:           spec.GetToken().GetValue(),
+				Valid:           true,
+				CreateTime:      parseTime(spec.GetCtime().GetValue()),
+				ModifyTime:      parseTime(spec.GetMtime().GetValue()),
+				ServiceExportTo: toSet(spec.GetServiceExportTo()),
+				Metadata:        spec.GetMetadata(),
+			}, nil
+		},
+		update: func(s store.Store, item, _ *model.Namespace) error {
+			if err := s.UpdateNamespace(item); err != nil {
+				return err
+			}
+			return s.UpdateNamespaceToken(item.Name, item.Token)
+		},
+	}
+}
+
+func serviceResource() Resource {
+	return &resource[*model.Service, *apiservice.Service]{
+		kind:      dataset.Services(),
+		spec:      func() *apiservice.Service { return &apiservice.Service{} },
+		namespace: func(spec *apiservice.Service) string { return spec.GetNamespace().GetValue() },
+		id:        func(item *model.Service) string { return item.ID },
+		toSpec: func(_ *dumpContext, item *model.Service) (*apiservice.Service, error) {
+			spec := item.ToSpec()
+			spec.PlatformId = utils.NewStringValue(item.PlatformID)
+			spec.Ctime = utils.NewStringValue(formatTime(item.CreateTime))
+			spec.Mtime = utils.NewStringValue(formatTime(item.ModifyTime))
+			return spec, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apiservice.Service) (*model.Service, error) {
+			return &model.Service{
+				ID:         spec.GetId().GetValue(),
+				Name:       spec.GetName().GetValue(),
+				Namespace:  spec.GetNamespace().GetValue(),
+				Business:   spec.GetBusiness().GetValue(),
+				Ports:      spec.GetPorts().GetValue(),
+				Meta:       spec.GetMetadata(),
+				Comment:    spec.GetComment().GetValue(),
+				Department: spec.GetDepartment().GetValue(),
+				CmdbMod1:   spec.GetCmdbMod1().GetValue(),
+				CmdbMod2:   spec.GetCmdbMod2().GetValue(),
+				CmdbMod3:   spec.GetCmdbMod3().GetValue(),
+				Token:      spec.GetToken().GetValue(),
+				Owner:      spec.GetOwners().GetValue(),
+				Revision:   spec.GetRevision().GetValue(),
+				PlatformID: spec.GetPlatformId().GetValue(),
+				Valid:      true,
+				CreateTime: parseTime(spec.GetCtime().GetValue()),
+				ModifyTime: parseTime(spec.GetMtime().GetValue()),
+				ExportTo:   toSet(spec.GetExportTo()),
+			}, nil
+		},
+		update: func(s store.Store, item, old *model.Service) error {
+			// 目标端的服务 ID 可能已经被其他资源引用，保持不变
+			item.ID = old.ID
+			return s.UpdateService(item, true)
+		},
+	}
+}
+
+func serviceAliasResource() Resource {
+	return &resource[*model.Service, *apiservice.ServiceAlias]{
+		kind:      dataset.ServiceAliases(),
+		spec:      func() *apiservice.ServiceAlias { return &apiservice.ServiceAlias{} },
+		namespace: func(spec *apiservice.ServiceAlias) string { return spec.GetAliasNamespace().GetValue() },
+		toSpec: func(ctx *dumpContext, item *model.Service) (*apiservice.ServiceAlias, error) {
+			source, err := ctx.serviceByID(item.Reference)
+			if err != nil {
+				return nil, err
+			}
+			return &apiservice.ServiceAlias{
+				Id:             utils.NewStringValue(item.ID),
+				Service:        utils.NewStringValue(source.Name),
+				Namespace:      utils.NewStringValue(source.Namespace),
+				Alias:          utils.NewStringValue(item.Name),
+				AliasNamespace: utils.NewStringValue(item.Namespace),
+				Owners:         utils.NewStringValue(item.Owner),
+				Comment:        utils.NewStringValue(item.Comment),
+				ServiceToken:   utils.NewStringValue(item.Token),
+				Ctime:          utils.NewStringValue(formatTime(item.CreateTime)),
+				Mtime:          utils.NewStringValue(formatTime(item.ModifyTime)),
+			}, nil
+		},
+		fromSpec: func(ctx *loadContext, spec *apiservice.ServiceAlias) (*model.Service, error) {
+			reference, err := ctx.serviceID(spec.GetNamespace().GetValue(), spec.GetService().GetValue())
+			if err != nil {
+				return nil, err
+			}
+			return &model.Service{
+				ID:         spec.GetId().GetValue(),
+				Name:       spec.GetAlias().GetValue(),
+				Namespace:  spec.GetAliasNamespace().GetValue(),
+				Reference:  reference,
+				Token:      spec.GetServiceToken().GetValue(),
+				Owner:      spec.GetOwners().GetValue(),
+				Comment:    spec.GetComment().GetValue(),
+				Revision:   utils.NewUUID(),
+				Valid:      true,
+				CreateTime: parseTime(spec.GetCtime().GetValue()),
+				ModifyTime: parseTime(spec.GetMtime().GetValue()),
+			}, nil
+		},
+		update: func(s store.Store, item, old *model.Service) error {
+			item.ID = old.ID
+			return s.UpdateServiceAlias(item, true)
+		},
+	}
+}
+
+func instanceResource() Resource {
+	return &resource[*model.Instance, *apiservice.Instance]{
+		kind:      dataset.Instances(),
+		spec:      func() *apiservice.Instance { return &apiservice.Instance{} },
+		namespace: func(spec *apiservice.Instance) string { return spec.GetNamespace().GetValue() },
+		toSpec: func(ctx *dumpContext, item *model.Instance) (*apiservice.Instance, error) {
+			svc, err := ctx.serviceByID(item.ServiceID)
+			if err != nil {
+				return nil, err
+			}
+			spec := proto.Clone(item.Proto).(*apiservice.Instance)
+			spec.Service = utils.NewStringValue(svc.Name)
+			spec.Namespace = utils.NewStringValue(svc.Namespace)
+			spec.Mtime = utils.NewStringValue(formatTime(item.ModifyTime))
+			return spec, nil
+		},
+		fromSpec: func(ctx *loadContext, spec *apiservice.Instance) (*model.Instance, error) {
+			serviceID, err := ctx.serviceID(spec.GetNamespace().GetValue(), spec.GetService().GetValue())
+			if err != nil {
+				return nil, err
+			}
+			return &model.Instance{
+				Proto:      spec,
+				ServiceID:  serviceID,
+				Valid:      true,
+				ModifyTime: parseTime(spec.GetMtime().GetValue()),
+			}, nil
+		},
+		update: func(s store.Store, item, _ *model.Instance) error {
+			return s.UpdateInstance(item)
+		},
+	}
+}
+
+func routingConfigResource() Resource {
+	return &resource[*model.RoutingConfig, *apitraffic.Routing]{
+		kind:      dataset.RoutingConfigs(),
+		spec:      func() *apitraffic.Routing { return &apitraffic.Routing{} },
+		namespace: func(spec *apitraffic.Routing) string { return spec.GetNamespace().GetValue() },
+		toSpec: func(ctx *dumpContext, item *model.RoutingConfig) (*apitraffic.Routing, error) {
+			svc, err := ctx.serviceByID(item.ID)
+			if err != nil {
+				return nil, err
+			}
+			spec := &apitraffic.Routing{
+				Service:   utils.NewStringValue(svc.Name),
+				Namespace: utils.NewStringValue(svc.Namespace),
+				Revision:  utils.NewStringValue(item.Revision),
+				Ctime:     utils.NewStringValue(formatTime(item.CreateTime)),
+				Mtime:     utils.NewStringValue(formatTime(item.ModifyTime)),
+			}
+			if item.InBounds != "" {
+				if err := json.Unmarshal([]byte(item.InBounds), &spec.Inbounds); err != nil {
+					return nil, err
+				}
+			}
+			if item.OutBounds != "" {
+				if err := json.Unmarshal([]byte(item.OutBounds), &spec.Outbounds); err != nil {
+					return nil, err
+				}
+			}
+			return spec, nil
+		},
+		fromSpec: func(ctx *loadContext, spec *apitraffic.Routing) (*model.RoutingConfig, error) {
+			// v1 版本的路由规则以服务 ID 作为主键
+			serviceID, err := ctx.serviceID(spec.GetNamespace().GetValue(), spec.GetService().GetValue())
+			if err != nil {
+				return nil, err
+			}
+			inBounds, err := json.Marshal(spec.GetInbounds())
+			if err != nil {
+				return nil, err
+			}
+			outBounds, err := json.Marshal(spec.GetOutbounds())
+			if err != nil {
+				return nil, err
+			}
+			return &model.RoutingConfig{
+				ID:         serviceID,
+				InBounds:   string(inBounds),
+				OutBounds:  string(outBounds),
+				Revision:   utils.DefaultString(spec.GetRevision().GetValue(), utils.NewUUID()),
+				Valid:      true,
+				CreateTime: parseTime(spec.GetCtime().GetValue()),
+				ModifyTime: parseTime(spec.GetMtime().GetValue()),
+			}, nil
+		},
+		update: func(s store.Store, item, _ *model.RoutingConfig) error {
+			return s.UpdateRoutingConfig(item)
+		},
+	}
+}
+
+func routeRuleResource() Resource {
+	return &resource[*model.RouterConfig, *apitraffic.RouteRule]{
+		kind:      dataset.RouterConfigs(),
+		spec:      func() *apitraffic.RouteRule { return &apitraffic.RouteRule{} },
+		namespace: func(spec *apitraffic.RouteRule) string { return spec.GetNamespace() },
+		toSpec: func(_ *dumpContext, item *model.RouterConfig) (*apitraffic.RouteRule, error) {
+			extend, err := item.ToExpendRoutingConfig()
+			if err != nil {
+				return nil, err
+			}
+			// 规则内容为空时 ToApi 无法处理
+			if extend.RuleRouting == nil {
+				extend.RuleRouting = &model.RuleRoutingConfigWrapper{RuleRouting: &apitraffic.RuleRoutingConfig{}}
+			}
+			spec, err := extend.ToApi()
+			if err != nil {
+				return nil, err
+			}
+			spec.Metadata = item.Metadata
+			return spec, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apitraffic.RouteRule) (*model.RouterConfig, error) {
+			item := &model.RouterConfig{Valid: true}
+			if err := item.ParseRouteRuleFromAPI(spec); err != nil {
+				return nil, err
+			}
+			item.CreateTime = parseTime(spec.GetCtime())
+			item.ModifyTime = parseTime(spec.GetMtime())
+			item.EnableTime = parseTime(spec.GetEtime())
+			return item, nil
+		},
+		update: func(s store.Store, item, _ *model.RouterConfig) error {
+			return s.UpdateRoutingConfigV2(item)
+		},
+	}
+}
+
+func rateLimitResource() Resource {
+	return &resource[*model.RateLimit, *apitraffic.Rule]{
+		kind:      dataset.RateLimits(),
+		spec:      func() *apitraffic.Rule { return &apitraffic.Rule{} },
+		namespace: func(spec *apitraffic.Rule) string { return spec.GetNamespace().GetValue() },
+		toSpec: func(ctx *dumpContext, item *model.RateLimit) (*apitraffic.Rule, error) {
+			item = item.CopyNoProto()
+			item.Proto = &apitraffic.Rule{}
+			if len(item.Rule) > 0 {
+				if err := json.Unmarshal([]byte(item.Rule), item.Proto); err != nil {
+					return nil, err
+				}
+				// 存量标签适配到参数列表
+				if err := item.AdaptLabels(); err != nil {
+					return nil, err
+				}
+			}
+			spec := item.Proto
+			// 旧版本的限流规则只记录了服务 ID
+			if spec.GetService().GetValue() == "" && item.ServiceID != "" {
+				if svc, err := ctx.serviceByID(item.ServiceID); err == nil {
+					spec.Service = utils.NewStringValue(svc.Name)
+					spec.Namespace = utils.NewStringValue(svc.Namespace)
+				}
+			}
+			if spec.GetMethod() == nil {
+				spec.Method = &apimodel.MatchString{Value: utils.NewStringValue(item.Method)}
+			}
+			spec.Id = utils.NewStringValue(item.ID)
+			spec.Name = utils.NewStringValue(item.Name)
+			spec.Priority = utils.NewUInt32Value(item.Priority)
+			spec.Disable = utils.NewBoolValue(item.Disable)
+			spec.Revision = utils.NewStringValue(item.Revision)
+			spec.Metadata = item.Metadata
+			spec.Ctime = utils.NewStringValue(formatTime(item.CreateTime))
+			spec.Mtime = utils.NewStringValue(formatTime(item.ModifyTime))
+			spec.Etime = utils.NewStringValue(formatEnableTime(item.EnableTime))
+			return spec, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apitraffic.Rule) (*model.RateLimit, error) {
+			// 与控制台接口保持一致，规则内容不包含 ID、版本号等元数据
+			rule, err := json.Marshal(&apitraffic.Rule{
+				Name:          spec.GetName(),
+				Resource:      spec.GetResource(),
+				Service:       spec.GetService(),
+				Namespace:     spec.GetNamespace(),
+				Type:          spec.GetType(),
+				Amounts:       spec.GetAmounts(),
+				Action:        spec.GetAction(),
+				Disable:       spec.GetDisable(),
+				Report:        spec.GetReport(),
+				Adjuster:      spec.GetAdjuster(),
+				RegexCombine:  spec.GetRegexCombine(),
+				AmountMode:    spec.GetAmountMode(),
+				Failover:      spec.GetFailover(),
+				Arguments:     spec.GetArguments(),
+				Method:        spec.GetMethod(),
+				MaxQueueDelay: spec.GetMaxQueueDelay(),
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &model.RateLimit{
+				ID:         spec.GetId().GetValue(),
+				Name:       spec.GetName().GetValue(),
+				Method:     spec.GetMethod().GetValue().GetValue(),
+				Priority:   spec.GetPriority().GetValue(),
+				Rule:       string(rule),
+				Revision:   spec.GetRevision().GetValue(),
+				Disable:    spec.GetDisable().GetValue(),
+				Valid:      true,
+				Metadata:   spec.GetMetadata(),
+				CreateTime: parseTime(spec.GetCtime().GetValue()),
+				ModifyTime: parseTime(spec.GetMtime().GetValue()),
+				EnableTime: parseTime(spec.GetEtime().GetValue()),
+			}, nil
+		},
+		update: func(s store.Store, item, _ *model.RateLimit) error {
+			return s.UpdateRateLimit(item)
+		},
+	}
+}
+
+func circuitBreakerResource() Resource {
+	return &resource[*model.CircuitBreakerRule, *apifault.CircuitBreakerRule]{
+		kind:      dataset.CircuitBreakerRules(),
+		spec:      func() *apifault.CircuitBreakerRule { return &apifault.CircuitBreakerRule{} },
+		namespace: func(spec *apifault.CircuitBreakerRule) string { return spec.GetNamespace() },
+		toSpec: func(_ *dumpContext, item *model.CircuitBreakerRule) (*apifault.CircuitBreakerRule, error) {
+			spec := &apifault.CircuitBreakerRule{}
+			if len(item.Rule) > 0 {
+				if err := json.Unmarshal([]byte(item.Rule), spec); err != nil {
+					return nil, err
+				}
+			} else {
+				spec.RuleMatcher = &apifault.RuleMatcher{
+					Source: &apifault.RuleMatcher_SourceService{
+						Service:   item.SrcService,
+						Namespace: item.SrcNamespace,
+					},
+					Destination: &apifault.RuleMatcher_DestinationService{
+						Service:   item.DstService,
+						Namespace: item.DstNamespace,
+						Method:    &apimodel.MatchString{Value: &wrappers.StringValue{Value: item.DstMethod}},
+					},
+				}
+			}
+			spec.Id = item.ID
+			spec.Name = item.Name
+			spec.Namespace = item.Namespace
+			spec.Description = item.Description
+			spec.Level = apifault.Level(item.Level)
+			spec.Enable = item.Enable
+			spec.Revision = item.Revision
+			spec.Ctime = formatTime(item.CreateTime)
+			spec.Mtime = formatTime(item.ModifyTime)
+			spec.Etime = formatEnableTime(item.EnableTime)
+			return spec, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apifault.CircuitBreakerRule) (*model.CircuitBreakerRule, error) {
+			rule, err := json.Marshal(&apifault.CircuitBreakerRule{
+				RuleMatcher:        spec.RuleMatcher,
+				ErrorConditions:    spec.ErrorConditions,
+				TriggerCondition:   spec.TriggerCondition,
+				MaxEjectionPercent: spec.MaxEjectionPercent,
+				RecoverCondition:   spec.RecoverCondition,
+				FaultDetectConfig:  spec.FaultDetectConfig,
+				FallbackConfig:     spec.FallbackConfig,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &model.CircuitBreakerRule{
+				ID:           spec.GetId(),
+				Name:         spec.GetName(),
+				Namespace:    spec.GetNamespace(),
+				Description:  spec.GetDescription(),
+				Level:        int(spec.GetLevel()),
+				SrcService:   spec.GetRuleMatcher().GetSource().GetService(),
+				SrcNamespace: spec.GetRuleMatcher().GetSource().GetNamespace(),
+				DstService:   spec.GetRuleMatcher().GetDestination().GetService(),
+				DstNamespace: spec.GetRuleMatcher().GetDestination().GetNamespace(),
+				DstMethod:    spec.GetRuleMatcher().GetDestination().GetMethod().GetValue().GetValue(),
+				Enable:       spec.GetEnable(),
+				Rule:         string(rule),
+				Revision:     spec.GetRevision(),
+				Valid:        true,
+				CreateTime:   parseTime(spec.GetCtime()),
+				ModifyTime:   parseTime(spec.GetMtime()),
+				EnableTime:   parseTime(spec.GetEtime()),
+			}, nil
+		},
+		update: func(s store.Store, item, _ *model.CircuitBreakerRule) error {
+			return s.UpdateCircuitBreakerRule(item)
+		},
+	}
+}
+
+func faultDetectResource() Resource {
+	return &resource[*model.FaultDetectRule, *apifault.FaultDetectRule]{
+		kind:      dataset.FaultDetectRules(),
+		spec:      func() *apifault.FaultDetectRule { return &apifault.FaultDetectRule{} },
+		namespace: func(spec *apifault.FaultDetectRule) string { return spec.GetNamespace() },
+		toSpec: func(_ *dumpContext, item *model.FaultDetectRule) (*apifault.FaultDetectRule, error) {
+			spec := &apifault.FaultDetectRule{}
+			if len(item.Rule) > 0 {
+				if err := json.Unmarshal([]byte(item.Rule), spec); err != nil {
+					return nil, err
+				}
+			} else {
+				spec.TargetService = &apifault.FaultDetectRule_DestinationService{
+					Service:   item.DstService,
+					Namespace: item.DstNamespace,
+					Method:    &apimodel.MatchString{Value: &wrappers.StringValue{Value: item.DstMethod}},
+				}
+			}
+			spec.Id = item.ID
+			spec.Name = item.Name
+			spec.Namespace = item.Namespace
+			spec.Description = item.Description
+			spec.Revision = item.Revision
+			spec.Metadata = item.Metadata
+			spec.Ctime = formatTime(item.CreateTime)
+			spec.Mtime = formatTime(item.ModifyTime)
+			return spec, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apifault.FaultDetectRule) (*model.FaultDetectRule, error) {
+			rule, err := json.Marshal(&apifault.FaultDetectRule{
+				TargetService: spec.TargetService,
+				Interval:      spec.Interval,
+				Timeout:       spec.Timeout,
+				Port:          spec.Port,
+				Protocol:      spec.Protocol,
+				HttpConfig:    spec.HttpConfig,
+				TcpConfig:     spec.TcpConfig,
+				UdpConfig:     spec.UdpConfig,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &model.FaultDetectRule{
+				ID:           spec.GetId(),
+				Name:         spec.GetName(),
+				Namespace:    spec.GetNamespace(),
+				Description:  spec.GetDescription(),
+				DstService:   spec.GetTargetService().GetService(),
+				DstNamespace: spec.GetTargetService().GetNamespace(),
+				DstMethod:    spec.GetTargetService().GetMethod().GetValue().GetValue(),
+				Rule:         string(rule),
+				Revision:     spec.GetRevision(),
+				Metadata:     spec.GetMetadata(),
+				Valid:        true,
+				CreateTime:   parseTime(spec.GetCtime()),
+				ModifyTime:   parseTime(spec.GetMtime()),
+			}, nil
+		},
+		update: func(s store.Store, item, _ *model.FaultDetectRule) error {
+			return s.UpdateFaultDetectRule(item)
+		},
+	}
+}
+
+func serviceContractResource() Resource {
+	return &resource[*model.EnrichServiceContract, *apiservice.ServiceContract]{
+		kind:      dataset.ServiceContracts(),
+		spec:      func() *apiservice.ServiceContract { return &apiservice.ServiceContract{} },
+		namespace: func(spec *apiservice.ServiceContract) string { return spec.GetNamespace() },
+		toSpec: func(_ *dumpContext, item *model.EnrichServiceContract) (*apiservice.ServiceContract, error) {
+			spec := item.ToSpec()
+			sort.Slice(spec.Interfaces, func(i, j int) bool {
+				return spec.Interfaces[i].GetId() < spec.Interfaces[j].GetId()
+			})
+			return spec, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apiservice.ServiceContract) (*model.EnrichServiceContract, error) {
+			contract := &model.ServiceContract{
+				ID:         spec.GetId(),
+				Namespace:  spec.GetNamespace(),
+				Service:    spec.GetService(),
+				Type:       utils.DefaultString(spec.GetType(), spec.GetName()),
+				Protocol:   spec.GetProtocol(),
+				Version:    spec.GetVersion(),
+				Revision:   spec.GetRevision(),
+				Content:    spec.GetContent(),
+				Valid:      true,
+				CreateTime: parseTime(spec.GetCtime()),
+				ModifyTime: parseTime(spec.GetMtime()),
+			}
+			interfaces := make([]*model.InterfaceDescriptor, 0, len(spec.GetInterfaces()))
+			for _, descriptor := range spec.GetInterfaces() {
+				interfaces = append(interfaces, &model.InterfaceDescriptor{
+					ID:         descriptor.GetId(),
+					ContractID: contract.ID,
+					Namespace:  contract.Namespace,
+					Service:    contract.Service,
+					Protocol:   contract.Protocol,
+					Version:    contract.Version,
+					Type:       contract.Type,
+					Method:     descriptor.GetMethod(),
+					Path:       descriptor.GetPath(),
+					Content:    descriptor.GetContent(),
+					Revision:   descriptor.GetRevision(),
+					Source:     descriptor.GetSource(),
+					Valid:      true,
+					CreateTime: parseTime(descriptor.GetCtime()),
+					ModifyTime: parseTime(descriptor.GetMtime()),
+				})
+			}
+			return &model.EnrichServiceContract{ServiceContract: contract, Interfaces: interfaces}, nil
+		},
+		update: func(s store.Store, item, _ *model.EnrichServiceContract) error {
+			if err := s.UpdateServiceContract(item.ServiceContract); err != nil {
+				return err
+			}
+			if len(item.Interfaces) == 0 {
+				return nil
+			}
+			return s.AddServiceContractInterfaces(item)
+		},
+	}
+}
+
+func laneGroupResource() Resource {
+	return &resource[*model.LaneGroup, *apitraffic.LaneGroup]{
+		kind: dataset.LaneGroups(),
+		spec: func() *apitraffic.LaneGroup { return &apitraffic.LaneGroup{} },
+		id:   func(item *model.LaneGroup) string { return item.ID },
+		toSpec: func(_ *dumpContext, item *model.LaneGroup) (*apitraffic.LaneGroup, error) {
+			group, err := item.ToProto()
+			if err != nil {
+				return nil, err
+			}
+			sort.Slice(group.Proto.Rules, func(i, j int) bool {
+				return group.Proto.Rules[i].GetName() < group.Proto.Rules[j].GetName()
+			})
+			return group.Proto, nil
+		},
+		fromSpec: func(_ *loadContext, spec *apitraffic.LaneGroup) (*model.LaneGroup, error) {
+			item := &model.LaneGroup{}
+			if err := item.FromSpec(spec); err != nil {
+				return nil, err
+			}
+			item.Revision = utils.DefaultString(spec.GetRevision(), utils.NewUUID())
+			item.Valid = true
+			item.CreateTime = parseTime(spec.GetCtime())
+			item.ModifyTime = parseTime(spec.GetMtime())
+			for _, rule := range spec.GetRules() {
+				laneRule, ok := item.LaneRules[rule.GetId()]
+				if !ok {
+					continue
+				}
+				laneRule.Valid = true
+				laneRule.CreateTime = parseTime(rule.GetCtime())
+				laneRule.ModifyTime = parseTime(rule.GetMtime())
+				laneRule.EnableTime = parseTime(rule.GetEtime())
+			}
+			return item, nil
+		},
+		update: func(s store.Store, item, old *model.LaneGroup) error {
+			item.ID = old.ID
+			oldRules := make(map[string]*model.LaneRule, len(old.LaneRules))
+			for _, rule := range old.LaneRules {
+				oldRules[rule.Name] = rule
+			}
+			rules := make(map[string]*model.LaneRule, len(item.LaneRules))
+			for _, rule := range item.LaneRules {
+				// 同名的泳道规则沿用目标端的 ID
+				if prev, ok := oldRules[rule.Name]; ok {
+					rule.ID = prev.ID
+					rule.SetChangeEnable(prev.Enable != rule.Enable)
+				} else {
+					rule.SetAddFlag(true)
+					rule.SetChangeEnable(rule.Enable)
+				}
+				rules[rule.ID] = rule
+			}
+			item.LaneRules = rules
+			return dataset.DoTransaction(s, func(tx store.Tx) error {
+				return s.UpdateLaneGroup(tx, item)
+			})
+		},
+	}
+}
+
+// formatEnableTime 规则未启用过时启用时间为空
+func formatEnableTime(t time.Time) string {
+	if t.Year() > 2000 {
+		return formatTime(t)
+	}
+	return ""
+}
+
+func toSet(values []*wrappers.StringValue) map[string]struct{} {
+	ret := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		ret[value.GetValue()] = struct{}{}
+	}
+	return ret
+}
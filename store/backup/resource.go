@@ -0,0 +1,318 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/polarismesh/polaris/common/model"
+	commontime "github.com/polarismesh/polaris/common/time"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/dataset"
+)
+
+// Resource 一类需要备份的资源
+type Resource interface {
+	// Name 资源名称
+	Name() string
+	// global 是否为不属于任何命名空间的全局资源，设置了命名空间过滤时不处理全局资源
+	global() bool
+	// newSpec 创建资源对应的 protobuf 消息
+	newSpec() proto.Message
+	// dump 导出资源
+	dump(ctx *dumpContext) ([]proto.Message, error)
+	// load 将备份中的资源写入存储
+	load(ctx *loadContext, items []proto.Message) (*ResourceReport, error)
+}
+
+// resource 资源的存储模型 M 与 specification 中的消息 S 之间的转换，读写方式由 dataset 统一定义
+type resource[M any, S proto.Message] struct {
+	// kind 资源在存储中的读写方式，拉取的顺序即为恢复时的写入顺序，key 用于判断恢复时是否冲突
+	kind *dataset.Kind[M]
+	// spec 创建空的消息
+	spec func() S
+	// namespace 资源所属的命名空间，全局资源为空
+	namespace func(spec S) string
+	// id 资源的 ID，不为空时记录冲突记录在备份与目标端之间的 ID 映射，供引用该资源的其他资源转换 ID
+	id func(item M) string
+	// toSpec 将存储模型转换为消息
+	toSpec func(ctx *dumpContext, item M) (S, error)
+	// fromSpec 将消息转换为存储模型
+	fromSpec func(ctx *loadContext, spec S) (M, error)
+	// update 使用备份中的记录覆盖目标端的记录 old，为空表示该类资源不支持覆盖
+	update func(s store.Store, item, old M) error
+}
+
+// Name 资源名称
+func (r *resource[M, S]) Name() string {
+	return r.kind.Name()
+}
+
+func (r *resource[M, S]) global() bool {
+	return r.namespace == nil
+}
+
+func (r *resource[M, S]) newSpec() proto.Message {
+	return r.spec()
+}
+
+func (r *resource[M, S]) dump(ctx *dumpContext) ([]proto.Message, error) {
+	items, err := r.kind.List(ctx.store)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", r.Name(), err)
+	}
+	ret := make([]proto.Message, 0, len(items))
+	for i := range items {
+		spec, err := r.toSpec(ctx, items[i])
+		if err != nil {
+			return nil, fmt.Errorf("convert %s %s: %w", r.Name(), r.kind.Key(items[i]), err)
+		}
+		if !r.global() && !ctx.match(r.namespace(spec)) {
+			continue
+		}
+		ret = append(ret, spec)
+	}
+	return ret, nil
+}
+
+func (r *resource[M, S]) load(ctx *loadContext, items []proto.Message) (*ResourceReport, error) {
+	exists, err := r.listExists(ctx.store)
+	if err != nil {
+		return nil, fmt.Errorf("list %s from target store: %w", r.Name(), err)
+	}
+
+	report := &ResourceReport{Resource: r.Name()}
+	for i := range items {
+		spec := items[i].(S)
+		if !r.global() && !ctx.match(r.namespace(spec)) {
+			continue
+		}
+		report.Total++
+		item, err := r.fromSpec(ctx, spec)
+		if err != nil {
+			return nil, fmt.Errorf("convert %s: %w", r.Name(), err)
+		}
+		key := r.kind.Key(item)
+		old, ok := exists[key]
+		if ok && r.id != nil {
+			ctx.mapID(r.Name(), r.id(item), r.id(old))
+		}
+		switch {
+		case !ok:
+			if err := r.kind.Create(ctx.store, item); err != nil {
+				return nil, fmt.Errorf("create %s %s: %w", r.Name(), key, err)
+			}
+			report.Created++
+		case ctx.conflict == ConflictOverwrite && r.update != nil:
+			if err := r.update(ctx.store, item, old); err != nil {
+				return nil, fmt.Errorf("update %s %s: %w", r.Name(), key, err)
+			}
+			report.Updated++
+		default:
+			report.Skipped++
+			continue
+		}
+		if ctx.restorer != nil {
+			if err := ctx.restorer.RestoreTimestamps(item); err != nil {
+				return nil, fmt.Errorf("restore %s %s timestamps: %w", r.Name(), key, err)
+			}
+		}
+	}
+	return report, nil
+}
+
+func (r *resource[M, S]) listExists(s store.Store) (map[string]M, error) {
+	items, err := r.kind.List(s)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]M, len(items))
+	for i := range items {
+		ret[r.kind.Key(items[i])] = items[i]
+	}
+	return ret, nil
+}
+
+// DefaultResources 按照 dataset.Resources 的依赖顺序返回全部需要备份的资源
+func DefaultResources() []Resource {
+	return []Resource{
+		namespaceResource(),
+		serviceResource(),
+		serviceAliasResource(),
+		instanceResource(),
+		routingConfigResource(),
+		routeRuleResource(),
+		rateLimitResource(),
+		circuitBreakerResource(),
+		faultDetectResource(),
+		serviceContractResource(),
+		laneGroupResource(),
+		configGroupResource(),
+		configFileResource(),
+		configReleaseResource(),
+		configReleaseHistoryResource(),
+		configTemplateResource(),
+		grayResource(),
+		userResource(),
+		userGroupResource(),
+		strategyResource(),
+		roleResource(),
+	}
+}
+
+// namespaceFilter 命名空间过滤条件
+type namespaceFilter map[string]struct{}
+
+func newNamespaceFilter(namespaces []string) namespaceFilter {
+	filter := namespaceFilter{}
+	for _, ns := range namespaces {
+		if ns != "" {
+			filter[ns] = struct{}{}
+		}
+	}
+	return filter
+}
+
+// filtered 是否设置了过滤条件
+func (f namespaceFilter) filtered() bool {
+	return len(f) > 0
+}
+
+func (f namespaceFilter) match(namespace string) bool {
+	if len(f) == 0 {
+		return true
+	}
+	_, ok := f[namespace]
+	return ok
+}
+
+// dumpContext 一次备份过程中共享的数据
+type dumpContext struct {
+	namespaceFilter
+	// store 绑定在同一个只读事务上的读视图
+	store store.Store
+	// services 服务 ID -> 服务，用于实例、别名找到所属服务的名称
+	services map[string]*model.Service
+}
+
+func newDumpContext(view store.Store, namespaces []string) *dumpContext {
+	return &dumpContext{
+		namespaceFilter: newNamespaceFilter(namespaces),
+		store:           view,
+	}
+}
+
+// serviceByID 根据服务 ID 查找服务，首次调用时拉取全部服务
+func (c *dumpContext) serviceByID(id string) (*model.Service, error) {
+	if c.services == nil {
+		services, err := c.store.GetMoreServices(time.Time{}, true, false, false)
+		if err != nil {
+			return nil, err
+		}
+		c.services = services
+	}
+	svc, ok := c.services[id]
+	if !ok || !svc.Valid {
+		return nil, fmt.Errorf("service %s not found", id)
+	}
+	return svc, nil
+}
+
+// loadContext 一次恢复过程中共享的数据
+type loadContext struct {
+	namespaceFilter
+	store    store.Store
+	conflict ConflictPolicy
+	restorer store.MigrateStore
+	// serviceIDs 命名空间/服务名 -> 目标端的服务 ID
+	serviceIDs map[string]string
+	// ids 资源名称 -> 备份中的 ID -> 目标端已存在记录的 ID
+	ids map[string]map[string]string
+}
+
+func newLoadContext(s store.Store, opt *RestoreOption) *loadContext {
+	ctx := &loadContext{
+		namespaceFilter: newNamespaceFilter(opt.Namespaces),
+		store:           s,
+		conflict:        opt.Conflict,
+		serviceIDs:      map[string]string{},
+		ids:             map[string]map[string]string{},
+	}
+	if restorer, ok := s.(store.MigrateStore); ok && opt.RestoreTimestamps {
+		ctx.restorer = restorer
+	}
+	return ctx
+}
+
+// serviceID 查找服务在目标端的 ID，服务可能在恢复时被覆盖为目标端原有的 ID
+func (c *loadContext) serviceID(namespace, name string) (string, error) {
+	key := namespace + "/" + name
+	if id, ok := c.serviceIDs[key]; ok {
+		return id, nil
+	}
+	svc, err := c.store.GetService(name, namespace)
+	if err != nil {
+		return "", err
+	}
+	if svc == nil {
+		return "", fmt.Errorf("service %s not found", key)
+	}
+	c.serviceIDs[key] = svc.ID
+	return svc.ID, nil
+}
+
+func (c *loadContext) mapID(resource, id, target string) {
+	if id == target {
+		return
+	}
+	if _, ok := c.ids[resource]; !ok {
+		c.ids[resource] = map[string]string{}
+	}
+	c.ids[resource][id] = target
+}
+
+// targetID 返回备份中的资源 ID 在目标端对应的 ID，目标端与备份冲突时两者可能不同
+func (c *loadContext) targetID(resource, id string) string {
+	if target, ok := c.ids[resource][id]; ok {
+		return target
+	}
+	return id
+}
+
+// parseTime 解析消息中 commontime.Time2String 格式的时间，解析失败返回零值
+func parseTime(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", s, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// formatTime 与 parseTime 对应，零值输出为空
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return commontime.Time2String(t)
+}
@@ -19,6 +19,7 @@ package boltdb
 
 import (
 	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -39,14 +40,12 @@ func teardown() {
 }
 
 func TestAdminStore_BatchCleanDeletedClients(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 
 	store := &adminStore{handler: handler}
 	cStore := &clientStore{handler: handler}
@@ -98,14 +97,12 @@ func TestAdminStore_BatchCleanDeletedClients(t *testing.T) {
 }
 
 func TestAdminStore_BatchCleanDeletedInstances(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 
 	store := &adminStore{handler: handler}
 	sStore := &serviceStore{handler: handler}
@@ -222,14 +219,12 @@ func TestAdminStore_ListLeaderElections(t *testing.T) {
 }
 
 func TestAdminStore_getUnHealthyInstancesBefore(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 
 	store := &adminStore{handler: handler}
 	sStore := &serviceStore{handler: handler}
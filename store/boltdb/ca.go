@@ -0,0 +1,108 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package boltdb
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+var _ store.CAStore = (*caStore)(nil)
+
+const (
+	tblCAKeyPair string = "CAKeyPair"
+
+	CAKeyPairFieldRetireTime string = "RetireTime"
+	CAKeyPairFieldModifyTime string = "ModifyTime"
+)
+
+type caStore struct {
+	handler BoltHandler
+}
+
+// AddCAKeyPair 新增根证书
+func (c *caStore) AddCAKeyPair(pair *model.CAKeyPair) error {
+	key := strconv.FormatUint(pair.Generation, 10)
+	return c.handler.Execute(true, func(tx *bolt.Tx) error {
+		values := make(map[string]interface{})
+		if err := loadValues(tx, tblCAKeyPair, []string{key}, &model.CAKeyPair{}, values); err != nil {
+			log.Errorf("[Store][boltdb] load ca key pair(%s) err: %s", key, err.Error())
+			return store.Error(err)
+		}
+		if len(values) != 0 {
+			return store.NewStatusError(store.DuplicateEntryErr, "ca key pair generation "+key+" already exists")
+		}
+		tN := time.Now()
+		pair.CreateTime = tN
+		pair.ModifyTime = tN
+		if err := saveValue(tx, tblCAKeyPair, key, pair); err != nil {
+			log.Errorf("[Store][boltdb] save ca key pair(%s) err: %s", key, err.Error())
+			return store.Error(err)
+		}
+		return nil
+	})
+}
+
+// RetireCAKeyPair 设置根证书的退役时间
+func (c *caStore) RetireCAKeyPair(generation uint64, retireTime time.Time) error {
+	properties := map[string]interface{}{
+		CAKeyPairFieldRetireTime: retireTime,
+		CAKeyPairFieldModifyTime: time.Now(),
+	}
+	if err := c.handler.UpdateValue(tblCAKeyPair, strconv.FormatUint(generation, 10), properties); err != nil {
+		log.Errorf("[Store][boltdb] retire ca key pair(%d) err: %s", generation, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetCAKeyPairs 获取全部根证书
+func (c *caStore) GetCAKeyPairs() ([]*model.CAKeyPair, error) {
+	values, err := c.handler.LoadValuesAll(tblCAKeyPair, &model.CAKeyPair{})
+	if err != nil {
+		log.Errorf("[Store][boltdb] get ca key pairs err: %s", err.Error())
+		return nil, store.Error(err)
+	}
+	pairs := make([]*model.CAKeyPair, 0, len(values))
+	for _, v := range values {
+		pair := v.(*model.CAKeyPair)
+		// 零值时间经过编解码后会变成 1970-01-01，这里还原成零值
+		if pair.RetireTime.Unix() <= 0 {
+			pair.RetireTime = time.Time{}
+		}
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Generation < pairs[j].Generation
+	})
+	return pairs, nil
+}
+
+// DeleteCAKeyPair 删除根证书
+func (c *caStore) DeleteCAKeyPair(generation uint64) error {
+	if err := c.handler.DeleteValues(tblCAKeyPair, []string{strconv.FormatUint(generation, 10)}); err != nil {
+		log.Errorf("[Store][boltdb] delete ca key pair(%d) err: %s", generation, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package boltdb
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+func TestCAStore(t *testing.T) {
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+
+	caStore := &caStore{handler: handler}
+	notBefore := time.Now().Truncate(time.Second)
+	for _, generation := range []uint64{2, 1} {
+		err := caStore.AddCAKeyPair(&model.CAKeyPair{
+			Generation: generation,
+			Cert:       "cert",
+			KeyCipher:  "cipher",
+			CryptoAlgo: "AES",
+			NotBefore:  notBefore,
+			NotAfter:   notBefore.Add(time.Hour),
+		})
+		assert.NoError(t, err)
+	}
+
+	err = caStore.AddCAKeyPair(&model.CAKeyPair{Generation: 1})
+	assert.Equal(t, store.DuplicateEntryErr, store.Code(err))
+
+	retireTime := notBefore.Add(time.Minute)
+	assert.NoError(t, caStore.RetireCAKeyPair(1, retireTime))
+
+	pairs, err := caStore.GetCAKeyPairs()
+	assert.NoError(t, err)
+	assert.Len(t, pairs, 2)
+	assert.Equal(t, uint64(1), pairs[0].Generation)
+	assert.True(t, pairs[0].Retired())
+	assert.True(t, retireTime.Equal(pairs[0].RetireTime))
+	assert.Equal(t, "cipher", pairs[1].KeyCipher)
+	assert.False(t, pairs[1].Retired())
+	assert.True(t, notBefore.Equal(pairs[1].NotBefore))
+
+	assert.NoError(t, caStore.DeleteCAKeyPair(1))
+	pairs, err = caStore.GetCAKeyPairs()
+	assert.NoError(t, err)
+	assert.Len(t, pairs, 1)
+}
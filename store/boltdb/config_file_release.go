@@ -361,6 +361,14 @@ func (cfr *configFileReleaseStore) InactiveConfigFileReleaseTx(tx store.Tx, rele
 	return updateValue(dbTx, tblConfigFileRelease, release.ReleaseKey(), properties)
 }
 
+// RestoreReleaseVersionTx 回写配置发布的版本号
+func (cfr *configFileReleaseStore) RestoreReleaseVersionTx(tx store.Tx, release *model.ConfigFileRelease) error {
+	dbTx := tx.GetDelegateTx().(*bolt.Tx)
+	properties := make(map[string]interface{})
+	properties[FileReleaseFieldVersion] = release.Version
+	return updateValue(dbTx, tblConfigFileRelease, release.ReleaseKey(), properties)
+}
+
 func (cfr *configFileReleaseStore) inactiveConfigFileRelease(tx *bolt.Tx,
 	release *model.ConfigFileRelease) (uint64, error) {
 
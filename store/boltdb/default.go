@@ -82,6 +82,11 @@ type boltStore struct {
 	*configFileTemplateStore
 
 	*grayStore
+	*caStore
+	*serviceAccessStore
+	*heartbeatStore
+	*healthHistoryStore
+	*healthSuspensionStore
 
 	// adminStore store
 	*adminStore
@@ -192,23 +197,30 @@ func (m *boltStore) initNamingStoreData() error {
 }
 
 func (m *boltStore) newStore() error {
-	var err error
-
-	m.l5Store = &l5Store{handler: m.handler}
-	if err = m.l5Store.InitL5Data(); err != nil {
+	m.bindStores()
+	if err := m.l5Store.InitL5Data(); err != nil {
 		return err
 	}
+	return m.namespaceStore.InitData()
+}
+
+// bindStores 使用 handler 初始化各个子类
+func (m *boltStore) bindStores() {
+	m.l5Store = &l5Store{handler: m.handler}
 	m.namespaceStore = &namespaceStore{handler: m.handler}
-	if err = m.namespaceStore.InitData(); err != nil {
-		return err
-	}
 	m.clientStore = &clientStore{handler: m.handler}
 	m.grayStore = &grayStore{handler: m.handler}
+	m.caStore = &caStore{handler: m.handler}
+	m.serviceAccessStore = &serviceAccessStore{handler: m.handler}
+	// 内部数据的写入不受存储只读限制
+	internal := internalHandler(m.handler)
+	m.heartbeatStore = &heartbeatStore{handler: internal}
+	m.healthHistoryStore = &healthHistoryStore{handler: internal}
+	m.healthSuspensionStore = &healthSuspensionStore{handler: internal}
 	m.newDiscoverModuleStore()
 	m.newAuthModuleStore()
 	m.newConfigModuleStore()
 	m.newMaintainModuleStore()
-	return nil
 }
 
 func (m *boltStore) newDiscoverModuleStore() {
@@ -239,7 +251,7 @@ func (m *boltStore) newConfigModuleStore() {
 }
 
 func (m *boltStore) newMaintainModuleStore() {
-	m.adminStore = &adminStore{handler: m.handler, leMap: make(map[string]bool)}
+	m.adminStore = &adminStore{handler: internalHandler(m.handler), leMap: make(map[string]bool)}
 }
 
 // Destroy store
@@ -258,6 +270,9 @@ func (m *boltStore) CreateTransaction() (store.Transaction, error) {
 
 // StartTx starting transactions
 func (m *boltStore) StartTx() (store.Tx, error) {
+	if err := store.CheckWritable(); err != nil {
+		return nil, err
+	}
 	return m.handler.StartTx()
 }
 
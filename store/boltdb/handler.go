@@ -115,6 +115,16 @@ func NewBoltHandler(config *BoltConfig) (BoltHandler, error) {
 
 type boltHandler struct {
 	db *bolt.DB
+	// internal 为 true 时不受存储只读限制，供选主、心跳等内部数据的存储使用
+	internal bool
+}
+
+// internalHandler 创建共享同一个数据库、不受存储只读限制的 BoltHandler
+func internalHandler(handler BoltHandler) BoltHandler {
+	if b, ok := handler.(*boltHandler); ok {
+		return &boltHandler{db: b.db, internal: true}
+	}
+	return handler
 }
 
 func openBoltDB(path string) (*bolt.DB, error) {
@@ -125,7 +135,7 @@ func openBoltDB(path string) (*bolt.DB, error) {
 
 // SaveValue insert data object, each data object should be identified by unique key
 func (b *boltHandler) SaveValue(typ string, key string, value interface{}) error {
-	return b.db.Update(func(tx *bolt.Tx) error {
+	return b.update(func(tx *bolt.Tx) error {
 		return saveValue(tx, typ, key, value)
 	})
 }
@@ -351,32 +361,36 @@ func (b *boltHandler) IterateFields(typ string, field string, typObject interfac
 		return nil
 	}
 	return b.db.View(func(tx *bolt.Tx) error {
-		typeBucket := tx.Bucket([]byte(typ))
-		if typeBucket == nil {
-			return nil
+		return iterateFields(tx, typ, field, typObject, filter)
+	})
+}
+
+func iterateFields(tx *bolt.Tx, typ string, field string, typObject interface{}, filter func(interface{})) error {
+	typeBucket := tx.Bucket([]byte(typ))
+	if typeBucket == nil {
+		return nil
+	}
+	keys, err := getKeys(typeBucket)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		bucket := typeBucket.Bucket([]byte(key))
+		if bucket == nil {
+			log.Warnf("[BlobStore] bucket not found for key %s, type %s", key, typ)
+			continue
 		}
-		keys, err := getKeys(typeBucket)
+		var fieldObj interface{}
+		fieldObj, err = getFieldObject(bucket, typObject, field)
 		if err != nil {
 			return err
 		}
-		if len(keys) == 0 {
-			return nil
-		}
-		for _, key := range keys {
-			bucket := typeBucket.Bucket([]byte(key))
-			if bucket == nil {
-				log.Warnf("[BlobStore] bucket not found for key %s, type %s", key, typ)
-				continue
-			}
-			var fieldObj interface{}
-			fieldObj, err = getFieldObject(bucket, typObject, field)
-			if err != nil {
-				return err
-			}
-			filter(fieldObj)
-		}
-		return nil
-	})
+		filter(fieldObj)
+	}
+	return nil
 }
 
 // Close boltdb
@@ -392,7 +406,7 @@ func (b *boltHandler) DeleteValues(typ string, keys []string) error {
 	if len(keys) == 0 {
 		return nil
 	}
-	return b.db.Update(func(tx *bolt.Tx) error {
+	return b.update(func(tx *bolt.Tx) error {
 		return deleteValues(tx, typ, keys)
 	})
 }
@@ -505,7 +519,7 @@ func countValues(tx *bolt.Tx, typ string) (int, error) {
 
 // UpdateValue update properties of data object
 func (b *boltHandler) UpdateValue(typ string, key string, properties map[string]interface{}) error {
-	return b.db.Update(func(tx *bolt.Tx) error {
+	return b.update(func(tx *bolt.Tx) error {
 		return updateValue(tx, typ, key, properties)
 	})
 }
@@ -567,43 +581,58 @@ func updateValue(tx *bolt.Tx, typ string, key string, properties map[string]inte
 func (b *boltHandler) LoadValuesAll(typ string, typObject interface{}) (map[string]interface{}, error) {
 	values := make(map[string]interface{})
 	err := b.db.View(func(tx *bolt.Tx) error {
-		typeBucket := tx.Bucket([]byte(typ))
-		if typeBucket == nil {
-			return nil
+		return loadValuesAll(tx, typ, typObject, values)
+	})
+	return values, err
+}
+
+func loadValuesAll(tx *bolt.Tx, typ string, typObject interface{}, values map[string]interface{}) error {
+	typeBucket := tx.Bucket([]byte(typ))
+	if typeBucket == nil {
+		return nil
+	}
+	keys, err := getKeys(typeBucket)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		bucket := typeBucket.Bucket([]byte(key))
+		if bucket == nil {
+			log.Warnf("[BlobStore] bucket not found for key %s, type %s", key, typ)
+			continue
 		}
-		keys, err := getKeys(typeBucket)
+		var targetObj interface{}
+		targetObj, err = deserializeObject(bucket, typObject)
 		if err != nil {
 			return err
 		}
-		if len(keys) == 0 {
-			return nil
-		}
-		for _, key := range keys {
-			bucket := typeBucket.Bucket([]byte(key))
-			if bucket == nil {
-				log.Warnf("[BlobStore] bucket not found for key %s, type %s", key, typ)
-				continue
-			}
-			var targetObj interface{}
-			targetObj, err = deserializeObject(bucket, typObject)
-			if err != nil {
-				return err
-			}
-			values[key] = targetObj
-		}
-		return nil
-	})
-	return values, err
+		values[key] = targetObj
+	}
+	return nil
 }
 
 // Execute execute scripts directly
 func (b *boltHandler) Execute(writable bool, process func(tx *bolt.Tx) error) error {
 	if writable {
-		return b.db.Update(process)
+		return b.update(process)
 	}
 	return b.db.View(process)
 }
 
+// update 执行写事务，存储只读时拒绝写入
+func (b *boltHandler) update(process func(tx *bolt.Tx) error) error {
+	if b.internal {
+		return b.db.Update(process)
+	}
+	if err := store.CheckWritable(); err != nil {
+		return err
+	}
+	return b.db.Update(process)
+}
+
 // StartTx start a new tx
 func (b *boltHandler) StartTx() (store.Tx, error) {
 	tx, err := b.db.Begin(true)
@@ -31,6 +31,7 @@ import (
 
 	"github.com/polarismesh/polaris/common/model"
 	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	"github.com/polarismesh/polaris/store"
 )
 
 func CreateTableDBHandlerAndRun(t *testing.T, tableName string, tf func(t *testing.T, handler BoltHandler)) {
@@ -52,7 +53,8 @@ func CreateTableDBHandlerAndRun(t *testing.T, tableName string, tf func(t *testi
 }
 
 func TestBoltHandler_SaveNamespace(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -72,8 +74,29 @@ func TestBoltHandler_SaveNamespace(t *testing.T) {
 	}
 }
 
+func TestBoltHandler_ReadOnly(t *testing.T) {
+	CreateTableDBHandlerAndRun(t, "test_read_only", func(t *testing.T, handler BoltHandler) {
+		store.SetReadOnly(true)
+		defer store.SetReadOnly(false)
+
+		nsValue := &model.Namespace{Name: "Test", Valid: true}
+		err := handler.SaveValue(tblNameNamespace, nsValue.Name, nsValue)
+		if store.Code(err) != store.StoreUnavailable {
+			t.Fatalf("expect store unavailable, got %v", err)
+		}
+		values, err := handler.LoadValues(tblNameNamespace, []string{nsValue.Name}, &model.Namespace{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(values) != 0 {
+			t.Fatal("namespace should not be saved")
+		}
+	})
+}
+
 func TestBoltHandler_LoadNamespace(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -87,6 +110,9 @@ func TestBoltHandler_LoadNamespace(t *testing.T) {
 		CreateTime: time.Now(),
 		ModifyTime: time.Now(),
 	}
+	if err := handler.SaveValue(tblNameNamespace, nsValue.Name, nsValue); err != nil {
+		t.Fatal(err)
+	}
 	nsValues, err := handler.LoadValues(tblNameNamespace, []string{nsValue.Name}, &Namespace{})
 	if err != nil {
 		t.Fatal(err)
@@ -100,7 +126,8 @@ func TestBoltHandler_LoadNamespace(t *testing.T) {
 }
 
 func TestBoltHandler_DeleteNamespace(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -115,7 +142,8 @@ func TestBoltHandler_DeleteNamespace(t *testing.T) {
 }
 
 func TestBoltHandler_Service(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -161,7 +189,8 @@ func TestBoltHandler_Service(t *testing.T) {
 }
 
 func TestBoltHandler_Location(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -200,10 +229,7 @@ const (
 )
 
 func TestBoltHandler_CountValues(t *testing.T) {
-
-	// 删除之前测试的遗留文件
-	_ = os.RemoveAll("./table.bolt")
-
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
 	count := 5
 	var idToServices = make(map[string]*model.Service)
 	var ids = make([]string, 0)
@@ -223,14 +249,11 @@ func TestBoltHandler_CountValues(t *testing.T) {
 		idToServices[svcValue.ID] = svcValue
 		ids = append(ids, svcValue.ID)
 	}
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	for id, svc := range idToServices {
 		err = handler.SaveValue(tblService, id, svc)
 		if err != nil {
@@ -275,6 +298,7 @@ func TestBoltHandler_CountValues(t *testing.T) {
 }
 
 func TestBoltHandler_LoadValuesByFilter(t *testing.T) {
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
 	count := 5
 	var idToServices = make(map[string]*model.Service)
 	var ids = make([]string, 0)
@@ -294,14 +318,11 @@ func TestBoltHandler_LoadValuesByFilter(t *testing.T) {
 		idToServices[svcValue.ID] = svcValue
 		ids = append(ids, svcValue.ID)
 	}
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	for id, svc := range idToServices {
 		err = handler.SaveValue(tblService, id, svc)
 		if err != nil {
@@ -326,6 +347,7 @@ func TestBoltHandler_LoadValuesByFilter(t *testing.T) {
 }
 
 func TestBoltHandler_IterateFields(t *testing.T) {
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
 	count := 5
 	var idToServices = make(map[string]*model.Service)
 	var ids = make([]string, 0)
@@ -345,14 +367,11 @@ func TestBoltHandler_IterateFields(t *testing.T) {
 		idToServices[svcValue.ID] = svcValue
 		ids = append(ids, svcValue.ID)
 	}
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	for id, svc := range idToServices {
 		err = handler.SaveValue(tblService, id, svc)
 		if err != nil {
@@ -376,6 +395,7 @@ func TestBoltHandler_IterateFields(t *testing.T) {
 }
 
 func TestBoltHandler_UpdateValue(t *testing.T) {
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
 	count := 5
 	var idToServices = make(map[string]*model.Service)
 	var ids = make([]string, 0)
@@ -395,14 +415,11 @@ func TestBoltHandler_UpdateValue(t *testing.T) {
 		idToServices[svcValue.ID] = svcValue
 		ids = append(ids, svcValue.ID)
 	}
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	for id, svc := range idToServices {
 		err = handler.SaveValue(tblService, id, svc)
 		if err != nil {
@@ -0,0 +1,125 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+var _ store.InstanceHealthHistoryStore = (*healthHistoryStore)(nil)
+
+const (
+	tblInstanceHealthHistory string = "instance_health_history"
+)
+
+type healthHistoryStore struct {
+	handler BoltHandler
+}
+
+// healthHistoryData 实例的变更记录以 json 数组的形式保存，按照时间倒序排列
+type healthHistoryData struct {
+	InstanceID  string
+	Transitions string
+}
+
+// BatchAppendInstanceHealthHistory 在一个事务中追加一批变更记录，并清理这些实例超出 maxSize 的旧记录
+func (h *healthHistoryStore) BatchAppendInstanceHealthHistory(records []*model.InstanceHealthTransition,
+	maxSize int) error {
+	if len(records) == 0 {
+		return nil
+	}
+	// 同一个实例的新记录按照时间倒序排列在前面
+	appended := make(map[string][]*model.InstanceHealthTransition, len(records))
+	instanceIDs := make([]string, 0, len(records))
+	for _, record := range records {
+		if _, ok := appended[record.InstanceID]; !ok {
+			instanceIDs = append(instanceIDs, record.InstanceID)
+		}
+		appended[record.InstanceID] = append([]*model.InstanceHealthTransition{record},
+			appended[record.InstanceID]...)
+	}
+	err := h.handler.Execute(true, func(tx *bolt.Tx) error {
+		values := map[string]interface{}{}
+		if err := loadValues(tx, tblInstanceHealthHistory, instanceIDs, &healthHistoryData{}, values); err != nil {
+			return err
+		}
+		for _, instanceID := range instanceIDs {
+			transitions := appended[instanceID]
+			if v, ok := values[instanceID]; ok {
+				var exist []*model.InstanceHealthTransition
+				if err := json.Unmarshal([]byte(v.(*healthHistoryData).Transitions), &exist); err != nil {
+					return err
+				}
+				transitions = append(transitions, exist...)
+			}
+			if maxSize > 0 && len(transitions) > maxSize {
+				transitions = transitions[:maxSize]
+			}
+			if err := saveValue(tx, tblInstanceHealthHistory, instanceID, &healthHistoryData{
+				InstanceID:  instanceID,
+				Transitions: utils.MustJson(transitions),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("[Store][boltdb] batch append %d instance health history err: %s", len(records), err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetInstanceHealthHistory 查询实例最近的变更记录
+func (h *healthHistoryStore) GetInstanceHealthHistory(instanceID string,
+	limit int) ([]*model.InstanceHealthTransition, error) {
+	values, err := h.handler.LoadValues(tblInstanceHealthHistory, []string{instanceID}, &healthHistoryData{})
+	if err != nil {
+		log.Errorf("[Store][boltdb] get instance(%s) health history err: %s", instanceID, err.Error())
+		return nil, store.Error(err)
+	}
+	v, ok := values[instanceID]
+	if !ok {
+		return nil, nil
+	}
+	var ret []*model.InstanceHealthTransition
+	if err := json.Unmarshal([]byte(v.(*healthHistoryData).Transitions), &ret); err != nil {
+		log.Errorf("[Store][boltdb] unmarshal instance(%s) health history err: %s", instanceID, err.Error())
+		return nil, store.Error(err)
+	}
+	if limit > 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret, nil
+}
+
+// DeleteInstanceHealthHistory 删除实例的所有变更记录
+func (h *healthHistoryStore) DeleteInstanceHealthHistory(instanceID string) error {
+	if err := h.handler.DeleteValues(tblInstanceHealthHistory, []string{instanceID}); err != nil {
+		log.Errorf("[Store][boltdb] delete instance(%s) health history err: %s", instanceID, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
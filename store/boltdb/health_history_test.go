@@ -0,0 +1,80 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+func TestHealthHistoryStore(t *testing.T) {
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+
+	historyStore := &healthHistoryStore{handler: handler}
+	start := time.Unix(1000, 0)
+	newRecord := func(instanceID string, i int) *model.InstanceHealthTransition {
+		return &model.InstanceHealthTransition{
+			InstanceID: instanceID,
+			Healthy:    i%2 == 1,
+			Reason:     model.HealthReasonHeartbeatExpired,
+			Server:     "127.0.0.1",
+			Time:       start.Add(time.Duration(i) * time.Second),
+		}
+	}
+	assert.NoError(t, historyStore.BatchAppendInstanceHealthHistory([]*model.InstanceHealthTransition{
+		newRecord("ins-1", 0), newRecord("ins-3", 0), newRecord("ins-1", 1),
+	}, 3))
+	assert.NoError(t, historyStore.BatchAppendInstanceHealthHistory([]*model.InstanceHealthTransition{
+		newRecord("ins-1", 2), newRecord("ins-1", 3), newRecord("ins-1", 4),
+	}, 3))
+
+	// 只保留最近的 3 条，按照时间倒序返回
+	records, err := historyStore.GetInstanceHealthHistory("ins-1", 10)
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.True(t, records[0].Time.Equal(start.Add(4*time.Second)))
+	assert.True(t, records[2].Time.Equal(start.Add(2*time.Second)))
+	assert.False(t, records[0].Healthy)
+
+	records, err = historyStore.GetInstanceHealthHistory("ins-1", 1)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	records, err = historyStore.GetInstanceHealthHistory("ins-3", 10)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	records, err = historyStore.GetInstanceHealthHistory("ins-2", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+
+	assert.NoError(t, historyStore.DeleteInstanceHealthHistory("ins-1"))
+	records, err = historyStore.GetInstanceHealthHistory("ins-1", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+}
@@ -0,0 +1,97 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+var _ store.HealthCheckSuspensionStore = (*healthSuspensionStore)(nil)
+
+const (
+	tblHealthCheckSuspension string = "health_check_suspension"
+)
+
+type healthSuspensionStore struct {
+	handler BoltHandler
+}
+
+// healthSuspensionData 暂停范围以 json 的形式保存
+type healthSuspensionData struct {
+	ID         string
+	Selector   string
+	Comment    string
+	CreateTime time.Time
+	ExpireTime time.Time
+}
+
+// AddHealthCheckSuspension 新增暂停规则
+func (h *healthSuspensionStore) AddHealthCheckSuspension(suspension *model.HealthCheckSuspension) error {
+	data := &healthSuspensionData{
+		ID:         suspension.ID,
+		Selector:   utils.MustJson(suspension.Selector),
+		Comment:    suspension.Comment,
+		CreateTime: suspension.CreateTime,
+		ExpireTime: suspension.ExpireTime,
+	}
+	if err := h.handler.SaveValue(tblHealthCheckSuspension, suspension.ID, data); err != nil {
+		log.Errorf("[Store][boltdb] add health check suspension(%s) err: %s", suspension.ID, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// DeleteHealthCheckSuspension 删除暂停规则
+func (h *healthSuspensionStore) DeleteHealthCheckSuspension(id string) error {
+	if err := h.handler.DeleteValues(tblHealthCheckSuspension, []string{id}); err != nil {
+		log.Errorf("[Store][boltdb] delete health check suspension(%s) err: %s", id, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetHealthCheckSuspensions 查询所有的暂停规则
+func (h *healthSuspensionStore) GetHealthCheckSuspensions() ([]*model.HealthCheckSuspension, error) {
+	values, err := h.handler.LoadValuesAll(tblHealthCheckSuspension, &healthSuspensionData{})
+	if err != nil {
+		log.Errorf("[Store][boltdb] get health check suspensions err: %s", err.Error())
+		return nil, store.Error(err)
+	}
+	ret := make([]*model.HealthCheckSuspension, 0, len(values))
+	for _, v := range values {
+		data := v.(*healthSuspensionData)
+		suspension := &model.HealthCheckSuspension{
+			ID:         data.ID,
+			Selector:   &model.HealthCheckSuspensionSelector{},
+			Comment:    data.Comment,
+			CreateTime: data.CreateTime,
+			ExpireTime: data.ExpireTime,
+		}
+		if err := json.Unmarshal([]byte(data.Selector), suspension.Selector); err != nil {
+			log.Errorf("[Store][boltdb] unmarshal health check suspension(%s) err: %s", data.ID, err.Error())
+			return nil, store.Error(err)
+		}
+		ret = append(ret, suspension)
+	}
+	return ret, nil
+}
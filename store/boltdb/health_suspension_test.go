@@ -0,0 +1,61 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+func TestHealthSuspensionStore(t *testing.T) {
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+
+	suspensionStore := &healthSuspensionStore{handler: handler}
+	tN := time.Now()
+	err = suspensionStore.AddHealthCheckSuspension(&model.HealthCheckSuspension{
+		ID:         "suspension-1",
+		Selector:   &model.HealthCheckSuspensionSelector{Namespace: "default", Zone: "zone-1"},
+		Comment:    "zone-1 network partition",
+		CreateTime: tN,
+		ExpireTime: tN.Add(time.Hour),
+	})
+	assert.NoError(t, err)
+
+	suspensions, err := suspensionStore.GetHealthCheckSuspensions()
+	assert.NoError(t, err)
+	assert.Len(t, suspensions, 1)
+	assert.Equal(t, "suspension-1", suspensions[0].ID)
+	assert.Equal(t, &model.HealthCheckSuspensionSelector{Namespace: "default", Zone: "zone-1"},
+		suspensions[0].Selector)
+	assert.True(t, suspensions[0].ExpireTime.Equal(tN.Add(time.Hour)))
+
+	assert.NoError(t, suspensionStore.DeleteHealthCheckSuspension("suspension-1"))
+	suspensions, err = suspensionStore.GetHealthCheckSuspensions()
+	assert.NoError(t, err)
+	assert.Empty(t, suspensions)
+}
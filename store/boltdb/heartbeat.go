@@ -0,0 +1,115 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+var _ store.HeartbeatStore = (*heartbeatStore)(nil)
+
+const (
+	tblInstanceHeartbeat string = "instance_heartbeat"
+)
+
+type heartbeatStore struct {
+	handler BoltHandler
+}
+
+// heartbeatData 保存在 boltdb 中的实例心跳
+type heartbeatData struct {
+	InstanceID string
+	Server     string
+	CurTimeSec int64
+	Count      int64
+}
+
+// BatchUpsertHeartbeats 批量写入实例心跳，只有心跳时间更新时才会覆盖已有的记录
+func (h *heartbeatStore) BatchUpsertHeartbeats(records []*model.HeartbeatRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(records))
+	for i := range records {
+		keys = append(keys, records[i].InstanceID)
+	}
+	err := h.handler.Execute(true, func(tx *bolt.Tx) error {
+		values := map[string]interface{}{}
+		if err := loadValues(tx, tblInstanceHeartbeat, keys, &heartbeatData{}, values); err != nil {
+			return err
+		}
+		for i := range records {
+			record := records[i]
+			if v, ok := values[record.InstanceID]; ok && v.(*heartbeatData).CurTimeSec > record.CurTimeSec {
+				continue
+			}
+			if err := saveValue(tx, tblInstanceHeartbeat, record.InstanceID, &heartbeatData{
+				InstanceID: record.InstanceID,
+				Server:     record.Server,
+				CurTimeSec: record.CurTimeSec,
+				Count:      record.Count,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("[Store][boltdb] batch upsert %d heartbeats err: %s", len(records), err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetHeartbeats 批量查询实例心跳
+func (h *heartbeatStore) GetHeartbeats(instanceIDs []string) (map[string]*model.HeartbeatRecord, error) {
+	ret := make(map[string]*model.HeartbeatRecord, len(instanceIDs))
+	if len(instanceIDs) == 0 {
+		return ret, nil
+	}
+	values, err := h.handler.LoadValues(tblInstanceHeartbeat, instanceIDs, &heartbeatData{})
+	if err != nil {
+		log.Errorf("[Store][boltdb] get heartbeats err: %s", err.Error())
+		return nil, store.Error(err)
+	}
+	for id, v := range values {
+		data := v.(*heartbeatData)
+		ret[id] = &model.HeartbeatRecord{
+			InstanceID: data.InstanceID,
+			Server:     data.Server,
+			CurTimeSec: data.CurTimeSec,
+			Count:      data.Count,
+		}
+	}
+	return ret, nil
+}
+
+// DeleteHeartbeats 批量删除实例心跳
+func (h *heartbeatStore) DeleteHeartbeats(instanceIDs []string) error {
+	if len(instanceIDs) == 0 {
+		return nil
+	}
+	if err := h.handler.DeleteValues(tblInstanceHeartbeat, instanceIDs); err != nil {
+		log.Errorf("[Store][boltdb] delete %d heartbeats err: %s", len(instanceIDs), err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+func TestHeartbeatStore(t *testing.T) {
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+
+	beatStore := &heartbeatStore{handler: handler}
+	err = beatStore.BatchUpsertHeartbeats([]*model.HeartbeatRecord{
+		{InstanceID: "ins-1", Server: "127.0.0.1", CurTimeSec: 100},
+		{InstanceID: "ins-2", Server: "127.0.0.1", CurTimeSec: 100, Count: 2},
+	})
+	assert.NoError(t, err)
+
+	// 旧的心跳不会覆盖新的心跳
+	err = beatStore.BatchUpsertHeartbeats([]*model.HeartbeatRecord{
+		{InstanceID: "ins-1", Server: "127.0.0.2", CurTimeSec: 90},
+		{InstanceID: "ins-2", Server: "127.0.0.2", CurTimeSec: 110, Count: 3},
+	})
+	assert.NoError(t, err)
+
+	records, err := beatStore.GetHeartbeats([]string{"ins-1", "ins-2", "ins-3"})
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, &model.HeartbeatRecord{InstanceID: "ins-1", Server: "127.0.0.1", CurTimeSec: 100},
+		records["ins-1"])
+	assert.Equal(t, &model.HeartbeatRecord{InstanceID: "ins-2", Server: "127.0.0.2", CurTimeSec: 110, Count: 3},
+		records["ins-2"])
+
+	assert.NoError(t, beatStore.DeleteHeartbeats([]string{"ins-1"}))
+	records, err = beatStore.GetHeartbeats([]string{"ins-1", "ins-2"})
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Contains(t, records, "ins-2")
+}
@@ -19,7 +19,7 @@ package boltdb
 
 import (
 	"fmt"
-	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -37,14 +37,12 @@ const (
 )
 
 func TestInstanceStore_AddInstance(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 	batchAddInstances(t, insStore, "svcid1", insCount)
 }
@@ -97,14 +95,12 @@ func batchAddInstances(t *testing.T, insStore *instanceStore, svcId string, insC
 }
 
 func TestInstanceStore_BatchAddInstances(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 
 	instances := make([]*model.Instance, 0)
@@ -146,15 +142,12 @@ func TestInstanceStore_BatchAddInstances(t *testing.T) {
 }
 
 func TestInstanceStore_GetExpandInstances(t *testing.T) {
-	_ = os.RemoveAll("./table.bolt")
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 	batchAddInstances(t, insStore, "svcid1", insCount)
 
@@ -169,14 +162,12 @@ func TestInstanceStore_GetExpandInstances(t *testing.T) {
 }
 
 func TestInstanceStore_GetMoreInstances(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 	batchAddInstances(t, insStore, "svcid2", insCount)
 
@@ -201,14 +192,12 @@ func TestInstanceStore_GetMoreInstances(t *testing.T) {
 }
 
 func TestInstanceStore_SetInstanceHealthStatus(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 	batchAddInstances(t, insStore, "svcid1", 8)
 
@@ -229,14 +218,12 @@ func TestInstanceStore_SetInstanceHealthStatus(t *testing.T) {
 }
 
 func TestInstanceStore_BatchSetInstanceIsolate(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 	batchAddInstances(t, insStore, "svcid1", 10)
 
@@ -267,14 +254,12 @@ func TestInstanceStore_BatchSetInstanceIsolate(t *testing.T) {
 }
 
 func TestInstanceStore_GetInstancesMainByService(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 	batchAddInstances(t, insStore, "svcid1", insCount)
 
@@ -289,14 +274,12 @@ func TestInstanceStore_GetInstancesMainByService(t *testing.T) {
 }
 
 func TestInstanceStore_UpdateInstance(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 	batchAddInstances(t, insStore, "svcid1", insCount)
 
@@ -341,14 +324,12 @@ func TestInstanceStore_UpdateInstance(t *testing.T) {
 }
 
 func TestInstanceStore_GetInstancesBrief(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 	batchAddInstances(t, insStore, "svcid2", 10)
 	batchAddInstances(t, insStore, "svcid1", 5)
@@ -433,14 +414,12 @@ func TestInstanceStore_GetInstancesBrief(t *testing.T) {
 }
 
 func TestInstanceStore_GetInstancesCount(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 	batchAddInstances(t, insStore, "svcid1", insCount)
 
@@ -455,14 +434,12 @@ func TestInstanceStore_GetInstancesCount(t *testing.T) {
 }
 
 func TestInstanceStore_CheckInstancesExisted(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 	batchAddInstances(t, insStore, "svcid1", insCount)
 
@@ -484,14 +461,12 @@ func TestInstanceStore_CheckInstancesExisted(t *testing.T) {
 }
 
 func TestInstanceStore_DeleteInstance(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 	batchAddInstances(t, insStore, "svcid1", insCount)
 
@@ -512,14 +487,12 @@ func TestInstanceStore_DeleteInstance(t *testing.T) {
 }
 
 func TestInstanceStore_BatchDeleteInstances(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-		_ = os.RemoveAll("./table.bolt")
-	}()
+	defer handler.Close()
 	insStore := &instanceStore{handler: handler}
 	batchAddInstances(t, insStore, "svcid1", insCount)
 
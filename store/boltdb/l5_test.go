@@ -19,11 +19,13 @@ package boltdb
 
 import (
 	"fmt"
+	"path/filepath"
 	"testing"
 )
 
 func TestL5Store_GenNextL5Sid(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -19,7 +19,7 @@ package boltdb
 
 import (
 	"fmt"
-	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -53,14 +53,12 @@ func InitNamespaceData(nsStore *namespaceStore, nsCount int) error {
 }
 
 func TestNamespaceStore_AddNamespace(t *testing.T) {
-	_ = os.RemoveAll("./table.bolt")
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer func() {
-		handler.Close()
-	}()
+	defer handler.Close()
 	nsStore := &namespaceStore{handler: handler}
 	for i := 0; i < nsCount; i++ {
 		err = nsStore.AddNamespace(&model.Namespace{
@@ -79,8 +77,8 @@ func TestNamespaceStore_AddNamespace(t *testing.T) {
 }
 
 func TestNamespaceStore_GetNamespaces(t *testing.T) {
-	_ = os.RemoveAll("./table.bolt")
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -198,8 +196,8 @@ func TestNamespaceStore_GetNamespaces(t *testing.T) {
 }
 
 func TestNamespaceStore_GetNamespace(t *testing.T) {
-	_ = os.RemoveAll("./table.bolt")
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -223,8 +221,8 @@ func TestNamespaceStore_GetNamespace(t *testing.T) {
 }
 
 func TestNamespaceStore_UpdateNamespace(t *testing.T) {
-	_ = os.RemoveAll("./table.bolt")
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -263,8 +261,8 @@ func TestNamespaceStore_UpdateNamespace(t *testing.T) {
 }
 
 func TestNamespaceStore_UpdateNamespaceToken(t *testing.T) {
-	_ = os.RemoveAll("./table.bolt")
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -300,8 +298,8 @@ func TestNamespaceStore_UpdateNamespaceToken(t *testing.T) {
 }
 
 func TestNamespaceStore_GetMoreNamespaces(t *testing.T) {
-	_ = os.RemoveAll("./table.bolt")
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -322,8 +320,8 @@ func TestNamespaceStore_GetMoreNamespaces(t *testing.T) {
 }
 
 func TestTransaction_LockNamespace(t *testing.T) {
-	_ = os.RemoveAll("./table.bolt")
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -348,8 +346,8 @@ func TestTransaction_LockNamespace(t *testing.T) {
 }
 
 func TestTransaction_DeleteNamespace(t *testing.T) {
-	_ = os.RemoveAll("./table.bolt")
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
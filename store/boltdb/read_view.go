@@ -0,0 +1,115 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"errors"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/polarismesh/polaris/store"
+)
+
+var errReadView = errors.New("boltdb read view is read only")
+
+// ReadView 返回一个全部读操作都在 tx 中执行的 store
+func (m *boltStore) ReadView(tx store.Tx) (store.Store, error) {
+	dbTx, ok := tx.GetDelegateTx().(*bolt.Tx)
+	if !ok || dbTx == nil {
+		return nil, errors.New("read view requires a transaction started by StartReadTx")
+	}
+	ret := &boltStore{handler: &txHandler{tx: dbTx}, start: true}
+	ret.bindStores()
+	return ret, nil
+}
+
+// txHandler 绑定在一个事务上的只读 BoltHandler
+type txHandler struct {
+	tx *bolt.Tx
+}
+
+// SaveValue 只读视图不支持写入
+func (h *txHandler) SaveValue(typ string, key string, object interface{}) error {
+	return errReadView
+}
+
+// DeleteValues 只读视图不支持写入
+func (h *txHandler) DeleteValues(typ string, keys []string) error {
+	return errReadView
+}
+
+// UpdateValue 只读视图不支持写入
+func (h *txHandler) UpdateValue(typ string, key string, properties map[string]interface{}) error {
+	return errReadView
+}
+
+// LoadValues load data objects by unique keys, return value is 'key->object' map
+func (h *txHandler) LoadValues(typ string, keys []string, typObject interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if len(keys) == 0 {
+		return values, nil
+	}
+	err := loadValues(h.tx, typ, keys, typObject, values)
+	return values, err
+}
+
+// LoadValuesByFilter filter data objects by condition, return value is 'key->object' map
+func (h *txHandler) LoadValuesByFilter(typ string, fields []string,
+	typObject interface{}, filter func(map[string]interface{}) bool) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	err := loadValuesByFilter(h.tx, typ, fields, typObject, filter, values)
+	return values, err
+}
+
+// LoadValuesAll load all saved data objects, return value is 'key->object' map
+func (h *txHandler) LoadValuesAll(typ string, typObject interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	err := loadValuesAll(h.tx, typ, typObject, values)
+	return values, err
+}
+
+// IterateFields iterate all saved data objects
+func (h *txHandler) IterateFields(typ string, field string, typObject interface{}, filter func(interface{})) error {
+	if filter == nil {
+		return nil
+	}
+	return iterateFields(h.tx, typ, field, typObject, filter)
+}
+
+// CountValues count all data objects
+func (h *txHandler) CountValues(typ string) (int, error) {
+	return countValues(h.tx, typ)
+}
+
+// Execute 只读视图仅支持只读的脚本
+func (h *txHandler) Execute(writable bool, process func(tx *bolt.Tx) error) error {
+	if writable {
+		return errReadView
+	}
+	return process(h.tx)
+}
+
+// StartTx 返回绑定在视图事务上的嵌套事务
+func (h *txHandler) StartTx() (store.Tx, error) {
+	return &Tx{delegateTx: h.tx, nested: true}, nil
+}
+
+// Close 视图事务由创建方关闭
+func (h *txHandler) Close() error {
+	return nil
+}
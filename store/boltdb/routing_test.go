@@ -19,6 +19,7 @@ package boltdb
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -33,7 +34,8 @@ const (
 )
 
 func TestRoutingStore_CreateRoutingConfig(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -195,9 +197,9 @@ func TestRoutingStore_GetRoutingConfigsForCache(t *testing.T) {
 }
 
 func TestRoutingStore_GetRoutingConfigWithService(t *testing.T) {
-
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
 	// find service
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -225,7 +227,8 @@ func TestRoutingStore_GetRoutingConfigWithService(t *testing.T) {
 }
 
 func TestRoutingStore_GetRoutingConfigWithID(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -242,7 +245,8 @@ func TestRoutingStore_GetRoutingConfigWithID(t *testing.T) {
 }
 
 func TestRoutingStore_DeleteRoutingConfig(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
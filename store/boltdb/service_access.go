@@ -0,0 +1,189 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"encoding/json"
+	"time"
+
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+var _ store.ServiceAccessPolicyStore = (*serviceAccessStore)(nil)
+
+const (
+	tblServiceAccessPolicy string = "service_access_policy"
+
+	ServiceAccessFieldName       string = "Name"
+	ServiceAccessFieldAction     string = "Action"
+	ServiceAccessFieldSources    string = "Sources"
+	ServiceAccessFieldConditions string = "Conditions"
+	ServiceAccessFieldComment    string = "Comment"
+	ServiceAccessFieldRevision   string = "Revision"
+	ServiceAccessFieldValid      string = "Valid"
+	ServiceAccessFieldModifyTime string = "ModifyTime"
+)
+
+type serviceAccessStore struct {
+	handler BoltHandler
+}
+
+// serviceAccessData 来源服务以及接口条件以 json 的形式保存
+type serviceAccessData struct {
+	ID         string
+	Name       string
+	Namespace  string
+	Service    string
+	Action     string
+	Sources    string
+	Conditions string
+	Comment    string
+	Revision   string
+	Valid      bool
+	CreateTime time.Time
+	ModifyTime time.Time
+}
+
+// AddServiceAccessPolicy 新增服务访问策略
+func (s *serviceAccessStore) AddServiceAccessPolicy(policy *authcommon.ServiceAccessPolicy) error {
+	tN := time.Now()
+	data := &serviceAccessData{
+		ID:         policy.ID,
+		Name:       policy.Name,
+		Namespace:  policy.Namespace,
+		Service:    policy.Service,
+		Action:     policy.Action,
+		Sources:    utils.MustJson(policy.Sources),
+		Conditions: utils.MustJson(policy.Conditions),
+		Comment:    policy.Comment,
+		Revision:   policy.Revision,
+		Valid:      true,
+		CreateTime: tN,
+		ModifyTime: tN,
+	}
+	if err := s.handler.SaveValue(tblServiceAccessPolicy, policy.ID, data); err != nil {
+		log.Errorf("[Store][boltdb] add service access policy(%s) err: %s", policy.ID, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// UpdateServiceAccessPolicy 更新服务访问策略
+func (s *serviceAccessStore) UpdateServiceAccessPolicy(policy *authcommon.ServiceAccessPolicy) error {
+	properties := map[string]interface{}{
+		ServiceAccessFieldName:       policy.Name,
+		ServiceAccessFieldAction:     policy.Action,
+		ServiceAccessFieldSources:    utils.MustJson(policy.Sources),
+		ServiceAccessFieldConditions: utils.MustJson(policy.Conditions),
+		ServiceAccessFieldComment:    policy.Comment,
+		ServiceAccessFieldRevision:   policy.Revision,
+		ServiceAccessFieldModifyTime: time.Now(),
+	}
+	if err := s.handler.UpdateValue(tblServiceAccessPolicy, policy.ID, properties); err != nil {
+		log.Errorf("[Store][boltdb] update service access policy(%s) err: %s", policy.ID, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// DeleteServiceAccessPolicy 删除服务访问策略
+func (s *serviceAccessStore) DeleteServiceAccessPolicy(id string) error {
+	properties := map[string]interface{}{
+		ServiceAccessFieldValid:      false,
+		ServiceAccessFieldModifyTime: time.Now(),
+	}
+	if err := s.handler.UpdateValue(tblServiceAccessPolicy, id, properties); err != nil {
+		log.Errorf("[Store][boltdb] delete service access policy(%s) err: %s", id, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetServiceAccessPolicy 获取单个服务访问策略
+func (s *serviceAccessStore) GetServiceAccessPolicy(id string) (*authcommon.ServiceAccessPolicy, error) {
+	values, err := s.handler.LoadValues(tblServiceAccessPolicy, []string{id}, &serviceAccessData{})
+	if err != nil {
+		log.Errorf("[Store][boltdb] get service access policy(%s) err: %s", id, err.Error())
+		return nil, store.Error(err)
+	}
+	v, ok := values[id]
+	if !ok {
+		return nil, nil
+	}
+	data := v.(*serviceAccessData)
+	if !data.Valid {
+		return nil, nil
+	}
+	return toServiceAccessPolicy(data)
+}
+
+// GetMoreServiceAccessPolicies 获取增量的服务访问策略
+func (s *serviceAccessStore) GetMoreServiceAccessPolicies(mtime time.Time,
+	firstUpdate bool) ([]*authcommon.ServiceAccessPolicy, error) {
+	fields := []string{ServiceAccessFieldModifyTime, ServiceAccessFieldValid}
+	values, err := s.handler.LoadValuesByFilter(tblServiceAccessPolicy, fields, &serviceAccessData{},
+		func(m map[string]interface{}) bool {
+			if firstUpdate {
+				return m[ServiceAccessFieldValid].(bool)
+			}
+			return !m[ServiceAccessFieldModifyTime].(time.Time).Before(mtime)
+		})
+	if err != nil {
+		log.Errorf("[Store][boltdb] get more service access policies err: %s", err.Error())
+		return nil, store.Error(err)
+	}
+	policies := make([]*authcommon.ServiceAccessPolicy, 0, len(values))
+	for _, v := range values {
+		policy, err := toServiceAccessPolicy(v.(*serviceAccessData))
+		if err != nil {
+			return nil, store.Error(err)
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+func toServiceAccessPolicy(data *serviceAccessData) (*authcommon.ServiceAccessPolicy, error) {
+	policy := &authcommon.ServiceAccessPolicy{
+		ID:         data.ID,
+		Name:       data.Name,
+		Namespace:  data.Namespace,
+		Service:    data.Service,
+		Action:     data.Action,
+		Comment:    data.Comment,
+		Revision:   data.Revision,
+		Valid:      data.Valid,
+		CreateTime: data.CreateTime,
+		ModifyTime: data.ModifyTime,
+	}
+	if data.Sources != "" {
+		if err := json.Unmarshal([]byte(data.Sources), &policy.Sources); err != nil {
+			log.Errorf("[Store][boltdb] unmarshal service access policy(%s) err: %s", data.ID, err.Error())
+			return nil, err
+		}
+	}
+	if data.Conditions != "" {
+		if err := json.Unmarshal([]byte(data.Conditions), &policy.Conditions); err != nil {
+			log.Errorf("[Store][boltdb] unmarshal service access policy(%s) err: %s", data.ID, err.Error())
+			return nil, err
+		}
+	}
+	return policy, nil
+}
@@ -0,0 +1,83 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package boltdb
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+)
+
+func TestServiceAccessStore(t *testing.T) {
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+
+	accessStore := &serviceAccessStore{handler: handler}
+	policy := &authcommon.ServiceAccessPolicy{
+		ID:        "p1",
+		Name:      "deny-order",
+		Namespace: "default",
+		Service:   "payment",
+		Action:    "DENY",
+		Sources:   []authcommon.ServiceAccessSource{{Namespace: "default", Service: "order"}},
+		Conditions: []authcommon.ServiceAccessCondition{
+			{Methods: []string{"POST"}, Path: "/refund", PathType: authcommon.PathMatchPrefix},
+		},
+		Revision: "r1",
+	}
+	assert.NoError(t, accessStore.AddServiceAccessPolicy(policy))
+
+	saved, err := accessStore.GetServiceAccessPolicy("p1")
+	assert.NoError(t, err)
+	assert.True(t, saved.Valid)
+	assert.Equal(t, policy.Sources, saved.Sources)
+	assert.Equal(t, policy.Conditions, saved.Conditions)
+
+	policy.Action = "ALLOW"
+	policy.Sources = nil
+	policy.Revision = "r2"
+	assert.NoError(t, accessStore.UpdateServiceAccessPolicy(policy))
+	saved, err = accessStore.GetServiceAccessPolicy("p1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ALLOW", saved.Action)
+	assert.Empty(t, saved.Sources)
+	assert.Equal(t, "r2", saved.Revision)
+
+	since := time.Now()
+	assert.NoError(t, accessStore.DeleteServiceAccessPolicy("p1"))
+	saved, err = accessStore.GetServiceAccessPolicy("p1")
+	assert.NoError(t, err)
+	assert.Nil(t, saved)
+
+	// 首次加载只返回有效数据，增量加载需要返回被删除的数据
+	policies, err := accessStore.GetMoreServiceAccessPolicies(time.Time{}, true)
+	assert.NoError(t, err)
+	assert.Len(t, policies, 0)
+	policies, err = accessStore.GetMoreServiceAccessPolicies(since, false)
+	assert.NoError(t, err)
+	assert.Len(t, policies, 1)
+	assert.False(t, policies[0].Valid)
+}
@@ -19,6 +19,7 @@ package boltdb
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -34,16 +35,8 @@ const (
 	aliasCount   = 3
 )
 
-func TestServiceStore_AddService(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	defer handler.Close()
-
-	sStore := &serviceStore{handler: handler}
-
+// addTestServices 写入测试用的服务以及别名，每个用例都使用独立的存储文件
+func addTestServices(t *testing.T, sStore *serviceStore) {
 	for i := 0; i < serviceCount; i++ {
 		err := sStore.AddService(&model.Service{
 			ID:        "svcid" + strconv.Itoa(i),
@@ -97,8 +90,22 @@ func TestServiceStore_AddService(t *testing.T) {
 	}
 }
 
+func TestServiceStore_AddService(t *testing.T) {
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer handler.Close()
+
+	sStore := &serviceStore{handler: handler}
+	addTestServices(t, sStore)
+}
+
 func TestServiceStore_GetServices(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -121,7 +128,8 @@ func TestServiceStore_GetServices(t *testing.T) {
 }
 
 func TestServiceStore_GetServicesBatch(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -129,6 +137,7 @@ func TestServiceStore_GetServicesBatch(t *testing.T) {
 	defer handler.Close()
 
 	sStore := &serviceStore{handler: handler}
+	addTestServices(t, sStore)
 
 	sArg := make([]*model.Service, 2)
 	for i := 0; i < 2; i++ {
@@ -149,7 +158,8 @@ func TestServiceStore_GetServicesBatch(t *testing.T) {
 }
 
 func TestServiceStore_GetServiceByID(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -167,7 +177,8 @@ func TestServiceStore_GetServiceByID(t *testing.T) {
 }
 
 func TestServiceStore_UpdateService(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -175,6 +186,7 @@ func TestServiceStore_UpdateService(t *testing.T) {
 	defer handler.Close()
 
 	sStore := &serviceStore{handler: handler}
+	addTestServices(t, sStore)
 
 	err = sStore.UpdateService(&model.Service{
 		ID:        "svcid1",
@@ -208,7 +220,8 @@ func TestServiceStore_UpdateService(t *testing.T) {
 }
 
 func TestServiceStore_UpdateServiceToken(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -216,6 +229,7 @@ func TestServiceStore_UpdateServiceToken(t *testing.T) {
 	defer handler.Close()
 
 	sStore := &serviceStore{handler: handler}
+	addTestServices(t, sStore)
 
 	err = sStore.UpdateServiceToken("svcid1", "ttttt1", "rrrrrr1")
 	if err != nil {
@@ -238,7 +252,8 @@ func TestServiceStore_UpdateServiceToken(t *testing.T) {
 }
 
 func TestServiceStore_GetSourceServiceToken(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -256,7 +271,8 @@ func TestServiceStore_GetSourceServiceToken(t *testing.T) {
 }
 
 func TestServiceStore_GetService(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -274,7 +290,8 @@ func TestServiceStore_GetService(t *testing.T) {
 }
 
 func TestServiceStore_GetServiceAliases(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -282,6 +299,7 @@ func TestServiceStore_GetServiceAliases(t *testing.T) {
 	defer handler.Close()
 
 	sStore := &serviceStore{handler: handler}
+	addTestServices(t, sStore)
 
 	total, ss, err := sStore.GetServiceAliases(nil, 0, 20)
 	if err != nil {
@@ -300,7 +318,8 @@ func TestServiceStore_GetServiceAliases(t *testing.T) {
 }
 
 func TestServiceStore_GetServicesCount(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -316,7 +335,8 @@ func TestServiceStore_GetServicesCount(t *testing.T) {
 }
 
 func TestServiceStore_FuzzyGetService(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -401,7 +421,8 @@ func TestServiceStore_FuzzyGetService(t *testing.T) {
 }
 
 func TestServiceStore_GetMoreServices(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -409,6 +430,7 @@ func TestServiceStore_GetMoreServices(t *testing.T) {
 	defer handler.Close()
 
 	sStore := &serviceStore{handler: handler}
+	addTestServices(t, sStore)
 
 	ss, err := sStore.GetService("svcname3", "testsvc")
 	if err != nil {
@@ -422,7 +444,8 @@ func TestServiceStore_GetMoreServices(t *testing.T) {
 }
 
 func TestServiceStore_UpdateServiceAlias(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -430,6 +453,7 @@ func TestServiceStore_UpdateServiceAlias(t *testing.T) {
 	defer handler.Close()
 
 	sStore := &serviceStore{handler: handler}
+	addTestServices(t, sStore)
 
 	err = sStore.UpdateServiceAlias(&model.Service{
 		ID:        "svcid2",
@@ -486,7 +510,8 @@ func TestServiceStore_DeleteServiceAlias(t *testing.T) {
 }
 
 func TestServiceStore_DeleteService(t *testing.T) {
-	handler, err := NewBoltHandler(&BoltConfig{FileName: "./table.bolt"})
+	boltFile := filepath.Join(t.TempDir(), "table.bolt")
+	handler, err := NewBoltHandler(&BoltConfig{FileName: boltFile})
 	if err != nil {
 		t.Fatal(err)
 	}
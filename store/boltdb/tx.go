@@ -25,6 +25,8 @@ import (
 
 type Tx struct {
 	delegateTx *bolt.Tx
+	// nested 是否为只读视图中的嵌套事务，提交、回滚由视图的创建方负责
+	nested bool
 }
 
 func NewBoltTx(delegateTx *bolt.Tx) store.Tx {
@@ -34,10 +36,16 @@ func NewBoltTx(delegateTx *bolt.Tx) store.Tx {
 }
 
 func (t *Tx) Commit() error {
+	if t.nested {
+		return nil
+	}
 	return t.delegateTx.Commit()
 }
 
 func (t *Tx) Rollback() error {
+	if t.nested {
+		return nil
+	}
 	return t.delegateTx.Rollback()
 }
 
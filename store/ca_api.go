@@ -0,0 +1,35 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package store
+
+import (
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+// CAStore 内置证书颁发机构的可选扩展接口，多个节点通过存储共享同一套根证书
+type CAStore interface {
+	// AddCAKeyPair 新增根证书，相同 Generation 已经存在时返回 DuplicateEntryErr
+	AddCAKeyPair(pair *model.CAKeyPair) error
+	// RetireCAKeyPair 设置根证书的退役时间，到期后从信任列表中移除
+	RetireCAKeyPair(generation uint64, retireTime time.Time) error
+	// GetCAKeyPairs 获取全部根证书
+	GetCAKeyPairs() ([]*model.CAKeyPair, error)
+	// DeleteCAKeyPair 删除根证书
+	DeleteCAKeyPair(generation uint64) error
+}
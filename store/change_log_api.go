@@ -0,0 +1,37 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package store
+
+import (
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+// ChangeLogStore 资源变更日志的可选扩展接口，多个节点共享存储时由存储插件实现，
+// 节点写入数据后追加一条变更日志，其他节点轮询变更日志后按需刷新缓存
+type ChangeLogStore interface {
+	// AppendChangeLogs 追加资源变更日志
+	AppendChangeLogs(logs []*model.ChangeLog) error
+	// GetChangeLogs 按照 ID 升序获取 ID 大于 lastID 的变更日志，最多返回 limit 条
+	GetChangeLogs(lastID uint64, limit uint32) ([]*model.ChangeLog, error)
+	// GetLatestChangeLogID 获取当前最大的变更日志 ID，没有日志时返回 0
+	GetLatestChangeLogID() (uint64, error)
+	// CleanChangeLogs 删除创建时间早于 retention 之前的变更日志，返回删除的条数
+	CleanChangeLogs(retention time.Duration) (uint64, error)
+}
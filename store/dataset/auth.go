@@ -0,0 +1,124 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dataset
+
+import (
+	"sort"
+	"time"
+
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	"github.com/polarismesh/polaris/store"
+)
+
+// Users 用户，主账户排在子账户之前写入
+func Users() *Kind[*authcommon.User] {
+	return &Kind[*authcommon.User]{
+		name: "user",
+		list: func(s store.Store) ([]*authcommon.User, error) {
+			items, err := s.GetUsersForCache(time.Time{}, true)
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *authcommon.User) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool {
+				if items[i].Type != items[j].Type {
+					return items[i].Type < items[j].Type
+				}
+				return items[i].ID < items[j].ID
+			})
+			return items, nil
+		},
+		key:      func(item *authcommon.User) string { return item.Owner + "/" + item.Name },
+		revision: func(item *authcommon.User) string { return item.ID + "/" + item.Token },
+		create: func(s store.Store, item *authcommon.User) error {
+			return DoTransaction(s, func(tx store.Tx) error {
+				return s.AddUser(tx, item)
+			})
+		},
+	}
+}
+
+// UserGroups 用户组
+func UserGroups() *Kind[*authcommon.UserGroupDetail] {
+	return &Kind[*authcommon.UserGroupDetail]{
+		name: "user_group",
+		list: func(s store.Store) ([]*authcommon.UserGroupDetail, error) {
+			items, err := s.GetGroupsForCache(time.Time{}, true)
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *authcommon.UserGroupDetail) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+			return items, nil
+		},
+		key:      func(item *authcommon.UserGroupDetail) string { return item.Owner + "/" + item.Name },
+		revision: func(item *authcommon.UserGroupDetail) string { return item.ID + "/" + item.Token },
+		create: func(s store.Store, item *authcommon.UserGroupDetail) error {
+			return DoTransaction(s, func(tx store.Tx) error {
+				return s.AddGroup(tx, item)
+			})
+		},
+	}
+}
+
+// Strategies 鉴权策略
+func Strategies() *Kind[*authcommon.StrategyDetail] {
+	return &Kind[*authcommon.StrategyDetail]{
+		name: "auth_strategy",
+		list: func(s store.Store) ([]*authcommon.StrategyDetail, error) {
+			items, err := s.GetMoreStrategies(time.Time{}, true)
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *authcommon.StrategyDetail) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+			return items, nil
+		},
+		key:      func(item *authcommon.StrategyDetail) string { return item.Owner + "/" + item.Name },
+		revision: func(item *authcommon.StrategyDetail) string { return item.ID + "/" + item.Revision },
+		create: func(s store.Store, item *authcommon.StrategyDetail) error {
+			return DoTransaction(s, func(tx store.Tx) error {
+				return s.AddStrategy(tx, item)
+			})
+		},
+	}
+}
+
+// Roles 角色及其成员
+func Roles() *Kind[*authcommon.Role] {
+	return &Kind[*authcommon.Role]{
+		name: "auth_role",
+		list: func(s store.Store) ([]*authcommon.Role, error) {
+			items, err := s.GetMoreRoles(true, time.Time{})
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *authcommon.Role) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+			return items, nil
+		},
+		key: func(item *authcommon.Role) string { return item.Owner + "/" + item.Name },
+		revision: func(item *authcommon.Role) string {
+			return fingerprint(item.ID, item.Source, item.Type, item.Comment, metadataOf(item.Metadata),
+				principalIDs(item.Users), principalIDs(item.UserGroups))
+		},
+		create: func(s store.Store, item *authcommon.Role) error {
+			return s.AddRole(item)
+		},
+	}
+}
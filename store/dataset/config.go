@@ -0,0 +1,215 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dataset
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+// ConfigGroups 配置分组
+func ConfigGroups() *Kind[*model.ConfigFileGroup] {
+	return &Kind[*model.ConfigFileGroup]{
+		name: "config_file_group",
+		list: func(s store.Store) ([]*model.ConfigFileGroup, error) {
+			items, err := s.GetMoreConfigGroup(true, time.Time{})
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *model.ConfigFileGroup) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].Id < items[j].Id })
+			return items, nil
+		},
+		key: func(item *model.ConfigFileGroup) string { return item.Namespace + "/" + item.Name },
+		revision: func(item *model.ConfigFileGroup) string {
+			return fingerprint(item.Comment, item.Owner, item.Business, item.Department,
+				metadataOf(item.Metadata), item.CreateBy, item.ModifyBy)
+		},
+		create: func(s store.Store, item *model.ConfigFileGroup) error {
+			_, err := s.CreateConfigFileGroup(item)
+			return err
+		},
+	}
+}
+
+// ConfigFiles 配置文件
+func ConfigFiles() *Kind[*model.ConfigFile] {
+	return &Kind[*model.ConfigFile]{
+		name: "config_file",
+		list: func(s store.Store) ([]*model.ConfigFile, error) {
+			items := make([]*model.ConfigFile, 0, pageSize)
+			for offset := uint32(0); ; offset += pageSize {
+				total, files, err := s.QueryConfigFiles(map[string]string{}, offset, pageSize)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, files...)
+				if len(files) == 0 || uint32(len(items)) >= total {
+					break
+				}
+			}
+			sort.Slice(items, func(i, j int) bool { return items[i].KeyString() < items[j].KeyString() })
+			return items, nil
+		},
+		key:      func(item *model.ConfigFile) string { return item.KeyString() },
+		revision: func(item *model.ConfigFile) string { return contentDigest(item.Content) },
+		create: func(s store.Store, item *model.ConfigFile) error {
+			return DoTransaction(s, func(tx store.Tx) error {
+				return s.CreateConfigFileTx(tx, item)
+			})
+		},
+	}
+}
+
+// ConfigReleases 配置发布，写入时保留源端的版本号以及激活状态
+func ConfigReleases() *Kind[*model.ConfigFileRelease] {
+	return &Kind[*model.ConfigFileRelease]{
+		name: "config_file_release",
+		list: func(s store.Store) ([]*model.ConfigFileRelease, error) {
+			values, err := s.GetMoreReleaseFile(true, time.Time{})
+			if err != nil {
+				return nil, err
+			}
+			items := FilterValid(values, func(item *model.ConfigFileRelease) bool { return item.Valid })
+			// 新增发布时存储层会重新生成版本号并下线同类型的其他发布，因此按照版本号从小到大写入
+			sort.SliceStable(items, func(i, j int) bool {
+				if items[i].FileKey() != items[j].FileKey() {
+					return items[i].FileKey() < items[j].FileKey()
+				}
+				return items[i].Version < items[j].Version
+			})
+			return items, nil
+		},
+		key: func(item *model.ConfigFileRelease) string { return item.ReleaseKey() },
+		// Format、BetaLabels 不在发布记录中持久化，不参与比对
+		revision: func(item *model.ConfigFileRelease) string {
+			return fingerprint(item.Version, item.Md5, item.Content, item.Active, item.Comment,
+				metadataOf(item.Metadata), item.ReleaseDescription, item.CreateBy, item.ModifyBy)
+		},
+		create: func(s store.Store, item *model.ConfigFileRelease) error {
+			return DoTransaction(s, func(tx store.Tx) error {
+				return CreateReleaseTx(s, tx, item)
+			})
+		},
+	}
+}
+
+// CreateReleaseTx 在事务中写入配置发布，并回写 item 中记录的版本号以及激活状态
+func CreateReleaseTx(s store.Store, tx store.Tx, item *model.ConfigFileRelease) error {
+	// 存储层会改写入参中的版本号、激活状态，使用副本写入
+	key := *item.ConfigFileReleaseKey
+	simple := *item.SimpleConfigFileRelease
+	simple.ConfigFileReleaseKey = &key
+	release := &model.ConfigFileRelease{SimpleConfigFileRelease: &simple, Content: item.Content}
+	if err := s.CreateConfigFileReleaseTx(tx, release); err != nil {
+		return err
+	}
+	if !item.Active {
+		if err := s.InactiveConfigFileReleaseTx(tx, release); err != nil {
+			return err
+		}
+	}
+	if restorer, ok := s.(store.ReleaseVersionStore); ok {
+		return restorer.RestoreReleaseVersionTx(tx, item)
+	}
+	return nil
+}
+
+// ConfigReleaseHistories 配置发布历史
+func ConfigReleaseHistories() *Kind[*model.ConfigFileReleaseHistory] {
+	return &Kind[*model.ConfigFileReleaseHistory]{
+		name: "config_file_release_history",
+		list: func(s store.Store) ([]*model.ConfigFileReleaseHistory, error) {
+			items := make([]*model.ConfigFileReleaseHistory, 0, pageSize)
+			for offset := uint32(0); ; offset += pageSize {
+				total, histories, err := s.QueryConfigFileReleaseHistories(map[string]string{}, offset, pageSize)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, histories...)
+				if len(histories) == 0 || uint32(len(items)) >= total {
+					break
+				}
+			}
+			// 按照发布的先后顺序写入
+			sort.SliceStable(items, func(i, j int) bool {
+				return items[i].Id < items[j].Id
+			})
+			return items, nil
+		},
+		key: func(item *model.ConfigFileReleaseHistory) string {
+			return fmt.Sprintf("%s/%s/%s/%s/%d/%s", item.Namespace, item.Group, item.FileName, item.Name,
+				item.Version, item.Type)
+		},
+		revision: func(item *model.ConfigFileReleaseHistory) string {
+			return fingerprint(item.Md5, item.Content, item.Status, item.Reason, item.Comment,
+				metadataOf(item.Metadata), item.ReleaseDescription, item.CreateBy, item.ModifyBy)
+		},
+		create: func(s store.Store, item *model.ConfigFileReleaseHistory) error {
+			return s.CreateConfigFileReleaseHistory(item)
+		},
+	}
+}
+
+// ConfigTemplates 配置模板
+func ConfigTemplates() *Kind[*model.ConfigFileTemplate] {
+	return &Kind[*model.ConfigFileTemplate]{
+		name: "config_file_template",
+		list: func(s store.Store) ([]*model.ConfigFileTemplate, error) {
+			items, err := s.QueryAllConfigFileTemplates()
+			if err != nil {
+				return nil, err
+			}
+			sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+			return items, nil
+		},
+		key:      func(item *model.ConfigFileTemplate) string { return item.Name },
+		revision: func(item *model.ConfigFileTemplate) string { return contentDigest(item.Content) },
+		create: func(s store.Store, item *model.ConfigFileTemplate) error {
+			_, err := s.CreateConfigFileTemplate(item)
+			return err
+		},
+	}
+}
+
+// GrayResources 灰度发布使用的灰度规则
+func GrayResources() *Kind[*model.GrayResource] {
+	return &Kind[*model.GrayResource]{
+		name: "gray_resource",
+		list: func(s store.Store) ([]*model.GrayResource, error) {
+			items, err := s.GetMoreGrayResouces(true, time.Time{})
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *model.GrayResource) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+			return items, nil
+		},
+		key:      func(item *model.GrayResource) string { return item.Name },
+		revision: func(item *model.GrayResource) string { return contentDigest(item.MatchRule) },
+		create: func(s store.Store, item *model.GrayResource) error {
+			return DoTransaction(s, func(tx store.Tx) error {
+				return s.CreateGrayResourceTx(tx, item)
+			})
+		},
+	}
+}
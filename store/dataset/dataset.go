@@ -0,0 +1,207 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package dataset 定义了各类业务资源在存储中的读取、写入方式，供离线迁移、备份恢复等工具共用
+package dataset
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	// pageSize 分页拉取数据时每页的数量
+	pageSize = 100
+)
+
+// Resource 擦除了记录类型的 Kind，用于按照依赖顺序遍历全部资源
+type Resource interface {
+	// Name 资源名称
+	Name() string
+	// ListItems 拉取存储中该类资源的全部有效记录
+	ListItems(s store.Store) ([]interface{}, error)
+	// ItemKey 资源在存储中的唯一标识
+	ItemKey(item interface{}) string
+	// ItemRevision 资源的版本
+	ItemRevision(item interface{}) string
+	// CreateItem 将资源写入存储
+	CreateItem(s store.Store, item interface{}) error
+}
+
+// Kind 一类资源的读取、写入以及比对方式
+type Kind[T any] struct {
+	name string
+	// list 拉取存储中该类资源的全部有效记录，返回的顺序即为写入顺序，多次拉取的顺序保持一致
+	list func(s store.Store) ([]T, error)
+	// key 资源在存储中的唯一标识
+	key func(item T) string
+	// revision 用于校验数据是否一致，没有版本号的资源返回全部业务字段的摘要
+	revision func(item T) string
+	// create 将资源写入存储
+	create func(s store.Store, item T) error
+}
+
+// Name 资源名称
+func (k *Kind[T]) Name() string {
+	return k.name
+}
+
+// List 拉取存储中该类资源的全部有效记录，需要一致性快照时 s 传入 store.ReadViewStore 返回的只读视图
+func (k *Kind[T]) List(s store.Store) ([]T, error) {
+	return k.list(s)
+}
+
+// Key 资源在存储中的唯一标识
+func (k *Kind[T]) Key(item T) string {
+	return k.key(item)
+}
+
+// Revision 资源的版本，版本一致表示记录的业务字段一致
+func (k *Kind[T]) Revision(item T) string {
+	return k.revision(item)
+}
+
+// Create 将资源写入存储
+func (k *Kind[T]) Create(s store.Store, item T) error {
+	return k.create(s, item)
+}
+
+// ListItems 实现 Resource
+func (k *Kind[T]) ListItems(s store.Store) ([]interface{}, error) {
+	items, err := k.list(s)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]interface{}, 0, len(items))
+	for i := range items {
+		ret = append(ret, items[i])
+	}
+	return ret, nil
+}
+
+// ItemKey 实现 Resource
+func (k *Kind[T]) ItemKey(item interface{}) string {
+	return k.key(item.(T))
+}
+
+// ItemRevision 实现 Resource
+func (k *Kind[T]) ItemRevision(item interface{}) string {
+	return k.revision(item.(T))
+}
+
+// CreateItem 实现 Resource
+func (k *Kind[T]) CreateItem(s store.Store, item interface{}) error {
+	return k.create(s, item.(T))
+}
+
+// Resources 按照依赖顺序返回全部资源
+func Resources() []Resource {
+	return []Resource{
+		Namespaces(),
+		Services(),
+		ServiceAliases(),
+		Instances(),
+		RoutingConfigs(),
+		RouterConfigs(),
+		RateLimits(),
+		CircuitBreakerRules(),
+		FaultDetectRules(),
+		ServiceContracts(),
+		LaneGroups(),
+		ConfigGroups(),
+		ConfigFiles(),
+		ConfigReleases(),
+		ConfigReleaseHistories(),
+		ConfigTemplates(),
+		GrayResources(),
+		Users(),
+		UserGroups(),
+		Strategies(),
+		Roles(),
+	}
+}
+
+// FilterValid 过滤掉已经被逻辑删除的记录
+func FilterValid[T any](items []T, valid func(T) bool) []T {
+	ret := make([]T, 0, len(items))
+	for i := range items {
+		if valid(items[i]) {
+			ret = append(ret, items[i])
+		}
+	}
+	return ret
+}
+
+// MapValues 返回 map 中的全部值
+func MapValues[T any](values map[string]T) []T {
+	ret := make([]T, 0, len(values))
+	for key := range values {
+		ret = append(ret, values[key])
+	}
+	return ret
+}
+
+// DoTransaction 在事务中执行写操作
+func DoTransaction(s store.Store, handle func(tx store.Tx) error) error {
+	tx, err := s.StartTx()
+	if err != nil {
+		return err
+	}
+	if err := handle(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// contentDigest 内容摘要，用于比对没有版本号的资源
+func contentDigest(content string) string {
+	h := sha1.Sum([]byte(content))
+	return hex.EncodeToString(h[:])
+}
+
+// fingerprint 业务字段的摘要，用于比对记录的全部业务字段是否一致
+func fingerprint(fields ...interface{}) string {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err.Error()
+	}
+	return contentDigest(string(data))
+}
+
+// metadataOf 不同存储对空标签的返回值不同，统一为空 map 后再比对
+func metadataOf(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return map[string]string{}
+	}
+	return metadata
+}
+
+// principalIDs 排序后的成员 ID 列表
+func principalIDs(principals []authcommon.Principal) []string {
+	ids := make([]string, 0, len(principals))
+	for i := range principals {
+		ids = append(ids, principals[i].PrincipalID)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,68 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dataset
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store/mock"
+)
+
+func TestResources(t *testing.T) {
+	names := map[string]struct{}{}
+	for _, res := range Resources() {
+		_, ok := names[res.Name()]
+		assert.False(t, ok, res.Name())
+		names[res.Name()] = struct{}{}
+	}
+}
+
+func TestConfigReleases(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s := mock.NewMockStore(ctrl)
+
+	newRelease := func(name string, version uint64, active bool) *model.ConfigFileRelease {
+		return &model.ConfigFileRelease{
+			SimpleConfigFileRelease: &model.SimpleConfigFileRelease{
+				ConfigFileReleaseKey: &model.ConfigFileReleaseKey{
+					Name: name, Namespace: "default", Group: "group", FileName: "app.yaml",
+				},
+				Version: version,
+				Active:  active,
+				Valid:   true,
+			},
+		}
+	}
+	s.EXPECT().GetMoreReleaseFile(true, gomock.Any()).Return([]*model.ConfigFileRelease{
+		newRelease("v3", 3, true), newRelease("v1", 1, false), newRelease("v2", 2, false),
+	}, nil)
+
+	items, err := ConfigReleases().List(s)
+	assert.NoError(t, err)
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	// 按照版本号从小到大写入
+	assert.Equal(t, []string{"v1", "v2", "v3"}, names)
+}
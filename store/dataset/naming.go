@@ -0,0 +1,289 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package dataset
+
+import (
+	"sort"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+// Namespaces 命名空间
+func Namespaces() *Kind[*model.Namespace] {
+	return &Kind[*model.Namespace]{
+		name: "namespace",
+		list: func(s store.Store) ([]*model.Namespace, error) {
+			items, err := s.GetMoreNamespaces(time.Time{})
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *model.Namespace) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+			return items, nil
+		},
+		key:      func(item *model.Namespace) string { return item.Name },
+		revision: func(item *model.Namespace) string { return item.Token },
+		create: func(s store.Store, item *model.Namespace) error {
+			return s.AddNamespace(item)
+		},
+	}
+}
+
+// listServices 拉取全部服务，aliases 指定返回服务还是服务别名
+func listServices(s store.Store, aliases bool) ([]*model.Service, error) {
+	values, err := s.GetMoreServices(time.Time{}, true, false, true)
+	if err != nil {
+		return nil, err
+	}
+	items := FilterValid(MapValues(values), func(item *model.Service) bool {
+		return item.Valid && item.IsAlias() == aliases
+	})
+	sort.Slice(items, func(i, j int) bool { return serviceKey(items[i]) < serviceKey(items[j]) })
+	return items, nil
+}
+
+func serviceKey(item *model.Service) string {
+	return item.Namespace + "/" + item.Name
+}
+
+// Services 服务，不包含服务别名
+func Services() *Kind[*model.Service] {
+	return &Kind[*model.Service]{
+		name: "service",
+		list: func(s store.Store) ([]*model.Service, error) {
+			return listServices(s, false)
+		},
+		key:      serviceKey,
+		revision: func(item *model.Service) string { return item.ID + "/" + item.Revision },
+		create: func(s store.Store, item *model.Service) error {
+			return s.AddService(item)
+		},
+	}
+}
+
+// ServiceAliases 服务别名，依赖于源服务，需要在服务之后写入
+func ServiceAliases() *Kind[*model.Service] {
+	return &Kind[*model.Service]{
+		name: "service_alias",
+		list: func(s store.Store) ([]*model.Service, error) {
+			return listServices(s, true)
+		},
+		key:      serviceKey,
+		revision: func(item *model.Service) string { return item.ID + "/" + item.Revision },
+		create: func(s store.Store, item *model.Service) error {
+			return s.AddService(item)
+		},
+	}
+}
+
+// Instances 服务实例
+func Instances() *Kind[*model.Instance] {
+	return &Kind[*model.Instance]{
+		name: "instance",
+		list: func(s store.Store) ([]*model.Instance, error) {
+			tx, err := s.StartReadTx()
+			if err != nil {
+				return nil, err
+			}
+			defer func() {
+				_ = tx.Rollback()
+			}()
+			values, err := s.GetMoreInstances(tx, time.Time{}, true, true, nil)
+			if err != nil {
+				return nil, err
+			}
+			items := FilterValid(MapValues(values), func(item *model.Instance) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].ID() < items[j].ID() })
+			return items, nil
+		},
+		key:      func(item *model.Instance) string { return item.ID() },
+		revision: func(item *model.Instance) string { return item.Revision() },
+		create: func(s store.Store, item *model.Instance) error {
+			return s.BatchAddInstances([]*model.Instance{item})
+		},
+	}
+}
+
+// RoutingConfigs v1 版本的路由规则
+func RoutingConfigs() *Kind[*model.RoutingConfig] {
+	return &Kind[*model.RoutingConfig]{
+		name: "routing_config",
+		list: func(s store.Store) ([]*model.RoutingConfig, error) {
+			items, err := s.GetRoutingConfigsForCache(time.Time{}, true)
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *model.RoutingConfig) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+			return items, nil
+		},
+		key:      func(item *model.RoutingConfig) string { return item.ID },
+		revision: func(item *model.RoutingConfig) string { return item.Revision },
+		create: func(s store.Store, item *model.RoutingConfig) error {
+			return s.CreateRoutingConfig(item)
+		},
+	}
+}
+
+// RouterConfigs v2 版本的路由规则
+func RouterConfigs() *Kind[*model.RouterConfig] {
+	return &Kind[*model.RouterConfig]{
+		name: "route_rule",
+		list: func(s store.Store) ([]*model.RouterConfig, error) {
+			items, err := s.GetRoutingConfigsV2ForCache(time.Time{}, true)
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *model.RouterConfig) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+			return items, nil
+		},
+		key:      func(item *model.RouterConfig) string { return item.ID },
+		revision: func(item *model.RouterConfig) string { return item.Revision },
+		create: func(s store.Store, item *model.RouterConfig) error {
+			return s.CreateRoutingConfigV2(item)
+		},
+	}
+}
+
+// RateLimits 限流规则
+func RateLimits() *Kind[*model.RateLimit] {
+	return &Kind[*model.RateLimit]{
+		name: "ratelimit_rule",
+		list: func(s store.Store) ([]*model.RateLimit, error) {
+			items, err := s.GetRateLimitsForCache(time.Time{}, true)
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *model.RateLimit) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+			return items, nil
+		},
+		key:      func(item *model.RateLimit) string { return item.ID },
+		revision: func(item *model.RateLimit) string { return item.Revision },
+		create: func(s store.Store, item *model.RateLimit) error {
+			return s.CreateRateLimit(item)
+		},
+	}
+}
+
+// CircuitBreakerRules 熔断规则
+func CircuitBreakerRules() *Kind[*model.CircuitBreakerRule] {
+	return &Kind[*model.CircuitBreakerRule]{
+		name: "circuitbreaker_rule",
+		list: func(s store.Store) ([]*model.CircuitBreakerRule, error) {
+			items, err := s.GetCircuitBreakerRulesForCache(time.Time{}, true)
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *model.CircuitBreakerRule) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+			return items, nil
+		},
+		key:      func(item *model.CircuitBreakerRule) string { return item.ID },
+		revision: func(item *model.CircuitBreakerRule) string { return item.Revision },
+		create: func(s store.Store, item *model.CircuitBreakerRule) error {
+			return s.CreateCircuitBreakerRule(item)
+		},
+	}
+}
+
+// FaultDetectRules 主动探测规则
+func FaultDetectRules() *Kind[*model.FaultDetectRule] {
+	return &Kind[*model.FaultDetectRule]{
+		name: "fault_detect_rule",
+		list: func(s store.Store) ([]*model.FaultDetectRule, error) {
+			items, err := s.GetFaultDetectRulesForCache(time.Time{}, true)
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *model.FaultDetectRule) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+			return items, nil
+		},
+		key:      func(item *model.FaultDetectRule) string { return item.ID },
+		revision: func(item *model.FaultDetectRule) string { return item.Revision },
+		create: func(s store.Store, item *model.FaultDetectRule) error {
+			return s.CreateFaultDetectRule(item)
+		},
+	}
+}
+
+// ServiceContracts 服务契约及其接口
+func ServiceContracts() *Kind[*model.EnrichServiceContract] {
+	return &Kind[*model.EnrichServiceContract]{
+		name: "service_contract",
+		list: func(s store.Store) ([]*model.EnrichServiceContract, error) {
+			items, err := s.GetMoreServiceContracts(true, time.Time{})
+			if err != nil {
+				return nil, err
+			}
+			items = FilterValid(items, func(item *model.EnrichServiceContract) bool { return item.Valid })
+			sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+			return items, nil
+		},
+		key:      func(item *model.EnrichServiceContract) string { return item.ID },
+		revision: func(item *model.EnrichServiceContract) string { return item.Revision },
+		create: func(s store.Store, item *model.EnrichServiceContract) error {
+			if err := s.CreateServiceContract(item.ServiceContract); err != nil {
+				return err
+			}
+			if len(item.Interfaces) == 0 {
+				return nil
+			}
+			return s.AddServiceContractInterfaces(item)
+		},
+	}
+}
+
+// LaneGroups 泳道组及其泳道规则，拉取时过滤掉已经删除的泳道规则
+func LaneGroups() *Kind[*model.LaneGroup] {
+	return &Kind[*model.LaneGroup]{
+		name: "lane_group",
+		list: func(s store.Store) ([]*model.LaneGroup, error) {
+			values, err := s.GetMoreLaneGroups(time.Time{}, true)
+			if err != nil {
+				return nil, err
+			}
+			items := FilterValid(MapValues(values), func(item *model.LaneGroup) bool { return item.Valid })
+			for _, item := range items {
+				for id, rule := range item.LaneRules {
+					if !rule.Valid {
+						delete(item.LaneRules, id)
+					}
+				}
+			}
+			sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+			return items, nil
+		},
+		key:      func(item *model.LaneGroup) string { return item.Name },
+		revision: func(item *model.LaneGroup) string { return item.ID + "/" + item.Revision },
+		create: func(s store.Store, item *model.LaneGroup) error {
+			for _, rule := range item.LaneRules {
+				// 存储层只会插入标记为新增的泳道规则
+				rule.SetAddFlag(true)
+				rule.SetChangeEnable(rule.Enable)
+			}
+			return DoTransaction(s, func(tx store.Tx) error {
+				return s.AddLaneGroup(tx, item)
+			})
+		},
+	}
+}
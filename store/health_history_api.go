@@ -0,0 +1,32 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package store
+
+import (
+	"github.com/polarismesh/polaris/common/model"
+)
+
+// InstanceHealthHistoryStore 实例健康状态变更历史的持久化，非必须实现的接口
+type InstanceHealthHistoryStore interface {
+	// BatchAppendInstanceHealthHistory 追加一批按照时间先后排列的变更记录，每个实例只保留最近的 maxSize 条
+	BatchAppendInstanceHealthHistory(records []*model.InstanceHealthTransition, maxSize int) error
+	// GetInstanceHealthHistory 查询实例最近的 limit 条变更记录，按照时间倒序排列
+	GetInstanceHealthHistory(instanceID string, limit int) ([]*model.InstanceHealthTransition, error)
+	// DeleteInstanceHealthHistory 删除实例的所有变更记录
+	DeleteInstanceHealthHistory(instanceID string) error
+}
@@ -0,0 +1,32 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package store
+
+import (
+	"github.com/polarismesh/polaris/common/model"
+)
+
+// HealthCheckSuspensionStore 按范围暂停健康检查的规则的持久化，用于在集群内同步，非必须实现的接口
+type HealthCheckSuspensionStore interface {
+	// AddHealthCheckSuspension 新增暂停规则
+	AddHealthCheckSuspension(suspension *model.HealthCheckSuspension) error
+	// DeleteHealthCheckSuspension 删除暂停规则
+	DeleteHealthCheckSuspension(id string) error
+	// GetHealthCheckSuspensions 查询所有的暂停规则，包括已经过期但是还没有被清理的
+	GetHealthCheckSuspensions() ([]*model.HealthCheckSuspension, error)
+}
@@ -0,0 +1,32 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package store
+
+import (
+	"github.com/polarismesh/polaris/common/model"
+)
+
+// HeartbeatStore 实例心跳记录的持久化，供不依赖 Redis 的集群心跳健康检查使用，非必须实现的接口
+type HeartbeatStore interface {
+	// BatchUpsertHeartbeats 批量写入实例最近一次的心跳，已有更新的心跳时间时不会被覆盖
+	BatchUpsertHeartbeats(records []*model.HeartbeatRecord) error
+	// GetHeartbeats 批量查询实例心跳，不存在的实例不会出现在返回结果中
+	GetHeartbeats(instanceIDs []string) (map[string]*model.HeartbeatRecord, error)
+	// DeleteHeartbeats 批量删除实例心跳
+	DeleteHeartbeats(instanceIDs []string) error
+}
@@ -0,0 +1,121 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/polarismesh/polaris/store"
+)
+
+// Migrator 在两个存储插件之间离线迁移数据
+type Migrator struct {
+	source    store.Store
+	target    store.Store
+	resources []Resource
+}
+
+// NewMigrator 创建迁移器，source 与 target 需要已经完成初始化
+func NewMigrator(source, target store.Store) *Migrator {
+	return &Migrator{
+		source:    source,
+		target:    target,
+		resources: DefaultResources(),
+	}
+}
+
+// Run 按照依赖顺序迁移全部资源，目标端已存在的记录会被跳过，写入完成后对每类资源做一次校验
+func (m *Migrator) Run() (*Report, error) {
+	if m.source.Name() == m.target.Name() {
+		return nil, errors.New("source and target store must be different plugins")
+	}
+	_, restorable := m.target.(store.MigrateStore)
+	report := &Report{
+		Source:             m.source.Name(),
+		Target:             m.target.Name(),
+		TimestampsRestored: restorable,
+	}
+	for _, res := range m.resources {
+		item, err := res.migrate(m.source, m.target)
+		if err != nil {
+			return report, err
+		}
+		report.Resources = append(report.Resources, item)
+	}
+	return report, nil
+}
+
+// ResourceReport 单类资源的迁移结果
+type ResourceReport struct {
+	// Resource 资源名称
+	Resource string
+	// Source 源端有效记录数
+	Source int
+	// Migrated 写入目标端的记录数
+	Migrated int
+	// Skipped 目标端已存在而跳过的记录数
+	Skipped int
+	// Missing 校验时在目标端找不到的记录数
+	Missing int
+	// Mismatch 校验时版本不一致的记录数
+	Mismatch int
+}
+
+// Report 迁移报告
+type Report struct {
+	Source    string
+	Target    string
+	Resources []*ResourceReport
+	// TimestampsRestored 目标端是否保留了源端记录的创建、修改时间
+	TimestampsRestored bool
+}
+
+// Passed 校验是否全部通过
+func (r *Report) Passed() bool {
+	for _, item := range r.Resources {
+		if item.Missing > 0 || item.Mismatch > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Print 以表格的形式输出迁移报告
+func (r *Report) Print(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "migrate from %s to %s\n\n", r.Source, r.Target)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RESOURCE\tSOURCE\tMIGRATED\tSKIPPED\tMISSING\tMISMATCH")
+	for _, item := range r.Resources {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\n", item.Resource, item.Source, item.Migrated,
+			item.Skipped, item.Missing, item.Mismatch)
+	}
+	_ = tw.Flush()
+
+	_, _ = fmt.Fprintln(w)
+	if !r.TimestampsRestored {
+		_, _ = fmt.Fprintf(w, "store %s does not support restoring timestamps, ctime/mtime were reset\n", r.Target)
+	}
+	if r.Passed() {
+		_, _ = fmt.Fprintln(w, "verify: passed")
+	} else {
+		_, _ = fmt.Fprintln(w, "verify: failed")
+	}
+}
@@ -0,0 +1,223 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package migrate
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/dataset"
+	"github.com/polarismesh/polaris/store/mock"
+)
+
+// restorableStore 支持回写时间的 mock store
+type restorableStore struct {
+	*mock.MockStore
+	restored []interface{}
+	versions map[string]uint64
+}
+
+func (s *restorableStore) RestoreTimestamps(item interface{}) error {
+	s.restored = append(s.restored, item)
+	return nil
+}
+
+func (s *restorableStore) RestoreReleaseVersionTx(tx store.Tx, release *model.ConfigFileRelease) error {
+	if s.versions == nil {
+		s.versions = map[string]uint64{}
+	}
+	s.versions[release.ReleaseKey()] = release.Version
+	return nil
+}
+
+func newTestStores(t *testing.T) (*gomock.Controller, *mock.MockStore, *restorableStore) {
+	ctrl := gomock.NewController(t)
+	source := mock.NewMockStore(ctrl)
+	source.EXPECT().Name().Return("boltdbStore").AnyTimes()
+	target := &restorableStore{MockStore: mock.NewMockStore(ctrl)}
+	target.EXPECT().Name().Return("defaultStore").AnyTimes()
+	return ctrl, source, target
+}
+
+func TestMigrator_Run(t *testing.T) {
+	ctrl, source, target := newTestStores(t)
+	defer ctrl.Finish()
+
+	mtime := time.Unix(1700000000, 0)
+	defaultNs := &model.Namespace{Name: "default", Token: "t1", Valid: true}
+	testNs := &model.Namespace{Name: "test", Token: "t2", Valid: true, ModifyTime: mtime}
+	deletedNs := &model.Namespace{Name: "deleted", Token: "t3", Valid: false}
+
+	source.EXPECT().GetMoreNamespaces(gomock.Any()).
+		Return([]*model.Namespace{defaultNs, testNs, deletedNs}, nil)
+	gomock.InOrder(
+		target.EXPECT().GetMoreNamespaces(gomock.Any()).Return([]*model.Namespace{defaultNs}, nil),
+		target.EXPECT().AddNamespace(testNs).Return(nil),
+		target.EXPECT().GetMoreNamespaces(gomock.Any()).Return([]*model.Namespace{defaultNs, testNs}, nil),
+	)
+
+	m := NewMigrator(source, target)
+	m.resources = []Resource{newResource(dataset.Namespaces())}
+	report, err := m.Run()
+	assert.NoError(t, err)
+	assert.True(t, report.Passed())
+	assert.True(t, report.TimestampsRestored)
+	assert.Equal(t, []*ResourceReport{
+		{Resource: "namespace", Source: 2, Migrated: 1, Skipped: 1},
+	}, report.Resources)
+	assert.Equal(t, []interface{}{testNs}, target.restored)
+
+	buf := bytes.NewBuffer(nil)
+	report.Print(buf)
+	assert.Contains(t, buf.String(), "verify: passed")
+}
+
+func TestMigrator_RunVerifyFailed(t *testing.T) {
+	ctrl, source, target := newTestStores(t)
+	defer ctrl.Finish()
+
+	svc1 := &model.Service{ID: "svc-1", Name: "svc1", Namespace: "default", Revision: "r1", Valid: true}
+	svc2 := &model.Service{ID: "svc-2", Name: "svc2", Namespace: "default", Revision: "r2", Valid: true}
+	alias := &model.Service{ID: "alias-1", Name: "alias", Namespace: "default", Reference: "svc-1",
+		Revision: "r3", Valid: true}
+	source.EXPECT().GetMoreServices(gomock.Any(), true, false, true).
+		Return(map[string]*model.Service{alias.ID: alias, svc2.ID: svc2, svc1.ID: svc1}, nil)
+	gomock.InOrder(
+		target.EXPECT().GetMoreServices(gomock.Any(), true, false, true).
+			Return(map[string]*model.Service{}, nil),
+		// 服务别名单独作为一类资源在服务之后写入
+		target.EXPECT().AddService(svc1).Return(nil),
+		target.EXPECT().AddService(svc2).Return(nil),
+		target.EXPECT().GetMoreServices(gomock.Any(), true, false, true).
+			Return(map[string]*model.Service{svc1.ID: {ID: "svc-1", Name: "svc1", Namespace: "default",
+				Revision: "r0", Valid: true}}, nil),
+	)
+
+	m := NewMigrator(source, target)
+	m.resources = []Resource{newResource(dataset.Services())}
+	report, err := m.Run()
+	assert.NoError(t, err)
+	assert.False(t, report.Passed())
+	assert.Equal(t, []*ResourceReport{
+		{Resource: "service", Source: 2, Migrated: 2, Missing: 1, Mismatch: 1},
+	}, report.Resources)
+}
+
+func TestMigrator_RunSameStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s := mock.NewMockStore(ctrl)
+	s.EXPECT().Name().Return("defaultStore").AnyTimes()
+
+	_, err := NewMigrator(s, s).Run()
+	assert.Error(t, err)
+}
+
+func TestMigrator_RunConfigRelease(t *testing.T) {
+	ctrl, source, target := newTestStores(t)
+	defer ctrl.Finish()
+
+	newRelease := func(version uint64, active bool) *model.ConfigFileRelease {
+		return &model.ConfigFileRelease{
+			SimpleConfigFileRelease: &model.SimpleConfigFileRelease{
+				ConfigFileReleaseKey: &model.ConfigFileReleaseKey{
+					Name: "v" + strconv.FormatUint(version, 10), Namespace: "default", Group: "group",
+					FileName: "app.yaml",
+				},
+				Version:  version,
+				Md5:      "md5",
+				Active:   active,
+				Valid:    true,
+				Metadata: map[string]string{},
+			},
+			Content: "content",
+		}
+	}
+	v3, v5 := newRelease(3, false), newRelease(5, true)
+	source.EXPECT().GetMoreReleaseFile(true, gomock.Any()).Return([]*model.ConfigFileRelease{v5, v3}, nil)
+
+	tx := mock.NewMockTx(ctrl)
+	tx.EXPECT().Commit().Return(nil).Times(2)
+	target.EXPECT().StartTx().Return(tx, nil).Times(2)
+	// 模拟存储层重新生成版本号、激活状态
+	var nextVersion uint64
+	target.EXPECT().CreateConfigFileReleaseTx(tx, gomock.Any()).DoAndReturn(
+		func(_ store.Tx, release *model.ConfigFileRelease) error {
+			nextVersion++
+			release.Version = nextVersion
+			release.Active = true
+			return nil
+		}).Times(2)
+	target.EXPECT().InactiveConfigFileReleaseTx(tx, gomock.Any()).Return(nil)
+	gomock.InOrder(
+		target.EXPECT().GetMoreReleaseFile(true, gomock.Any()).Return(nil, nil),
+		target.EXPECT().GetMoreReleaseFile(true, gomock.Any()).DoAndReturn(
+			func(bool, time.Time) ([]*model.ConfigFileRelease, error) {
+				// 版本号回写后与源端一致，v3 的内容被截断
+				lossy := newRelease(3, false)
+				lossy.Content = ""
+				return []*model.ConfigFileRelease{newRelease(5, true), lossy}, nil
+			}),
+	)
+
+	m := NewMigrator(source, target)
+	m.resources = []Resource{newResource(dataset.ConfigReleases())}
+	report, err := m.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]uint64{v3.ReleaseKey(): 3, v5.ReleaseKey(): 5}, target.versions)
+	// 源端数据不会被存储层改写
+	assert.Equal(t, uint64(3), v3.Version)
+	assert.False(t, v3.Active)
+	assert.Equal(t, []*ResourceReport{
+		{Resource: "config_file_release", Source: 2, Migrated: 2, Mismatch: 1},
+	}, report.Resources)
+}
+
+func TestMigrator_RunVerifyFields(t *testing.T) {
+	ctrl, source, target := newTestStores(t)
+	defer ctrl.Finish()
+
+	role := &authcommon.Role{ID: "role-1", Name: "admin", Owner: "polaris", Comment: "c", Valid: true,
+		Users: []authcommon.Principal{{PrincipalID: "u2"}, {PrincipalID: "u1"}}}
+	source.EXPECT().GetMoreRoles(true, gomock.Any()).Return([]*authcommon.Role{role}, nil)
+	gomock.InOrder(
+		target.EXPECT().GetMoreRoles(true, gomock.Any()).Return(nil, nil),
+		target.EXPECT().AddRole(role).Return(nil),
+		// 角色丢失了部分成员
+		target.EXPECT().GetMoreRoles(true, gomock.Any()).Return([]*authcommon.Role{{ID: "role-1",
+			Name: "admin", Owner: "polaris", Comment: "c", Valid: true,
+			Users: []authcommon.Principal{{PrincipalID: "u1"}}}}, nil),
+	)
+
+	m := NewMigrator(source, target)
+	m.resources = []Resource{newResource(dataset.Roles())}
+	report, err := m.Run()
+	assert.NoError(t, err)
+	assert.False(t, report.Passed())
+	assert.Equal(t, []*ResourceReport{
+		{Resource: "auth_role", Source: 1, Migrated: 1, Mismatch: 1},
+	}, report.Resources)
+}
@@ -0,0 +1,126 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package migrate
+
+import (
+	"fmt"
+
+	"github.com/polarismesh/polaris/store"
+	"github.com/polarismesh/polaris/store/dataset"
+)
+
+// Resource 一类需要迁移的资源
+type Resource interface {
+	// Name 资源名称
+	Name() string
+	// migrate 将源端的资源写入目标端，返回该类资源的迁移报告
+	migrate(source, target store.Store) (*ResourceReport, error)
+}
+
+// resource 基于 dataset 中定义的读写方式迁移一类资源
+type resource struct {
+	dataset.Resource
+}
+
+func (r *resource) migrate(source, target store.Store) (*ResourceReport, error) {
+	report := &ResourceReport{Resource: r.Name()}
+	items, err := r.ListItems(source)
+	if err != nil {
+		return nil, fmt.Errorf("list %s from source store: %w", r.Name(), err)
+	}
+	exists, err := r.listKeys(target)
+	if err != nil {
+		return nil, fmt.Errorf("list %s from target store: %w", r.Name(), err)
+	}
+	restorer, _ := target.(store.MigrateStore)
+
+	report.Source = len(items)
+	// 部分存储在写入时会改写入参，需要在写入前记录源端的版本
+	migrated := make(map[string]string, len(items))
+	for i := range items {
+		item := items[i]
+		key := r.ItemKey(item)
+		if _, ok := exists[key]; ok {
+			report.Skipped++
+			continue
+		}
+		migrated[key] = r.ItemRevision(item)
+		if err := r.CreateItem(target, item); err != nil {
+			return nil, fmt.Errorf("create %s %s: %w", r.Name(), key, err)
+		}
+		if restorer != nil {
+			if err := restorer.RestoreTimestamps(item); err != nil {
+				return nil, fmt.Errorf("restore %s %s timestamps: %w", r.Name(), key, err)
+			}
+		}
+		report.Migrated++
+	}
+	if err := r.verify(target, migrated, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// verify 重新拉取目标端数据，检查迁移的记录是否全部存在且版本一致，migrated 为写入的记录及其源端版本
+func (r *resource) verify(target store.Store, migrated map[string]string, report *ResourceReport) error {
+	items, err := r.ListItems(target)
+	if err != nil {
+		return fmt.Errorf("verify %s from target store: %w", r.Name(), err)
+	}
+	revisions := make(map[string]string, len(items))
+	for i := range items {
+		revisions[r.ItemKey(items[i])] = r.ItemRevision(items[i])
+	}
+	for key, expect := range migrated {
+		revision, ok := revisions[key]
+		if !ok {
+			report.Missing++
+			continue
+		}
+		if revision != expect {
+			report.Mismatch++
+		}
+	}
+	return nil
+}
+
+func (r *resource) listKeys(s store.Store) (map[string]struct{}, error) {
+	items, err := r.ListItems(s)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]struct{}, len(items))
+	for i := range items {
+		keys[r.ItemKey(items[i])] = struct{}{}
+	}
+	return keys, nil
+}
+
+// DefaultResources 按照依赖顺序返回全部需要迁移的资源
+func DefaultResources() []Resource {
+	kinds := dataset.Resources()
+	ret := make([]Resource, 0, len(kinds))
+	for _, kind := range kinds {
+		ret = append(ret, newResource(kind))
+	}
+	return ret
+}
+
+func newResource(kind dataset.Resource) Resource {
+	return &resource{Resource: kind}
+}
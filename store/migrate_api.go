@@ -0,0 +1,35 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package store
+
+import "github.com/polarismesh/polaris/common/model"
+
+// MigrateStore 离线数据迁移使用的可选扩展接口，存储插件按需实现
+// 各个 Create/Add 接口在写入时都会以当前时间作为创建、修改时间，迁移工具通过该接口回写源端的原始时间
+type MigrateStore interface {
+	// RestoreTimestamps 将资源在存储中的创建时间、修改时间回写为 item 中记录的时间，
+	// item 为各个 Create/Add 接口入参的资源对象，不支持的资源类型直接忽略
+	RestoreTimestamps(item interface{}) error
+}
+
+// ReleaseVersionStore 离线数据迁移、恢复使用的可选扩展接口，存储插件按需实现
+// 新增配置发布时存储会以当前最大版本号加一作为新的版本号，迁移工具通过该接口回写源端的原始版本号
+type ReleaseVersionStore interface {
+	// RestoreReleaseVersionTx 将配置发布在存储中的版本号回写为 release 中记录的版本号
+	RestoreReleaseVersionTx(tx Tx, release *model.ConfigFileRelease) error
+}
@@ -205,6 +205,8 @@ type leaderElectionStateMachine struct {
 	releaseSignal    int32
 	releaseTickLimit int32
 	leader           string
+	// createPending 选举记录尚未创建成功
+	createPending bool
 }
 
 // isLeader
@@ -232,6 +234,14 @@ func (le *leaderElectionStateMachine) mainLoop() {
 
 // tick
 func (le *leaderElectionStateMachine) tick() {
+	if le.createPending {
+		if err := le.leStore.CreateLeaderElection(le.electKey); err != nil {
+			log.Errorf("[Store][database] create leader election err (%s), stay follower state (%s)",
+				err.Error(), le.electKey)
+			return
+		}
+		le.createPending = false
+	}
 	if le.checkReleaseTickLimit() {
 		log.Infof("[Store][database] abandon leader election in this tick (%s)", le.electKey)
 		return
@@ -378,9 +388,12 @@ func (m *adminStore) StartLeaderElection(key string) error {
 		releaseSignal:    0,
 		releaseTickLimit: 0,
 	}
-	err := le.leStore.CreateLeaderElection(key)
-	if err != nil {
-		return store.Error(err)
+	// 存储暂时不可用时不影响启动，由选举协程在之后的周期中重新创建选举记录
+	le.createPending = true
+	if err := le.leStore.CreateLeaderElection(key); err != nil {
+		log.Warnf("[Store][database] create leader election (%s) err, retry later: %v", key, store.Error(err))
+	} else {
+		le.createPending = false
 	}
 
 	m.leMap[key] = le
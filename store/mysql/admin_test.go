@@ -274,14 +274,20 @@ func TestAdminStore_StartLeaderElection1(t *testing.T) {
 		leMap:   make(map[string]*leaderElectionStateMachine),
 	}
 
+	// 存储不可用时仍然启动选举，由选举协程重新创建选举记录
 	err := m.StartLeaderElection(TestElectKey)
-	if err == nil {
-		t.Errorf("should start failed")
+	if err != nil {
+		t.Errorf("should start success")
 	}
-	_, ok := m.leMap[TestElectKey]
-	if ok {
-		t.Errorf("should not in map")
+	le, ok := m.leMap[TestElectKey]
+	if !ok {
+		t.Errorf("should in map")
+	}
+	if !le.createPending {
+		t.Errorf("should retry creating leader election")
 	}
+
+	m.StopLeaderElections()
 }
 
 func TestAdminStore_StartLeaderElection2(t *testing.T) {
@@ -38,6 +38,10 @@ type BaseDB struct {
 	cfg            *dbConfig
 	isolationLevel sql.IsolationLevel
 	parsePwd       plugin.ParsePassword
+	// view 只读视图绑定的事务，不为空时全部语句都在该事务中执行
+	view *sql.Tx
+	// guarded 存储只读时是否拒绝写入
+	guarded bool
 }
 
 // dbConfig store的配置
@@ -89,10 +93,6 @@ func (b *BaseDB) openDatabase() error {
 		log.Errorf("[Store][database] sql open err: %s", err.Error())
 		return err
 	}
-	if pingErr := db.Ping(); pingErr != nil {
-		log.Errorf("[Store][database] database ping err: %s", pingErr.Error())
-		return pingErr
-	}
 	if c.maxOpenConns > 0 {
 		log.Infof("[Store][database] db set max open conns: %d", c.maxOpenConns)
 		db.SetMaxOpenConns(c.maxOpenConns)
@@ -119,7 +119,16 @@ func (b *BaseDB) Exec(query string, args ...interface{}) (sql.Result, error) {
 	)
 	defer reportCallMetrics("Exec", start, err)
 
+	if b.guarded {
+		if err = store.CheckWritable(); err != nil {
+			return nil, err
+		}
+	}
 	Retry("exec "+query, func() error {
+		if b.view != nil {
+			result, err = b.view.Exec(query, args...)
+			return err
+		}
 		result, err = b.DB.Exec(query, args...)
 		return err
 	})
@@ -137,6 +146,10 @@ func (b *BaseDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
 	defer reportCallMetrics("Query", start, err)
 
 	Retry("query "+query, func() error {
+		if b.view != nil {
+			rows, err = b.view.Query(query, args...)
+			return err
+		}
 		rows, err = b.DB.Query(query, args...)
 		return err
 	})
@@ -154,6 +167,10 @@ func (b *BaseDB) QueryRow(query string, args ...interface{}) *sql.Row {
 	defer reportCallMetrics("QueryRow", start, err)
 
 	Retry("query "+query, func() error {
+		if b.view != nil {
+			row = b.view.QueryRow(query, args...)
+			return row.Err()
+		}
 		row = b.DB.QueryRow(query, args...)
 		err = row.Err()
 		return row.Err()
@@ -170,6 +187,10 @@ func (b *BaseDB) Begin() (*BaseTx, error) {
 		option *sql.TxOptions
 		start  = time.Now()
 	)
+	if b.view != nil {
+		// 只读视图中不再开启新的事务，提交、回滚由视图的创建方负责
+		return &BaseTx{Tx: b.view, nested: true}, nil
+	}
 	if b.isolationLevel > 0 {
 		option = &sql.TxOptions{Isolation: sql.IsolationLevel(b.isolationLevel)}
 	}
@@ -181,7 +202,7 @@ func (b *BaseDB) Begin() (*BaseTx, error) {
 		return err
 	})
 
-	return &BaseTx{Tx: tx}, err
+	return &BaseTx{Tx: tx, guarded: b.guarded}, err
 }
 
 func reportCallMetrics(label string, start time.Time, err error) {
@@ -205,6 +226,20 @@ func reportCallMetrics(label string, start time.Time, err error) {
 // BaseTx 对sql.Tx的封装
 type BaseTx struct {
 	*sql.Tx
+	// nested 是否为只读视图中的嵌套事务
+	nested bool
+	// guarded 存储只读时是否拒绝写入
+	guarded bool
+}
+
+// Exec 存储只读时拒绝事务中的写入
+func (b *BaseTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if b.guarded {
+		if err := store.CheckWritable(); err != nil {
+			return nil, err
+		}
+	}
+	return b.Tx.Exec(query, args...)
 }
 
 // Commit .
@@ -213,6 +248,9 @@ func (b *BaseTx) Commit() error {
 		start = time.Now()
 		err   error
 	)
+	if b.nested {
+		return nil
+	}
 	defer reportCallMetrics("Commit", start, err)
 	err = b.Tx.Commit()
 	return err
@@ -224,6 +262,9 @@ func (b *BaseTx) Rollback() error {
 		start = time.Now()
 		err   error
 	)
+	if b.nested {
+		return nil
+	}
 	defer reportCallMetrics("Rollback", start, err)
 	err = b.Tx.Rollback()
 	return err
@@ -265,6 +306,28 @@ func RetryTransaction(label string, handle func() error) error {
 	return err
 }
 
+// readView 创建一个全部语句都在 tx 中执行的 BaseDB
+func (b *BaseDB) readView(tx *BaseTx) *BaseDB {
+	return &BaseDB{
+		DB:             b.DB,
+		cfg:            b.cfg,
+		isolationLevel: b.isolationLevel,
+		parsePwd:       b.parsePwd,
+		view:           tx.Tx,
+	}
+}
+
+// writeGuarded 创建一个共享连接池、存储只读时拒绝写入的 BaseDB
+func (b *BaseDB) writeGuarded() *BaseDB {
+	return &BaseDB{
+		DB:             b.DB,
+		cfg:            b.cfg,
+		isolationLevel: b.isolationLevel,
+		parsePwd:       b.parsePwd,
+		guarded:        true,
+	}
+}
+
 func (b *BaseDB) processWithTransaction(label string, handle func(*BaseTx) error) error {
 	tx, err := b.Begin()
 	if err != nil {
@@ -0,0 +1,107 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package sqldb
+
+import (
+	"strings"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+// changeLogStore 实现了 store.ChangeLogStore
+type changeLogStore struct {
+	master *BaseDB
+}
+
+// AppendChangeLogs 追加资源变更日志
+func (c *changeLogStore) AppendChangeLogs(logs []*model.ChangeLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(logs))
+	args := make([]interface{}, 0, 2*len(logs))
+	for _, item := range logs {
+		values = append(values, "(?, ?, sysdate())")
+		args = append(args, string(item.Resource), item.Server)
+	}
+	str := "INSERT INTO change_log (resource, server, ctime) VALUES " + strings.Join(values, ", ")
+	if _, err := c.master.Exec(str, args...); err != nil {
+		log.Errorf("[Store][database] append change logs err: %s", err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetChangeLogs 按照 ID 升序获取 ID 大于 lastID 的变更日志
+func (c *changeLogStore) GetChangeLogs(lastID uint64, limit uint32) ([]*model.ChangeLog, error) {
+	str := "SELECT id, resource, server, UNIX_TIMESTAMP(ctime) FROM change_log " +
+		"WHERE id > ? ORDER BY id LIMIT ?"
+	rows, err := c.master.Query(str, lastID, limit)
+	if err != nil {
+		log.Errorf("[Store][database] get change logs err: %s", err.Error())
+		return nil, store.Error(err)
+	}
+	defer rows.Close()
+
+	logs := make([]*model.ChangeLog, 0, limit)
+	for rows.Next() {
+		var (
+			item     = &model.ChangeLog{}
+			resource string
+			ctime    int64
+		)
+		if err := rows.Scan(&item.ID, &resource, &item.Server, &ctime); err != nil {
+			log.Errorf("[Store][database] scan change log err: %s", err.Error())
+			return nil, store.Error(err)
+		}
+		item.Resource = model.Resource(resource)
+		item.CreateTime = time.Unix(ctime, 0)
+		logs = append(logs, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, store.Error(err)
+	}
+	return logs, nil
+}
+
+// GetLatestChangeLogID 获取当前最大的变更日志 ID
+func (c *changeLogStore) GetLatestChangeLogID() (uint64, error) {
+	var id uint64
+	if err := c.master.QueryRow("SELECT IFNULL(MAX(id), 0) FROM change_log").Scan(&id); err != nil {
+		log.Errorf("[Store][database] get latest change log id err: %s", err.Error())
+		return 0, store.Error(err)
+	}
+	return id, nil
+}
+
+// CleanChangeLogs 删除超过保留时长的变更日志，以数据库时间为准避免各节点时钟不一致
+func (c *changeLogStore) CleanChangeLogs(retention time.Duration) (uint64, error) {
+	str := "DELETE FROM change_log WHERE ctime < FROM_UNIXTIME(UNIX_TIMESTAMP(SYSDATE()) - ?)"
+	result, err := c.master.Exec(str, int64(retention.Seconds()))
+	if err != nil {
+		log.Errorf("[Store][database] clean change logs err: %s", err.Error())
+		return 0, store.Error(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, store.Error(err)
+	}
+	return uint64(rows), nil
+}
@@ -0,0 +1,71 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package sqldb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/common/model"
+)
+
+func Test_changeLogStore(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	s := &changeLogStore{master: &BaseDB{DB: db}}
+
+	// 没有需要写入的日志时不访问数据库
+	assert.NoError(t, s.AppendChangeLogs(nil))
+
+	mock.ExpectExec("INSERT INTO change_log (resource, server, ctime) VALUES (?, ?, sysdate()), (?, ?, sysdate())").
+		WithArgs("Service", "127.0.0.1", "Instance", "127.0.0.1").
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	assert.NoError(t, s.AppendChangeLogs([]*model.ChangeLog{
+		{Resource: model.RService, Server: "127.0.0.1"},
+		{Resource: model.RInstance, Server: "127.0.0.1"},
+	}))
+
+	mock.ExpectQuery("SELECT id, resource, server, UNIX_TIMESTAMP(ctime) FROM change_log "+
+		"WHERE id > ? ORDER BY id LIMIT ?").
+		WithArgs(1, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "resource", "server", "ctime"}).
+			AddRow(2, "Instance", "127.0.0.2", 1700000000))
+	logs, err := s.GetChangeLogs(1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.ChangeLog{
+		{ID: 2, Resource: model.RInstance, Server: "127.0.0.2", CreateTime: time.Unix(1700000000, 0)},
+	}, logs)
+
+	mock.ExpectQuery("SELECT IFNULL(MAX(id), 0) FROM change_log").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	id, err := s.GetLatestChangeLogID()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), id)
+
+	mock.ExpectExec("DELETE FROM change_log WHERE ctime < FROM_UNIXTIME(UNIX_TIMESTAMP(SYSDATE()) - ?)").
+		WithArgs(600).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	count, err := s.CleanChangeLogs(10 * time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -270,6 +270,21 @@ func (cfr *configFileReleaseStore) InactiveConfigFileReleaseTx(tx store.Tx, rele
 	return nil
 }
 
+// RestoreReleaseVersionTx 回写配置发布的版本号
+func (cfr *configFileReleaseStore) RestoreReleaseVersionTx(tx store.Tx, release *model.ConfigFileRelease) error {
+	if tx == nil {
+		return ErrTxIsNil
+	}
+	dbTx := tx.GetDelegateTx().(*BaseTx)
+
+	args := []interface{}{release.Version, release.Namespace, release.Group, release.FileName, release.Name}
+	if _, err := dbTx.Exec("UPDATE config_file_release SET version = ? "+
+		" WHERE namespace = ? AND `group` = ? AND file_name = ? AND name = ?", args...); err != nil {
+		return store.Error(err)
+	}
+	return nil
+}
+
 func (cfr *configFileReleaseStore) inactiveConfigFileRelease(tx *BaseTx,
 	release *model.ConfigFileRelease) (uint64, error) {
 	if tx == nil {
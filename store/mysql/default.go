@@ -20,6 +20,7 @@ package sqldb
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 
@@ -34,6 +35,8 @@ const (
 	STORENAME = "defaultStore"
 	// DefaultConnMaxLifetime default maximum connection lifetime
 	DefaultConnMaxLifetime = 60 * 30 // 默认是30分钟
+	// reconnectInterval 启动时数据库不可用，重新检查连接的间隔
+	reconnectInterval = 5 * time.Second
 	// emptyEnableTime 规则禁用时启用时间的默认值
 	emptyEnableTime = "STR_TO_DATE('1980-01-01 00:00:01', '%Y-%m-%d %H:%i:%s')"
 )
@@ -71,17 +74,29 @@ type stableStore struct {
 	*adminStore
 	*toolStore
 	*grayStore
+	*migrateStore
+	*changeLogStore
+	*caStore
+	*serviceAccessStore
+	*heartbeatStore
+	*healthHistoryStore
+	*healthSuspensionStore
+	*schemaStore
 
 	*userStore
 	*groupStore
 	*strategyStore
 	*roleStore
 
-	// 主数据库，可以进行读写
+	// 主数据库，可以进行读写，存储只读时拒绝写入
 	master *BaseDB
+	// internal 不受存储只读限制的主数据库，供选主、变更日志、心跳等内部数据的存储使用
+	internal *BaseDB
 	// 备数据库，提供只读
 	slave *BaseDB
 	start bool
+	// stopCh 停止启动时数据库不可用而在后台进行的表结构检查
+	stopCh chan struct{}
 }
 
 // Name 实现Name函数
@@ -103,7 +118,9 @@ func (s *stableStore) Initialize(conf *store.Config) error {
 	if err != nil {
 		return err
 	}
-	s.master = master
+	// 各个子存储通过 master 写入，存储只读时拒绝写入；表结构升级、只读查询不受影响
+	s.master = master.writeGuarded()
+	s.internal = master
 
 	if slaveConfig != nil {
 		log.Infof("[Store][database] use slave database config: %+v", slaveConfig)
@@ -115,16 +132,56 @@ func (s *stableStore) Initialize(conf *store.Config) error {
 	}
 	// 如果slave为空，意味着slaveConfig为空，用master数据库替代
 	if s.slave == nil {
-		s.slave = s.master
+		s.slave = master
 	}
 
-	log.Infof("[Store][database] connect the database successfully")
+	if s.schemaStore, err = newSchemaStore(master); err != nil {
+		return err
+	}
+	// 默认自动执行尚未应用的表结构升级脚本
+	autoUpgrade := true
+	if v, ok := conf.Option["autoUpgrade"].(bool); ok {
+		autoUpgrade = v
+	}
+	if err := master.Ping(); err != nil {
+		// 数据库不可用时不阻塞启动，由缓存快照提供只读服务，连接恢复后再检查表结构
+		log.Errorf("[Store][database] database is unreachable, continue booting and keep connecting: %s", err.Error())
+		s.stopCh = make(chan struct{})
+		go s.checkSchemaLater(autoUpgrade, s.stopCh)
+	} else {
+		log.Infof("[Store][database] connect the database successfully")
+		if err := s.schemaStore.checkSchema(autoUpgrade); err != nil {
+			return err
+		}
+	}
 
 	s.start = true
 	s.newStore()
 	return nil
 }
 
+// checkSchemaLater 等待数据库可以连接后再检查表结构
+func (s *stableStore) checkSchemaLater(autoUpgrade bool, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(reconnectInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stopCh:
+			return
+		}
+		if err := s.schemaStore.master.Ping(); err != nil {
+			log.Warnf("[Store][database] database is still unreachable: %s", err.Error())
+			continue
+		}
+		log.Infof("[Store][database] connect the database successfully")
+		if err := s.schemaStore.checkSchema(autoUpgrade); err != nil {
+			log.Errorf("[Store][database] check database schema err: %s", err.Error())
+		}
+		return
+	}
+}
+
 // parseDatabaseConf return slave, master, error
 func parseDatabaseConf(opt map[string]interface{}) (*dbConfig, *dbConfig, error) {
 	// 必填
@@ -195,6 +252,10 @@ func parseStoreConfig(opts interface{}) (*dbConfig, error) {
 // Destroy 退出函数
 func (s *stableStore) Destroy() error {
 	s.start = false
+	if s.stopCh != nil {
+		close(s.stopCh)
+		s.stopCh = nil
+	}
 	if s.master != nil {
 		_ = s.master.Close()
 	}
@@ -208,6 +269,7 @@ func (s *stableStore) Destroy() error {
 
 	s.master = nil
 	s.slave = nil
+	s.internal = nil
 
 	return nil
 }
@@ -250,6 +312,18 @@ func (s *stableStore) StartReadTx() (store.Tx, error) {
 	return NewSqlDBTx(tx), nil
 }
 
+// ReadView 返回一个全部读操作都在 tx 中执行的 store
+func (s *stableStore) ReadView(tx store.Tx) (store.Store, error) {
+	dbTx, ok := tx.GetDelegateTx().(*BaseTx)
+	if !ok || dbTx == nil {
+		return nil, errors.New("read view requires a transaction started by StartReadTx")
+	}
+	view := s.slave.readView(dbTx)
+	ret := &stableStore{master: view, slave: view, internal: view, start: true, schemaStore: s.schemaStore}
+	ret.newStore()
+	return ret, nil
+}
+
 // newStore 初始化子类
 func (s *stableStore) newStore() {
 	s.namespaceStore = &namespaceStore{master: s.master, slave: s.slave}
@@ -274,8 +348,15 @@ func (s *stableStore) newStore() {
 
 	s.grayStore = &grayStore{master: s.master, slave: s.slave}
 
-	s.adminStore = newAdminStore(s.master)
+	s.adminStore = newAdminStore(s.internal)
 	s.toolStore = &toolStore{db: s.master}
+	s.migrateStore = &migrateStore{master: s.master}
+	s.changeLogStore = &changeLogStore{master: s.internal}
+	s.caStore = &caStore{master: s.master}
+	s.serviceAccessStore = &serviceAccessStore{master: s.master, slave: s.slave}
+	s.heartbeatStore = &heartbeatStore{master: s.internal}
+	s.healthHistoryStore = &healthHistoryStore{master: s.internal}
+	s.healthSuspensionStore = &healthSuspensionStore{master: s.internal}
 
 	s.userStore = &userStore{master: s.master, slave: s.slave}
 	s.groupStore = &groupStore{master: s.master, slave: s.slave}
@@ -0,0 +1,88 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package sqldb
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+var _ store.HealthCheckSuspensionStore = (*healthSuspensionStore)(nil)
+
+// healthSuspensionStore 实现了 store.HealthCheckSuspensionStore
+type healthSuspensionStore struct {
+	master *BaseDB
+}
+
+// AddHealthCheckSuspension 新增暂停规则
+func (h *healthSuspensionStore) AddHealthCheckSuspension(suspension *model.HealthCheckSuspension) error {
+	str := "INSERT INTO health_check_suspension (id, selector, comment, ctime, etime) VALUES (?, ?, ?, ?, ?)"
+	if _, err := h.master.Exec(str, suspension.ID, utils.MustJson(suspension.Selector), suspension.Comment,
+		suspension.CreateTime.UnixMilli(), suspension.ExpireTime.UnixMilli()); err != nil {
+		log.Errorf("[Store][database] add health check suspension(%s) err: %s", suspension.ID, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// DeleteHealthCheckSuspension 删除暂停规则
+func (h *healthSuspensionStore) DeleteHealthCheckSuspension(id string) error {
+	if _, err := h.master.Exec("DELETE FROM health_check_suspension WHERE id = ?", id); err != nil {
+		log.Errorf("[Store][database] delete health check suspension(%s) err: %s", id, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetHealthCheckSuspensions 查询所有的暂停规则
+func (h *healthSuspensionStore) GetHealthCheckSuspensions() ([]*model.HealthCheckSuspension, error) {
+	rows, err := h.master.Query("SELECT id, selector, comment, ctime, etime FROM health_check_suspension")
+	if err != nil {
+		log.Errorf("[Store][database] get health check suspensions err: %s", err.Error())
+		return nil, store.Error(err)
+	}
+	defer rows.Close()
+
+	var ret []*model.HealthCheckSuspension
+	for rows.Next() {
+		var (
+			suspension   = &model.HealthCheckSuspension{Selector: &model.HealthCheckSuspensionSelector{}}
+			selector     string
+			ctime, etime int64
+		)
+		if err := rows.Scan(&suspension.ID, &selector, &suspension.Comment, &ctime, &etime); err != nil {
+			log.Errorf("[Store][database] scan health check suspension err: %s", err.Error())
+			return nil, store.Error(err)
+		}
+		if err := json.Unmarshal([]byte(selector), suspension.Selector); err != nil {
+			log.Errorf("[Store][database] unmarshal health check suspension(%s) err: %s", suspension.ID, err.Error())
+			return nil, store.Error(err)
+		}
+		suspension.CreateTime = time.UnixMilli(ctime)
+		suspension.ExpireTime = time.UnixMilli(etime)
+		ret = append(ret, suspension)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, store.Error(err)
+	}
+	return ret, nil
+}
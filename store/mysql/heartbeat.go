@@ -0,0 +1,104 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package sqldb
+
+import (
+	"strings"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+var _ store.HeartbeatStore = (*heartbeatStore)(nil)
+
+// heartbeatStore 实现了 store.HeartbeatStore
+type heartbeatStore struct {
+	master *BaseDB
+}
+
+// BatchUpsertHeartbeats 批量写入实例心跳，只有心跳时间更新时才会覆盖已有的记录
+func (h *heartbeatStore) BatchUpsertHeartbeats(records []*model.HeartbeatRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(records))
+	args := make([]interface{}, 0, 4*len(records))
+	for _, item := range records {
+		values = append(values, "(?, ?, ?, ?)")
+		args = append(args, item.InstanceID, item.Server, item.CurTimeSec, item.Count)
+	}
+	// 字段按照从左到右的顺序更新，beat_time 必须放在最后
+	str := "INSERT INTO instance_heartbeat (id, server, beat_time, count) VALUES " + strings.Join(values, ", ") +
+		" ON DUPLICATE KEY UPDATE server = IF(VALUES(beat_time) >= beat_time, VALUES(server), server), " +
+		"count = IF(VALUES(beat_time) >= beat_time, VALUES(count), count), " +
+		"beat_time = GREATEST(beat_time, VALUES(beat_time))"
+	if _, err := h.master.Exec(str, args...); err != nil {
+		log.Errorf("[Store][database] batch upsert %d heartbeats err: %s", len(records), err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetHeartbeats 批量查询实例心跳
+func (h *heartbeatStore) GetHeartbeats(instanceIDs []string) (map[string]*model.HeartbeatRecord, error) {
+	ret := make(map[string]*model.HeartbeatRecord, len(instanceIDs))
+	if len(instanceIDs) == 0 {
+		return ret, nil
+	}
+	str := "SELECT id, server, beat_time, count FROM instance_heartbeat WHERE id IN (" +
+		PlaceholdersN(len(instanceIDs)) + ")"
+	args := make([]interface{}, 0, len(instanceIDs))
+	for i := range instanceIDs {
+		args = append(args, instanceIDs[i])
+	}
+	rows, err := h.master.Query(str, args...)
+	if err != nil {
+		log.Errorf("[Store][database] get heartbeats err: %s", err.Error())
+		return nil, store.Error(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		record := &model.HeartbeatRecord{}
+		if err := rows.Scan(&record.InstanceID, &record.Server, &record.CurTimeSec, &record.Count); err != nil {
+			log.Errorf("[Store][database] scan heartbeat err: %s", err.Error())
+			return nil, store.Error(err)
+		}
+		ret[record.InstanceID] = record
+	}
+	if err := rows.Err(); err != nil {
+		return nil, store.Error(err)
+	}
+	return ret, nil
+}
+
+// DeleteHeartbeats 批量删除实例心跳
+func (h *heartbeatStore) DeleteHeartbeats(instanceIDs []string) error {
+	if len(instanceIDs) == 0 {
+		return nil
+	}
+	str := "DELETE FROM instance_heartbeat WHERE id IN (" + PlaceholdersN(len(instanceIDs)) + ")"
+	args := make([]interface{}, 0, len(instanceIDs))
+	for i := range instanceIDs {
+		args = append(args, instanceIDs[i])
+	}
+	if _, err := h.master.Exec(str, args...); err != nil {
+		log.Errorf("[Store][database] delete %d heartbeats err: %s", len(instanceIDs), err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
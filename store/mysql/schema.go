@@ -0,0 +1,449 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package sqldb
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	// schemaLockKey 表结构升级使用的启动锁，保证集群中只有一个节点执行升级
+	schemaLockKey = "schema_upgrade"
+	// baselineScript 标记基线版本时记录的脚本名称
+	baselineScript = "baseline"
+	deltaScriptDir = "scripts/delta"
+)
+
+var (
+	//go:embed scripts/delta/*.sql
+	deltaScriptFS embed.FS
+
+	// ErrUnknownSchemaVersion 数据库中没有表结构版本记录
+	ErrUnknownSchemaVersion = errors.New("database schema version is unknown, " +
+		"please run `polaris-server db upgrade --baseline <version>` to specify the current version")
+)
+
+// schemaVersion 表结构版本，对应 polaris 的发布版本号
+type schemaVersion [3]int
+
+// parseSchemaVersion 解析版本号，支持 v1_18_1、v1.18.1、1.18.1 这几种格式
+func parseSchemaVersion(s string) (schemaVersion, error) {
+	var v schemaVersion
+	parts := strings.FieldsFunc(strings.TrimPrefix(s, "v"), func(r rune) bool {
+		return r == '_' || r == '.'
+	})
+	if len(parts) != len(v) {
+		return v, fmt.Errorf("invalid schema version: %s", s)
+	}
+	for i := range parts {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return v, fmt.Errorf("invalid schema version: %s", s)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+func (v schemaVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+func (v schemaVersion) compare(o schemaVersion) int {
+	for i := range v {
+		if v[i] != o[i] {
+			if v[i] < o[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// deltaScript scripts/delta 下的增量升级脚本，文件名格式为 v{from}-v{to}.sql
+type deltaScript struct {
+	name string
+	from schemaVersion
+	to   schemaVersion
+}
+
+// loadDeltaScripts 加载全部增量升级脚本，并按照版本顺序排列
+func loadDeltaScripts() ([]*deltaScript, error) {
+	entries, err := deltaScriptFS.ReadDir(deltaScriptDir)
+	if err != nil {
+		return nil, err
+	}
+	scripts := make([]*deltaScript, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		versions := strings.Split(strings.TrimSuffix(name, ".sql"), "-")
+		if len(versions) != 2 {
+			return nil, fmt.Errorf("invalid delta script name: %s", name)
+		}
+		from, err := parseSchemaVersion(versions[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := parseSchemaVersion(versions[1])
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, &deltaScript{name: name, from: from, to: to})
+	}
+	sort.Slice(scripts, func(i, j int) bool {
+		return scripts[i].from.compare(scripts[j].from) < 0
+	})
+	return scripts, nil
+}
+
+// latestSchemaVersion 当前程序支持的最新表结构版本
+func latestSchemaVersion(scripts []*deltaScript) schemaVersion {
+	var latest schemaVersion
+	for _, script := range scripts {
+		if script.to.compare(latest) > 0 {
+			latest = script.to
+		}
+	}
+	return latest
+}
+
+// pendingScripts 从 current 版本升级到最新版本需要依次执行的脚本
+func pendingScripts(scripts []*deltaScript, current schemaVersion) ([]*deltaScript, error) {
+	if current.compare(latestSchemaVersion(scripts)) >= 0 {
+		return nil, nil
+	}
+	pending := make([]*deltaScript, 0, len(scripts))
+	version := current
+	for _, script := range scripts {
+		if script.from.compare(version) < 0 {
+			continue
+		}
+		if script.from.compare(version) > 0 {
+			break
+		}
+		pending = append(pending, script)
+		version = script.to
+	}
+	if len(pending) == 0 {
+		return nil, fmt.Errorf("no delta script can upgrade schema from version %s", current)
+	}
+	return pending, nil
+}
+
+// readStatements 读取脚本并拆分为单条语句，USE 以及 CREATE DATABASE 语句会被忽略，使用配置中的数据库
+func (d *deltaScript) readStatements() ([]string, error) {
+	content, err := deltaScriptFS.ReadFile(path.Join(deltaScriptDir, d.name))
+	if err != nil {
+		return nil, err
+	}
+	statements := make([]string, 0, 8)
+	for _, stmt := range splitStatements(string(content)) {
+		fields := strings.Fields(strings.ToUpper(stmt))
+		if fields[0] == "USE" || (len(fields) > 1 && fields[0] == "CREATE" && fields[1] == "DATABASE") {
+			continue
+		}
+		statements = append(statements, stmt)
+	}
+	return statements, nil
+}
+
+// splitStatements 按照分号拆分 SQL 脚本，忽略注释以及字符串、标识符中的分号
+func splitStatements(content string) []string {
+	var (
+		statements []string
+		sb         strings.Builder
+		quote      byte
+	)
+	flush := func() {
+		if stmt := strings.TrimSpace(sb.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		sb.Reset()
+	}
+	for i := 0; i < len(content); i++ {
+		ch := content[i]
+		switch {
+		case quote != 0:
+			sb.WriteByte(ch)
+			if ch == '\\' && i+1 < len(content) {
+				i++
+				sb.WriteByte(content[i])
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+			sb.WriteByte(ch)
+		case ch == '-' && strings.HasPrefix(content[i:], "--"):
+			if end := strings.IndexByte(content[i:], '\n'); end >= 0 {
+				i += end
+				sb.WriteByte('\n')
+			} else {
+				i = len(content)
+			}
+		case ch == '/' && strings.HasPrefix(content[i:], "/*"):
+			if end := strings.Index(content[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(content)
+			}
+		case ch == ';':
+			flush()
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	flush()
+	return statements
+}
+
+// schemaStore 实现了 store.SchemaStore，使用 schema_version 表记录已经执行的升级脚本
+type schemaStore struct {
+	master  *BaseDB
+	scripts []*deltaScript
+}
+
+func newSchemaStore(master *BaseDB) (*schemaStore, error) {
+	scripts, err := loadDeltaScripts()
+	if err != nil {
+		return nil, err
+	}
+	return &schemaStore{master: master, scripts: scripts}, nil
+}
+
+// checkSchema 启动时检查表结构版本，数据库版本高于程序支持的版本时拒绝启动
+func (s *schemaStore) checkSchema(autoUpgrade bool) error {
+	current, found, err := s.currentVersion()
+	if err != nil {
+		return err
+	}
+	if !found {
+		log.Warnf("[Store][database] no schema version recorded, skip schema check, " +
+			"run `polaris-server db upgrade --baseline <version>` to manage the schema version")
+		return nil
+	}
+	latest := latestSchemaVersion(s.scripts)
+	if current.compare(latest) > 0 {
+		return fmt.Errorf("database schema version %s is newer than %s supported by this server", current, latest)
+	}
+	if current.compare(latest) == 0 {
+		return nil
+	}
+	if !autoUpgrade {
+		log.Warnf("[Store][database] database schema version %s is older than %s, "+
+			"run `polaris-server db upgrade` to upgrade it", current, latest)
+		return nil
+	}
+	return s.UpgradeSchema(&store.SchemaUpgradeOption{})
+}
+
+// UpgradeSchema 执行尚未应用的增量升级脚本
+func (s *schemaStore) UpgradeSchema(opt *store.SchemaUpgradeOption) error {
+	out := opt.Output
+	if out == nil {
+		out = io.Discard
+	}
+	if opt.DryRun {
+		return s.dryRun(opt, out)
+	}
+	if err := s.prepare(); err != nil {
+		return err
+	}
+
+	tx, err := s.master.Begin()
+	if err != nil {
+		return err
+	}
+	lock := &transaction{tx: tx}
+	defer func() {
+		_ = lock.Commit()
+	}()
+	// 升级脚本中都是 DDL 语句，会隐式提交所在的事务，因此只用事务持有锁，脚本在另外的连接上执行
+	if err := lock.LockBootstrap(schemaLockKey, utils.LocalHost); err != nil {
+		return err
+	}
+
+	// 加锁后重新读取版本，其他节点可能已经完成了升级
+	current, err := s.resolveVersion(opt.Baseline, true)
+	if err != nil {
+		return err
+	}
+	latest := latestSchemaVersion(s.scripts)
+	if current.compare(latest) > 0 {
+		return fmt.Errorf("database schema version %s is newer than %s supported by this server", current, latest)
+	}
+	pending, err := pendingScripts(s.scripts, current)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "current schema version: %s, latest schema version: %s\n", current, latest)
+	for _, script := range pending {
+		_, _ = fmt.Fprintf(out, "apply %s\n", script.name)
+		if err := s.apply(script); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *schemaStore) dryRun(opt *store.SchemaUpgradeOption, out io.Writer) error {
+	current, err := s.resolveVersion(opt.Baseline, false)
+	if err != nil {
+		return err
+	}
+	latest := latestSchemaVersion(s.scripts)
+	_, _ = fmt.Fprintf(out, "current schema version: %s, latest schema version: %s\n", current, latest)
+	if current.compare(latest) > 0 {
+		return fmt.Errorf("database schema version %s is newer than %s supported by this server", current, latest)
+	}
+	pending, err := pendingScripts(s.scripts, current)
+	if err != nil {
+		return err
+	}
+	for _, script := range pending {
+		statements, err := script.readStatements()
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "\n-- %s\n", script.name)
+		for _, stmt := range statements {
+			_, _ = fmt.Fprintf(out, "%s;\n", stmt)
+		}
+	}
+	return nil
+}
+
+// resolveVersion 获取数据库当前的表结构版本，没有版本记录时使用 baseline，record 为 true 时将 baseline 写入版本表
+func (s *schemaStore) resolveVersion(baseline string, record bool) (schemaVersion, error) {
+	current, found, err := s.currentVersion()
+	if err != nil {
+		return current, err
+	}
+	if found {
+		if baseline != "" {
+			log.Warnf("[Store][database] schema version %s already recorded, ignore baseline %s", current, baseline)
+		}
+		return current, nil
+	}
+	if baseline == "" {
+		return current, ErrUnknownSchemaVersion
+	}
+	if current, err = parseSchemaVersion(baseline); err != nil {
+		return current, err
+	}
+	if record {
+		if err := s.recordVersion(current, baselineScript); err != nil {
+			return current, err
+		}
+	}
+	return current, nil
+}
+
+// currentVersion 读取版本表中记录的最高版本，found 为 false 表示没有任何版本记录
+func (s *schemaStore) currentVersion() (schemaVersion, bool, error) {
+	var current schemaVersion
+	var count int
+	countSql := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() " +
+		" AND table_name = 'schema_version'"
+	if err := s.master.QueryRow(countSql).Scan(&count); err != nil {
+		log.Errorf("[Store][database] check schema version table err: %s", err.Error())
+		return current, false, store.Error(err)
+	}
+	if count == 0 {
+		return current, false, nil
+	}
+
+	rows, err := s.master.Query("SELECT version FROM schema_version")
+	if err != nil {
+		log.Errorf("[Store][database] query schema version err: %s", err.Error())
+		return current, false, store.Error(err)
+	}
+	defer rows.Close()
+	found := false
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return current, false, store.Error(err)
+		}
+		version, err := parseSchemaVersion(value)
+		if err != nil {
+			return current, false, err
+		}
+		if !found || version.compare(current) > 0 {
+			current = version
+		}
+		found = true
+	}
+	return current, found, rows.Err()
+}
+
+// prepare 创建版本表以及升级锁，兼容没有这两项数据的存量数据库
+func (s *schemaStore) prepare() error {
+	createSql := "CREATE TABLE IF NOT EXISTS schema_version (" +
+		" version VARCHAR(32) NOT NULL COMMENT 'schema version'," +
+		" script VARCHAR(128) NOT NULL COMMENT 'applied script'," +
+		" ctime TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'applied time'," +
+		" PRIMARY KEY (version)) ENGINE = InnoDB"
+	if _, err := s.master.Exec(createSql); err != nil {
+		log.Errorf("[Store][database] create schema version table err: %s", err.Error())
+		return store.Error(err)
+	}
+	lockSql := "INSERT IGNORE INTO start_lock (lock_id, lock_key, server) VALUES (1, ?, '')"
+	if _, err := s.master.Exec(lockSql, schemaLockKey); err != nil {
+		log.Errorf("[Store][database] insert schema upgrade lock err: %s", err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// apply 依次执行脚本中的语句，全部成功后记录版本
+func (s *schemaStore) apply(script *deltaScript) error {
+	statements, err := script.readStatements()
+	if err != nil {
+		return err
+	}
+	log.Infof("[Store][database] apply schema delta script %s", script.name)
+	for _, stmt := range statements {
+		if _, err := s.master.Exec(stmt); err != nil {
+			log.Errorf("[Store][database] apply schema delta script %s, exec %s err: %s",
+				script.name, stmt, err.Error())
+			return fmt.Errorf("apply %s: %w", script.name, err)
+		}
+	}
+	return s.recordVersion(script.to, script.name)
+}
+
+func (s *schemaStore) recordVersion(version schemaVersion, script string) error {
+	insertSql := "INSERT INTO schema_version (version, script, ctime) VALUES (?, ?, sysdate())"
+	if _, err := s.master.Exec(insertSql, version.String(), script); err != nil {
+		log.Errorf("[Store][database] record schema version %s err: %s", version, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
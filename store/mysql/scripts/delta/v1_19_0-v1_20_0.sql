@@ -0,0 +1,103 @@
+/*
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+--
+-- Database: `polaris_server`
+--
+USE `polaris_server`;
+
+-- 资源变更日志，用于多个节点之间通知缓存刷新
+CREATE TABLE
+    `change_log` (
+        `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'change log id',
+        `resource` VARCHAR(64) NOT NULL COMMENT 'changed resource type',
+        `server` VARCHAR(128) NOT NULL COMMENT 'server which changed the resource',
+        `ctime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'change time',
+        PRIMARY KEY (`id`),
+        KEY `ctime` (`ctime`)
+    ) ENGINE = InnoDB;
+
+-- 内置证书颁发机构的根证书，私钥经过加密后保存
+CREATE TABLE
+    `ca_key_pair` (
+        `generation` BIGINT UNSIGNED NOT NULL COMMENT 'root certificate generation',
+        `cert` TEXT NOT NULL COMMENT 'root certificate in PEM',
+        `key_cipher` TEXT NOT NULL COMMENT 'encrypted private key in PEM',
+        `crypto_algo` VARCHAR(32) NOT NULL DEFAULT '' COMMENT 'crypto algorithm of the private key',
+        `not_before` BIGINT NOT NULL COMMENT 'unix seconds the certificate becomes valid',
+        `not_after` BIGINT NOT NULL COMMENT 'unix seconds the certificate expires',
+        `retire_time` BIGINT NOT NULL DEFAULT 0 COMMENT 'unix seconds the certificate leaves the trust bundle, 0 means active',
+        `ctime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'create time',
+        `mtime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'last update time',
+        PRIMARY KEY (`generation`)
+    ) ENGINE = InnoDB;
+
+-- 服务间访问策略，由 Envoy ext_authz 在被调服务的 Sidecar 上执行
+CREATE TABLE
+    `service_access_policy` (
+        `id` VARCHAR(128) NOT NULL COMMENT 'policy id',
+        `name` VARCHAR(100) NOT NULL COMMENT 'policy name',
+        `namespace` VARCHAR(64) NOT NULL COMMENT 'namespace of the destination service',
+        `service` VARCHAR(128) NOT NULL COMMENT 'destination service',
+        `action` VARCHAR(32) NOT NULL COMMENT 'ALLOW or DENY',
+        `sources` TEXT COMMENT 'source services in json',
+        `conditions` TEXT COMMENT 'method and path conditions in json',
+        `comment` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'describe',
+        `revision` VARCHAR(128) NOT NULL COMMENT 'policy revision',
+        `flag` TINYINT (4) NOT NULL DEFAULT '0' COMMENT 'Whether the policy is valid, 0 is valid, 1 is deleted',
+        `ctime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'create time',
+        `mtime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'last update time',
+        PRIMARY KEY (`id`),
+        KEY `destination` (`namespace`, `service`),
+        KEY `mtime` (`mtime`)
+    ) ENGINE = InnoDB;
+
+-- v1.20.0, heartbeatStore 健康检查插件持久化的实例心跳记录
+CREATE TABLE
+    `instance_heartbeat` (
+        `id` VARCHAR(128) NOT NULL COMMENT 'instance id',
+        `server` VARCHAR(128) NOT NULL DEFAULT '' COMMENT 'polaris server which received the last heartbeat',
+        `beat_time` BIGINT NOT NULL DEFAULT 0 COMMENT 'last heartbeat time in seconds',
+        `count` BIGINT NOT NULL DEFAULT 0 COMMENT 'heartbeat count of the not exist instance',
+        `mtime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'last update time',
+        PRIMARY KEY (`id`)
+    ) ENGINE = InnoDB;
+
+-- v1.20.0, 实例健康状态的变更历史
+CREATE TABLE
+    `instance_health_history` (
+        `seq` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+        `id` VARCHAR(128) NOT NULL COMMENT 'instance id',
+        `healthy` TINYINT(4) NOT NULL COMMENT 'health status after the transition, 1 is healthy',
+        `reason` VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'reason of the transition',
+        `detail` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'extra information of the transition',
+        `server` VARCHAR(128) NOT NULL DEFAULT '' COMMENT 'polaris server which made the decision',
+        `last_beat` BIGINT NOT NULL DEFAULT 0 COMMENT 'last heartbeat time in seconds',
+        `ctime` BIGINT NOT NULL COMMENT 'transition time in milliseconds',
+        PRIMARY KEY (`seq`),
+        KEY `instance` (`id`, `seq`)
+    ) ENGINE = InnoDB;
+
+-- v1.20.0, 按范围暂停健康检查的规则
+CREATE TABLE
+    `health_check_suspension` (
+        `id` VARCHAR(128) NOT NULL COMMENT 'suspension id',
+        `selector` TEXT NOT NULL COMMENT 'instances selected by the suspension in json',
+        `comment` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'describe',
+        `ctime` BIGINT NOT NULL COMMENT 'create time in milliseconds',
+        `etime` BIGINT NOT NULL COMMENT 'expire time in milliseconds',
+        PRIMARY KEY (`id`)
+    ) ENGINE = InnoDB;
@@ -425,7 +425,25 @@ CREATE TABLE
 INSERT INTO
     `start_lock` (`lock_id`, `lock_key`, `server`, `mtime`)
 VALUES
-    (1, 'sz', 'aaa', '2019-12-05 08:35:49');
+    (1, 'sz', 'aaa', '2019-12-05 08:35:49'),
+    (1, 'schema_upgrade', '', '2019-12-05 08:35:49');
+
+-- --------------------------------------------------------
+--
+-- Table structure `schema_version`
+--
+CREATE TABLE
+    `schema_version` (
+        `version` VARCHAR(32) NOT NULL COMMENT 'schema version',
+        `script` VARCHAR(128) NOT NULL COMMENT 'applied script',
+        `ctime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'applied time',
+        PRIMARY KEY (`version`)
+    ) ENGINE = InnoDB;
+
+INSERT INTO
+    `schema_version` (`version`, `script`, `ctime`)
+VALUES
+    ('1.20.0', 'polaris_server.sql', sysdate());
 
 -- --------------------------------------------------------
 --
@@ -967,6 +985,89 @@ CREATE TABLE
         UNIQUE KEY `name` (`group_name`, `name`)
     ) ENGINE = InnoDB;
 
+-- v1.20.0, 资源变更日志，用于多个节点之间通知缓存刷新
+CREATE TABLE
+    `change_log` (
+        `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'change log id',
+        `resource` VARCHAR(64) NOT NULL COMMENT 'changed resource type',
+        `server` VARCHAR(128) NOT NULL COMMENT 'server which changed the resource',
+        `ctime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'change time',
+        PRIMARY KEY (`id`),
+        KEY `ctime` (`ctime`)
+    ) ENGINE = InnoDB;
+
+-- v1.20.0, 内置证书颁发机构的根证书，私钥经过加密后保存
+CREATE TABLE
+    `ca_key_pair` (
+        `generation` BIGINT UNSIGNED NOT NULL COMMENT 'root certificate generation',
+        `cert` TEXT NOT NULL COMMENT 'root certificate in PEM',
+        `key_cipher` TEXT NOT NULL COMMENT 'encrypted private key in PEM',
+        `crypto_algo` VARCHAR(32) NOT NULL DEFAULT '' COMMENT 'crypto algorithm of the private key',
+        `not_before` BIGINT NOT NULL COMMENT 'unix seconds the certificate becomes valid',
+        `not_after` BIGINT NOT NULL COMMENT 'unix seconds the certificate expires',
+        `retire_time` BIGINT NOT NULL DEFAULT 0 COMMENT 'unix seconds the certificate leaves the trust bundle, 0 means active',
+        `ctime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'create time',
+        `mtime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'last update time',
+        PRIMARY KEY (`generation`)
+    ) ENGINE = InnoDB;
+
+-- v1.20.0, 服务间访问策略，由 Envoy ext_authz 在被调服务的 Sidecar 上执行
+CREATE TABLE
+    `service_access_policy` (
+        `id` VARCHAR(128) NOT NULL COMMENT 'policy id',
+        `name` VARCHAR(100) NOT NULL COMMENT 'policy name',
+        `namespace` VARCHAR(64) NOT NULL COMMENT 'namespace of the destination service',
+        `service` VARCHAR(128) NOT NULL COMMENT 'destination service',
+        `action` VARCHAR(32) NOT NULL COMMENT 'ALLOW or DENY',
+        `sources` TEXT COMMENT 'source services in json',
+        `conditions` TEXT COMMENT 'method and path conditions in json',
+        `comment` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'describe',
+        `revision` VARCHAR(128) NOT NULL COMMENT 'policy revision',
+        `flag` TINYINT (4) NOT NULL DEFAULT '0' COMMENT 'Whether the policy is valid, 0 is valid, 1 is deleted',
+        `ctime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'create time',
+        `mtime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'last update time',
+        PRIMARY KEY (`id`),
+        KEY `destination` (`namespace`, `service`),
+        KEY `mtime` (`mtime`)
+    ) ENGINE = InnoDB;
+
+-- heartbeatStore 健康检查插件持久化的实例心跳记录
+CREATE TABLE
+    `instance_heartbeat` (
+        `id` VARCHAR(128) NOT NULL COMMENT 'instance id',
+        `server` VARCHAR(128) NOT NULL DEFAULT '' COMMENT 'polaris server which received the last heartbeat',
+        `beat_time` BIGINT NOT NULL DEFAULT 0 COMMENT 'last heartbeat time in seconds',
+        `count` BIGINT NOT NULL DEFAULT 0 COMMENT 'heartbeat count of the not exist instance',
+        `mtime` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'last update time',
+        PRIMARY KEY (`id`)
+    ) ENGINE = InnoDB;
+
+-- 实例健康状态的变更历史
+CREATE TABLE
+    `instance_health_history` (
+        `seq` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+        `id` VARCHAR(128) NOT NULL COMMENT 'instance id',
+        `healthy` TINYINT(4) NOT NULL COMMENT 'health status after the transition, 1 is healthy',
+        `reason` VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'reason of the transition',
+        `detail` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'extra information of the transition',
+        `server` VARCHAR(128) NOT NULL DEFAULT '' COMMENT 'polaris server which made the decision',
+        `last_beat` BIGINT NOT NULL DEFAULT 0 COMMENT 'last heartbeat time in seconds',
+        `ctime` BIGINT NOT NULL COMMENT 'transition time in milliseconds',
+        PRIMARY KEY (`seq`),
+        KEY `instance` (`id`, `seq`)
+    ) ENGINE = InnoDB;
+
+-- 按范围暂停健康检查的规则
+CREATE TABLE
+    `health_check_suspension` (
+        `id` VARCHAR(128) NOT NULL COMMENT 'suspension id',
+        `selector` TEXT NOT NULL COMMENT 'instances selected by the suspension in json',
+        `comment` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'describe',
+        `ctime` BIGINT NOT NULL COMMENT 'create time in milliseconds',
+        `etime` BIGINT NOT NULL COMMENT 'expire time in milliseconds',
+        PRIMARY KEY (`id`)
+    ) ENGINE = InnoDB;
+
 
 /* 默认资源信息数据插入 */
 
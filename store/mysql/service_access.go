@@ -0,0 +1,149 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package sqldb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	"github.com/polarismesh/polaris/common/utils"
+	"github.com/polarismesh/polaris/store"
+)
+
+const selectServiceAccessPolicySql = "SELECT id, name, namespace, service, action, sources, conditions, comment, " +
+	"revision, flag, UNIX_TIMESTAMP(ctime), UNIX_TIMESTAMP(mtime) FROM service_access_policy "
+
+// serviceAccessStore 实现了 store.ServiceAccessPolicyStore
+type serviceAccessStore struct {
+	master *BaseDB
+	slave  *BaseDB
+}
+
+// AddServiceAccessPolicy 新增服务访问策略
+func (s *serviceAccessStore) AddServiceAccessPolicy(policy *authcommon.ServiceAccessPolicy) error {
+	str := "INSERT INTO service_access_policy (id, name, namespace, service, action, sources, conditions, " +
+		"comment, revision, flag, ctime, mtime) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0, sysdate(), sysdate())"
+	if _, err := s.master.Exec(str, policy.ID, policy.Name, policy.Namespace, policy.Service, policy.Action,
+		utils.MustJson(policy.Sources), utils.MustJson(policy.Conditions), policy.Comment,
+		policy.Revision); err != nil {
+		log.Errorf("[Store][database] add service access policy(%s) err: %s", policy.ID, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// UpdateServiceAccessPolicy 更新服务访问策略
+func (s *serviceAccessStore) UpdateServiceAccessPolicy(policy *authcommon.ServiceAccessPolicy) error {
+	str := "UPDATE service_access_policy SET name = ?, action = ?, sources = ?, conditions = ?, comment = ?, " +
+		"revision = ?, mtime = sysdate() WHERE id = ? AND flag = 0"
+	if _, err := s.master.Exec(str, policy.Name, policy.Action, utils.MustJson(policy.Sources),
+		utils.MustJson(policy.Conditions), policy.Comment, policy.Revision, policy.ID); err != nil {
+		log.Errorf("[Store][database] update service access policy(%s) err: %s", policy.ID, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// DeleteServiceAccessPolicy 删除服务访问策略
+func (s *serviceAccessStore) DeleteServiceAccessPolicy(id string) error {
+	str := "UPDATE service_access_policy SET flag = 1, mtime = sysdate() WHERE id = ?"
+	if _, err := s.master.Exec(str, id); err != nil {
+		log.Errorf("[Store][database] delete service access policy(%s) err: %s", id, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetServiceAccessPolicy 获取单个服务访问策略
+func (s *serviceAccessStore) GetServiceAccessPolicy(id string) (*authcommon.ServiceAccessPolicy, error) {
+	rows, err := s.master.Query(selectServiceAccessPolicySql+"WHERE id = ? AND flag = 0", id)
+	if err != nil {
+		log.Errorf("[Store][database] get service access policy(%s) err: %s", id, err.Error())
+		return nil, store.Error(err)
+	}
+	policies, err := fetchServiceAccessPolicyRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return policies[0], nil
+}
+
+// GetMoreServiceAccessPolicies 获取增量的服务访问策略
+func (s *serviceAccessStore) GetMoreServiceAccessPolicies(mtime time.Time,
+	firstUpdate bool) ([]*authcommon.ServiceAccessPolicy, error) {
+	str := selectServiceAccessPolicySql + "WHERE mtime >= FROM_UNIXTIME(?)"
+	if firstUpdate {
+		str += " AND flag = 0"
+	}
+	rows, err := s.slave.Query(str, timeToTimestamp(mtime))
+	if err != nil {
+		log.Errorf("[Store][database] get more service access policies err: %s", err.Error())
+		return nil, store.Error(err)
+	}
+	return fetchServiceAccessPolicyRows(rows)
+}
+
+func fetchServiceAccessPolicyRows(rows *sql.Rows) ([]*authcommon.ServiceAccessPolicy, error) {
+	defer rows.Close()
+
+	policies := make([]*authcommon.ServiceAccessPolicy, 0, 4)
+	for rows.Next() {
+		var (
+			item                = &authcommon.ServiceAccessPolicy{}
+			sources, conditions string
+			flag                int
+			ctime, mtime        int64
+		)
+		if err := rows.Scan(&item.ID, &item.Name, &item.Namespace, &item.Service, &item.Action, &sources,
+			&conditions, &item.Comment, &item.Revision, &flag, &ctime, &mtime); err != nil {
+			log.Errorf("[Store][database] scan service access policy err: %s", err.Error())
+			return nil, store.Error(err)
+		}
+		if err := unmarshalServiceAccessRules(item, sources, conditions); err != nil {
+			log.Errorf("[Store][database] unmarshal service access policy(%s) err: %s", item.ID, err.Error())
+			return nil, store.Error(err)
+		}
+		item.Valid = flag == 0
+		item.CreateTime = time.Unix(ctime, 0)
+		item.ModifyTime = time.Unix(mtime, 0)
+		policies = append(policies, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, store.Error(err)
+	}
+	return policies, nil
+}
+
+func unmarshalServiceAccessRules(policy *authcommon.ServiceAccessPolicy, sources, conditions string) error {
+	if sources != "" {
+		if err := json.Unmarshal([]byte(sources), &policy.Sources); err != nil {
+			return err
+		}
+	}
+	if conditions != "" {
+		if err := json.Unmarshal([]byte(conditions), &policy.Conditions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+package postgresql
+
+import (
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+// caStore 实现了 store.CAStore
+type caStore struct {
+	master *BaseDB
+}
+
+// AddCAKeyPair 新增根证书
+func (c *caStore) AddCAKeyPair(pair *model.CAKeyPair) error {
+	str := "INSERT INTO ca_key_pair (generation, cert, key_cipher, crypto_algo, not_before, " +
+		"not_after, retire_time, ctime, mtime) VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)"
+	var retireTime int64
+	if !pair.RetireTime.IsZero() {
+		retireTime = pair.RetireTime.Unix()
+	}
+	if _, err := c.master.Exec(str, pair.Generation, pair.Cert, pair.KeyCipher, pair.CryptoAlgo,
+		pair.NotBefore.Unix(), pair.NotAfter.Unix(), retireTime); err != nil {
+		log.Errorf("[Store][postgresql] add ca key pair(%d) err: %s", pair.Generation, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// RetireCAKeyPair 设置根证书的退役时间
+func (c *caStore) RetireCAKeyPair(generation uint64, retireTime time.Time) error {
+	str := "UPDATE ca_key_pair SET retire_time = ?, mtime = CURRENT_TIMESTAMP WHERE generation = ?"
+	if _, err := c.master.Exec(str, retireTime.Unix(), generation); err != nil {
+		log.Errorf("[Store][postgresql] retire ca key pair(%d) err: %s", generation, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetCAKeyPairs 获取全部根证书
+func (c *caStore) GetCAKeyPairs() ([]*model.CAKeyPair, error) {
+	str := "SELECT generation, cert, key_cipher, crypto_algo, not_before, not_after, retire_time, " +
+		"CAST(EXTRACT(EPOCH FROM ctime) AS BIGINT), CAST(EXTRACT(EPOCH FROM mtime) AS BIGINT) " +
+		"FROM ca_key_pair ORDER BY generation"
+	rows, err := c.master.Query(str)
+	if err != nil {
+		log.Errorf("[Store][postgresql] get ca key pairs err: %s", err.Error())
+		return nil, store.Error(err)
+	}
+	defer rows.Close()
+
+	pairs := make([]*model.CAKeyPair, 0, 2)
+	for rows.Next() {
+		var (
+			item                                          = &model.CAKeyPair{}
+			notBefore, notAfter, retireTime, ctime, mtime int64
+		)
+		if err := rows.Scan(&item.Generation, &item.Cert, &item.KeyCipher, &item.CryptoAlgo,
+			&notBefore, &notAfter, &retireTime, &ctime, &mtime); err != nil {
+			log.Errorf("[Store][postgresql] scan ca key pair err: %s", err.Error())
+			return nil, store.Error(err)
+		}
+		item.NotBefore = time.Unix(notBefore, 0)
+		item.NotAfter = time.Unix(notAfter, 0)
+		if retireTime > 0 {
+			item.RetireTime = time.Unix(retireTime, 0)
+		}
+		item.CreateTime = time.Unix(ctime, 0)
+		item.ModifyTime = time.Unix(mtime, 0)
+		pairs = append(pairs, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, store.Error(err)
+	}
+	return pairs, nil
+}
+
+// DeleteCAKeyPair 删除根证书
+func (c *caStore) DeleteCAKeyPair(generation uint64) error {
+	if _, err := c.master.Exec("DELETE FROM ca_key_pair WHERE generation = ?", generation); err != nil {
+		log.Errorf("[Store][postgresql] delete ca key pair(%d) err: %s", generation, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
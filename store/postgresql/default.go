@@ -0,0 +1,374 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/polarismesh/polaris/plugin"
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	// SystemNamespace system namespace
+	SystemNamespace = "Polaris"
+	// STORENAME database storage name
+	STORENAME = "postgresqlStore"
+	// DefaultConnMaxLifetime default maximum connection lifetime
+	DefaultConnMaxLifetime = 60 * 30 // 默认是30分钟
+	// reconnectInterval 启动时数据库不可用，重新检查连接的间隔
+	reconnectInterval = 5 * time.Second
+	// DefaultSSLMode 默认不开启 SSL 连接
+	DefaultSSLMode = "disable"
+	// emptyEnableTime 规则禁用时启用时间的默认值
+	emptyEnableTime = "CAST('1980-01-01 00:00:01' AS TIMESTAMPTZ)"
+)
+
+// init 自动引入包初始化函数
+func init() {
+	s := &stableStore{}
+	_ = store.RegisterStore(s)
+}
+
+// stableStore 实现了Store接口
+type stableStore struct {
+	*namespaceStore
+
+	// 服务治理中心 stores
+	*serviceStore
+	*instanceStore
+	*routingConfigStore
+	*l5Store
+	*rateLimitStore
+	*circuitBreakerStore
+	*faultDetectRuleStore
+	*routingConfigStoreV2
+	*serviceContractStore
+	*laneStore
+
+	// 配置中心 stores
+	*configFileGroupStore
+	*configFileStore
+	*configFileReleaseStore
+	*configFileReleaseHistoryStore
+	*configFileTemplateStore
+
+	*clientStore
+	*adminStore
+	*toolStore
+	*grayStore
+	*migrateStore
+	*changeLogStore
+	*caStore
+	*serviceAccessStore
+	*healthHistoryStore
+	*healthSuspensionStore
+	*schemaStore
+
+	*userStore
+	*groupStore
+	*strategyStore
+	*roleStore
+
+	// 主数据库，可以进行读写，存储只读时拒绝写入
+	master *BaseDB
+	// internal 不受存储只读限制的主数据库，供选主、变更日志、心跳等内部数据的存储使用
+	internal *BaseDB
+	// 备数据库，提供只读
+	slave *BaseDB
+	start bool
+	// stopCh 停止启动时数据库不可用而在后台进行的表结构检查
+	stopCh chan struct{}
+}
+
+// Name 实现Name函数
+func (s *stableStore) Name() string {
+	return STORENAME
+}
+
+// Initialize 初始化函数
+func (s *stableStore) Initialize(conf *store.Config) error {
+	if s.start {
+		return nil
+	}
+
+	masterConfig, slaveConfig, err := parseDatabaseConf(conf.Option)
+	if err != nil {
+		return err
+	}
+	master, err := NewBaseDB(masterConfig, plugin.GetParsePassword())
+	if err != nil {
+		return err
+	}
+	// 各个子存储通过 master 写入，存储只读时拒绝写入；表结构升级、只读查询不受影响
+	s.master = master.writeGuarded()
+	s.internal = master
+
+	if slaveConfig != nil {
+		log.Infof("[Store][postgresql] use slave database config: %+v", slaveConfig)
+		slave, err := NewBaseDB(slaveConfig, plugin.GetParsePassword())
+		if err != nil {
+			return err
+		}
+		s.slave = slave
+	}
+	// 如果slave为空，意味着slaveConfig为空，用master数据库替代
+	if s.slave == nil {
+		s.slave = master
+	}
+
+	if s.schemaStore, err = newSchemaStore(master); err != nil {
+		return err
+	}
+	// 默认自动执行尚未应用的表结构升级脚本
+	autoUpgrade := true
+	if v, ok := conf.Option["autoUpgrade"].(bool); ok {
+		autoUpgrade = v
+	}
+	if err := master.Ping(); err != nil {
+		// 数据库不可用时不阻塞启动，由缓存快照提供只读服务，连接恢复后再检查表结构
+		log.Errorf("[Store][postgresql] database is unreachable, continue booting and keep connecting: %s", err.Error())
+		s.stopCh = make(chan struct{})
+		go s.checkSchemaLater(autoUpgrade, s.stopCh)
+	} else {
+		log.Infof("[Store][postgresql] connect the database successfully")
+		if err := s.schemaStore.checkSchema(autoUpgrade); err != nil {
+			return err
+		}
+	}
+
+	s.start = true
+	s.newStore()
+	return nil
+}
+
+// checkSchemaLater 等待数据库可以连接后再检查表结构
+func (s *stableStore) checkSchemaLater(autoUpgrade bool, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(reconnectInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stopCh:
+			return
+		}
+		if err := s.schemaStore.master.Ping(); err != nil {
+			log.Warnf("[Store][postgresql] database is still unreachable: %s", err.Error())
+			continue
+		}
+		log.Infof("[Store][postgresql] connect the database successfully")
+		if err := s.schemaStore.checkSchema(autoUpgrade); err != nil {
+			log.Errorf("[Store][postgresql] check database schema err: %s", err.Error())
+		}
+		return
+	}
+}
+
+// parseDatabaseConf return slave, master, error
+func parseDatabaseConf(opt map[string]interface{}) (*dbConfig, *dbConfig, error) {
+	// 必填
+	masterEnter, ok := opt["master"]
+	if !ok || masterEnter == nil {
+		return nil, nil, errors.New("database master db config is missing")
+	}
+	masterConfig, err := parseStoreConfig(masterEnter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 只读数据库可选
+	slaveEntry, ok := opt["slave"]
+	if !ok || slaveEntry == nil {
+		return masterConfig, nil, nil
+	}
+	slaveConfig, err := parseStoreConfig(slaveEntry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return masterConfig, slaveConfig, nil
+}
+
+// parseStoreConfig 解析store的配置
+func parseStoreConfig(opts interface{}) (*dbConfig, error) {
+	obj, _ := opts.(map[interface{}]interface{})
+
+	needCheckFields := map[string]string{"dbUser": "", "dbPwd": "", "dbAddr": "", "dbName": ""}
+
+	for key := range needCheckFields {
+		val, ok := obj[key]
+		if !ok {
+			return nil, fmt.Errorf("config Plugin %s:%s type must be string", STORENAME, key)
+		}
+		if val != nil {
+			needCheckFields[key] = fmt.Sprintf("%v", val)
+		} else {
+			log.Warnf("[Store][postgresql] config field is empty: %s", key)
+		}
+	}
+
+	c := &dbConfig{
+		dbUser: needCheckFields["dbUser"],
+		dbPwd:  needCheckFields["dbPwd"],
+		dbAddr: needCheckFields["dbAddr"],
+		dbName: needCheckFields["dbName"],
+	}
+	if maxOpenConns, _ := obj["maxOpenConns"].(int); maxOpenConns > 0 {
+		c.maxOpenConns = maxOpenConns
+	}
+	if maxIdleConns, _ := obj["maxIdleConns"].(int); maxIdleConns > 0 {
+		c.maxIdleConns = maxIdleConns
+	}
+	c.sslMode = DefaultSSLMode
+	if sslMode, _ := obj["sslMode"].(string); sslMode != "" {
+		c.sslMode = sslMode
+	}
+	c.connMaxLifetime = DefaultConnMaxLifetime
+	if connMaxLifetime, _ := obj["connMaxLifetime"].(int); connMaxLifetime > 0 {
+		c.connMaxLifetime = connMaxLifetime
+	}
+
+	if isolationLevel, _ := obj["txIsolationLevel"].(int); isolationLevel > 0 {
+		c.txIsolationLevel = isolationLevel
+	}
+	return c, nil
+}
+
+// Destroy 退出函数
+func (s *stableStore) Destroy() error {
+	s.start = false
+	if s.stopCh != nil {
+		close(s.stopCh)
+		s.stopCh = nil
+	}
+	if s.master != nil {
+		_ = s.master.Close()
+	}
+	if s.slave != nil {
+		_ = s.slave.Close()
+	}
+
+	if s.adminStore != nil {
+		s.adminStore.StopLeaderElections()
+	}
+
+	s.master = nil
+	s.slave = nil
+	s.internal = nil
+
+	return nil
+}
+
+// CreateTransaction 创建一个事务
+func (s *stableStore) CreateTransaction() (store.Transaction, error) {
+	// 每次创建事务前，还是需要ping一下
+	_ = s.master.Ping()
+
+	nt := &transaction{}
+	tx, err := s.master.Begin()
+	if err != nil {
+		log.Errorf("[Store][postgresql] database begin err: %s", err.Error())
+		return nil, err
+	}
+
+	nt.tx = tx
+	return nt, nil
+}
+
+func (s *stableStore) StartTx() (store.Tx, error) {
+	// 每次创建事务前，还是需要ping一下
+	_ = s.master.Ping()
+
+	tx, err := s.master.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return NewSqlDBTx(tx), nil
+}
+
+func (s *stableStore) StartReadTx() (store.Tx, error) {
+	// 每次创建事务前，还是需要ping一下
+	_ = s.slave.Ping()
+
+	tx, err := s.slave.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return NewSqlDBTx(tx), nil
+}
+
+// ReadView 返回一个全部读操作都在 tx 中执行的 store
+func (s *stableStore) ReadView(tx store.Tx) (store.Store, error) {
+	dbTx, ok := tx.GetDelegateTx().(*BaseTx)
+	if !ok || dbTx == nil {
+		return nil, errors.New("read view requires a transaction started by StartReadTx")
+	}
+	view := s.slave.readView(dbTx)
+	ret := &stableStore{master: view, slave: view, internal: view, start: true, schemaStore: s.schemaStore}
+	ret.newStore()
+	return ret, nil
+}
+
+// newStore 初始化子类
+func (s *stableStore) newStore() {
+	s.namespaceStore = &namespaceStore{master: s.master, slave: s.slave}
+
+	s.serviceStore = &serviceStore{master: s.master, slave: s.slave}
+	s.instanceStore = &instanceStore{master: s.master, slave: s.slave}
+	s.routingConfigStore = &routingConfigStore{master: s.master, slave: s.slave}
+	s.l5Store = &l5Store{master: s.master, slave: s.slave}
+	s.rateLimitStore = &rateLimitStore{master: s.master, slave: s.slave}
+	s.circuitBreakerStore = &circuitBreakerStore{master: s.master, slave: s.slave}
+	s.faultDetectRuleStore = &faultDetectRuleStore{master: s.master, slave: s.slave}
+	s.routingConfigStoreV2 = &routingConfigStoreV2{master: s.master, slave: s.slave}
+	s.serviceContractStore = &serviceContractStore{master: s.master, slave: s.slave}
+	s.laneStore = &laneStore{master: s.master, slave: s.slave}
+
+	s.configFileGroupStore = &configFileGroupStore{master: s.master, slave: s.slave}
+	s.configFileStore = &configFileStore{master: s.master, slave: s.slave}
+	s.configFileReleaseStore = &configFileReleaseStore{master: s.master, slave: s.slave}
+	s.configFileReleaseHistoryStore = &configFileReleaseHistoryStore{master: s.master, slave: s.slave}
+	s.configFileTemplateStore = &configFileTemplateStore{master: s.master, slave: s.slave}
+	s.clientStore = &clientStore{master: s.master, slave: s.slave}
+
+	s.grayStore = &grayStore{master: s.master, slave: s.slave}
+
+	s.adminStore = newAdminStore(s.internal)
+	s.toolStore = &toolStore{db: s.master}
+	s.migrateStore = &migrateStore{master: s.master}
+	s.changeLogStore = &changeLogStore{master: s.internal}
+	s.caStore = &caStore{master: s.master}
+	s.serviceAccessStore = &serviceAccessStore{master: s.master, slave: s.slave}
+	s.healthHistoryStore = &healthHistoryStore{master: s.internal}
+	s.healthSuspensionStore = &healthSuspensionStore{master: s.internal}
+
+	s.userStore = &userStore{master: s.master, slave: s.slave}
+	s.groupStore = &groupStore{master: s.master, slave: s.slave}
+	s.strategyStore = &strategyStore{master: s.master, slave: s.slave}
+	s.roleStore = &roleStore{master: s.master, slave: s.slave}
+}
+
+func buildEtimeStr(enable bool) string {
+	etimeStr := "CURRENT_TIMESTAMP"
+	if !enable {
+		etimeStr = emptyEnableTime
+	}
+	return etimeStr
+}
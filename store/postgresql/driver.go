@@ -0,0 +1,150 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// driverName 注册到 database/sql 中的驱动名称
+	driverName = "polaris-postgres"
+)
+
+func init() {
+	sql.Register(driverName, &pgDriver{})
+}
+
+// pgDriver 对 pq 驱动的封装，负责在下发 SQL 之前将 '?' 占位符转换为 PostgreSQL 的 '$n' 形式，
+// 使得各个存储实现可以像 MySQL 一样通过字符串拼接的方式动态构造查询条件
+type pgDriver struct {
+	delegate pq.Driver
+}
+
+// Open 实现 driver.Driver 接口
+func (d *pgDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.delegate.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &pgConn{conn: conn}, nil
+}
+
+// pgConn 对 pq 连接的封装
+type pgConn struct {
+	conn driver.Conn
+}
+
+// Prepare 实现 driver.Conn 接口
+func (c *pgConn) Prepare(query string) (driver.Stmt, error) {
+	return c.conn.Prepare(rebind(query))
+}
+
+// PrepareContext 实现 driver.ConnPrepareContext 接口
+func (c *pgConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.conn.(driver.ConnPrepareContext).PrepareContext(ctx, rebind(query))
+}
+
+// Close 实现 driver.Conn 接口
+func (c *pgConn) Close() error {
+	return c.conn.Close()
+}
+
+// Begin 实现 driver.Conn 接口
+func (c *pgConn) Begin() (driver.Tx, error) {
+	return c.conn.Begin() //nolint:staticcheck
+}
+
+// BeginTx 实现 driver.ConnBeginTx 接口
+func (c *pgConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+// ExecContext 实现 driver.ExecerContext 接口
+func (c *pgConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.conn.(driver.ExecerContext).ExecContext(ctx, rebind(query), args)
+}
+
+// QueryContext 实现 driver.QueryerContext 接口
+func (c *pgConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.conn.(driver.QueryerContext).QueryContext(ctx, rebind(query), args)
+}
+
+// Ping 实现 driver.Pinger 接口
+func (c *pgConn) Ping(ctx context.Context) error {
+	return c.conn.(driver.Pinger).Ping(ctx)
+}
+
+// ResetSession 实现 driver.SessionResetter 接口
+func (c *pgConn) ResetSession(ctx context.Context) error {
+	return c.conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+// IsValid 实现 driver.Validator 接口
+func (c *pgConn) IsValid() bool {
+	return c.conn.(driver.Validator).IsValid()
+}
+
+// CheckNamedValue 实现 driver.NamedValueChecker 接口
+// 表结构中的标记位沿用了 MySQL 的 TINYINT 语义，这里将 bool 参数统一转换为 1/0
+func (c *pgConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if v, ok := nv.Value.(bool); ok {
+		nv.Value = int64(boolToInt(v))
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+// rebind 将 SQL 中的 '?' 占位符替换为 '$1', '$2' ... 的形式
+// 字符串常量以及带引号的标识符中的 '?' 不做处理
+func rebind(query string) string {
+	if strings.IndexByte(query, '?') < 0 {
+		return query
+	}
+
+	var (
+		sb    strings.Builder
+		index int
+		quote byte
+	)
+	sb.Grow(len(query) + 16)
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '?':
+			index++
+			sb.WriteByte('$')
+			sb.WriteString(strconv.Itoa(index))
+			continue
+		}
+		sb.WriteByte(ch)
+	}
+	return sb.String()
+}
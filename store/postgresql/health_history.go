@@ -0,0 +1,126 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package postgresql
+
+import (
+	"strings"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	"github.com/polarismesh/polaris/store"
+)
+
+var _ store.InstanceHealthHistoryStore = (*healthHistoryStore)(nil)
+
+// healthHistoryStore 实现了 store.InstanceHealthHistoryStore
+type healthHistoryStore struct {
+	master *BaseDB
+}
+
+// BatchAppendInstanceHealthHistory 在一个事务中追加一批变更记录，并清理这些实例超出 maxSize 的旧记录
+func (h *healthHistoryStore) BatchAppendInstanceHealthHistory(records []*model.InstanceHealthTransition,
+	maxSize int) error {
+	if len(records) == 0 {
+		return nil
+	}
+	err := RetryTransaction("batchAppendInstanceHealthHistory", func() error {
+		tx, err := h.master.Begin()
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback() }()
+
+		values := make([]string, 0, len(records))
+		args := make([]interface{}, 0, len(records)*7)
+		instanceIDs := make([]string, 0, len(records))
+		exists := make(map[string]struct{}, len(records))
+		for _, record := range records {
+			values = append(values, "(?, ?, ?, ?, ?, ?, ?)")
+			args = append(args, record.InstanceID, model.StatusBoolToInt(record.Healthy), record.Reason,
+				record.Detail, record.Server, record.LastHeartbeatSec, record.Time.UnixMilli())
+			if _, ok := exists[record.InstanceID]; !ok {
+				exists[record.InstanceID] = struct{}{}
+				instanceIDs = append(instanceIDs, record.InstanceID)
+			}
+		}
+		str := "INSERT INTO instance_health_history (id, healthy, reason, detail, server, last_beat, ctime) " +
+			"VALUES " + strings.Join(values, ", ")
+		if _, err := tx.Exec(str, args...); err != nil {
+			return err
+		}
+		if maxSize > 0 {
+			// 找到第 maxSize + 1 新的记录，删除它以及更早的记录
+			str = "DELETE FROM instance_health_history WHERE id = ? AND seq <= " +
+				"(SELECT seq FROM instance_health_history WHERE id = ? ORDER BY seq DESC LIMIT 1 OFFSET ?)"
+			for _, instanceID := range instanceIDs {
+				if _, err := tx.Exec(str, instanceID, instanceID, maxSize); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		log.Errorf("[Store][postgresql] batch append %d instance health history err: %s", len(records), err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
+
+// GetInstanceHealthHistory 查询实例最近的变更记录
+func (h *healthHistoryStore) GetInstanceHealthHistory(instanceID string,
+	limit int) ([]*model.InstanceHealthTransition, error) {
+	str := "SELECT id, healthy, reason, detail, server, last_beat, ctime FROM instance_health_history " +
+		"WHERE id = ? ORDER BY seq DESC LIMIT ?"
+	rows, err := h.master.Query(str, instanceID, limit)
+	if err != nil {
+		log.Errorf("[Store][postgresql] get instance(%s) health history err: %s", instanceID, err.Error())
+		return nil, store.Error(err)
+	}
+	defer rows.Close()
+
+	var ret []*model.InstanceHealthTransition
+	for rows.Next() {
+		var (
+			record  = &model.InstanceHealthTransition{}
+			healthy int
+			ctime   int64
+		)
+		if err := rows.Scan(&record.InstanceID, &healthy, &record.Reason, &record.Detail, &record.Server,
+			&record.LastHeartbeatSec, &ctime); err != nil {
+			log.Errorf("[Store][postgresql] scan instance health history err: %s", err.Error())
+			return nil, store.Error(err)
+		}
+		record.Healthy = model.Int2bool(healthy)
+		record.Time = time.UnixMilli(ctime)
+		ret = append(ret, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, store.Error(err)
+	}
+	return ret, nil
+}
+
+// DeleteInstanceHealthHistory 删除实例的所有变更记录
+func (h *healthHistoryStore) DeleteInstanceHealthHistory(instanceID string) error {
+	if _, err := h.master.Exec("DELETE FROM instance_health_history WHERE id = ?", instanceID); err != nil {
+		log.Errorf("[Store][postgresql] delete instance(%s) health history err: %s", instanceID, err.Error())
+		return store.Error(err)
+	}
+	return nil
+}
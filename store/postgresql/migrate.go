@@ -0,0 +1,161 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/polarismesh/polaris/common/model"
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+	"github.com/polarismesh/polaris/store"
+)
+
+// migrateStore 实现了 store.MigrateStore
+type migrateStore struct {
+	master *BaseDB
+}
+
+// timestampRecord 需要回写时间的记录
+type timestampRecord struct {
+	table    string
+	ctimeCol string
+	mtimeCol string
+	ctime    time.Time
+	mtime    time.Time
+	// where 定位记录的查询条件
+	where string
+	args  []interface{}
+}
+
+// RestoreTimestamps 回写资源的创建时间、修改时间
+func (m *migrateStore) RestoreTimestamps(item interface{}) error {
+	for _, record := range toTimestampRecords(item) {
+		if err := m.restore(record); err != nil {
+			log.Errorf("[Store][postgresql] restore %s timestamps err: %s", record.table, err.Error())
+			return store.Error(err)
+		}
+	}
+	return nil
+}
+
+func (m *migrateStore) restore(record *timestampRecord) error {
+	sets := make([]string, 0, 2)
+	args := make([]interface{}, 0, 2+len(record.args))
+	if !record.ctime.IsZero() {
+		sets = append(sets, record.ctimeCol+" = to_timestamp(?)")
+		args = append(args, timeToTimestamp(record.ctime))
+	}
+	if !record.mtime.IsZero() {
+		sets = append(sets, record.mtimeCol+" = to_timestamp(?)")
+		args = append(args, timeToTimestamp(record.mtime))
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+	args = append(args, record.args...)
+	str := fmt.Sprintf("UPDATE %s SET %s WHERE %s", record.table, strings.Join(sets, ", "), record.where)
+	_, err := m.master.Exec(str, args...)
+	return err
+}
+
+// toTimestampRecords 根据资源类型找到对应的表及主键
+func toTimestampRecords(item interface{}) []*timestampRecord {
+	newRecord := func(table string, ctime, mtime time.Time, where string, args ...interface{}) *timestampRecord {
+		return &timestampRecord{table: table, ctimeCol: "ctime", mtimeCol: "mtime",
+			ctime: ctime, mtime: mtime, where: where, args: args}
+	}
+	newConfigRecord := func(table string, ctime, mtime time.Time, where string,
+		args ...interface{}) *timestampRecord {
+		return &timestampRecord{table: table, ctimeCol: "create_time", mtimeCol: "modify_time",
+			ctime: ctime, mtime: mtime, where: where, args: args}
+	}
+
+	switch v := item.(type) {
+	case *model.Namespace:
+		return []*timestampRecord{newRecord("namespace", v.CreateTime, v.ModifyTime,
+			"name = ?", v.Name)}
+	case *model.Service:
+		return []*timestampRecord{newRecord("service", v.CreateTime, v.ModifyTime,
+			"id = ?", v.ID)}
+	case *model.Instance:
+		return []*timestampRecord{newRecord("instance", time.Time{}, v.ModifyTime,
+			"id = ?", v.ID())}
+	case *model.RoutingConfig:
+		return []*timestampRecord{newRecord("routing_config", v.CreateTime, v.ModifyTime,
+			"id = ?", v.ID)}
+	case *model.RouterConfig:
+		return []*timestampRecord{newRecord("routing_config_v2", v.CreateTime, v.ModifyTime,
+			"id = ?", v.ID)}
+	case *model.RateLimit:
+		return []*timestampRecord{newRecord("ratelimit_config", v.CreateTime, v.ModifyTime,
+			"id = ?", v.ID)}
+	case *model.CircuitBreakerRule:
+		return []*timestampRecord{newRecord("circuitbreaker_rule_v2", v.CreateTime, v.ModifyTime,
+			"id = ?", v.ID)}
+	case *model.FaultDetectRule:
+		return []*timestampRecord{newRecord("fault_detect_rule", v.CreateTime, v.ModifyTime,
+			"id = ?", v.ID)}
+	case *model.EnrichServiceContract:
+		return []*timestampRecord{newRecord("service_contract", v.CreateTime, v.ModifyTime,
+			"id = ?", v.ID)}
+	case *model.LaneGroup:
+		records := []*timestampRecord{newRecord("lane_group", v.CreateTime, v.ModifyTime,
+			"id = ?", v.ID)}
+		for _, rule := range v.LaneRules {
+			records = append(records, newRecord("lane_rule", rule.CreateTime, rule.ModifyTime,
+				"id = ?", rule.ID))
+		}
+		return records
+	case *model.ConfigFileGroup:
+		return []*timestampRecord{newConfigRecord("config_file_group", v.CreateTime, v.ModifyTime,
+			"namespace = ? AND name = ?", v.Namespace, v.Name)}
+	case *model.ConfigFile:
+		return []*timestampRecord{newConfigRecord("config_file", v.CreateTime, v.ModifyTime,
+			`namespace = ? AND "group" = ? AND name = ?`, v.Namespace, v.Group, v.Name)}
+	case *model.ConfigFileRelease:
+		return []*timestampRecord{newConfigRecord("config_file_release", v.CreateTime, v.ModifyTime,
+			`namespace = ? AND "group" = ? AND file_name = ? AND name = ?`,
+			v.Namespace, v.Group, v.FileName, v.Name)}
+	case *model.ConfigFileReleaseHistory:
+		return []*timestampRecord{newConfigRecord("config_file_release_history", v.CreateTime, v.ModifyTime,
+			`namespace = ? AND "group" = ? AND file_name = ? AND name = ? AND version = ?`,
+			v.Namespace, v.Group, v.FileName, v.Name, v.Version)}
+	case *model.ConfigFileTemplate:
+		return []*timestampRecord{newConfigRecord("config_file_template", v.CreateTime, v.ModifyTime,
+			"name = ?", v.Name)}
+	case *model.GrayResource:
+		return []*timestampRecord{newConfigRecord("gray_resource", v.CreateTime, v.ModifyTime,
+			"name = ?", v.Name)}
+	case *authcommon.User:
+		return []*timestampRecord{newRecord("\"user\"", v.CreateTime, v.ModifyTime,
+			"id = ?", v.ID)}
+	case *authcommon.UserGroupDetail:
+		return []*timestampRecord{newRecord("user_group", v.CreateTime, v.ModifyTime,
+			"id = ?", v.ID)}
+	case *authcommon.StrategyDetail:
+		return []*timestampRecord{newRecord("auth_strategy", v.CreateTime, v.ModifyTime,
+			"id = ?", v.ID)}
+	case *authcommon.Role:
+		return []*timestampRecord{newRecord("auth_role", v.CreateTime, v.ModifyTime,
+			"id = ?", v.ID)}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,177 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package postgresql
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/polarismesh/polaris/store"
+)
+
+const (
+	schemaTableCountSql = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() " +
+		" AND table_name = 'schema_version'"
+)
+
+func Test_loadDeltaScripts(t *testing.T) {
+	scripts, err := loadDeltaScripts()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, scripts)
+	// 增量脚本必须首尾相连
+	for i := 1; i < len(scripts); i++ {
+		assert.Equal(t, scripts[i-1].to, scripts[i].from, scripts[i].name)
+	}
+
+	// 全量脚本中记录的版本需要与最新的增量脚本保持一致
+	content, err := os.ReadFile("scripts/polaris_server.sql")
+	assert.NoError(t, err)
+	matches := regexp.MustCompile(`\('([0-9.]+)', 'polaris_server.sql'`).FindStringSubmatch(string(content))
+	assert.Len(t, matches, 2)
+	assert.Equal(t, latestSchemaVersion(scripts).String(), matches[1])
+}
+
+func Test_pendingScripts(t *testing.T) {
+	scripts, err := loadDeltaScripts()
+	assert.NoError(t, err)
+
+	pending, err := pendingScripts(scripts, schemaVersion{1, 19, 0})
+	assert.NoError(t, err)
+	names := make([]string, 0, len(pending))
+	for _, script := range pending {
+		names = append(names, script.name)
+	}
+	assert.Equal(t, []string{"v1_19_0-v1_20_0.sql"}, names)
+
+	pending, err = pendingScripts(scripts, latestSchemaVersion(scripts))
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	_, err = pendingScripts(scripts, schemaVersion{1, 18, 0})
+	assert.Error(t, err)
+}
+
+func Test_parseSchemaVersion(t *testing.T) {
+	for _, s := range []string{"v1_18_1", "v1.18.1", "1.18.1"} {
+		v, err := parseSchemaVersion(s)
+		assert.NoError(t, err)
+		assert.Equal(t, schemaVersion{1, 18, 1}, v)
+	}
+	_, err := parseSchemaVersion("1.18")
+	assert.Error(t, err)
+	_, err = parseSchemaVersion("v1_x_0")
+	assert.Error(t, err)
+}
+
+func Test_splitStatements(t *testing.T) {
+	content := `
+-- comment; with semicolon
+/* block; comment */
+CREATE DATABASE polaris_server;
+ALTER TABLE service ADD COLUMN a VARCHAR(32) NOT NULL DEFAULT 'a;b';
+INSERT INTO t ("key;") VALUES ('x;y')
+`
+	statements := splitStatements(content)
+	assert.Equal(t, []string{
+		"CREATE DATABASE polaris_server",
+		"ALTER TABLE service ADD COLUMN a VARCHAR(32) NOT NULL DEFAULT 'a;b'",
+		"INSERT INTO t (\"key;\") VALUES ('x;y')",
+	}, statements)
+}
+
+func Test_deltaScript_readStatements(t *testing.T) {
+	scripts, err := loadDeltaScripts()
+	assert.NoError(t, err)
+	for _, script := range scripts {
+		statements, err := script.readStatements()
+		assert.NoError(t, err)
+		assert.NotEmpty(t, statements, script.name)
+		for _, stmt := range statements {
+			assert.NotRegexp(t, `(?i)^CREATE\s+DATABASE`, stmt)
+		}
+	}
+}
+
+func Test_schemaStore_checkSchema(t *testing.T) {
+	t.Run("没有版本记录", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(schemaTableCountSql).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		s, err := newSchemaStore(&BaseDB{DB: db})
+		assert.NoError(t, err)
+		assert.NoError(t, s.checkSchema(true))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("数据库版本高于程序版本", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(schemaTableCountSql).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery("SELECT version FROM schema_version").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("1.19.0").AddRow("99.0.0"))
+		s, err := newSchemaStore(&BaseDB{DB: db})
+		assert.NoError(t, err)
+		assert.Error(t, s.checkSchema(true))
+	})
+
+	t.Run("未开启自动升级", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(schemaTableCountSql).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery("SELECT version FROM schema_version").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("1.19.0"))
+		s, err := newSchemaStore(&BaseDB{DB: db})
+		assert.NoError(t, err)
+		assert.NoError(t, s.checkSchema(false))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func Test_schemaStore_UpgradeSchemaDryRun(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(schemaTableCountSql).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s, err := newSchemaStore(&BaseDB{DB: db})
+	assert.NoError(t, err)
+
+	// 没有版本记录且未指定基线版本
+	assert.ErrorIs(t, s.UpgradeSchema(&store.SchemaUpgradeOption{DryRun: true}), ErrUnknownSchemaVersion)
+
+	mock.ExpectQuery(schemaTableCountSql).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	buf := bytes.NewBuffer(nil)
+	err = s.UpgradeSchema(&store.SchemaUpgradeOption{DryRun: true, Baseline: "1.19.0", Output: buf})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "current schema version: 1.19.0")
+	assert.Contains(t, buf.String(), "-- v1_19_0-v1_20_0.sql")
+	assert.Contains(t, buf.String(), "CREATE TABLE\n    change_log")
+	// dry-run 不会写入任何数据
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
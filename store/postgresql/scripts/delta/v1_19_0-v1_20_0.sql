@@ -0,0 +1,94 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+--
+-- Database: polaris_server
+--
+
+-- 资源变更日志，用于多个节点之间通知缓存刷新
+CREATE TABLE
+    change_log (
+        id BIGSERIAL NOT NULL, -- change log id
+        resource VARCHAR(64) NOT NULL, -- changed resource type
+        server VARCHAR(128) NOT NULL, -- server which changed the resource
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- change time
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_change_log_ctime ON change_log (ctime);
+
+-- 内置证书颁发机构的根证书，私钥经过加密后保存
+CREATE TABLE
+    ca_key_pair (
+        generation BIGINT NOT NULL, -- root certificate generation
+        cert TEXT NOT NULL, -- root certificate in PEM
+        key_cipher TEXT NOT NULL, -- encrypted private key in PEM
+        crypto_algo VARCHAR(32) NOT NULL DEFAULT '', -- crypto algorithm of the private key
+        not_before BIGINT NOT NULL, -- unix seconds the certificate becomes valid
+        not_after BIGINT NOT NULL, -- unix seconds the certificate expires
+        retire_time BIGINT NOT NULL DEFAULT 0, -- unix seconds the certificate leaves the trust bundle, 0 means active
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- last update time
+        PRIMARY KEY (generation)
+    );
+
+-- 服务间访问策略，由 Envoy ext_authz 在被调服务的 Sidecar 上执行
+CREATE TABLE
+    service_access_policy (
+        id VARCHAR(128) NOT NULL, -- policy id
+        name VARCHAR(100) NOT NULL, -- policy name
+        namespace VARCHAR(64) NOT NULL, -- namespace of the destination service
+        service VARCHAR(128) NOT NULL, -- destination service
+        action VARCHAR(32) NOT NULL, -- ALLOW or DENY
+        sources TEXT, -- source services in json
+        conditions TEXT, -- method and path conditions in json
+        comment VARCHAR(255) NOT NULL DEFAULT '', -- describe
+        revision VARCHAR(128) NOT NULL, -- policy revision
+        flag SMALLINT NOT NULL DEFAULT '0', -- Whether the policy is valid, 0 is valid, 1 is deleted
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- last update time
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_service_access_policy_destination ON service_access_policy (namespace, service);
+CREATE INDEX idx_service_access_policy_mtime ON service_access_policy (mtime);
+
+-- 实例健康状态的变更历史
+CREATE TABLE
+    instance_health_history (
+        seq BIGSERIAL NOT NULL, -- primary key
+        id VARCHAR(128) NOT NULL, -- instance id
+        healthy SMALLINT NOT NULL, -- health status after the transition, 1 is healthy
+        reason VARCHAR(64) NOT NULL DEFAULT '', -- reason of the transition
+        detail VARCHAR(255) NOT NULL DEFAULT '', -- extra information of the transition
+        server VARCHAR(128) NOT NULL DEFAULT '', -- polaris server which made the decision
+        last_beat BIGINT NOT NULL DEFAULT 0, -- last heartbeat time in seconds
+        ctime BIGINT NOT NULL, -- transition time in milliseconds
+        PRIMARY KEY (seq)
+    );
+
+CREATE INDEX idx_instance_health_history_instance ON instance_health_history (id, seq);
+
+-- 按范围暂停健康检查的规则
+CREATE TABLE
+    health_check_suspension (
+        id VARCHAR(128) NOT NULL, -- suspension id
+        selector TEXT NOT NULL, -- instances selected by the suspension in json
+        comment VARCHAR(255) NOT NULL DEFAULT '', -- describe
+        ctime BIGINT NOT NULL, -- create time in milliseconds
+        etime BIGINT NOT NULL, -- expire time in milliseconds
+        PRIMARY KEY (id)
+    );
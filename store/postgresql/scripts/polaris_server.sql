@@ -0,0 +1,1603 @@
+/*
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+--
+-- Database: polaris_server
+--
+-- CREATE DATABASE polaris_server ENCODING 'UTF8';
+-- \c polaris_server
+--
+SET
+    TIME ZONE 'UTC';
+
+--
+-- 对应 MySQL 中的 ON UPDATE CURRENT_TIMESTAMP，更新时未显式设置时间字段的情况下自动刷新
+--
+CREATE OR REPLACE FUNCTION polaris_update_mtime() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.mtime IS NOT DISTINCT FROM OLD.mtime THEN
+        NEW.mtime = CURRENT_TIMESTAMP;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION polaris_update_modify_time() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.modify_time IS NOT DISTINCT FROM OLD.modify_time THEN
+        NEW.modify_time = CURRENT_TIMESTAMP;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- --------------------------------------------------------
+--
+-- Table structure instance
+--
+CREATE TABLE
+    instance (
+        id VARCHAR(128) NOT NULL, -- Unique ID
+        service_id VARCHAR(32) NOT NULL, -- Service ID
+        vpc_id VARCHAR(64) DEFAULT NULL, -- VPC ID
+        host VARCHAR(128) NOT NULL, -- instance Host Information
+        port INTEGER NOT NULL, -- instance port information
+        protocol VARCHAR(32) DEFAULT NULL, -- Listening protocols for corresponding ports, such as TPC, UDP, GRPC, DUBBO, etc.
+        version VARCHAR(32) DEFAULT NULL, -- The version of the instance can be used for version routing
+        health_status SMALLINT NOT NULL DEFAULT '1', -- The health status of the instance, 1 is health, 0 is unhealthy
+        isolate SMALLINT NOT NULL DEFAULT '0', -- Example isolation status flag, 0 is not isolated, 1 is isolated
+        weight SMALLINT NOT NULL DEFAULT '100', -- The weight of the instance is mainly used for LoadBalance, default is 100
+        enable_health_check SMALLINT NOT NULL DEFAULT '0', -- Whether to open a heartbeat on an instance, check the logic, 0 is not open, 1 is open
+        logic_set VARCHAR(128) DEFAULT NULL, -- Example logic packet information
+        cmdb_region VARCHAR(128) DEFAULT NULL, -- The region information of the instance is mainly used to close the route
+        cmdb_zone VARCHAR(128) DEFAULT NULL, -- The ZONE information of the instance is mainly used to close the route.
+        cmdb_idc VARCHAR(128) DEFAULT NULL, -- The IDC information of the instance is mainly used to close the route
+        priority SMALLINT NOT NULL DEFAULT '0', -- Example priority, currently useless
+        revision VARCHAR(32) NOT NULL, -- Instance version information
+        flag SMALLINT NOT NULL DEFAULT '0', -- Logic delete flag, 0 means visible, 1 means that it has been logically deleted
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_instance_service_id ON instance (service_id);
+CREATE INDEX idx_instance_mtime ON instance (mtime);
+CREATE INDEX idx_instance_host ON instance (host);
+
+CREATE TRIGGER instance_mtime_on_update BEFORE UPDATE ON instance FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+-- --------------------------------------------------------
+--
+-- Table structure health_check
+--
+CREATE TABLE
+    health_check (
+        id VARCHAR(128) NOT NULL, -- Instance ID
+        type SMALLINT NOT NULL DEFAULT '0', -- Instance health check type
+        ttl INTEGER NOT NULL, -- TTL time jumping
+        PRIMARY KEY (id)
+    );
+
+-- --------------------------------------------------------
+--
+-- Table structure instance_metadata
+--
+CREATE TABLE
+    instance_metadata (
+        id VARCHAR(128) NOT NULL, -- Instance ID
+        mkey VARCHAR(128) NOT NULL, -- instance label of Key
+        mvalue VARCHAR(4096) NOT NULL, -- instance label Value
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        PRIMARY KEY (id, mkey)
+    );
+
+CREATE INDEX idx_instance_metadata_mkey ON instance_metadata (mkey);
+
+CREATE TRIGGER instance_metadata_mtime_on_update BEFORE UPDATE ON instance_metadata FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+-- --------------------------------------------------------
+--
+-- Table structure namespace
+--
+CREATE TABLE
+    namespace (
+        name VARCHAR(64) NOT NULL, -- Namespace name, unique
+        comment VARCHAR(1024) DEFAULT NULL, -- Description of namespace
+        token VARCHAR(64) NOT NULL, -- TOKEN named space for write operation check
+        owner VARCHAR(1024) NOT NULL, -- Responsible for named space Owner
+        flag SMALLINT NOT NULL DEFAULT '0', -- Logic delete flag, 0 means visible, 1 means that it has been logically deleted
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        service_export_to TEXT, -- namespace metadata
+        metadata TEXT, -- namespace metadata
+        PRIMARY KEY (name)
+    );
+
+CREATE TRIGGER namespace_mtime_on_update BEFORE UPDATE ON namespace FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+--
+-- Data in the conveyor namespace
+--
+INSERT INTO
+    namespace (
+        name,
+        comment,
+        token,
+        owner,
+        flag,
+        ctime,
+        mtime
+    )
+VALUES
+    (
+        'Polaris',
+        'Polaris-server',
+        '2d1bfe5d12e04d54b8ee69e62494c7fd',
+        'polaris',
+        0,
+        '2019-09-06 07:55:07',
+        '2019-09-06 07:55:07'
+    ),
+    (
+        'default',
+        'Default Environment',
+        'e2e473081d3d4306b52264e49f7ce227',
+        'polaris',
+        0,
+        '2021-07-27 19:37:37',
+        '2021-07-27 19:37:37'
+    );
+
+-- --------------------------------------------------------
+--
+-- Table structure routing_config
+--
+CREATE TABLE
+    routing_config (
+        id VARCHAR(32) NOT NULL, -- Routing configuration ID
+        in_bounds TEXT, -- Service is routing rules
+        out_bounds TEXT, -- Service main routing rules
+        revision VARCHAR(40) NOT NULL, -- Routing rule version
+        flag SMALLINT NOT NULL DEFAULT '0', -- Logic delete flag, 0 means visible, 1 means that it has been logically deleted
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_routing_config_mtime ON routing_config (mtime);
+
+CREATE TRIGGER routing_config_mtime_on_update BEFORE UPDATE ON routing_config FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+-- --------------------------------------------------------
+--
+-- Table structure ratelimit_config
+--
+CREATE TABLE
+    ratelimit_config (
+        id VARCHAR(32) NOT NULL, -- ratelimit rule ID
+        name VARCHAR(64) NOT NULL, -- ratelimt rule name
+        disable SMALLINT NOT NULL DEFAULT '0', -- ratelimit disable
+        service_id VARCHAR(32) NOT NULL, -- Service ID
+        method VARCHAR(512) NOT NULL, -- ratelimit method
+        labels TEXT NOT NULL, -- Conductive flow for a specific label
+        priority SMALLINT NOT NULL DEFAULT '0', -- ratelimit rule priority
+        rule TEXT NOT NULL, -- Current limiting rules
+        revision VARCHAR(32) NOT NULL, -- Limiting version
+        flag SMALLINT NOT NULL DEFAULT '0', -- Logic delete flag, 0 means visible, 1 means that it has been logically deleted
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        etime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- RateLimit rule enable time
+        metadata TEXT, -- ratelimit rule metadata
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_ratelimit_config_mtime ON ratelimit_config (mtime);
+CREATE INDEX idx_ratelimit_config_service_id ON ratelimit_config (service_id);
+
+CREATE TRIGGER ratelimit_config_mtime_on_update BEFORE UPDATE ON ratelimit_config FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+-- --------------------------------------------------------
+--
+-- Table structure ratelimit_revision
+--
+CREATE TABLE
+    ratelimit_revision (
+        service_id VARCHAR(32) NOT NULL, -- Service ID
+        last_revision VARCHAR(40) NOT NULL, -- The latest limited limiting rule version of the corresponding service
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        PRIMARY KEY (service_id)
+    );
+
+CREATE INDEX idx_ratelimit_revision_service_id ON ratelimit_revision (service_id);
+CREATE INDEX idx_ratelimit_revision_mtime ON ratelimit_revision (mtime);
+
+CREATE TRIGGER ratelimit_revision_mtime_on_update BEFORE UPDATE ON ratelimit_revision FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+-- --------------------------------------------------------
+--
+-- Table structure service
+--
+CREATE TABLE
+    service (
+        id VARCHAR(32) NOT NULL, -- Service ID
+        name VARCHAR(128) NOT NULL, -- Service name, only under the namespace
+        namespace VARCHAR(64) NOT NULL, -- Namespace belongs to the service
+        ports TEXT DEFAULT NULL, -- Service will have a list of all port information of the external exposure (single process exposing multiple protocols)
+        business VARCHAR(64) DEFAULT NULL, -- Service business information
+        department VARCHAR(1024) DEFAULT NULL, -- Service department information
+        cmdb_mod1 VARCHAR(1024) DEFAULT NULL,
+        cmdb_mod2 VARCHAR(1024) DEFAULT NULL,
+        cmdb_mod3 VARCHAR(1024) DEFAULT NULL,
+        comment VARCHAR(1024) DEFAULT NULL, -- Description information
+        token VARCHAR(2048) NOT NULL, -- Service token, used to handle all the services involved in the service
+        revision VARCHAR(32) NOT NULL, -- Service version information
+        owner VARCHAR(1024) NOT NULL, -- Owner information belonging to the service
+        flag SMALLINT NOT NULL DEFAULT '0', -- Logic delete flag, 0 means visible, 1 means that it has been logically deleted
+        reference VARCHAR(32) DEFAULT NULL, -- Service alias, what is the actual service name that the service is actually pointed out?
+        refer_filter VARCHAR(1024) DEFAULT NULL,
+        platform_id VARCHAR(32) DEFAULT '', -- The platform ID to which the service belongs
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        export_to TEXT, -- service export to some namespace
+        PRIMARY KEY (id),
+        CONSTRAINT uk_service_name UNIQUE (name, namespace)
+    );
+
+CREATE INDEX idx_service_namespace ON service (namespace);
+CREATE INDEX idx_service_mtime ON service (mtime);
+CREATE INDEX idx_service_reference ON service (reference);
+CREATE INDEX idx_service_platform_id ON service (platform_id);
+
+CREATE TRIGGER service_mtime_on_update BEFORE UPDATE ON service FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+-- --------------------------------------------------------
+--
+-- Data in the conveyor service
+--
+INSERT INTO
+    service (
+        id,
+        name,
+        namespace,
+        comment,
+        business,
+        token,
+        revision,
+        owner,
+        flag,
+        ctime,
+        mtime
+    )
+VALUES
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        'polaris.checker',
+        'Polaris',
+        'polaris checker service',
+        'polaris',
+        '7d19c46de327408d8709ee7392b7700b',
+        '301b1e9f0bbd47a6b697e26e99dfe012',
+        'polaris',
+        0,
+        '2021-09-06 07:55:07',
+        '2021-09-06 07:55:09'
+    );
+
+-- --------------------------------------------------------
+--
+-- Table structure service_metadata
+--
+CREATE TABLE
+    service_metadata (
+        id VARCHAR(32) NOT NULL, -- Service ID
+        mkey VARCHAR(128) NOT NULL, -- Service label key
+        mvalue VARCHAR(4096) NOT NULL, -- Service label Value
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        PRIMARY KEY (id, mkey)
+    );
+
+CREATE INDEX idx_service_metadata_mkey ON service_metadata (mkey);
+
+CREATE TRIGGER service_metadata_mtime_on_update BEFORE UPDATE ON service_metadata FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+-- --------------------------------------------------------
+--
+-- Table structure owner_service_mapQuickly query all services under an Owner
+--
+CREATE TABLE
+    owner_service_map (
+        id VARCHAR(32) NOT NULL,
+        owner VARCHAR(32) NOT NULL, -- Service Owner
+        service VARCHAR(128) NOT NULL, -- service name
+        namespace VARCHAR(64) NOT NULL, -- namespace name
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_owner_service_map_owner ON owner_service_map (owner);
+CREATE INDEX idx_owner_service_map_name ON owner_service_map (service, namespace);
+
+-- --------------------------------------------------------
+--
+-- Table structure circuitbreaker_rule
+--
+CREATE TABLE
+    circuitbreaker_rule (
+        id VARCHAR(97) NOT NULL, -- Melting rule ID
+        version VARCHAR(32) NOT NULL DEFAULT 'master', -- Melting rule version, default is MASTR
+        name VARCHAR(128) NOT NULL, -- Melting rule name
+        namespace VARCHAR(64) NOT NULL, -- Melting rule belongs to name space
+        business VARCHAR(64) DEFAULT NULL, -- Business information of fuse regular
+        department VARCHAR(1024) DEFAULT NULL, -- Department information to which the fuse regular belongs
+        comment VARCHAR(1024) DEFAULT NULL, -- Description of the fuse rule
+        inbounds TEXT NOT NULL, -- Service-tuned fuse rule
+        outbounds TEXT NOT NULL, -- Service Motoring Fuse Rule
+        token VARCHAR(32) NOT NULL, -- Token, which is fucking, mainly for writing operation check
+        owner VARCHAR(1024) NOT NULL, -- Melting rule Owner information
+        revision VARCHAR(32) NOT NULL, -- Melt rule version information
+        flag SMALLINT NOT NULL DEFAULT '0', -- Logic delete flag, 0 means visible, 1 means that it has been logically deleted
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        metadata TEXT, -- circuit_breaker rule metadata
+        PRIMARY KEY (id, version),
+        CONSTRAINT uk_circuitbreaker_rule_name UNIQUE (name, namespace, version)
+    );
+
+CREATE INDEX idx_circuitbreaker_rule_mtime ON circuitbreaker_rule (mtime);
+
+CREATE TRIGGER circuitbreaker_rule_mtime_on_update BEFORE UPDATE ON circuitbreaker_rule FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+-- --------------------------------------------------------
+--
+-- Table structure circuitbreaker_rule_relation
+--
+CREATE TABLE
+    circuitbreaker_rule_relation (
+        service_id VARCHAR(32) NOT NULL, -- Service ID
+        rule_id VARCHAR(97) NOT NULL, -- Melting rule ID
+        rule_version VARCHAR(32) NOT NULL, -- Melting rule version
+        flag SMALLINT NOT NULL DEFAULT '0', -- Logic delete flag, 0 means visible, 1 means that it has been logically deleted
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        PRIMARY KEY (service_id)
+    );
+
+CREATE INDEX idx_circuitbreaker_rule_relation_mtime ON circuitbreaker_rule_relation (mtime);
+CREATE INDEX idx_circuitbreaker_rule_relation_rule_id ON circuitbreaker_rule_relation (rule_id);
+
+CREATE TRIGGER circuitbreaker_rule_relation_mtime_on_update BEFORE UPDATE ON circuitbreaker_rule_relation FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+-- --------------------------------------------------------
+--
+-- Table structure t_ip_config
+--
+CREATE TABLE
+    t_ip_config (
+        Fip BIGINT NOT NULL, -- Machine IP
+        FareaId BIGINT NOT NULL, -- Area number
+        FcityId BIGINT NOT NULL, -- City number
+        FidcId BIGINT NOT NULL, -- IDC number
+        Fflag SMALLINT DEFAULT '0',
+        Fstamp TIMESTAMPTZ NOT NULL,
+        Fflow BIGINT NOT NULL,
+        PRIMARY KEY (Fip)
+    );
+
+CREATE INDEX idx_t_ip_config_Fflow ON t_ip_config (Fflow);
+
+-- --------------------------------------------------------
+--
+-- Table structure t_policy
+--
+CREATE TABLE
+    t_policy (
+        FmodId BIGINT NOT NULL,
+        Fdiv BIGINT NOT NULL,
+        Fmod BIGINT NOT NULL,
+        Fflag SMALLINT DEFAULT '0',
+        Fstamp TIMESTAMPTZ NOT NULL,
+        Fflow BIGINT NOT NULL,
+        PRIMARY KEY (FmodId)
+    );
+
+-- --------------------------------------------------------
+--
+-- Table structure t_route
+--
+CREATE TABLE
+    t_route (
+        Fip BIGINT NOT NULL,
+        FmodId BIGINT NOT NULL,
+        FcmdId BIGINT NOT NULL,
+        FsetId VARCHAR(32) NOT NULL,
+        Fflag SMALLINT DEFAULT '0',
+        Fstamp TIMESTAMPTZ NOT NULL,
+        Fflow BIGINT NOT NULL,
+        PRIMARY KEY (Fip, FmodId, FcmdId)
+    );
+
+CREATE INDEX idx_t_route_Fflow ON t_route (Fflow);
+CREATE INDEX idx_t_route_idx1 ON t_route (FmodId, FcmdId, FsetId);
+
+-- --------------------------------------------------------
+--
+-- Table structure t_section
+--
+CREATE TABLE
+    t_section (
+        FmodId BIGINT NOT NULL,
+        Ffrom BIGINT NOT NULL,
+        Fto BIGINT NOT NULL,
+        Fxid BIGINT NOT NULL,
+        Fflag SMALLINT DEFAULT '0',
+        Fstamp TIMESTAMPTZ NOT NULL,
+        Fflow BIGINT NOT NULL,
+        PRIMARY KEY (FmodId, Ffrom, Fto)
+    );
+
+-- --------------------------------------------------------
+--
+-- Table structure start_lock
+--
+CREATE TABLE
+    start_lock (
+        lock_id INTEGER NOT NULL, -- 锁序号
+        lock_key VARCHAR(32) NOT NULL, -- Lock name
+        server VARCHAR(32) NOT NULL, -- SERVER holding launch lock
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Update time
+        PRIMARY KEY (lock_id, lock_key)
+    );
+
+CREATE TRIGGER start_lock_mtime_on_update BEFORE UPDATE ON start_lock FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+--
+-- Data in the conveyor start_lock
+--
+INSERT INTO
+    start_lock (lock_id, lock_key, server, mtime)
+VALUES
+    (1, 'sz', 'aaa', '2019-12-05 08:35:49'),
+    (1, 'schema_upgrade', '', '2019-12-05 08:35:49');
+
+-- --------------------------------------------------------
+--
+-- Table structure schema_version
+--
+CREATE TABLE
+    schema_version (
+        version VARCHAR(32) NOT NULL, -- schema version
+        script VARCHAR(128) NOT NULL, -- applied script
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- applied time
+        PRIMARY KEY (version)
+    );
+
+INSERT INTO
+    schema_version (version, script, ctime)
+VALUES
+    ('1.20.0', 'polaris_server.sql', CURRENT_TIMESTAMP);
+
+-- --------------------------------------------------------
+--
+-- Table structure cl5_module
+--
+CREATE TABLE
+    cl5_module (
+        module_id INTEGER NOT NULL, -- Module ID
+        interface_id INTEGER NOT NULL, -- Interface ID
+        range_num INTEGER NOT NULL,
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        PRIMARY KEY (module_id)
+    );
+
+CREATE TRIGGER cl5_module_mtime_on_update BEFORE UPDATE ON cl5_module FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+--
+-- Data in the conveyor cl5_module
+--
+INSERT INTO
+    cl5_module (module_id, interface_id, range_num)
+VALUES
+    (3000001, 1, 0);
+
+-- --------------------------------------------------------
+--
+-- Table structure config_file
+--
+CREATE TABLE
+    config_file (
+        id BIGSERIAL NOT NULL, -- 主键
+        namespace VARCHAR(64) NOT NULL, -- 所属的namespace
+        "group" VARCHAR(128) NOT NULL DEFAULT '', -- 所属的文件组
+        name VARCHAR(128) NOT NULL, -- 配置文件名
+        content TEXT NOT NULL, -- 文件内容
+        format VARCHAR(16) DEFAULT 'text', -- 文件格式，枚举值
+        comment VARCHAR(512) DEFAULT NULL, -- 备注信息
+        flag SMALLINT NOT NULL DEFAULT '0', -- 软删除标记位
+        create_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 创建时间
+        create_by VARCHAR(32) DEFAULT NULL, -- 创建人
+        modify_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 最后更新时间
+        modify_by VARCHAR(32) DEFAULT NULL, -- 最后更新人
+        PRIMARY KEY (id),
+        CONSTRAINT uk_config_file_file UNIQUE (namespace, "group", name)
+    );
+
+CREATE TRIGGER config_file_modify_time_on_update BEFORE UPDATE ON config_file FOR EACH ROW EXECUTE FUNCTION polaris_update_modify_time();
+
+-- --------------------------------------------------------
+--
+-- Table structure config_file_group
+--
+CREATE TABLE
+    config_file_group (
+        id BIGSERIAL NOT NULL, -- 主键
+        name VARCHAR(128) NOT NULL, -- 配置文件分组名
+        namespace VARCHAR(64) NOT NULL, -- 所属的namespace
+        comment VARCHAR(512) DEFAULT NULL, -- 备注信息
+        owner VARCHAR(1024) DEFAULT NULL, -- 负责人
+        create_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 创建时间
+        create_by VARCHAR(32) DEFAULT NULL, -- 创建人
+        modify_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 最后更新时间
+        modify_by VARCHAR(32) DEFAULT NULL, -- 最后更新人
+        business VARCHAR(64) DEFAULT NULL, -- Service business information
+        department VARCHAR(1024) DEFAULT NULL, -- Service department information
+        metadata TEXT, -- 配置分组标签
+        flag SMALLINT NOT NULL DEFAULT '0', -- 是否被删除
+        PRIMARY KEY (id),
+        CONSTRAINT uk_config_file_group_name UNIQUE (namespace, name)
+    );
+
+CREATE TRIGGER config_file_group_modify_time_on_update BEFORE UPDATE ON config_file_group FOR EACH ROW EXECUTE FUNCTION polaris_update_modify_time();
+
+-- --------------------------------------------------------
+--
+-- Table structure config_file_release
+--
+CREATE TABLE
+    config_file_release (
+        id BIGSERIAL NOT NULL, -- 主键
+        name VARCHAR(128) DEFAULT NULL, -- 发布标题
+        namespace VARCHAR(64) NOT NULL, -- 所属的namespace
+        "group" VARCHAR(128) NOT NULL, -- 所属的文件组
+        file_name VARCHAR(128) NOT NULL, -- 配置文件名
+        format VARCHAR(16) DEFAULT 'text', -- 文件格式，枚举值
+        content TEXT NOT NULL, -- 文件内容
+        comment VARCHAR(512) DEFAULT NULL, -- 备注信息
+        md5 VARCHAR(128) NOT NULL, -- content的md5值
+        version BIGINT NOT NULL, -- 版本号，每次发布自增1
+        flag SMALLINT NOT NULL DEFAULT '0', -- 是否被删除
+        create_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 创建时间
+        create_by VARCHAR(32) DEFAULT NULL, -- 创建人
+        modify_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 最后更新时间
+        modify_by VARCHAR(32) DEFAULT NULL, -- 最后更新人
+        tags TEXT, -- 文件标签
+        active SMALLINT NOT NULL DEFAULT '0', -- 是否处于使用中
+        description VARCHAR(512) DEFAULT NULL, -- 发布描述
+        release_type VARCHAR(25) NOT NULL DEFAULT '', -- 文件类型：""：全量 gray：灰度
+        PRIMARY KEY (id),
+        CONSTRAINT uk_config_file_release_file UNIQUE (namespace, "group", file_name, name)
+    );
+
+CREATE INDEX idx_config_file_release_modify_time ON config_file_release (modify_time);
+
+CREATE TRIGGER config_file_release_modify_time_on_update BEFORE UPDATE ON config_file_release FOR EACH ROW EXECUTE FUNCTION polaris_update_modify_time();
+
+-- --------------------------------------------------------
+--
+-- Table structure config_file_release_history
+--
+CREATE TABLE
+    config_file_release_history (
+        id BIGSERIAL NOT NULL, -- 主键
+        name VARCHAR(64) DEFAULT '', -- 发布名称
+        namespace VARCHAR(64) NOT NULL, -- 所属的namespace
+        "group" VARCHAR(128) NOT NULL, -- 所属的文件组
+        file_name VARCHAR(128) NOT NULL, -- 配置文件名
+        content TEXT NOT NULL, -- 文件内容
+        format VARCHAR(16) DEFAULT 'text', -- 文件格式
+        comment VARCHAR(512) DEFAULT NULL, -- 备注信息
+        md5 VARCHAR(128) NOT NULL, -- content的md5值
+        type VARCHAR(32) NOT NULL, -- 发布类型，例如全量发布、灰度发布
+        status VARCHAR(16) NOT NULL DEFAULT 'success', -- 发布状态，success表示成功，fail 表示失败
+        create_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 创建时间
+        create_by VARCHAR(32) DEFAULT NULL, -- 创建人
+        modify_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 最后更新时间
+        modify_by VARCHAR(32) DEFAULT NULL, -- 最后更新人
+        tags TEXT, -- 文件标签
+        version BIGINT, -- 版本号，每次发布自增1
+        reason VARCHAR(3000) DEFAULT '', -- 原因
+        description VARCHAR(512) DEFAULT NULL, -- 发布描述
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_config_file_release_history_file ON config_file_release_history (namespace, "group", file_name);
+
+CREATE TRIGGER config_file_release_history_modify_time_on_update BEFORE UPDATE ON config_file_release_history FOR EACH ROW EXECUTE FUNCTION polaris_update_modify_time();
+
+-- --------------------------------------------------------
+--
+-- Table structure config_file_tag
+--
+CREATE TABLE
+    config_file_tag (
+        id BIGSERIAL NOT NULL, -- 主键
+        key VARCHAR(128) NOT NULL, -- tag 的键
+        Value VARCHAR(128) NOT NULL, -- tag 的值
+        namespace VARCHAR(64) NOT NULL, -- 所属的namespace
+        "group" VARCHAR(128) NOT NULL DEFAULT '', -- 所属的文件组
+        file_name VARCHAR(128) NOT NULL, -- 配置文件名
+        create_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 创建时间
+        create_by VARCHAR(32) DEFAULT NULL, -- 创建人
+        modify_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 最后更新时间
+        modify_by VARCHAR(32) DEFAULT NULL, -- 最后更新人
+        PRIMARY KEY (id),
+        CONSTRAINT uk_config_file_tag_tag UNIQUE (key, Value, namespace, "group", file_name)
+    );
+
+CREATE INDEX idx_config_file_tag_file ON config_file_tag (namespace, "group", file_name);
+
+CREATE TRIGGER config_file_tag_modify_time_on_update BEFORE UPDATE ON config_file_tag FOR EACH ROW EXECUTE FUNCTION polaris_update_modify_time();
+
+CREATE TABLE
+    "user" (
+        id VARCHAR(128) NOT NULL, -- User ID
+        name VARCHAR(100) NOT NULL, -- user name
+        password VARCHAR(100) NOT NULL, -- user password
+        owner VARCHAR(128) NOT NULL, -- Main account ID
+        source VARCHAR(32) NOT NULL, -- Account source
+        mobile VARCHAR(12) NOT NULL DEFAULT '', -- Account mobile phone number
+        email VARCHAR(64) NOT NULL DEFAULT '', -- Account mailbox
+        token VARCHAR(255) NOT NULL, -- The token information owned by the account can be used for SDK access authentication
+        token_enable SMALLINT NOT NULL DEFAULT 1,
+        user_type INTEGER NOT NULL DEFAULT 20, -- Account type, 0 is the admin super account, 20 is the primary account, 50 for the child account
+        comment VARCHAR(255) NOT NULL, -- describe
+        flag SMALLINT NOT NULL DEFAULT '0', -- Whether the rules are valid, 0 is valid, 1 is invalid, it is deleted
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        metadata TEXT, -- user metadata
+        PRIMARY KEY (id),
+        CONSTRAINT uk_user_name_owner UNIQUE (name, owner)
+    );
+
+CREATE INDEX idx_user_owner ON "user" (owner);
+CREATE INDEX idx_user_mtime ON "user" (mtime);
+
+CREATE TRIGGER user_mtime_on_update BEFORE UPDATE ON "user" FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+CREATE TABLE
+    user_group (
+        id VARCHAR(128) NOT NULL, -- User group ID
+        name VARCHAR(100) NOT NULL, -- User group name
+        owner VARCHAR(128) NOT NULL, -- The main account ID of the user group
+        token VARCHAR(255) NOT NULL, -- TOKEN information of this user group
+        comment VARCHAR(255) NOT NULL, -- Description
+        token_enable SMALLINT NOT NULL DEFAULT 1,
+        flag SMALLINT NOT NULL DEFAULT '0', -- Whether the rules are valid, 0 is valid, 1 is invalid, it is deleted
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        metadata TEXT, -- user_group metadata
+        PRIMARY KEY (id),
+        CONSTRAINT uk_user_group_name_owner UNIQUE (name, owner)
+    );
+
+CREATE INDEX idx_user_group_owner ON user_group (owner);
+CREATE INDEX idx_user_group_mtime ON user_group (mtime);
+
+CREATE TRIGGER user_group_mtime_on_update BEFORE UPDATE ON user_group FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+CREATE TABLE
+    user_group_relation (
+        user_id VARCHAR(128) NOT NULL, -- User ID
+        group_id VARCHAR(128) NOT NULL, -- User group ID
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        PRIMARY KEY (user_id, group_id)
+    );
+
+CREATE INDEX idx_user_group_relation_mtime ON user_group_relation (mtime);
+
+CREATE TRIGGER user_group_relation_mtime_on_update BEFORE UPDATE ON user_group_relation FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+CREATE TABLE
+    auth_strategy (
+        id VARCHAR(128) NOT NULL, -- Strategy ID
+        name VARCHAR(100) NOT NULL, -- Policy name
+        action VARCHAR(32) NOT NULL, -- Read and write permission for this policy, only_read = 0, read_write = 1
+        owner VARCHAR(128) NOT NULL, -- The account ID to which this policy is
+        comment VARCHAR(255) NOT NULL, -- describe
+        "default" SMALLINT NOT NULL DEFAULT '0',
+        source VARCHAR(32) NOT NULL, -- policy rule source
+        revision VARCHAR(128) NOT NULL, -- Authentication rule version
+        flag SMALLINT NOT NULL DEFAULT '0', -- Whether the rules are valid, 0 is valid, 1 is invalid, it is deleted
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        metadata TEXT, -- policy rule metadata
+        PRIMARY KEY (id),
+        CONSTRAINT uk_auth_strategy_name_owner UNIQUE (name, owner)
+    );
+
+CREATE INDEX idx_auth_strategy_owner ON auth_strategy (owner);
+CREATE INDEX idx_auth_strategy_mtime ON auth_strategy (mtime);
+
+CREATE TRIGGER auth_strategy_mtime_on_update BEFORE UPDATE ON auth_strategy FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+CREATE TABLE
+    auth_principal (
+        strategy_id VARCHAR(128) NOT NULL, -- Strategy ID
+        principal_id VARCHAR(128) NOT NULL, -- Principal ID
+        principal_role INTEGER NOT NULL, -- PRINCIPAL type, 1 is User, 2 is Group, 3 is Role
+        extend_info TEXT, -- link principal extend info
+        PRIMARY KEY (strategy_id, principal_id, principal_role)
+    );
+
+CREATE TABLE
+    auth_strategy_resource (
+        strategy_id VARCHAR(128) NOT NULL, -- Strategy ID
+        res_type INTEGER NOT NULL, -- Resource Type, Namespaces = 0, Service = 1, configgroups = 2
+        res_id VARCHAR(128) NOT NULL, -- Resource ID
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        PRIMARY KEY (strategy_id, res_type, res_id)
+    );
+
+CREATE INDEX idx_auth_strategy_resource_mtime ON auth_strategy_resource (mtime);
+
+CREATE TRIGGER auth_strategy_resource_mtime_on_update BEFORE UPDATE ON auth_strategy_resource FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+/* 角色数据 */
+CREATE TABLE
+    auth_role (
+        id VARCHAR(128) NOT NULL, -- role id
+        name VARCHAR(100) NOT NULL, -- role name
+        owner VARCHAR(128) NOT NULL, -- Main account ID
+        source VARCHAR(32) NOT NULL, -- role source
+        role_type INTEGER NOT NULL DEFAULT 20, -- role type
+        comment VARCHAR(255) NOT NULL, -- describe
+        flag SMALLINT NOT NULL DEFAULT '0', -- Whether the rules are valid, 0 is valid, 1 is invalid, it is deleted
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Last updated time
+        metadata TEXT, -- user metadata
+        PRIMARY KEY (id),
+        CONSTRAINT uk_auth_role_name_owner UNIQUE (name, owner)
+    );
+
+CREATE INDEX idx_auth_role_owner ON auth_role (owner);
+CREATE INDEX idx_auth_role_mtime ON auth_role (mtime);
+
+CREATE TRIGGER auth_role_mtime_on_update BEFORE UPDATE ON auth_role FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+/* 角色关联用户/用户组关系表 */
+CREATE TABLE
+    auth_role_principal (
+        role_id VARCHAR(128) NOT NULL, -- role id
+        principal_id VARCHAR(128) NOT NULL, -- principal id
+        principal_role INTEGER NOT NULL, -- PRINCIPAL type, 1 is User, 2 is Group
+        extend_info TEXT, -- link principal extend info
+        PRIMARY KEY (role_id, principal_id, principal_role)
+    );
+
+/* 鉴权策略中的资源标签关联信息 */
+CREATE TABLE
+    auth_strategy_label (
+        strategy_id VARCHAR(128) NOT NULL, -- strategy id
+        key VARCHAR(128) NOT NULL, -- tag key
+        value TEXT NOT NULL, -- tag value
+        compare_type VARCHAR(128) NOT NULL, -- tag kv compare func
+        PRIMARY KEY (strategy_id, key)
+    );
+
+/* 鉴权策略中的资源标签关联信息 */
+CREATE TABLE
+    auth_strategy_function (
+        strategy_id VARCHAR(128) NOT NULL, -- strategy id
+        function VARCHAR(256) NOT NULL, -- server provider function name
+        PRIMARY KEY (strategy_id, function)
+    );
+
+-- v1.8.0, support client info storage
+CREATE TABLE
+    client (
+        id VARCHAR(128) NOT NULL, -- client id
+        host VARCHAR(100) NOT NULL, -- client host IP
+        type VARCHAR(100) NOT NULL, -- client type: polaris-java/polaris-go
+        version VARCHAR(32) NOT NULL, -- client SDK version
+        region VARCHAR(128) DEFAULT NULL, -- region info for client
+        zone VARCHAR(128) DEFAULT NULL, -- zone info for client
+        campus VARCHAR(128) DEFAULT NULL, -- campus info for client
+        flag SMALLINT NOT NULL DEFAULT '0', -- 0 is valid, 1 is invalid(deleted)
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- last updated time
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_client_mtime ON client (mtime);
+
+CREATE TRIGGER client_mtime_on_update BEFORE UPDATE ON client FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+CREATE TABLE
+    client_stat (
+        client_id VARCHAR(128) NOT NULL, -- client id
+        target VARCHAR(100) NOT NULL, -- target stat platform
+        port INTEGER NOT NULL, -- client port to get stat information
+        protocol VARCHAR(100) NOT NULL, -- stat info transport protocol
+        path VARCHAR(128) NOT NULL, -- stat metric path
+        PRIMARY KEY (client_id, target, port)
+    );
+
+-- v1.9.0
+CREATE TABLE
+    config_file_template (
+        id BIGSERIAL NOT NULL, -- 主键
+        name VARCHAR(128) NOT NULL, -- 配置文件模板名称
+        content TEXT NOT NULL, -- 配置文件模板内容
+        format VARCHAR(16) DEFAULT 'text', -- 模板文件格式
+        comment VARCHAR(512) DEFAULT NULL, -- 模板描述信息
+        create_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 创建时间
+        create_by VARCHAR(32) DEFAULT NULL, -- 创建人
+        modify_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 最后更新时间
+        modify_by VARCHAR(32) DEFAULT NULL, -- 最后更新人
+        PRIMARY KEY (id),
+        CONSTRAINT uk_config_file_template_name UNIQUE (name)
+    );
+
+CREATE TRIGGER config_file_template_modify_time_on_update BEFORE UPDATE ON config_file_template FOR EACH ROW EXECUTE FUNCTION polaris_update_modify_time();
+
+INSERT INTO
+    config_file_template (
+        name,
+        content,
+        format,
+        comment,
+        create_time,
+        create_by,
+        modify_time,
+        modify_by
+    )
+VALUES
+    (
+        'spring-cloud-gateway-braining',
+        '{
+        "rules":[
+            {
+                "conditions":[
+                    {
+                        "key":"${http.query.uid}",
+                        "values":["10000"],
+                        "operation":"EQUALS"
+                    }
+                ],
+                "labels":[
+                    {
+                        "key":"env",
+                        "value":"green"
+                    }
+                ]
+            }
+        ]
+    }',
+        'json',
+        'Spring Cloud Gateway  染色规则',
+        CURRENT_TIMESTAMP,
+        'polaris',
+        CURRENT_TIMESTAMP,
+        'polaris'
+    );
+
+-- v1.12.0
+CREATE TABLE
+    routing_config_v2 (
+        id VARCHAR(128) NOT NULL,
+        name VARCHAR(64) NOT NULL DEFAULT '',
+        namespace VARCHAR(64) NOT NULL DEFAULT '',
+        policy VARCHAR(64) NOT NULL,
+        config TEXT,
+        enable INTEGER NOT NULL DEFAULT 0,
+        revision VARCHAR(40) NOT NULL,
+        description VARCHAR(500) NOT NULL DEFAULT '',
+        priority SMALLINT NOT NULL DEFAULT '0', -- ratelimit rule priority
+        flag SMALLINT NOT NULL DEFAULT '0',
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        etime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        extend_info VARCHAR(1024) DEFAULT '',
+        metadata TEXT, -- route rule metadata
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_routing_config_v2_mtime ON routing_config_v2 (mtime);
+
+CREATE TRIGGER routing_config_v2_mtime_on_update BEFORE UPDATE ON routing_config_v2 FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+CREATE TABLE
+    leader_election (
+        elect_key VARCHAR(128) NOT NULL,
+        version BIGINT NOT NULL DEFAULT 0,
+        leader VARCHAR(128) NOT NULL,
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (elect_key)
+    );
+
+CREATE INDEX idx_leader_election_version ON leader_election (version);
+
+CREATE TRIGGER leader_election_mtime_on_update BEFORE UPDATE ON leader_election FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+-- v1.14.0
+CREATE TABLE
+    circuitbreaker_rule_v2 (
+        id VARCHAR(128) NOT NULL,
+        name VARCHAR(64) NOT NULL,
+        namespace VARCHAR(64) NOT NULL DEFAULT '',
+        enable INTEGER NOT NULL DEFAULT 0,
+        revision VARCHAR(40) NOT NULL,
+        description VARCHAR(1024) NOT NULL DEFAULT '',
+        level INTEGER NOT NULL,
+        src_service VARCHAR(128) NOT NULL,
+        src_namespace VARCHAR(64) NOT NULL,
+        dst_service VARCHAR(128) NOT NULL,
+        dst_namespace VARCHAR(64) NOT NULL,
+        dst_method VARCHAR(128) NOT NULL,
+        config TEXT,
+        flag SMALLINT NOT NULL DEFAULT '0',
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        etime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        metadata TEXT, -- circuit_breaker rule metadata
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_circuitbreaker_rule_v2_name ON circuitbreaker_rule_v2 (name);
+CREATE INDEX idx_circuitbreaker_rule_v2_mtime ON circuitbreaker_rule_v2 (mtime);
+
+CREATE TRIGGER circuitbreaker_rule_v2_mtime_on_update BEFORE UPDATE ON circuitbreaker_rule_v2 FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+CREATE TABLE
+    fault_detect_rule (
+        id VARCHAR(128) NOT NULL,
+        name VARCHAR(64) NOT NULL,
+        namespace VARCHAR(64) NOT NULL DEFAULT 'DEFAULT',
+        revision VARCHAR(40) NOT NULL,
+        description VARCHAR(1024) NOT NULL DEFAULT '',
+        dst_service VARCHAR(128) NOT NULL,
+        dst_namespace VARCHAR(64) NOT NULL,
+        dst_method VARCHAR(128) NOT NULL,
+        config TEXT,
+        flag SMALLINT NOT NULL DEFAULT '0',
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        metadata TEXT, -- faultdetect rule metadata
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_fault_detect_rule_name ON fault_detect_rule (name);
+CREATE INDEX idx_fault_detect_rule_mtime ON fault_detect_rule (mtime);
+
+CREATE TRIGGER fault_detect_rule_mtime_on_update BEFORE UPDATE ON fault_detect_rule FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+/* 服务契约表 */
+CREATE TABLE
+    service_contract (
+        id VARCHAR(128) NOT NULL, -- 服务契约主键
+        type VARCHAR(128) NOT NULL, -- 服务契约名称
+        namespace VARCHAR(64) NOT NULL, -- 命名空间
+        service VARCHAR(128) NOT NULL, -- 服务名称
+        protocol VARCHAR(32) NOT NULL, -- 当前契约对应的协议信息 e.g. http/dubbo/grpc/thrift
+        version VARCHAR(64) NOT NULL, -- 服务契约版本
+        revision VARCHAR(128) NOT NULL, -- 当前服务契约的全部内容版本摘要
+        flag SMALLINT DEFAULT 0, -- 逻辑删除标志位 ， 0 位有效 ， 1 为逻辑删除
+        content TEXT, -- 描述信息
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_service_contract_namespace_service_type_version_protocol ON service_contract (namespace, service, type, version, protocol);
+
+CREATE TRIGGER service_contract_mtime_on_update BEFORE UPDATE ON service_contract FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+/* 服务契约中针对单个接口定义的详细信息描述表 */
+CREATE TABLE
+    service_contract_detail (
+        id VARCHAR(128) NOT NULL, -- 服务契约单个接口定义记录主键
+        contract_id VARCHAR(128) NOT NULL, -- 服务契约 ID
+        type VARCHAR(128) NOT NULL, -- 服务契约接口名称
+        namespace VARCHAR(64) NOT NULL, -- 命名空间
+        service VARCHAR(128) NOT NULL, -- 服务名称
+        protocol VARCHAR(32) NOT NULL, -- 当前契约对应的协议信息 e.g. http/dubbo/grpc/thrift
+        version VARCHAR(64) NOT NULL, -- 服务契约版本
+        method VARCHAR(32) NOT NULL, -- http协议中的 method 字段, eg:POST/GET/PUT/DELETE, 其他 gRPC 可以用来标识 stream 类型
+        path VARCHAR(128) NOT NULL, -- 接口具体全路径描述
+        source INTEGER, -- 该条记录来源, 0:SDK/1:MANUAL
+        content TEXT, -- 描述信息
+        revision VARCHAR(128) NOT NULL, -- 当前接口定义的全部内容版本摘要
+        flag SMALLINT DEFAULT 0, -- 逻辑删除标志位, 0 位有效, 1 为逻辑删除
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_service_contract_detail_contract_id_path_method_source ON service_contract_detail (contract_id, path, method, source);
+
+CREATE TRIGGER service_contract_detail_mtime_on_update BEFORE UPDATE ON service_contract_detail FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+/* 灰度资源 */
+CREATE TABLE
+    gray_resource (
+        name VARCHAR(128) NOT NULL, -- 灰度资源
+        match_rule TEXT NOT NULL, -- 配置规则
+        create_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 创建时间
+        create_by VARCHAR(32) DEFAULT '', -- 创建人
+        modify_time TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 最后更新时间
+        modify_by VARCHAR(32) DEFAULT '', -- 最后更新人
+        flag SMALLINT DEFAULT 0, -- 逻辑删除标志位, 0 位有效, 1 为逻辑删除
+        PRIMARY KEY (name)
+    );
+
+CREATE TRIGGER gray_resource_modify_time_on_update BEFORE UPDATE ON gray_resource FOR EACH ROW EXECUTE FUNCTION polaris_update_modify_time();
+
+CREATE TABLE
+    lane_group (
+        id VARCHAR(128) NOT NULL, -- 泳道分组 ID
+        name VARCHAR(64) NOT NULL, -- 泳道分组名称
+        rule TEXT NOT NULL, -- 规则的 json 字符串
+        description VARCHAR(3000), -- 规则描述
+        revision VARCHAR(40) NOT NULL, -- 规则摘要
+        flag SMALLINT DEFAULT 0, -- 软删除标识位
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        metadata TEXT, -- lane rule metadata
+        PRIMARY KEY (id),
+        CONSTRAINT uk_lane_group_name UNIQUE (name)
+    );
+
+CREATE TRIGGER lane_group_mtime_on_update BEFORE UPDATE ON lane_group FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+CREATE TABLE
+    lane_rule (
+        id VARCHAR(128) NOT NULL, -- 规则 id
+        name VARCHAR(64) NOT NULL, -- 规则名称
+        group_name VARCHAR(64) NOT NULL, -- 泳道分组名称
+        rule TEXT NOT NULL, -- 规则的 json 字符串
+        revision VARCHAR(40) NOT NULL, -- 规则摘要
+        description VARCHAR(3000), -- 规则描述
+        enable SMALLINT, -- 是否启用
+        flag SMALLINT DEFAULT 0, -- 软删除标识位
+        priority BIGINT NOT NULL DEFAULT 0, -- 泳道规则优先级
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        etime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (id),
+        CONSTRAINT uk_lane_rule_name UNIQUE (group_name, name)
+    );
+
+CREATE TRIGGER lane_rule_mtime_on_update BEFORE UPDATE ON lane_rule FOR EACH ROW EXECUTE FUNCTION polaris_update_mtime();
+
+-- v1.20.0, 资源变更日志，用于多个节点之间通知缓存刷新
+CREATE TABLE
+    change_log (
+        id BIGSERIAL NOT NULL, -- change log id
+        resource VARCHAR(64) NOT NULL, -- changed resource type
+        server VARCHAR(128) NOT NULL, -- server which changed the resource
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- change time
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_change_log_ctime ON change_log (ctime);
+
+-- v1.20.0, 内置证书颁发机构的根证书，私钥经过加密后保存
+CREATE TABLE
+    ca_key_pair (
+        generation BIGINT NOT NULL, -- root certificate generation
+        cert TEXT NOT NULL, -- root certificate in PEM
+        key_cipher TEXT NOT NULL, -- encrypted private key in PEM
+        crypto_algo VARCHAR(32) NOT NULL DEFAULT '', -- crypto algorithm of the private key
+        not_before BIGINT NOT NULL, -- unix seconds the certificate becomes valid
+        not_after BIGINT NOT NULL, -- unix seconds the certificate expires
+        retire_time BIGINT NOT NULL DEFAULT 0, -- unix seconds the certificate leaves the trust bundle, 0 means active
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- last update time
+        PRIMARY KEY (generation)
+    );
+
+-- v1.20.0, 服务间访问策略，由 Envoy ext_authz 在被调服务的 Sidecar 上执行
+CREATE TABLE
+    service_access_policy (
+        id VARCHAR(128) NOT NULL, -- policy id
+        name VARCHAR(100) NOT NULL, -- policy name
+        namespace VARCHAR(64) NOT NULL, -- namespace of the destination service
+        service VARCHAR(128) NOT NULL, -- destination service
+        action VARCHAR(32) NOT NULL, -- ALLOW or DENY
+        sources TEXT, -- source services in json
+        conditions TEXT, -- method and path conditions in json
+        comment VARCHAR(255) NOT NULL DEFAULT '', -- describe
+        revision VARCHAR(128) NOT NULL, -- policy revision
+        flag SMALLINT NOT NULL DEFAULT '0', -- Whether the policy is valid, 0 is valid, 1 is deleted
+        ctime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- create time
+        mtime TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- last update time
+        PRIMARY KEY (id)
+    );
+
+CREATE INDEX idx_service_access_policy_destination ON service_access_policy (namespace, service);
+CREATE INDEX idx_service_access_policy_mtime ON service_access_policy (mtime);
+
+-- v1.20.0, 实例健康状态的变更历史
+CREATE TABLE
+    instance_health_history (
+        seq BIGSERIAL NOT NULL, -- primary key
+        id VARCHAR(128) NOT NULL, -- instance id
+        healthy SMALLINT NOT NULL, -- health status after the transition, 1 is healthy
+        reason VARCHAR(64) NOT NULL DEFAULT '', -- reason of the transition
+        detail VARCHAR(255) NOT NULL DEFAULT '', -- extra information of the transition
+        server VARCHAR(128) NOT NULL DEFAULT '', -- polaris server which made the decision
+        last_beat BIGINT NOT NULL DEFAULT 0, -- last heartbeat time in seconds
+        ctime BIGINT NOT NULL, -- transition time in milliseconds
+        PRIMARY KEY (seq)
+    );
+
+CREATE INDEX idx_instance_health_history_instance ON instance_health_history (id, seq);
+
+-- v1.20.0, 按范围暂停健康检查的规则
+CREATE TABLE
+    health_check_suspension (
+        id VARCHAR(128) NOT NULL, -- suspension id
+        selector TEXT NOT NULL, -- instances selected by the suspension in json
+        comment VARCHAR(255) NOT NULL DEFAULT '', -- describe
+        ctime BIGINT NOT NULL, -- create time in milliseconds
+        etime BIGINT NOT NULL, -- expire time in milliseconds
+        PRIMARY KEY (id)
+    );
+
+
+/* 默认资源信息数据插入 */
+
+-- Create a default master account, password is Polarismesh @ 2021
+INSERT INTO
+    "user" (
+        id,
+        name,
+        password,
+        source,
+        token,
+        token_enable,
+        user_type,
+        comment,
+        mobile,
+        email,
+        owner
+    )
+VALUES
+    (
+        '65e4789a6d5b49669adf1e9e8387549c',
+        'polaris',
+        '$2a$10$3izWuZtE5SBdAtSZci.gs.iZ2pAn9I8hEqYrC6gwJp1dyjqQnrrum',
+        'Polaris',
+        'nu/0WRA4EqSR1FagrjRj0fZwPXuGlMpX+zCuWu4uMqy8xr1vRjisSbA25aAC3mtU8MeeRsKhQiDAynUR09I=',
+        1,
+        20,
+        'default polaris admin account',
+        '12345678910',
+        '12345678910',
+        ''
+    );
+
+-- Permissions policy inserted into Polaris-Admin
+INSERT INTO
+    auth_strategy (
+        id,
+        name,
+        action,
+        owner,
+        comment,
+        "default",
+        source,
+        revision,
+        flag,
+        ctime,
+        mtime
+    )
+VALUES
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        '(用户) polaris的默认策略',
+        'READ_WRITE',
+        '65e4789a6d5b49669adf1e9e8387549c',
+        'default admin',
+        1,
+        'Polaris',
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        0,
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    );
+
+-- Sport rules inserted into Polaris-Admin to access
+INSERT INTO
+    auth_strategy_resource (
+        strategy_id,
+        res_type,
+        res_id,
+        ctime,
+        mtime
+    )
+VALUES
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        0,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        1,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        2,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        3,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        4,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        5,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        6,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        7,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        20,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        21,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        22,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        23,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    );
+
+-- Insert permission policies and association relationships for Polaris-Admin accounts
+INSERT INTO
+    auth_principal (strategy_id, principal_id, principal_role) VALUES (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        '65e4789a6d5b49669adf1e9e8387549c',
+        1
+    );
+
+INSERT INTO
+    auth_strategy_function (strategy_id, function) VALUES (
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        '*'
+    );
+
+/* 默认的全局只读策略 */
+INSERT INTO
+    auth_strategy (
+        id,
+        name,
+        action,
+        owner,
+        comment,
+        "default",
+        source,
+        revision,
+        flag,
+        ctime,
+        mtime
+    )
+VALUES
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        '全局只读策略',
+        'ALLOW',
+        '65e4789a6d5b49669adf1e9e8387549c',
+        'global resources read onyly',
+        1,
+        'Polaris',
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        0,
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    );
+
+INSERT INTO
+    auth_strategy_resource (
+        strategy_id,
+        res_type,
+        res_id,
+        ctime,
+        mtime
+    )
+VALUES
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        0,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        1,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        2,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        3,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        4,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        5,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        6,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        7,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        20,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        21,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        22,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        23,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    );
+
+INSERT INTO
+    auth_strategy_function (strategy_id, function) VALUES (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        'Describe*'
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        'List*'
+    ),
+    (
+        'bfa04ae1e32a94fbca9ead86e1ecf581',
+        'Get*'
+    );
+
+
+/* 默认的全局读写策略 */
+INSERT INTO
+    auth_strategy (
+        id,
+        name,
+        action,
+        owner,
+        comment,
+        "default",
+        source,
+        revision,
+        flag,
+        ctime,
+        mtime
+    )
+VALUES
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        '全局读写策略',
+        'ALLOW',
+        '65e4789a6d5b49669adf1e9e8387549c',
+        'global resources read and write',
+        1,
+        'Polaris',
+        'fbca9bfa04ae4ead86e1ecf5811e32a9',
+        0,
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    );
+
+INSERT INTO
+    auth_strategy_resource (
+        strategy_id,
+        res_type,
+        res_id,
+        ctime,
+        mtime
+    )
+VALUES
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        0,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        1,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        2,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        3,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        4,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        5,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        6,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        7,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        20,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        21,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        22,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ),
+    (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        23,
+        '*',
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    );
+
+INSERT INTO
+    auth_strategy_function (strategy_id, function) VALUES (
+        'e3d86e1ecf5812bfa04ae1a94fbca9ea',
+        '*'
+    );
+
@@ -0,0 +1,26 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package store
+
+// ReadViewStore 一致性读取使用的可选扩展接口，存储插件按需实现
+// 各个 GetMore/Query 接口每次调用都会使用独立的连接或事务，备份工具通过该接口在同一个只读事务中读取全部资源
+type ReadViewStore interface {
+	// ReadView 返回一个全部读操作都在 tx 中执行的 Store，tx 需要由 StartReadTx 创建，
+	// 返回的 Store 仅用于读取，生命周期不能超过 tx
+	ReadView(tx Tx) (Store, error)
+}
@@ -0,0 +1,38 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package store
+
+import (
+	"io"
+)
+
+// SchemaUpgradeOption 表结构升级参数
+type SchemaUpgradeOption struct {
+	// DryRun 只输出待执行的升级脚本，不实际执行
+	DryRun bool
+	// Baseline 对于没有版本记录的存量数据库，先将其标记为该版本，再执行后续的升级脚本
+	Baseline string
+	// Output 升级过程的输出
+	Output io.Writer
+}
+
+// SchemaStore 支持表结构版本管理的存储插件实现的可选接口
+type SchemaStore interface {
+	// UpgradeSchema 执行尚未应用的表结构升级脚本
+	UpgradeSchema(opt *SchemaUpgradeOption) error
+}
@@ -0,0 +1,38 @@
+/**
+ * Tencent is pleased to support the open source community by making Polaris available.
+ *
+ * Copyright (C) 2019 THL A29 Limited, a Tencent company. All rights reserved.
+ *
+ * Licensed under the BSD 3-Clause License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://opensource.org/licenses/BSD-3-Clause
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed
+ * under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+ * CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package store
+
+import (
+	"time"
+
+	authcommon "github.com/polarismesh/polaris/common/model/auth"
+)
+
+// ServiceAccessPolicyStore 服务间访问策略的可选扩展接口
+type ServiceAccessPolicyStore interface {
+	// AddServiceAccessPolicy 新增服务访问策略
+	AddServiceAccessPolicy(policy *authcommon.ServiceAccessPolicy) error
+	// UpdateServiceAccessPolicy 更新服务访问策略
+	UpdateServiceAccessPolicy(policy *authcommon.ServiceAccessPolicy) error
+	// DeleteServiceAccessPolicy 删除服务访问策略，只做逻辑删除，便于缓存增量感知
+	DeleteServiceAccessPolicy(id string) error
+	// GetServiceAccessPolicy 获取单个服务访问策略，不存在时返回 nil
+	GetServiceAccessPolicy(id string) (*authcommon.ServiceAccessPolicy, error)
+	// GetMoreServiceAccessPolicies 获取增量的服务访问策略，firstUpdate 时只返回有效数据
+	GetMoreServiceAccessPolicies(mtime time.Time, firstUpdate bool) ([]*authcommon.ServiceAccessPolicy, error)
+}
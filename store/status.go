@@ -67,6 +67,8 @@ const (
 	// 非法的用户ID列表
 	InvalidUserIDSlice
 	NotFoundResource
+	// 存储不可写，缓存尚未和存储完成同步
+	StoreUnavailable
 )
 
 // Error 普通error转StatusError
@@ -81,13 +83,15 @@ func Error(err error) error {
 	}
 
 	s := &StatusError{message: err.Error()}
-	if strings.Contains(s.message, "Data too long") {
+	if strings.Contains(s.message, "Data too long") || strings.Contains(s.message, "value too long") {
 		s.code = OutOfRangeErr
-	} else if strings.Contains(s.message, "Duplicate entry") {
+	} else if strings.Contains(s.message, "Duplicate entry") ||
+		strings.Contains(s.message, "duplicate key value violates unique constraint") {
 		s.code = DuplicateEntryErr
-	} else if strings.Contains(s.message, "a foreign key constraint fails") {
+	} else if strings.Contains(s.message, "a foreign key constraint fails") ||
+		strings.Contains(s.message, "violates foreign key constraint") {
 		s.code = ForeignKeyErr
-	} else if strings.Contains(s.message, "Deadlock") {
+	} else if strings.Contains(s.message, "Deadlock") || strings.Contains(s.message, "deadlock detected") {
 		s.code = DeadlockErr
 	} else {
 		s.code = Unknown
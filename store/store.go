@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 )
 
 // Config Store的通用配置
@@ -36,6 +37,8 @@ var (
 
 	once   = &sync.Once{}
 	config = &Config{}
+	// readOnly 存储是否只读
+	readOnly atomic.Bool
 )
 
 // RegisterStore 注册一个新的Store
@@ -80,3 +83,17 @@ func initialize(s Store) {
 		}
 	})
 }
+
+// SetReadOnly 设置存储是否只读，从快照启动的缓存尚未和存储完成首次对账时，基于缓存的写入校验不可信，此时拒绝写入
+func SetReadOnly(v bool) {
+	readOnly.Store(v)
+}
+
+// CheckWritable 存储只读时返回 StoreUnavailable 错误，由存储实现在执行写操作前调用
+func CheckWritable() error {
+	if readOnly.Load() {
+		return NewStatusError(StoreUnavailable,
+			"store unavailable: server is read-only until its cache is reloaded from the store")
+	}
+	return nil
+}
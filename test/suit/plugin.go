@@ -26,7 +26,9 @@ import (
 	_ "github.com/polarismesh/polaris/plugin/discoverevent/local"
 	_ "github.com/polarismesh/polaris/plugin/healthchecker/leader"
 	_ "github.com/polarismesh/polaris/plugin/healthchecker/memory"
+	_ "github.com/polarismesh/polaris/plugin/healthchecker/probe"
 	_ "github.com/polarismesh/polaris/plugin/healthchecker/redis"
+	_ "github.com/polarismesh/polaris/plugin/healthchecker/store"
 	_ "github.com/polarismesh/polaris/plugin/history/logger"
 	_ "github.com/polarismesh/polaris/plugin/password"
 	_ "github.com/polarismesh/polaris/plugin/ratelimit/token"